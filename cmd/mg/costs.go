@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+)
+
+// costDayJSON is one day in `mg costs --format json` output.
+type costDayJSON struct {
+	Date         string             `json:"date"`
+	Cost         float64            `json:"cost"`
+	InputTokens  int                `json:"input_tokens"`
+	OutputTokens int                `json:"output_tokens"`
+	Sessions     int                `json:"sessions"`
+	ByRole       map[string]float64 `json:"by_role,omitempty"`
+	ByRig        map[string]float64 `json:"by_rig,omitempty"`
+}
+
+// runCosts implements `mg costs`: the cost history mg records from `gt
+// costs`, one row per day, as a table, CSV or JSON. It returns the process
+// exit code.
+func runCosts(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("mg costs", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	since := fs.String("since", "30d", "Start of the report: a span back from today (30d, 4w) or a date (2006-01-02)")
+	format := fs.String("format", "table", "Output format: table, csv, or json")
+	history := fs.String("history", gastown.CostHistoryPath(), "Path to the cost history file")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: mg costs [flags]\n\n")
+		fmt.Fprintf(stderr, "Report the daily spend mg has recorded from gt costs, with per-role and\n")
+		fmt.Fprintf(stderr, "per-rig breakdowns.\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	now := time.Now()
+	start, err := parseSince(*since, now)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+	samples, err := gastown.LoadCostHistory(*history, start)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	span := int(startOfDay(now).Sub(startOfDay(start)).Hours()/24+0.5) + 1
+	var days []gastown.CostDay
+	for _, d := range gastown.CostDays(samples, span, now) {
+		if d.Sampled {
+			days = append(days, d)
+		}
+	}
+
+	switch *format {
+	case "csv":
+		err = writeCostsCSV(stdout, days)
+	case "json":
+		err = writeCostsJSON(stdout, days)
+	case "table":
+		writeCostsTable(stdout, days, start)
+	default:
+		fmt.Fprintf(stderr, "Error: unknown format %q (want table, csv, or json)\n", *format)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// parseSince reads --since as a day or week span back from today ("30d",
+// "4w") or a calendar date.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > 1 {
+		if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n > 0 {
+			switch s[len(s)-1] {
+			case 'd':
+				return startOfDay(now).AddDate(0, 0, -n+1), nil
+			case 'w':
+				return startOfDay(now).AddDate(0, 0, -7*n+1), nil
+			}
+		}
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, now.Location()); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: want a span like 30d or 4w, or a date like 2006-01-02", s)
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// writeCostsCSV writes one town row per day followed by its role and rig
+// rows, in a long format that pivots cleanly in a spreadsheet.
+func writeCostsCSV(w io.Writer, days []gastown.CostDay) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"date", "scope", "name", "cost", "input_tokens", "output_tokens", "sessions"})
+	money := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	for _, d := range days {
+		date := d.Day.Format(time.DateOnly)
+		_ = cw.Write([]string{date, "town", "", money(d.Cost),
+			strconv.Itoa(d.InputTokens), strconv.Itoa(d.OutputTokens), strconv.Itoa(d.Sessions)})
+		day := gastown.SumCostDays([]gastown.CostDay{d})
+		for _, rc := range day.ByRole {
+			_ = cw.Write([]string{date, "role", rc.Role, money(rc.Cost), "", "", ""})
+		}
+		for _, rc := range day.ByRig {
+			_ = cw.Write([]string{date, "rig", rc.Rig, money(rc.Cost), "", "", ""})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeCostsJSON(w io.Writer, days []gastown.CostDay) error {
+	out := make([]costDayJSON, len(days))
+	for i, d := range days {
+		out[i] = costDayJSON{
+			Date: d.Day.Format(time.DateOnly), Cost: d.Cost,
+			InputTokens: d.InputTokens, OutputTokens: d.OutputTokens, Sessions: d.Sessions,
+			ByRole: d.ByRole, ByRig: d.ByRig,
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func writeCostsTable(w io.Writer, days []gastown.CostDay, start time.Time) {
+	if len(days) == 0 {
+		fmt.Fprintf(w, "No cost history since %s. mg records gt costs while it runs with Gas Town.\n", start.Format(time.DateOnly))
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "DATE\tCOST\tIN\tOUT\tSESSIONS\t")
+	for _, d := range days {
+		fmt.Fprintf(tw, "%s\t$%.2f\t%dk\t%dk\t%d\t\n",
+			d.Day.Format(time.DateOnly), d.Cost, d.InputTokens/1000, d.OutputTokens/1000, d.Sessions)
+	}
+	total := gastown.SumCostDays(days)
+	fmt.Fprintf(tw, "TOTAL\t$%.2f\t%dk\t%dk\t%d\t\n",
+		total.Total.Cost, total.Total.InputTokens/1000, total.Total.OutputTokens/1000, total.Sessions)
+	_ = tw.Flush()
+
+	if len(total.ByRole) == 0 && len(total.ByRig) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SCOPE\tNAME\tCOST\tSHARE")
+	share := func(v float64) string {
+		if total.Total.Cost <= 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.0f%%", v*100/total.Total.Cost)
+	}
+	for _, rc := range total.ByRole {
+		fmt.Fprintf(tw, "role\t%s\t$%.2f\t%s\n", rc.Role, rc.Cost, share(rc.Cost))
+	}
+	for _, rc := range total.ByRig {
+		fmt.Fprintf(tw, "rig\t%s\t$%.2f\t%s\n", rc.Rig, rc.Cost, share(rc.Cost))
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+)
+
+func writeCostHistory(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "costs.jsonl")
+	now := time.Now()
+	for i, cost := range []float64{12.5, 7.25} {
+		s := gastown.CostSample{At: now.AddDate(0, 0, i-1)}
+		s.Total = gastown.CostTotal{InputTokens: 40000, OutputTokens: 8000, Cost: cost}
+		s.Sessions = 3
+		s.ByRole = []gastown.RoleCost{{Role: "polecat", Cost: cost}}
+		s.ByRig = []gastown.RigCost{{Rig: "gastown", Cost: cost}}
+		if err := gastown.RecordCostSample(path, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Outside a one-week window.
+	old := gastown.CostSample{At: now.AddDate(0, 0, -20)}
+	old.Total.Cost = 99
+	if err := gastown.RecordCostSample(path, old); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunCostsCSV(t *testing.T) {
+	path := writeCostHistory(t)
+	var stdout, stderr bytes.Buffer
+	if code := runCosts([]string{"--history", path, "--since", "1w", "--format", "csv"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d, stderr: %s", code, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("got %d lines, want header + 3 rows for each of 2 days:\n%s", len(lines), stdout.String())
+	}
+	if lines[0] != "date,scope,name,cost,input_tokens,output_tokens,sessions" {
+		t.Errorf("header = %q", lines[0])
+	}
+	yesterday := time.Now().AddDate(0, 0, -1).Format(time.DateOnly)
+	if lines[1] != yesterday+",town,,12.50,40000,8000,3" || lines[2] != yesterday+",role,polecat,12.50,,," {
+		t.Errorf("rows = %q", lines[1:3])
+	}
+	if strings.Contains(stdout.String(), "99.00") {
+		t.Error("--since should drop older samples")
+	}
+}
+
+func TestRunCostsJSONAndTable(t *testing.T) {
+	path := writeCostHistory(t)
+	var stdout, stderr bytes.Buffer
+	if code := runCosts([]string{"--history", path, "--since", "30d", "--format", "json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d, stderr: %s", code, stderr.String())
+	}
+	var got []costDayJSON
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("bad JSON %q: %v", stdout.String(), err)
+	}
+	if len(got) != 3 || got[0].Cost != 99 || got[2].ByRig["gastown"] != 7.25 {
+		t.Errorf("days = %+v", got)
+	}
+
+	stdout.Reset()
+	if code := runCosts([]string{"--history", path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d, stderr: %s", code, stderr.String())
+	}
+	for _, want := range []string{"DATE", "TOTAL", "$118.75", "polecat", "$19.75"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("table missing %q:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	for in, want := range map[string]string{
+		"1d":         "2026-03-10",
+		"30d":        "2026-02-09",
+		"2w":         "2026-02-25",
+		"2026-01-01": "2026-01-01",
+	} {
+		got, err := parseSince(in, now)
+		if err != nil || got.Format(time.DateOnly) != want {
+			t.Errorf("parseSince(%q) = %v, %v; want %s", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "d", "0d", "3x", "yesterday"} {
+		if _, err := parseSince(bad, now); err == nil {
+			t.Errorf("parseSince(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+)
+
+// forecastJSON is one forecast in `mg forecast --json` output.
+type forecastJSON struct {
+	Kind      string `json:"kind"`
+	ID        string `json:"id"`
+	Title     string `json:"title,omitempty"`
+	Remaining int    `json:"remaining"`
+	P50       string `json:"p50,omitempty"`
+	P85       string `json:"p85,omitempty"`
+	P95       string `json:"p95,omitempty"`
+	Histogram []int  `json:"histogram,omitempty"`
+}
+
+// runForecast implements `mg forecast`: Monte Carlo completion dates for open
+// convoys, epics and filter queries, printed as a table or JSON. It returns
+// the process exit code.
+func runForecast(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("mg forecast", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	path := fs.String("path", "", "Path to .beads/issues.jsonl file")
+	window := fs.Int("window", gastown.DefaultForecastWindow, "Days of close history to sample")
+	trials := fs.Int("trials", gastown.DefaultForecastTrials, "Simulated futures per forecast")
+	seed := fs.Uint64("seed", 0, "Random seed for reproducible output (default: time-based)")
+	query := fs.String("query", "", "Also forecast the open issues matching this filter query (e.g. \"type:bug p1\")")
+	convoys := fs.Bool("convoys", true, "Forecast open convoys when an orchestrator is available")
+	asJSON := fs.Bool("json", false, "Print forecasts as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: mg forecast [flags]\n\n")
+		fmt.Fprintf(stderr, "Forecast P50/P85/P95 completion dates for open convoys, epics, and filter queries\n")
+		fmt.Fprintf(stderr, "by sampling recent per-day close counts.\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	_, issues, ok := loadIssues(*path, stderr)
+	if !ok {
+		return 1
+	}
+
+	var convoyList []gastown.ConvoyDetail
+	if *convoys {
+		convoyList = loadForecastConvoys(stderr)
+	}
+
+	now := time.Now()
+	opts := gastown.ForecastOptions{Window: *window, Trials: *trials, Seed: *seed}
+	forecasts := gastown.Forecasts(issues, convoyList, strings.TrimSpace(*query), opts, now)
+
+	if *asJSON {
+		out := make([]forecastJSON, len(forecasts))
+		for i, f := range forecasts {
+			out[i] = forecastJSON{Kind: f.Kind, ID: f.ID, Title: f.Title, Remaining: f.Remaining, Histogram: f.Histogram}
+			if f.Known {
+				out[i].P50 = f.P50.Format(time.DateOnly)
+				out[i].P85 = f.P85.Format(time.DateOnly)
+				out[i].P95 = f.P95.Format(time.DateOnly)
+			}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if len(forecasts) == 0 {
+		fmt.Fprintln(stdout, "Nothing to forecast: no open convoys or epics with remaining work.")
+		return 0
+	}
+	fmt.Fprintf(stdout, "Monte Carlo forecast: %d trials over the last %d days of closes\n\n", *trials, *window)
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tID\tLEFT\tP50\tP85\tP95\tTITLE")
+	for _, f := range forecasts {
+		title := f.Title
+		if f.Kind == gastown.ForecastQuery {
+			title = ""
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+			f.Kind, f.ID, f.Remaining,
+			f.PercentileLabel(f.P50, now), f.PercentileLabel(f.P85, now), f.PercentileLabel(f.P95, now),
+			title)
+	}
+	_ = tw.Flush()
+	return 0
+}
+
+// loadForecastConvoys lists convoys through the active driver when an
+// orchestrator is reachable. Failures are reported and forecasting carries on
+// with epics and queries alone.
+func loadForecastConvoys(stderr io.Writer) []gastown.ConvoyDetail {
+	driver, err := gastown.SelectDriver()
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: %v\n", err)
+	}
+	if c, ok := driver.(io.Closer); ok {
+		defer c.Close()
+	}
+	if !gastown.Detect().Available && driver.Backend() == gastown.BackendGasTown {
+		return nil
+	}
+	convoys, err := driver.ConvoyList(context.Background())
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: could not list convoys: %v\n", err)
+		return nil
+	}
+	return convoys
+}
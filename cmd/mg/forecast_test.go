@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeForecastIssues(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	mustMkdir(t, filepath.Join(dir, ".beads"))
+	now := time.Now()
+	ts := func(t time.Time) string { return t.UTC().Format(time.RFC3339) }
+	lines := []string{
+		fmt.Sprintf(`{"id":"mg-1","title":"Launch","status":"open","priority":2,"issue_type":"epic","created_at":%q}`, ts(now)),
+		fmt.Sprintf(`{"id":"mg-1.1","title":"Part one","status":"open","priority":2,"issue_type":"task","created_at":%q}`, ts(now)),
+		fmt.Sprintf(`{"id":"mg-1.2","title":"Part two","status":"open","priority":2,"issue_type":"bug","created_at":%q}`, ts(now)),
+		fmt.Sprintf(`{"id":"mg-2","title":"Old","status":"closed","priority":2,"issue_type":"task","created_at":%q,"closed_at":%q}`, ts(now), ts(now)),
+		fmt.Sprintf(`{"id":"mg-3","title":"Older","status":"closed","priority":2,"issue_type":"task","created_at":%q,"closed_at":%q}`, ts(now), ts(now.AddDate(0, 0, -1))),
+	}
+	path := filepath.Join(dir, ".beads", "issues.jsonl")
+	mustWrite(t, path, []byte(strings.Join(lines, "\n")+"\n"))
+	return path
+}
+
+func TestRunForecastJSON(t *testing.T) {
+	path := writeForecastIssues(t)
+	var stdout, stderr bytes.Buffer
+	code := runForecast([]string{"--path", path, "--window", "2", "--trials", "50", "--seed", "3",
+		"--convoys=false", "--query", "type:bug", "--json"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit %d, stderr: %s", code, stderr.String())
+	}
+	var got []forecastJSON
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("bad JSON %q: %v", stdout.String(), err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("forecasts = %+v, want the epic and the query", got)
+	}
+	// One close a day: the epic's two open children finish tomorrow.
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(time.DateOnly)
+	if got[0].Kind != "epic" || got[0].Remaining != 2 || got[0].P95 != tomorrow {
+		t.Errorf("epic forecast = %+v, want 2 left done %s", got[0], tomorrow)
+	}
+	if got[1].Kind != "query" || got[1].ID != "type:bug" || got[1].Remaining != 1 {
+		t.Errorf("query forecast = %+v, want 1 open bug", got[1])
+	}
+}
+
+func TestRunForecastTable(t *testing.T) {
+	path := writeForecastIssues(t)
+	var stdout, stderr bytes.Buffer
+	if code := runForecast([]string{"--path", path, "--convoys=false"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d, stderr: %s", code, stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"KIND", "P85", "epic", "mg-1", "Launch"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunForecastLoadErrorGoesToStderr(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".beads", "issues.jsonl")
+	var stdout, stderr bytes.Buffer
+	if code := runForecast([]string{"--path", path, "--convoys=false"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit %d, want 1", code)
+	}
+	if stdout.Len() != 0 || !strings.Contains(stderr.String(), path) {
+		t.Fatalf("stdout %q, stderr %q: want the load error on stderr", stdout.String(), stderr.String())
+	}
+}
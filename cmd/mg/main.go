@@ -6,6 +6,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -31,6 +32,14 @@ const (
 var version = "dev"
 
 func main() {
+	// Headless subcommands take their own flags.
+	if len(os.Args) > 1 && os.Args[1] == "forecast" {
+		os.Exit(runForecast(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "costs" {
+		os.Exit(runCosts(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	path := flag.String("path", "", "Path to .beads/issues.jsonl file")
 	blockTypesFlag := flag.String("block-types", "", "Comma-separated dependency types that count as blockers (default: blocks)")
 	excludeTypesFlag := flag.String("exclude-type", "", "Comma-separated issue types to hide from the parade and status output")
@@ -39,7 +48,9 @@ func main() {
 	showVersion := flag.Bool("version", false, "Print version and exit")
 	noAnimations := flag.Bool("no-animations", false, "Disable confetti and header shimmer animations")
 	cmdTimeout := flag.Int("cmd-timeout", 0, "Command timeout in seconds (scales all external command timeouts; default 30)")
-	agentRuntime := flag.String("agent", "", "Preferred agent runtime: claude or cursor (default: claude if available, else cursor)")
+	agentRuntime := flag.String("agent", "", "Preferred agent runtime: claude, cursor, codex or a runtime from runtimes.json (default: first found on PATH)")
+	worktrees := flag.Bool("worktrees", false, "Launch each local agent in its own git worktree on the issue's branch")
+	maxAgents := flag.Int("max-agents", 0, "Agents the local agent queue runs at once (default 3)")
 	themeFlag := flag.String("theme", "", "Color theme: auto, dark, or light (default: MG_THEME env or auto)")
 	flag.Parse()
 
@@ -54,6 +65,16 @@ func main() {
 		os.Setenv("MG_AGENT_RUNTIME", *agentRuntime)
 	}
 
+	// --worktrees feeds MG_WORKTREES, read by data.WorktreesEnabled.
+	if *worktrees {
+		os.Setenv("MG_WORKTREES", "1")
+	}
+
+	// --max-agents feeds MG_MAX_AGENTS, read by agent.MaxAgents.
+	if *maxAgents > 0 {
+		os.Setenv("MG_MAX_AGENTS", strconv.Itoa(*maxAgents))
+	}
+
 	// MG_CMD_TIMEOUT env var as alternative to --cmd-timeout flag
 	if *cmdTimeout <= 0 {
 		if envTimeout := os.Getenv("MG_CMD_TIMEOUT"); envTimeout != "" {
@@ -80,19 +101,57 @@ func main() {
 	excludeTypes := parseTypeSet(*excludeTypesFlag)
 	excludeLabels := parseTypeSet(*excludeLabelsFlag)
 
-	// Resolve data source: JSONL file or bd CLI fallback
-	cwd, err := os.Getwd()
+	source, issues := mustLoadIssues(*path)
+
+	filters := app.Filters{ExcludeTypes: excludeTypes, ExcludeLabels: excludeLabels}
+
+	if *statusMode {
+		visible := data.ExcludeByLabel(data.ExcludeByType(issues, excludeTypes), excludeLabels)
+		groups := data.GroupByParade(visible, blockingTypes)
+		fmt.Print(tmux.StatusLine(groups))
+		return
+	}
+
+	// Run TUI
+	applyTheme(*themeFlag)
+	guard := app.NewOSCGuard()
+	model := app.NewWithGuard(issues, source, blockingTypes, guard, *noAnimations, filters)
+	p := tea.NewProgram(model, tea.WithFilter(guard.Filter()))
+	finalModel, err := p.Run()
+	if final, ok := finalModel.(app.Model); ok {
+		final.Cleanup()
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	source := resolveSource(cwd, *path)
-	if source.Mode == SourceJSONL && source.Path == "" {
-		fmt.Fprintf(os.Stderr, "No .beads/issues.jsonl found and bd not on PATH.\n\n")
-		fmt.Fprintf(os.Stderr, "Run mg from inside a project with Beads, or specify a path:\n")
-		fmt.Fprintf(os.Stderr, "  mg --path /path/to/.beads/issues.jsonl\n")
+}
+
+// mustLoadIssues is loadIssues for the TUI: it exits when no issues load.
+func mustLoadIssues(pathFlag string) (data.Source, []data.Issue) {
+	source, issues, ok := loadIssues(pathFlag, os.Stderr)
+	if !ok {
 		os.Exit(1)
 	}
+	return source, issues
+}
+
+// loadIssues resolves the data source (JSONL file or bd CLI fallback) and
+// loads its issues. When neither is available it writes guidance to stderr
+// and reports false.
+func loadIssues(pathFlag string, stderr io.Writer) (data.Source, []data.Issue, bool) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error getting working directory: %v\n", err)
+		return data.Source{}, nil, false
+	}
+	source := resolveSource(cwd, pathFlag)
+	if source.Mode == SourceJSONL && source.Path == "" {
+		fmt.Fprintf(stderr, "No .beads/issues.jsonl found and bd not on PATH.\n\n")
+		fmt.Fprintf(stderr, "Run mg from inside a project with Beads, or specify a path:\n")
+		fmt.Fprintf(stderr, "  mg --path /path/to/.beads/issues.jsonl\n")
+		return source, nil, false
+	}
 
 	// Load issues
 	var issues []data.Issue
@@ -100,48 +159,26 @@ func main() {
 	case SourceCLI:
 		issues, err = data.FetchIssuesCLI(source.ProjectDir)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error loading issues via bd list: %v\n\n", err)
+			fmt.Fprintf(stderr, "Error loading issues via bd list: %v\n\n", err)
 			if hint := data.SchemaSkewHint(err); hint != "" {
-				fmt.Fprint(os.Stderr, hint)
+				fmt.Fprint(stderr, hint)
 			} else {
-				fmt.Fprintf(os.Stderr, "Ensure the Dolt server is running (dolt sql-server) and bd is working.\n")
+				fmt.Fprintf(stderr, "Ensure the Dolt server is running (dolt sql-server) and bd is working.\n")
 			}
-			os.Exit(1)
+			return source, nil, false
 		}
 	default:
 		var skipped int
 		issues, skipped, err = data.LoadIssues(source.Path)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error loading issues from %s: %v\n", source.Path, err)
-			os.Exit(1)
+			fmt.Fprintf(stderr, "Error loading issues from %s: %v\n", source.Path, err)
+			return source, nil, false
 		}
 		if skipped > 0 {
-			fmt.Fprintf(os.Stderr, "Warning: skipped %d malformed line(s) in %s\n", skipped, source.Path)
+			fmt.Fprintf(stderr, "Warning: skipped %d malformed line(s) in %s\n", skipped, source.Path)
 		}
 	}
-
-	filters := app.Filters{ExcludeTypes: excludeTypes, ExcludeLabels: excludeLabels}
-
-	if *statusMode {
-		visible := data.ExcludeByLabel(data.ExcludeByType(issues, excludeTypes), excludeLabels)
-		groups := data.GroupByParade(visible, blockingTypes)
-		fmt.Print(tmux.StatusLine(groups))
-		return
-	}
-
-	// Run TUI
-	applyTheme(*themeFlag)
-	guard := app.NewOSCGuard()
-	model := app.NewWithGuard(issues, source, blockingTypes, guard, *noAnimations, filters)
-	p := tea.NewProgram(model, tea.WithFilter(guard.Filter()))
-	finalModel, err := p.Run()
-	if final, ok := finalModel.(app.Model); ok {
-		final.Cleanup()
-	}
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
+	return source, issues, true
 }
 
 // applyTheme resolves the color theme from the --theme flag, the MG_THEME env
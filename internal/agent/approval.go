@@ -0,0 +1,368 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/codexmcp"
+	"github.com/matt-wright86/mardi-gras/internal/config"
+)
+
+// Approval rule decisions.
+const (
+	ApprovalApprove = "approve"
+	ApprovalDeny    = "deny"
+)
+
+// ApprovalPolicy is the codex approval rules file, read from
+// ApprovalRulesPath:
+//
+//	{
+//	  "rules": [
+//	    {"name": "ci config", "decision": "deny", "files": [".github/**"]},
+//	    {"name": "tests", "decision": "approve", "command": ["go", "test", "**"]},
+//	    {"name": "read-only git", "decision": "approve", "command": ["git", "status|diff|log", "**"], "cwd": "~/src"}
+//	  ]
+//	}
+//
+// Rules are checked in order and the first match decides. Requests no rule
+// matches go to the approval dialog.
+type ApprovalPolicy struct {
+	Rules []ApprovalRule `json:"rules"`
+}
+
+// ApprovalRule auto-approves or auto-denies codex exec and patch requests.
+// Every condition it sets must hold:
+//
+//   - Command matches exec argv element by element. Each element is a
+//     wildcard pattern ("*" matches anything, "a|b" either alternative) and
+//     a final "**" matches any remaining arguments. Commands wrapped in
+//     `bash -lc "<script>"` are matched against the script's words unless
+//     the script chains or redirects commands.
+//   - Cwd matches when the request's directory is inside it.
+//   - Files are path globs where "**" spans directories; relative globs
+//     match at any depth. A deny rule matches a patch touching any matching
+//     file, an approve rule only one whose files all match. Paths are
+//     cleaned first, and a file that escapes the request's directory (a
+//     leftover "..", or an absolute path outside it) never matches an
+//     approve rule and always matches a deny rule.
+type ApprovalRule struct {
+	Name     string   `json:"name,omitempty"`
+	Decision string   `json:"decision"`       // ApprovalApprove or ApprovalDeny
+	Kind     string   `json:"kind,omitempty"` // "exec", "patch" or both when empty
+	Command  []string `json:"command,omitempty"`
+	Cwd      string   `json:"cwd,omitempty"`
+	Files    []string `json:"files,omitempty"`
+}
+
+// ApprovalRulesPath returns the approval rules path: MG_APPROVAL_RULES if
+// set, otherwise mardi-gras/approvals.json under the user config directory.
+func ApprovalRulesPath() string {
+	return config.Path("MG_APPROVAL_RULES", "approvals.json")
+}
+
+// LoadApprovalPolicy reads and checks an approval rules file. A missing file
+// is not an error: it returns nil, nil and every request goes to the dialog.
+func LoadApprovalPolicy(path string) (*ApprovalPolicy, error) {
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("approval rules: %w", err)
+	}
+	var p ApprovalPolicy
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, fmt.Errorf("approval rules %s: %w", path, err)
+	}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		r.Cwd = expandHome(r.Cwd)
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("approval rules %s: %s: %w", path, r.Name, err)
+		}
+	}
+	return &p, nil
+}
+
+func (r ApprovalRule) validate() error {
+	switch r.Decision {
+	case ApprovalApprove, ApprovalDeny:
+	default:
+		return fmt.Errorf("decision %q, want approve or deny", r.Decision)
+	}
+	switch r.Kind {
+	case "", "exec", "patch":
+	default:
+		return fmt.Errorf("kind %q, want exec or patch", r.Kind)
+	}
+	if len(r.Command) > 0 && r.Kind == "patch" || len(r.Files) > 0 && r.Kind == "exec" {
+		return fmt.Errorf("kind %s can't match both command and files", r.Kind)
+	}
+	if len(r.Command) > 0 && len(r.Files) > 0 {
+		return fmt.Errorf("command and files never match the same request")
+	}
+	if r.Kind == "" && len(r.Command) == 0 && r.Cwd == "" && len(r.Files) == 0 {
+		return fmt.Errorf("no conditions: it would match every request")
+	}
+	for _, f := range r.Files {
+		if _, err := path.Match(strings.ReplaceAll(f, "**", "*"), ""); err != nil {
+			return fmt.Errorf("files %q: %w", f, err)
+		}
+	}
+	return nil
+}
+
+// Match returns the first rule matching the request, if any. The caller
+// fills a.Cwd for patches from the session's directory.
+func (p *ApprovalPolicy) Match(a codexmcp.ElicitApproval) (ApprovalRule, bool) {
+	if p == nil {
+		return ApprovalRule{}, false
+	}
+	for _, r := range p.Rules {
+		if r.matches(a) {
+			return r, true
+		}
+	}
+	return ApprovalRule{}, false
+}
+
+func (r ApprovalRule) matches(a codexmcp.ElicitApproval) bool {
+	if a.Kind != "exec" && a.Kind != "patch" || r.Kind != "" && r.Kind != a.Kind {
+		return false
+	}
+	if r.Cwd != "" && !withinDir(a.Cwd, r.Cwd) {
+		return false
+	}
+	if len(r.Command) > 0 && (a.Kind != "exec" || !matchArgv(r.Command, a.Command)) {
+		return false
+	}
+	if len(r.Files) > 0 {
+		if a.Kind != "patch" || len(a.Changes) == 0 {
+			return false
+		}
+		for file := range a.Changes {
+			file, inside := cleanPatchPath(file, a.Cwd)
+			m := inside && matchAnyGlob(r.Files, file) || !inside && r.Decision == ApprovalDeny
+			if r.Decision == ApprovalDeny && m {
+				return true
+			}
+			if r.Decision == ApprovalApprove && !m {
+				return false
+			}
+		}
+		return r.Decision == ApprovalApprove
+	}
+	return true
+}
+
+// ReviewDecision is the codex decision the rule answers with.
+func (r ApprovalRule) ReviewDecision() string {
+	if r.Decision == ApprovalApprove {
+		return "approved"
+	}
+	return "denied"
+}
+
+// shellWrappers are the shells codex wraps commands in.
+var shellWrappers = map[string]bool{"bash": true, "sh": true, "zsh": true, "/bin/bash": true, "/bin/sh": true, "/bin/zsh": true}
+
+// matchArgv matches argv, or the words of a simple `bash -lc` script,
+// against a Command pattern.
+func matchArgv(pattern, argv []string) bool {
+	if matchArgs(pattern, argv) {
+		return true
+	}
+	if len(argv) == 3 && shellWrappers[argv[0]] && (argv[1] == "-c" || argv[1] == "-lc") &&
+		!strings.ContainsAny(argv[2], ";&|<>`$()\n") {
+		return matchArgs(pattern, strings.Fields(argv[2]))
+	}
+	return false
+}
+
+func matchArgs(pattern, args []string) bool {
+	for i, p := range pattern {
+		if p == "**" && i == len(pattern)-1 {
+			return true
+		}
+		if i >= len(args) || !matchWord(p, args[i]) {
+			return false
+		}
+	}
+	return len(args) == len(pattern)
+}
+
+// matchWord matches one argument against "|"-separated wildcard
+// alternatives.
+func matchWord(pattern, s string) bool {
+	for alt := range strings.SplitSeq(pattern, "|") {
+		if wildcard(alt, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcard reports whether s matches pattern, where "*" matches any run of
+// characters, "/" included.
+func wildcard(pattern, s string) bool {
+	star, mark := -1, 0
+	pi, si := 0, 0
+	for si < len(s) {
+		switch {
+		case pi < len(pattern) && pattern[pi] == '*':
+			star, mark = pi, si
+			pi++
+		case pi < len(pattern) && pattern[pi] == s[si]:
+			pi++
+			si++
+		case star >= 0:
+			pi = star + 1
+			mark++
+			si = mark
+		default:
+			return false
+		}
+	}
+	for pi < len(pattern) && pattern[pi] == '*' {
+		pi++
+	}
+	return pi == len(pattern)
+}
+
+// cleanPatchPath cleans a patched file's path and reports whether it stays
+// inside cwd. Relative paths are taken as relative to cwd; absolute ones
+// need a cwd to be checked against.
+func cleanPatchPath(file, cwd string) (string, bool) {
+	file = path.Clean(filepath.ToSlash(file))
+	if !path.IsAbs(file) {
+		return file, file != ".." && !strings.HasPrefix(file, "../")
+	}
+	return file, cwd != "" && withinDir(filepath.FromSlash(file), cwd)
+}
+
+func matchAnyGlob(globs []string, file string) bool {
+	file = filepath.ToSlash(file)
+	for _, g := range globs {
+		if matchGlob(g, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash-separated path against a glob where "**" spans
+// any number of directories. Relative globs may match any trailing part of
+// the path, so ".github/**" catches /src/repo/.github/workflows/ci.yml.
+func matchGlob(glob, file string) bool {
+	gs := strings.Split(strings.Trim(glob, "/"), "/")
+	fs := strings.Split(strings.Trim(file, "/"), "/")
+	if strings.HasPrefix(glob, "/") {
+		return matchSegments(gs, fs)
+	}
+	for i := range fs {
+		if matchSegments(gs, fs[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(glob, file []string) bool {
+	if len(glob) == 0 {
+		return len(file) == 0
+	}
+	if glob[0] == "**" {
+		for i := 0; i <= len(file); i++ {
+			if matchSegments(glob[1:], file[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(file) == 0 {
+		return false
+	}
+	ok, _ := path.Match(glob[0], file[0])
+	return ok && matchSegments(glob[1:], file[1:])
+}
+
+// withinDir reports whether dir is root or inside it.
+func withinDir(dir, root string) bool {
+	if dir == "" {
+		return false
+	}
+	dir, root = filepath.Clean(dir), filepath.Clean(root)
+	return dir == root || strings.HasPrefix(dir, root+string(filepath.Separator))
+}
+
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, strings.TrimPrefix(p, "~"))
+}
+
+// Who decided an approval request.
+const (
+	ApprovedByRule = "rule" // a rule in the approvals file
+	ApprovedByUser = "user" // the approval dialog
+	ApprovedByMg   = "mg"   // an unsupported request mg denied
+)
+
+// ApprovalAudit is one line of the approval audit log.
+type ApprovalAudit struct {
+	At       time.Time `json:"at"`
+	Issue    string    `json:"issue"`
+	Kind     string    `json:"kind"`
+	Command  []string  `json:"command,omitempty"`
+	Cwd      string    `json:"cwd,omitempty"`
+	Files    []string  `json:"files,omitempty"`
+	Decision string    `json:"decision"` // the codex ReviewDecision sent
+	By       string    `json:"by"`       // ApprovedByRule, ApprovedByUser or ApprovedByMg
+	Rule     string    `json:"rule,omitempty"`
+}
+
+// NewApprovalAudit records a decision on a request for issueID.
+func NewApprovalAudit(at time.Time, issueID string, a codexmcp.ElicitApproval, decision, by, rule string) ApprovalAudit {
+	files := make([]string, 0, len(a.Changes))
+	for f := range a.Changes {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return ApprovalAudit{
+		At: at, Issue: issueID, Kind: a.Kind, Command: a.Command, Cwd: a.Cwd, Files: files,
+		Decision: decision, By: by, Rule: rule,
+	}
+}
+
+// ApprovalAuditPath returns the approval audit log path: MG_APPROVAL_AUDIT
+// if set, otherwise mardi-gras/approval-audit.jsonl under the user config
+// directory.
+func ApprovalAuditPath() string {
+	return config.Path("MG_APPROVAL_AUDIT", "approval-audit.jsonl")
+}
+
+// AppendApprovalAudit appends one entry to the audit log at path.
+func AppendApprovalAudit(path string, entry ApprovalAudit) error {
+	if err := config.AppendJSONL(path, entry); err != nil {
+		return fmt.Errorf("approval audit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package agent
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/codexmcp"
+)
+
+func writeApprovalRules(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "approvals.json")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func patchOf(files ...string) codexmcp.ElicitApproval {
+	changes := make(map[string]json.RawMessage, len(files))
+	for _, f := range files {
+		changes[f] = json.RawMessage(`{}`)
+	}
+	return codexmcp.ElicitApproval{Kind: "patch", Changes: changes, Cwd: "/src/mg"}
+}
+
+func TestApprovalPolicyMatch(t *testing.T) {
+	p, err := LoadApprovalPolicy(writeApprovalRules(t, `{"rules": [
+		{"name": "ci config", "decision": "deny", "files": [".github/**"]},
+		{"name": "tests", "decision": "approve", "command": ["go", "test", "**"]},
+		{"name": "git read", "decision": "approve", "command": ["git", "status|diff"], "cwd": "/src/mg"},
+		{"name": "docs", "decision": "approve", "files": ["docs/**", "*.md"]},
+		{"decision": "deny", "kind": "exec", "command": ["rm", "**"]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := func(cwd string, argv ...string) codexmcp.ElicitApproval {
+		return codexmcp.ElicitApproval{Kind: "exec", Command: argv, Cwd: cwd}
+	}
+	tests := []struct {
+		name string
+		req  codexmcp.ElicitApproval
+		rule string // "" when no rule should match
+	}{
+		{"argv with rest", exec("/src/mg", "go", "test", "./..."), "tests"},
+		{"bare command", exec("/src/mg", "go", "test"), "tests"},
+		{"other subcommand", exec("/src/mg", "go", "build", "./..."), ""},
+		{"shell wrapped", exec("/src/mg", "bash", "-lc", "go test ./internal/..."), "tests"},
+		{"chained script isn't unwrapped", exec("/src/mg", "bash", "-lc", "go test ./... && rm -rf /"), ""},
+		{"alternatives in cwd", exec("/src/mg/internal", "git", "diff"), "git read"},
+		{"outside cwd", exec("/src/mgx", "git", "status"), ""},
+		{"extra args need **", exec("/src/mg", "git", "status", "-s"), ""},
+		{"unnamed rule", exec("/tmp", "rm", "-rf", "build"), "rule 5"},
+		{"deny on any file", patchOf("/src/mg/README.md", "/src/mg/.github/workflows/ci.yml"), "ci config"},
+		{"approve needs every file", patchOf("/src/mg/docs/a.md", "/src/mg/main.go"), ""},
+		{"approve when all match", patchOf("/src/mg/docs/guide/a.txt", "CHANGELOG.md"), "docs"},
+		{"unknown kind", codexmcp.ElicitApproval{Command: []string{"go", "test"}}, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, ok := p.Match(tc.req)
+			if got := map[bool]string{true: r.Name}[ok]; got != tc.rule {
+				t.Errorf("matched %q, want %q", got, tc.rule)
+			}
+		})
+	}
+
+	var none *ApprovalPolicy
+	if _, ok := none.Match(exec("/", "go", "test")); ok {
+		t.Error("a nil policy matches nothing")
+	}
+}
+
+func TestLoadApprovalPolicyErrors(t *testing.T) {
+	if p, err := LoadApprovalPolicy(filepath.Join(t.TempDir(), "missing.json")); p != nil || err != nil {
+		t.Fatalf("missing file = %v, %v", p, err)
+	}
+	for _, body := range []string{
+		`{"rules": [{"decision": "allow", "command": ["ls"]}]}`,
+		`{"rules": [{"decision": "approve"}]}`,
+		`{"rules": [{"decision": "deny", "kind": "exec", "files": ["*.go"]}]}`,
+		`{"rules": [{"decision": "deny", "files": ["[.go"]}]}`,
+		`{"rules": [`,
+	} {
+		if _, err := LoadApprovalPolicy(writeApprovalRules(t, body)); err == nil {
+			t.Errorf("%s: expected an error", body)
+		}
+	}
+}
+
+func TestAppendApprovalAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "approval-audit.jsonl")
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	entries := []ApprovalAudit{
+		NewApprovalAudit(at, "mg-1", codexmcp.ElicitApproval{Kind: "exec", Command: []string{"go", "test"}, Cwd: "/src"}, "approved", ApprovedByRule, "tests"),
+		NewApprovalAudit(at, "mg-1", patchOf("b.go", "a.go"), "denied", ApprovedByUser, ""),
+	}
+	for _, e := range entries {
+		if err := AppendApprovalAudit(path, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %d", len(lines))
+	}
+	var got ApprovalAudit
+	if err := json.Unmarshal([]byte(lines[1]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.By != ApprovedByUser || got.Decision != "denied" || strings.Join(got.Files, ",") != "a.go,b.go" {
+		t.Errorf("second entry = %+v", got)
+	}
+	if !strings.Contains(lines[0], `"rule":"tests"`) {
+		t.Errorf("first entry = %s", lines[0])
+	}
+}
+
+func TestApprovalPolicyPatchPathsEscapingCwd(t *testing.T) {
+	approve := &ApprovalPolicy{Rules: []ApprovalRule{{Name: "src", Decision: ApprovalApprove, Files: []string{"src/**"}}}}
+	deny := &ApprovalPolicy{Rules: []ApprovalRule{{Name: "vendor", Decision: ApprovalDeny, Files: []string{"vendor/**"}}}}
+	noCwd := patchOf("/src/mg/src/a.go")
+	noCwd.Cwd = ""
+	tests := []struct {
+		name            string
+		req             codexmcp.ElicitApproval
+		approve, denied bool
+	}{
+		{"inside", patchOf("src/a.go"), true, false},
+		{"dot-dot cleaned inside", patchOf("src/x/../a.go"), true, false},
+		{"dot-dot climbing out of src", patchOf("src/../.github/workflows/x.yml"), false, false},
+		{"dot-dot leaving cwd", patchOf("src/../../etc/passwd"), false, true},
+		{"absolute inside cwd", patchOf("/src/mg/src/a.go"), true, false},
+		{"absolute outside cwd", patchOf("/etc/src/a.go"), false, true},
+		{"absolute without cwd", noCwd, false, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, ok := approve.Match(tc.req); ok != tc.approve {
+				t.Errorf("approve rule matched = %v, want %v", ok, tc.approve)
+			}
+			if _, ok := deny.Match(tc.req); ok != tc.denied {
+				t.Errorf("deny rule matched = %v, want %v", ok, tc.denied)
+			}
+		})
+	}
+}
@@ -10,44 +10,117 @@ import (
 	"sync"
 
 	"github.com/matt-wright86/mardi-gras/internal/codexmcp"
+	"github.com/matt-wright86/mardi-gras/internal/mcp"
 )
 
-// CodexMCPHandle owns the lifecycle of one codex MCP subprocess plus the
-// most recent session running against it. Callers must call Close when done;
-// mg's app closes all handles on quit. Replies rotate the session pointer
-// (see Reply); the underlying subprocess is reused across replies.
-type CodexMCPHandle struct {
-	transport *codexmcp.SubprocessTransport
-	client    *codexmcp.Client
-	session   *codexmcp.Session
+// MCPSession is one tool call streaming into a transcript: a codex session,
+// or the call a generic MCP agent is driven through. Events carry codex
+// event shapes either way, so the transcript renders both alike.
+type MCPSession interface {
+	Events() <-chan codexmcp.CodexEvent
+	Done() <-chan codexmcp.SessionResult
+	// ThreadID is the conversation a reply continues; "" when there is none.
+	ThreadID() string
+	Cancel()
+}
+
+// MCPHandle owns the lifecycle of one MCP agent subprocess plus the most
+// recent session running against it. Callers must call Close when done;
+// mg's app closes all handles on quit. For codex, replies rotate the session
+// pointer (see Reply); the underlying subprocess is reused across replies.
+type MCPHandle struct {
+	transport *mcp.SubprocessTransport
+	client    *mcp.Client
+	codex     *codexmcp.Client // nil for generic agents
+	session   MCPSession
+	// generic marks an agent other than codex: its approvals are plain MCP
+	// elicitations and it has no reply tool.
+	generic bool
+	label   string
 
 	closeOnce sync.Once
 	closeErr  error
 }
 
-// Session returns the most recent codexmcp.Session attached to this handle.
-// After Reply rotates the session, Session() returns the new one.
-func (h *CodexMCPHandle) Session() *codexmcp.Session { return h.session }
+// Session returns the most recent session attached to this handle. After
+// Reply rotates the session, Session() returns the new one.
+func (h *MCPHandle) Session() MCPSession { return h.session }
+
+// Label names the agent for display, e.g. "Codex".
+func (h *MCPHandle) Label() string {
+	if h.label == "" {
+		return "Codex"
+	}
+	return h.label
+}
 
-// ServerRequests returns the client's server-initiated request channel (codex
-// approval prompts). The channel is stable across Reply session rotation since it
-// belongs to the underlying client/subprocess. Returns nil if the handle is closed.
-func (h *CodexMCPHandle) ServerRequests() <-chan codexmcp.ServerRequest {
+// ServerRequests returns the client's server-initiated request channel
+// (approval prompts). The channel is stable across Reply session rotation since
+// it belongs to the underlying client/subprocess. Returns nil if the handle is
+// closed.
+func (h *MCPHandle) ServerRequests() <-chan mcp.ServerRequest {
 	if h.client == nil {
 		return nil
 	}
 	return h.client.ServerRequests()
 }
 
+// ParseApproval decodes a server request into an approval for the dialog.
+// Codex sends exec and patch approvals; other agents send plain
+// elicitations, which come back with Kind "elicit". ok is false for
+// anything else, and for elicitations that ask for more than yes/no answers,
+// which the caller denies.
+func (h *MCPHandle) ParseApproval(req mcp.ServerRequest) (codexmcp.ElicitApproval, bool) {
+	if !h.generic {
+		return codexmcp.ParseElicitApproval(req.Params)
+	}
+	if req.Method != mcp.MethodElicit {
+		return codexmcp.ElicitApproval{}, false
+	}
+	e, ok := mcp.ParseElicitRequest(req.Params)
+	if !ok {
+		return codexmcp.ElicitApproval{}, false
+	}
+	if _, ok := mcp.ElicitConfirmContent(e.RequestedSchema); !ok {
+		return codexmcp.ElicitApproval{}, false
+	}
+	return codexmcp.ElicitApproval{Kind: "elicit", Message: e.Message}, true
+}
+
+// ApprovalResult builds the reply to req from a codex ReviewDecision
+// ("approved", "approved_for_session", "denied", "abort"). Generic agents
+// get the matching elicitation action. An approval accepts only a schema
+// with at most one boolean, answering it yes; any other schema is declined,
+// since the dialog shows just the message and has no form to fill it in.
+func (h *MCPHandle) ApprovalResult(req mcp.ServerRequest, decision string) map[string]any {
+	if !h.generic {
+		return map[string]any{"decision": decision}
+	}
+	switch decision {
+	case "approved", "approved_for_session":
+		e, _ := mcp.ParseElicitRequest(req.Params)
+		if content, ok := mcp.ElicitConfirmContent(e.RequestedSchema); ok {
+			return mcp.ElicitResult(mcp.ElicitAccept, content)
+		}
+	case "abort":
+		return mcp.ElicitResult(mcp.ElicitCancel, nil)
+	}
+	return mcp.ElicitResult(mcp.ElicitDecline, nil)
+}
+
 // Respond answers a server-initiated request (e.g. an approval prompt) with a
-// result, echoing the request's RawID. See codexmcp.Client.Respond.
-func (h *CodexMCPHandle) Respond(rawID json.RawMessage, result any) error {
+// result, echoing the request's RawID. See mcp.Client.Respond.
+func (h *MCPHandle) Respond(rawID json.RawMessage, result any) error {
 	if h.client == nil {
-		return errors.New("agent: CodexMCPHandle has no client (already closed?)")
+		return errors.New("agent: MCPHandle has no client (already closed?)")
 	}
 	return h.client.Respond(rawID, result)
 }
 
+// CanReply reports whether the agent takes follow-up prompts. Only codex
+// has a reply tool.
+func (h *MCPHandle) CanReply() bool { return !h.generic }
+
 // Reply continues the conversation by invoking codex-reply with the given
 // prompt against the threadID captured from the original session. The new
 // session becomes h.Session(); the old session has already terminated by the
@@ -59,10 +132,13 @@ func (h *CodexMCPHandle) Respond(rawID json.RawMessage, result any) error {
 // defer-cancel in the dispatch goroutine doesn't kill the session before
 // any reply event is rendered (the same trap v0.21.1 fixed on the launch
 // path). ctx is reserved for a future setup-only timeout if needed.
-func (h *CodexMCPHandle) Reply(ctx context.Context, prompt string) (*codexmcp.Session, error) {
+func (h *MCPHandle) Reply(ctx context.Context, prompt string) (MCPSession, error) {
 	_ = ctx // reserved; intentionally not propagated to StartReplySession
-	if h.client == nil {
-		return nil, errors.New("agent: CodexMCPHandle has no client (already closed?)")
+	if h.generic {
+		return nil, fmt.Errorf("agent: %s has no reply tool", h.Label())
+	}
+	if h.codex == nil {
+		return nil, errors.New("agent: MCPHandle has no client (already closed?)")
 	}
 	threadID := ""
 	if h.session != nil {
@@ -71,7 +147,7 @@ func (h *CodexMCPHandle) Reply(ctx context.Context, prompt string) (*codexmcp.Se
 	if threadID == "" {
 		return nil, errors.New("agent: cannot Reply — original session has no threadID yet")
 	}
-	sess, err := h.client.StartReplySession(context.Background(), threadID, prompt)
+	sess, err := h.codex.StartReplySession(context.Background(), threadID, prompt)
 	if err != nil {
 		return nil, fmt.Errorf("start codex-reply session: %w", err)
 	}
@@ -81,7 +157,7 @@ func (h *CodexMCPHandle) Reply(ctx context.Context, prompt string) (*codexmcp.Se
 
 // Close cancels the session, terminates the subprocess, and releases pipes.
 // Safe to call multiple times.
-func (h *CodexMCPHandle) Close() error {
+func (h *MCPHandle) Close() error {
 	h.closeOnce.Do(func() {
 		if h.session != nil {
 			h.session.Cancel()
@@ -96,7 +172,7 @@ func (h *CodexMCPHandle) Close() error {
 
 // StderrTail returns the last stderr lines emitted by the subprocess. Useful
 // for diagnostic messages when the session ends with an error.
-func (h *CodexMCPHandle) StderrTail(n int) []string {
+func (h *MCPHandle) StderrTail(n int) []string {
 	if h.transport == nil {
 		return nil
 	}
@@ -122,16 +198,20 @@ type LaunchCodexMCPOptions struct {
 	// ClientVersion is advertised to the server in initialize. Defaults to
 	// "dev".
 	ClientVersion string
+	// ThreadID resumes a stored conversation: the session starts with
+	// codex-reply against this thread instead of a fresh codex call, and
+	// Sandbox, ApprovalPolicy and Model are inherited from the original.
+	ThreadID string
 }
 
 // codexTransportFactory is the function used to spawn the codex MCP transport.
 // Tests override this to inject a pipe-based transport without a real codex
 // binary.
-var codexTransportFactory = func(opts LaunchCodexMCPOptions) (codexmcp.Transport, *codexmcp.SubprocessTransport, error) {
+var codexTransportFactory = func(opts LaunchCodexMCPOptions) (mcp.Transport, *mcp.SubprocessTransport, error) {
 	if _, err := exec.LookPath("codex"); err != nil {
 		return nil, nil, ErrCodexUnavailable
 	}
-	t, err := codexmcp.SpawnSubprocess(codexmcp.WithDir(opts.ProjectDir))
+	t, err := codexmcp.SpawnSubprocess(mcp.WithDir(opts.ProjectDir))
 	if err != nil {
 		return nil, nil, fmt.Errorf("spawn codex mcp-server: %w", err)
 	}
@@ -144,7 +224,7 @@ var codexTransportFactory = func(opts LaunchCodexMCPOptions) (codexmcp.Transport
 //
 // LaunchCodexMCP requires `codex` on PATH. If the binary is missing the call
 // returns ErrCodexUnavailable so callers can fall back to the tmux path.
-func LaunchCodexMCP(ctx context.Context, opts LaunchCodexMCPOptions) (*CodexMCPHandle, error) {
+func LaunchCodexMCP(ctx context.Context, opts LaunchCodexMCPOptions) (*MCPHandle, error) {
 	if strings.TrimSpace(opts.Prompt) == "" {
 		return nil, errors.New("agent: LaunchCodexMCP requires a prompt")
 	}
@@ -181,22 +261,28 @@ func LaunchCodexMCP(ctx context.Context, opts LaunchCodexMCPOptions) (*CodexMCPH
 
 	// Detach the session from the caller's ctx. mg's launch path defer-cancels
 	// the launch ctx once LaunchCodexMCP returns, which would kill the
-	// session before any event flows. Cancellation is via CodexMCPHandle.Close.
-	session, err := client.StartSession(context.Background(), codexmcp.SessionOptions{
-		Prompt:         opts.Prompt,
-		Cwd:            opts.ProjectDir,
-		Sandbox:        sandbox,
-		ApprovalPolicy: approval,
-		Model:          opts.Model,
-	})
+	// session before any event flows. Cancellation is via MCPHandle.Close.
+	var session *codexmcp.Session
+	if opts.ThreadID != "" {
+		session, err = client.StartReplySession(context.Background(), opts.ThreadID, opts.Prompt)
+	} else {
+		session, err = client.StartSession(context.Background(), codexmcp.SessionOptions{
+			Prompt:         opts.Prompt,
+			Cwd:            opts.ProjectDir,
+			Sandbox:        sandbox,
+			ApprovalPolicy: approval,
+			Model:          opts.Model,
+		})
+	}
 	if err != nil {
 		_ = client.Close()
 		return nil, fmt.Errorf("start codex session: %w", err)
 	}
 
-	return &CodexMCPHandle{
+	return &MCPHandle{
 		transport: subproc,
-		client:    client,
+		client:    client.Client,
+		codex:     client,
 		session:   session,
 	}, nil
 }
@@ -157,7 +157,7 @@ func TestIntegrationCodexReplyAgainstRealCodex(t *testing.T) {
 
 // drainTurn reads events from sess until Done fires, returning the final
 // threadId and the last agent_message seen.
-func drainTurn(t *testing.T, sess *codexmcp.Session, timeout time.Duration) (threadID, lastAgentMessage string) {
+func drainTurn(t *testing.T, sess MCPSession, timeout time.Duration) (threadID, lastAgentMessage string) {
 	t.Helper()
 	deadline := time.After(timeout)
 	for {
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/matt-wright86/mardi-gras/internal/codexmcp"
+	"github.com/matt-wright86/mardi-gras/internal/mcp"
 )
 
 func TestLaunchCodexMCPRequiresPrompt(t *testing.T) {
@@ -45,7 +46,7 @@ type fakeMCPServer struct {
 	closed chan struct{}
 }
 
-func newFakePipe(t *testing.T) (codexmcp.Transport, *codexmcp.SubprocessTransport, *fakeMCPServer) {
+func newFakePipe(t *testing.T) (mcp.Transport, *mcp.SubprocessTransport, *fakeMCPServer) {
 	t.Helper()
 	cr, sw := io.Pipe()
 	sr, cw := io.Pipe()
@@ -131,7 +132,7 @@ func (f *fakeMCPServer) notify(method string, params any) {
 func withFakeCodexTransport(t *testing.T) {
 	t.Helper()
 	prev := codexTransportFactory
-	codexTransportFactory = func(LaunchCodexMCPOptions) (codexmcp.Transport, *codexmcp.SubprocessTransport, error) {
+	codexTransportFactory = func(LaunchCodexMCPOptions) (mcp.Transport, *mcp.SubprocessTransport, error) {
 		tp, sp, _ := newFakePipe(t)
 		return tp, sp, nil
 	}
@@ -189,7 +190,7 @@ func TestLaunchCodexMCPBridgesEventsAndDone(t *testing.T) {
 
 func TestLaunchCodexMCPReportsTransportError(t *testing.T) {
 	prev := codexTransportFactory
-	codexTransportFactory = func(opts LaunchCodexMCPOptions) (codexmcp.Transport, *codexmcp.SubprocessTransport, error) {
+	codexTransportFactory = func(opts LaunchCodexMCPOptions) (mcp.Transport, *mcp.SubprocessTransport, error) {
 		return nil, nil, ErrCodexUnavailable
 	}
 	t.Cleanup(func() { codexTransportFactory = prev })
@@ -311,7 +312,7 @@ func TestReplyRotatesSession(t *testing.T) {
 	}()
 
 	prev := codexTransportFactory
-	codexTransportFactory = func(opts LaunchCodexMCPOptions) (codexmcp.Transport, *codexmcp.SubprocessTransport, error) {
+	codexTransportFactory = func(opts LaunchCodexMCPOptions) (mcp.Transport, *mcp.SubprocessTransport, error) {
 		return transport, nil, nil
 	}
 	t.Cleanup(func() { codexTransportFactory = prev })
@@ -437,7 +438,7 @@ func TestReplyCtxDoesNotKillSession(t *testing.T) {
 	}()
 
 	prev := codexTransportFactory
-	codexTransportFactory = func(opts LaunchCodexMCPOptions) (codexmcp.Transport, *codexmcp.SubprocessTransport, error) {
+	codexTransportFactory = func(opts LaunchCodexMCPOptions) (mcp.Transport, *mcp.SubprocessTransport, error) {
 		return transport, nil, nil
 	}
 	t.Cleanup(func() { codexTransportFactory = prev })
@@ -518,3 +519,74 @@ func TestCloseIsIdempotent(t *testing.T) {
 		}
 	}
 }
+
+func TestLaunchCodexMCPResumesThread(t *testing.T) {
+	cr, sw := io.Pipe()
+	sr, cw := io.Pipe()
+	transport := &pipeTransport{clientRead: cr, clientWrite: cw}
+	fs := &fakeMCPServer{
+		dec:    json.NewDecoder(bufio.NewReader(sr)),
+		enc:    json.NewEncoder(sw),
+		closed: make(chan struct{}),
+	}
+	calls := make(chan map[string]any, 1)
+	go func() {
+		defer func() {
+			_ = sr.Close()
+			_ = sw.Close()
+			close(fs.closed)
+		}()
+		if !fs.runHandshake() {
+			return
+		}
+		var call map[string]any
+		if err := fs.dec.Decode(&call); err != nil {
+			return
+		}
+		calls <- call
+		id, _ := call["id"].(float64)
+		fs.respond(int(id), map[string]any{
+			"structuredContent": map[string]any{"threadId": "thr-stored", "content": "resumed"},
+		})
+		for {
+			var m map[string]any
+			if err := fs.dec.Decode(&m); err != nil {
+				return
+			}
+		}
+	}()
+
+	prev := codexTransportFactory
+	codexTransportFactory = func(LaunchCodexMCPOptions) (mcp.Transport, *mcp.SubprocessTransport, error) {
+		return transport, nil, nil
+	}
+	t.Cleanup(func() { codexTransportFactory = prev })
+
+	h, err := LaunchCodexMCP(context.Background(), LaunchCodexMCPOptions{Prompt: "carry on", ThreadID: "thr-stored"})
+	if err != nil {
+		t.Fatalf("LaunchCodexMCP: %v", err)
+	}
+	t.Cleanup(func() { _ = h.Close() })
+
+	select {
+	case call := <-calls:
+		params := call["params"].(map[string]any)
+		args := params["arguments"].(map[string]any)
+		if params["name"] != "codex-reply" || args["threadId"] != "thr-stored" || args["prompt"] != "carry on" {
+			t.Fatalf("tools/call params = %v", params)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no tools/call")
+	}
+	if h.Session().ThreadID() != "thr-stored" {
+		t.Errorf("ThreadID = %q", h.Session().ThreadID())
+	}
+	select {
+	case res := <-h.Session().Done():
+		if res.Err != nil || res.Content != "resumed" {
+			t.Fatalf("Done = %+v", res)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Done timeout")
+	}
+}
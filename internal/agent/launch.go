@@ -1,14 +1,11 @@
-// Package agent handles AI agent runtime detection and launch. It supports
-// Claude Code and Cursor, with tmux window dispatch for multi-agent sessions.
+// Package agent handles AI agent runtime detection and launch. Runtimes come
+// from a registry (Claude Code, Cursor and Codex built in, more from a
+// runtimes file), with tmux window dispatch for multi-agent sessions.
 package agent
 
 import (
-	"fmt"
-	"os"
 	"os/exec"
 	"strings"
-
-	"github.com/matt-wright86/mardi-gras/internal/data"
 )
 
 // Runtime identifies which AI agent binary to use.
@@ -22,37 +19,13 @@ const (
 
 // DetectRuntime returns the agent runtime to launch.
 //
-// If MG_AGENT_RUNTIME is set to "claude", "cursor" (or "cursor-agent"), or
-// "codex" and the corresponding binary is on PATH, that runtime wins. Unknown
-// values or missing binaries fall through to the default detection order:
-// claude, then cursor-agent, then codex.
+// If MG_AGENT_RUNTIME names a runtime in the registry (by name or alias, so
+// "cursor" means cursor-agent) and it is installed, that runtime wins.
+// Unknown values or missing binaries fall through to the registry's
+// detection order: claude, then cursor-agent, then codex, then any runtimes
+// defined in the runtimes file.
 func DetectRuntime() Runtime {
-	if pref := strings.ToLower(strings.TrimSpace(os.Getenv("MG_AGENT_RUNTIME"))); pref != "" {
-		switch pref {
-		case "claude":
-			if _, err := exec.LookPath("claude"); err == nil {
-				return RuntimeClaude
-			}
-		case "cursor", "cursor-agent":
-			if _, err := exec.LookPath("cursor-agent"); err == nil {
-				return RuntimeCursor
-			}
-		case "codex":
-			if _, err := exec.LookPath("codex"); err == nil {
-				return RuntimeCodex
-			}
-		}
-	}
-	if _, err := exec.LookPath("claude"); err == nil {
-		return RuntimeClaude
-	}
-	if _, err := exec.LookPath("cursor-agent"); err == nil {
-		return RuntimeCursor
-	}
-	if _, err := exec.LookPath("codex"); err == nil {
-		return RuntimeCodex
-	}
-	return ""
+	return loadRegistry().Preferred()
 }
 
 // Available returns true if any supported agent CLI is on PATH.
@@ -62,101 +35,34 @@ func Available() bool {
 
 // RuntimeLabel returns a display name for the runtime.
 func (r Runtime) RuntimeLabel() string {
-	switch r {
-	case RuntimeClaude:
-		return "Claude Code"
-	case RuntimeCursor:
-		return "Cursor"
-	case RuntimeCodex:
-		return "Codex"
-	default:
-		return "unknown"
-	}
+	return loadRegistry().Label(r)
 }
 
-// BuildPrompt composes the initial prompt for a Claude Code session
-// given a selected issue and its evaluated dependencies.
-func BuildPrompt(issue data.Issue, deps data.DepEval, issueMap map[string]*data.Issue) string {
-	var b strings.Builder
-
-	b.WriteString("Work on this Beads issue:\n\n")
-	fmt.Fprintf(&b, "## %s: %s\n\n", issue.ID, issue.Title)
-
-	fmt.Fprintf(&b, "Status: %s | Type: %s | Priority: %s\n",
-		issue.Status, issue.IssueType, data.PriorityLabel(issue.Priority))
-	if issue.Owner != "" {
-		fmt.Fprintf(&b, "Owner: %s\n", issue.Owner)
-	}
-	if issue.Assignee != "" {
-		fmt.Fprintf(&b, "Assignee: %s\n", issue.Assignee)
-	}
-
-	if issue.Description != "" {
-		fmt.Fprintf(&b, "\n%s\n", issue.Description)
-	}
-
-	if issue.Notes != "" {
-		fmt.Fprintf(&b, "\n### Notes\n%s\n", issue.Notes)
-	}
-
-	if issue.AcceptanceCriteria != "" {
-		fmt.Fprintf(&b, "\n### Acceptance Criteria\n%s\n", issue.AcceptanceCriteria)
-	}
-
-	if len(deps.Edges) > 0 {
-		b.WriteString("\n### Dependencies\n")
-		for _, edge := range deps.Edges {
-			switch edge.Status {
-			case data.DepBlocking:
-				if dep, ok := issueMap[edge.DependsOnID]; ok {
-					fmt.Fprintf(&b, "- Blocked by: %s (%s) -- %s\n",
-						edge.DependsOnID, dep.Title, dep.Status)
-				}
-			case data.DepMissing:
-				fmt.Fprintf(&b, "- Missing: %s (not found)\n", edge.DependsOnID)
-			case data.DepResolved:
-				if dep, ok := issueMap[edge.DependsOnID]; ok {
-					fmt.Fprintf(&b, "- Resolved: %s (%s) -- closed\n",
-						edge.DependsOnID, dep.Title)
-				}
-			case data.DepNonBlocking:
-				if dep, ok := issueMap[edge.DependsOnID]; ok {
-					fmt.Fprintf(&b, "- Related: %s (%s) -- %s\n",
-						edge.DependsOnID, dep.Title, edge.Type)
-				}
-			}
-		}
-	}
-
-	fmt.Fprintf(&b, "\n---\nWhen you begin work, run: bd update %s --status=in_progress\n", issue.ID)
-	fmt.Fprintf(&b, "When finished, run: bd close %s\n", issue.ID)
-	b.WriteString("\nIf this task is complex enough to benefit from parallel work, consider using agent teams to spawn teammates for independent subtasks.")
-
-	return b.String()
+// Spec returns the runtime's registry entry.
+func (r Runtime) Spec() (RuntimeSpec, bool) {
+	return loadRegistry().Lookup(string(r))
 }
 
 // Command returns an *exec.Cmd that launches the detected agent runtime
-// with the given prompt, working directory set to projectDir.
-//
-// Codex defaults to sandboxed execution with interactive approval prompts,
-// which would block unattended agent sessions. We pass --sandbox workspace-write
-// and -a on-request to match the zero-friction posture Claude and Cursor have
-// out of the box. Power users can override via codex profiles or
-// MG_AGENT_RUNTIME=codex combined with a custom shell alias.
+// with the given prompt, working directory set to projectDir. With no
+// runtime detected it falls back to claude.
 func Command(prompt, projectDir string) *exec.Cmd {
-	rt := DetectRuntime()
-	var c *exec.Cmd
-	switch rt {
-	case RuntimeCursor:
-		c = exec.Command("cursor-agent", "-f", "-p", prompt)
-	case RuntimeCodex:
-		c = exec.Command("codex",
-			"--sandbox", "workspace-write",
-			"-a", "on-request",
-			"-C", projectDir,
-			prompt)
-	default: // Claude Code
-		c = exec.Command("claude", prompt)
+	reg := loadRegistry()
+	return reg.Command(reg.Preferred(), prompt, projectDir)
+}
+
+// Command is the package-level Command for runtime rt.
+func (r *Registry) Command(rt Runtime, prompt, projectDir string) *exec.Cmd {
+	spec := r.launchSpec(rt)
+	argv, stdin, err := spec.Argv(prompt, projectDir, false)
+	if err != nil {
+		// Argv only fails writing a prompt file; start the agent bare
+		// rather than not at all.
+		argv = []string{spec.Binary}
+	}
+	c := exec.Command(argv[0], argv[1:]...)
+	if stdin != "" {
+		c.Stdin = strings.NewReader(stdin)
 	}
 	c.Dir = projectDir
 	return c
@@ -0,0 +1,254 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/matt-wright86/mardi-gras/internal/codexmcp"
+	"github.com/matt-wright86/mardi-gras/internal/mcp"
+)
+
+// MCPSpec drives a runtime in-process over MCP instead of launching its CLI:
+// mg spawns Command as an MCP server and calls Tool with the prompt, then
+// streams the call's notifications into the transcript and answers its
+// elicitations with the approval dialog. Command is an argv template where
+// {project_dir} is replaced at launch.
+type MCPSpec struct {
+	Command []string `json:"command"`
+	Tool    string   `json:"tool"`
+	// PromptArg names the tool argument carrying the prompt; default "prompt".
+	PromptArg string `json:"prompt_arg,omitempty"`
+	// CwdArg names the tool argument carrying the project directory, if the
+	// tool takes one.
+	CwdArg string `json:"cwd_arg,omitempty"`
+	// Args are fixed arguments sent with every call.
+	Args map[string]any `json:"args,omitempty"`
+}
+
+// arguments builds the tools/call arguments for a prompt.
+func (s MCPSpec) arguments(prompt, projectDir string) map[string]any {
+	args := maps.Clone(s.Args)
+	if args == nil {
+		args = map[string]any{}
+	}
+	promptArg := s.PromptArg
+	if promptArg == "" {
+		promptArg = "prompt"
+	}
+	args[promptArg] = prompt
+	if s.CwdArg != "" && projectDir != "" {
+		args[s.CwdArg] = projectDir
+	}
+	return args
+}
+
+// LaunchMCPAgentOptions controls how a generic MCP agent is launched.
+type LaunchMCPAgentOptions struct {
+	// Spec is the runtime to drive; its MCP field must be set.
+	Spec RuntimeSpec
+	// Prompt is the user prompt. Required.
+	Prompt string
+	// ProjectDir is the working directory for the subprocess.
+	ProjectDir string
+	// ClientVersion is advertised to the server in initialize. Defaults to
+	// "dev".
+	ClientVersion string
+}
+
+// mcpTransportFactory spawns a generic agent's MCP server. Tests override it
+// to inject a pipe-based transport.
+var mcpTransportFactory = func(spec MCPSpec, projectDir string) (mcp.Transport, *mcp.SubprocessTransport, error) {
+	argv := expand(spec.Command, map[string]string{"{project_dir}": projectDir})
+	if _, err := exec.LookPath(argv[0]); err != nil {
+		return nil, nil, fmt.Errorf("agent: %s not on PATH", argv[0])
+	}
+	t, err := mcp.SpawnSubprocess(argv[0], argv[1:], mcp.WithDir(projectDir))
+	if err != nil {
+		return nil, nil, fmt.Errorf("spawn %s: %w", strings.Join(argv, " "), err)
+	}
+	return t, t, nil
+}
+
+// LaunchMCPAgent spawns a runtime's MCP server, checks that it offers the
+// spec's tool, and calls it with the prompt. Like LaunchCodexMCP it returns
+// a handle whose session streams into the transcript; the session ends
+// when the tool call returns.
+func LaunchMCPAgent(ctx context.Context, opts LaunchMCPAgentOptions) (*MCPHandle, error) {
+	spec := opts.Spec.MCP
+	if spec == nil || len(spec.Command) == 0 || spec.Tool == "" {
+		return nil, fmt.Errorf("agent: runtime %s has no mcp command and tool", opts.Spec.Name)
+	}
+	if strings.TrimSpace(opts.Prompt) == "" {
+		return nil, errors.New("agent: LaunchMCPAgent requires a prompt")
+	}
+	label := opts.Spec.DisplayLabel()
+
+	transport, subproc, err := mcpTransportFactory(*spec, opts.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+	clientVersion := opts.ClientVersion
+	if clientVersion == "" {
+		clientVersion = "dev"
+	}
+	client, err := mcp.Dial(ctx, transport, mcp.WithClientVersion(clientVersion))
+	if err != nil {
+		if subproc != nil {
+			if stderr := strings.Join(subproc.StderrLines(10), "\n"); stderr != "" {
+				return nil, fmt.Errorf("%s mcp handshake: %w (stderr: %s)", label, err, stderr)
+			}
+		}
+		return nil, fmt.Errorf("%s mcp handshake: %w", label, err)
+	}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("%s: %w", label, err)
+	}
+	if !slices.ContainsFunc(tools, func(t mcp.Tool) bool { return t.Name == spec.Tool }) {
+		_ = client.Close()
+		names := make([]string, len(tools))
+		for i, t := range tools {
+			names[i] = t.Name
+		}
+		return nil, fmt.Errorf("%s mcp server has no tool %q (has %s)", label, spec.Tool, strings.Join(names, ", "))
+	}
+
+	// Detached from ctx for the same reason as LaunchCodexMCP: the caller
+	// cancels ctx as soon as the launch returns.
+	call, err := client.CallTool(context.Background(), spec.Tool, spec.arguments(opts.Prompt, opts.ProjectDir), mcp.WithProgress())
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("%s: %w", label, err)
+	}
+	sess := &mcpAgentSession{
+		client: client,
+		call:   call,
+		events: make(chan codexmcp.CodexEvent, 128),
+		done:   make(chan codexmcp.SessionResult, 1),
+	}
+	go sess.run()
+
+	return &MCPHandle{
+		transport: subproc,
+		client:    client,
+		session:   sess,
+		generic:   true,
+		label:     label,
+	}, nil
+}
+
+// mcpAgentSession is the MCPSession of a generic agent: one tools/call
+// whose notifications are translated into codex event shapes.
+type mcpAgentSession struct {
+	client *mcp.Client
+	call   *mcp.ToolCall
+	events chan codexmcp.CodexEvent
+	done   chan codexmcp.SessionResult
+}
+
+func (s *mcpAgentSession) Events() <-chan codexmcp.CodexEvent  { return s.events }
+func (s *mcpAgentSession) Done() <-chan codexmcp.SessionResult { return s.done }
+
+// ThreadID is always empty: a generic agent has no conversation to reply to.
+func (s *mcpAgentSession) ThreadID() string { return "" }
+
+// Cancel sends notifications/cancelled for the tool call.
+func (s *mcpAgentSession) Cancel() { s.call.Cancel("cancelled by user") }
+
+// run forwards notifications until the call finishes. Notifications sent
+// before the result are already buffered when it lands, so they are
+// drained before Done fires.
+func (s *mcpAgentSession) run() {
+	defer close(s.events)
+	notes := s.client.Notifications()
+	for {
+		select {
+		case n, ok := <-notes:
+			if !ok {
+				notes = nil // transport closed; the call ends with its error
+				continue
+			}
+			s.forward(n)
+		case res := <-s.call.Done():
+			for drained := false; !drained && notes != nil; {
+				select {
+				case n, ok := <-notes:
+					if !ok {
+						drained = true
+						break
+					}
+					s.forward(n)
+				default:
+					drained = true
+				}
+			}
+			s.done <- toolSessionResult(res)
+			return
+		}
+	}
+}
+
+// forward turns a notification into a transcript event: progress for this
+// call and log messages become background events, anything else a raw one.
+// Events are dropped when the buffer is full rather than stall the reader.
+func (s *mcpAgentSession) forward(n mcp.Notification) {
+	var msg map[string]any
+	switch n.Method {
+	case mcp.MethodProgress:
+		var p mcp.ProgressParams
+		if json.Unmarshal(n.Params, &p) != nil || !s.call.IsProgress(p) {
+			return
+		}
+		text := p.Message
+		if text == "" && p.Total > 0 {
+			text = fmt.Sprintf("progress %g/%g", p.Progress, p.Total)
+		} else if text == "" {
+			text = fmt.Sprintf("progress %g", p.Progress)
+		}
+		msg = map[string]any{"type": "background_event", "message": text}
+	case mcp.MethodLogMessage:
+		var l mcp.LogMessageParams
+		if json.Unmarshal(n.Params, &l) != nil {
+			return
+		}
+		var text string
+		if json.Unmarshal(l.Data, &text) != nil {
+			text = string(l.Data)
+		}
+		msg = map[string]any{"type": "background_event", "message": "[" + l.Level + "] " + text}
+	default:
+		msg = map[string]any{"type": n.Method, "params": n.Params}
+	}
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	select {
+	case s.events <- codexmcp.CodexEvent{Msg: raw}:
+	default:
+	}
+}
+
+// toolSessionResult maps a tool call's outcome onto a SessionResult. A tool
+// that reports isError fails the session with its text.
+func toolSessionResult(res mcp.ToolCallResult) codexmcp.SessionResult {
+	if res.Err != nil {
+		return codexmcp.SessionResult{Err: res.Err}
+	}
+	text := res.Result.Text()
+	if res.Result.IsError {
+		if text == "" {
+			text = "tool reported an error"
+		}
+		return codexmcp.SessionResult{Err: errors.New(text)}
+	}
+	return codexmcp.SessionResult{Content: text}
+}
@@ -0,0 +1,192 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/codexmcp"
+	"github.com/matt-wright86/mardi-gras/internal/mcp"
+)
+
+// withFakeMCPAgent swaps mcpTransportFactory for a pipe-backed fake server
+// that lists one tool named "run" and hands the tools/call to script.
+func withFakeMCPAgent(t *testing.T, script func(f *fakeMCPServer, call map[string]any)) {
+	t.Helper()
+	prev := mcpTransportFactory
+	mcpTransportFactory = func(MCPSpec, string) (mcp.Transport, *mcp.SubprocessTransport, error) {
+		cr, sw := io.Pipe()
+		sr, cw := io.Pipe()
+		f := &fakeMCPServer{dec: json.NewDecoder(bufio.NewReader(sr)), enc: json.NewEncoder(sw), closed: make(chan struct{})}
+		go func() {
+			defer func() {
+				_ = sr.Close()
+				_ = sw.Close()
+			}()
+			if !f.runHandshake() {
+				return
+			}
+			var list map[string]any
+			if f.dec.Decode(&list) != nil {
+				return
+			}
+			id, _ := list["id"].(float64)
+			f.respond(int(id), map[string]any{"tools": []map[string]any{{"name": "run"}}})
+			var call map[string]any
+			if f.dec.Decode(&call) != nil {
+				return
+			}
+			script(f, call)
+			for {
+				var m map[string]any
+				if f.dec.Decode(&m) != nil {
+					return
+				}
+			}
+		}()
+		return &pipeTransport{clientRead: cr, clientWrite: cw}, nil, nil
+	}
+	t.Cleanup(func() { mcpTransportFactory = prev })
+}
+
+func TestLaunchMCPAgentStreamsNotificationsAndResult(t *testing.T) {
+	var gotArgs map[string]any
+	withFakeMCPAgent(t, func(f *fakeMCPServer, call map[string]any) {
+		params, _ := call["params"].(map[string]any)
+		gotArgs, _ = params["arguments"].(map[string]any)
+		meta, _ := params["_meta"].(map[string]any)
+		id, _ := call["id"].(float64)
+		f.notify("notifications/progress", map[string]any{"progressToken": meta["progressToken"], "progress": 1, "total": 3})
+		f.notify("notifications/message", map[string]any{"level": "info", "data": "reading files"})
+		f.notify("agent/custom", map[string]any{"x": 1})
+		f.respond(int(id), map[string]any{"content": []map[string]any{{"type": "text", "text": "all done"}}})
+	})
+
+	h, err := LaunchMCPAgent(context.Background(), LaunchMCPAgentOptions{
+		Spec: RuntimeSpec{Name: "helper", Label: "Helper", MCP: &MCPSpec{
+			Command: []string{"helper", "mcp"}, Tool: "run", CwdArg: "dir", Args: map[string]any{"mode": "auto"},
+		}},
+		Prompt:     "fix it",
+		ProjectDir: "/work",
+	})
+	if err != nil {
+		t.Fatalf("LaunchMCPAgent: %v", err)
+	}
+	defer func() { _ = h.Close() }()
+	if h.Label() != "Helper" || h.CanReply() {
+		t.Fatalf("label %q, CanReply %v", h.Label(), h.CanReply())
+	}
+
+	var types, messages []string
+	sess := h.Session()
+	for ev := range sess.Events() {
+		types = append(types, ev.EventType())
+		var bg codexmcp.BackgroundEvent
+		if json.Unmarshal(ev.Msg, &bg) == nil && bg.Message != "" {
+			messages = append(messages, bg.Message)
+		}
+	}
+	select {
+	case res := <-sess.Done():
+		if res.Err != nil || res.Content != "all done" {
+			t.Fatalf("result = %+v", res)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("session never finished")
+	}
+	if strings.Join(types, ",") != "background_event,background_event,agent/custom" {
+		t.Fatalf("event types = %v", types)
+	}
+	if strings.Join(messages, "|") != "progress 1/3|[info] reading files" {
+		t.Fatalf("messages = %v", messages)
+	}
+	if gotArgs["prompt"] != "fix it" || gotArgs["dir"] != "/work" || gotArgs["mode"] != "auto" {
+		t.Fatalf("arguments = %v", gotArgs)
+	}
+}
+
+func TestLaunchMCPAgentRequiresTool(t *testing.T) {
+	withFakeMCPAgent(t, func(*fakeMCPServer, map[string]any) {})
+	_, err := LaunchMCPAgent(context.Background(), LaunchMCPAgentOptions{
+		Spec:   RuntimeSpec{Name: "helper", MCP: &MCPSpec{Command: []string{"helper"}, Tool: "missing"}},
+		Prompt: "x",
+	})
+	if err == nil || !strings.Contains(err.Error(), `no tool "missing" (has run)`) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestMCPHandleApprovals(t *testing.T) {
+	generic := &MCPHandle{generic: true}
+	a, ok := generic.ParseApproval(mcp.ServerRequest{Method: mcp.MethodElicit, Params: json.RawMessage(`{"message":"Push to main?"}`)})
+	if !ok || a.Kind != "elicit" || a.Message != "Push to main?" {
+		t.Fatalf("generic approval = %+v, %v", a, ok)
+	}
+	if _, ok := generic.ParseApproval(mcp.ServerRequest{Method: "sampling/createMessage"}); ok {
+		t.Fatal("non-elicitation request parsed as an approval")
+	}
+	plain := mcp.ServerRequest{Method: mcp.MethodElicit, Params: json.RawMessage(`{"message":"Push to main?"}`)}
+	for decision, action := range map[string]string{"approved": "accept", "approved_for_session": "accept", "denied": "decline", "abort": "cancel"} {
+		if got := generic.ApprovalResult(plain, decision)["action"]; got != action {
+			t.Errorf("%s -> %v, want %s", decision, got, action)
+		}
+	}
+
+	codex := &MCPHandle{}
+	if got := codex.ApprovalResult(mcp.ServerRequest{}, "denied"); got["decision"] != "denied" {
+		t.Fatalf("codex result = %v", got)
+	}
+	if _, err := generic.Reply(context.Background(), "more"); err == nil {
+		t.Fatal("generic Reply should fail")
+	}
+}
+
+func TestMCPHandleApprovalSchemas(t *testing.T) {
+	generic := &MCPHandle{generic: true}
+	elicit := func(schema string) mcp.ServerRequest {
+		return mcp.ServerRequest{Method: mcp.MethodElicit, Params: json.RawMessage(`{"message":"Go?","requestedSchema":` + schema + `}`)}
+	}
+
+	yesNo := elicit(`{"type":"object","properties":{"confirm":{"type":"boolean"}},"required":["confirm"]}`)
+	if _, ok := generic.ParseApproval(yesNo); !ok {
+		t.Fatal("boolean-only schema should reach the approval dialog")
+	}
+	got := generic.ApprovalResult(yesNo, "approved")
+	if content, _ := got["content"].(map[string]any); got["action"] != "accept" || content["confirm"] != true {
+		t.Errorf("boolean-only approve = %v, want accept with confirm=true", got)
+	}
+	if got := generic.ApprovalResult(elicit(`{"type":"object"}`), "approved"); got["action"] != "accept" || got["content"] != nil {
+		t.Errorf("empty schema approve = %v, want accept without content", got)
+	}
+
+	for _, schema := range []string{
+		`{"type":"object","properties":{"branch":{"type":"string"}},"required":["branch"]}`,
+		`{"type":"object","properties":{"ok":{"type":"boolean"},"note":{"type":"string"}}}`,
+		`{"type":"object","properties":{"push":{"type":"boolean"},"force":{"type":"boolean"}}}`,
+		`{"type":"object","required":["branch"]}`,
+	} {
+		req := elicit(schema)
+		if _, ok := generic.ParseApproval(req); ok {
+			t.Errorf("%s: should not be offered as a yes/no approval", schema)
+		}
+		if got := generic.ApprovalResult(req, "approved"); got["action"] != "decline" {
+			t.Errorf("%s: approve = %v, want decline", schema, got)
+		}
+	}
+}
+
+func TestLoadRegistryRejectsIncompleteMCP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtimes.json")
+	if err := os.WriteFile(path, []byte(`{"runtimes":[{"name":"helper","mcp":{"command":["helper"]}}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRegistry(path); err == nil || !strings.Contains(err.Error(), "mcp needs a command and a tool") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+// maxPromptComments caps how many of an issue's latest comments a prompt
+// template sees.
+const maxPromptComments = 5
+
+// DefaultPromptName names the built-in template in previews and errors.
+const DefaultPromptName = "built-in"
+
+// DefaultPromptTemplate is the prompt used when a project has no template
+// of its own. It doubles as a starting point for writing one.
+const DefaultPromptTemplate = `Work on this Beads issue:
+
+## {{.Issue.ID}}: {{.Issue.Title}}
+
+Status: {{.Issue.Status}} | Type: {{.Issue.IssueType}} | Priority: {{priority .Issue.Priority}}
+{{if .Issue.Owner}}Owner: {{.Issue.Owner}}
+{{end}}{{if .Issue.Assignee}}Assignee: {{.Issue.Assignee}}
+{{end}}{{if .Issue.Description}}
+{{.Issue.Description}}
+{{end}}{{if .Issue.Notes}}
+### Notes
+{{.Issue.Notes}}
+{{end}}{{if .Issue.AcceptanceCriteria}}
+### Acceptance Criteria
+{{.Issue.AcceptanceCriteria}}
+{{end}}{{if .DepEval.Edges}}
+### Dependencies
+{{range .Deps}}{{if eq .Kind "blocking"}}- Blocked by: {{.ID}} ({{.Title}}) -- {{.Status}}
+{{else if eq .Kind "missing"}}- Missing: {{.ID}} (not found)
+{{else if eq .Kind "resolved"}}- Resolved: {{.ID}} ({{.Title}}) -- closed
+{{else}}- Related: {{.ID}} ({{.Title}}) -- {{.Type}}
+{{end}}{{end}}{{end}}
+---
+When you begin work, run: bd update {{.Issue.ID}} --status=in_progress
+When finished, run: bd close {{.Issue.ID}}
+
+If this task is complex enough to benefit from parallel work, consider using agent teams to spawn teammates for independent subtasks.`
+
+// PromptDep is one dependency edge as a prompt template sees it. Kind is
+// "blocking", "missing", "resolved" or "related".
+type PromptDep struct {
+	ID     string
+	Title  string
+	Status data.Status
+	Type   string
+	Kind   string
+}
+
+// PromptComment is one issue comment as a prompt template sees it.
+type PromptComment struct {
+	Author string
+	Body   string
+	Time   string
+}
+
+// PromptData is what a prompt template is executed over.
+type PromptData struct {
+	Issue   data.Issue
+	DepEval data.DepEval
+	// Deps lists the dependency edges that can be described: missing ones,
+	// and the others whose issue is loaded.
+	Deps []PromptDep
+	// Parent is the issue's parent epic, by dotted ID or parent-child
+	// dependency; nil if it has none or it isn't loaded.
+	Parent *data.Issue
+	// Siblings are the parent's other children, by ID.
+	Siblings []data.Issue
+	// Comments are the latest comments, oldest first.
+	Comments []PromptComment
+	Metadata map[string]any
+}
+
+// NewPromptData gathers the template data for issue from the loaded issues.
+// comments may be nil when they haven't been fetched.
+func NewPromptData(issue data.Issue, deps data.DepEval, issueMap map[string]*data.Issue, comments []PromptComment) PromptData {
+	d := PromptData{Issue: issue, DepEval: deps, Metadata: issue.Metadata}
+	for _, edge := range deps.Edges {
+		pd := PromptDep{ID: edge.DependsOnID, Type: edge.Type}
+		switch edge.Status {
+		case data.DepBlocking:
+			pd.Kind = "blocking"
+		case data.DepMissing:
+			d.Deps = append(d.Deps, PromptDep{ID: edge.DependsOnID, Type: edge.Type, Kind: "missing"})
+			continue
+		case data.DepResolved:
+			pd.Kind = "resolved"
+		case data.DepNonBlocking:
+			pd.Kind = "related"
+		default:
+			continue
+		}
+		dep, ok := issueMap[edge.DependsOnID]
+		if !ok {
+			continue
+		}
+		pd.Title, pd.Status = dep.Title, dep.Status
+		d.Deps = append(d.Deps, pd)
+	}
+
+	if parentID := promptParentID(&issue); parentID != "" {
+		d.Parent = issueMap[parentID]
+		for id, iss := range issueMap {
+			if id != issue.ID && promptParentID(iss) == parentID {
+				d.Siblings = append(d.Siblings, *iss)
+			}
+		}
+		sort.Slice(d.Siblings, func(i, j int) bool { return d.Siblings[i].ID < d.Siblings[j].ID })
+	}
+
+	if len(comments) > maxPromptComments {
+		comments = comments[len(comments)-maxPromptComments:]
+	}
+	d.Comments = comments
+	return d
+}
+
+// promptParentID is the issue's dotted parent, else the target of its
+// parent-child dependency.
+func promptParentID(iss *data.Issue) string {
+	if id := iss.ParentID(); id != "" {
+		return id
+	}
+	for _, dep := range iss.Dependencies {
+		if dep.Type == "parent-child" {
+			return dep.DependsOnID
+		}
+	}
+	return ""
+}
+
+var promptFuncs = template.FuncMap{
+	"priority": data.PriorityLabel,
+	"join":     strings.Join,
+	"trim":     strings.TrimSpace,
+}
+
+// RenderPrompt executes the template text over d.
+func RenderPrompt(text string, d PromptData) (string, error) {
+	t, err := template.New("prompt").Funcs(promptFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, d); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// PromptsDir returns where a project keeps its prompt templates:
+// MG_PROMPTS if set, otherwise .mardi-gras/prompts under projectDir.
+func PromptsDir(projectDir string) string {
+	if p := os.Getenv("MG_PROMPTS"); p != "" {
+		return p
+	}
+	if projectDir == "" {
+		return ""
+	}
+	return filepath.Join(projectDir, ".mardi-gras", "prompts")
+}
+
+// PromptTemplateFor picks the template for issue from the project's prompts
+// directory: label-<label>.tmpl for the first of its labels that has one,
+// then type-<issue_type>.tmpl, then default.tmpl, then the built-in
+// DefaultPromptTemplate. It returns the template's name and text.
+func PromptTemplateFor(projectDir string, issue data.Issue) (name, text string, err error) {
+	dir := PromptsDir(projectDir)
+	if dir == "" {
+		return DefaultPromptName, DefaultPromptTemplate, nil
+	}
+	candidates := make([]string, 0, len(issue.Labels)+2)
+	for _, l := range issue.Labels {
+		candidates = append(candidates, "label-"+promptFileName(l)+".tmpl")
+	}
+	if issue.IssueType != "" {
+		candidates = append(candidates, "type-"+promptFileName(string(issue.IssueType))+".tmpl")
+	}
+	candidates = append(candidates, "default.tmpl")
+	for _, c := range candidates {
+		raw, err := os.ReadFile(filepath.Join(dir, c))
+		if err == nil {
+			return c, string(raw), nil
+		}
+		if !os.IsNotExist(err) {
+			return c, "", fmt.Errorf("prompt template %s: %w", c, err)
+		}
+	}
+	return DefaultPromptName, DefaultPromptTemplate, nil
+}
+
+// promptFileName makes a label or type safe to use in a file name.
+func promptFileName(s string) string {
+	return strings.NewReplacer("/", "-", `\`, "-").Replace(strings.ToLower(s))
+}
+
+// BuildPrompt composes the initial prompt for an agent session given a
+// selected issue and its evaluated dependencies, using the built-in
+// template.
+func BuildPrompt(issue data.Issue, deps data.DepEval, issueMap map[string]*data.Issue) string {
+	// The built-in template is covered by tests and cannot fail to render.
+	out, _ := RenderPrompt(DefaultPromptTemplate, NewPromptData(issue, deps, issueMap, nil))
+	return out
+}
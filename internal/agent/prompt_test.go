@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+func TestPromptTemplateForPicksLabelThenTypeThenDefault(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MG_PROMPTS", dir)
+	issue := data.Issue{ID: "mg-1", IssueType: data.TypeBug, Labels: []string{"backend", "ui/web"}}
+
+	if name, text, err := PromptTemplateFor("", issue); err != nil || name != DefaultPromptName || text != DefaultPromptTemplate {
+		t.Fatalf("empty dir = %q, %v; want the built-in", name, err)
+	}
+	for _, f := range []string{"default.tmpl", "type-bug.tmpl", "label-ui-web.tmpl"} {
+		if err := os.WriteFile(filepath.Join(dir, f), []byte(f), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		name, text, err := PromptTemplateFor("", issue)
+		if err != nil || name != f || text != f {
+			t.Fatalf("after adding %s: got %q (%q), %v", f, name, text, err)
+		}
+	}
+}
+
+func TestRenderPromptTemplateData(t *testing.T) {
+	issues := []data.Issue{
+		{ID: "mg-1", Title: "Epic", IssueType: data.TypeEpic},
+		{ID: "mg-1.1", Title: "First", Status: data.StatusClosed},
+		{ID: "mg-1.2", Title: "Second", Metadata: map[string]any{"area": "parser"}},
+		{ID: "mg-9", Title: "Adopted", Dependencies: []data.Dependency{{IssueID: "mg-9", DependsOnID: "mg-1", Type: "parent-child"}}},
+	}
+	issueMap := data.BuildIssueMap(issues)
+	issue := *issueMap["mg-1.2"]
+	var comments []PromptComment
+	for i := range 7 {
+		comments = append(comments, PromptComment{Author: "ann", Body: string(rune('a' + i))})
+	}
+	d := NewPromptData(issue, issue.EvaluateDependencies(issueMap, data.DefaultBlockingTypes), issueMap, comments)
+
+	tmpl := `{{.Parent.Title}}|{{range .Siblings}}{{.ID}} {{end}}|{{range .Comments}}{{.Body}}{{end}}|{{index .Metadata "area"}}`
+	got, err := RenderPrompt(tmpl, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Epic|mg-1.1 mg-9 |cdefg|parser"; got != want {
+		t.Fatalf("rendered %q, want %q", got, want)
+	}
+
+	if _, err := RenderPrompt("{{.Issue.Nope}}", d); err == nil {
+		t.Error("unknown field should fail to render")
+	}
+	if _, err := RenderPrompt("{{if}}", d); err == nil || !strings.Contains(err.Error(), "if") {
+		t.Errorf("bad template error = %v", err)
+	}
+}
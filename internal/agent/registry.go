@@ -0,0 +1,338 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/config"
+)
+
+// Prompt-passing styles: how a runtime receives the prompt.
+const (
+	// PromptArg substitutes the prompt for {prompt} in the argv template,
+	// or appends it as the last argument when the template has none.
+	PromptArg = "arg"
+	// PromptStdin feeds the prompt on the agent's standard input.
+	PromptStdin = "stdin"
+	// PromptFile writes the prompt to a temp file and substitutes its path
+	// for {prompt_file}.
+	PromptFile = "file"
+)
+
+// detectTimeout bounds a runtime's detect command.
+const detectTimeout = 2 * time.Second
+
+// RuntimeSpec declares how to find and launch one agent CLI. Args, TmuxArgs
+// and Resume are argv templates following the binary; {prompt},
+// {prompt_file} and {project_dir} are replaced at launch.
+type RuntimeSpec struct {
+	Name    string   `json:"name"`
+	Label   string   `json:"label,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+	Binary  string   `json:"binary"`
+	// Detect is an optional command that must exit zero for the runtime
+	// to count as installed; by default finding Binary on PATH is enough.
+	Detect []string `json:"detect,omitempty"`
+	Args   []string `json:"args,omitempty"`
+	// TmuxArgs is used when launching into a tmux pane; empty means Args.
+	TmuxArgs []string `json:"tmux_args,omitempty"`
+	// Resume continues the runtime's most recent session; empty means the
+	// runtime has no resume.
+	Resume []string `json:"resume,omitempty"`
+	Prompt string   `json:"prompt,omitempty"`
+	// MCP, when set, runs the runtime in-process over MCP rather than in a
+	// terminal; see MCPSpec.
+	MCP *MCPSpec `json:"mcp,omitempty"`
+}
+
+// DisplayLabel is the runtime's Label, or its Name when it has none.
+func (s RuntimeSpec) DisplayLabel() string {
+	if s.Label != "" {
+		return s.Label
+	}
+	return s.Name
+}
+
+// Installed reports whether the runtime's binary is on PATH and its detect
+// command, if any, succeeds.
+func (s RuntimeSpec) Installed() bool {
+	if _, err := exec.LookPath(s.Binary); err != nil {
+		return false
+	}
+	if len(s.Detect) == 0 {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), detectTimeout)
+	defer cancel()
+	return exec.CommandContext(ctx, s.Detect[0], s.Detect[1:]...).Run() == nil
+}
+
+// Argv expands the one-shot (or, with tmux, the tmux pane) template into a
+// full argv, binary first. stdin is the prompt when the runtime reads it on
+// standard input. A PromptFile runtime gets the prompt written to a temp
+// file, and the argv is wrapped in a shell that removes it once the agent
+// exits.
+func (s RuntimeSpec) Argv(prompt, projectDir string, tmux bool) (argv []string, stdin string, err error) {
+	tmpl := s.Args
+	if tmux && len(s.TmuxArgs) > 0 {
+		tmpl = s.TmuxArgs
+	}
+	vars := map[string]string{"{project_dir}": projectDir}
+	switch s.Prompt {
+	case "", PromptArg:
+		vars["{prompt}"] = prompt
+		if !slices.ContainsFunc(tmpl, func(a string) bool { return strings.Contains(a, "{prompt}") }) {
+			tmpl = append(slices.Clone(tmpl), "{prompt}")
+		}
+	case PromptStdin:
+		stdin = prompt
+	case PromptFile:
+		path, err := writePromptFile(prompt)
+		if err != nil {
+			return nil, "", fmt.Errorf("runtime %s: %w", s.Name, err)
+		}
+		vars["{prompt_file}"] = path
+	default:
+		return nil, "", fmt.Errorf("runtime %s: unknown prompt style %q", s.Name, s.Prompt)
+	}
+	argv = append([]string{s.Binary}, expand(tmpl, vars)...)
+	if path, ok := vars["{prompt_file}"]; ok {
+		argv = removingPromptFile(path, `"$@"`, argv)
+	}
+	return argv, stdin, nil
+}
+
+// ResumeArgv expands the resume template, or returns nil when the runtime
+// has none.
+func (s RuntimeSpec) ResumeArgv(projectDir string) []string {
+	if len(s.Resume) == 0 {
+		return nil
+	}
+	return append([]string{s.Binary}, expand(s.Resume, map[string]string{"{project_dir}": projectDir})...)
+}
+
+// writePromptFile saves prompt to a new temp file and returns its path.
+func writePromptFile(prompt string) (string, error) {
+	f, err := os.CreateTemp("", "mg-prompt-*.md")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.WriteString(prompt)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return f.Name(), err
+}
+
+// removingPromptFile wraps argv in a shell that runs it with run (argv is
+// "$@", the prompt file "$f") and removes the prompt file at path once it
+// exits, or when the shell is interrupted.
+func removingPromptFile(path, run string, argv []string) []string {
+	script := `f=$1; shift; trap 'rm -f "$f"' EXIT HUP INT TERM; ` + run
+	return append([]string{"sh", "-c", script, "sh", path}, argv...)
+}
+
+// expand substitutes vars into each template argument in a single pass, so
+// a value that contains another placeholder (a prompt quoting
+// "{project_dir}") is left as written.
+func expand(tmpl []string, vars map[string]string) []string {
+	pairs := make([]string, 0, 2*len(vars))
+	for k, v := range vars {
+		pairs = append(pairs, k, v)
+	}
+	r := strings.NewReplacer(pairs...)
+	out := make([]string, len(tmpl))
+	for i, a := range tmpl {
+		out[i] = r.Replace(a)
+	}
+	return out
+}
+
+// builtinRuntimes are the runtimes mg knows out of the box, in default
+// detection order.
+//
+// Codex defaults to sandboxed execution with interactive approval prompts,
+// which would block unattended agent sessions. We pass --sandbox
+// workspace-write and -a on-request to match the zero-friction posture
+// Claude and Cursor have out of the box; a runtimes file can override it.
+// In tmux, --no-alt-screen preserves scrollback inside the split pane.
+var builtinRuntimes = []RuntimeSpec{
+	{
+		Name:     string(RuntimeClaude),
+		Label:    "Claude Code",
+		Binary:   "claude",
+		Args:     []string{"{prompt}"},
+		TmuxArgs: []string{"--teammate-mode", "tmux", "{prompt}"},
+		Resume:   []string{"--continue"},
+	},
+	{
+		Name:    string(RuntimeCursor),
+		Label:   "Cursor",
+		Aliases: []string{"cursor"},
+		Binary:  "cursor-agent",
+		Args:    []string{"-f", "-p", "{prompt}"},
+	},
+	{
+		Name:     string(RuntimeCodex),
+		Label:    "Codex",
+		Binary:   "codex",
+		Args:     []string{"--sandbox", "workspace-write", "-a", "on-request", "-C", "{project_dir}", "{prompt}"},
+		TmuxArgs: []string{"--no-alt-screen", "--sandbox", "workspace-write", "-a", "on-request", "-C", "{project_dir}", "{prompt}"},
+		Resume:   []string{"resume", "--last", "--no-alt-screen", "-C", "{project_dir}"},
+	},
+}
+
+// Registry is the set of known runtimes in detection order. It remembers
+// which runtimes it found installed, so a long-lived Registry runs each
+// detect command once; load a new one to pick up changes.
+type Registry struct {
+	Runtimes []RuntimeSpec
+
+	mu        sync.Mutex
+	installed map[string]bool // by runtime name
+}
+
+// DefaultRegistry returns a registry holding only the built-in runtimes.
+func DefaultRegistry() *Registry {
+	return &Registry{Runtimes: slices.Clone(builtinRuntimes)}
+}
+
+// RuntimesPath returns the runtimes file path: MG_RUNTIMES if set, otherwise
+// mardi-gras/runtimes.json under the user config directory.
+func RuntimesPath() string {
+	return config.Path("MG_RUNTIMES", "runtimes.json")
+}
+
+// LoadRegistry returns the built-in runtimes merged with those in the
+// runtimes file at path. A runtime named like a built-in replaces it in
+// place; new runtimes are detected after the built-ins, in file order. A
+// missing file yields the built-ins alone. On error the built-ins are still
+// returned, so a bad file never leaves mg without an agent.
+func LoadRegistry(path string) (*Registry, error) {
+	reg := DefaultRegistry()
+	if path == "" {
+		return reg, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return reg, nil
+		}
+		return reg, fmt.Errorf("runtimes %s: %w", path, err)
+	}
+	var file struct {
+		Runtimes []RuntimeSpec `json:"runtimes"`
+	}
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return reg, fmt.Errorf("runtimes %s: %w", path, err)
+	}
+	merged := DefaultRegistry()
+	for i, s := range file.Runtimes {
+		s.Name = strings.ToLower(strings.TrimSpace(s.Name))
+		if s.Name == "" {
+			return reg, fmt.Errorf("runtimes %s: runtime %d: missing name", path, i+1)
+		}
+		if s.Binary == "" {
+			s.Binary = s.Name
+		}
+		switch s.Prompt {
+		case "", PromptArg, PromptStdin, PromptFile:
+		default:
+			return reg, fmt.Errorf("runtimes %s: %s: unknown prompt style %q", path, s.Name, s.Prompt)
+		}
+		if s.MCP != nil && (len(s.MCP.Command) == 0 || s.MCP.Tool == "") {
+			return reg, fmt.Errorf("runtimes %s: %s: mcp needs a command and a tool", path, s.Name)
+		}
+		if j := slices.IndexFunc(merged.Runtimes, func(r RuntimeSpec) bool { return r.Name == s.Name }); j >= 0 {
+			merged.Runtimes[j] = s
+		} else {
+			merged.Runtimes = append(merged.Runtimes, s)
+		}
+	}
+	return merged, nil
+}
+
+// loadRegistry reads the runtimes file afresh for the package-level
+// helpers; a bad file falls back to the built-ins. Long-lived callers hold
+// a Registry instead.
+func loadRegistry() *Registry {
+	reg, _ := LoadRegistry(RuntimesPath())
+	return reg
+}
+
+// Lookup finds a runtime by name or alias, case-insensitively. A nil
+// Registry holds the built-ins.
+func (r *Registry) Lookup(name string) (RuntimeSpec, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return RuntimeSpec{}, false
+	}
+	runtimes := builtinRuntimes
+	if r != nil {
+		runtimes = r.Runtimes
+	}
+	for _, s := range runtimes {
+		if s.Name == name || slices.Contains(s.Aliases, name) {
+			return s, true
+		}
+	}
+	return RuntimeSpec{}, false
+}
+
+// Detect returns the preferred runtime if it is installed, otherwise the
+// first installed runtime in registry order, or "" if none is.
+func (r *Registry) Detect(pref string) Runtime {
+	if s, ok := r.Lookup(pref); ok && r.isInstalled(s) {
+		return Runtime(s.Name)
+	}
+	for _, s := range r.Runtimes {
+		if r.isInstalled(s) {
+			return Runtime(s.Name)
+		}
+	}
+	return ""
+}
+
+// Preferred is Detect with MG_AGENT_RUNTIME as the preference.
+func (r *Registry) Preferred() Runtime {
+	return r.Detect(os.Getenv("MG_AGENT_RUNTIME"))
+}
+
+// isInstalled is s.Installed, checked once per registry.
+func (r *Registry) isInstalled(s RuntimeSpec) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ok, seen := r.installed[s.Name]; seen {
+		return ok
+	}
+	ok := s.Installed()
+	if r.installed == nil {
+		r.installed = make(map[string]bool)
+	}
+	r.installed[s.Name] = ok
+	return ok
+}
+
+// Label returns a display name for the runtime, or "unknown".
+func (r *Registry) Label(rt Runtime) string {
+	if s, ok := r.Lookup(string(rt)); ok {
+		return s.DisplayLabel()
+	}
+	return "unknown"
+}
+
+// launchSpec is rt's entry, or claude's when rt is unknown.
+func (r *Registry) launchSpec(rt Runtime) RuntimeSpec {
+	if s, ok := r.Lookup(string(rt)); ok {
+		return s
+	}
+	s, _ := r.Lookup(string(RuntimeClaude))
+	return s
+}
@@ -0,0 +1,188 @@
+package agent
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeRuntimes(t *testing.T, raw string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "runtimes.json")
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadRegistryMergesUserRuntimes(t *testing.T) {
+	path := writeRuntimes(t, `{"runtimes": [
+		{"name": "aider", "label": "Aider", "args": ["--yes-always", "--message", "{prompt}"]},
+		{"name": "codex", "label": "Codex (yolo)", "binary": "codex", "args": ["--full-auto", "{prompt}"]}
+	]}`)
+	reg, err := LoadRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range reg.Runtimes {
+		names = append(names, s.Name)
+	}
+	if want := []string{"claude", "cursor-agent", "codex", "aider"}; !slices.Equal(names, want) {
+		t.Fatalf("order = %v, want %v", names, want)
+	}
+	aider, ok := reg.Lookup("Aider")
+	if !ok || aider.Binary != "aider" {
+		t.Fatalf("aider = %+v, %v (binary should default to the name)", aider, ok)
+	}
+	codex, _ := reg.Lookup("codex")
+	argv, _, err := codex.Argv("fix it", "/tmp/p", true)
+	if err != nil || !slices.Equal(argv, []string{"codex", "--full-auto", "fix it"}) {
+		t.Fatalf("overridden codex argv = %v, %v (tmux falls back to args)", argv, err)
+	}
+	if codex.ResumeArgv("/tmp/p") != nil {
+		t.Errorf("override without resume should drop the built-in resume")
+	}
+
+	for _, bad := range []string{
+		`{"runtimes": [{"binary": "x"}]}`,
+		`{"runtimes": [{"name": "x", "prompt": "carrier-pigeon"}]}`,
+		`not json`,
+	} {
+		reg, err := LoadRegistry(writeRuntimes(t, bad))
+		if err == nil {
+			t.Errorf("LoadRegistry(%s) should fail", bad)
+		}
+		if len(reg.Runtimes) != len(builtinRuntimes) {
+			t.Errorf("LoadRegistry(%s) should fall back to the built-ins, got %d", bad, len(reg.Runtimes))
+		}
+	}
+
+	reg, err = LoadRegistry(filepath.Join(t.TempDir(), "none.json"))
+	if err != nil || len(reg.Runtimes) != len(builtinRuntimes) {
+		t.Fatalf("missing file = %d runtimes, %v", len(reg.Runtimes), err)
+	}
+}
+
+func TestRuntimeSpecArgvPromptStyles(t *testing.T) {
+	appended := RuntimeSpec{Name: "goose", Binary: "goose", Args: []string{"run", "-t"}}
+	argv, stdin, err := appended.Argv("hi", "/p", false)
+	if err != nil || stdin != "" || !slices.Equal(argv, []string{"goose", "run", "-t", "hi"}) {
+		t.Fatalf("arg style = %v, %q, %v", argv, stdin, err)
+	}
+
+	piped := RuntimeSpec{Name: "wrap", Binary: "wrap", Args: []string{"--cwd={project_dir}"}, Prompt: PromptStdin}
+	argv, stdin, err = piped.Argv("hi", "/p", false)
+	if err != nil || stdin != "hi" || !slices.Equal(argv, []string{"wrap", "--cwd=/p"}) {
+		t.Fatalf("stdin style = %v, %q, %v", argv, stdin, err)
+	}
+
+	filed := RuntimeSpec{Name: "oc", Binary: "cat", Args: []string{"{prompt_file}"}, Prompt: PromptFile}
+	argv, _, err = filed.Argv("hello", "/p", false)
+	if err != nil || len(argv) != 7 || argv[0] != "sh" || argv[5] != "cat" || argv[6] != argv[4] {
+		t.Fatalf("file style = %v, %v", argv, err)
+	}
+	path := argv[4]
+	t.Cleanup(func() { _ = os.Remove(path) })
+	out, err := exec.Command(argv[0], argv[1:]...).Output()
+	if err != nil || string(out) != "hello" {
+		t.Fatalf("agent read %q, %v; want the prompt", out, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("prompt file should be removed once the agent exits, stat = %v", err)
+	}
+}
+
+func TestRemovingPromptFileKeepsExitStatus(t *testing.T) {
+	path, err := writePromptFile("hi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Remove(path) })
+	argv := removingPromptFile(path, `"$@" < "$f"`, []string{"sh", "-c", `read -r line; [ "$line" = hi ] && exit 3`})
+	err = exec.Command(argv[0], argv[1:]...).Run()
+	var exit *exec.ExitError
+	if !errors.As(err, &exit) || exit.ExitCode() != 3 {
+		t.Fatalf("run = %v, want the agent's exit status 3", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("prompt file should be removed, stat = %v", err)
+	}
+}
+
+func TestExpandSinglePass(t *testing.T) {
+	got := expand([]string{"--cwd={project_dir}", "{prompt}"}, map[string]string{
+		"{project_dir}": "/p",
+		"{prompt}":      "write {project_dir}/notes.md",
+	})
+	if !slices.Equal(got, []string{"--cwd=/p", "write {project_dir}/notes.md"}) {
+		t.Errorf("expand = %q, want the prompt left as written", got)
+	}
+}
+
+func TestDetectRuntimeFromRuntimesFile(t *testing.T) {
+	withFakePath(t, "claude", "aider", "goose")
+	dir := os.Getenv("PATH")
+	if err := os.WriteFile(filepath.Join(dir, "nope"), []byte("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MG_RUNTIMES", writeRuntimes(t, `{"runtimes": [
+		{"name": "aider", "label": "Aider", "aliases": ["ai"], "args": ["--message", "{prompt}"]},
+		{"name": "goose", "detect": ["nope"]}
+	]}`))
+
+	t.Setenv("MG_AGENT_RUNTIME", "ai")
+	if got := DetectRuntime(); got != "aider" {
+		t.Fatalf("alias should select aider, got %q", got)
+	}
+	if got := Runtime("aider").RuntimeLabel(); got != "Aider" {
+		t.Errorf("label = %q", got)
+	}
+	cmd := Command("do the thing", "/tmp/project")
+	if want := []string{"aider", "--message", "do the thing"}; !slices.Equal(cmd.Args, want) || cmd.Dir != "/tmp/project" {
+		t.Errorf("Command = %v in %q, want %v", cmd.Args, cmd.Dir, want)
+	}
+
+	t.Setenv("MG_AGENT_RUNTIME", "goose")
+	if got := DetectRuntime(); got != RuntimeClaude {
+		t.Errorf("failing detect command should fall back to claude, got %q", got)
+	}
+}
+
+func TestRegistryRunsDetectOnce(t *testing.T) {
+	withFakePath(t, "aider")
+	dir := os.Getenv("PATH")
+	count := filepath.Join(t.TempDir(), "count")
+	script := "#!/bin/sh\necho x >> " + count + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "probe"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := writeRuntimes(t, `{"runtimes": [{"name": "aider", "detect": ["probe"]}]}`)
+	t.Setenv("MG_AGENT_RUNTIME", "aider")
+	runs := func() int {
+		raw, _ := os.ReadFile(count)
+		return len(raw) / 2
+	}
+
+	reg, err := LoadRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		if got := reg.Preferred(); got != "aider" {
+			t.Fatalf("Preferred = %q", got)
+		}
+	}
+	if n := runs(); n != 1 {
+		t.Errorf("detect ran %d times on one registry, want 1", n)
+	}
+
+	reg, _ = LoadRegistry(path)
+	reg.Preferred()
+	if n := runs(); n != 2 {
+		t.Errorf("a reloaded registry should detect again, ran %d times", n)
+	}
+}
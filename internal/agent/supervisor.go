@@ -0,0 +1,208 @@
+package agent
+
+import (
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+// DefaultMaxAgents is how many supervised agents run at once unless
+// MG_MAX_AGENTS says otherwise.
+const DefaultMaxAgents = 3
+
+// paneGrace is how long a supervised tmux agent may go unseen by the pane
+// poll before its missing pane counts as an exit. It covers the poll that
+// was already in flight when the pane was split.
+const paneGrace = 15 * time.Second
+
+// Backend is where a supervised agent runs.
+type Backend string
+
+const (
+	BackendTmux  Backend = "tmux"  // a tagged tmux pane
+	BackendCodex Backend = "codex" // an MCP session inside mg (codex or another MCP runtime)
+)
+
+// MaxAgents returns the supervisor's concurrency limit from MG_MAX_AGENTS
+// (set by --max-agents), falling back to DefaultMaxAgents.
+func MaxAgents() int {
+	if n, err := strconv.Atoi(os.Getenv("MG_MAX_AGENTS")); err == nil && n > 0 {
+		return n
+	}
+	return DefaultMaxAgents
+}
+
+// Supervised is one agent the Supervisor has started.
+type Supervised struct {
+	IssueID  string
+	Backend  Backend
+	Started  time.Time
+	Launched bool // false while the issue is being claimed and launched
+	seen     bool // its tmux pane has shown up in a poll
+}
+
+// Supervisor is a small local dispatcher for machines without an
+// orchestrator. It queues issues and keeps at most Limit agents running,
+// handing out the next queued issue whenever a slot frees up. It only keeps
+// the books: the caller claims and launches what Next returns and reports
+// launches, pane polls and issue changes back.
+type Supervisor struct {
+	Limit   int
+	queue   []string
+	running map[string]*Supervised
+}
+
+// NewSupervisor returns an empty Supervisor running at most limit agents.
+func NewSupervisor(limit int) *Supervisor {
+	if limit < 1 {
+		limit = 1
+	}
+	return &Supervisor{Limit: limit, running: make(map[string]*Supervised)}
+}
+
+// Enqueue appends issues that aren't already queued or running and returns
+// how many were added.
+func (s *Supervisor) Enqueue(ids ...string) int {
+	added := 0
+	for _, id := range ids {
+		if _, running := s.running[id]; running || slices.Contains(s.queue, id) {
+			continue
+		}
+		s.queue = append(s.queue, id)
+		added++
+	}
+	return added
+}
+
+// Remove drops a queued issue, reporting whether it was queued.
+func (s *Supervisor) Remove(id string) bool {
+	i := slices.Index(s.queue, id)
+	if i < 0 {
+		return false
+	}
+	s.queue = slices.Delete(s.queue, i, i+1)
+	return true
+}
+
+// Clear empties the queue and returns how many issues it held. Running
+// agents are left alone.
+func (s *Supervisor) Clear() int {
+	n := len(s.queue)
+	s.queue = nil
+	return n
+}
+
+// Queue returns the queued issue IDs in launch order.
+func (s *Supervisor) Queue() []string {
+	return slices.Clone(s.queue)
+}
+
+// Position returns the issue's 1-based place in the queue, or 0.
+func (s *Supervisor) Position(id string) int {
+	return slices.Index(s.queue, id) + 1
+}
+
+// Running returns the supervised agents, oldest first.
+func (s *Supervisor) Running() []Supervised {
+	out := make([]Supervised, 0, len(s.running))
+	for _, r := range s.running {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Started.Equal(out[j].Started) {
+			return out[i].Started.Before(out[j].Started)
+		}
+		return out[i].IssueID < out[j].IssueID
+	})
+	return out
+}
+
+// IsRunning reports whether the issue has a supervised agent.
+func (s *Supervisor) IsRunning(id string) bool {
+	_, ok := s.running[id]
+	return ok
+}
+
+// Next pops as many queued issues as there are free slots and marks them
+// running on backend. The caller must claim and launch each one, then call
+// Launched, or Finish if that fails.
+func (s *Supervisor) Next(backend Backend, now time.Time) []string {
+	free := s.Limit - len(s.running)
+	if free <= 0 || len(s.queue) == 0 {
+		return nil
+	}
+	n := min(free, len(s.queue))
+	ids := slices.Clone(s.queue[:n])
+	s.queue = slices.Delete(s.queue, 0, n)
+	for _, id := range ids {
+		s.running[id] = &Supervised{IssueID: id, Backend: backend, Started: now}
+	}
+	return ids
+}
+
+// Launched records that the issue's agent has started.
+func (s *Supervisor) Launched(id string) {
+	if r, ok := s.running[id]; ok {
+		r.Launched = true
+	}
+}
+
+// Finish frees the issue's slot, reporting whether it was supervised.
+func (s *Supervisor) Finish(id string) bool {
+	if _, ok := s.running[id]; !ok {
+		return false
+	}
+	delete(s.running, id)
+	return true
+}
+
+// ObservePanes updates tmux agents from a poll of the tagged panes (issueID
+// -> pane ID) and finishes those whose pane has exited. It returns the
+// finished issue IDs.
+func (s *Supervisor) ObservePanes(panes map[string]string, now time.Time) []string {
+	var done []string
+	for id, r := range s.running {
+		if r.Backend != BackendTmux || !r.Launched {
+			continue
+		}
+		if _, ok := panes[id]; ok {
+			r.seen = true
+			continue
+		}
+		if r.seen || now.Sub(r.Started) > paneGrace {
+			done = append(done, id)
+		}
+	}
+	for _, id := range done {
+		delete(s.running, id)
+	}
+	sort.Strings(done)
+	return done
+}
+
+// ObserveIssues finishes agents whose issue has closed and drops closed
+// issues from the queue. It returns the finished issue IDs.
+func (s *Supervisor) ObserveIssues(issues []data.Issue) []string {
+	closed := make(map[string]bool)
+	for _, iss := range issues {
+		if iss.Status == data.StatusClosed {
+			closed[iss.ID] = true
+		}
+	}
+	s.queue = slices.DeleteFunc(s.queue, func(id string) bool { return closed[id] })
+	var done []string
+	for id := range s.running {
+		if closed[id] {
+			done = append(done, id)
+		}
+	}
+	for _, id := range done {
+		delete(s.running, id)
+	}
+	sort.Strings(done)
+	return done
+}
@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+func TestSupervisorRespectsLimit(t *testing.T) {
+	s := NewSupervisor(2)
+	now := time.Now()
+	if added := s.Enqueue("a", "b", "c", "a"); added != 3 {
+		t.Fatalf("Enqueue added %d, want 3 (duplicates skipped)", added)
+	}
+	if got := s.Next(BackendTmux, now); !slices.Equal(got, []string{"a", "b"}) {
+		t.Fatalf("Next = %v, want [a b]", got)
+	}
+	if got := s.Next(BackendTmux, now); got != nil {
+		t.Fatalf("Next with no free slot = %v", got)
+	}
+	if s.Enqueue("a") != 0 {
+		t.Error("a running issue shouldn't be queued again")
+	}
+	if s.Position("c") != 1 || s.Position("a") != 0 {
+		t.Errorf("positions: c=%d a=%d", s.Position("c"), s.Position("a"))
+	}
+
+	if !s.Finish("a") || s.Finish("a") {
+		t.Fatal("Finish should free a once")
+	}
+	if got := s.Next(BackendTmux, now); !slices.Equal(got, []string{"c"}) {
+		t.Fatalf("Next after finish = %v, want [c]", got)
+	}
+	if len(s.Queue()) != 0 || len(s.Running()) != 2 {
+		t.Fatalf("queue=%v running=%v", s.Queue(), s.Running())
+	}
+}
+
+func TestSupervisorObservePanes(t *testing.T) {
+	s := NewSupervisor(3)
+	start := time.Now()
+	s.Enqueue("seen", "slow", "codex")
+	s.Next(BackendTmux, start)
+	s.Launched("seen")
+	s.Launched("slow")
+	s.running["codex"].Backend = BackendCodex
+	s.Launched("codex")
+
+	// A pane that hasn't shown up yet is given a grace period.
+	if done := s.ObservePanes(map[string]string{"seen": "%1"}, start.Add(time.Second)); done != nil {
+		t.Fatalf("early poll finished %v", done)
+	}
+	done := s.ObservePanes(map[string]string{}, start.Add(2*time.Second))
+	if !slices.Equal(done, []string{"seen"}) {
+		t.Fatalf("seen pane exit: finished %v", done)
+	}
+	done = s.ObservePanes(map[string]string{}, start.Add(paneGrace+time.Second))
+	if !slices.Equal(done, []string{"slow"}) {
+		t.Fatalf("after grace: finished %v", done)
+	}
+	if !s.IsRunning("codex") {
+		t.Error("codex sessions aren't tracked by panes")
+	}
+}
+
+func TestSupervisorObserveIssues(t *testing.T) {
+	s := NewSupervisor(1)
+	s.Enqueue("a", "b", "c")
+	s.Next(BackendCodex, time.Now())
+	done := s.ObserveIssues([]data.Issue{
+		{ID: "a", Status: data.StatusClosed},
+		{ID: "b", Status: data.StatusClosed},
+		{ID: "c", Status: data.StatusOpen},
+	})
+	if !slices.Equal(done, []string{"a"}) || !slices.Equal(s.Queue(), []string{"c"}) {
+		t.Fatalf("finished %v, queue %v", done, s.Queue())
+	}
+}
+
+func TestMaxAgents(t *testing.T) {
+	t.Setenv("MG_MAX_AGENTS", "")
+	if MaxAgents() != DefaultMaxAgents {
+		t.Errorf("default = %d", MaxAgents())
+	}
+	t.Setenv("MG_MAX_AGENTS", "5")
+	if MaxAgents() != 5 {
+		t.Errorf("MG_MAX_AGENTS=5 gave %d", MaxAgents())
+	}
+	t.Setenv("MG_MAX_AGENTS", "0")
+	if MaxAgents() != DefaultMaxAgents {
+		t.Errorf("MG_MAX_AGENTS=0 gave %d", MaxAgents())
+	}
+}
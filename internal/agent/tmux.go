@@ -25,26 +25,63 @@ func WindowName(issueID string) string {
 	return "mg-" + issueID
 }
 
-// LaunchInTmux opens a new tmux pane running claude to the right of the current pane.
+// LaunchInTmux opens a new tmux pane running the detected agent runtime to
+// the right of the current pane.
 func LaunchInTmux(prompt, projectDir, issueID string) (string, error) {
-	paneName := WindowName(issueID)
-	// Build agent command based on detected runtime
-	var agentArgs []string
-	switch DetectRuntime() {
-	case RuntimeCursor:
-		agentArgs = []string{"cursor-agent", "-f", "-p", prompt}
-	case RuntimeCodex:
-		// --no-alt-screen preserves tmux scrollback inside the split pane.
-		agentArgs = []string{"codex",
-			"--no-alt-screen",
-			"--sandbox", "workspace-write",
-			"-a", "on-request",
-			"-C", projectDir,
-			prompt}
-	default: // Claude Code
-		agentArgs = []string{"claude", "--teammate-mode", "tmux", prompt}
+	reg := loadRegistry()
+	return reg.LaunchInTmux(reg.Preferred(), prompt, projectDir, issueID)
+}
+
+// LaunchInTmux is the package-level LaunchInTmux for runtime rt.
+func (r *Registry) LaunchInTmux(rt Runtime, prompt, projectDir, issueID string) (string, error) {
+	spec := r.launchSpec(rt)
+	agentArgs, stdin, err := spec.Argv(prompt, projectDir, true)
+	if err != nil {
+		return "", err
+	}
+	if stdin != "" {
+		// tmux can't pipe into the pane, so hand the prompt over in a file
+		// and redirect it from a shell, which removes it afterwards.
+		path, err := writePromptFile(stdin)
+		if err != nil {
+			return "", fmt.Errorf("runtime %s: %w", spec.Name, err)
+		}
+		agentArgs = removingPromptFile(path, `"$@" < "$f"`, agentArgs)
+	}
+
+	paneID, err := splitAgentPane(projectDir, agentArgs)
+	if err != nil {
+		return "", err
+	}
+
+	// Tag the pane with our naming convention so we can find it later.
+	// tmux doesn't name panes, but we can set an environment variable.
+	_ = exec.Command("tmux", "set-option", "-p", "-t", paneID,
+		"@mg_agent", WindowName(issueID)).Run()
+
+	return paneID, nil
+}
+
+// LaunchResumeInTmux opens a new tmux pane resuming the detected runtime's
+// last session, rooted at projectDir. Returns the pane ID.
+func LaunchResumeInTmux(projectDir string) (string, error) {
+	reg := loadRegistry()
+	return reg.LaunchResumeInTmux(reg.Preferred(), projectDir)
+}
+
+// LaunchResumeInTmux is the package-level LaunchResumeInTmux for runtime rt.
+func (r *Registry) LaunchResumeInTmux(rt Runtime, projectDir string) (string, error) {
+	spec, _ := r.Lookup(string(rt))
+	argv := spec.ResumeArgv(projectDir)
+	if argv == nil {
+		return "", fmt.Errorf("%s has no resume command", r.Label(rt))
 	}
+	return splitAgentPane(projectDir, argv)
+}
 
+// splitAgentPane runs argv in a new pane to the right of the current one
+// and returns the new pane's ID.
+func splitAgentPane(projectDir string, argv []string) (string, error) {
 	tmuxArgs := []string{"split-window",
 		"-h",        // vertical split (pane to the right)
 		"-l", "60%", // agent gets 60% of width
@@ -53,20 +90,12 @@ func LaunchInTmux(prompt, projectDir, issueID string) (string, error) {
 		"-P", "-F", "#{pane_id}", // print the new pane ID
 		"--",
 	}
-	tmuxArgs = append(tmuxArgs, agentArgs...)
-	cmd := exec.Command("tmux", tmuxArgs...)
-	out, err := cmd.CombinedOutput()
+	tmuxArgs = append(tmuxArgs, argv...)
+	out, err := exec.Command("tmux", tmuxArgs...).CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("tmux split-window: %w", err)
 	}
-	paneID := strings.TrimSpace(string(out))
-
-	// Tag the pane with our naming convention so we can find it later.
-	// tmux doesn't name panes, but we can set an environment variable.
-	_ = exec.Command("tmux", "set-option", "-p", "-t", paneID,
-		"@mg_agent", paneName).Run()
-
-	return paneID, nil
+	return strings.TrimSpace(string(out)), nil
 }
 
 // ListAgentWindows returns a map of issueID -> paneID for all tmux panes
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+)
+
+// toggleAnalytics shows or hides the flow analytics overlay in place of the
+// detail pane. Opening it computes cycle and lead times and the cumulative
+// flow history from the loaded issues; it needs no orchestrator.
+func (m Model) toggleAnalytics() (tea.Model, tea.Cmd) {
+	m.showAnalytics = !m.showAnalytics
+	if !m.showAnalytics {
+		m.activPane = PaneParade
+		return m, nil
+	}
+	m.showGasTown = false
+	m.showProblems = false
+	m.showDoctor = false
+	m.showPlanner = false
+	m.showCodex = false
+	m.showCodexSessions = false
+	m.dismissCodexReply()
+	m.activPane = PaneDetail
+	m.refreshAnalytics()
+	if m.costHistory == nil {
+		return m, m.loadCostHistoryCmd()
+	}
+	return m, nil
+}
+
+// refreshAnalytics recomputes flow metrics and the cumulative flow history
+// from the loaded issues, and hands the view the recorded cost history.
+func (m *Model) refreshAnalytics() {
+	now := time.Now()
+	m.analytics.SetFlow(gastown.ComputeFlow(m.issues, now), now)
+	m.analytics.SetCFD(gastown.ComputeCFD(m.issues, m.blockingTypes, gastown.MaxCFDDays, now))
+	m.analytics.SetCostHistory(m.costHistory)
+}
+
+// analyticsFocused reports whether the flow analytics overlay owns key input.
+func (m Model) analyticsFocused() bool {
+	return m.showAnalytics && m.activPane == PaneDetail
+}
+
+// handleAnalyticsKey routes keys while the analytics overlay is focused. esc
+// closes it; navigation and the breakdown, metric and mode switches belong to
+// the view.
+func (m Model) handleAnalyticsKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd, bool) {
+	switch msg.String() {
+	case "esc":
+		m.showAnalytics = false
+		m.activPane = PaneParade
+		return m, nil, true
+	case "j", "k", "up", "down", "g", "G", "h", "l", "left", "right", "m", "v", "+", "=", "-":
+		var cmd tea.Cmd
+		m.analytics, cmd = m.analytics.Update(msg)
+		return m, cmd, true
+	}
+	return m, nil, false
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/matt-wright86/mardi-gras/internal/data"
+	"github.com/matt-wright86/mardi-gras/internal/views"
+)
+
+func TestToggleAnalyticsFocusesOverlay(t *testing.T) {
+	started := time.Now().Add(-2 * time.Hour)
+	closed := time.Now().Add(-time.Hour)
+	issues := []data.Issue{{ID: "mg-1", Status: data.StatusClosed, CreatedAt: started, StartedAt: &started, ClosedAt: &closed}}
+	m := Model{issues: issues, showDoctor: true}
+	m.analytics = views.NewAnalytics(80, 20)
+
+	next, _ := m.toggleAnalytics()
+	m = next.(Model)
+	if !m.showAnalytics || !m.analyticsFocused() || m.showDoctor {
+		t.Fatal("analytics should replace the doctor overlay and take focus")
+	}
+
+	next, _, handled := m.handleAnalyticsKey(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m = next.(Model); !handled || m.showAnalytics || m.activPane != PaneParade {
+		t.Error("esc should close analytics and return to the parade")
+	}
+}
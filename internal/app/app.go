@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -51,6 +52,14 @@ const (
 	changeIndicatorDuration = 30 * time.Second
 )
 
+const (
+	// activityFeedLimit is how many recent events the activity section shows.
+	activityFeedLimit = 20
+	// eventHistoryLimit is how much of the event log tail issue costs read
+	// for agent sessions.
+	eventHistoryLimit = 2000
+)
+
 // Model is the root BubbleTea model.
 type Model struct {
 	issues        []data.Issue
@@ -75,12 +84,16 @@ type Model struct {
 	spinner       spinner.Model // branded loading spinner (shown until ready)
 	agentAvail    bool
 	agentRuntime  agent.Runtime
+	registry      *agent.Registry // loaded once; reloaded from the palette
+	runtimesErr   error           // unreadable runtimes file; the built-ins still work
 	projectDir    string
 	inTmux        bool
 	activeAgents  map[string]string   // issueID -> tmux window name
 	gtEnv         gastown.Env         // Gas Town environment, read once at startup
 	driver        gastown.Driver      // Orchestrator seam; GTDriver today (gt CLI)
+	driverErr     error               // configured driver failed to start; driver is the gt fallback
 	townStatus    *gastown.TownStatus // Latest gt status, nil when unavailable
+	eventHistory  []gastown.Event     // Event log tail (newest first) for issue costs
 	gasTown       views.GasTown       // Gas Town control surface panel
 	showGasTown   bool                // Whether the Gas Town panel replaces detail
 
@@ -95,6 +108,10 @@ type Model struct {
 	changedAt    time.Time
 	prevIssueMap map[string]data.Status // issueID -> previous status for diffing
 
+	// Closed issues seen coming back open, oldest first, for scorecards
+	reopens           []gastown.Reopen
+	reopenHistoryPath string
+
 	// Focus mode
 	focusMode bool
 
@@ -158,10 +175,38 @@ type Model struct {
 	showDoctor bool
 	doctor     views.Doctor
 
+	// Session transcript overlay for a roster agent, shown in place of the
+	// Gas Town panel (t on an agent). Refreshed from the liveness tick.
+	showTranscript      bool
+	agentTranscript     views.AgentTranscript
+	transcriptInFlight  bool
+	lastTranscriptFetch time.Time
+
+	// Convoy timeline overlay (t on a convoy): members as bars on a time
+	// axis, shown in place of the Gas Town panel.
+	showConvoyTimeline bool
+	convoyGantt        views.ConvoyGantt
+
+	// Convoy planner overlay (P): proposes members from a query or root
+	// issues plus their blocking closure, shown in place of the detail pane.
+	showPlanner   bool
+	convoyPlanner views.ConvoyPlanner
+
+	// Flow analytics overlay (I): cycle and lead time distributions and aging
+	// WIP, shown in place of the detail pane.
+	showAnalytics bool
+	analytics     views.Analytics
+
 	// Codex MCP transcript overlay + per-issue session registry
 	showCodex       bool
 	codexTranscript views.CodexTranscript
 	codexSessions   map[string]*codexSession
+	codexShownID    string // issue whose transcript the overlay shows
+	codexStoreErr   error  // saved transcripts that failed to load
+
+	// Codex session list (S) for switching between transcripts
+	showCodexSessions bool
+	codexSessionList  views.CodexSessions
 
 	// Codex MCP follow-up reply input state. Activated by `r` while the
 	// transcript overlay is open and the prior turn is terminal.
@@ -180,10 +225,28 @@ type Model struct {
 	currentApproval  codexApprovalRequestMsg
 	pendingApprovals []codexApprovalRequestMsg
 
+	// Codex approval rules, applied before the modal, and the audit log of
+	// every approval decision.
+	approvalPolicy    *agent.ApprovalPolicy
+	approvalPolicyErr error
+	approvalAuditPath string
+
 	// Recovery confirmation dialog
 	recovering     bool
 	recoveryDialog components.RecoveryDialog
 
+	// Agent prompt preview before a local launch
+	previewingPrompt bool
+	promptPreview    components.PromptPreview
+
+	// Per-issue git worktrees for local agents (--worktrees)
+	worktreesEnabled   bool
+	worktrees          map[string]data.Worktree // issueID -> worktree
+	worktreeRefreshing bool
+
+	// Local agent queue without an orchestrator (at most MG_MAX_AGENTS running)
+	supervisor *agent.Supervisor
+
 	// Data source mode (JSONL file watcher vs bd CLI polling)
 	sourceMode data.SourceMode
 
@@ -203,6 +266,9 @@ type Model struct {
 	// Gas Town panel liveness tick
 	gasTownTicking bool
 
+	// Seed of the latest forecast run; results from older runs are dropped
+	forecastSeed uint64
+
 	// Layout preset (cycle with command palette)
 	layoutPreset LayoutPreset
 
@@ -223,6 +289,48 @@ type Model struct {
 	patrolScan         *gastown.PatrolScanResult
 	patrolScanInFlight bool
 	lastPatrolScan     time.Time
+	patrolHistory      []gastown.PatrolSample // the last day of scans, oldest first
+	patrolHistoryPath  string
+	patrolSave         saveGate
+
+	// Cost budgets from budgets.json (nil when unconfigured). costsInFlight
+	// and lastCostsFetch gate the background costs poll; budgetAlerted holds
+	// the overruns already toasted.
+	budgets        *gastown.BudgetConfig
+	budgetErr      error
+	budgetStatus   []gastown.BudgetStatus
+	budgetAlerted  map[string]bool
+	costsInFlight  bool
+	lastCostsFetch time.Time
+
+	// Recorded cost history for trends and weekly budgets
+	costHistory       []gastown.CostSample
+	costHistoryPath   string
+	costSave          saveGate
+	pendingCostSample *gastown.CostSample // reading waiting on costSave
+
+	// Problem tracking across polls (stable IDs, hysteresis, acks, snoozes,
+	// history) and the user's alert rules from alerts.json.
+	problemTracker     gastown.ProblemTracker
+	problemHistoryPath string
+	problemSave        saveGate
+	alertRules         []gastown.AlertRule
+	alertRulesErr      error
+
+	// Remediation playbooks from playbooks.json: what they have done, the
+	// latest action per problem for the panel, and the palette kill switch.
+	playbooks         *gastown.PlaybookConfig
+	playbooksErr      error
+	playbookState     gastown.PlaybookState
+	playbookAuditPath string
+	playbookLast      map[string]gastown.PlaybookAudit
+	playbooksPaused   bool
+
+	// Over-budget sling confirmation: pendingSlingKey is replayed with
+	// budgetConfirmed set once the user accepts.
+	confirmingSling bool
+	pendingSlingKey tea.KeyPressMsg
+	budgetConfirmed bool
 
 	// Shared terminal control-sequence guard (used by both the Bubble Tea
 	// filter and app-level deferred key handling).
@@ -285,37 +393,75 @@ func NewWithGuard(issues []data.Issue, source data.Source, blockingTypes map[str
 		prevMap[iss.ID] = iss.Status
 	}
 
+	registry, runtimesErr := agent.LoadRegistry(agent.RuntimesPath())
+	agentRuntime := registry.Preferred()
 	gtEnv := gastown.Detect()
+	driver, driverErr := gastown.SelectDriver()
 	metaSchema := data.LoadMetadataSchema(projectDir)
+	budgets, budgetErr := gastown.LoadBudgets(gastown.BudgetsPath())
+	alertRules, alertRulesErr := gastown.LoadAlertRules(gastown.AlertRulesPath())
+	problemHistoryPath := gastown.ProblemHistoryPath()
+	problemHistory, _ := gastown.LoadProblemHistory(problemHistoryPath) // unreadable history starts empty
+	playbooks, playbooksErr := gastown.LoadPlaybooks(gastown.PlaybooksPath())
+	transcriptsDir, _ := codexTranscriptsDir(projectDir)
+	codexTranscripts, codexTranscriptsErr := loadCodexTranscripts(transcriptsDir)
+	approvalPolicy, approvalPolicyErr := agent.LoadApprovalPolicy(agent.ApprovalRulesPath())
+	patrolHistoryPath := gastown.PatrolHistoryPath()
+	patrolHistory, _ := gastown.LoadPatrolHistory(patrolHistoryPath, time.Now().Add(-gastown.PatrolHistoryRetention)) // unreadable history starts empty
+	reopenHistoryPath := gastown.ReopenHistoryPath()
+	reopens, _ := gastown.LoadReopens(reopenHistoryPath) // unreadable history starts empty
 
 	return Model{
-		issues:         issues,
-		groups:         groups,
-		activPane:      PaneParade,
-		watchPath:      watchPath,
-		pathExplicit:   pathExplicit,
-		lastFileMod:    lastFileMod,
-		blockingTypes:  blockingTypes,
-		excludeTypes:   f.ExcludeTypes,
-		excludeLabels:  f.ExcludeLabels,
-		filterInput:    ti,
-		agentAvail:     agent.Available(),
-		agentRuntime:   agent.DetectRuntime(),
-		projectDir:     projectDir,
-		inTmux:         agent.InTmux() && agent.TmuxAvailable(),
-		activeAgents:   make(map[string]string),
-		gtEnv:          gtEnv,
-		driver:         gastown.SelectDriver(),
-		gtPollInFlight: gtEnv.Available || gastown.GCEnabled(), // Init() launches the first poll; gate subsequent ones
-		changedIDs:     make(map[string]bool),
-		prevIssueMap:   prevMap,
-		sourceMode:     source.Mode,
-		metadataSchema: metaSchema,
-		startedAt:      time.Now(),
-		spinner:        newLoadingSpinner(),
-		oscGuard:       guard,
-		noAnimations:   noAnimations,
-		codexSessions:  make(map[string]*codexSession),
+		issues:             issues,
+		groups:             groups,
+		activPane:          PaneParade,
+		watchPath:          watchPath,
+		pathExplicit:       pathExplicit,
+		lastFileMod:        lastFileMod,
+		blockingTypes:      blockingTypes,
+		excludeTypes:       f.ExcludeTypes,
+		excludeLabels:      f.ExcludeLabels,
+		filterInput:        ti,
+		agentAvail:         agentRuntime != "",
+		agentRuntime:       agentRuntime,
+		registry:           registry,
+		runtimesErr:        runtimesErr,
+		worktreesEnabled:   data.WorktreesEnabled(),
+		supervisor:         agent.NewSupervisor(agent.MaxAgents()),
+		projectDir:         projectDir,
+		inTmux:             agent.InTmux() && agent.TmuxAvailable(),
+		activeAgents:       make(map[string]string),
+		gtEnv:              gtEnv,
+		driver:             driver,
+		driverErr:          driverErr,
+		gtPollInFlight:     gtEnv.Available || driver.Backend() != gastown.BackendGasTown, // Init() launches the first poll; gate subsequent ones
+		changedIDs:         make(map[string]bool),
+		prevIssueMap:       prevMap,
+		reopens:            reopens,
+		reopenHistoryPath:  reopenHistoryPath,
+		sourceMode:         source.Mode,
+		metadataSchema:     metaSchema,
+		startedAt:          time.Now(),
+		spinner:            newLoadingSpinner(),
+		oscGuard:           guard,
+		noAnimations:       noAnimations,
+		codexSessions:      restoredCodexSessions(codexTranscripts),
+		codexStoreErr:      codexTranscriptsErr,
+		approvalPolicy:     approvalPolicy,
+		approvalPolicyErr:  approvalPolicyErr,
+		approvalAuditPath:  agent.ApprovalAuditPath(),
+		budgets:            budgets,
+		budgetErr:          budgetErr,
+		costHistoryPath:    gastown.CostHistoryPath(),
+		problemTracker:     *gastown.NewProblemTracker(problemHistory),
+		problemHistoryPath: problemHistoryPath,
+		alertRules:         alertRules,
+		alertRulesErr:      alertRulesErr,
+		playbooks:          playbooks,
+		playbooksErr:       playbooksErr,
+		playbookAuditPath:  gastown.PlaybookAuditPath(),
+		patrolHistory:      patrolHistory,
+		patrolHistoryPath:  patrolHistoryPath,
 	}
 }
 
@@ -339,6 +485,9 @@ func (m Model) Init() tea.Cmd {
 	if m.sourceMode == data.SourceCLI {
 		cmds = append(cmds, fetchCurrentIssue, fetchDoctorDiagnostics, fetchBeadsContext)
 	}
+	if cmd := m.refreshWorktrees(); cmd != nil {
+		cmds = append(cmds, cmd)
+	}
 	return tea.Batch(cmds...)
 }
 
@@ -386,12 +535,13 @@ func (m Model) startPollImmediate() tea.Cmd {
 }
 
 // orchestratorAvailable reports whether mg has a reachable orchestrator — Gas
-// Town (`gt` on PATH) or the Gas City HTTP driver (selected when MG_GC_API is
-// set). It gates the agent control surface. Because it's true whenever
+// Town (`gt` on PATH), or an explicitly configured non-gt driver: the Gas City
+// HTTP driver (MG_GC_API) or an out-of-process driver (MG_DRIVER=exec:…). It
+// gates the agent control surface. Because it's true whenever
 // gtEnv.Available is true, existing Gas Town behavior is unchanged; it only
-// additionally lights up the panel on a pure-Gas-City box with no `gt`.
+// additionally lights up the panel on a box with no `gt`.
 func (m Model) orchestratorAvailable() bool {
-	return m.gtEnv.Available || m.driver.Backend() == "gascity"
+	return m.gtEnv.Available || m.driver.Backend() != gastown.BackendGasTown
 }
 
 // gasTownLoading reports whether the Gas Town panel is open and still waiting
@@ -408,7 +558,10 @@ func (m *Model) activateGasTown() tea.Cmd {
 
 	m.showGasTown = true
 	m.showProblems = false
+	m.showTranscript = false
+	m.showConvoyTimeline = false
 	m.gasTown.SetStatus(m.townStatus, m.gtEnv)
+	m.gasTown.SetPatrolHistory(m.patrolHistory)
 
 	cmds := []tea.Cmd{
 		m.fetchConvoyList,
@@ -432,11 +585,37 @@ func (m *Model) activateGasTown() tea.Cmd {
 }
 
 // allProblems returns the combined list of Gas Town agent problems, doctor diagnostics,
-// and patrol scan findings.
+// patrol scan findings, budget overruns, and alert rule matches. It is a
+// snapshot; refreshProblems tracks it across polls.
 func (m Model) allProblems() []gastown.Problem {
 	problems := gastown.DetectProblems(m.townStatus, m.driver.Backend())
 	problems = append(problems, m.doctorProblems...)
 	problems = append(problems, gastown.PatrolScanProblems(m.patrolScan)...)
+	problems = append(problems, m.budgetProblems()...)
+	problems = append(problems, m.alertProblems()...)
+	problems = append(problems, m.playbookProblems()...)
+	problems = append(problems, m.approvalProblems()...)
+	if m.driverErr != nil {
+		problems = append(problems, gastown.Problem{
+			Type:     "driver",
+			Detail:   m.driverErr.Error() + " (using gt)",
+			Severity: "error",
+		})
+	}
+	if m.runtimesErr != nil {
+		problems = append(problems, gastown.Problem{
+			Type:     "runtime",
+			Detail:   m.runtimesErr.Error(),
+			Severity: "warn",
+		})
+	}
+	if m.codexStoreErr != nil {
+		problems = append(problems, gastown.Problem{
+			Type:     "codex",
+			Detail:   m.codexStoreErr.Error(),
+			Severity: "warn",
+		})
+	}
 	return problems
 }
 
@@ -643,8 +822,9 @@ type costsMsg struct {
 }
 
 type activityMsg struct {
-	events []gastown.Event
-	err    error
+	events  []gastown.Event // recent feed for the activity section
+	history []gastown.Event // longer tail for issue costs
+	err     error
 }
 
 type vitalsMsg struct {
@@ -752,6 +932,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Handle prompt preview result
+	if result, ok := msg.(components.PromptPreviewResult); ok {
+		return m.handlePromptPreviewResult(result)
+	}
+
 	// Handle palette result
 	if result, ok := msg.(components.PaletteResult); ok {
 		m.showPalette = false
@@ -769,7 +954,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.formulaMulti = nil
 				m.formulaTarget = ""
 				// Gas City needs a target agent — prompt, carrying the formula.
-				if driver.Backend() == "gascity" {
+				if driver.Backend() == gastown.BackendGasCity {
 					return m.openSlingTarget(ids, formula)
 				}
 				return m, func() tea.Msg {
@@ -779,7 +964,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			issueID := m.formulaTarget
 			m.formulaTarget = ""
-			if driver.Backend() == "gascity" {
+			if driver.Backend() == gastown.BackendGasCity {
 				return m.openSlingTarget([]string{issueID}, formula)
 			}
 			return m, func() tea.Msg {
@@ -829,9 +1014,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	}
 
-	// Forward all messages to the codex approval dialog when active
-	if m.approving {
-		if km, ok := msg.(tea.KeyPressMsg); ok && km.String() == "ctrl+c" {
+	// Forward keys to the codex approval dialog when active. Everything else
+	// falls through: its result, and the codex events that keep streaming
+	// while it waits, are handled below.
+	if km, ok := msg.(tea.KeyPressMsg); ok && m.approving {
+		if km.String() == "ctrl+c" {
 			logRoute("approvalDialog ctrl+c -> quit")
 			return m, tea.Quit
 		}
@@ -841,6 +1028,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	}
 
+	// Over-budget sling confirmation captures keys while open
+	if m.confirmingSling {
+		if km, ok := msg.(tea.KeyPressMsg); ok {
+			logRoute("slingConfirm key")
+			return m.handleSlingConfirmKey(km)
+		}
+	}
+
 	// Forward all messages to recovery dialog when active
 	if m.recovering {
 		if km, ok := msg.(tea.KeyPressMsg); ok && km.String() == "ctrl+c" {
@@ -853,6 +1048,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	}
 
+	// Forward all messages to the prompt preview when active
+	if m.previewingPrompt {
+		if km, ok := msg.(tea.KeyPressMsg); ok && km.String() == "ctrl+c" {
+			logRoute("promptPreview ctrl+c -> quit")
+			return m, tea.Quit
+		}
+		logRoute("promptPreview forward")
+		var cmd tea.Cmd
+		m.promptPreview, cmd = m.promptPreview.Update(msg)
+		return m, cmd
+	}
+
 	// Forward all messages to nudge input when active
 	if m.nudging {
 		if km, ok := msg.(tea.KeyPressMsg); ok {
@@ -980,7 +1187,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 				sess, ok := m.codexSessions[issueID]
-				if !ok || sess == nil || sess.handle == nil {
+				if !ok || sess == nil || sess.state == nil && sess.handle == nil {
 					return m, nil
 				}
 				// Flip transcript back to running for the new turn.
@@ -991,6 +1198,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.codexTranscript.SetState(sess.state)
 					}
 				}
+				if sess.handle == nil {
+					// Restored from disk: relaunch codex on the stored thread.
+					cwd := sess.state.Cwd
+					if cwd == "" {
+						cwd = m.projectDir
+					}
+					return m, codexResumeCmd(issueID, body, sess.state.ThreadID, cwd)
+				}
 				return m, codexReplyCmd(issueID, body, sess.handle)
 			}
 		}
@@ -1160,6 +1375,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}))
 		}
 
+		worktreeNotice := m.closedWorktreeNotice(msg.Issues)
+		cmds = append(cmds, m.recordReopens(msg.Issues))
+
 		// Update snapshot for next diff
 		m.prevIssueMap = make(map[string]data.Status, len(msg.Issues))
 		for _, iss := range msg.Issues {
@@ -1168,6 +1386,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		m.issues = msg.Issues
 		m.groups = data.GroupByParade(msg.Issues, m.blockingTypes)
+		m.recomputeIssueCosts()
 		if !msg.LastMod.IsZero() {
 			m.lastFileMod = msg.LastMod
 		}
@@ -1183,8 +1402,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.toast = toast
 			cmds = append(cmds, toastCmd)
 		}
-		m.recomputeVelocity()
+		if worktreeNotice != "" {
+			// Shown over the selection toast: the closed issue is usually
+			// the one that was selected.
+			toast, toastCmd := components.ShowToast(worktreeNotice, components.ToastInfo, toastDuration)
+			m.toast = toast
+			cmds = append(cmds, toastCmd)
+		}
+		cmds = append(cmds, m.recomputeVelocity())
 		cmds = append(cmds, m.detailFetchBatch()...)
+		cmds = append(cmds, m.refreshWorktrees())
+		if done := m.supervisor.ObserveIssues(msg.Issues); len(done) > 0 {
+			cmds = append(cmds, m.dispatchQueued())
+		}
 		return m, tea.Batch(cmds...)
 
 	case data.FileUnchangedMsg:
@@ -1230,8 +1460,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, tea.Batch(cmds...)
 
+	case worktreeReadyMsg:
+		return m.handleWorktreeReady(msg)
+
+	case worktreesMsg:
+		return m.handleWorktrees(msg)
+
+	case worktreeRemovedMsg:
+		return m.handleWorktreeRemoved(msg)
+
+	case supervisorClaimedMsg:
+		return m.handleSupervisorClaimed(msg)
+
 	case agentLaunchedMsg:
 		m.activeAgents[msg.issueID] = msg.windowName
+		m.supervisor.Launched(msg.issueID)
 		m.propagateAgentState()
 		toast, cmd := components.ShowToast(
 			fmt.Sprintf("Agent launched for %s", msg.issueID),
@@ -1246,27 +1489,32 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			components.ToastError, toastDuration,
 		)
 		m.toast = toast
-		return m, cmd
+		return m, tea.Batch(cmd, m.finishSupervised(msg.issueID))
 
 	case codexLaunchedMsg:
+		if old := m.codexSessions[msg.issueID]; old != nil && old.handle != nil {
+			// A relaunch replaces the issue's finished session.
+			_ = old.handle.Close()
+		}
 		m.codexSessions[msg.issueID] = msg.sess
+		m.supervisor.Launched(msg.issueID)
 		if m.isCodexShownFor(msg.issueID) {
 			m.codexTranscript.SetState(msg.sess.state)
 		}
 		toast, cmd := components.ShowToast(
-			fmt.Sprintf("Codex (MCP) launched for %s", msg.issueID),
+			fmt.Sprintf("%s (MCP) launched for %s", msg.sess.label(), msg.issueID),
 			components.ToastSuccess, toastDuration,
 		)
 		m.toast = toast
-		return m, tea.Batch(cmd, codexNextEventCmd(msg.issueID, msg.sess.handle.Session(), msg.sess.handle.ServerRequests()))
+		return m, tea.Batch(cmd, codexNextEventCmd(msg.issueID, msg.sess.handle.Session(), msg.sess.handle))
 
 	case codexLaunchErrorMsg:
 		toast, cmd := components.ShowToast(
-			fmt.Sprintf("Codex MCP launch failed: %s", msg.err),
+			fmt.Sprintf("MCP agent launch failed: %s", msg.err),
 			components.ToastError, toastDuration,
 		)
 		m.toast = toast
-		return m, cmd
+		return m, tea.Batch(cmd, m.finishSupervised(msg.issueID))
 
 	case codexEventMsg:
 		sess := m.codexSessions[msg.issueID]
@@ -1277,6 +1525,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// noisy event types (raw_response_item, agent_message_content_delta,
 		// mcp_startup_update) that AppendEvent drops; without this guard a
 		// long session triggers hundreds of no-op transcript re-renders.
+		hadThread := sess.state != nil && sess.state.ThreadID != ""
 		appended := applyCodexEvent(sess, msg.ev)
 		if appended && m.isCodexShownFor(msg.issueID) {
 			m.codexTranscript.SetState(sess.state)
@@ -1284,7 +1533,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if sess.handle == nil {
 			return m, nil
 		}
-		return m, codexNextEventCmd(msg.issueID, sess.handle.Session(), sess.handle.ServerRequests())
+		next := codexNextEventCmd(msg.issueID, sess.handle.Session(), sess.handle)
+		if !hadThread && sess.state != nil && sess.state.ThreadID != "" {
+			// Save as soon as the thread is known so a crash mid-turn still
+			// leaves something to resume.
+			return m, tea.Batch(next, m.persistCodexSession(msg.issueID))
+		}
+		return m, next
 
 	case codexDoneMsg:
 		sess := m.codexSessions[msg.issueID]
@@ -1298,15 +1553,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var msgText string
 		var kind components.ToastLevel
 		if msg.result.Err != nil {
-			msgText = fmt.Sprintf("Codex MCP errored for %s", msg.issueID)
+			msgText = fmt.Sprintf("%s MCP errored for %s", sess.label(), msg.issueID)
 			kind = components.ToastError
 		} else {
-			msgText = fmt.Sprintf("Codex MCP done for %s", msg.issueID)
+			msgText = fmt.Sprintf("%s MCP done for %s", sess.label(), msg.issueID)
 			kind = components.ToastSuccess
 		}
 		toast, cmd := components.ShowToast(msgText, kind, toastDuration)
 		m.toast = toast
-		return m, cmd
+		return m, tea.Batch(cmd, m.persistCodexSession(msg.issueID), m.finishSupervised(msg.issueID))
+
+	case codexResumedMsg:
+		sess := m.codexSessions[msg.issueID]
+		if sess == nil {
+			_ = msg.handle.Close()
+			return m, nil
+		}
+		sess.handle = msg.handle
+		toast, cmd := components.ShowToast(
+			fmt.Sprintf("Codex resumed %s", msg.issueID),
+			components.ToastSuccess, toastDuration,
+		)
+		m.toast = toast
+		return m, tea.Batch(cmd, codexNextEventCmd(msg.issueID, msg.handle.Session(), msg.handle))
 
 	case codexReplyDispatchedMsg:
 		// Handle.Reply rotated the underlying session pointer; the
@@ -1316,7 +1585,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if sess == nil || sess.handle == nil {
 			return m, nil
 		}
-		return m, codexNextEventCmd(msg.issueID, msg.sess, sess.handle.ServerRequests())
+		return m, codexNextEventCmd(msg.issueID, msg.sess, sess.handle)
 
 	case codexReplyErrorMsg:
 		toast, cmd := components.ShowToast(
@@ -1355,6 +1624,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case agentStatusMsg:
 		m.activeAgents = msg.activeAgents
 		m.propagateAgentState()
+		if done := m.supervisor.ObservePanes(msg.activeAgents, time.Now()); len(done) > 0 {
+			return m, m.dispatchQueued()
+		}
 		return m, nil
 
 	case townStatusMsg:
@@ -1363,17 +1635,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.townStatus = msg.status
 			m.activeAgents = msg.status.ActiveAgentMap()
 			m.propagateAgentState()
+			var forecastCmd tea.Cmd
 			if m.showGasTown {
 				m.gasTown.SetStatus(m.townStatus, m.gtEnv)
-				m.recomputeVelocity()
-			}
-			if m.showProblems {
-				m.problems.SetProblems(m.allProblems())
+				forecastCmd = m.recomputeVelocity()
 			}
+			saveCmd := m.refreshProblems()
 			// Check if selected issue now has an agent → fetch molecule
 			if cmd := m.maybeFetchMolecule(); cmd != nil {
-				return m, cmd
+				return m, tea.Batch(cmd, saveCmd, forecastCmd)
 			}
+			return m, tea.Batch(saveCmd, forecastCmd)
 		}
 		return m, nil
 
@@ -1383,19 +1655,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Clear stale patrol data and update TTL to prevent hot-loop retries
 			m.patrolScan = nil
 			m.lastPatrolScan = time.Now()
-			m.header.ProblemCount = len(m.allProblems())
-			if m.showProblems {
-				m.problems.SetProblems(m.allProblems())
-			}
-			return m, nil
+			return m, m.refreshProblems()
 		}
 		if msg.scan != nil {
 			m.patrolScan = msg.scan
 			m.lastPatrolScan = time.Now()
-			m.header.ProblemCount = len(m.allProblems())
-			if m.showProblems {
-				m.problems.SetProblems(m.allProblems())
-			}
+			saveCmd := m.recordPatrolScan(*msg.scan)
+			return m, tea.Batch(m.refreshProblems(), saveCmd)
 		}
 		return m, nil
 
@@ -1497,8 +1763,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			label = fmt.Sprintf("Nudged %s: %s", msg.target, display)
 		}
+		// A message sent from the transcript overlay should show up there
+		// without waiting for the next poll.
+		var followUp []tea.Cmd
+		if msg.err == nil && m.showTranscript {
+			followUp = append(followUp, m.fetchTranscript())
+		}
 		return m.toastResult(msg.err,
-			fmt.Sprintf("Nudge failed for %s", msg.target), label)
+			fmt.Sprintf("Nudge failed for %s", msg.target), label, followUp...)
 
 	case handoffResultMsg:
 		return m.toastResult(msg.err,
@@ -1506,6 +1778,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			fmt.Sprintf("Handoff initiated for %s", msg.target),
 			m.gatedPollAgentState())
 
+	case playbookResultMsg:
+		return m.handlePlaybookResult(msg)
+
 	case decommissionResultMsg:
 		return m.toastResult(msg.err,
 			fmt.Sprintf("Decommission failed for %s", msg.address),
@@ -1515,6 +1790,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case convoyListMsg:
 		if msg.err == nil {
 			m.gasTown.SetConvoyDetails(msg.convoys)
+			m.convoyPlanner.SetConvoys(msg.convoys)
+			return m, m.refreshForecasts()
+		}
+		return m, nil
+
+	case forecastsMsg:
+		// A newer run supersedes this one.
+		if msg.seed == m.forecastSeed {
+			m.gasTown.SetForecasts(msg.forecasts, gastown.DefaultForecastWindow)
 		}
 		return m, nil
 
@@ -1630,16 +1914,44 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case patrolHistorySavedMsg:
+		if m.patrolSave.done() {
+			return m, m.savePatrolHistory()
+		}
+		return m, nil
+
+	case problemHistorySavedMsg:
+		if m.problemSave.done() {
+			return m, m.saveProblemHistory()
+		}
+		return m, nil
+
 	case costsMsg:
+		m.costsInFlight = false
+		m.lastCostsFetch = time.Now()
 		if msg.err == nil && msg.costs != nil {
 			m.gasTown.SetCosts(msg.costs)
-			m.recomputeVelocity()
+			forecastCmd := m.recomputeVelocity()
+			m.recomputeIssueCosts()
+			if m.detail.Issue != nil {
+				m.detail.SetIssue(m.detail.Issue)
+			}
+			return m, tea.Batch(m.applyBudgets(), m.recordCosts(msg.costs), forecastCmd)
 		}
 		return m, nil
 
+	case costHistoryMsg:
+		return m.handleCostHistory(msg)
+
 	case activityMsg:
 		if msg.err == nil && len(msg.events) > 0 {
 			m.gasTown.SetEvents(msg.events)
+			m.eventHistory = msg.history
+			m.recomputeScorecards()
+			m.recomputeIssueCosts()
+			if m.detail.Issue != nil {
+				m.detail.SetIssue(m.detail.Issue)
+			}
 		}
 		return m, nil
 
@@ -1652,9 +1964,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case views.GasTownActionMsg:
 		return m.handleGasTownAction(msg)
 
+	case convoyTimelineMsg:
+		return m.handleConvoyTimeline(msg)
+
+	case sessionTranscriptMsg:
+		return m.handleSessionTranscript(msg)
+
+	case views.ConvoyPlanMsg:
+		return m.handleConvoyPlan(msg)
+
+	case convoyAddResultMsg:
+		return m.handleConvoyAddResult(msg)
+
 	case views.RecoveryActionMsg:
 		return m.handleRecoveryAction(msg)
 
+	case views.ProblemActionMsg:
+		return m.handleProblemAction(msg)
+
+	case views.CodexSessionSelectMsg:
+		return m.showCodexSession(msg.IssueID)
+
 	case components.RecoveryDialogResult:
 		if msg.Cancelled {
 			m.recovering = false
@@ -1778,7 +2108,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.gasTown.Tick()
 		// Keep ticking while panel is visible
 		if m.showGasTown {
-			return m, gasTownTickCmd()
+			pollCmd := m.pollTranscript()
+			return m, tea.Batch(gasTownTickCmd(), pollCmd)
 		}
 		m.gasTownTicking = false
 		return m, nil
@@ -1826,13 +2157,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			})
 		}
 		m.doctorProblems = gastown.DoctorProblems(diags)
-		if m.showProblems {
-			m.problems.SetProblems(m.allProblems())
-		}
 		if m.showDoctor {
 			m.doctor.SetResult(msg.result)
 		}
-		return m, nil
+		return m, m.refreshProblems()
 
 	case beadsContextMsg:
 		m.beadsContext = msg.ctx
@@ -1918,6 +2246,39 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		dbg("  handleKey: String=%q Keystroke=%q (DIFFER)", str, ks)
 	}
 
+	// When the convoy planner is focused, route its keys before global handlers
+	if m.plannerFocused() {
+		if next, cmd, handled := m.handlePlannerKey(msg); handled {
+			logAction("planner key: %s", msg.String())
+			return next, cmd
+		}
+	}
+
+	// Likewise for the flow analytics overlay
+	if m.analyticsFocused() {
+		if next, cmd, handled := m.handleAnalyticsKey(msg); handled {
+			logAction("analytics key: %s", msg.String())
+			return next, cmd
+		}
+	}
+
+	// When the session transcript overlay is focused, route its keys before
+	// the Gas Town panel it replaces and before global handlers
+	if m.transcriptFocused() {
+		if next, cmd, handled := m.handleTranscriptKey(msg); handled {
+			logAction("transcript key: %s", msg.String())
+			return next, cmd
+		}
+	}
+
+	// Likewise for the convoy timeline overlay
+	if m.convoyTimelineFocused() {
+		if next, cmd, handled := m.handleConvoyTimelineKey(msg); handled {
+			logAction("convoy timeline key: %s", msg.String())
+			return next, cmd
+		}
+	}
+
 	// When Doctor panel is focused, route its keys before global handlers
 	if m.showDoctor && m.activPane == PaneDetail {
 		switch msg.String() {
@@ -1933,10 +2294,26 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// The codex session list takes its keys wherever focus is: it's a
+	// switcher, opened and closed with S.
+	if m.showCodexSessions {
+		switch msg.String() {
+		case "j", "k", "up", "down", "g", "G", "enter", "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			logAction("codex sessions key: %s", msg.String())
+			m.codexSessionList.SetRows(m.codexSessionRows())
+			var cmd tea.Cmd
+			m.codexSessionList, cmd = m.codexSessionList.Update(msg)
+			return m, cmd
+		case "esc":
+			m.showCodexSessions = false
+			return m, nil
+		}
+	}
+
 	// When Problems panel is focused, route its keys before global handlers
 	if m.showProblems && m.activPane == PaneDetail {
 		switch msg.String() {
-		case "j", "k", "up", "down", "g", "G", "n", "h", "K", "R":
+		case "j", "k", "up", "down", "g", "G", "n", "h", "K", "R", "a", "z", "H":
 			logAction("problems panel key: %s", msg.String())
 			var cmd tea.Cmd
 			m.problems, cmd = m.problems.Update(msg)
@@ -1944,10 +2321,22 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// When the codex transcript is focused, j/k pick an entry and enter
+	// expands its output.
+	if m.showCodex && m.activPane == PaneDetail {
+		switch msg.String() {
+		case "j", "k", "up", "down", "g", "G", "enter", "space":
+			logAction("codex transcript key: %s", msg.String())
+			var cmd tea.Cmd
+			m.codexTranscript, cmd = m.codexTranscript.Update(msg)
+			return m, cmd
+		}
+	}
+
 	// When Gas Town panel is focused, route its keys before global handlers
 	if m.showGasTown && m.activPane == PaneDetail {
 		switch msg.String() {
-		case "j", "k", "up", "down", "g", "G", "n", "h", "K", "tab", "enter", "l", "x", "r", "d", "w", "W", "R":
+		case "j", "k", "up", "down", "g", "G", "n", "h", "K", "t", "tab", "enter", "l", "x", "r", "d", "w", "W", "R", "o", "O":
 			logAction("gastown panel key: %s", msg.String())
 			var cmd tea.Cmd
 			m.gasTown, cmd = m.gasTown.Update(msg)
@@ -2011,7 +2400,9 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		m.showGasTown = !m.showGasTown
 		if m.showGasTown {
 			m.showDoctor = false
+			m.showAnalytics = false
 			m.showCodex = false
+			m.showCodexSessions = false
 			m.dismissCodexReply()
 			cmd := m.activateGasTown()
 			return m, cmd
@@ -2026,9 +2417,11 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		if m.showProblems {
 			m.showGasTown = false
 			m.showDoctor = false
+			m.showAnalytics = false
 			m.showCodex = false
+			m.showCodexSessions = false
 			m.dismissCodexReply()
-			m.problems.SetProblems(m.allProblems())
+			m.syncProblems(time.Now())
 		}
 		return m, nil
 
@@ -2037,7 +2430,9 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		if m.showDoctor {
 			m.showGasTown = false
 			m.showProblems = false
+			m.showAnalytics = false
 			m.showCodex = false
+			m.showCodexSessions = false
 			m.dismissCodexReply()
 			// Set existing result if available, then refresh
 			if m.doctorResult != nil {
@@ -2050,6 +2445,12 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	case "M":
 		return m.toggleCodexTranscript()
 
+	case "S":
+		return m.toggleCodexSessions()
+
+	case "I":
+		return m.toggleAnalytics()
+
 	case "c":
 		m.parade.ToggleClosed()
 		m.syncSelection()
@@ -2080,9 +2481,12 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m.createAndSwitchBranch()
 
 	case "a":
+		if m.guardSling(msg) {
+			return m, nil
+		}
 		// Gas City dispatch needs an explicit target agent — prompt for it
 		// (single or multi), then sling through the target prompt.
-		if m.driver.Backend() == "gascity" {
+		if m.driver.Backend() == gastown.BackendGasCity {
 			var ids []string
 			if selected := m.parade.SelectedIssues(); len(selected) > 0 {
 				ids = make([]string, len(selected))
@@ -2099,8 +2503,8 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			return m.openSlingTarget(ids, "")
 		}
 
-		// Multi-sling with Gas Town
-		if selected := m.parade.SelectedIssues(); len(selected) > 0 && m.gtEnv.Available {
+		// Multi-sling through the orchestrator
+		if selected := m.parade.SelectedIssues(); len(selected) > 0 && m.orchestratorAvailable() {
 			ids := make([]string, len(selected))
 			for i, iss := range selected {
 				ids[i] = iss.ID
@@ -2115,15 +2519,15 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		}
 
 		issue := m.parade.SelectedIssue
-		if issue == nil || !m.agentAvail {
+		if issue == nil {
 			return m, nil
 		}
-		if _, active := m.activeAgents[issue.ID]; active && m.inTmux {
+		if _, active := m.activeAgents[issue.ID]; active && m.inTmux && m.agentAvail {
 			_ = agent.SelectAgentWindow(issue.ID)
 			return m, nil
 		}
 
-		if m.gtEnv.Available {
+		if m.orchestratorAvailable() {
 			issueID := issue.ID
 			runtime := m.agentRuntime
 			driver := m.driver
@@ -2132,24 +2536,15 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 				return slingResultMsg{issueID: issueID, err: err}
 			}
 		}
+		if !m.agentAvail {
+			return m, nil
+		}
 
-		deps := issue.EvaluateDependencies(m.detail.IssueMap, m.blockingTypes)
-		prompt := agent.BuildPrompt(*issue, deps, m.detail.IssueMap)
-
-		if m.inTmux {
-			issueID := issue.ID
-			return m, func() tea.Msg {
-				winName, err := agent.LaunchInTmux(prompt, m.projectDir, issueID)
-				if err != nil {
-					return agentLaunchErrorMsg{issueID: issueID, err: err}
-				}
-				return agentLaunchedMsg{issueID: issueID, windowName: winName}
-			}
+		prompt, template, err := m.agentPrompt(issue)
+		if err != nil {
+			return m.promptErrorToast(template, err)
 		}
-		c := agent.Command(prompt, m.projectDir)
-		return m, tea.ExecProcess(c, func(err error) tea.Msg {
-			return agentFinishedMsg{err: err}
-		})
+		return m.launchAgent(issue.ID, prompt)
 
 	case "A":
 		issue := m.parade.SelectedIssue
@@ -2183,6 +2578,9 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		if !m.orchestratorAvailable() {
 			return m, nil
 		}
+		if m.guardSling(msg) {
+			return m, nil
+		}
 		// Multi-select: collect IDs for formula picking
 		if selected := m.parade.SelectedIssues(); len(selected) > 0 {
 			ids := make([]string, len(selected))
@@ -2317,6 +2715,9 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		m.convoyInput.Focus()
 		return m, textinput.Blink
 
+	case "P":
+		return m.openConvoyPlanner()
+
 	case "ctrl+k":
 		m.showPalette = true
 		m.palette = components.NewPalette(m.width, m.height, m.buildPaletteCommands())
@@ -2543,34 +2944,58 @@ func (m Model) runClaimNextReady() (tea.Model, tea.Cmd) {
 	}
 }
 
-// resumeCodexSession launches `codex resume --last` in a new tmux pane,
-// preserving the active project's prior agent session. Surfaces a toast if no
-// codex session is on disk (~/.codex/sessions/YYYY/MM/DD/*.jsonl) so the user
-// doesn't get a confusing empty tmux pane from a no-op resume.
-func (m Model) resumeCodexSession() (tea.Model, tea.Cmd) {
-	if !m.inTmux {
-		toast, cmd := components.ShowToast("Codex resume requires tmux", components.ToastInfo, toastDuration)
+// reloadRuntimes re-reads the runtimes file and re-detects which agent CLIs
+// are installed. The registry is otherwise loaded once per session.
+func (m Model) reloadRuntimes() (tea.Model, tea.Cmd) {
+	m.registry, m.runtimesErr = agent.LoadRegistry(agent.RuntimesPath())
+	m.agentRuntime = m.registry.Preferred()
+	m.agentAvail = m.agentRuntime != ""
+	if m.runtimesErr != nil {
+		toast, cmd := components.ShowToast("Runtimes: "+m.runtimesErr.Error(), components.ToastError, toastDuration)
 		m.toast = toast
 		return m, cmd
 	}
-	home, err := os.UserHomeDir()
-	if err != nil {
-		toast, cmd := components.ShowToast("Codex resume: cannot resolve $HOME", components.ToastError, toastDuration)
-		m.toast = toast
-		return m, cmd
+	msg := "No agent runtime installed"
+	if m.agentAvail {
+		msg = "Agent runtime: " + m.registry.Label(m.agentRuntime)
 	}
-	sessionsDir := filepath.Join(home, ".codex", "sessions")
-	if !agent.CodexHasPriorSession(sessionsDir) {
-		toast, cmd := components.ShowToast("No prior Codex session to resume", components.ToastInfo, toastDuration)
+	toast, cmd := components.ShowToast(msg, components.ToastInfo, toastDuration)
+	m.toast = toast
+	return m, cmd
+}
+
+// resumeAgentSession launches the active runtime's resume command (for
+// Codex, `codex resume --last`) in a new tmux pane, preserving the project's
+// prior agent session. For Codex it first surfaces a toast if no session is
+// on disk (~/.codex/sessions/YYYY/MM/DD/*.jsonl) so the user doesn't get a
+// confusing empty tmux pane from a no-op resume.
+func (m Model) resumeAgentSession() (tea.Model, tea.Cmd) {
+	label := m.registry.Label(m.agentRuntime)
+	if !m.inTmux {
+		toast, cmd := components.ShowToast(label+" resume requires tmux", components.ToastInfo, toastDuration)
 		m.toast = toast
 		return m, cmd
 	}
-	if _, err := agent.LaunchCodexResumeInTmux(m.projectDir); err != nil {
-		toast, cmd := components.ShowToast("Codex resume: "+err.Error(), components.ToastError, toastDuration)
+	if m.agentRuntime == agent.RuntimeCodex {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			toast, cmd := components.ShowToast("Codex resume: cannot resolve $HOME", components.ToastError, toastDuration)
+			m.toast = toast
+			return m, cmd
+		}
+		sessionsDir := filepath.Join(home, ".codex", "sessions")
+		if !agent.CodexHasPriorSession(sessionsDir) {
+			toast, cmd := components.ShowToast("No prior Codex session to resume", components.ToastInfo, toastDuration)
+			m.toast = toast
+			return m, cmd
+		}
+	}
+	if _, err := m.registry.LaunchResumeInTmux(m.agentRuntime, m.projectDir); err != nil {
+		toast, cmd := components.ShowToast(label+" resume: "+err.Error(), components.ToastError, toastDuration)
 		m.toast = toast
 		return m, cmd
 	}
-	toast, cmd := components.ShowToast("Resumed last Codex session", components.ToastSuccess, toastDuration)
+	toast, cmd := components.ShowToast("Resumed last "+label+" session", components.ToastSuccess, toastDuration)
 	m.toast = toast
 	return m, cmd
 }
@@ -2690,16 +3115,55 @@ func (m Model) buildPaletteCommands() []components.PaletteCommand {
 		{Name: "Prune preview (closed > 30d)", Desc: "Dry-run: report closed non-ephemeral beads older than 30d", Key: "", Action: components.ActionPrunePreview},
 		{Name: "Prune closed > 30d (force)", Desc: "Delete closed non-ephemeral beads older than 30d — destructive, no undo", Key: "", Action: components.ActionPruneClosed},
 		{Name: "Claim next ready", Desc: "Atomically claim the top-priority ready bead (bd ready --claim)", Key: "", Action: components.ActionClaimNextReady},
+		{Name: "Reload agent runtimes", Desc: "Re-read runtimes.json and re-detect installed agent CLIs", Key: "", Action: components.ActionReloadRuntimes},
 	}
 
 	if m.agentAvail {
 		cmds = append(cmds,
-			components.PaletteCommand{Name: "Launch agent", Desc: fmt.Sprintf("Start %s agent on issue", m.agentRuntime.RuntimeLabel()), Key: "a", Action: components.ActionLaunchAgent},
+			components.PaletteCommand{Name: "Launch agent", Desc: fmt.Sprintf("Start %s agent on issue", m.registry.Label(m.agentRuntime)), Key: "a", Action: components.ActionLaunchAgent},
 			components.PaletteCommand{Name: "Kill agent", Desc: "Stop agent working on issue", Key: "A", Action: components.ActionKillAgent},
 		)
-		if m.agentRuntime == agent.RuntimeCodex && m.inTmux {
+		if n := len(m.codexSessions); n > 0 {
 			cmds = append(cmds,
-				components.PaletteCommand{Name: "Resume last Codex session", Desc: "codex resume --last in a new tmux pane", Key: "", Action: components.ActionCodexResume},
+				components.PaletteCommand{Name: "Codex sessions", Desc: fmt.Sprintf("Switch between %d codex transcripts", n), Key: "S", Action: components.ActionCodexSessions},
+			)
+		}
+		if issue := m.parade.SelectedIssue; issue != nil {
+			if sess := m.codexSessions[issue.ID]; sess != nil && sess.state != nil {
+				cmds = append(cmds,
+					components.PaletteCommand{Name: "Attach codex transcript", Desc: "Comment a summary of the codex session on the issue", Key: "", Action: components.ActionAttachCodexTranscript},
+				)
+			}
+			if wt, ok := m.worktrees[issue.ID]; ok {
+				cmds = append(cmds,
+					components.PaletteCommand{Name: "Clean up worktree", Desc: "Remove " + wt.Path + ", delete its branch if merged", Key: "", Action: components.ActionCleanupWorktree},
+				)
+			}
+		}
+		if m.launchesLocally() {
+			cmds = append(cmds,
+				components.PaletteCommand{Name: "Preview agent prompt", Desc: "Review and edit the prompt, then launch", Key: "", Action: components.ActionPreviewPrompt},
+			)
+		}
+		if _, ok := m.supervisorBackend(); ok {
+			cmds = append(cmds,
+				components.PaletteCommand{Name: "Queue for agents", Desc: fmt.Sprintf("Run selected issues through the local agent queue (%d at a time)", m.supervisor.Limit), Key: "", Action: components.ActionQueueAgents},
+			)
+			if issue := m.parade.SelectedIssue; issue != nil && m.supervisor.Position(issue.ID) > 0 {
+				cmds = append(cmds,
+					components.PaletteCommand{Name: "Remove from agent queue", Desc: "Drop " + issue.ID + " from the queue", Key: "", Action: components.ActionUnqueueAgent},
+				)
+			}
+			if n := len(m.supervisor.Queue()); n > 0 {
+				cmds = append(cmds,
+					components.PaletteCommand{Name: "Clear agent queue", Desc: fmt.Sprintf("Drop %d queued issues; running agents continue", n), Key: "", Action: components.ActionClearAgentQueue},
+				)
+			}
+		}
+		if spec, ok := m.registry.Lookup(string(m.agentRuntime)); ok && len(spec.Resume) > 0 && m.inTmux {
+			label := spec.DisplayLabel()
+			cmds = append(cmds,
+				components.PaletteCommand{Name: "Resume last " + label + " session", Desc: "Continue the last " + label + " session in a new tmux pane", Key: "", Action: components.ActionResumeAgent},
 			)
 		}
 	}
@@ -2711,8 +3175,16 @@ func (m Model) buildPaletteCommands() []components.PaletteCommand {
 			components.PaletteCommand{Name: "Nudge agent", Desc: "Nudge agent with message", Key: "n", Action: components.ActionNudgeAgent},
 			components.PaletteCommand{Name: "Create & assign to crew", Desc: "Create issue and hook to crew member", Key: "", Action: components.ActionAssign},
 			components.PaletteCommand{Name: "Create convoy", Desc: "Create convoy from selected issues", Key: "C", Action: components.ActionCreateConvoy},
+			components.PaletteCommand{Name: "Plan convoy", Desc: "Build a convoy from a query or blocking closure", Key: "P", Action: components.ActionPlanConvoy},
 			components.PaletteCommand{Name: "Cascade close", Desc: "Close issue and all children", Key: "", Action: components.ActionCascadeClose},
 		)
+		if m.playbooks != nil {
+			cmd := components.PaletteCommand{Name: "Pause playbooks", Desc: "Kill switch: stop automated remediation", Key: "", Action: components.ActionTogglePlaybooks}
+			if m.playbooksPaused {
+				cmd.Name, cmd.Desc = "Resume playbooks", "Let playbooks remediate problems again"
+			}
+			cmds = append(cmds, cmd)
+		}
 		// Recovery shells out to gt directly rather than going through the
 		// Driver, so it must not be offered on a backend that has no gt.
 		if m.driver.Supports(gastown.FeatureRecovery) {
@@ -2781,6 +3253,20 @@ func (m Model) executePaletteAction(action components.PaletteAction) (tea.Model,
 		return m.handleKey(tea.KeyPressMsg{Code: 'a', Text: "a"})
 	case components.ActionKillAgent:
 		return m.handleKey(tea.KeyPressMsg{Code: 'A', Text: "A"})
+	case components.ActionPreviewPrompt:
+		return m.openPromptPreview()
+	case components.ActionCleanupWorktree:
+		return m.cleanupWorktree()
+	case components.ActionCodexSessions:
+		return m.toggleCodexSessions()
+	case components.ActionAttachCodexTranscript:
+		return m.attachCodexTranscript()
+	case components.ActionQueueAgents:
+		return m.queueForAgents()
+	case components.ActionUnqueueAgent:
+		return m.unqueueSelected()
+	case components.ActionClearAgentQueue:
+		return m.clearAgentQueue()
 	case components.ActionSlingFormula:
 		return m.handleKey(tea.KeyPressMsg{Code: 's', Text: "s"})
 	case components.ActionNudgeAgent:
@@ -2801,6 +3287,8 @@ func (m Model) executePaletteAction(action components.PaletteAction) (tea.Model,
 		return m, nil
 	case components.ActionCreateConvoy:
 		return m.handleKey(tea.KeyPressMsg{Code: 'C', Text: "C"})
+	case components.ActionPlanConvoy:
+		return m.openConvoyPlanner()
 	case components.ActionCascadeClose:
 		return m.cascadeCloseIssue()
 	case components.ActionCycleLayout:
@@ -2840,14 +3328,18 @@ func (m Model) executePaletteAction(action components.PaletteAction) (tea.Model,
 		m.recovering = true
 		m.recoveryDialog = components.NewRecoveryDialog(rigName, orphans, m.width, m.height)
 		return m, nil
+	case components.ActionTogglePlaybooks:
+		return m.togglePlaybooks()
 	case components.ActionPrunePreview:
 		return m.runPrune(true)
 	case components.ActionPruneClosed:
 		return m.runPrune(false)
 	case components.ActionClaimNextReady:
 		return m.runClaimNextReady()
-	case components.ActionCodexResume:
-		return m.resumeCodexSession()
+	case components.ActionResumeAgent:
+		return m.resumeAgentSession()
+	case components.ActionReloadRuntimes:
+		return m.reloadRuntimes()
 	case components.ActionHelp:
 		m.showHelp = true
 		return m, nil
@@ -2895,6 +3387,12 @@ func (m Model) handleGasTownAction(msg views.GasTownActionMsg) (tea.Model, tea.C
 		m.nudgeInput.Focus()
 		return m, textinput.Blink
 
+	case views.ActionTranscript:
+		return m.openTranscript(msg.Agent)
+
+	case views.ActionConvoyTimeline:
+		return m.openConvoyTimeline(msg.ConvoyID)
+
 	case views.ActionHandoff:
 		// Handoff shells out to `gt handoff`, so it needs Gas Town regardless
 		// of tmux — check the backend before blaming the terminal.
@@ -3224,7 +3722,7 @@ func (m *Model) layout() {
 		AgentCount:       len(m.activeAgents),
 		TownStatus:       m.townStatus,
 		GasTownAvailable: m.orchestratorAvailable(),
-		ProblemCount:     len(m.allProblems()),
+		ProblemCount:     m.problemTracker.Unacked(time.Now()),
 		BeadOffset:       m.beadOffset,
 		CurrentIssueID:   m.currentIssueID,
 	}
@@ -3235,7 +3733,18 @@ func (m *Model) layout() {
 	m.problems.SetSize(detailW, bodyH)
 	m.doctor.SetSize(detailW, bodyH)
 	m.codexTranscript.SetSize(detailW, bodyH)
+	m.codexSessionList.SetSize(detailW, bodyH)
+	m.agentTranscript.SetSize(detailW, bodyH)
+	m.convoyGantt.SetSize(detailW, bodyH)
+	m.convoyPlanner.SetSize(detailW, bodyH)
+	m.analytics.SetSize(detailW, bodyH)
 	m.detail.AllIssues = m.issues
+	if m.showPlanner {
+		m.convoyPlanner.SetIssues(m.issues)
+	}
+	if m.showAnalytics {
+		m.refreshAnalytics()
+	}
 	detailIssueMap := data.BuildIssueMap(m.issues)
 	m.detail.IssueMap = detailIssueMap
 	m.detail.BlockingTypes = m.blockingTypes
@@ -3304,7 +3813,7 @@ func (m *Model) rebuildParade() {
 		AgentCount:       len(m.activeAgents),
 		TownStatus:       m.townStatus,
 		GasTownAvailable: m.orchestratorAvailable(),
-		ProblemCount:     len(m.allProblems()),
+		ProblemCount:     m.problemTracker.Unacked(time.Now()),
 		BeadOffset:       m.beadOffset,
 		CurrentIssueID:   m.currentIssueID,
 	}
@@ -3410,19 +3919,80 @@ func (m *Model) selectNearestSelectable(hintCursor int) {
 }
 
 // recomputeVelocity recalculates velocity metrics and scorecards from current data
-// and pushes them to the Gas Town panel (only when visible).
-func (m *Model) recomputeVelocity() {
+// and pushes them to the Gas Town panel (only when visible). The forecasts are
+// too slow for Update, so it returns the Cmd that refreshes them, if any.
+func (m *Model) recomputeVelocity() tea.Cmd {
 	if !m.showGasTown {
-		return
+		return nil
 	}
 	v := gastown.ComputeVelocity(m.issues, m.townStatus, m.gasTown.GetCosts())
 	m.gasTown.SetVelocity(v)
 
-	cards := gastown.ComputeScorecards(m.issues)
-	m.gasTown.SetScorecards(cards)
+	m.recomputeScorecards()
 
 	preds := gastown.PredictConvoys(m.gasTown.GetConvoys(), v)
 	m.gasTown.SetPredictions(preds)
+
+	return m.refreshForecasts()
+}
+
+// forecastsMsg carries forecasts computed off the update loop, tagged with
+// the seed of the inputs they were computed from.
+type forecastsMsg struct {
+	seed      uint64
+	forecasts []gastown.Forecast
+}
+
+// refreshForecasts starts a Monte Carlo forecast run when the issues,
+// convoys or query changed since the last one. The run is seeded from its
+// inputs so unchanged data keeps its dates from poll to poll.
+func (m *Model) refreshForecasts() tea.Cmd {
+	if !m.showGasTown {
+		return nil
+	}
+	query := strings.TrimSpace(m.filterInput.Value())
+	issues, convoys, now := slices.Clone(m.issues), slices.Clone(m.gasTown.GetConvoys()), time.Now()
+	seed := gastown.ForecastSeed(issues, convoys, query, now)
+	if seed == m.forecastSeed {
+		return nil
+	}
+	m.forecastSeed = seed
+	return func() tea.Msg {
+		opts := gastown.ForecastOptions{Seed: seed}
+		return forecastsMsg{seed: seed, forecasts: gastown.Forecasts(issues, convoys, query, opts, now)}
+	}
+}
+
+// recordReopens notes closed issues that issues shows open again, before the
+// previous snapshot is replaced, and returns a Cmd appending them to the
+// reopen history.
+func (m *Model) recordReopens(issues []data.Issue) tea.Cmd {
+	reopened := gastown.DetectReopens(m.prevIssueMap, issues, time.Now())
+	if len(reopened) == 0 {
+		return nil
+	}
+	m.reopens = append(m.reopens, reopened...)
+	path := m.reopenHistoryPath
+	if path == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := gastown.RecordReopens(path, reopened); err != nil {
+			logRoute(err.Error())
+		}
+		return nil
+	}
+}
+
+// recomputeScorecards rebuilds agent scorecards from issues, the roster,
+// costs, the problem history and the reopen history, and pushes them to the
+// Gas Town panel.
+func (m *Model) recomputeScorecards() {
+	if !m.showGasTown {
+		return
+	}
+	cards := gastown.ComputeScorecardsFrom(m.issues, m.townStatus, m.gasTown.GetCosts(), m.problemTracker.Entries(), m.reopens, time.Now())
+	m.gasTown.SetScorecards(cards)
 }
 
 // propagateAgentState pushes active agent info to all sub-views.
@@ -3432,6 +4002,8 @@ func (m *Model) propagateAgentState() {
 	m.detail.ActiveAgents = m.activeAgents
 	m.detail.TownStatus = m.townStatus
 	m.header.AgentCount = len(m.activeAgents)
+	m.header.QueuedAgents = len(m.supervisor.Queue())
+	m.detail.AgentQueue = m.supervisor.Queue()
 	m.header.TownStatus = m.townStatus
 	m.header.GasTownAvailable = m.orchestratorAvailable()
 
@@ -3439,6 +4011,7 @@ func (m *Model) propagateAgentState() {
 	m.parade.OrphanedIDs = buildOrphanedIDs(m.townStatus)
 	// Build zombie issue ID set (dead sessions, not full dead rigs)
 	m.parade.ZombieIDs = buildZombieIDs(m.townStatus, m.parade.OrphanedIDs)
+	m.recomputeIssueCosts()
 
 	if m.detail.Issue != nil {
 		m.detail.SetIssue(m.detail.Issue)
@@ -3499,6 +4072,9 @@ func (m *Model) gatedPollAgentState() tea.Cmd {
 		if cmd := m.gatedPollPatrolScan(); cmd != nil {
 			cmds = append(cmds, cmd)
 		}
+		if cmd := m.gatedPollCosts(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 		if len(cmds) > 0 {
 			return tea.Batch(cmds...)
 		}
@@ -3554,6 +4130,34 @@ func (m *Model) gatedPollPatrolScan() tea.Cmd {
 	return m.pollPatrolScan
 }
 
+// recordPatrolScan adds a scan to the patrol history and the Gas Town
+// panel, returning a Cmd saving the history.
+func (m *Model) recordPatrolScan(scan gastown.PatrolScanResult) tea.Cmd {
+	m.patrolHistory = gastown.AddPatrolSample(m.patrolHistory, gastown.PatrolSample{At: time.Now(), PatrolScanResult: scan})
+	m.gasTown.SetPatrolHistory(m.patrolHistory)
+	return m.savePatrolHistory()
+}
+
+// patrolHistorySavedMsg reports that a patrol history save finished.
+type patrolHistorySavedMsg struct{}
+
+// savePatrolHistory returns a Cmd writing the patrol history, or nil when
+// there is nowhere to save or a save is already running, in which case it is
+// repeated once that one finishes.
+func (m *Model) savePatrolHistory() tea.Cmd {
+	path := m.patrolHistoryPath
+	if path == "" || !m.patrolSave.start() {
+		return nil
+	}
+	history := m.patrolHistory
+	return func() tea.Msg {
+		if err := gastown.SavePatrolHistory(path, history); err != nil {
+			logRoute("patrol history: " + err.Error())
+		}
+		return patrolHistorySavedMsg{}
+	}
+}
+
 func (m Model) pollPatrolScan() tea.Msg {
 	scan, err := m.driver.PatrolScan(context.Background())
 	return patrolScanMsg{scan: scan, err: err}
@@ -3616,8 +4220,8 @@ func (m Model) fetchActivity() tea.Msg {
 		return activityMsg{}
 	}
 	path := gastown.EventsPath()
-	events, err := gastown.LoadRecentEvents(path, 20)
-	return activityMsg{events: events, err: err}
+	history, err := gastown.LoadRecentEvents(path, eventHistoryLimit)
+	return activityMsg{events: history[:min(len(history), activityFeedLimit)], history: history, err: err}
 }
 
 func (m Model) fetchVitals() tea.Msg {
@@ -3676,12 +4280,23 @@ func (m Model) View() tea.View {
 	} else {
 		var rightPanel string
 		switch {
+		case m.showCodexSessions:
+			m.codexSessionList.SetRows(m.codexSessionRows())
+			rightPanel = m.codexSessionList.View()
 		case m.showCodex:
 			rightPanel = m.codexTranscript.View()
+		case m.showPlanner && m.orchestratorAvailable():
+			rightPanel = m.convoyPlanner.View()
+		case m.showAnalytics:
+			rightPanel = m.analytics.View()
 		case m.showDoctor:
 			rightPanel = m.doctor.View()
 		case m.showProblems && m.orchestratorAvailable():
 			rightPanel = m.problems.View()
+		case m.showGasTown && m.showTranscript && m.orchestratorAvailable():
+			rightPanel = m.agentTranscript.View()
+		case m.showGasTown && m.showConvoyTimeline && m.orchestratorAvailable():
+			rightPanel = m.convoyGantt.View()
 		case m.showGasTown && m.orchestratorAvailable():
 			if m.gasTownLoading() {
 				m.gasTown.SetLoadingFrame(m.spinner.View())
@@ -3813,6 +4428,20 @@ func (m Model) View() tea.View {
 		return altView(lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, rdBox))
 	}
 
+	if m.previewingPrompt {
+		ppWidth := m.width - 8
+		ppTitle := ui.HelpTitle.Width(ppWidth - 4).Render("[ AGENT PROMPT ]")
+		ppBody := m.promptPreview.View()
+		ppHint := ui.HelpHint.Width(ppWidth - 4).Render("edit for this run · ctrl+s launch · esc cancel")
+		ppContent := lipgloss.JoinVertical(lipgloss.Left, ppTitle, "", ppBody, "", ppHint)
+		ppBox := ui.OverlayBox(ppContent, ppWidth)
+		return altView(lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, ppBox))
+	}
+
+	if m.confirmingSling {
+		return altView(lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.slingConfirmView()))
+	}
+
 	return altView(screen)
 }
 
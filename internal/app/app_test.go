@@ -1,6 +1,7 @@
 package app
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -127,6 +128,7 @@ func TestFileChangedMsgQueuesDetailRefetchesForPendingSelection(t *testing.T) {
 	got.gtEnv.Available = true
 	got.gtPollInFlight = true     // suppress gt status polling
 	got.patrolScanInFlight = true // suppress patrol scan polling
+	got.costsInFlight = true      // suppress costs polling
 	got.activeAgents["open-2"] = "Toast"
 	got.detail.CommentsIssueID = "open-1"
 	got.detail.Comments = []gastown.Comment{{Author: "alpha", Body: "cached"}}
@@ -366,3 +368,101 @@ func TestHelpOpensAfterLeavingFilteringMode(t *testing.T) {
 		t.Fatal("expected help overlay to close on esc")
 	}
 }
+
+func TestPatrolScanRecordsHistory(t *testing.T) {
+	m := setupModel(t)
+	m.patrolHistoryPath = filepath.Join(t.TempDir(), "patrol.jsonl")
+	m.problemHistoryPath = ""
+	m.patrolHistory = []gastown.PatrolSample{{At: time.Now().Add(-25 * time.Hour)}}
+	scan := &gastown.PatrolScanResult{Rig: "gastown", Zombies: gastown.PatrolFinding{Checked: 2, Found: 1}}
+
+	model, cmd := m.Update(patrolScanMsg{scan: scan})
+	m = model.(Model)
+	if len(m.patrolHistory) != 1 || m.patrolHistory[0].Rig != "gastown" {
+		t.Fatalf("history = %+v, want the new scan with the day-old one dropped", m.patrolHistory)
+	}
+	if cmd == nil {
+		t.Fatal("a scan should be saved")
+	}
+	if batch, ok := cmd().(tea.BatchMsg); ok {
+		for _, c := range batch {
+			if c != nil {
+				c()
+			}
+		}
+	}
+	saved, err := gastown.LoadPatrolHistory(m.patrolHistoryPath, time.Time{})
+	if err != nil || len(saved) != 1 || saved[0].Zombies.Found != 1 {
+		t.Fatalf("saved history = %+v, %v", saved, err)
+	}
+
+	// The first save hasn't reported back, so the next scan's save waits
+	// for it and runs when it does.
+	if m.recordPatrolScan(*scan) != nil {
+		t.Fatal("a second save should wait for the first")
+	}
+	model, again := m.Update(patrolHistorySavedMsg{})
+	m = model.(Model)
+	if again == nil {
+		t.Fatal("the waiting save should run once the first finishes")
+	}
+	again()
+	if saved, _ := gastown.LoadPatrolHistory(m.patrolHistoryPath, time.Time{}); len(saved) != 2 {
+		t.Fatalf("saved %d scans, want 2", len(saved))
+	}
+}
+
+func TestForecastsRunOffTheUpdateLoopOnlyWhenDataChanges(t *testing.T) {
+	m := setupModel(t)
+	m.showGasTown = true
+
+	cmd := m.recomputeVelocity()
+	if cmd == nil {
+		t.Fatal("first recompute should start a forecast run")
+	}
+	if again := m.recomputeVelocity(); again != nil {
+		t.Fatal("unchanged data should not rerun the forecasts")
+	}
+	msg, ok := cmd().(forecastsMsg)
+	if !ok || msg.seed != m.forecastSeed {
+		t.Fatalf("forecast cmd returned %T seed %d, want seed %d", msg, msg.seed, m.forecastSeed)
+	}
+
+	m.issues = append(m.issues, testIssue("open-4", data.StatusOpen))
+	if m.recomputeVelocity() == nil {
+		t.Fatal("a new issue should rerun the forecasts")
+	}
+	if msg.seed == m.forecastSeed {
+		t.Fatal("the earlier run should now be stale")
+	}
+}
+
+func TestFileChangeRecordsReopens(t *testing.T) {
+	m := initModel(t)
+	m.reopens = nil
+	m.reopenHistoryPath = filepath.Join(t.TempDir(), "reopens.jsonl")
+
+	reopened := testIssue("closed-1", data.StatusInProgress)
+	reopened.Assignee = "Toast"
+	issues := []data.Issue{testIssue("open-1", data.StatusClosed), testIssue("open-2", data.StatusOpen), reopened}
+	model, _ := m.Update(data.FileChangedMsg{Issues: issues})
+	m = model.(Model)
+	if len(m.reopens) != 1 || m.reopens[0].IssueID != "closed-1" || m.reopens[0].Assignee != "Toast" {
+		t.Fatalf("reopens = %+v, want closed-1 by Toast", m.reopens)
+	}
+
+	// Seen again, the issue is no longer a reopen; closing and reopening it is.
+	if cmd := m.recordReopens(issues); cmd != nil || len(m.reopens) != 1 {
+		t.Fatalf("unchanged issues recorded a reopen: %+v", m.reopens)
+	}
+	m.prevIssueMap["closed-1"] = data.StatusClosed
+	cmd := m.recordReopens(issues)
+	if cmd == nil {
+		t.Fatal("expected a Cmd recording the reopen")
+	}
+	cmd()
+	saved, err := gastown.LoadReopens(m.reopenHistoryPath)
+	if err != nil || len(saved) != 1 || saved[0].IssueID != "closed-1" || len(m.reopens) != 2 {
+		t.Fatalf("saved %+v, %v; in memory %d", saved, err, len(m.reopens))
+	}
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/matt-wright86/mardi-gras/internal/agent"
+	"github.com/matt-wright86/mardi-gras/internal/codexmcp"
+	"github.com/matt-wright86/mardi-gras/internal/components"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+	"github.com/matt-wright86/mardi-gras/internal/views"
+)
+
+// auditApproval returns a Cmd appending a decision to the approval audit
+// log.
+func (m Model) auditApproval(issueID string, a codexmcp.ElicitApproval, decision, by, rule string) tea.Cmd {
+	path := m.approvalAuditPath
+	if path == "" {
+		return nil
+	}
+	entry := agent.NewApprovalAudit(time.Now(), issueID, a, decision, by, rule)
+	return func() tea.Msg {
+		if err := agent.AppendApprovalAudit(path, entry); err != nil {
+			logRoute("codex: " + err.Error())
+		}
+		return nil
+	}
+}
+
+// approvalSubject describes what codex asked to do, for toasts and the
+// transcript.
+func approvalSubject(a codexmcp.ElicitApproval) string {
+	if a.Kind == "patch" {
+		return fmt.Sprintf("patch to %d file(s)", len(a.Changes))
+	}
+	return strings.Join(a.Command, " ")
+}
+
+// applyApprovalRules answers a request from the approval rules. It reports
+// false when no rule matches and the request needs the dialog.
+func (m *Model) applyApprovalRules(sess *codexSession, msg codexApprovalRequestMsg) (tea.Cmd, bool) {
+	a := msg.approval
+	if a.Cwd == "" && sess.state != nil {
+		// Patch requests carry no cwd; rules scope them by the session's.
+		a.Cwd = sess.state.Cwd
+	}
+	rule, ok := m.approvalPolicy.Match(a)
+	if !ok {
+		return nil, false
+	}
+	decision := rule.ReviewDecision()
+	what := "approved"
+	level := components.ToastInfo
+	if rule.Decision == agent.ApprovalDeny {
+		what = "denied"
+		level = components.ToastWarn
+	}
+	if sess.state != nil {
+		sess.state.AppendEntry(views.CodexTranscriptEntry{
+			At:    time.Now(),
+			Kind:  "info",
+			Title: fmt.Sprintf("%s %s by rule %q", what, approvalSubject(a), rule.Name),
+			Error: rule.Decision == agent.ApprovalDeny,
+		})
+		if m.isCodexShownFor(msg.issueID) {
+			m.codexTranscript.SetState(sess.state)
+		}
+	}
+	toast, toastCmd := components.ShowToast(
+		fmt.Sprintf("Codex %s: %s (rule %s)", what, approvalSubject(a), rule.Name),
+		level, toastDuration,
+	)
+	m.toast = toast
+	return tea.Batch(
+		codexRespondCmd(msg.issueID, sess.handle, msg.req, decision),
+		m.auditApproval(msg.issueID, a, decision, agent.ApprovedByRule, rule.Name),
+		toastCmd,
+	), true
+}
+
+// approvalProblems reports an unreadable approval rules file.
+func (m Model) approvalProblems() []gastown.Problem {
+	if m.approvalPolicyErr == nil {
+		return nil
+	}
+	return []gastown.Problem{{
+		Type:     "approval",
+		Detail:   m.approvalPolicyErr.Error(),
+		Severity: "warn",
+	}}
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matt-wright86/mardi-gras/internal/agent"
+	"github.com/matt-wright86/mardi-gras/internal/codexmcp"
+	"github.com/matt-wright86/mardi-gras/internal/views"
+)
+
+func TestApprovalRulesAnswerBeforeTheDialog(t *testing.T) {
+	dir := t.TempDir()
+	rules := filepath.Join(dir, "approvals.json")
+	if err := os.WriteFile(rules, []byte(`{"rules": [
+		{"name": "ci config", "decision": "deny", "files": [".github/**"]},
+		{"name": "tests", "decision": "approve", "command": ["go", "test", "**"]}
+	]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MG_APPROVAL_RULES", rules)
+	t.Setenv("MG_APPROVAL_AUDIT", filepath.Join(dir, "audit.jsonl"))
+
+	m := setupModel(t)
+	if m.approvalPolicy == nil || len(m.approvalPolicy.Rules) != 2 {
+		t.Fatalf("policy = %+v, err %v", m.approvalPolicy, m.approvalPolicyErr)
+	}
+	state := &views.CodexTranscriptState{IssueID: "open-1", Cwd: "/src/mg", Status: "running"}
+	m.codexSessions["open-1"] = &codexSession{state: state, handle: &agent.MCPHandle{}}
+
+	exec := codexmcp.ElicitApproval{Kind: "exec", Command: []string{"go", "test", "./..."}, Cwd: "/src/mg"}
+	model, _ := m.Update(codexApprovalRequestMsg{issueID: "open-1", approval: exec, ok: true})
+	m = model.(Model)
+	if m.approving {
+		t.Fatal("a matching rule shouldn't open the dialog")
+	}
+	if last := state.Entries[len(state.Entries)-1]; !strings.Contains(last.Title, `approved go test ./... by rule "tests"`) {
+		t.Errorf("transcript entry = %q", last.Title)
+	}
+
+	patch := codexmcp.ElicitApproval{Kind: "patch", Changes: map[string]json.RawMessage{"/src/mg/.github/ci.yml": nil}}
+	model, _ = m.Update(codexApprovalRequestMsg{issueID: "open-1", approval: patch, ok: true})
+	m = model.(Model)
+	if m.approving || !strings.Contains(m.toast.Message, "denied") {
+		t.Fatalf("approving=%v toast=%q", m.approving, m.toast.Message)
+	}
+
+	other := codexmcp.ElicitApproval{Kind: "exec", Command: []string{"make"}, Cwd: "/src/mg"}
+	model, _ = m.Update(codexApprovalRequestMsg{issueID: "open-1", approval: other, ok: true})
+	m = model.(Model)
+	if !m.approving {
+		t.Fatal("an unmatched request should go to the dialog")
+	}
+
+	if msg := m.auditApproval("open-1", other, "approved", agent.ApprovedByUser, "")(); msg != nil {
+		t.Fatalf("audit cmd returned %v", msg)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "audit.jsonl"))
+	if err != nil || !strings.Contains(string(raw), `"by":"user"`) {
+		t.Fatalf("audit log = %q, %v", raw, err)
+	}
+}
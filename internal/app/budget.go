@@ -0,0 +1,151 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/matt-wright86/mardi-gras/internal/components"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+	"github.com/matt-wright86/mardi-gras/internal/ui"
+)
+
+// costsPollTTL paces the background `gt costs` poll that keeps budgets and
+// the cost history current while the Gas Town panel is closed.
+const costsPollTTL = 5 * time.Minute
+
+// gatedPollCosts returns a Cmd to refresh costs when the driver reports
+// costs, no fetch is in flight, and the TTL has elapsed.
+func (m *Model) gatedPollCosts() tea.Cmd {
+	if !m.driver.Supports(gastown.FeatureCosts) || m.costsInFlight {
+		return nil
+	}
+	if !m.lastCostsFetch.IsZero() && time.Since(m.lastCostsFetch) < costsPollTTL {
+		return nil
+	}
+	m.costsInFlight = true
+	return m.fetchCosts
+}
+
+// applyBudgets re-measures the configured budgets against the latest costs,
+// refreshes the panel and Problems, and toasts any budget that has newly
+// exceeded or started projecting past its limit.
+func (m *Model) applyBudgets() tea.Cmd {
+	if m.budgets == nil {
+		return nil
+	}
+	now := time.Now()
+	m.budgetStatus = gastown.EvaluateBudgets(m.budgets, m.gasTown.GetCosts(), m.costHistory, now)
+	m.gasTown.SetBudgets(m.budgetStatus)
+	saveCmd := m.refreshProblems()
+
+	if m.budgetAlerted == nil {
+		m.budgetAlerted = make(map[string]bool)
+	}
+	var fresh []gastown.BudgetStatus
+	seen := make(map[string]bool, len(m.budgetStatus))
+	for _, s := range m.budgetStatus {
+		if !s.Exceeded() && !s.Overrun() {
+			continue
+		}
+		// Key on the state too, so crossing from projected to exceeded
+		// alerts a second time.
+		key := s.Label()
+		if s.Exceeded() {
+			key += " exceeded"
+		}
+		seen[key] = true
+		if !m.budgetAlerted[key] {
+			m.budgetAlerted[key] = true
+			fresh = append(fresh, s)
+		}
+	}
+	for key := range m.budgetAlerted {
+		if !seen[key] {
+			delete(m.budgetAlerted, key)
+		}
+	}
+	if len(fresh) == 0 {
+		return saveCmd
+	}
+
+	s := fresh[0]
+	text := fmt.Sprintf("%s budget projected to overrun ($%.2f of $%.2f)", s.Label(), s.Projected, s.Limit)
+	level := components.ToastInfo
+	if s.Exceeded() {
+		text = fmt.Sprintf("%s budget exceeded ($%.2f of $%.2f)", s.Label(), s.Spent, s.Limit)
+		level = components.ToastError
+	}
+	if len(fresh) > 1 {
+		text += fmt.Sprintf(" +%d more", len(fresh)-1)
+	}
+	toast, cmd := components.ShowToast(text, level, toastDuration)
+	m.toast = toast
+	return tea.Batch(cmd, saveCmd)
+}
+
+// budgetProblems reports budget overruns plus an unreadable budgets file.
+func (m Model) budgetProblems() []gastown.Problem {
+	problems := gastown.BudgetProblems(m.budgetStatus)
+	if m.budgetErr != nil {
+		problems = append(problems, gastown.Problem{
+			Type:     "budget",
+			Detail:   m.budgetErr.Error(),
+			Severity: "warn",
+		})
+	}
+	return problems
+}
+
+// guardSling holds a sling key for confirmation when budgets ask for it and
+// one is already exceeded. It reports whether the key was held.
+func (m *Model) guardSling(msg tea.KeyPressMsg) bool {
+	if m.budgetConfirmed || m.budgets == nil || !m.budgets.ConfirmSling {
+		return false
+	}
+	if len(gastown.ExceededBudgets(m.budgetStatus)) == 0 {
+		return false
+	}
+	m.confirmingSling = true
+	m.pendingSlingKey = msg
+	return true
+}
+
+// handleSlingConfirmKey answers the over-budget prompt: y/enter replays the
+// held sling key past the guard, n/esc drops it.
+func (m Model) handleSlingConfirmKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "y", "enter":
+		m.confirmingSling = false
+		m.budgetConfirmed = true
+		result, cmd := m.handleKey(m.pendingSlingKey)
+		next := result.(Model)
+		next.budgetConfirmed = false
+		return next, cmd
+	case "n", "esc":
+		m.confirmingSling = false
+		toast, cmd := components.ShowToast("Sling cancelled: over budget", components.ToastInfo, toastDuration)
+		m.toast = toast
+		return m, cmd
+	}
+	return m, nil
+}
+
+// slingConfirmView renders the over-budget confirmation box.
+func (m Model) slingConfirmView() string {
+	w := min(m.width-8, 64)
+	title := ui.HelpTitle.Width(w - 4).Render("[ OVER BUDGET ]")
+	var lines []string
+	for _, s := range gastown.ExceededBudgets(m.budgetStatus) {
+		lines = append(lines, fmt.Sprintf("%s: $%.2f of $%.2f", s.Label(), s.Spent, s.Limit))
+	}
+	body := lipgloss.NewStyle().Foreground(ui.StateBackoff).Render(strings.Join(lines, "\n"))
+	hint := ui.HelpHint.Width(w - 4).Render("y/enter to sling anyway  esc to cancel")
+	content := lipgloss.JoinVertical(lipgloss.Left, title, "", body, "", hint)
+	return ui.OverlayBox(content, w)
+}
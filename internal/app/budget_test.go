@@ -0,0 +1,97 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+)
+
+func overBudgetModel(t *testing.T) Model {
+	t.Helper()
+	m := setupModel(t)
+	m.gtEnv.Available = true
+	m.budgets = &gastown.BudgetConfig{BudgetLimit: gastown.BudgetLimit{Daily: 10}, ConfirmSling: true}
+	m.budgetStatus = []gastown.BudgetStatus{
+		{Scope: gastown.BudgetTown, Period: gastown.BudgetDaily, Limit: 10, Spent: 12, Projected: 30},
+	}
+	model, _ := m.Update(tea.KeyPressMsg{Code: ' ', Text: " "})
+	return model.(Model)
+}
+
+func TestSlingGuardHoldsKeyUntilConfirmed(t *testing.T) {
+	got := overBudgetModel(t)
+
+	model, _ := got.Update(tea.KeyPressMsg{Code: 'a', Text: "a"})
+	got = model.(Model)
+	if !got.confirmingSling {
+		t.Fatal("expected over-budget confirmation before slinging")
+	}
+	if got.parade.SelectionCount() == 0 {
+		t.Fatal("held sling should not consume the selection yet")
+	}
+	if view := ansi.Strip(got.View().Content); !strings.Contains(view, "OVER BUDGET") || !strings.Contains(view, "town daily") {
+		t.Fatalf("confirmation should list exceeded budgets:\n%s", view)
+	}
+
+	model, cmd := got.Update(tea.KeyPressMsg{Code: 'y', Text: "y"})
+	got = model.(Model)
+	if got.confirmingSling || got.budgetConfirmed {
+		t.Fatal("confirming should close the prompt and reset the bypass")
+	}
+	if cmd == nil || got.parade.SelectionCount() != 0 {
+		t.Fatal("confirming should replay the multi-sling")
+	}
+}
+
+func TestSlingGuardCancel(t *testing.T) {
+	got := overBudgetModel(t)
+
+	model, _ := got.Update(tea.KeyPressMsg{Code: 'a', Text: "a"})
+	model, _ = model.(Model).Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	got = model.(Model)
+	if got.confirmingSling {
+		t.Fatal("esc should close the prompt")
+	}
+	if got.parade.SelectionCount() == 0 {
+		t.Fatal("cancelled sling should leave the selection alone")
+	}
+}
+
+func TestSlingGuardOffWithoutConfirmSling(t *testing.T) {
+	got := overBudgetModel(t)
+	got.budgets.ConfirmSling = false
+
+	model, cmd := got.Update(tea.KeyPressMsg{Code: 'a', Text: "a"})
+	if model.(Model).confirmingSling || cmd == nil {
+		t.Fatal("without confirm_sling the sling should go straight through")
+	}
+}
+
+func TestCostsMsgAlertsOnceAndFeedsProblems(t *testing.T) {
+	m := setupModel(t)
+	m.costHistoryPath = "" // no history write; only the toast cmd is expected
+	m.budgets = &gastown.BudgetConfig{BudgetLimit: gastown.BudgetLimit{Daily: 10}}
+	costs := &gastown.CostsOutput{Total: gastown.CostTotal{Cost: 25}}
+
+	model, cmd := m.Update(costsMsg{costs: costs})
+	m = model.(Model)
+	if cmd == nil || !m.toast.Active() {
+		t.Fatal("exceeded budget should toast")
+	}
+	found := false
+	for _, p := range m.allProblems() {
+		if p.Type == "budget" && p.Severity == "error" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("exceeded budget should appear in Problems")
+	}
+
+	if _, cmd = m.Update(costsMsg{costs: costs}); cmd != nil {
+		t.Fatal("an unchanged overrun should not toast again")
+	}
+}
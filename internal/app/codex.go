@@ -2,7 +2,9 @@ package app
 
 import (
 	"context"
+	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"charm.land/bubbles/v2/textinput"
@@ -10,6 +12,7 @@ import (
 	"github.com/matt-wright86/mardi-gras/internal/agent"
 	"github.com/matt-wright86/mardi-gras/internal/codexmcp"
 	"github.com/matt-wright86/mardi-gras/internal/components"
+	"github.com/matt-wright86/mardi-gras/internal/mcp"
 	"github.com/matt-wright86/mardi-gras/internal/ui"
 	"github.com/matt-wright86/mardi-gras/internal/views"
 )
@@ -18,10 +21,22 @@ import (
 // to a specific issue. State holds the transcript surface; handle owns the
 // subprocess.
 type codexSession struct {
-	handle *agent.CodexMCPHandle
+	handle *agent.MCPHandle
 	state  *views.CodexTranscriptState
 }
 
+// label names the session's agent for toasts: the handle's, or for a
+// session restored from disk the one it was saved with.
+func (s *codexSession) label() string {
+	switch {
+	case s.handle != nil:
+		return s.handle.Label()
+	case s.state != nil && s.state.Agent != "":
+		return s.state.Agent
+	}
+	return "Codex"
+}
+
 // Codex MCP message types. They are scoped to the codex feature so app.go's
 // existing message dispatch stays uncluttered.
 
@@ -53,15 +68,16 @@ type codexDoneMsg struct {
 
 type codexReplyDispatchedMsg struct {
 	issueID string
-	sess    *codexmcp.Session
+	sess    agent.MCPSession
 }
 
-// codexApprovalRequestMsg lands when codex sends a server-initiated approval
-// request (exec or patch). ok is false when the request isn't a supported
-// exec/patch approval, in which case the handler auto-denies.
+// codexApprovalRequestMsg lands when the agent sends a server-initiated
+// approval request (a codex exec or patch, or another agent's elicitation).
+// ok is false when the request isn't a supported approval, in which case the
+// handler auto-denies.
 type codexApprovalRequestMsg struct {
 	issueID  string
-	req      codexmcp.ServerRequest
+	req      mcp.ServerRequest
 	approval codexmcp.ElicitApproval
 	ok       bool
 }
@@ -78,6 +94,13 @@ type codexReplyErrorMsg struct {
 	err     error
 }
 
+// codexResumedMsg lands when a saved session has been relaunched with
+// codex-reply against its stored thread.
+type codexResumedMsg struct {
+	issueID string
+	handle  *agent.MCPHandle
+}
+
 // codexNextEventCmd returns a tea.Cmd that reads the next event or terminal
 // result from the session.
 //
@@ -86,7 +109,8 @@ type codexReplyErrorMsg struct {
 // closes events). Go picks pseudo-randomly; if the closed-events branch wins,
 // we must still surface the terminal result instead of returning a sentinel
 // the handler would have to interpret.
-func codexNextEventCmd(issueID string, sess *codexmcp.Session, serverReqCh <-chan codexmcp.ServerRequest) tea.Cmd {
+func codexNextEventCmd(issueID string, sess agent.MCPSession, handle *agent.MCPHandle) tea.Cmd {
+	serverReqCh := handle.ServerRequests()
 	return func() tea.Msg {
 		select {
 		case ev, ok := <-sess.Events():
@@ -102,7 +126,7 @@ func codexNextEventCmd(issueID string, sess *codexmcp.Session, serverReqCh <-cha
 				res := <-sess.Done()
 				return codexDoneMsg{issueID: issueID, result: res}
 			}
-			approval, valid := codexmcp.ParseElicitApproval(req.Params)
+			approval, valid := handle.ParseApproval(req)
 			return codexApprovalRequestMsg{issueID: issueID, req: req, approval: approval, ok: valid}
 		case res := <-sess.Done():
 			return codexDoneMsg{issueID: issueID, result: res}
@@ -110,18 +134,12 @@ func codexNextEventCmd(issueID string, sess *codexmcp.Session, serverReqCh <-cha
 	}
 }
 
-// codexApprovalDecisionResult builds the JSON-RPC result object for an
-// elicitation/create approval reply. Decision is a codex ReviewDecision value
-// (e.g. "approved", "approved_for_session", "denied", "abort").
-func codexApprovalDecisionResult(decision string) map[string]any {
-	return map[string]any{"decision": decision}
-}
-
-// codexRespondCmd writes an approval decision back to codex on the request's
-// RawID, in a goroutine, surfacing the outcome as codexApprovalResolvedMsg.
-func codexRespondCmd(issueID string, handle *agent.CodexMCPHandle, req codexmcp.ServerRequest, decision string) tea.Cmd {
+// codexRespondCmd writes an approval decision back to the agent on the
+// request's RawID, in a goroutine, surfacing the outcome as
+// codexApprovalResolvedMsg.
+func codexRespondCmd(issueID string, handle *agent.MCPHandle, req mcp.ServerRequest, decision string) tea.Cmd {
 	return func() tea.Msg {
-		err := handle.Respond(req.RawID, codexApprovalDecisionResult(decision))
+		err := handle.Respond(req.RawID, handle.ApprovalResult(req, decision))
 		return codexApprovalResolvedMsg{issueID: issueID, err: err}
 	}
 }
@@ -129,24 +147,30 @@ func codexRespondCmd(issueID string, handle *agent.CodexMCPHandle, req codexmcp.
 // handleCodexApprovalRequest routes an inbound exec/patch approval request. It
 // always re-issues the event pump so the transcript keeps streaming while a modal
 // is up (codex emits events while waiting on the approval). Unsupported requests
-// are auto-denied; a second request that arrives while a modal is open is queued.
+// are auto-denied and the approval rules answer what they match; a second request
+// that reaches the modal while it is open is queued.
 func (m Model) handleCodexApprovalRequest(msg codexApprovalRequestMsg) (tea.Model, tea.Cmd) {
 	sess := m.codexSessions[msg.issueID]
 	if sess == nil || sess.handle == nil {
 		// Session gone — nothing to reply on, and re-pumping would be pointless.
 		return m, nil
 	}
-	rePump := codexNextEventCmd(msg.issueID, sess.handle.Session(), sess.handle.ServerRequests())
+	rePump := codexNextEventCmd(msg.issueID, sess.handle.Session(), sess.handle)
 
 	// Unknown / unsupported elicitation: deny so the agent loop doesn't stall.
 	if !msg.ok {
 		deny := codexRespondCmd(msg.issueID, sess.handle, msg.req, "denied")
 		toast, tcmd := components.ShowToast(
-			"Codex sent an unsupported approval request — auto-denied.",
+			sess.label()+" sent an unsupported approval request — auto-denied.",
 			components.ToastWarn, toastDuration,
 		)
 		m.toast = toast
-		return m, tea.Batch(rePump, deny, tcmd)
+		return m, tea.Batch(rePump, deny, tcmd, m.auditApproval(msg.issueID, msg.approval, "denied", agent.ApprovedByMg, ""))
+	}
+
+	// The approval rules answer what they match without asking.
+	if cmd, ok := m.applyApprovalRules(sess, msg); ok {
+		return m, tea.Batch(rePump, cmd)
 	}
 
 	// A modal is already up — queue this one behind it.
@@ -169,9 +193,23 @@ func (m Model) handleApprovalDialogResult(res components.ApprovalDialogResult) (
 
 	cur := m.currentApproval
 	sess := m.codexSessions[cur.issueID]
+	if len(res.Rejected) > 0 && sess != nil && sess.state != nil {
+		sess.state.AppendEntry(views.CodexTranscriptEntry{
+			At:    time.Now(),
+			Kind:  "info",
+			Title: "patch " + decision + ": rejected " + strings.Join(res.Rejected, ", "),
+			Error: true,
+		})
+		if m.isCodexShownFor(cur.issueID) {
+			m.codexTranscript.SetState(sess.state)
+		}
+	}
 	var respond tea.Cmd
 	if sess != nil && sess.handle != nil {
-		respond = codexRespondCmd(cur.issueID, sess.handle, cur.req, decision)
+		respond = tea.Batch(
+			codexRespondCmd(cur.issueID, sess.handle, cur.req, decision),
+			m.auditApproval(cur.issueID, cur.approval, decision, agent.ApprovedByUser, ""),
+		)
 	}
 
 	if len(m.pendingApprovals) > 0 {
@@ -186,28 +224,56 @@ func (m Model) handleApprovalDialogResult(res components.ApprovalDialogResult) (
 }
 
 // openApprovalDialog builds the modal for an approval request and marks the model
-// as approving. Pointer receiver — mutates dialog state in place.
+// as approving. Patch requests get their decoded diffs, with paths shown
+// relative to the session's directory. Pointer receiver — mutates dialog state
+// in place.
 func (m *Model) openApprovalDialog(msg codexApprovalRequestMsg) {
 	a := msg.approval
 	var files []string
+	var diffs []components.DiffFile
 	if a.Kind == "patch" {
-		files = make([]string, 0, len(a.Changes))
-		for p := range a.Changes {
-			files = append(files, p)
+		var cwd string
+		if sess := m.codexSessions[msg.issueID]; sess != nil && sess.state != nil {
+			cwd = sess.state.Cwd
+		}
+		for _, c := range a.FileChanges() {
+			files = append(files, c.Path)
+			diffs = append(diffs, components.DiffFile{
+				Path:     relToDir(cwd, c.Path),
+				Kind:     c.Kind,
+				MovePath: relToDir(cwd, c.MovePath),
+				Lines:    c.DiffLines(),
+			})
 		}
-		sort.Strings(files)
 	}
 	m.approving = true
 	m.currentApproval = msg
 	m.approvalDialog = components.NewApprovalDialog(
 		a.Kind, a.Message, a.Command, a.Cwd, a.Reason, files, m.width, m.height,
 	)
+	if sess := m.codexSessions[msg.issueID]; sess != nil {
+		m.approvalDialog.SetAgent(sess.label())
+	}
+	if len(diffs) > 0 {
+		m.approvalDialog.SetDiffs(diffs)
+	}
+}
+
+// relToDir shortens an absolute path inside dir to a relative one.
+func relToDir(dir, path string) string {
+	if dir == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
 }
 
 // codexReplyCmd invokes Handle.Reply in a goroutine and returns the
 // resulting tea.Msg (either codexReplyDispatchedMsg with the new session
 // or codexReplyErrorMsg).
-func codexReplyCmd(issueID, prompt string, handle *agent.CodexMCPHandle) tea.Cmd {
+func codexReplyCmd(issueID, prompt string, handle *agent.MCPHandle) tea.Cmd {
 	return func() tea.Msg {
 		// Use a generous context for the reply tools/call. Like the initial
 		// launch, the session itself uses context.Background() internally so
@@ -222,6 +288,25 @@ func codexReplyCmd(issueID, prompt string, handle *agent.CodexMCPHandle) tea.Cmd
 	}
 }
 
+// codexResumeCmd continues a saved session whose codex process is gone: it
+// spawns a fresh codex mcp-server and calls codex-reply on the stored thread.
+// Failures come back as codexReplyErrorMsg like any other reply.
+func codexResumeCmd(issueID, prompt, threadID, cwd string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
+		defer cancel()
+		handle, err := agent.LaunchCodexMCP(ctx, agent.LaunchCodexMCPOptions{
+			Prompt:     prompt,
+			ProjectDir: cwd,
+			ThreadID:   threadID,
+		})
+		if err != nil {
+			return codexReplyErrorMsg{issueID: issueID, err: err}
+		}
+		return codexResumedMsg{issueID: issueID, handle: handle}
+	}
+}
+
 // codexLaunchCmd kicks off the LaunchCodexMCP call in a goroutine. Codex's
 // initial handshake (the mcp_startup of sub-MCP servers) can take many
 // seconds — return codexLaunchedMsg only after the session is ready to
@@ -253,13 +338,96 @@ func codexLaunchCmd(issueID, prompt, projectDir, clientVersion, approvalPolicy s
 	}
 }
 
+// mcpAgentLaunchCmd launches a runtime that declares an MCP server and
+// streams it into the same transcript as codex, landing as codexLaunchedMsg.
+func mcpAgentLaunchCmd(issueID, prompt, projectDir string, spec agent.RuntimeSpec) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
+		defer cancel()
+		handle, err := agent.LaunchMCPAgent(ctx, agent.LaunchMCPAgentOptions{
+			Spec:       spec,
+			Prompt:     prompt,
+			ProjectDir: projectDir,
+		})
+		if err != nil {
+			return codexLaunchErrorMsg{issueID: issueID, err: err}
+		}
+		return codexLaunchedMsg{
+			issueID: issueID,
+			sess: &codexSession{
+				handle: handle,
+				state: &views.CodexTranscriptState{
+					IssueID: issueID,
+					Agent:   handle.Label(),
+					Cwd:     projectDir,
+					Status:  "running",
+					StartAt: time.Now(),
+				},
+			},
+		}
+	}
+}
+
+// mcpRuntime returns the active runtime's spec when it declares an MCP
+// server to be driven in-process.
+func (m Model) mcpRuntime() (agent.RuntimeSpec, bool) {
+	spec, ok := m.registry.Lookup(string(m.agentRuntime))
+	return spec, ok && spec.MCP != nil
+}
+
+// agentSessionCmd starts an in-process session for issueID: the active
+// runtime over its MCP server when it declares one, otherwise codex.
+// approvalPolicy only applies to codex.
+func (m Model) agentSessionCmd(issueID, prompt, dir, approvalPolicy string) tea.Cmd {
+	if spec, ok := m.mcpRuntime(); ok {
+		return mcpAgentLaunchCmd(issueID, prompt, dir, spec)
+	}
+	return codexLaunchCmd(issueID, prompt, dir, "", approvalPolicy)
+}
+
+// openAgentSession shows issueID's transcript and, unless a session is
+// already running there, starts one in dir. It is how `a` launches a
+// runtime with an MCP server: in-process instead of in tmux.
+func (m Model) openAgentSession(issueID, prompt, dir string) (tea.Model, tea.Cmd) {
+	m.showCodex = true
+	m.showCodexSessions = false
+	m.codexShownID = issueID
+	m.dismissCodexReply()
+	m.showGasTown = false
+	m.showProblems = false
+	m.showDoctor = false
+	m.showAnalytics = false
+	if sess := m.codexSessions[issueID]; sess != nil && sess.state != nil && sess.state.Status == "running" {
+		m.codexTranscript.SetState(sess.state)
+		return m, nil
+	}
+	spec, _ := m.mcpRuntime()
+	m.codexTranscript.SetState(&views.CodexTranscriptState{
+		IssueID: issueID,
+		Agent:   spec.DisplayLabel(),
+		Status:  "running",
+		StartAt: time.Now(),
+	})
+	return m, m.agentSessionCmd(issueID, prompt, dir, "on-request")
+}
+
 // isCodexShownFor returns true when the codex transcript overlay is visible
 // and currently showing the session for issueID. Used as a guard before
 // updating the displayed transcript state.
 func (m *Model) isCodexShownFor(issueID string) bool {
-	return m.showCodex &&
-		m.parade.SelectedIssue != nil &&
-		m.parade.SelectedIssue.ID == issueID
+	return m.showCodex && m.codexShownIssue() == issueID
+}
+
+// codexShownIssue returns the issue whose session the transcript overlay
+// shows: the one picked from the session list or by M, else the selection.
+func (m *Model) codexShownIssue() string {
+	if m.codexShownID != "" {
+		return m.codexShownID
+	}
+	if m.parade.SelectedIssue != nil {
+		return m.parade.SelectedIssue.ID
+	}
+	return ""
 }
 
 // dismissCodexReply clears any in-flight reply input state. Called whenever
@@ -289,16 +457,16 @@ func (m *Model) startCodexReply(issueID string) tea.Cmd {
 // a usable threadID, then opens the reply input. On gate failure it surfaces
 // a toast and leaves state untouched.
 func (m Model) openCodexReply() (tea.Model, tea.Cmd) {
-	issue := m.parade.SelectedIssue
-	if issue == nil {
+	issueID := m.codexShownIssue()
+	if issueID == "" {
 		return m, nil
 	}
-	reason := codexReplyGateReason(m.codexSessions[issue.ID])
+	reason := codexReplyGateReason(m.codexSessions[issueID])
 	if reason != "" {
 		cmd := m.codexReplyToast(reason)
 		return m, cmd
 	}
-	cmd := m.startCodexReply(issue.ID)
+	cmd := m.startCodexReply(issueID)
 	return m, cmd
 }
 
@@ -308,15 +476,17 @@ func codexReplyGateReason(sess *codexSession) string {
 	if sess == nil {
 		return "No codex session for this issue."
 	}
+	if sess.handle != nil && !sess.handle.CanReply() || sess.handle == nil && sess.state != nil && sess.state.Agent != "" {
+		return sess.label() + " takes no follow-up prompts."
+	}
 	if sess.state == nil || sess.state.ThreadID == "" {
 		return "Codex session not ready yet."
 	}
 	if sess.state.Status == "running" {
 		return "Codex turn still running."
 	}
-	if sess.handle == nil {
-		return "Session handle is closed — press M to relaunch."
-	}
+	// A saved session has no handle; the reply relaunches codex on its
+	// stored thread.
 	return ""
 }
 
@@ -354,6 +524,7 @@ func finalizeCodexSession(sess *codexSession, res codexmcp.SessionResult) {
 		return
 	}
 	sess.state.Status = "done"
+	sess.state.Final = res.Content
 	if res.Content != "" {
 		sess.state.AppendEntry(views.CodexTranscriptEntry{
 			At:    now,
@@ -378,10 +549,11 @@ func closeAllCodexSessions(sessions map[string]*codexSession) {
 	}
 }
 
-// Cleanup terminates all codex MCP subprocesses owned by this model. Safe to
-// call after tea.Program.Run returns. Idempotent.
+// Cleanup terminates all codex MCP subprocesses owned by this model and saves
+// their transcripts. Safe to call after tea.Program.Run returns. Idempotent.
 func (m *Model) Cleanup() {
 	closeAllCodexSessions(m.codexSessions)
+	m.saveAllCodexSessions()
 }
 
 // toggleCodexTranscript is the M-key handler. It opens the codex transcript
@@ -400,9 +572,12 @@ func (m Model) toggleCodexTranscript() (tea.Model, tea.Cmd) {
 	}
 
 	m.showCodex = true
+	m.showCodexSessions = false
+	m.codexShownID = issue.ID
 	m.showGasTown = false
 	m.showProblems = false
 	m.showDoctor = false
+	m.showAnalytics = false
 
 	if sess, ok := m.codexSessions[issue.ID]; ok && sess != nil {
 		m.codexTranscript.SetState(sess.state)
@@ -410,15 +585,110 @@ func (m Model) toggleCodexTranscript() (tea.Model, tea.Cmd) {
 	}
 
 	// No session yet — spawn one.
-	deps := issue.EvaluateDependencies(m.detail.IssueMap, m.blockingTypes)
-	prompt := agent.BuildPrompt(*issue, deps, m.detail.IssueMap)
-	m.codexTranscript.SetState(&views.CodexTranscriptState{
+	prompt, template, err := m.agentPrompt(issue)
+	if err != nil {
+		m.showCodex = false
+		return m.promptErrorToast(template, err)
+	}
+	state := &views.CodexTranscriptState{
 		IssueID: issue.ID,
 		Status:  "running",
 		StartAt: time.Now(),
-	})
+	}
+	if spec, ok := m.mcpRuntime(); ok {
+		state.Agent = spec.DisplayLabel()
+	}
+	m.codexTranscript.SetState(state)
 	// M-key launches are human-present: use on-request so codex surfaces exec
 	// and apply-patch approvals through mg's modal instead of auto-approving.
 	// Polecat/gt-sling and tmux launches keep "never" (no human at the terminal).
-	return m, codexLaunchCmd(issue.ID, prompt, m.projectDir, "", "on-request")
+	return m, m.agentSessionCmd(issue.ID, prompt, m.projectDir, "on-request")
+}
+
+// codexAwaitingApproval reports whether issueID's session has an approval
+// request open or queued behind the modal.
+func (m Model) codexAwaitingApproval(issueID string) bool {
+	if m.approving && m.currentApproval.issueID == issueID {
+		return true
+	}
+	for _, p := range m.pendingApprovals {
+		if p.issueID == issueID {
+			return true
+		}
+	}
+	return false
+}
+
+// codexSessionRows describes every codex session for the session list,
+// oldest first.
+func (m Model) codexSessionRows() []views.CodexSessionRow {
+	rows := make([]views.CodexSessionRow, 0, len(m.codexSessions))
+	for id, sess := range m.codexSessions {
+		if sess == nil || sess.state == nil {
+			continue
+		}
+		st := sess.state
+		row := views.CodexSessionRow{
+			IssueID:     id,
+			Model:       st.Model,
+			State:       st.Status,
+			Tokens:      st.Tokens,
+			LastEventAt: st.LastEventAt,
+			Shown:       m.showCodex && id == m.codexShownIssue(),
+		}
+		if issue, ok := m.detail.IssueMap[id]; ok {
+			row.Title = issue.Title
+		}
+		if m.codexAwaitingApproval(id) {
+			row.State = "awaiting approval"
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := m.codexSessions[rows[i].IssueID].state, m.codexSessions[rows[j].IssueID].state
+		if !a.StartAt.Equal(b.StartAt) {
+			return a.StartAt.Before(b.StartAt)
+		}
+		return rows[i].IssueID < rows[j].IssueID
+	})
+	return rows
+}
+
+// toggleCodexSessions is the S-key handler: it opens the list of codex
+// sessions in place of the detail pane for switching between transcripts.
+func (m Model) toggleCodexSessions() (tea.Model, tea.Cmd) {
+	if m.showCodexSessions {
+		m.showCodexSessions = false
+		return m, nil
+	}
+	if len(m.codexSessions) == 0 {
+		toast, cmd := components.ShowToast("No codex sessions yet — press M on an issue to launch one.", components.ToastInfo, toastDuration)
+		m.toast = toast
+		return m, cmd
+	}
+	m.showCodexSessions = true
+	m.showCodex = false
+	m.dismissCodexReply()
+	m.showGasTown = false
+	m.showProblems = false
+	m.showDoctor = false
+	m.showAnalytics = false
+	m.showPlanner = false
+	m.codexSessionList.SetRows(m.codexSessionRows())
+	return m, nil
+}
+
+// showCodexSession switches the transcript overlay to issueID's session
+// without moving the parade selection.
+func (m Model) showCodexSession(issueID string) (tea.Model, tea.Cmd) {
+	sess, ok := m.codexSessions[issueID]
+	if !ok || sess == nil {
+		return m, nil
+	}
+	m.showCodexSessions = false
+	m.showCodex = true
+	m.codexShownID = issueID
+	m.dismissCodexReply()
+	m.codexTranscript.SetState(sess.state)
+	return m, nil
 }
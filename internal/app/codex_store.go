@@ -0,0 +1,196 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/matt-wright86/mardi-gras/internal/components"
+	"github.com/matt-wright86/mardi-gras/internal/config"
+	"github.com/matt-wright86/mardi-gras/internal/data"
+	"github.com/matt-wright86/mardi-gras/internal/views"
+)
+
+// codexTranscriptsDir returns where codex MCP transcripts are saved:
+// MG_CODEX_TRANSCRIPTS if set, else mardi-gras/codex/<project key> under the
+// user config directory, so they stay out of the working tree. With no
+// config directory it falls back to .mardi-gras/codex in the project, and
+// inTree is true. It returns "" when there is no project to save for.
+func codexTranscriptsDir(projectDir string) (dir string, inTree bool) {
+	if p := os.Getenv("MG_CODEX_TRANSCRIPTS"); p != "" {
+		return p, false
+	}
+	if projectDir == "" {
+		return "", false
+	}
+	if p := config.Path("", filepath.Join("codex", codexProjectKey(projectDir))); p != "" {
+		return p, false
+	}
+	return filepath.Join(projectDir, ".mardi-gras", "codex"), true
+}
+
+// codexProjectKey names a project's transcript directory: its base name,
+// for people browsing the directory, and a hash of its absolute path, so
+// two checkouts with the same name don't share transcripts.
+func codexProjectKey(projectDir string) string {
+	if abs, err := filepath.Abs(projectDir); err == nil {
+		projectDir = abs
+	}
+	sum := sha256.Sum256([]byte(projectDir))
+	return filepath.Base(projectDir) + "-" + hex.EncodeToString(sum[:6])
+}
+
+// saveCodexTranscript writes one issue's transcript to <dir>/<issue>.json,
+// atomically, so concurrent saves of the same issue can't interleave. An
+// in-tree dir gets a .gitignore on first save.
+func saveCodexTranscript(dir string, inTree bool, state views.CodexTranscriptState) error {
+	if dir == "" {
+		return fmt.Errorf("codex transcript: no directory")
+	}
+	if state.IssueID == "" || strings.ContainsAny(state.IssueID, `/\`) || strings.HasPrefix(state.IssueID, ".") {
+		return fmt.Errorf("codex transcript: bad issue ID %q", state.IssueID)
+	}
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("codex transcript: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("codex transcript: %w", err)
+	}
+	if inTree {
+		ignore := filepath.Join(dir, ".gitignore")
+		if _, err := os.Stat(ignore); os.IsNotExist(err) {
+			if err := os.WriteFile(ignore, []byte("*\n"), 0o644); err != nil {
+				return fmt.Errorf("codex transcript: %w", err)
+			}
+		}
+	}
+	if err := config.WriteFileAtomic(filepath.Join(dir, state.IssueID+".json"), append(b, '\n')); err != nil {
+		return fmt.Errorf("codex transcript: %w", err)
+	}
+	return nil
+}
+
+// loadCodexTranscripts reads every saved transcript in dir, keyed by issue.
+// A missing directory is not an error. Sessions saved mid-turn come back
+// canceled, since their codex process died with the previous mg.
+func loadCodexTranscripts(dir string) (map[string]*views.CodexTranscriptState, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("codex transcripts: %w", err)
+	}
+	out := make(map[string]*views.CodexTranscriptState, len(paths))
+	var bad []string
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			bad = append(bad, filepath.Base(path))
+			continue
+		}
+		var st views.CodexTranscriptState
+		if err := json.Unmarshal(b, &st); err != nil || st.IssueID == "" {
+			bad = append(bad, filepath.Base(path))
+			continue
+		}
+		if st.Status == "running" {
+			st.Status = "canceled"
+			if st.EndAt.IsZero() {
+				st.EndAt = st.LastEventAt
+			}
+		}
+		out[st.IssueID] = &st
+	}
+	if len(bad) > 0 {
+		return out, fmt.Errorf("codex transcripts: unreadable %s in %s", strings.Join(bad, ", "), dir)
+	}
+	return out, nil
+}
+
+// restoredCodexSessions turns saved transcripts into sessions without a
+// handle; codex-reply brings them back to life.
+func restoredCodexSessions(states map[string]*views.CodexTranscriptState) map[string]*codexSession {
+	sessions := make(map[string]*codexSession, len(states))
+	for id, st := range states {
+		sessions[id] = &codexSession{state: st}
+	}
+	return sessions
+}
+
+// snapshotCodexTranscript copies a session's state so it can be written
+// while the event loop keeps appending to the original.
+func snapshotCodexTranscript(sess *codexSession) (views.CodexTranscriptState, bool) {
+	if sess == nil || sess.state == nil {
+		return views.CodexTranscriptState{}, false
+	}
+	st := *sess.state
+	st.Entries = slices.Clone(st.Entries)
+	return st, true
+}
+
+// persistCodexSession returns a Cmd saving the issue's transcript.
+func (m Model) persistCodexSession(issueID string) tea.Cmd {
+	dir, inTree := codexTranscriptsDir(m.projectDir)
+	st, ok := snapshotCodexTranscript(m.codexSessions[issueID])
+	if dir == "" || !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := saveCodexTranscript(dir, inTree, st); err != nil {
+			logRoute("codex: " + err.Error())
+		}
+		return nil
+	}
+}
+
+// saveAllCodexSessions writes every transcript synchronously. Called on
+// quit, after the sessions have been closed.
+func (m *Model) saveAllCodexSessions() {
+	dir, inTree := codexTranscriptsDir(m.projectDir)
+	if dir == "" {
+		return
+	}
+	for id, sess := range m.codexSessions {
+		st, ok := snapshotCodexTranscript(sess)
+		if !ok {
+			continue
+		}
+		if st.Status == "running" {
+			st.Status = "canceled"
+			st.EndAt = time.Now()
+		}
+		if err := saveCodexTranscript(dir, inTree, st); err != nil {
+			logRoute("codex: " + id + ": " + err.Error())
+		}
+	}
+}
+
+// attachCodexTranscript posts the selected issue's codex session summary as
+// a comment on the issue.
+func (m Model) attachCodexTranscript() (tea.Model, tea.Cmd) {
+	issue := m.parade.SelectedIssue
+	if issue == nil {
+		return m, nil
+	}
+	st, ok := snapshotCodexTranscript(m.codexSessions[issue.ID])
+	if !ok {
+		toast, cmd := components.ShowToast("No codex transcript for "+issue.ID, components.ToastWarn, toastDuration)
+		m.toast = toast
+		return m, cmd
+	}
+	id := issue.ID
+	return m, func() tea.Msg {
+		err := data.AddComment(id, st.Summary())
+		return mutateResultMsg{issueID: id, action: "codex transcript attached", err: err}
+	}
+}
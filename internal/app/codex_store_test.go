@@ -0,0 +1,138 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/matt-wright86/mardi-gras/internal/views"
+)
+
+func TestCodexTranscriptRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	done := views.CodexTranscriptState{
+		IssueID: "mg-1", ThreadID: "thr-1", Model: "gpt-5", Cwd: "/src/mg", Status: "done",
+		StartAt: start, EndAt: start.Add(time.Minute), Final: "All tests pass.", Tokens: 1200,
+		Entries: []views.CodexTranscriptEntry{{At: start, Kind: "exec", Title: "$ go test ./..."}},
+	}
+	running := views.CodexTranscriptState{IssueID: "mg-2", ThreadID: "thr-2", Status: "running", StartAt: start, LastEventAt: start.Add(time.Second)}
+	for _, st := range []views.CodexTranscriptState{done, running} {
+		if err := saveCodexTranscript(dir, false, st); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := saveCodexTranscript(dir, false, views.CodexTranscriptState{IssueID: "../escape"}); err == nil {
+		t.Error("an issue ID with a path separator should be rejected")
+	}
+
+	got, err := loadCodexTranscripts(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := got["mg-1"]; st == nil || st.ThreadID != "thr-1" || st.Cwd != "/src/mg" || st.Final != "All tests pass." ||
+		len(st.Entries) != 1 || !st.EndAt.Equal(done.EndAt) || st.Tokens != 1200 {
+		t.Fatalf("mg-1 = %+v", got["mg-1"])
+	}
+	if st := got["mg-2"]; st == nil || st.Status != "canceled" || !st.EndAt.Equal(running.LastEventAt) {
+		t.Fatalf("a session saved mid-turn should load canceled: %+v", got["mg-2"])
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = loadCodexTranscripts(dir)
+	if err == nil || len(got) != 2 {
+		t.Fatalf("a corrupt file should be reported without losing the rest: %v, %d loaded", err, len(got))
+	}
+}
+
+func TestCodexTranscriptsRestoredAndSaved(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MG_CODEX_TRANSCRIPTS", dir)
+	if err := saveCodexTranscript(dir, false, views.CodexTranscriptState{IssueID: "open-1", ThreadID: "thr-9", Status: "done"}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := setupModel(t)
+	sess := m.codexSessions["open-1"]
+	if sess == nil || sess.handle != nil || sess.state.ThreadID != "thr-9" {
+		t.Fatalf("restored session = %+v", sess)
+	}
+
+	// Replying to a restored session relaunches codex on the stored thread.
+	m.showCodex = true
+	m.codexShownID = "open-1"
+	model, _ := m.Update(tea.KeyPressMsg{Code: 'r', Text: "r"})
+	m = model.(Model)
+	m.codexReplyInput.SetValue("pick it back up")
+	model, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = model.(Model)
+	if cmd == nil || m.codexSessions["open-1"].state.Status != "running" {
+		t.Fatalf("resume: cmd=%v status=%q", cmd != nil, m.codexSessions["open-1"].state.Status)
+	}
+
+	m.codexSessions["open-1"].state.Final = "resumed and finished"
+	m.Cleanup()
+	got, err := loadCodexTranscripts(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := got["open-1"]; st == nil || st.Final != "resumed and finished" || st.Status != "canceled" {
+		t.Fatalf("saved on cleanup = %+v", got["open-1"])
+	}
+}
+
+func TestCodexTranscriptsDirStaysOutOfTheProject(t *testing.T) {
+	t.Setenv("MG_CODEX_TRANSCRIPTS", "")
+	config := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+	t.Setenv("HOME", config)
+
+	a, inTree := codexTranscriptsDir("/src/one/mg")
+	b, _ := codexTranscriptsDir("/src/two/mg")
+	if inTree || !strings.HasPrefix(a, config) || a == b {
+		t.Fatalf("dirs = %q, %q (inTree %v); want distinct dirs under %s", a, b, inTree, config)
+	}
+	if dir, _ := codexTranscriptsDir(""); dir != "" {
+		t.Errorf("no project should mean no dir, got %q", dir)
+	}
+
+	project := t.TempDir()
+	dir := filepath.Join(project, ".mardi-gras", "codex")
+	if err := saveCodexTranscript(dir, true, views.CodexTranscriptState{IssueID: "mg-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if raw, err := os.ReadFile(filepath.Join(dir, ".gitignore")); err != nil || string(raw) != "*\n" {
+		t.Errorf("in-tree dir should be git-ignored: %q, %v", raw, err)
+	}
+}
+
+func TestCodexTranscriptConcurrentSaves(t *testing.T) {
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			st := views.CodexTranscriptState{IssueID: "mg-1", Final: strings.Repeat("x", 1000*(i+1))}
+			if err := saveCodexTranscript(dir, false, st); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := loadCodexTranscripts(dir)
+	if err != nil || got["mg-1"] == nil {
+		t.Fatalf("after concurrent saves: %v, %v", got, err)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(leftovers) > 0 {
+		t.Errorf("temp files left behind: %v", leftovers)
+	}
+}
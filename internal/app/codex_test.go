@@ -2,12 +2,17 @@ package app
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/matt-wright86/mardi-gras/internal/agent"
 	"github.com/matt-wright86/mardi-gras/internal/codexmcp"
+	"github.com/matt-wright86/mardi-gras/internal/components"
 	"github.com/matt-wright86/mardi-gras/internal/views"
 )
 
@@ -77,6 +82,38 @@ func TestCodexEventMsgAppendsToTranscript(t *testing.T) {
 	}
 }
 
+func TestCodexTranscriptKeysExpandWhenFocused(t *testing.T) {
+	got := setupModel(t)
+	issueID := got.parade.SelectedIssue.ID
+	state := &views.CodexTranscriptState{IssueID: issueID, Status: "running", StartAt: time.Now()}
+	got.codexSessions[issueID] = &codexSession{state: state}
+	for _, ev := range []map[string]any{
+		{"type": "exec_command_end", "exit_code": 0, "aggregated_output": "ok"},
+		{"type": "agent_message", "message": "done"},
+	} {
+		raw, _ := json.Marshal(ev)
+		state.AppendEvent(codexmcp.CodexEvent{Msg: raw})
+	}
+	model, _ := got.Update(tea.KeyPressMsg{Code: 'M', Text: "M"})
+	got = model.(Model)
+
+	// With the parade focused, k still moves the parade.
+	model, _ = got.Update(tea.KeyPressMsg{Code: 'k', Text: "k"})
+	got = model.(Model)
+	if !got.codexTranscript.Following() {
+		t.Fatal("k on the parade should not reach the transcript")
+	}
+
+	got.activPane = PaneDetail
+	for _, key := range []tea.KeyPressMsg{{Code: 'k', Text: "k"}, {Code: tea.KeyEnter}} {
+		model, _ = got.Update(key)
+		got = model.(Model)
+	}
+	if !state.Entries[0].Expanded {
+		t.Fatal("k then enter should expand the command output")
+	}
+}
+
 func TestKeyRGate(t *testing.T) {
 	const issueID = "open-1"
 	tests := []struct {
@@ -91,7 +128,7 @@ func TestKeyRGate(t *testing.T) {
 			overlay: true,
 			sess: &codexSession{
 				state:  &views.CodexTranscriptState{IssueID: issueID, ThreadID: "thr-test", Status: "done"},
-				handle: &agent.CodexMCPHandle{},
+				handle: &agent.MCPHandle{},
 			},
 			wantReplying: true,
 		},
@@ -100,7 +137,7 @@ func TestKeyRGate(t *testing.T) {
 			overlay: true,
 			sess: &codexSession{
 				state:  &views.CodexTranscriptState{IssueID: issueID, ThreadID: "thr-test", Status: "running"},
-				handle: &agent.CodexMCPHandle{},
+				handle: &agent.MCPHandle{},
 			},
 			wantReplying: false,
 		},
@@ -109,7 +146,23 @@ func TestKeyRGate(t *testing.T) {
 			overlay: true,
 			sess: &codexSession{
 				state:  &views.CodexTranscriptState{IssueID: issueID, Status: "done"},
-				handle: &agent.CodexMCPHandle{},
+				handle: &agent.MCPHandle{},
+			},
+			wantReplying: false,
+		},
+		{
+			name:    "saved session without a handle opens reply",
+			overlay: true,
+			sess: &codexSession{
+				state: &views.CodexTranscriptState{IssueID: issueID, ThreadID: "thr-test", Status: "canceled"},
+			},
+			wantReplying: true,
+		},
+		{
+			name:    "saved session of an agent without replies refuses",
+			overlay: true,
+			sess: &codexSession{
+				state: &views.CodexTranscriptState{IssueID: issueID, Agent: "Helper", Status: "done"},
 			},
 			wantReplying: false,
 		},
@@ -140,6 +193,63 @@ func TestKeyRGate(t *testing.T) {
 	}
 }
 
+// TestMCPRuntimeLaunchesInProcess checks that a runtime declaring an MCP
+// server opens the transcript instead of a tmux pane, and is queued as an
+// in-process session.
+func TestMCPRuntimeLaunchesInProcess(t *testing.T) {
+	runtimes := filepath.Join(t.TempDir(), "runtimes.json")
+	if err := os.WriteFile(runtimes, []byte(`{"runtimes":[{"name":"helper","label":"Helper","mcp":{"command":["helper","mcp"],"tool":"run"}}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MG_RUNTIMES", runtimes)
+	m := setupModel(t)
+	m.agentRuntime = "helper"
+	m.agentAvail = true
+	m.gtEnv.Available = false
+	m.inTmux = true
+
+	if backend, ok := m.supervisorBackend(); !ok || backend != agent.BackendCodex {
+		t.Fatalf("supervisorBackend = %q, %v", backend, ok)
+	}
+	model, cmd := m.launchAgentIn("open-1", "do it", "/work")
+	got := model.(Model)
+	if cmd == nil || !got.showCodex || got.codexShownID != "open-1" {
+		t.Fatalf("showCodex=%v shown=%q cmd=%v", got.showCodex, got.codexShownID, cmd != nil)
+	}
+	if out := got.codexTranscript.View(); !strings.Contains(out, "HELPER (MCP)") {
+		t.Errorf("transcript header should name the runtime:\n%s", out)
+	}
+}
+
+// TestRuntimesLoadOncePerSession checks that the runtimes file is read into
+// the model at startup and only re-read when the palette asks for it.
+func TestRuntimesLoadOncePerSession(t *testing.T) {
+	runtimes := filepath.Join(t.TempDir(), "runtimes.json")
+	if err := os.WriteFile(runtimes, []byte(`{"runtimes":[{"name":"helper","label":"Helper","mcp":{"command":["helper","mcp"],"tool":"run"}}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MG_RUNTIMES", runtimes)
+	m := setupModel(t)
+	m.agentRuntime = "helper"
+
+	if err := os.WriteFile(runtimes, []byte(`{"runtimes":[{"name":""}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.mcpRuntime(); !ok {
+		t.Fatal("an edited runtimes file should not take effect until reloaded")
+	}
+
+	model, _ := m.executePaletteAction(components.ActionReloadRuntimes)
+	got := model.(Model)
+	if got.runtimesErr == nil {
+		t.Fatal("reload should report the bad runtimes file")
+	}
+	got.agentRuntime = "helper"
+	if _, ok := got.mcpRuntime(); ok {
+		t.Error("reload should drop runtimes the file no longer defines")
+	}
+}
+
 // TestCodexReplyEnterDispatchesAndFlipsStatus drives the codexReplying
 // enter path: type a body, hit enter, observe state.Status flip to
 // "running" and codexReplying clear. Doesn't drive Handle.Reply itself —
@@ -156,7 +266,7 @@ func TestCodexReplyEnterDispatchesAndFlipsStatus(t *testing.T) {
 			Status:   "done",
 			StartAt:  time.Now(),
 		},
-		handle: &agent.CodexMCPHandle{},
+		handle: &agent.MCPHandle{},
 	}
 
 	model, _ := got.Update(tea.KeyPressMsg{Code: 'r', Text: "r"})
@@ -220,3 +330,74 @@ func TestCodexDoneMsgMarksSessionTerminal(t *testing.T) {
 		t.Fatal("EndAt should be set")
 	}
 }
+
+func TestCodexSessionListSwitchesTranscript(t *testing.T) {
+	got := setupModel(t)
+	model, _ := got.Update(tea.KeyPressMsg{Code: 'S', Text: "S"})
+	got = model.(Model)
+	if got.showCodexSessions {
+		t.Fatal("S without sessions should only toast")
+	}
+
+	start := time.Now()
+	for i, id := range []string{"first", "second"} {
+		got.codexSessions[id] = &codexSession{state: &views.CodexTranscriptState{
+			IssueID: id, Status: "running", StartAt: start.Add(time.Duration(i) * time.Second),
+		}}
+	}
+	model, _ = got.Update(tea.KeyPressMsg{Code: 'S', Text: "S"})
+	got = model.(Model)
+	if !got.showCodexSessions {
+		t.Fatal("S should open the session list")
+	}
+	got.approving = true
+	got.currentApproval = codexApprovalRequestMsg{issueID: "second"}
+	rows := got.codexSessionRows()
+	if len(rows) != 2 || rows[0].IssueID != "first" || rows[1].State != "awaiting approval" {
+		t.Fatalf("rows = %+v", rows)
+	}
+
+	got.approving = false
+	model, cmd := got.Update(tea.KeyPressMsg{Code: '2', Text: "2"})
+	got = model.(Model)
+	model, _ = got.Update(cmd())
+	got = model.(Model)
+	if got.showCodexSessions || !got.showCodex || !got.isCodexShownFor("second") || got.isCodexShownFor("first") {
+		t.Fatalf("switch: list=%v codex=%v shown=%q", got.showCodexSessions, got.showCodex, got.codexShownID)
+	}
+
+	// Events for the other session don't disturb the shown transcript.
+	raw, _ := json.Marshal(map[string]string{"type": "agent_message", "message": "busy"})
+	model, _ = got.Update(codexEventMsg{issueID: "first", ev: codexmcp.CodexEvent{Msg: raw}})
+	got = model.(Model)
+	if len(got.codexSessions["first"].state.Entries) != 1 || len(got.codexSessions["second"].state.Entries) != 0 {
+		t.Fatal("event should land on its own session only")
+	}
+}
+
+func TestPatchApprovalShowsDiffsAndRecordsRejections(t *testing.T) {
+	got := setupModel(t)
+	t.Setenv("MG_APPROVAL_AUDIT", filepath.Join(t.TempDir(), "audit.jsonl"))
+	got.approvalAuditPath = agent.ApprovalAuditPath()
+	state := &views.CodexTranscriptState{IssueID: "open-1", Cwd: "/src/mg", Status: "running"}
+	got.codexSessions["open-1"] = &codexSession{state: state, handle: &agent.MCPHandle{}}
+
+	patch := codexmcp.ElicitApproval{Kind: "patch", Changes: map[string]json.RawMessage{
+		"/src/mg/README.md": json.RawMessage(`{"type": "update", "unified_diff": "@@ -1 +1 @@\n-old\n+new\n"}`),
+	}}
+	model, _ := got.Update(codexApprovalRequestMsg{issueID: "open-1", approval: patch, ok: true})
+	got = model.(Model)
+	v := ansi.Strip(got.approvalDialog.View())
+	if !got.approving || !strings.Contains(v, "M README.md") || !strings.Contains(v, "+new") {
+		t.Fatalf("dialog:\n%s", v)
+	}
+
+	model, _ = got.Update(components.ApprovalDialogResult{Decision: "denied", Rejected: []string{"README.md"}})
+	got = model.(Model)
+	if got.approving {
+		t.Fatal("dialog should close")
+	}
+	if last := state.Entries[len(state.Entries)-1]; last.Title != "patch denied: rejected README.md" {
+		t.Errorf("transcript entry = %q", last.Title)
+	}
+}
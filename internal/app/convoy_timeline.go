@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+)
+
+// convoyTimelineMsg carries a freshly fetched convoy for the timeline overlay.
+// convoyID is echoed back so a late reply for a previously opened convoy is
+// dropped.
+type convoyTimelineMsg struct {
+	convoyID string
+	convoy   *gastown.ConvoyDetail
+	err      error
+}
+
+// openConvoyTimeline shows the timeline overlay for a convoy in place of the
+// Gas Town panel and fetches its current members.
+func (m Model) openConvoyTimeline(convoyID string) (tea.Model, tea.Cmd) {
+	c := gastown.ConvoyDetail{ID: convoyID}
+	for _, known := range m.gasTown.GetConvoys() {
+		if known.ID == convoyID {
+			c = known
+			break
+		}
+	}
+	m.showConvoyTimeline = true
+	m.showTranscript = false
+	m.convoyGantt.Open(c)
+	if len(c.Tracked) > 0 {
+		// Draw from the panel's copy while the fresh one is fetched.
+		m.convoyGantt.SetTimeline(m.buildConvoyTimeline(c))
+	}
+	return m, m.fetchConvoyTimeline(convoyID)
+}
+
+// fetchConvoyTimeline returns a Cmd reading one convoy's tracked members.
+func (m Model) fetchConvoyTimeline(convoyID string) tea.Cmd {
+	driver := m.driver
+	return func() tea.Msg {
+		c, err := driver.ConvoyStatus(context.Background(), convoyID)
+		return convoyTimelineMsg{convoyID: convoyID, convoy: c, err: err}
+	}
+}
+
+// buildConvoyTimeline places c's members on a time axis using the local
+// issue history, the agent roster and the current velocity.
+func (m Model) buildConvoyTimeline(c gastown.ConvoyDetail) gastown.ConvoyTimeline {
+	velocity := gastown.ComputeVelocity(m.issues, m.townStatus, m.gasTown.GetCosts())
+	return gastown.BuildConvoyTimeline(c, m.issues, m.townStatus, velocity, m.blockingTypes, time.Now())
+}
+
+func (m Model) handleConvoyTimeline(msg convoyTimelineMsg) (tea.Model, tea.Cmd) {
+	if !m.showConvoyTimeline || msg.convoyID != m.convoyGantt.ConvoyID() {
+		return m, nil
+	}
+	if msg.err != nil || msg.convoy == nil {
+		m.convoyGantt.SetError(msg.err)
+		return m, nil
+	}
+	m.convoyGantt.SetTimeline(m.buildConvoyTimeline(*msg.convoy))
+	return m, nil
+}
+
+// handleConvoyTimelineKey routes keys while the convoy timeline is focused.
+// esc returns to the Gas Town panel and R re-fetches the convoy; navigation
+// belongs to the view.
+func (m Model) handleConvoyTimelineKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd, bool) {
+	switch msg.String() {
+	case "esc":
+		m.showConvoyTimeline = false
+		return m, nil, true
+	case "R":
+		return m, m.fetchConvoyTimeline(m.convoyGantt.ConvoyID()), true
+	case "j", "k", "up", "down", "g", "G":
+		var cmd tea.Cmd
+		m.convoyGantt, cmd = m.convoyGantt.Update(msg)
+		return m, cmd, true
+	}
+	return m, nil, false
+}
+
+// convoyTimelineFocused reports whether the convoy timeline owns key input.
+func (m Model) convoyTimelineFocused() bool {
+	return m.showGasTown && m.showConvoyTimeline && m.activPane == PaneDetail
+}
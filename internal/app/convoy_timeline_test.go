@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/matt-wright86/mardi-gras/internal/data"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+	"github.com/matt-wright86/mardi-gras/internal/views"
+)
+
+// timelineDriver answers ConvoyStatus with a canned convoy and records the
+// id it was asked for. Other Driver methods are unused.
+type timelineDriver struct {
+	gastown.Driver
+	asked string
+}
+
+func (d *timelineDriver) ConvoyStatus(_ context.Context, id string) (*gastown.ConvoyDetail, error) {
+	d.asked = id
+	return &gastown.ConvoyDetail{ID: id, Title: "Sprint", Tracked: []gastown.TrackedIssueInfo{
+		{ID: "mg-1", Status: "open"},
+		{ID: "mg-2", Status: "in_progress"},
+	}}, nil
+}
+
+func TestOpenConvoyTimelineFetchesAndBuilds(t *testing.T) {
+	d := &timelineDriver{}
+	m := Model{showGasTown: true, activPane: PaneDetail, gtEnv: gastown.Env{Available: true}, driver: d}
+	m.convoyGantt = views.NewConvoyGantt(100, 20)
+	m.blockingTypes = data.DefaultBlockingTypes
+	m.issues = []data.Issue{
+		{ID: "mg-1", Status: data.StatusOpen,
+			Dependencies: []data.Dependency{{IssueID: "mg-1", DependsOnID: "mg-2", Type: "blocks"}}},
+		{ID: "mg-2", Status: data.StatusInProgress},
+	}
+
+	next, cmd := m.handleGasTownAction(views.GasTownActionMsg{Type: views.ActionConvoyTimeline, ConvoyID: "cv-1"})
+	m = next.(Model)
+	if !m.showConvoyTimeline || !m.convoyTimelineFocused() {
+		t.Fatal("convoy timeline should be open and focused")
+	}
+	if cmd == nil {
+		t.Fatal("expected a fetch cmd")
+	}
+	next, _ = m.Update(cmd())
+	m = next.(Model)
+	if d.asked != "cv-1" {
+		t.Errorf("fetched %q, want cv-1", d.asked)
+	}
+	sel := m.convoyGantt.Selected()
+	if sel == nil || sel.ID != "mg-1" || !sel.IsBlocked() {
+		t.Fatalf("selected member = %+v, want mg-1 blocked by mg-2", sel)
+	}
+
+	// A late reply for another convoy is dropped.
+	next, _ = m.handleConvoyTimeline(convoyTimelineMsg{convoyID: "cv-other"})
+	m = next.(Model)
+	if m.convoyGantt.Selected() == nil {
+		t.Error("stale reply must not clear the timeline")
+	}
+
+	next, _ = m.handleKey(tea.KeyPressMsg{Code: tea.KeyEscape})
+	m = next.(Model)
+	if m.showConvoyTimeline || !m.showGasTown {
+		t.Fatal("esc should close the timeline back to the Gas Town panel")
+	}
+}
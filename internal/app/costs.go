@@ -0,0 +1,99 @@
+package app
+
+import (
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+)
+
+// costHistoryMsg carries the recorded cost history after a sample is written
+// or the history is first read. recorded marks the reply to recordCosts.
+type costHistoryMsg struct {
+	samples  []gastown.CostSample
+	err      error
+	recorded bool
+}
+
+// recordCosts returns a Cmd that writes a costs reading to the cost history
+// and reads back the trend window. It is nil when there is nowhere to record.
+func (m *Model) recordCosts(costs *gastown.CostsOutput) tea.Cmd {
+	if costs == nil {
+		return nil
+	}
+	return m.recordCostSample(gastown.CostSample{At: time.Now(), CostsOutput: *costs})
+}
+
+// recordCostSample is recordCosts for a timestamped reading. RecordCostSample
+// rewrites the file, so overlapping writes could drop a sample: while one is
+// running the reading waits, replacing any older one waiting, and
+// handleCostHistory records it when the running write reports back.
+func (m *Model) recordCostSample(sample gastown.CostSample) tea.Cmd {
+	path := m.costHistoryPath
+	if path == "" {
+		return nil
+	}
+	if !m.costSave.start() {
+		m.pendingCostSample = &sample
+		return nil
+	}
+	return func() tea.Msg {
+		if err := gastown.RecordCostSample(path, sample); err != nil {
+			return costHistoryMsg{err: err, recorded: true}
+		}
+		msg := loadCostHistory(path).(costHistoryMsg)
+		msg.recorded = true
+		return msg
+	}
+}
+
+// loadCostHistoryCmd returns a Cmd that reads the cost history without
+// recording, for the analytics costs view opened before any costs fetch.
+func (m Model) loadCostHistoryCmd() tea.Cmd {
+	path := m.costHistoryPath
+	if path == "" {
+		return nil
+	}
+	return func() tea.Msg { return loadCostHistory(path) }
+}
+
+func loadCostHistory(path string) tea.Msg {
+	since := time.Now().AddDate(0, 0, -gastown.MaxCostHistoryDays)
+	samples, err := gastown.LoadCostHistory(path, since)
+	return costHistoryMsg{samples: samples, err: err}
+}
+
+// handleCostHistory stores the history, re-measures weekly budgets against
+// it, and refreshes the analytics costs view and per-issue costs.
+func (m Model) handleCostHistory(msg costHistoryMsg) (tea.Model, tea.Cmd) {
+	var next tea.Cmd
+	if msg.recorded && m.costSave.done() && m.pendingCostSample != nil {
+		pending := m.pendingCostSample
+		m.pendingCostSample = nil
+		next = m.recordCostSample(*pending)
+	}
+	if msg.err != nil {
+		logRoute("cost history: " + msg.err.Error())
+		return m, next
+	}
+	m.costHistory = msg.samples
+	m.analytics.SetCostHistory(m.costHistory)
+	m.recomputeIssueCosts()
+	if m.detail.Issue != nil {
+		m.detail.SetIssue(m.detail.Issue)
+	}
+	return m, tea.Batch(m.applyBudgets(), next)
+}
+
+// recomputeIssueCosts re-attributes spend to issues from the roster, event
+// log and cost history, and hands the result to the detail and Gas Town
+// panels. Callers re-render the detail pane if they need it current.
+func (m *Model) recomputeIssueCosts() {
+	costs := m.gasTown.GetCosts()
+	var attributed map[string]gastown.IssueCost
+	if costs != nil || len(m.costHistory) > 0 {
+		attributed = gastown.AttributeIssueCosts(m.issues, m.townStatus, m.eventHistory, m.costHistory, costs, time.Now())
+	}
+	m.detail.IssueCosts = attributed
+	m.gasTown.SetIssueCosts(attributed)
+}
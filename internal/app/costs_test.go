@@ -0,0 +1,108 @@
+package app
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+)
+
+func TestCostsMsgRecordsHistory(t *testing.T) {
+	m := setupModel(t)
+	m.costHistoryPath = filepath.Join(t.TempDir(), "costs.jsonl")
+	m.problemHistoryPath = ""
+
+	// A sample from earlier this week, unless today is Sunday.
+	now := time.Now()
+	if now.Weekday() != time.Sunday {
+		earlier := gastown.CostSample{At: now.AddDate(0, 0, -1)}
+		earlier.Total.Cost = 30
+		if err := gastown.RecordCostSample(m.costHistoryPath, earlier); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.budgets = &gastown.BudgetConfig{BudgetLimit: gastown.BudgetLimit{Weekly: 1000}}
+
+	costs := &gastown.CostsOutput{Total: gastown.CostTotal{Cost: 5}}
+	model, cmd := m.Update(costsMsg{costs: costs})
+	m = model.(Model)
+
+	hist := costHistoryFrom(t, cmd)
+	if hist.err != nil {
+		t.Fatalf("record = %#v", hist)
+	}
+	model, _ = m.Update(hist)
+	m = model.(Model)
+	if len(m.costHistory) == 0 || m.costHistory[len(m.costHistory)-1].Total.Cost != 5 {
+		t.Fatalf("history = %+v, want today's sample last", m.costHistory)
+	}
+
+	want := 5.0
+	if now.Weekday() != time.Sunday {
+		want = 35
+	}
+	if len(m.budgetStatus) != 1 || m.budgetStatus[0].Spent != want {
+		t.Fatalf("weekly budget = %+v, want spent %v", m.budgetStatus, want)
+	}
+}
+
+func TestCostSamplesRecordOneAtATime(t *testing.T) {
+	m := setupModel(t)
+	m.costHistoryPath = filepath.Join(t.TempDir(), "costs.jsonl")
+	m.problemHistoryPath = ""
+
+	first := m.recordCosts(&gastown.CostsOutput{Period: "last 24h", Total: gastown.CostTotal{Cost: 5}})
+	if first == nil {
+		t.Fatal("first reading should be recorded")
+	}
+	for _, cost := range []float64{6, 7} {
+		if m.recordCosts(&gastown.CostsOutput{Period: "last 24h", Total: gastown.CostTotal{Cost: cost}}) != nil {
+			t.Fatal("a reading should wait while a write is running")
+		}
+	}
+
+	model, cmd := m.Update(first())
+	m = model.(Model)
+	model, _ = m.Update(costHistoryFrom(t, cmd))
+	m = model.(Model)
+	var got []float64
+	for _, s := range m.costHistory {
+		got = append(got, s.Total.Cost)
+	}
+	// The waiting reading is recorded, replacing the first within the hour;
+	// the one it superseded while waiting never is.
+	if len(got) == 0 || got[len(got)-1] != 7 || slices.Contains(got, 6) {
+		t.Fatalf("history = %v, want the latest waiting reading last", got)
+	}
+	if m.costSave.inFlight || m.pendingCostSample != nil {
+		t.Errorf("nothing should be left waiting: %+v, %v", m.costSave, m.pendingCostSample)
+	}
+}
+
+// costHistoryFrom runs cmd, or each Cmd of a batch, and returns the
+// costHistoryMsg it produces.
+func costHistoryFrom(t *testing.T, cmd tea.Cmd) costHistoryMsg {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("no Cmd")
+	}
+	switch msg := cmd().(type) {
+	case costHistoryMsg:
+		return msg
+	case tea.BatchMsg:
+		for _, c := range msg {
+			if c == nil {
+				continue
+			}
+			if hist, ok := c().(costHistoryMsg); ok {
+				return hist
+			}
+		}
+	}
+	t.Fatal("Cmd produced no costHistoryMsg")
+	return costHistoryMsg{}
+}
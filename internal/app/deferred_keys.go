@@ -58,6 +58,16 @@ func (m Model) handleKeyPress(msg tea.KeyPressMsg, allowDeferredBuffer bool) (te
 		return m.handleFilteringKey(msg)
 	}
 
+	if m.plannerFocused() && m.convoyPlanner.Editing() {
+		logRoute("handlePlannerInputKey")
+		return m.handlePlannerInputKey(msg)
+	}
+
+	if m.transcriptFocused() && m.agentTranscript.Searching() {
+		logRoute("handleTranscriptSearchKey")
+		return m.handleTranscriptSearchKey(msg)
+	}
+
 	if allowDeferredBuffer && m.shouldDeferKey(msg) {
 		dbg("  DEFER staging key: %q (pendingCount=%d)", msg.String(), len(m.pendingKeys))
 		return m.handleDeferredKeyPress(msg)
@@ -322,6 +322,15 @@ func TestBuildZombieIDsNoZombies(t *testing.T) {
 	}
 }
 
+// namedDriver stands in for an out-of-process driver, which reports whatever
+// backend name its handshake gave.
+type namedDriver struct {
+	gastown.Driver
+	name string
+}
+
+func (d namedDriver) Backend() string { return d.name }
+
 func TestOrchestratorAvailable(t *testing.T) {
 	gc, err := gastown.NewGCDriver("http://127.0.0.1:8080", "")
 	if err != nil {
@@ -336,6 +345,7 @@ func TestOrchestratorAvailable(t *testing.T) {
 		{"no orchestrator", Model{gtEnv: gastown.Env{Available: false}, driver: gastown.NewGTDriver()}, false},
 		{"gas city only (no gt)", Model{gtEnv: gastown.Env{Available: false}, driver: gc}, true},
 		{"both gt and gc", Model{gtEnv: gastown.Env{Available: true}, driver: gc}, true},
+		{"exec driver only (no gt)", Model{gtEnv: gastown.Env{Available: false}, driver: namedDriver{name: "acme"}}, true},
 	}
 	for _, c := range cases {
 		if got := c.m.orchestratorAvailable(); got != c.want {
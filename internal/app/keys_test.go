@@ -1,6 +1,7 @@
 package app
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -621,3 +622,29 @@ func TestKeyAMultiSlingWithSelection(t *testing.T) {
 		t.Fatal("expected selection to be cleared after multi-sling")
 	}
 }
+
+// processDriverStub stands in for an MG_DRIVER=exec: scheduler.
+type processDriverStub struct {
+	playbookDriver
+}
+
+func (d *processDriverStub) Backend() string { return "acme" }
+
+func TestKeyASlingsThroughProcessDriverWithoutGT(t *testing.T) {
+	got := setupModel(t)
+	d := &processDriverStub{}
+	got.driver = d
+	got.gtEnv.Available = false
+	got.agentAvail = true
+	if got.launchesLocally() {
+		t.Fatal("a configured driver should take launches, not the local agent")
+	}
+
+	_, cmd := got.Update(tea.KeyPressMsg{Code: 'a', Text: "a"})
+	if cmd == nil {
+		t.Fatal("expected a sling cmd")
+	}
+	if _, ok := cmd().(slingResultMsg); !ok || len(d.calls) != 1 || !strings.HasPrefix(d.calls[0], "sling ") {
+		t.Fatalf("driver calls = %v", d.calls)
+	}
+}
@@ -0,0 +1,30 @@
+package app
+
+// saveGate keeps one save of a file in flight at a time. Tea runs Cmds
+// concurrently, so two saves started back to back could finish in either
+// order and leave the older snapshot on disk. A save asked for while one is
+// running marks the file dirty instead, and the running save's completion
+// starts another from the state at that point.
+type saveGate struct {
+	inFlight bool
+	dirty    bool
+}
+
+// start reports whether a save may begin now; if not, it marks the gate
+// dirty so the save is repeated when the running one finishes.
+func (g *saveGate) start() bool {
+	if g.inFlight {
+		g.dirty = true
+		return false
+	}
+	g.inFlight = true
+	return true
+}
+
+// done ends the running save and reports whether another is owed.
+func (g *saveGate) done() bool {
+	g.inFlight = false
+	again := g.dirty
+	g.dirty = false
+	return again
+}
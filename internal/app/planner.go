@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/matt-wright86/mardi-gras/internal/views"
+)
+
+// convoyAddResultMsg reports the outcome of adding planned issues to an
+// existing convoy.
+type convoyAddResultMsg struct {
+	convoyID string
+	count    int
+	err      error
+}
+
+// plannerSource picks the convoy planner's starting source: the parade's
+// multi-selection, else the active filter query, else the cursor issue as a
+// root. An empty source opens the planner on its source prompt.
+func (m Model) plannerSource() string {
+	if selected := m.parade.SelectedIssues(); len(selected) > 0 {
+		ids := make([]string, len(selected))
+		for i, iss := range selected {
+			ids[i] = iss.ID
+		}
+		return strings.Join(ids, " ")
+	}
+	if q := strings.TrimSpace(m.filterInput.Value()); q != "" {
+		return q
+	}
+	if m.parade.SelectedIssue != nil {
+		return m.parade.SelectedIssue.ID
+	}
+	return ""
+}
+
+// openConvoyPlanner shows the convoy planner in place of the detail pane,
+// seeded from plannerSource, and refreshes the convoy list it offers as
+// targets for adding.
+func (m Model) openConvoyPlanner() (tea.Model, tea.Cmd) {
+	if !m.orchestratorAvailable() {
+		return m, nil
+	}
+	source := m.plannerSource()
+	m.parade.ClearSelection()
+	m.showPlanner = true
+	m.showDoctor = false
+	m.showProblems = false
+	m.showAnalytics = false
+	m.showCodex = false
+	m.showCodexSessions = false
+	m.dismissCodexReply()
+	m.activPane = PaneDetail
+	cmd := m.convoyPlanner.Open(m.issues, m.blockingTypes, source, m.gasTown.GetConvoys())
+	return m, tea.Batch(cmd, m.fetchConvoyList)
+}
+
+// plannerFocused reports whether the convoy planner owns key input.
+func (m Model) plannerFocused() bool {
+	return m.showPlanner && m.activPane == PaneDetail
+}
+
+// handlePlannerKey routes keys while the planner is focused. esc closes it;
+// navigation, pruning and the create/add prompts belong to the view.
+func (m Model) handlePlannerKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd, bool) {
+	switch msg.String() {
+	case "esc":
+		m.showPlanner = false
+		m.activPane = PaneParade
+		return m, nil, true
+	case "j", "k", "up", "down", "g", "G", "space", "x", "X", "/", "enter", "c", "+":
+		var cmd tea.Cmd
+		m.convoyPlanner, cmd = m.convoyPlanner.Update(msg)
+		return m, cmd, true
+	}
+	return m, nil, false
+}
+
+// handlePlannerInputKey feeds every key to the planner's prompt while it has
+// focus, like the parade filter: printable keys (including "q" and "?") must
+// reach the input as literals.
+func (m Model) handlePlannerInputKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.convoyPlanner, cmd = m.convoyPlanner.Update(msg)
+	return m, cmd
+}
+
+// handleConvoyPlan closes the planner and creates the planned convoy, or adds
+// the planned issues to an existing one, through the active driver.
+func (m Model) handleConvoyPlan(msg views.ConvoyPlanMsg) (tea.Model, tea.Cmd) {
+	m.showPlanner = false
+	m.activPane = PaneParade
+	driver := m.driver
+	if msg.ConvoyID != "" {
+		return m, func() tea.Msg {
+			err := driver.ConvoyAdd(context.Background(), msg.ConvoyID, msg.IssueIDs)
+			return convoyAddResultMsg{convoyID: msg.ConvoyID, count: len(msg.IssueIDs), err: err}
+		}
+	}
+	return m, func() tea.Msg {
+		_, err := driver.ConvoyCreate(context.Background(), msg.Name, msg.IssueIDs)
+		return convoyCreateResultMsg{name: msg.Name, err: err}
+	}
+}
+
+func (m Model) handleConvoyAddResult(msg convoyAddResultMsg) (tea.Model, tea.Cmd) {
+	return m.toastResult(msg.err,
+		"Convoy add failed",
+		fmt.Sprintf("Added %d issue(s) to convoy %s", msg.count, msg.convoyID),
+		m.fetchConvoyList)
+}
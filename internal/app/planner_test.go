@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/matt-wright86/mardi-gras/internal/data"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+	"github.com/matt-wright86/mardi-gras/internal/views"
+)
+
+// plannerDriver records convoy create/add calls. Other Driver methods are
+// unused.
+type plannerDriver struct {
+	gastown.Driver
+	created, addedTo string
+	ids              []string
+}
+
+func (d *plannerDriver) ConvoyList(context.Context) ([]gastown.ConvoyDetail, error) { return nil, nil }
+
+func (d *plannerDriver) ConvoyCreate(_ context.Context, name string, ids []string) (string, error) {
+	d.created, d.ids = name, ids
+	return "cv-new", nil
+}
+
+func (d *plannerDriver) ConvoyAdd(_ context.Context, convoyID string, ids []string) error {
+	d.addedTo, d.ids = convoyID, ids
+	return nil
+}
+
+func plannerModel(d gastown.Driver) Model {
+	issues := []data.Issue{
+		{ID: "mg-1", Title: "Ship login", Status: data.StatusOpen,
+			Dependencies: []data.Dependency{{IssueID: "mg-1", DependsOnID: "mg-2", Type: "blocks"}}},
+		{ID: "mg-2", Title: "Session store", Status: data.StatusOpen},
+	}
+	m := Model{gtEnv: gastown.Env{Available: true}, driver: d, issues: issues, blockingTypes: data.DefaultBlockingTypes}
+	m.parade = views.NewParade(issues, 40, 20, data.DefaultBlockingTypes)
+	m.convoyPlanner = views.NewConvoyPlanner(80, 20)
+	return m
+}
+
+func TestPlannerSourcePrefersFilterOverCursor(t *testing.T) {
+	m := plannerModel(&plannerDriver{})
+	m.filterInput.SetValue("type:bug")
+	if got := m.plannerSource(); got != "type:bug" {
+		t.Errorf("plannerSource() = %q, want the active filter", got)
+	}
+	m.filterInput.SetValue("")
+	if got := m.plannerSource(); got != m.parade.SelectedIssue.ID {
+		t.Errorf("plannerSource() = %q, want the cursor issue", got)
+	}
+}
+
+func TestOpenConvoyPlannerFocusesOverlay(t *testing.T) {
+	m := plannerModel(&plannerDriver{})
+	m.showDoctor = true
+	next, cmd := m.openConvoyPlanner()
+	m = next.(Model)
+	if !m.showPlanner || !m.plannerFocused() || m.showDoctor {
+		t.Fatal("planner should replace the doctor overlay and take focus")
+	}
+	if cmd == nil {
+		t.Error("expected a convoy list refresh")
+	}
+
+	next, _, handled := m.handlePlannerKey(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m = next.(Model); !handled || m.showPlanner {
+		t.Error("esc should close the planner")
+	}
+}
+
+func TestOpenConvoyPlannerNeedsOrchestrator(t *testing.T) {
+	m := plannerModel(&plannerDriver{})
+	m.driver = gastown.GTDriver{}
+	m.gtEnv.Available = false
+	next, _ := m.openConvoyPlanner()
+	if next.(Model).showPlanner {
+		t.Error("planner opened with no orchestrator")
+	}
+}
+
+func TestConvoyPlanMsgRoutesToDriver(t *testing.T) {
+	d := &plannerDriver{}
+	m := plannerModel(d)
+	m.showPlanner = true
+
+	next, cmd := m.Update(views.ConvoyPlanMsg{ConvoyID: "cv-1", IssueIDs: []string{"mg-2", "mg-1"}})
+	if next.(Model).showPlanner {
+		t.Error("confirming a plan should close the planner")
+	}
+	res, ok := cmd().(convoyAddResultMsg)
+	if !ok || res.err != nil || res.count != 2 {
+		t.Fatalf("add result = %+v", res)
+	}
+	if d.addedTo != "cv-1" || len(d.ids) != 2 {
+		t.Errorf("ConvoyAdd(%q, %v), want cv-1 with both issues", d.addedTo, d.ids)
+	}
+
+	_, cmd = m.Update(views.ConvoyPlanMsg{Name: "Login", IssueIDs: []string{"mg-1"}})
+	if _, ok := cmd().(convoyCreateResultMsg); !ok || d.created != "Login" {
+		t.Errorf("ConvoyCreate name = %q, want Login", d.created)
+	}
+}
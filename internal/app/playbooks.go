@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/matt-wright86/mardi-gras/internal/components"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+)
+
+// playbookResultMsg reports one remediation a playbook ran (or, in a dry
+// run, would have run).
+type playbookResultMsg struct {
+	remediation gastown.Remediation
+	audit       gastown.PlaybookAudit
+}
+
+// runPlaybooks plans the remediations due for the active problems and
+// returns a Cmd running them through the driver, each appending to the
+// audit log. It returns nil when no playbooks are configured, the kill
+// switch is on, or nothing is due.
+func (m *Model) runPlaybooks(now time.Time) tea.Cmd {
+	if m.playbooks == nil || m.playbooksPaused || m.driver == nil {
+		return nil
+	}
+	plan := gastown.PlanRemediations(m.playbooks, m.problemTracker.Active(now), &m.playbookState, now)
+	if len(plan) == 0 {
+		return nil
+	}
+	driver := m.driver
+	auditPath := m.playbookAuditPath
+	cmds := make([]tea.Cmd, len(plan))
+	for i, r := range plan {
+		cmds[i] = func() tea.Msg {
+			err := r.Execute(context.Background(), driver)
+			audit := r.Audit(time.Now(), err)
+			if auditPath != "" {
+				if aerr := gastown.AppendPlaybookAudit(auditPath, audit); aerr != nil {
+					logRoute("playbook audit: " + aerr.Error())
+				}
+			}
+			return playbookResultMsg{remediation: r, audit: audit}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// handlePlaybookResult notes the remediation on its problem and toasts the
+// outcome; a live action also refreshes agent state to see its effect.
+func (m Model) handlePlaybookResult(msg playbookResultMsg) (tea.Model, tea.Cmd) {
+	if m.playbookLast == nil {
+		m.playbookLast = make(map[string]gastown.PlaybookAudit)
+	}
+	m.playbookLast[msg.audit.ProblemID] = msg.audit
+	m.problems.SetRemediations(m.playbookLast)
+
+	what := msg.remediation.Describe()
+	if msg.audit.DryRun {
+		toast, cmd := components.ShowToast("Playbook (dry run): would "+what, components.ToastInfo, toastDuration)
+		m.toast = toast
+		return m, cmd
+	}
+	if msg.audit.Skipped != "" {
+		toast, cmd := components.ShowToast(
+			fmt.Sprintf("Playbook skipped %s: %s", what, msg.audit.Skipped),
+			components.ToastWarn, toastDuration,
+		)
+		m.toast = toast
+		return m, cmd
+	}
+	if msg.audit.Error != "" {
+		toast, cmd := components.ShowToast(
+			fmt.Sprintf("Playbook failed to %s: %s", what, msg.audit.Error),
+			components.ToastError, toastDuration,
+		)
+		m.toast = toast
+		return m, cmd
+	}
+	pollCmd := m.gatedPollAgentState()
+	toast, cmd := components.ShowToast("Playbook: "+what, components.ToastSuccess, toastDuration)
+	m.toast = toast
+	return m, tea.Batch(cmd, pollCmd)
+}
+
+// togglePlaybooks is the palette kill switch: it stops or resumes automated
+// remediation for the rest of the session.
+func (m Model) togglePlaybooks() (tea.Model, tea.Cmd) {
+	m.playbooksPaused = !m.playbooksPaused
+	text := "Playbooks resumed"
+	level := components.ToastSuccess
+	if m.playbooksPaused {
+		text = "Playbooks paused: no automated remediation until resumed"
+		level = components.ToastWarn
+	}
+	toast, cmd := components.ShowToast(text, level, toastDuration)
+	m.toast = toast
+	return m, cmd
+}
+
+// playbookProblems reports an unreadable playbooks file.
+func (m Model) playbookProblems() []gastown.Problem {
+	if m.playbooksErr == nil {
+		return nil
+	}
+	return []gastown.Problem{{
+		Type:     "playbook",
+		Detail:   m.playbooksErr.Error(),
+		Severity: "warn",
+	}}
+}
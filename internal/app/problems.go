@@ -0,0 +1,109 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/matt-wright86/mardi-gras/internal/components"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+	"github.com/matt-wright86/mardi-gras/internal/views"
+)
+
+// problemSnooze is how long z hides a problem.
+const problemSnooze = time.Hour
+
+// refreshProblems feeds the current problem snapshot to the tracker, then
+// updates the header count and the Problems panel. It returns a Cmd saving
+// the problem history when a problem was raised, came back or resolved,
+// batched with any remediation the playbooks have due.
+func (m *Model) refreshProblems() tea.Cmd {
+	now := time.Now()
+	changed := m.problemTracker.Observe(m.allProblems(), now)
+	m.syncProblems(now)
+	playbookCmd := m.runPlaybooks(now)
+	if !changed {
+		return playbookCmd
+	}
+	return tea.Batch(m.saveProblemHistory(), playbookCmd)
+}
+
+// syncProblems pushes the tracker's state to the header and the panel.
+func (m *Model) syncProblems(now time.Time) {
+	m.header.ProblemCount = m.problemTracker.Unacked(now)
+	if m.showProblems {
+		m.problems.SetTracked(m.problemTracker.Active(now), m.problemTracker.Snoozed(now), m.problemTracker.History())
+	}
+}
+
+// problemHistorySavedMsg reports that a problem history save finished.
+type problemHistorySavedMsg struct{}
+
+// saveProblemHistory returns a Cmd writing the tracker's entries to the
+// problem history, or nil when there is nowhere to save or a save is already
+// running, in which case it is repeated once that one finishes.
+func (m *Model) saveProblemHistory() tea.Cmd {
+	path := m.problemHistoryPath
+	if path == "" || !m.problemSave.start() {
+		return nil
+	}
+	entries := m.problemTracker.Entries()
+	return func() tea.Msg {
+		if err := gastown.SaveProblemHistory(path, entries); err != nil {
+			logRoute("problem history: " + err.Error())
+		}
+		return problemHistorySavedMsg{}
+	}
+}
+
+// alertProblems evaluates the user's alert rules, plus a problem for an
+// unreadable rules file.
+func (m Model) alertProblems() []gastown.Problem {
+	problems := gastown.EvaluateAlertRules(m.alertRules, m.townStatus, m.issues, time.Now())
+	if m.alertRulesErr != nil {
+		problems = append(problems, gastown.Problem{
+			Type:     "rule",
+			Detail:   m.alertRulesErr.Error(),
+			Severity: "warn",
+		})
+	}
+	return problems
+}
+
+// handleProblemAction acknowledges or snoozes a tracked problem from the
+// Problems panel.
+func (m Model) handleProblemAction(msg views.ProblemActionMsg) (tea.Model, tea.Cmd) {
+	now := time.Now()
+	var text string
+	switch msg.Action {
+	case views.ProblemAck:
+		if !m.problemTracker.Ack(msg.ID) {
+			return m, nil
+		}
+		text = "Acknowledged #" + msg.ID
+		for _, p := range m.problemTracker.Active(now) {
+			if p.ID == msg.ID && !p.Acked {
+				text = "Unacknowledged #" + msg.ID
+			}
+		}
+	case views.ProblemSnooze:
+		until := now.Add(problemSnooze)
+		text = fmt.Sprintf("Snoozed #%s until %s", msg.ID, until.Format("15:04"))
+		for _, p := range m.problemTracker.Snoozed(now) {
+			if p.ID == msg.ID {
+				until = time.Time{}
+				text = "Woke #" + msg.ID
+			}
+		}
+		if !m.problemTracker.Snooze(msg.ID, until) {
+			return m, nil
+		}
+	default:
+		return m, nil
+	}
+	m.syncProblems(now)
+	toast, toastCmd := components.ShowToast(text, components.ToastInfo, toastDuration)
+	m.toast = toast
+	return m, tea.Batch(toastCmd, m.saveProblemHistory())
+}
@@ -0,0 +1,181 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/components"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+	"github.com/matt-wright86/mardi-gras/internal/views"
+)
+
+func TestTownStatusTracksProblemsAndAck(t *testing.T) {
+	m := setupModel(t)
+	m.problemTracker = gastown.ProblemTracker{}
+	m.problemHistoryPath = filepath.Join(t.TempDir(), "problems.jsonl")
+	status := &gastown.TownStatus{Agents: []gastown.AgentRuntime{
+		{Name: "Toast", Role: "polecat", Address: "gastown/polecats/Toast", HookBead: "open-1"},
+	}}
+
+	model, cmd := m.Update(townStatusMsg{status: status})
+	m = model.(Model)
+	if m.header.ProblemCount != 1 || cmd == nil {
+		t.Fatalf("zombie should count and save history: count %d cmd %v", m.header.ProblemCount, cmd)
+	}
+	active := m.problemTracker.Active(time.Now())
+	if len(active) != 1 || active[0].Type != "zombie" {
+		t.Fatalf("active = %+v", active)
+	}
+	cmd()
+	if saved, err := gastown.LoadProblemHistory(m.problemHistoryPath); err != nil || len(saved) != 1 {
+		t.Fatalf("saved history = %+v, %v", saved, err)
+	}
+
+	// The same snapshot again is no change: nothing to save.
+	if _, cmd = m.Update(townStatusMsg{status: status}); cmd != nil {
+		t.Fatal("an unchanged problem should not rewrite the history")
+	}
+
+	model, _ = m.Update(views.ProblemActionMsg{ID: active[0].ID, Action: views.ProblemAck})
+	m = model.(Model)
+	if m.header.ProblemCount != 0 || !m.toast.Active() {
+		t.Fatalf("acked problem should leave the header count: %d", m.header.ProblemCount)
+	}
+}
+
+func TestProblemHistorySavesOneAtATime(t *testing.T) {
+	m := setupModel(t)
+	m.problemTracker = gastown.ProblemTracker{}
+	m.problemHistoryPath = filepath.Join(t.TempDir(), "problems.jsonl")
+
+	first := m.saveProblemHistory()
+	if first == nil {
+		t.Fatal("first save should start")
+	}
+	m.problemTracker.Observe([]gastown.Problem{{Type: "zombie", Subject: "Toast", Severity: "error"}}, time.Now())
+	if m.saveProblemHistory() != nil {
+		t.Fatal("a second save should wait for the first")
+	}
+
+	model, again := m.Update(first())
+	m = model.(Model)
+	if again == nil {
+		t.Fatal("a save asked for mid-flight should run once the first finishes")
+	}
+	model, _ = m.Update(again())
+	m = model.(Model)
+	if saved, err := gastown.LoadProblemHistory(m.problemHistoryPath); err != nil || len(saved) != 1 {
+		t.Fatalf("history should hold the newer snapshot: %+v, %v", saved, err)
+	}
+	if m.problemSave.inFlight || m.problemSave.dirty {
+		t.Errorf("gate = %+v, want idle", m.problemSave)
+	}
+}
+
+func TestAlertRulesFeedProblems(t *testing.T) {
+	m := setupModel(t)
+	m.alertRules = []gastown.AlertRule{{Name: "all-open", Target: gastown.RuleTargetIssue, Status: "open"}}
+
+	n := 0
+	for _, p := range m.allProblems() {
+		if p.Type == "rule" && p.Subject == "all-open" {
+			n++
+		}
+	}
+	if n != 3 {
+		t.Fatalf("rule matched %d issues, want the 3 open ones", n)
+	}
+}
+
+func TestDriverErrorIsAProblem(t *testing.T) {
+	m := setupModel(t)
+	m.driverErr = errors.New("MG_DRIVER: driver acme: initialize: EOF")
+	for _, p := range m.allProblems() {
+		if p.Type == "driver" && p.Severity == "error" && strings.Contains(p.Detail, "initialize") {
+			return
+		}
+	}
+	t.Fatalf("driver error missing from problems: %+v", m.allProblems())
+}
+
+// playbookDriver records the re-slings a playbook makes.
+type playbookDriver struct {
+	gastown.Driver
+	calls []string
+}
+
+func (d *playbookDriver) Unsling(_ context.Context, id string) error {
+	d.calls = append(d.calls, "unsling "+id)
+	return nil
+}
+
+func (d *playbookDriver) Sling(_ context.Context, req gastown.SlingRequest) error {
+	d.calls = append(d.calls, "sling "+strings.Join(req.IssueIDs, ","))
+	return nil
+}
+
+func (d *playbookDriver) Backend() string { return gastown.BackendGasTown }
+
+func (d *playbookDriver) Supports(f gastown.Feature) bool {
+	return f == gastown.FeatureUnsling || f == gastown.FeatureAutoSling
+}
+
+func TestPlaybooksRemediateAndKillSwitch(t *testing.T) {
+	m := setupModel(t)
+	d := &playbookDriver{}
+	m.driver = d
+	m.gtEnv.Available = true
+	m.problemTracker = gastown.ProblemTracker{}
+	m.problemHistoryPath = ""
+	m.playbookAuditPath = filepath.Join(t.TempDir(), "playbook-audit.jsonl")
+	m.playbooks = &gastown.PlaybookConfig{Playbooks: []gastown.Playbook{
+		{Problem: "zombie", Action: gastown.PlaybookResling},
+	}}
+	status := &gastown.TownStatus{Agents: []gastown.AgentRuntime{
+		{Name: "Toast", Role: "polecat", Address: "gastown/polecats/Toast", HookBead: "open-1"},
+	}}
+
+	model, cmd := m.Update(townStatusMsg{status: status})
+	m = model.(Model)
+	if cmd == nil {
+		t.Fatal("a zombie with a resling playbook should run it")
+	}
+	result, ok := cmd().(playbookResultMsg)
+	if !ok {
+		t.Fatalf("cmd = %T, want playbookResultMsg", cmd())
+	}
+	if got := strings.Join(d.calls, "; "); got != "unsling open-1; sling open-1" {
+		t.Fatalf("driver calls = %q", got)
+	}
+	if raw, err := os.ReadFile(m.playbookAuditPath); err != nil || !strings.Contains(string(raw), `"action":"resling"`) {
+		t.Fatalf("audit log = %s, %v", raw, err)
+	}
+
+	model, _ = m.Update(result)
+	m = model.(Model)
+	if !m.toast.Active() || m.playbookLast[result.audit.ProblemID].Action != gastown.PlaybookResling {
+		t.Fatalf("result should toast and be noted on the problem: %+v", m.playbookLast)
+	}
+
+	// The kill switch stops further remediation, cooldown or not.
+	model, _ = m.executePaletteAction(components.ActionTogglePlaybooks)
+	m = model.(Model)
+	m.playbookState = gastown.PlaybookState{}
+	if !m.playbooksPaused || m.runPlaybooks(time.Now()) != nil {
+		t.Fatal("paused playbooks should not run")
+	}
+	found := false
+	for _, c := range m.buildPaletteCommands() {
+		if c.Action == components.ActionTogglePlaybooks {
+			found = c.Name == "Resume playbooks"
+		}
+	}
+	if !found {
+		t.Fatal("palette should offer to resume playbooks")
+	}
+}
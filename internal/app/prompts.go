@@ -0,0 +1,101 @@
+package app
+
+import (
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/matt-wright86/mardi-gras/internal/agent"
+	"github.com/matt-wright86/mardi-gras/internal/components"
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+// agentPrompt renders the launch prompt for issue from the project's prompt
+// template for it, returning the template's name too. Comments are included
+// when the detail pane has them loaded for the issue.
+func (m Model) agentPrompt(issue *data.Issue) (prompt, template string, err error) {
+	name, text, err := agent.PromptTemplateFor(m.projectDir, *issue)
+	if err != nil {
+		return "", name, err
+	}
+	var comments []agent.PromptComment
+	if m.detail.CommentsIssueID == issue.ID {
+		for _, c := range m.detail.Comments {
+			comments = append(comments, agent.PromptComment{Author: c.Author, Body: c.Body, Time: c.Time})
+		}
+	}
+	deps := issue.EvaluateDependencies(m.detail.IssueMap, m.blockingTypes)
+	prompt, err = agent.RenderPrompt(text, agent.NewPromptData(*issue, deps, m.detail.IssueMap, comments))
+	return prompt, name, err
+}
+
+// promptErrorToast reports a prompt template that failed to load or render.
+func (m Model) promptErrorToast(template string, err error) (tea.Model, tea.Cmd) {
+	toast, cmd := components.ShowToast("Prompt template "+template+": "+err.Error(), components.ToastError, toastDuration)
+	m.toast = toast
+	return m, cmd
+}
+
+// launchAgent starts the detected runtime on issueID with prompt, first
+// preparing the issue's worktree when worktrees are enabled.
+func (m Model) launchAgent(issueID, prompt string) (tea.Model, tea.Cmd) {
+	if m.worktreesEnabled {
+		if cmd := m.prepareWorktree(issueID, prompt); cmd != nil {
+			return m, cmd
+		}
+	}
+	return m.launchAgentIn(issueID, prompt, m.projectDir)
+}
+
+// launchAgentIn starts the agent in dir: in-process when the runtime has an
+// MCP server, in a new tmux pane inside tmux, otherwise suspending mg until
+// the agent exits.
+func (m Model) launchAgentIn(issueID, prompt, dir string) (tea.Model, tea.Cmd) {
+	if _, ok := m.mcpRuntime(); ok {
+		return m.openAgentSession(issueID, prompt, dir)
+	}
+	if m.inTmux {
+		registry, runtime := m.registry, m.agentRuntime
+		return m, func() tea.Msg {
+			winName, err := registry.LaunchInTmux(runtime, prompt, dir, issueID)
+			if err != nil {
+				return agentLaunchErrorMsg{issueID: issueID, err: err}
+			}
+			return agentLaunchedMsg{issueID: issueID, windowName: winName}
+		}
+	}
+	c := m.registry.Command(m.agentRuntime, prompt, dir)
+	return m, tea.ExecProcess(c, func(err error) tea.Msg {
+		return agentFinishedMsg{err: err}
+	})
+}
+
+// launchesLocally reports whether `a` starts the agent here rather than
+// slinging the issue through an orchestrator.
+func (m Model) launchesLocally() bool {
+	return m.agentAvail && !m.orchestratorAvailable()
+}
+
+// openPromptPreview renders the selected issue's prompt into an editor so
+// it can be checked and tweaked before launching.
+func (m Model) openPromptPreview() (tea.Model, tea.Cmd) {
+	issue := m.parade.SelectedIssue
+	if issue == nil || !m.launchesLocally() {
+		return m, nil
+	}
+	prompt, template, err := m.agentPrompt(issue)
+	if err != nil {
+		return m.promptErrorToast(template, err)
+	}
+	m.previewingPrompt = true
+	m.promptPreview = components.NewPromptPreview(issue.ID, template, m.registry.Label(m.agentRuntime), prompt, m.width, m.height)
+	return m, m.promptPreview.Init()
+}
+
+// handlePromptPreviewResult launches with the edited prompt, which is used
+// for this run only.
+func (m Model) handlePromptPreviewResult(msg components.PromptPreviewResult) (tea.Model, tea.Cmd) {
+	m.previewingPrompt = false
+	if msg.Cancelled {
+		return m, nil
+	}
+	return m.launchAgent(msg.IssueID, msg.Prompt)
+}
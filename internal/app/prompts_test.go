@@ -0,0 +1,72 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/matt-wright86/mardi-gras/internal/components"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+)
+
+func TestPromptPreviewRendersTemplateAndLaunchesEdit(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MG_PROMPTS", dir)
+	tmpl := "Fix {{.Issue.ID}}{{range .Comments}} / {{.Author}}: {{.Body}}{{end}}"
+	if err := os.WriteFile(filepath.Join(dir, "default.tmpl"), []byte(tmpl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := initModel(t)
+	m.agentAvail = true
+	m.gtEnv.Available = false
+	m.inTmux = true
+	issue := m.parade.SelectedIssue
+	if issue == nil {
+		t.Fatal("no selected issue")
+	}
+	m.detail.SetComments(issue.ID, []gastown.Comment{{Author: "ann", Body: "use the cache"}})
+
+	model, _ := m.executePaletteAction(components.ActionPreviewPrompt)
+	m = model.(Model)
+	if !m.previewingPrompt {
+		t.Fatal("preview should open")
+	}
+	if view := ansi.Strip(m.promptPreview.View()); !strings.Contains(view, "Fix "+issue.ID+" / ann: use the cache") || !strings.Contains(view, "default.tmpl") {
+		t.Fatalf("preview = %q", view)
+	}
+
+	// Keys go to the editor, not the parade.
+	model, _ = m.Update(tea.KeyPressMsg{Code: 'j', Text: "j"})
+	m = model.(Model)
+	if !m.previewingPrompt {
+		t.Fatal("preview should stay open while editing")
+	}
+
+	_, cmd := m.Update(tea.KeyPressMsg{Code: 's', Mod: tea.ModCtrl})
+	if cmd == nil {
+		t.Fatal("ctrl+s should produce a result")
+	}
+	res, ok := cmd().(components.PromptPreviewResult)
+	if !ok || res.Cancelled || res.IssueID != issue.ID || !strings.HasPrefix(res.Prompt, "jFix ") {
+		t.Fatalf("result = %+v", res)
+	}
+	model, launch := m.Update(res)
+	m = model.(Model)
+	if m.previewingPrompt || launch == nil {
+		t.Fatalf("launch should close the preview and start the agent: previewing=%v cmd=%v", m.previewingPrompt, launch)
+	}
+
+	// A broken template is reported instead of launching.
+	if err := os.WriteFile(filepath.Join(dir, "default.tmpl"), []byte("{{.Nope"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	model, _ = m.executePaletteAction(components.ActionPreviewPrompt)
+	m = model.(Model)
+	if m.previewingPrompt || !strings.Contains(m.toast.Message, "default.tmpl") {
+		t.Fatalf("bad template: previewing=%v toast=%q", m.previewingPrompt, m.toast.Message)
+	}
+}
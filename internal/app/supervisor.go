@@ -0,0 +1,185 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/matt-wright86/mardi-gras/internal/agent"
+	"github.com/matt-wright86/mardi-gras/internal/components"
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+// supervisorClaimedMsg lands once a queued issue has been claimed (and its
+// worktree prepared) and is ready to launch.
+type supervisorClaimedMsg struct {
+	issueID  string
+	prompt   string
+	dir      string
+	backend  agent.Backend
+	worktree *data.Worktree
+	err      error
+}
+
+// supervisorBackend picks where supervised agents run: in-process MCP
+// sessions for a runtime with an MCP server, tmux panes when mg is inside
+// tmux, otherwise codex MCP sessions for the codex runtime. Other setups
+// can't run agents in the background.
+func (m Model) supervisorBackend() (agent.Backend, bool) {
+	_, viaMCP := m.mcpRuntime()
+	switch {
+	case !m.launchesLocally():
+		return "", false
+	case viaMCP:
+		return agent.BackendCodex, true
+	case m.inTmux:
+		return agent.BackendTmux, true
+	case m.agentRuntime == agent.RuntimeCodex:
+		return agent.BackendCodex, true
+	}
+	return "", false
+}
+
+// queueForAgents adds the selected issues (or the cursor issue) to the
+// agent queue and starts as many as the concurrency limit allows.
+func (m Model) queueForAgents() (tea.Model, tea.Cmd) {
+	if _, ok := m.supervisorBackend(); !ok {
+		toast, cmd := components.ShowToast("The agent queue needs tmux, or the codex or an MCP runtime outside tmux", components.ToastWarn, toastDuration)
+		m.toast = toast
+		return m, cmd
+	}
+	issues := m.parade.SelectedIssues()
+	if len(issues) == 0 && m.parade.SelectedIssue != nil {
+		issues = []*data.Issue{m.parade.SelectedIssue}
+	}
+	var ids []string
+	for _, iss := range issues {
+		if _, active := m.activeAgents[iss.ID]; active || iss.Status == data.StatusClosed {
+			continue
+		}
+		ids = append(ids, iss.ID)
+	}
+	m.parade.ClearSelection()
+	added := m.supervisor.Enqueue(ids...)
+	dispatch := m.dispatchQueued()
+	toast, cmd := components.ShowToast(
+		fmt.Sprintf("Queued %d for agents (%d running, limit %d)", added, len(m.supervisor.Running()), m.supervisor.Limit),
+		components.ToastInfo, toastDuration,
+	)
+	m.toast = toast
+	m.propagateAgentState()
+	return m, tea.Batch(cmd, dispatch)
+}
+
+// unqueueSelected drops the selected issue from the agent queue.
+func (m Model) unqueueSelected() (tea.Model, tea.Cmd) {
+	if issue := m.parade.SelectedIssue; issue != nil && m.supervisor.Remove(issue.ID) {
+		m.propagateAgentState()
+	}
+	return m, nil
+}
+
+// clearAgentQueue empties the agent queue; running agents keep going.
+func (m Model) clearAgentQueue() (tea.Model, tea.Cmd) {
+	n := m.supervisor.Clear()
+	m.propagateAgentState()
+	toast, cmd := components.ShowToast(fmt.Sprintf("Cleared %d queued issues", n), components.ToastInfo, toastDuration)
+	m.toast = toast
+	return m, cmd
+}
+
+// dispatchQueued starts queued issues while slots are free, returning Cmds
+// that claim each one before it launches.
+func (m *Model) dispatchQueued() tea.Cmd {
+	backend, ok := m.supervisorBackend()
+	if !ok {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for ids := m.supervisor.Next(backend, time.Now()); len(ids) > 0; ids = m.supervisor.Next(backend, time.Now()) {
+		for _, id := range ids {
+			issue, ok := m.detail.IssueMap[id]
+			if !ok {
+				m.supervisor.Finish(id)
+				continue
+			}
+			prompt, template, err := m.agentPrompt(issue)
+			if err != nil {
+				m.supervisor.Finish(id)
+				logRoute("agent queue: " + id + ": template " + template + ": " + err.Error())
+				continue
+			}
+			cmds = append(cmds, claimForAgent(*issue, prompt, m.projectDir, backend, m.worktreesEnabled))
+		}
+	}
+	m.propagateAgentState()
+	return tea.Batch(cmds...)
+}
+
+// claimForAgent returns a Cmd claiming issue and, with worktrees enabled,
+// preparing its worktree, so the agent only starts on work it owns.
+func claimForAgent(issue data.Issue, prompt, projectDir string, backend agent.Backend, worktrees bool) tea.Cmd {
+	return func() tea.Msg {
+		msg := supervisorClaimedMsg{issueID: issue.ID, prompt: prompt, dir: projectDir, backend: backend}
+		if err := data.ClaimIssue(issue.ID); err != nil {
+			msg.err = fmt.Errorf("claim: %w", err)
+			return msg
+		}
+		if worktrees {
+			wt, err := data.EnsureWorktree(projectDir, issue)
+			if err != nil {
+				msg.err = err
+				return msg
+			}
+			msg.dir = wt.Path
+			msg.worktree = &wt
+		}
+		return msg
+	}
+}
+
+// handleSupervisorClaimed launches a claimed issue's agent, or frees its
+// slot for the next one when the claim failed.
+func (m Model) handleSupervisorClaimed(msg supervisorClaimedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.supervisor.Finish(msg.issueID)
+		toast, cmd := components.ShowToast(
+			fmt.Sprintf("Agent queue: %s: %s", msg.issueID, msg.err),
+			components.ToastError, toastDuration,
+		)
+		m.toast = toast
+		return m, tea.Batch(cmd, m.dispatchQueued())
+	}
+	if msg.worktree != nil {
+		if m.worktrees == nil {
+			m.worktrees = make(map[string]data.Worktree)
+		}
+		m.worktrees[msg.issueID] = *msg.worktree
+		m.detail.SetWorktrees(m.worktrees)
+	}
+	if msg.backend == agent.BackendCodex {
+		// Nobody watches a queued session, so codex runs unattended like a
+		// tmux launch, unless approval rules are set: then codex asks, the
+		// rules answer, and only what they don't cover waits in the dialog.
+		approval := "never"
+		if m.approvalPolicy != nil {
+			approval = "on-request"
+		}
+		return m, m.agentSessionCmd(msg.issueID, msg.prompt, msg.dir, approval)
+	}
+	return m.launchAgentIn(msg.issueID, msg.prompt, msg.dir)
+}
+
+// finishSupervised frees the slots of agents that are done and starts the
+// next queued issues.
+func (m *Model) finishSupervised(ids ...string) tea.Cmd {
+	freed := false
+	for _, id := range ids {
+		freed = m.supervisor.Finish(id) || freed
+	}
+	if !freed {
+		return nil
+	}
+	return m.dispatchQueued()
+}
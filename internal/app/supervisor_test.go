@@ -0,0 +1,67 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matt-wright86/mardi-gras/internal/agent"
+	"github.com/matt-wright86/mardi-gras/internal/components"
+)
+
+func TestAgentQueueStartsNextWhenSlotFrees(t *testing.T) {
+	t.Setenv("MG_PROMPTS", t.TempDir())
+	m := initModel(t)
+	m.agentAvail = true
+	m.gtEnv.Available = false
+	m.inTmux = true
+	m.supervisor = agent.NewSupervisor(1)
+
+	m.parade.SelectedIssue = m.detail.IssueMap["open-1"]
+	model, cmd := m.executePaletteAction(components.ActionQueueAgents)
+	m = model.(Model)
+	m.parade.SelectedIssue = m.detail.IssueMap["open-2"]
+	model, _ = m.executePaletteAction(components.ActionQueueAgents)
+	m = model.(Model)
+	if cmd == nil || !m.supervisor.IsRunning("open-1") || m.supervisor.Position("open-2") != 1 {
+		t.Fatalf("running=%v queue=%v", m.supervisor.Running(), m.supervisor.Queue())
+	}
+	if m.header.QueuedAgents != 1 {
+		t.Errorf("header queued = %d", m.header.QueuedAgents)
+	}
+
+	// A failed claim frees the slot for the next issue.
+	model, cmd = m.Update(supervisorClaimedMsg{issueID: "open-1", err: errors.New("already claimed")})
+	m = model.(Model)
+	if cmd == nil || !m.supervisor.IsRunning("open-2") || m.supervisor.IsRunning("open-1") {
+		t.Fatalf("after failed claim: running=%v", m.supervisor.Running())
+	}
+	if !strings.Contains(m.toast.Message, "already claimed") {
+		t.Errorf("toast = %q", m.toast.Message)
+	}
+
+	// Once launched, the pane exiting finishes the agent.
+	model, _ = m.Update(agentLaunchedMsg{issueID: "open-2", windowName: "%2"})
+	m = model.(Model)
+	model, _ = m.Update(agentStatusMsg{activeAgents: map[string]string{"open-2": "%2"}})
+	m = model.(Model)
+	model, _ = m.Update(agentStatusMsg{activeAgents: map[string]string{}})
+	m = model.(Model)
+	if len(m.supervisor.Running()) != 0 {
+		t.Fatalf("pane exit should free the slot: %v", m.supervisor.Running())
+	}
+}
+
+func TestAgentQueueNeedsBackgroundBackend(t *testing.T) {
+	m := initModel(t)
+	m.agentAvail = true
+	m.gtEnv.Available = false
+	m.inTmux = false
+	m.agentRuntime = agent.RuntimeClaude
+
+	model, _ := m.executePaletteAction(components.ActionQueueAgents)
+	m = model.(Model)
+	if len(m.supervisor.Queue()) != 0 || !strings.Contains(m.toast.Message, "needs tmux") {
+		t.Fatalf("queue=%v toast=%q", m.supervisor.Queue(), m.toast.Message)
+	}
+}
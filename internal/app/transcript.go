@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/matt-wright86/mardi-gras/internal/components"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+)
+
+// transcriptRefreshInterval is how often an open session transcript is
+// re-fetched. Polling rides the Gas Town panel's 1s liveness tick, so this
+// only needs to be a multiple of gasTownTickInterval.
+const transcriptRefreshInterval = 2 * time.Second
+
+// sessionTranscriptMsg carries a fetched agent session transcript. target is
+// echoed back so a late reply for a previously opened agent is dropped.
+type sessionTranscriptMsg struct {
+	target     string
+	transcript *gastown.SessionTranscript
+	err        error
+}
+
+// transcriptTarget picks the handle a driver resolves to a session: the
+// roster's session name when reported, else the agent's address or name.
+func transcriptTarget(a gastown.AgentRuntime) string {
+	switch {
+	case a.Session != "":
+		return a.Session
+	case a.Address != "":
+		return a.Address
+	default:
+		return a.Name
+	}
+}
+
+// openTranscript shows the session transcript overlay for a roster agent in
+// place of the Gas Town panel and kicks off the first fetch.
+func (m Model) openTranscript(a gastown.AgentRuntime) (tea.Model, tea.Cmd) {
+	if !m.driver.Supports(gastown.FeatureTranscript) {
+		toast, cmd := components.ShowToast(
+			"Session transcripts are not supported by this backend",
+			components.ToastWarn, toastDuration,
+		)
+		m.toast = toast
+		return m, cmd
+	}
+	m.showTranscript = true
+	m.showConvoyTimeline = false
+	m.agentTranscript.Open(a)
+	m.transcriptInFlight = false
+	cmd := m.fetchTranscript()
+	return m, cmd
+}
+
+// fetchTranscript returns a Cmd reading the open agent's transcript, or nil
+// when a fetch is already in flight.
+func (m *Model) fetchTranscript() tea.Cmd {
+	if m.transcriptInFlight {
+		return nil
+	}
+	m.transcriptInFlight = true
+	m.lastTranscriptFetch = time.Now()
+	target := transcriptTarget(m.agentTranscript.Agent())
+	driver := m.driver
+	return func() tea.Msg {
+		tr, err := driver.SessionTranscript(context.Background(), target)
+		return sessionTranscriptMsg{target: target, transcript: tr, err: err}
+	}
+}
+
+// pollTranscript re-fetches the open transcript once the refresh interval
+// has elapsed. Called from the Gas Town liveness tick.
+func (m *Model) pollTranscript() tea.Cmd {
+	if !m.showGasTown || !m.showTranscript {
+		return nil
+	}
+	if time.Since(m.lastTranscriptFetch) < transcriptRefreshInterval {
+		return nil
+	}
+	return m.fetchTranscript()
+}
+
+func (m Model) handleSessionTranscript(msg sessionTranscriptMsg) (tea.Model, tea.Cmd) {
+	m.transcriptInFlight = false
+	if !m.showTranscript || msg.target != transcriptTarget(m.agentTranscript.Agent()) {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.agentTranscript.SetError(msg.err)
+		return m, nil
+	}
+	m.agentTranscript.SetTranscript(msg.transcript)
+	return m, nil
+}
+
+// handleTranscriptKey routes keys while the transcript overlay is focused.
+// esc returns to the Gas Town panel and R forces a refresh; everything else
+// (scrolling, search, follow, i to message the agent) belongs to the view.
+func (m Model) handleTranscriptKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd, bool) {
+	switch msg.String() {
+	case "esc":
+		m.showTranscript = false
+		return m, nil, true
+	case "R":
+		cmd := m.fetchTranscript()
+		return m, cmd, true
+	case "j", "k", "up", "down", "g", "G", "ctrl+d", "ctrl+u", "pgdown", "pgup",
+		"f", "/", "n", "N", "i":
+		var cmd tea.Cmd
+		m.agentTranscript, cmd = m.agentTranscript.Update(msg)
+		return m, cmd, true
+	}
+	return m, nil, false
+}
+
+// handleTranscriptSearchKey feeds every key to the transcript's search input
+// while it has focus, like the parade filter: printable keys (including "q"
+// and "?") must reach the input as literals.
+func (m Model) handleTranscriptSearchKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.agentTranscript, cmd = m.agentTranscript.Update(msg)
+	return m, cmd
+}
+
+// transcriptFocused reports whether the transcript overlay owns key input.
+func (m Model) transcriptFocused() bool {
+	return m.showGasTown && m.showTranscript && m.activPane == PaneDetail
+}
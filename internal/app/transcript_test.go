@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+	"github.com/matt-wright86/mardi-gras/internal/views"
+)
+
+// transcriptDriver answers SessionTranscript with a canned transcript and
+// records the target it was asked for. Other Driver methods are unused.
+type transcriptDriver struct {
+	gastown.Driver
+	target string
+}
+
+func (d *transcriptDriver) Supports(f gastown.Feature) bool { return f == gastown.FeatureTranscript }
+
+func (d *transcriptDriver) SessionTranscript(_ context.Context, target string) (*gastown.SessionTranscript, error) {
+	d.target = target
+	return &gastown.SessionTranscript{Session: target, Entries: []gastown.TranscriptEntry{{Text: "hello"}}}, nil
+}
+
+func transcriptModel(d gastown.Driver) Model {
+	m := Model{showGasTown: true, activPane: PaneDetail, gtEnv: gastown.Env{Available: true}, driver: d}
+	m.agentTranscript = views.NewAgentTranscript(80, 20)
+	return m
+}
+
+func TestTranscriptTarget(t *testing.T) {
+	tests := []struct {
+		agent gastown.AgentRuntime
+		want  string
+	}{
+		{gastown.AgentRuntime{Name: "toast", Address: "beads/toast", Session: "gt-beads-toast"}, "gt-beads-toast"},
+		{gastown.AgentRuntime{Name: "toast", Address: "beads/toast"}, "beads/toast"},
+		{gastown.AgentRuntime{Name: "toast"}, "toast"},
+	}
+	for _, tt := range tests {
+		if got := transcriptTarget(tt.agent); got != tt.want {
+			t.Errorf("transcriptTarget(%+v) = %q, want %q", tt.agent, got, tt.want)
+		}
+	}
+}
+
+func TestOpenTranscriptFetchesAndRenders(t *testing.T) {
+	d := &transcriptDriver{}
+	m := transcriptModel(d)
+
+	next, cmd := m.handleGasTownAction(views.GasTownActionMsg{
+		Type:  views.ActionTranscript,
+		Agent: gastown.AgentRuntime{Name: "toast", Session: "gt-beads-toast"},
+	})
+	m = next.(Model)
+	if !m.showTranscript || !m.transcriptFocused() {
+		t.Fatal("transcript overlay should be open and focused")
+	}
+	if cmd == nil {
+		t.Fatal("expected a fetch cmd")
+	}
+	msg := cmd()
+	if d.target != "gt-beads-toast" {
+		t.Errorf("fetched %q, want the agent's session", d.target)
+	}
+	next, _ = m.Update(msg)
+	m = next.(Model)
+	if m.transcriptInFlight {
+		t.Error("in-flight flag should clear when the transcript arrives")
+	}
+}
+
+func TestSessionTranscriptMsgDropsStaleTarget(t *testing.T) {
+	m := transcriptModel(&transcriptDriver{})
+	m.showTranscript = true
+	m.agentTranscript.Open(gastown.AgentRuntime{Name: "toast"})
+	m.transcriptInFlight = true
+
+	next, _ := m.handleSessionTranscript(sessionTranscriptMsg{target: "other", err: errors.New("boom")})
+	m = next.(Model)
+	if m.transcriptInFlight {
+		t.Error("in-flight flag should clear even for a stale reply")
+	}
+	if got := m.agentTranscript.View(); !strings.Contains(got, "loading session history") {
+		t.Error("stale reply for another agent must not update the overlay")
+	}
+}
+
+func TestPollTranscriptRespectsInterval(t *testing.T) {
+	m := transcriptModel(&transcriptDriver{})
+	m.showTranscript = true
+	m.agentTranscript.Open(gastown.AgentRuntime{Name: "toast"})
+
+	m.lastTranscriptFetch = time.Now()
+	if cmd := m.pollTranscript(); cmd != nil {
+		t.Error("poll within the refresh interval should be a no-op")
+	}
+	m.lastTranscriptFetch = time.Now().Add(-transcriptRefreshInterval)
+	if cmd := m.pollTranscript(); cmd == nil {
+		t.Error("poll after the refresh interval should fetch")
+	}
+	if cmd := m.pollTranscript(); cmd != nil {
+		t.Error("poll while a fetch is in flight should be a no-op")
+	}
+}
+
+func TestTranscriptKeys(t *testing.T) {
+	m := transcriptModel(&transcriptDriver{})
+	m.showTranscript = true
+	m.agentTranscript.Open(gastown.AgentRuntime{Name: "toast"})
+
+	// "/" opens search; "q" then reaches the input instead of quitting.
+	next, _ := m.handleKey(tea.KeyPressMsg{Code: '/', Text: "/"})
+	m = next.(Model)
+	if !m.agentTranscript.Searching() {
+		t.Fatal("/ should open transcript search")
+	}
+	next, cmd := m.handleKeyPress(tea.KeyPressMsg{Code: 'q', Text: "q"}, false)
+	m = next.(Model)
+	if cmd != nil {
+		if _, quit := cmd().(tea.QuitMsg); quit {
+			t.Fatal("q while searching must not quit")
+		}
+	}
+	next, _ = m.handleKeyPress(tea.KeyPressMsg{Code: tea.KeyEscape}, false)
+	m = next.(Model)
+
+	// esc closes the overlay back to the Gas Town panel.
+	next, _ = m.handleKey(tea.KeyPressMsg{Code: tea.KeyEscape})
+	m = next.(Model)
+	if m.showTranscript {
+		t.Fatal("esc should close the transcript overlay")
+	}
+	if !m.showGasTown {
+		t.Fatal("closing the transcript should leave the Gas Town panel open")
+	}
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"fmt"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/matt-wright86/mardi-gras/internal/components"
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+// worktreeReadyMsg reports the worktree prepared for an agent launch.
+type worktreeReadyMsg struct {
+	issueID  string
+	prompt   string
+	worktree data.Worktree
+	err      error
+}
+
+// worktreesMsg carries a refresh of the project's agent worktrees.
+type worktreesMsg struct {
+	worktrees map[string]data.Worktree
+	err       error
+}
+
+// worktreeRemovedMsg reports a worktree cleanup.
+type worktreeRemovedMsg struct {
+	worktree      data.Worktree
+	branchDeleted bool
+	err           error
+}
+
+// prepareWorktree returns a Cmd creating (or reusing) the issue's worktree
+// before launching the agent there with prompt.
+func (m Model) prepareWorktree(issueID, prompt string) tea.Cmd {
+	issue, ok := m.detail.IssueMap[issueID]
+	if !ok {
+		return nil
+	}
+	iss := *issue
+	projectDir := m.projectDir
+	return func() tea.Msg {
+		wt, err := data.EnsureWorktree(projectDir, iss)
+		return worktreeReadyMsg{issueID: issueID, prompt: prompt, worktree: wt, err: err}
+	}
+}
+
+// handleWorktreeReady launches the agent in its worktree, or reports why
+// the worktree couldn't be made.
+func (m Model) handleWorktreeReady(msg worktreeReadyMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		toast, cmd := components.ShowToast("Worktree: "+msg.err.Error(), components.ToastError, toastDuration)
+		m.toast = toast
+		return m, cmd
+	}
+	if m.worktrees == nil {
+		m.worktrees = make(map[string]data.Worktree)
+	}
+	m.worktrees[msg.issueID] = msg.worktree
+	m.detail.SetWorktrees(m.worktrees)
+	return m.launchAgentIn(msg.issueID, msg.prompt, msg.worktree.Path)
+}
+
+// refreshWorktrees returns a Cmd listing the project's agent worktrees with
+// their status. Only one refresh runs at a time.
+func (m *Model) refreshWorktrees() tea.Cmd {
+	if !m.worktreesEnabled || m.worktreeRefreshing {
+		return nil
+	}
+	m.worktreeRefreshing = true
+	projectDir := m.projectDir
+	issues := m.issues
+	return func() tea.Msg {
+		wts, err := data.ListWorktrees(projectDir, issues)
+		return worktreesMsg{worktrees: wts, err: err}
+	}
+}
+
+// handleWorktrees stores a worktree refresh. A failed refresh keeps the
+// previous list; the project may simply not be a git repository.
+func (m Model) handleWorktrees(msg worktreesMsg) (tea.Model, tea.Cmd) {
+	m.worktreeRefreshing = false
+	if msg.err != nil {
+		logRoute("worktrees: " + msg.err.Error())
+		return m, nil
+	}
+	m.worktrees = msg.worktrees
+	m.detail.SetWorktrees(m.worktrees)
+	return m, nil
+}
+
+// closedWorktreeNotice returns a cleanup hint for issues that have just
+// closed while their worktree is still around, or "" if there are none.
+func (m Model) closedWorktreeNotice(issues []data.Issue) string {
+	var closed []string
+	for _, iss := range issues {
+		if _, ok := m.worktrees[iss.ID]; !ok || iss.Status != data.StatusClosed {
+			continue
+		}
+		if prev, ok := m.prevIssueMap[iss.ID]; ok && prev != data.StatusClosed {
+			closed = append(closed, iss.ID)
+		}
+	}
+	switch len(closed) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("%s closed: clean up its worktree from the palette", closed[0])
+	default:
+		return fmt.Sprintf("%d issues closed: clean up their worktrees from the palette", len(closed))
+	}
+}
+
+// cleanupWorktree removes the selected issue's worktree and deletes its
+// branch if merged.
+func (m Model) cleanupWorktree() (tea.Model, tea.Cmd) {
+	issue := m.parade.SelectedIssue
+	if issue == nil {
+		return m, nil
+	}
+	wt, ok := m.worktrees[issue.ID]
+	if !ok {
+		return m, nil
+	}
+	projectDir := m.projectDir
+	return m, func() tea.Msg {
+		deleted, err := data.RemoveWorktree(projectDir, wt)
+		return worktreeRemovedMsg{worktree: wt, branchDeleted: deleted, err: err}
+	}
+}
+
+// handleWorktreeRemoved reports a cleanup and drops the worktree.
+func (m Model) handleWorktreeRemoved(msg worktreeRemovedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		toast, cmd := components.ShowToast("Worktree cleanup: "+msg.err.Error(), components.ToastError, toastDuration)
+		m.toast = toast
+		return m, cmd
+	}
+	delete(m.worktrees, msg.worktree.IssueID)
+	m.detail.SetWorktrees(m.worktrees)
+	text := "Removed worktree and merged branch " + msg.worktree.Branch
+	level := components.ToastSuccess
+	if !msg.branchDeleted {
+		text = "Removed worktree; kept unmerged branch " + msg.worktree.Branch
+		level = components.ToastInfo
+	}
+	toast, cmd := components.ShowToast(text, level, toastDuration)
+	m.toast = toast
+	return m, cmd
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matt-wright86/mardi-gras/internal/components"
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+func TestClosedIssueOffersWorktreeCleanup(t *testing.T) {
+	m := initModel(t)
+	m.worktreesEnabled = true
+	wt := data.Worktree{IssueID: "open-1", Path: "/tmp/wt/task-open-1", Branch: "task/open-1"}
+	model, _ := m.Update(worktreesMsg{worktrees: map[string]data.Worktree{"open-1": wt}})
+	m = model.(Model)
+	if m.detail.Worktrees["open-1"] != wt {
+		t.Fatalf("detail worktrees = %+v", m.detail.Worktrees)
+	}
+
+	issues := []data.Issue{
+		testIssue("open-1", data.StatusClosed),
+		testIssue("open-2", data.StatusOpen),
+		testIssue("closed-1", data.StatusClosed),
+	}
+	model, _ = m.Update(data.FileChangedMsg{Issues: issues})
+	m = model.(Model)
+	if !strings.Contains(m.toast.Message, "open-1 closed") {
+		t.Fatalf("toast = %q", m.toast.Message)
+	}
+
+	m.parade.SelectedIssue = &issues[0]
+	found := false
+	for _, c := range m.buildPaletteCommands() {
+		found = found || c.Action == components.ActionCleanupWorktree
+	}
+	if !found {
+		t.Fatal("palette should offer worktree cleanup")
+	}
+
+	model, _ = m.Update(worktreeRemovedMsg{worktree: wt, branchDeleted: false})
+	m = model.(Model)
+	if _, ok := m.worktrees["open-1"]; ok || !strings.Contains(m.toast.Message, "kept unmerged branch") {
+		t.Fatalf("after cleanup: worktrees=%+v toast=%q", m.worktrees, m.toast.Message)
+	}
+}
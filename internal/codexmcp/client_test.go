@@ -9,8 +9,18 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/mcp"
 )
 
+// request is the part of an inbound JSON-RPC message the fake server
+// routes on.
+type request struct {
+	ID     int             `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
 // fakeServer is a long-lived JSON-RPC peer for tests. A single goroutine reads
 // inbound requests and forwards them to the Incoming channel; tests respond
 // via Respond / SendEvent which encode onto the writer side.
@@ -50,7 +60,7 @@ func newFakeServer(t *testing.T) (*Client, *fakeServer) {
 	go func() {
 		defer close(handshakeDone)
 		for req := range fs.Incoming {
-			if req.Method == methodInitialize {
+			if req.Method == mcp.MethodInitialize {
 				fs.Respond(req.ID, json.RawMessage(`{
 					"protocolVersion":"2025-03-26",
 					"capabilities":{"tools":{"listChanged":true}},
@@ -75,7 +85,7 @@ func newFakeServer(t *testing.T) (*Client, *fakeServer) {
 	// don't require a response.
 	select {
 	case n := <-fs.Incoming:
-		if n.Method != methodNotifyInitialized {
+		if n.Method != mcp.MethodInitialized {
 			t.Fatalf("expected notifications/initialized, got %q", n.Method)
 		}
 	case <-time.After(2 * time.Second):
@@ -360,7 +370,7 @@ func TestServerRequestStringIDSurvivesAndEchoes(t *testing.T) {
 		},
 	})
 
-	var sr ServerRequest
+	var sr mcp.ServerRequest
 	select {
 	case sr = <-c.ServerRequests():
 	case <-time.After(2 * time.Second):
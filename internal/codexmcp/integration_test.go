@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/matt-wright86/mardi-gras/internal/codexmcp"
+	"github.com/matt-wright86/mardi-gras/internal/mcp"
 )
 
 // TestIntegrationRealCodex exercises a real `codex mcp-server` end-to-end:
@@ -28,7 +29,7 @@ func TestIntegrationRealCodex(t *testing.T) {
 		t.Skip("codex not on PATH")
 	}
 
-	transport, err := codexmcp.SpawnSubprocess(codexmcp.WithDir("/tmp"))
+	transport, err := codexmcp.SpawnSubprocess(mcp.WithDir("/tmp"))
 	if err != nil {
 		t.Fatalf("spawn: %v", err)
 	}
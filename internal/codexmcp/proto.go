@@ -1,98 +1,25 @@
-// Package codexmcp speaks the Model Context Protocol (MCP) over stdio to
-// `codex mcp-server`. It is a thin client that exposes the JSON-RPC request /
-// response surface plus the `codex/event` notification stream that Codex emits
-// while a session is running.
+// Package codexmcp drives `codex mcp-server`: it calls the `codex` and
+// `codex-reply` tools and decodes the `codex/event` notification stream and
+// the approval elicitations Codex emits while a session is running.
 //
-// The package is intentionally focused on Codex; it does not aim to be a
-// general MCP client. Only the subset of protocol surface mg needs is modeled.
+// The MCP plumbing — JSON-RPC framing, the initialize handshake, tool calls
+// and server requests — lives in package mcp; this package adds only what is
+// specific to Codex.
 package codexmcp
 
 import (
 	"encoding/json"
+	"sort"
+	"strings"
 )
 
-// JSON-RPC envelope types. Codex's MCP server uses JSON-RPC 2.0 framing
-// (newline-delimited objects on stdout/stdin).
-
-type request struct {
-	JSONRPC string          `json:"jsonrpc"`
-	ID      int             `json:"id"`
-	Method  string          `json:"method"`
-	Params  json.RawMessage `json:"params,omitempty"`
-}
-
-type notification struct {
-	JSONRPC string          `json:"jsonrpc"`
-	Method  string          `json:"method"`
-	Params  json.RawMessage `json:"params,omitempty"`
-}
-
-// response models any inbound JSON-RPC object: a response to one of our
-// requests, a notification (codex/event), or a server-initiated request
-// (elicitation/create). ID is kept as RawMessage so a string id can't fail the
-// whole-line decode — our own request ids are always ints (see parseIntID),
-// while server-request ids are echoed back verbatim.
-type response struct {
-	JSONRPC string          `json:"jsonrpc"`
-	ID      json.RawMessage `json:"id,omitempty"`
-	Result  json.RawMessage `json:"result,omitempty"`
-	Error   *rpcError       `json:"error,omitempty"`
-	Method  string          `json:"method,omitempty"`
-	Params  json.RawMessage `json:"params,omitempty"`
-}
-
-// parseIntID extracts an integer id from a raw JSON-RPC id. Returns ok=false for
-// absent or non-integer (e.g. string) ids. Used to route responses to the
-// pending map, which is keyed by the int ids we allocate for our own requests.
-func parseIntID(raw json.RawMessage) (int, bool) {
-	if len(raw) == 0 {
-		return 0, false
-	}
-	var id int
-	if err := json.Unmarshal(raw, &id); err != nil {
-		return 0, false
-	}
-	return id, true
-}
-
-type rpcError struct {
-	Code    int             `json:"code"`
-	Message string          `json:"message"`
-	Data    json.RawMessage `json:"data,omitempty"`
-}
-
-// MCP method names used by this client.
+// Codex-specific MCP names.
 const (
-	methodInitialize        = "initialize"
-	methodNotifyInitialized = "notifications/initialized"
-	methodToolsCall         = "tools/call"
-	methodCodexEvent        = "codex/event"
-	mcpProtocolVersion      = "2025-03-26"
-	codexToolName           = "codex"
-	codexReplyToolName      = "codex-reply"
-	clientName              = "mardi-gras"
-	clientVersionFallback   = "dev"
-	jsonRPCVersion          = "2.0"
+	methodCodexEvent   = "codex/event"
+	codexToolName      = "codex"
+	codexReplyToolName = "codex-reply"
 )
 
-// initializeParams is sent in the MCP `initialize` request.
-type initializeParams struct {
-	ProtocolVersion string         `json:"protocolVersion"`
-	Capabilities    map[string]any `json:"capabilities"`
-	ClientInfo      clientInfo     `json:"clientInfo"`
-}
-
-type clientInfo struct {
-	Name    string `json:"name"`
-	Version string `json:"version"`
-}
-
-// toolsCallParams is the params object for `tools/call`.
-type toolsCallParams struct {
-	Name      string         `json:"name"`
-	Arguments map[string]any `json:"arguments"`
-}
-
 // CodexEvent is the unmarshaled `codex/event` notification payload.
 // The raw shape is:
 //
@@ -162,11 +89,25 @@ type ExecCommandBeginEvent struct {
 }
 
 // ExecCommandEndEvent is `msg.type == "exec_command_end"`.
+// AggregatedOutput interleaves stdout and stderr in arrival order; older
+// codex builds only send the two streams separately.
 type ExecCommandEndEvent struct {
-	CallID   string `json:"call_id"`
-	Stdout   string `json:"stdout"`
-	Stderr   string `json:"stderr"`
-	ExitCode int    `json:"exit_code"`
+	CallID           string `json:"call_id"`
+	Stdout           string `json:"stdout"`
+	Stderr           string `json:"stderr"`
+	AggregatedOutput string `json:"aggregated_output"`
+	ExitCode         int    `json:"exit_code"`
+}
+
+// Output returns the command's output, preferring the interleaved stream.
+func (e ExecCommandEndEvent) Output() string {
+	if e.AggregatedOutput != "" {
+		return e.AggregatedOutput
+	}
+	if e.Stdout != "" && e.Stderr != "" {
+		return strings.TrimRight(e.Stdout, "\n") + "\n" + e.Stderr
+	}
+	return e.Stdout + e.Stderr
 }
 
 // MCPToolCallBeginEvent is `msg.type == "mcp_tool_call_begin"`.
@@ -198,11 +139,54 @@ type TaskCompleteEvent struct {
 	LastAgentMessage string `json:"last_agent_message"`
 }
 
+// PlanUpdateEvent is `msg.type == "plan_update"`, sent whenever the agent
+// rewrites its step list. Each update carries the whole plan.
+type PlanUpdateEvent struct {
+	Explanation string     `json:"explanation"`
+	Plan        []PlanItem `json:"plan"`
+}
+
+// PlanItem is one step of a PlanUpdateEvent. Status is "pending",
+// "in_progress" or "completed".
+type PlanItem struct {
+	Step   string `json:"step"`
+	Status string `json:"status"`
+}
+
 // ErrorEvent is `msg.type == "error"`.
 type ErrorEvent struct {
 	Message string `json:"message"`
 }
 
+// BackgroundEvent is `msg.type == "background_event"`, a status line codex
+// sends outside a turn (e.g. while reconnecting). mg also uses it for the
+// progress and log notifications of other MCP agents.
+type BackgroundEvent struct {
+	Message string `json:"message"`
+}
+
+// TokenCountEvent is `msg.type == "token_count"`, sent after each model
+// response. Info is null until the first response has been counted.
+type TokenCountEvent struct {
+	Info *TokenUsageInfo `json:"info"`
+}
+
+// TokenUsageInfo is the usage carried by a TokenCountEvent.
+type TokenUsageInfo struct {
+	TotalTokenUsage    TokenUsage `json:"total_token_usage"`
+	LastTokenUsage     TokenUsage `json:"last_token_usage"`
+	ModelContextWindow int        `json:"model_context_window"`
+}
+
+// TokenUsage counts the tokens of a session or of one response.
+type TokenUsage struct {
+	InputTokens           int `json:"input_tokens"`
+	CachedInputTokens     int `json:"cached_input_tokens"`
+	OutputTokens          int `json:"output_tokens"`
+	ReasoningOutputTokens int `json:"reasoning_output_tokens"`
+	TotalTokens           int `json:"total_tokens"`
+}
+
 // SessionConfiguredEvent is `msg.type == "session_configured"`.
 type SessionConfiguredEvent struct {
 	SessionID      string `json:"session_id"`
@@ -213,17 +197,6 @@ type SessionConfiguredEvent struct {
 	RolloutPath    string `json:"rollout_path"`
 }
 
-// ServerRequest is an inbound JSON-RPC request initiated by the codex MCP server
-// (as opposed to a response to one of our requests, or a codex/event
-// notification). Codex uses these for approval prompts via `elicitation/create`.
-// RawID is the server-allocated id; mg must echo it back verbatim on the reply
-// (see Client.Respond) — the type (number vs string) is server-defined.
-type ServerRequest struct {
-	RawID  json.RawMessage
-	Method string
-	Params json.RawMessage
-}
-
 // ElicitApproval is the decoded payload of an `elicitation/create` approval
 // request. Codex flattens the approval fields into the params object with
 // `codex_*` keys (rather than nesting them), discriminated by `codex_elicitation`.
@@ -236,6 +209,84 @@ type ElicitApproval struct {
 	Changes map[string]json.RawMessage // patch: codex_changes (path -> FileChange)
 }
 
+// FileChange is one file of a patch approval, decoded from a codex_changes
+// entry. Codex tags the variant with a "type" field; older servers wrap it
+// in a single-key object ({"update": {...}}) instead, and both are accepted.
+type FileChange struct {
+	Path        string
+	Kind        string // "add", "delete", "update", or "" when undecodable
+	Content     string // add/delete: the whole file
+	UnifiedDiff string // update: the diff against the current file
+	MovePath    string // update: the new path when the file is renamed
+	Raw         json.RawMessage
+}
+
+type fileChangeFields struct {
+	Type        string `json:"type"`
+	Content     string `json:"content"`
+	UnifiedDiff string `json:"unified_diff"`
+	MovePath    string `json:"move_path"`
+}
+
+// FileChanges decodes the patch's changes, sorted by path.
+func (a ElicitApproval) FileChanges() []FileChange {
+	out := make([]FileChange, 0, len(a.Changes))
+	for path, raw := range a.Changes {
+		out = append(out, parseFileChange(path, raw))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+func parseFileChange(path string, raw json.RawMessage) FileChange {
+	c := FileChange{Path: path, Raw: raw}
+	var f fileChangeFields
+	var wrapped map[string]fileChangeFields
+	if err := json.Unmarshal(raw, &f); err == nil && f.Type != "" {
+		c.Kind, c.Content, c.UnifiedDiff, c.MovePath = f.Type, f.Content, f.UnifiedDiff, f.MovePath
+	} else if err := json.Unmarshal(raw, &wrapped); err == nil && len(wrapped) == 1 {
+		for kind, f := range wrapped {
+			c.Kind, c.Content, c.UnifiedDiff, c.MovePath = kind, f.Content, f.UnifiedDiff, f.MovePath
+		}
+	}
+	switch c.Kind {
+	case "add", "delete", "update":
+	default:
+		c.Kind = ""
+	}
+	return c
+}
+
+// DiffLines renders the change as unified diff lines: the diff itself for
+// an update, and the whole file as added or removed lines otherwise. An
+// undecodable change shows its raw JSON.
+func (c FileChange) DiffLines() []string {
+	var prefix, body string
+	switch c.Kind {
+	case "update":
+		return splitLines(c.UnifiedDiff)
+	case "add":
+		prefix, body = "+", c.Content
+	case "delete":
+		prefix, body = "-", c.Content
+	default:
+		return splitLines(string(c.Raw))
+	}
+	lines := splitLines(body)
+	for i, l := range lines {
+		lines[i] = prefix + l
+	}
+	return lines
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
 // elicitApprovalKind maps the `codex_elicitation` discriminator to ElicitApproval.Kind.
 const (
 	elicitExecDiscriminator  = "exec-approval"
@@ -76,14 +76,30 @@ func TestParseElicitApprovalEmpty(t *testing.T) {
 	}
 }
 
-func TestParseIntID(t *testing.T) {
-	if id, ok := parseIntID(json.RawMessage(`42`)); !ok || id != 42 {
-		t.Fatalf("parseIntID(42) = %d,%v", id, ok)
-	}
-	if _, ok := parseIntID(json.RawMessage(`"abc"`)); ok {
-		t.Fatal("parseIntID(string) returned ok=true, want false")
-	}
-	if _, ok := parseIntID(nil); ok {
-		t.Fatal("parseIntID(nil) returned ok=true, want false")
+func TestFileChanges(t *testing.T) {
+	a := ElicitApproval{Changes: map[string]json.RawMessage{
+		"b.go":   json.RawMessage(`{"type": "update", "unified_diff": "@@ -1 +1 @@\n-old\n+new\n", "move_path": "c.go"}`),
+		"a.go":   json.RawMessage(`{"type": "add", "content": "package a\n\nfunc A() {}\n"}`),
+		"old.go": json.RawMessage(`{"delete": {"content": "gone\n"}}`),
+		"x.bin":  json.RawMessage(`{"type": "chmod"}`),
+	}}
+	got := a.FileChanges()
+	if len(got) != 4 || got[0].Path != "a.go" || got[1].Path != "b.go" {
+		t.Fatalf("FileChanges = %+v", got)
+	}
+	if got[1].Kind != "update" || got[1].MovePath != "c.go" {
+		t.Errorf("update = %+v", got[1])
+	}
+	if l := got[0].DiffLines(); len(l) != 3 || l[0] != "+package a" || l[1] != "+" {
+		t.Errorf("add lines = %q", l)
+	}
+	if l := got[1].DiffLines(); len(l) != 3 || l[2] != "+new" {
+		t.Errorf("update lines = %q", l)
+	}
+	if got[2].Kind != "delete" || got[2].DiffLines()[0] != "-gone" {
+		t.Errorf("externally tagged delete = %+v", got[2])
+	}
+	if got[3].Kind != "" || got[3].DiffLines()[0] != `{"type": "chmod"}` {
+		t.Errorf("unknown change = %+v, %q", got[3], got[3].DiffLines())
 	}
 }
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+
+	"github.com/matt-wright86/mardi-gras/internal/mcp"
 )
 
 // SessionOptions controls how StartSession invokes `tools/call codex`.
@@ -36,14 +38,15 @@ type SessionOptions struct {
 // first). mg should pair one Client to one Session for now.
 type Session struct {
 	client   *Client
+	call     *mcp.ToolCall
 	threadID atomic.Value // string
 	reqID    int
 
-	events     chan CodexEvent
-	done       chan SessionResult
-	cancelFunc context.CancelFunc
+	events chan CodexEvent
+	done   chan SessionResult
 
 	stop      chan struct{}
+	demuxDone chan struct{}
 	stopOnce  sync.Once
 	closeOnce sync.Once
 }
@@ -88,55 +91,38 @@ func (c *Client) StartReplySession(ctx context.Context, threadID, prompt string)
 	if threadID == "" {
 		return nil, errors.New("codexmcp: StartReplySession requires a threadID")
 	}
-	return c.startToolSession(ctx, codexReplyToolName, map[string]any{
+	s, err := c.startToolSession(ctx, codexReplyToolName, map[string]any{
 		"threadId": threadID,
 		"prompt":   prompt,
 	})
+	if err != nil {
+		return nil, err
+	}
+	// A reply continues a known thread, so the session doesn't have to wait
+	// for session_configured before it can be replied to again.
+	s.setThreadID(threadID)
+	return s, nil
 }
 
 // startToolSession is the shared core of StartSession and StartReplySession:
-// it marshals a tools/call request for the named tool, registers a pending
-// response channel, writes the request, and spawns the demux + await
+// it issues the tools/call for the named tool and spawns the demux + await
 // goroutines.
 func (c *Client) startToolSession(ctx context.Context, toolName string, args map[string]any) (*Session, error) {
-	params := toolsCallParams{Name: toolName, Arguments: args}
-	callCtx, cancel := context.WithCancel(ctx)
-
-	s := &Session{
-		client:     c,
-		events:     make(chan CodexEvent, 128),
-		done:       make(chan SessionResult, 1),
-		cancelFunc: cancel,
-		stop:       make(chan struct{}),
-	}
-
-	rawParams, err := json.Marshal(params)
+	call, err := c.CallTool(ctx, toolName, args)
 	if err != nil {
-		cancel()
-		return nil, fmt.Errorf("marshal tools/call params: %w", err)
-	}
-
-	id := int(c.nextID.Add(1))
-	s.reqID = id
-
-	respCh := make(chan response, 1)
-	c.pending.Store(id, respCh)
-
-	req := request{
-		JSONRPC: jsonRPCVersion,
-		ID:      id,
-		Method:  methodToolsCall,
-		Params:  rawParams,
+		return nil, err
 	}
-	if err := c.writeJSON(req); err != nil {
-		c.pending.Delete(id)
-		close(s.events) // safe — demuxer never started
-		cancel()
-		return nil, fmt.Errorf("write tools/call: %w", err)
+	s := &Session{
+		client:    c,
+		call:      call,
+		reqID:     call.ID(),
+		events:    make(chan CodexEvent, 128),
+		done:      make(chan SessionResult, 1),
+		stop:      make(chan struct{}),
+		demuxDone: make(chan struct{}),
 	}
-
 	go s.demuxEvents()
-	go s.awaitResponse(callCtx, respCh)
+	go s.awaitResponse()
 	return s, nil
 }
 
@@ -169,15 +155,11 @@ func (s *Session) setThreadID(id string) {
 	s.threadID.CompareAndSwap(nil, id)
 }
 
-// Cancel ends the session early. The transport-level interruption that codex
-// supports requires a separate notification (`interrupt`); for now this just
-// cancels the pending tools/call and stops demuxing. The subprocess will
-// finish its current turn and may still emit events until the parent Client
-// is closed.
+// Cancel ends the session early: it sends `notifications/cancelled` for the
+// tools/call and stops demuxing. Codex may finish its current step and still
+// emit events until the parent Client is closed.
 func (s *Session) Cancel() {
-	if s.cancelFunc != nil {
-		s.cancelFunc()
-	}
+	s.call.Cancel("cancelled by user")
 	s.signalStop()
 }
 
@@ -198,8 +180,9 @@ func (s *Session) closeEvents() {
 // honoring stop it drains any events already buffered in the client channel,
 // so events that arrived just before the tool response (a normal sequence:
 // codex emits agent_message then the tools/call result) are not lost when
-// awaitResponse's defer signals stop.
+// awaitResponse signals stop.
 func (s *Session) demuxEvents() {
+	defer close(s.demuxDone)
 	defer s.closeEvents()
 	for {
 		select {
@@ -253,40 +236,24 @@ func (s *Session) forward(ev CodexEvent) {
 	}
 }
 
-func (s *Session) awaitResponse(ctx context.Context, respCh chan response) {
-	defer s.signalStop()
-	select {
-	case <-ctx.Done():
-		s.client.pending.Delete(s.reqID)
-		s.done <- SessionResult{ThreadID: s.ThreadID(), Err: ctx.Err()}
-	case <-s.client.Done():
-		// Transport closed (subprocess exited) before any response — surface
-		// the underlying read error if there was one. Without this branch,
-		// awaitResponse would block on respCh forever because the client no
-		// longer closes pending channels on Close (that closed a race window
-		// with readLoop's dispatch).
-		s.client.pending.Delete(s.reqID)
-		err := s.client.ReadError()
-		if err == nil {
-			err = errors.New("codex mcp transport closed")
-		}
-		s.done <- SessionResult{ThreadID: s.ThreadID(), Err: err}
-	case resp, ok := <-respCh:
-		if !ok {
-			s.done <- SessionResult{ThreadID: s.ThreadID(), Err: errors.New("client closed")}
-			return
-		}
-		if resp.Error != nil {
-			s.done <- SessionResult{
-				ThreadID: s.ThreadID(),
-				Err:      fmt.Errorf("codex tool error %d: %s", resp.Error.Code, resp.Error.Message),
-			}
-			return
+// awaitResponse waits for the tools/call result and publishes it on done
+// only once demuxEvents has exited, so a reply started as soon as Done fires
+// can't have its first events drained away by this session.
+func (s *Session) awaitResponse() {
+	res := <-s.call.Done()
+	s.signalStop()
+	<-s.demuxDone
+	if res.Err != nil {
+		var rpcErr *mcp.RPCError
+		if errors.As(res.Err, &rpcErr) {
+			res.Err = fmt.Errorf("codex tool error %d: %s", rpcErr.Code, rpcErr.Message)
 		}
-		content, thread := parseCodexToolResult(resp.Result)
-		s.setThreadID(thread)
-		s.done <- SessionResult{ThreadID: s.ThreadID(), Content: content}
+		s.done <- SessionResult{ThreadID: s.ThreadID(), Err: res.Err}
+		return
 	}
+	content, thread := parseCodexToolResult(res.Raw)
+	s.setThreadID(thread)
+	s.done <- SessionResult{ThreadID: s.ThreadID(), Content: content}
 }
 
 func buildCodexArgs(opts SessionOptions) map[string]any {
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/mcp"
 )
 
 func TestStartSessionRequiresPrompt(t *testing.T) {
@@ -32,9 +34,9 @@ func TestStartSessionForwardsArgs(t *testing.T) {
 			t.Errorf("StartSession: %v", err)
 		}
 	}()
-	req := fs.Expect(t, methodToolsCall, 2*time.Second)
+	req := fs.Expect(t, mcp.MethodToolsCall, 2*time.Second)
 
-	var p toolsCallParams
+	var p mcp.CallToolParams
 	if err := json.Unmarshal(req.Params, &p); err != nil {
 		t.Fatalf("decode params: %v", err)
 	}
@@ -64,7 +66,7 @@ func TestSessionFiltersEventsByRequestID(t *testing.T) {
 	if err != nil {
 		t.Fatalf("StartSession: %v", err)
 	}
-	req := fs.Expect(t, methodToolsCall, 2*time.Second)
+	req := fs.Expect(t, mcp.MethodToolsCall, 2*time.Second)
 	// Wrong requestID — should be filtered out.
 	fs.SendEvent(999, "tx", `{"type":"agent_message","message":"unrelated"}`)
 	// Right requestID.
@@ -96,7 +98,7 @@ func TestSessionDoneReceivesResult(t *testing.T) {
 	if err != nil {
 		t.Fatalf("StartSession: %v", err)
 	}
-	req := fs.Expect(t, methodToolsCall, 2*time.Second)
+	req := fs.Expect(t, mcp.MethodToolsCall, 2*time.Second)
 	fs.SendEvent(req.ID, "t-final", `{"type":"task_complete","last_agent_message":"hi"}`)
 	fs.Respond(req.ID, json.RawMessage(`{"structuredContent":{"threadId":"t-final","content":"final answer"}}`))
 
@@ -118,7 +120,7 @@ func TestSessionDoneReceivesError(t *testing.T) {
 	if err != nil {
 		t.Fatalf("StartSession: %v", err)
 	}
-	req := fs.Expect(t, methodToolsCall, 2*time.Second)
+	req := fs.Expect(t, mcp.MethodToolsCall, 2*time.Second)
 	fs.RespondError(req.ID, -32000, "rate limited")
 	res := waitDone(t, sess)
 	if res.Err == nil || !strings.Contains(res.Err.Error(), "rate limited") {
@@ -145,9 +147,9 @@ func TestStartReplySessionForwardsArgs(t *testing.T) {
 			t.Errorf("StartReplySession: %v", err)
 		}
 	}()
-	req := fs.Expect(t, methodToolsCall, 2*time.Second)
+	req := fs.Expect(t, mcp.MethodToolsCall, 2*time.Second)
 
-	var p toolsCallParams
+	var p mcp.CallToolParams
 	if err := json.Unmarshal(req.Params, &p); err != nil {
 		t.Fatalf("decode params: %v", err)
 	}
@@ -172,7 +174,10 @@ func TestStartReplySessionStreamsEventsAndDone(t *testing.T) {
 	if err != nil {
 		t.Fatalf("StartReplySession: %v", err)
 	}
-	req := fs.Expect(t, methodToolsCall, 2*time.Second)
+	if sess.ThreadID() != "thr-2" {
+		t.Errorf("reply session ThreadID = %q before any event", sess.ThreadID())
+	}
+	req := fs.Expect(t, mcp.MethodToolsCall, 2*time.Second)
 	fs.SendEvent(req.ID, "thr-2", `{"type":"agent_message","message":"the reply"}`)
 	fs.Respond(req.ID, json.RawMessage(`{"structuredContent":{"threadId":"thr-2","content":"the reply"}}`))
 
@@ -206,12 +211,19 @@ func TestSessionCancelStopsBeforeResponse(t *testing.T) {
 	if err != nil {
 		t.Fatalf("StartSession: %v", err)
 	}
-	_ = fs.Expect(t, methodToolsCall, 2*time.Second)
+	req := fs.Expect(t, mcp.MethodToolsCall, 2*time.Second)
 	sess.Cancel()
 	res := waitDone(t, sess)
 	if res.Err == nil {
 		t.Fatal("expected cancellation error")
 	}
+	n := fs.Expect(t, mcp.MethodCancelled, 2*time.Second)
+	var p struct {
+		RequestID int `json:"requestId"`
+	}
+	if err := json.Unmarshal(n.Params, &p); err != nil || p.RequestID != req.ID {
+		t.Fatalf("cancelled params = %s, want requestId %d", n.Params, req.ID)
+	}
 }
 
 func waitDone(t *testing.T, sess *Session) SessionResult {
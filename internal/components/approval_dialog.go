@@ -6,16 +6,20 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/matt-wright86/mardi-gras/internal/ui"
 )
 
 // ApprovalDialogResult is sent when the approval dialog completes. Decision is a
 // codex ReviewDecision value ("approved", "approved_for_session", "denied",
 // "abort"). Cancelled is true when the user dismissed the dialog (esc/q); the app
-// treats that as a denial.
+// treats that as a denial. Rejected lists the patch files the user rejected;
+// codex takes one decision per patch, so any rejection turns an approval
+// into "denied".
 type ApprovalDialogResult struct {
 	Decision  string
 	Cancelled bool
+	Rejected  []string
 }
 
 // approvalDecision is one selectable choice in the dialog.
@@ -34,11 +38,21 @@ var approvalDecisions = []approvalDecision{
 	{"Abort turn", "abort"},
 }
 
-// ApprovalDialog prompts the user to approve or deny a codex action (a shell
-// command or a patch). It mirrors RecoveryDialog's Update/View shape and is
-// decoupled from codexmcp — the app passes plain fields.
+// elicitDecisions answer a generic MCP elicitation, which only accepts,
+// declines or cancels.
+var elicitDecisions = []approvalDecision{
+	{"Approve", "approved"},
+	{"Deny", "denied"},
+	{"Cancel", "abort"},
+}
+
+// ApprovalDialog prompts the user to approve or deny an agent's action (a
+// codex shell command or patch, or another MCP agent's question). It mirrors
+// RecoveryDialog's Update/View shape and is decoupled from codexmcp — the app
+// passes plain fields.
 type ApprovalDialog struct {
-	kind    string // "exec" | "patch"
+	kind    string // "exec" | "patch" | "elicit"
+	agent   string
 	message string
 	command []string
 	cwd     string
@@ -47,8 +61,20 @@ type ApprovalDialog struct {
 	selIdx  int
 	width   int
 	height  int
+
+	// Patch review: the decoded diffs, pre-highlighted, the file being shown,
+	// its scroll offset, and the files marked rejected.
+	diffs    []DiffFile
+	rendered [][]string
+	fileIdx  int
+	scroll   int
+	rejected map[int]bool
 }
 
+// approvalMaxFileRows caps the patch file list; longer lists scroll with the
+// selected file.
+const approvalMaxFileRows = 6
+
 // NewApprovalDialog builds an approval dialog. For exec approvals pass command +
 // cwd; for patch approvals pass files. reason is optional for both.
 func NewApprovalDialog(kind, message string, command []string, cwd, reason string, files []string, width, height int) ApprovalDialog {
@@ -64,6 +90,46 @@ func NewApprovalDialog(kind, message string, command []string, cwd, reason strin
 	}
 }
 
+// SetAgent names the agent asking, for the heading. Defaults to Codex.
+func (ad *ApprovalDialog) SetAgent(label string) {
+	ad.agent = label
+}
+
+// decisions returns the choices offered for the dialog's kind.
+func (ad ApprovalDialog) decisions() []approvalDecision {
+	if ad.kind == "elicit" {
+		return elicitDecisions
+	}
+	return approvalDecisions
+}
+
+// SetDiffs attaches the patch's per-file diffs, turning the file list into
+// a diff viewer with per-file review.
+func (ad *ApprovalDialog) SetDiffs(diffs []DiffFile) {
+	ad.diffs = diffs
+	ad.rendered = make([][]string, len(diffs))
+	for i, d := range diffs {
+		path := d.Path
+		if d.MovePath != "" {
+			path = d.MovePath
+		}
+		ad.rendered[i] = highlightDiff(path, d.Lines)
+	}
+	ad.fileIdx, ad.scroll = 0, 0
+	ad.rejected = make(map[int]bool)
+}
+
+// diffHeight is how many diff lines fit under the rest of the dialog.
+func (ad ApprovalDialog) diffHeight() int {
+	return max(ad.height-20-min(len(ad.diffs), approvalMaxFileRows), 4)
+}
+
+// scrollDiff moves the shown diff by delta lines, clamped to its length.
+func (ad *ApprovalDialog) scrollDiff(delta int) {
+	maxScroll := max(len(ad.rendered[ad.fileIdx])-ad.diffHeight(), 0)
+	ad.scroll = min(max(ad.scroll+delta, 0), maxScroll)
+}
+
 // Update handles key events for the approval dialog.
 func (ad ApprovalDialog) Update(msg tea.Msg) (ApprovalDialog, tea.Cmd) {
 	km, ok := msg.(tea.KeyPressMsg)
@@ -71,6 +137,34 @@ func (ad ApprovalDialog) Update(msg tea.Msg) (ApprovalDialog, tea.Cmd) {
 		return ad, nil
 	}
 
+	if len(ad.diffs) > 0 {
+		switch km.String() {
+		case "tab":
+			ad.fileIdx = (ad.fileIdx + 1) % len(ad.diffs)
+			ad.scroll = 0
+			return ad, nil
+		case "shift+tab":
+			ad.fileIdx = (ad.fileIdx + len(ad.diffs) - 1) % len(ad.diffs)
+			ad.scroll = 0
+			return ad, nil
+		case "J":
+			ad.scrollDiff(1)
+			return ad, nil
+		case "K":
+			ad.scrollDiff(-1)
+			return ad, nil
+		case "pgdown", "ctrl+d":
+			ad.scrollDiff(ad.diffHeight())
+			return ad, nil
+		case "pgup", "ctrl+u":
+			ad.scrollDiff(-ad.diffHeight())
+			return ad, nil
+		case "x":
+			ad.rejected[ad.fileIdx] = !ad.rejected[ad.fileIdx]
+			return ad, nil
+		}
+	}
+
 	switch km.String() {
 	case "esc", "q":
 		return ad, func() tea.Msg {
@@ -78,7 +172,7 @@ func (ad ApprovalDialog) Update(msg tea.Msg) (ApprovalDialog, tea.Cmd) {
 		}
 
 	case "j", "down":
-		if ad.selIdx < len(approvalDecisions)-1 {
+		if ad.selIdx < len(ad.decisions())-1 {
 			ad.selIdx++
 		}
 
@@ -88,9 +182,12 @@ func (ad ApprovalDialog) Update(msg tea.Msg) (ApprovalDialog, tea.Cmd) {
 		}
 
 	case "enter":
-		selected := approvalDecisions[ad.selIdx]
+		res := ApprovalDialogResult{Decision: ad.decisions()[ad.selIdx].Value}
+		if res.Rejected = ad.rejectedFiles(); len(res.Rejected) > 0 && strings.HasPrefix(res.Decision, "approved") {
+			res.Decision = "denied"
+		}
 		return ad, func() tea.Msg {
-			return ApprovalDialogResult{Decision: selected.Value}
+			return res
 		}
 	}
 
@@ -107,20 +204,33 @@ func (ad ApprovalDialog) View() string {
 	var lines []string
 
 	// Title
-	heading := "CODEX WANTS TO RUN A COMMAND"
-	if ad.kind == "patch" {
-		heading = "CODEX WANTS TO APPLY A PATCH"
+	agent := "CODEX"
+	if ad.agent != "" {
+		agent = strings.ToUpper(ad.agent)
+	}
+	heading := agent + " WANTS TO RUN A COMMAND"
+	switch ad.kind {
+	case "patch":
+		heading = agent + " WANTS TO APPLY A PATCH"
+	case "elicit":
+		heading = agent + " ASKS FOR APPROVAL"
 	}
 	lines = append(lines, titleStyle.Render(fmt.Sprintf("  %s %s", ui.SymGate, heading)))
 	lines = append(lines, "")
 
 	// Body
-	switch ad.kind {
-	case "patch":
+	switch {
+	case ad.kind == "patch" && len(ad.diffs) > 0:
+		lines = append(lines, ad.diffView()...)
+	case ad.kind == "patch":
 		lines = append(lines, normalStyle.Render(fmt.Sprintf("  %d file(s) changed:", len(ad.files))))
 		for _, f := range ad.files {
 			lines = append(lines, fmt.Sprintf("    %s", dimStyle.Render(f)))
 		}
+	case ad.kind == "elicit":
+		for l := range strings.SplitSeq(ad.message, "\n") {
+			lines = append(lines, normalStyle.Render("  "+l))
+		}
 	default:
 		lines = append(lines, normalStyle.Render(fmt.Sprintf("  %s", strings.Join(ad.command, " "))))
 		if ad.cwd != "" {
@@ -134,7 +244,7 @@ func (ad ApprovalDialog) View() string {
 	lines = append(lines, "")
 
 	// Decisions
-	for i, d := range approvalDecisions {
+	for i, d := range ad.decisions() {
 		cursor := "    "
 		labelStyle := normalStyle
 		if i == ad.selIdx {
@@ -144,7 +254,89 @@ func (ad ApprovalDialog) View() string {
 		lines = append(lines, fmt.Sprintf("%s%s", cursor, labelStyle.Render(d.Label)))
 	}
 	lines = append(lines, "")
-	lines = append(lines, dimStyle.Render("  ↑/↓ select   enter confirm   esc deny"))
+	if n := len(ad.rejectedFiles()); n > 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(ui.StatusStalled).Render(
+			fmt.Sprintf("  %d file(s) rejected: codex takes one decision per patch, so approving denies it", n)))
+	}
+	if len(ad.diffs) > 0 {
+		lines = append(lines, dimStyle.Render("  tab file   J/K scroll   x reject file   ↑/↓ select   enter confirm   esc deny"))
+	} else {
+		lines = append(lines, dimStyle.Render("  ↑/↓ select   enter confirm   esc deny"))
+	}
 
 	return strings.Join(lines, "\n")
 }
+
+// rejectedFiles returns the paths the user rejected, in file order.
+func (ad ApprovalDialog) rejectedFiles() []string {
+	var out []string
+	for i, d := range ad.diffs {
+		if ad.rejected[i] {
+			out = append(out, d.Path)
+		}
+	}
+	return out
+}
+
+// diffView renders the patch's file list and the selected file's diff.
+func (ad ApprovalDialog) diffView() []string {
+	dimStyle := lipgloss.NewStyle().Foreground(ui.Dim)
+	normalStyle := lipgloss.NewStyle().Foreground(ui.Light)
+	selectedStyle := lipgloss.NewStyle().Foreground(ui.BrightGreen)
+	rejectStyle := lipgloss.NewStyle().Foreground(ui.StatusStalled)
+	width := max(ad.width-12, 20)
+
+	lines := []string{normalStyle.Render(fmt.Sprintf("  %d file(s) changed:", len(ad.diffs)))}
+	first := min(max(ad.fileIdx-approvalMaxFileRows/2, 0), max(len(ad.diffs)-approvalMaxFileRows, 0))
+	last := min(first+approvalMaxFileRows, len(ad.diffs))
+	for i := first; i < last; i++ {
+		d := ad.diffs[i]
+		cursor, style := "    ", dimStyle
+		if i == ad.fileIdx {
+			cursor, style = selectedStyle.Render("  > "), normalStyle
+		}
+		mark := selectedStyle.Render("✓")
+		if ad.rejected[i] {
+			mark = rejectStyle.Render("✗")
+		}
+		name := d.Path
+		if d.MovePath != "" {
+			name += " → " + d.MovePath
+		}
+		adds, dels := diffStats(d.Lines)
+		stats := selectedStyle.Render(fmt.Sprintf("+%d", adds)) + " " + rejectStyle.Render(fmt.Sprintf("-%d", dels))
+		lines = append(lines, fmt.Sprintf("%s%s %s %s  %s", cursor, mark, diffKindLabel(d.Kind), style.Render(name), stats))
+	}
+	if last-first < len(ad.diffs) {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("    (%d of %d files)", ad.fileIdx+1, len(ad.diffs))))
+	}
+
+	body := ad.rendered[ad.fileIdx]
+	height := ad.diffHeight()
+	end := min(ad.scroll+height, len(body))
+	rule := fmt.Sprintf("  ── %s ", ad.diffs[ad.fileIdx].Path)
+	if len(body) > height {
+		rule += fmt.Sprintf("(%d–%d of %d) ", ad.scroll+1, end, len(body))
+	}
+	lines = append(lines, "", dimStyle.Render(rule+strings.Repeat("─", max(width-ansi.StringWidth(rule), 0))))
+	if len(body) == 0 {
+		lines = append(lines, dimStyle.Render("  (no changes)"))
+	}
+	for _, l := range body[ad.scroll:end] {
+		lines = append(lines, "  "+ansi.Truncate(l, width, "…"))
+	}
+	return lines
+}
+
+// diffKindLabel abbreviates a file change kind like git status does.
+func diffKindLabel(kind string) string {
+	switch kind {
+	case "add":
+		return lipgloss.NewStyle().Foreground(ui.BrightGreen).Render("A")
+	case "delete":
+		return lipgloss.NewStyle().Foreground(ui.StatusStalled).Render("D")
+	case "update":
+		return lipgloss.NewStyle().Foreground(ui.BrightGold).Render("M")
+	}
+	return lipgloss.NewStyle().Foreground(ui.Dim).Render("?")
+}
@@ -1,10 +1,12 @@
 package components
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
 )
 
 func TestApprovalDialogDefaultApprove(t *testing.T) {
@@ -78,6 +80,24 @@ func TestApprovalDialogViewExec(t *testing.T) {
 	}
 }
 
+func TestApprovalDialogElicit(t *testing.T) {
+	ad := NewApprovalDialog("elicit", "Delete the staging database?", nil, "", "", nil, 80, 24)
+	ad.SetAgent("Helper")
+	v := ad.View()
+	if !strings.Contains(v, "HELPER ASKS FOR APPROVAL") || !strings.Contains(v, "Delete the staging database?") {
+		t.Fatalf("elicit view:\n%s", v)
+	}
+	if strings.Contains(v, "Approve for this session") {
+		t.Fatalf("elicit view offers codex-only decisions:\n%s", v)
+	}
+	// Decisions: approved, denied, abort. One down → denied.
+	ad, _ = ad.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	_, cmd := ad.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if res := cmd().(ApprovalDialogResult); res.Decision != "denied" {
+		t.Fatalf("Decision = %q, want denied", res.Decision)
+	}
+}
+
 func TestApprovalDialogViewPatch(t *testing.T) {
 	ad := NewApprovalDialog("patch", "Allow?", nil, "", "implement", []string{"a.go", "b.go"}, 80, 24)
 	v := ad.View()
@@ -91,3 +111,65 @@ func TestApprovalDialogViewPatch(t *testing.T) {
 		t.Fatalf("patch view missing file count:\n%s", v)
 	}
 }
+
+func patchDialog() ApprovalDialog {
+	long := []string{"@@ -0,0 +1,40 @@"}
+	for i := range 40 {
+		long = append(long, fmt.Sprintf("+line %d", i))
+	}
+	ad := NewApprovalDialog("patch", "Allow?", nil, "", "", []string{"main.go", "notes.txt"}, 100, 30)
+	ad.SetDiffs([]DiffFile{
+		{Path: "main.go", Kind: "update", Lines: []string{
+			"@@ -1,3 +1,3 @@", " package main", "-// old comment", "+// new comment", "\\ No newline at end of file",
+		}},
+		{Path: "notes.txt", Kind: "add", Lines: long},
+	})
+	return ad
+}
+
+func TestApprovalDialogPatchDiffView(t *testing.T) {
+	ad := patchDialog()
+	v := ansi.Strip(ad.View())
+	for _, want := range []string{"M main.go  +1 -1", "A notes.txt", "── main.go", "-// old comment", "+// new comment", " package main", "tab file"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("view missing %q:\n%s", want, v)
+		}
+	}
+
+	ad, _ = ad.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	v = ansi.Strip(ad.View())
+	if !strings.Contains(v, "── notes.txt (1–") || !strings.Contains(v, "+line 0") {
+		t.Fatalf("tab should show the next file's diff:\n%s", v)
+	}
+	ad, _ = ad.Update(tea.KeyPressMsg{Code: 'J', Text: "J"})
+	if v = ansi.Strip(ad.View()); strings.Contains(v, "@@ -0,0") || !strings.Contains(v, "+line 7") {
+		t.Fatalf("J should scroll the diff by a line:\n%s", v)
+	}
+	for range 10 {
+		ad, _ = ad.Update(tea.KeyPressMsg{Code: tea.KeyPgDown})
+	}
+	if v = ansi.Strip(ad.View()); !strings.Contains(v, "+line 39") {
+		t.Fatalf("pgdown should stop at the end of the diff:\n%s", v)
+	}
+}
+
+func TestApprovalDialogRejectedFileDeniesPatch(t *testing.T) {
+	ad := patchDialog()
+	ad, _ = ad.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	ad, _ = ad.Update(tea.KeyPressMsg{Code: 'x', Text: "x"})
+	if v := ansi.Strip(ad.View()); !strings.Contains(v, "✗ A notes.txt") || !strings.Contains(v, "1 file(s) rejected") {
+		t.Fatalf("view should mark the rejection:\n%s", v)
+	}
+	_, cmd := ad.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	res := cmd().(ApprovalDialogResult)
+	if res.Decision != "denied" || len(res.Rejected) != 1 || res.Rejected[0] != "notes.txt" {
+		t.Fatalf("result = %+v", res)
+	}
+
+	// Un-rejecting restores the plain approval.
+	ad, _ = ad.Update(tea.KeyPressMsg{Code: 'x', Text: "x"})
+	_, cmd = ad.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if res := cmd().(ApprovalDialogResult); res.Decision != "approved" || res.Rejected != nil {
+		t.Fatalf("result = %+v", res)
+	}
+}
@@ -0,0 +1,139 @@
+package components
+
+import (
+	"path/filepath"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/matt-wright86/mardi-gras/internal/ui"
+)
+
+// DiffFile is one file of a patch shown in the approval dialog.
+type DiffFile struct {
+	Path     string
+	Kind     string // "add", "delete", "update", or "" when codex sent something unrecognized
+	MovePath string // new path when an update renames the file
+	Lines    []string
+}
+
+// diffStats counts added and removed lines, skipping file headers.
+func diffStats(lines []string) (adds, dels int) {
+	inHunk := false
+	for _, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "@@"):
+			inHunk = true
+		case !inHunk && (strings.HasPrefix(l, "--- ") || strings.HasPrefix(l, "+++ ")):
+		case strings.HasPrefix(l, "+"):
+			adds++
+		case strings.HasPrefix(l, "-"):
+			dels++
+		}
+	}
+	return adds, dels
+}
+
+// highlightDiff colors unified diff lines: hunk headers in gold, removed
+// lines in red, and added and context lines syntax-highlighted by the file's
+// language behind a +/space gutter.
+func highlightDiff(path string, lines []string) []string {
+	hunkStyle := lipgloss.NewStyle().Foreground(ui.BrightGold)
+	headerStyle := lipgloss.NewStyle().Foreground(ui.Dim)
+	delStyle := lipgloss.NewStyle().Foreground(ui.StatusStalled)
+	addStyle := lipgloss.NewStyle().Foreground(ui.BrightGreen)
+
+	out := make([]string, len(lines))
+	// Added and context lines are tokenised as one text so constructs that
+	// span lines (block comments, raw strings) keep their colors.
+	var codeIdx []int
+	var code strings.Builder
+	inHunk := false
+	for i, l := range lines {
+		l = strings.ReplaceAll(l, "\t", "    ")
+		switch {
+		case strings.HasPrefix(l, "@@"):
+			inHunk = true
+			out[i] = hunkStyle.Render(l)
+		case !inHunk && (strings.HasPrefix(l, "--- ") || strings.HasPrefix(l, "+++ ")),
+			strings.HasPrefix(l, `\`):
+			out[i] = headerStyle.Render(l)
+		case strings.HasPrefix(l, "-"):
+			out[i] = delStyle.Render(l)
+		default:
+			gutter := " "
+			if strings.HasPrefix(l, "+") {
+				gutter = addStyle.Render("+")
+			}
+			if l != "" {
+				l = l[1:]
+			}
+			out[i] = gutter
+			codeIdx = append(codeIdx, i)
+			code.WriteString(l + "\n")
+		}
+	}
+	for j, colored := range highlightCode(path, code.String(), len(codeIdx)) {
+		out[codeIdx[j]] += colored
+	}
+	return out
+}
+
+// highlightCode syntax-colors text with the lexer chroma picks for path and
+// returns exactly n lines. Unknown languages come back uncolored.
+func highlightCode(path, text string, n int) []string {
+	plain := lipgloss.NewStyle().Foreground(ui.Light)
+	out := make([]string, 0, n)
+	lexer := lexers.Match(filepath.Base(path))
+	var it chroma.Iterator
+	if lexer != nil {
+		it, _ = chroma.Coalesce(lexer).Tokenise(nil, text)
+	}
+	if it == nil {
+		for l := range strings.SplitSeq(strings.TrimSuffix(text, "\n"), "\n") {
+			out = append(out, plain.Render(l))
+		}
+	} else {
+		var cur strings.Builder
+		for tok := it(); tok != chroma.EOF; tok = it() {
+			style := tokenStyle(tok.Type)
+			for k, part := range strings.Split(tok.Value, "\n") {
+				if k > 0 {
+					out = append(out, cur.String())
+					cur.Reset()
+				}
+				if part != "" {
+					cur.WriteString(style.Render(part))
+				}
+			}
+		}
+		if cur.Len() > 0 {
+			out = append(out, cur.String())
+		}
+	}
+	for len(out) < n {
+		out = append(out, "")
+	}
+	return out[:n]
+}
+
+// tokenStyle maps chroma token types onto the Mardi Gras palette.
+func tokenStyle(t chroma.TokenType) lipgloss.Style {
+	s := lipgloss.NewStyle()
+	switch {
+	case t.InCategory(chroma.Comment):
+		return s.Foreground(ui.Dim)
+	case t.InCategory(chroma.Keyword):
+		return s.Foreground(ui.BrightPurple)
+	case t.InSubCategory(chroma.LiteralString):
+		return s.Foreground(ui.Green)
+	case t.InSubCategory(chroma.LiteralNumber):
+		return s.Foreground(ui.Orange)
+	case t == chroma.NameFunction, t == chroma.NameClass, t == chroma.NameBuiltin:
+		return s.Foreground(ui.BrightGold)
+	case t.InCategory(chroma.Operator), t.InCategory(chroma.Punctuation):
+		return s.Foreground(ui.Muted)
+	}
+	return s.Foreground(ui.Light)
+}
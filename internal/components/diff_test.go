@@ -0,0 +1,46 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestHighlightDiffKeepsText(t *testing.T) {
+	lines := []string{
+		"--- a/main.go",
+		"+++ b/main.go",
+		"@@ -1,4 +1,5 @@",
+		" package main",
+		"+/* spans",
+		"+   lines */",
+		"-func old() {}",
+		"+func main() {\tprintln(1) }",
+		"--- not a header inside a hunk",
+	}
+	got := highlightDiff("main.go", lines)
+	if len(got) != len(lines) {
+		t.Fatalf("got %d lines, want %d", len(got), len(lines))
+	}
+	for i, l := range got {
+		want := strings.ReplaceAll(lines[i], "\t", "    ")
+		if ansi.Strip(l) != want {
+			t.Errorf("line %d = %q, want %q", i, ansi.Strip(l), want)
+		}
+	}
+	// The gutter, keyword, name and punctuation are styled separately.
+	if strings.Count(got[7], "\x1b[") < 4 {
+		t.Errorf("code line isn't highlighted: %q", got[7])
+	}
+	if adds, dels := diffStats(lines); adds != 3 || dels != 2 {
+		t.Errorf("diffStats = +%d -%d, want +3 -2", adds, dels)
+	}
+}
+
+func TestHighlightCodeUnknownLanguage(t *testing.T) {
+	got := highlightCode("notes.unknownext", "a\nb\n", 3)
+	if len(got) != 3 || ansi.Strip(got[1]) != "b" || got[2] != "" {
+		t.Fatalf("got %q", got)
+	}
+}
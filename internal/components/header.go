@@ -18,6 +18,7 @@ type Header struct {
 	Width            int
 	Groups           map[data.ParadeStatus][]data.Issue
 	AgentCount       int
+	QueuedAgents     int // issues waiting in the local agent queue
 	TownStatus       *gastown.TownStatus
 	GasTownAvailable bool
 	ProblemCount     int
@@ -46,6 +47,9 @@ func (h Header) View() string {
 		agentStyle := lipgloss.NewStyle().Foreground(ui.StatusAgent).Bold(true)
 		agentInfo = agentStyle.Render(fmt.Sprintf(" %s%d", ui.SymAgent, h.AgentCount))
 	}
+	if h.QueuedAgents > 0 {
+		agentInfo += ui.HeaderCounts.Render(fmt.Sprintf(" +%d queued", h.QueuedAgents))
+	}
 
 	gasTownInfo := ""
 	if h.GasTownAvailable && h.TownStatus != nil {
@@ -73,6 +73,7 @@ func allSections() []helpSection {
 				{key: "?", desc: "Toggle help"},
 				{key: ": / Ctrl+K", desc: "Open command palette"},
 				{key: "p", desc: "Toggle problems view (gt)"},
+				{key: "I", desc: "Toggle flow analytics (cycle/lead time)"},
 			},
 		},
 		{
@@ -87,6 +88,7 @@ func allSections() []helpSection {
 				{key: "a", desc: "Launch agent (tmux: new window)"},
 				{key: "A", desc: "Kill active agent on issue"},
 				{key: "M", desc: "Toggle codex (MCP) live transcript"},
+				{key: "S", desc: "List codex sessions, switch transcript"},
 			},
 		},
 		{
@@ -151,20 +153,70 @@ func allSections() []helpSection {
 			bindings: []helpBinding{
 				{key: "j / k", desc: "Navigate agents/convoys"},
 				{key: "g / G", desc: "Jump to first/last"},
-				{key: "tab", desc: "Switch section (agents/convoys)"},
+				{key: "tab", desc: "Switch section (agents/convoys/mail/patrol)"},
 				{key: "n", desc: "Nudge selected agent"},
+				{key: "t", desc: "Agent transcript / convoy timeline"},
 				{key: "h", desc: "Handoff work from agent"},
 				{key: "K", desc: "Decommission polecat"},
-				{key: "enter", desc: "Expand/collapse convoy or message"},
+				{key: "enter", desc: "Expand/collapse convoy, message or scan"},
 				{key: "l", desc: "Land convoy"},
 				{key: "x", desc: "Close convoy"},
 				{key: "r", desc: "Reply to selected message"},
 				{key: "w", desc: "Compose new message to agent"},
 				{key: "d", desc: "Archive selected message"},
 				{key: "C", desc: "Create convoy from selection"},
+				{key: "P", desc: "Plan convoy (query or blocking closure)"},
+				{key: "o / O", desc: "Sort scorecards by next column / reverse"},
 				{key: "", desc: "convoy mini-DAG: ● done · ○ open · ─ dependency"},
 			},
 		},
+		{
+			title: "SESSION TRANSCRIPT (t in Gas Town panel)",
+			bindings: []helpBinding{
+				{key: "j / k", desc: "Scroll line by line"},
+				{key: "ctrl+d / ctrl+u", desc: "Scroll half a page"},
+				{key: "g / G", desc: "Jump to top/bottom (G resumes follow)"},
+				{key: "f", desc: "Toggle follow mode"},
+				{key: "/", desc: "Search transcript"},
+				{key: "n / N", desc: "Next/previous match"},
+				{key: "i", desc: "Send a message into the session"},
+				{key: "R", desc: "Refresh now"},
+				{key: "esc", desc: "Back to Gas Town panel"},
+			},
+		},
+		{
+			title: "CONVOY TIMELINE (t on a convoy)",
+			bindings: []helpBinding{
+				{key: "j / k", desc: "Navigate members"},
+				{key: "g / G", desc: "Jump to first/last"},
+				{key: "R", desc: "Refresh now"},
+				{key: "esc", desc: "Back to Gas Town panel"},
+				{key: "", desc: "· waiting  █ worked  ░ projected  ┃ now  ⊘ blocked  ◌ idle worker"},
+			},
+		},
+		{
+			title: "CONVOY PLANNER (P)",
+			bindings: []helpBinding{
+				{key: "j / k", desc: "Navigate proposed members"},
+				{key: "space / x", desc: "Prune or restore member"},
+				{key: "X", desc: "Prune all / restore all"},
+				{key: "/", desc: "Edit source: issue ids or filter query"},
+				{key: "c / enter", desc: "Create convoy from the selection"},
+				{key: "+", desc: "Add selection to existing convoy (tab completes)"},
+				{key: "esc", desc: "Close planner"},
+			},
+		},
+		{
+			title: "FLOW ANALYTICS (I)",
+			bindings: []helpBinding{
+				{key: "j / k", desc: "Navigate groups"},
+				{key: "h / l", desc: "Breakdown: type, priority, label, assignee"},
+				{key: "m", desc: "Toggle cycle time / lead time"},
+				{key: "v", desc: "Cycle table / histogram / scatter / cumulative flow / costs"},
+				{key: "+ / -", desc: "Flow and cost window (14/30/60/90 days)"},
+				{key: "esc", desc: "Close analytics"},
+			},
+		},
 		{
 			title: "PROBLEMS (p)",
 			bindings: []helpBinding{
@@ -174,6 +226,9 @@ func allSections() []helpSection {
 				{key: "h", desc: "Handoff from agent"},
 				{key: "K", desc: "Decommission polecat"},
 				{key: "R", desc: "Recover dead rig (opens confirmation)"},
+				{key: "a", desc: "Acknowledge problem (toggle)"},
+				{key: "z", desc: "Snooze problem for 1h (wake in history)"},
+				{key: "H", desc: "Toggle snoozed/resolved history"},
 			},
 		},
 	}
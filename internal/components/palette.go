@@ -45,7 +45,17 @@ const (
 	ActionPrunePreview
 	ActionPruneClosed
 	ActionClaimNextReady
-	ActionCodexResume
+	ActionResumeAgent
+	ActionPlanConvoy
+	ActionTogglePlaybooks
+	ActionPreviewPrompt
+	ActionCleanupWorktree
+	ActionQueueAgents
+	ActionUnqueueAgent
+	ActionClearAgentQueue
+	ActionCodexSessions
+	ActionAttachCodexTranscript
+	ActionReloadRuntimes
 )
 
 // PaletteCommand is a single entry in the command palette.
@@ -0,0 +1,101 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/textarea"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/matt-wright86/mardi-gras/internal/ui"
+)
+
+// PromptPreviewResult is sent when the prompt preview completes. Prompt is
+// the text as edited, for this launch only.
+type PromptPreviewResult struct {
+	IssueID   string
+	Prompt    string
+	Cancelled bool
+}
+
+// PromptPreview shows the rendered agent prompt for an issue in an editor,
+// so it can be tweaked before launching.
+type PromptPreview struct {
+	issueID  string
+	template string // name of the template the prompt came from
+	runtime  string // runtime label, for the title
+	editor   textarea.Model
+	width    int
+	height   int
+}
+
+// NewPromptPreview creates a preview holding prompt, rendered from the
+// named template, for launching runtime on issueID.
+func NewPromptPreview(issueID, template, runtime, prompt string, width, height int) PromptPreview {
+	ta := textarea.New()
+	ta.Prompt = ""
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	ta.MaxHeight = 0
+	styles := ta.Styles()
+	styles.Focused.CursorLine = lipgloss.NewStyle()
+	styles.Focused.Text = lipgloss.NewStyle().Foreground(ui.Light)
+	styles.Focused.EndOfBuffer = lipgloss.NewStyle().Foreground(ui.Dim)
+	ta.SetStyles(styles)
+	ta.SetWidth(width - 12)
+	ta.SetHeight(max(height-12, 5))
+	ta.SetValue(prompt)
+	ta.MoveToBegin()
+	ta.Focus()
+
+	return PromptPreview{
+		issueID:  issueID,
+		template: template,
+		runtime:  runtime,
+		editor:   ta,
+		width:    width,
+		height:   height,
+	}
+}
+
+// Init returns the blink command for the editor cursor.
+func (pp PromptPreview) Init() tea.Cmd {
+	return textarea.Blink
+}
+
+// Update handles messages for the prompt preview. ctrl+s launches with the
+// edited prompt and esc cancels; everything else edits.
+func (pp PromptPreview) Update(msg tea.Msg) (PromptPreview, tea.Cmd) {
+	if km, ok := msg.(tea.KeyPressMsg); ok {
+		switch km.String() {
+		case "esc":
+			return pp, func() tea.Msg {
+				return PromptPreviewResult{IssueID: pp.issueID, Cancelled: true}
+			}
+		case "ctrl+s":
+			prompt := pp.editor.Value()
+			if strings.TrimSpace(prompt) == "" {
+				return pp, nil
+			}
+			return pp, func() tea.Msg {
+				return PromptPreviewResult{IssueID: pp.issueID, Prompt: prompt}
+			}
+		}
+	}
+	var cmd tea.Cmd
+	pp.editor, cmd = pp.editor.Update(msg)
+	return pp, cmd
+}
+
+// View renders the prompt preview.
+func (pp PromptPreview) View() string {
+	dimStyle := lipgloss.NewStyle().Foreground(ui.Dim)
+	labelStyle := lipgloss.NewStyle().Foreground(ui.Light).Bold(true)
+
+	header := fmt.Sprintf("  %s %s  %s",
+		labelStyle.Render(pp.issueID),
+		dimStyle.Render("→ "+pp.runtime),
+		dimStyle.Render("template: "+pp.template),
+	)
+	return header + "\n\n" + pp.editor.View()
+}
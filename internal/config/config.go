@@ -0,0 +1,87 @@
+// Package config locates mg's per-user files, appends to its JSONL logs and
+// rewrites its state files atomically. It has no internal dependencies so
+// every package that keeps state under the user config directory can share
+// it.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Dir is the directory mg keeps its files in under the user config
+// directory.
+const Dir = "mardi-gras"
+
+// Path returns the path of one of mg's files: the value of the env variable
+// if it is set, otherwise name under mardi-gras/ in the user config
+// directory. An empty env has no override. It returns "" when neither is
+// available.
+func Path(env, name string) string {
+	if p := os.Getenv(env); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, Dir, name)
+}
+
+// AppendJSONL appends v to the file at path as one JSON line, creating the
+// file and its directory as needed.
+func AppendJSONL(path string, v any) error {
+	if path == "" {
+		return errors.New("no path")
+	}
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteFileAtomic replaces the file at path with data, creating its
+// directory as needed. It writes a temp file of its own in the same
+// directory and renames it into place, so readers never see a partial file
+// and concurrent writers can't share a temp file.
+func WriteFileAtomic(path string, data []byte) error {
+	if path == "" {
+		return errors.New("no path")
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	t.Setenv("MG_TEST_FILE", "/tmp/override.json")
+	if got := Path("MG_TEST_FILE", "x.json"); got != "/tmp/override.json" {
+		t.Fatalf("env override = %q", got)
+	}
+	home := t.TempDir()
+	t.Setenv("MG_TEST_FILE", "")
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+	got := Path("MG_TEST_FILE", "x.json")
+	if filepath.Base(got) != "x.json" || filepath.Base(filepath.Dir(got)) != Dir {
+		t.Fatalf("default = %q, want .../%s/x.json", got, Dir)
+	}
+}
+
+func TestAppendJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	for _, v := range []map[string]int{{"n": 1}, {"n": 2}} {
+		if err := AppendJSONL(path, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != "{\"n\":1}\n{\"n\":2}\n" {
+		t.Fatalf("log = %q", raw)
+	}
+	if err := AppendJSONL("", 1); err == nil {
+		t.Fatal("empty path should fail")
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state", "history.jsonl")
+	for _, body := range []string{"one\n", "two\n"} {
+		if err := WriteFileAtomic(path, []byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if raw, err := os.ReadFile(path); err != nil || string(raw) != "two\n" {
+		t.Fatalf("file = %q, %v", raw, err)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(dir, "state", "*.tmp")); len(tmps) > 0 {
+		t.Errorf("temp files left behind: %v", tmps)
+	}
+	if err := WriteFileAtomic("", nil); err == nil {
+		t.Fatal("empty path should fail")
+	}
+}
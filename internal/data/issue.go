@@ -121,6 +121,9 @@ type Issue struct {
 	// panel's "COMMENTS (n)" header still counts the fetched comments instead,
 	// since those arrive from a separate `bd comments` call.
 	CommentCount int `json:"comment_count,omitempty"`
+	// EstimatedMinutes is bd's optional effort estimate (`bd update
+	// --estimate`). The convoy planner prefers it over history-based guesses.
+	EstimatedMinutes *int `json:"estimated_minutes,omitempty"`
 }
 
 // EvaluateDependencies is the canonical function for classifying all dependency
@@ -0,0 +1,251 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EffortSource records where an effort figure came from.
+type EffortSource int
+
+const (
+	EffortUnknown  EffortSource = iota // nothing to go on
+	EffortEstimate                     // the issue's own estimated_minutes
+	EffortHistory                      // median lead time of closed issues of the same type
+)
+
+// Effort is an estimated amount of work for one issue.
+type Effort struct {
+	Duration time.Duration
+	Source   EffortSource
+}
+
+// Label renders the effort compactly: "45m", "3h", "2d". Figures derived from
+// history carry a "~" so they never read as someone's estimate; unknown
+// effort renders as "?".
+func (e Effort) Label() string {
+	if e.Source == EffortUnknown {
+		return "?"
+	}
+	var s string
+	switch d := e.Duration; {
+	case d < time.Hour:
+		s = fmt.Sprintf("%dm", max(int(d.Minutes()), 1))
+	case d < 24*time.Hour:
+		s = fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		s = fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+	if e.Source == EffortHistory {
+		return "~" + s
+	}
+	return s
+}
+
+// minTypeSamples is how many closed issues of a type are needed before their
+// median lead time is trusted over the median across all types.
+const minTypeSamples = 3
+
+// effortEstimator estimates effort from an issue's own estimate, falling back
+// to the median lead time of closed issues (by type when there is enough of
+// that type's history, else across all types).
+type effortEstimator struct {
+	byType  map[IssueType]time.Duration
+	overall time.Duration
+}
+
+func newEffortEstimator(issues []Issue) effortEstimator {
+	samples := make(map[IssueType][]time.Duration)
+	var all []time.Duration
+	for _, iss := range issues {
+		if iss.Status != StatusClosed || iss.ClosedAt == nil {
+			continue
+		}
+		start := iss.CreatedAt
+		if iss.StartedAt != nil {
+			start = *iss.StartedAt
+		}
+		d := iss.ClosedAt.Sub(start)
+		if d <= 0 {
+			continue
+		}
+		samples[iss.IssueType] = append(samples[iss.IssueType], d)
+		all = append(all, d)
+	}
+	e := effortEstimator{byType: make(map[IssueType]time.Duration), overall: Percentile(all, 0.5)}
+	for t, ds := range samples {
+		if len(ds) >= minTypeSamples {
+			e.byType[t] = Percentile(ds, 0.5)
+		}
+	}
+	return e
+}
+
+func (e effortEstimator) estimate(iss Issue) Effort {
+	if iss.EstimatedMinutes != nil && *iss.EstimatedMinutes > 0 {
+		return Effort{Duration: time.Duration(*iss.EstimatedMinutes) * time.Minute, Source: EffortEstimate}
+	}
+	if d, ok := e.byType[iss.IssueType]; ok {
+		return Effort{Duration: d, Source: EffortHistory}
+	}
+	if e.overall > 0 {
+		return Effort{Duration: e.overall, Source: EffortHistory}
+	}
+	return Effort{}
+}
+
+// PlanMember is one issue a convoy plan proposes to include.
+type PlanMember struct {
+	Issue  Issue
+	Parade ParadeStatus
+	Effort Effort
+	Via    string // for a blocker pulled in by the closure, the member it blocks; "" for a seed
+	Depth  int    // blocker hops from the nearest seed (0 = seed)
+}
+
+// PlanSeeds resolves a planner source to seed issue IDs. When every token of
+// source names a known issue, those issues are roots and each root's open
+// descendants (dot-hierarchy children and parent-child dependents) join it;
+// otherwise source is a parade filter query and every open match is a seed.
+// rooted reports which reading was used.
+func PlanSeeds(issues []Issue, source string) (seeds []string, rooted bool) {
+	tokens := strings.Fields(source)
+	if len(tokens) == 0 {
+		return nil, false
+	}
+	issueMap := BuildIssueMap(issues)
+	rooted = true
+	for _, t := range tokens {
+		if _, ok := issueMap[t]; !ok {
+			rooted = false
+			break
+		}
+	}
+
+	seen := make(map[string]bool)
+	add := func(iss *Issue) {
+		if iss.Status != StatusClosed && !seen[iss.ID] {
+			seen[iss.ID] = true
+			seeds = append(seeds, iss.ID)
+		}
+	}
+	if !rooted {
+		for _, iss := range FilterIssues(issues, source) {
+			add(&iss)
+		}
+		return seeds, false
+	}
+	for _, root := range tokens {
+		add(issueMap[root])
+		for _, d := range Descendants(issues, root) {
+			add(&d)
+		}
+	}
+	return seeds, true
+}
+
+// Descendants returns the issues under root, either by dotted ID or by a
+// parent-child dependency on it, in list order. Closed descendants are
+// included.
+func Descendants(issues []Issue, root string) []Issue {
+	var out []Issue
+	for idx := range issues {
+		if isDescendant(&issues[idx], root) {
+			out = append(out, issues[idx])
+		}
+	}
+	return out
+}
+
+// isDescendant reports whether iss sits under root, either by dotted ID
+// ("mg-007.2" under "mg-007") or by a parent-child dependency on it.
+func isDescendant(iss *Issue, root string) bool {
+	if strings.HasPrefix(iss.ID, root+".") {
+		return true
+	}
+	for _, dep := range iss.Dependencies {
+		if dep.Type == "parent-child" && dep.DependsOnID == root {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanConvoy expands seedIDs to their transitive blocking closure: every
+// open issue a seed waits on, directly or through other blockers. Closed
+// blockers are already resolved and missing ones cannot be enrolled, so both
+// are left out, as are closed or unknown seeds. Members are ordered so that
+// blockers come before the work they block, then by priority and ID.
+func PlanConvoy(issues []Issue, seedIDs []string, blockingTypes map[string]bool) []PlanMember {
+	issueMap := BuildIssueMap(issues)
+	est := newEffortEstimator(issues)
+
+	var members []PlanMember
+	index := make(map[string]int)
+	enrol := func(iss *Issue, via string, depth int) {
+		if _, ok := index[iss.ID]; ok || iss.Status == StatusClosed {
+			return
+		}
+		index[iss.ID] = len(members)
+		members = append(members, PlanMember{
+			Issue:  *iss,
+			Parade: iss.ParadeGroup(issueMap, blockingTypes),
+			Effort: est.estimate(*iss),
+			Via:    via,
+			Depth:  depth,
+		})
+	}
+	for _, id := range seedIDs {
+		if iss, ok := issueMap[id]; ok {
+			enrol(iss, "", 0)
+		}
+	}
+	// Breadth-first, so a blocker reachable from several seeds keeps the
+	// shortest path as its Via/Depth.
+	for i := 0; i < len(members); i++ {
+		m := members[i]
+		for _, id := range m.Issue.EvaluateDependencies(issueMap, blockingTypes).BlockingIDs {
+			enrol(issueMap[id], m.Issue.ID, m.Depth+1)
+		}
+	}
+
+	// level is the length of the longest chain of in-plan blockers under a
+	// member; sorting by it puts every blocker ahead of its dependents.
+	level := make(map[string]int, len(members))
+	visiting := make(map[string]bool)
+	var levelOf func(id string) int
+	levelOf = func(id string) int {
+		if l, ok := level[id]; ok {
+			return l
+		}
+		if visiting[id] {
+			return 0 // dependency cycle: break it here
+		}
+		visiting[id] = true
+		l := 0
+		for _, b := range issueMap[id].EvaluateDependencies(issueMap, blockingTypes).BlockingIDs {
+			if _, ok := index[b]; ok {
+				l = max(l, levelOf(b)+1)
+			}
+		}
+		visiting[id] = false
+		level[id] = l
+		return l
+	}
+	for _, m := range members {
+		levelOf(m.Issue.ID)
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		a, b := members[i].Issue, members[j].Issue
+		if level[a.ID] != level[b.ID] {
+			return level[a.ID] < level[b.ID]
+		}
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return a.ID < b.ID
+	})
+	return members
+}
@@ -0,0 +1,146 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func planIssue(id string, status Status, blockers ...string) Issue {
+	iss := Issue{ID: id, Title: id, Status: status, Priority: PriorityMedium, IssueType: TypeTask}
+	for _, b := range blockers {
+		iss.Dependencies = append(iss.Dependencies, Dependency{IssueID: id, DependsOnID: b, Type: "blocks"})
+	}
+	return iss
+}
+
+func planIDs(members []PlanMember) []string {
+	ids := make([]string, len(members))
+	for i, m := range members {
+		ids[i] = m.Issue.ID
+	}
+	return ids
+}
+
+func TestPlanConvoyTransitiveClosure(t *testing.T) {
+	issues := []Issue{
+		planIssue("mg-1", StatusOpen, "mg-2"),
+		planIssue("mg-2", StatusOpen, "mg-3", "mg-4"),
+		planIssue("mg-3", StatusInProgress),
+		planIssue("mg-4", StatusClosed), // resolved blocker: not enrolled
+		planIssue("mg-5", StatusOpen),   // unrelated
+		planIssue("mg-6", StatusOpen, "mg-gone"),
+	}
+	members := PlanConvoy(issues, []string{"mg-1", "mg-6"}, DefaultBlockingTypes)
+
+	got := planIDs(members)
+	want := []string{"mg-3", "mg-6", "mg-2", "mg-1"}
+	if len(got) != len(want) {
+		t.Fatalf("members = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("members = %v, want %v (blockers first)", got, want)
+		}
+	}
+
+	byID := make(map[string]PlanMember)
+	for _, m := range members {
+		byID[m.Issue.ID] = m
+	}
+	if m := byID["mg-3"]; m.Via != "mg-2" || m.Depth != 2 || m.Parade != ParadeRolling {
+		t.Errorf("mg-3 = via %q depth %d parade %d, want via mg-2 depth 2 rolling", m.Via, m.Depth, m.Parade)
+	}
+	if m := byID["mg-1"]; m.Via != "" || m.Depth != 0 || m.Parade != ParadeStalled {
+		t.Errorf("seed mg-1 = via %q depth %d parade %d", m.Via, m.Depth, m.Parade)
+	}
+}
+
+func TestPlanConvoyCycleTerminates(t *testing.T) {
+	issues := []Issue{
+		planIssue("mg-1", StatusOpen, "mg-2"),
+		planIssue("mg-2", StatusOpen, "mg-1"),
+	}
+	if got := planIDs(PlanConvoy(issues, []string{"mg-1"}, DefaultBlockingTypes)); len(got) != 2 {
+		t.Errorf("cyclic closure = %v, want both issues once", got)
+	}
+}
+
+func TestPlanSeedsRootIncludesDescendants(t *testing.T) {
+	child := planIssue("mg-9", StatusOpen)
+	child.Dependencies = []Dependency{{IssueID: "mg-9", DependsOnID: "mg-7", Type: "parent-child"}}
+	issues := []Issue{
+		planIssue("mg-7", StatusOpen),
+		planIssue("mg-7.1", StatusOpen),
+		planIssue("mg-7.2", StatusClosed),
+		child,
+		planIssue("mg-8", StatusOpen),
+	}
+	seeds, rooted := PlanSeeds(issues, "mg-7")
+	if !rooted {
+		t.Fatal("known issue id not treated as a root")
+	}
+	if len(seeds) != 3 || seeds[0] != "mg-7" || seeds[1] != "mg-7.1" || seeds[2] != "mg-9" {
+		t.Errorf("seeds = %v, want [mg-7 mg-7.1 mg-9]", seeds)
+	}
+}
+
+func TestPlanSeedsQuery(t *testing.T) {
+	bug := planIssue("mg-1", StatusOpen)
+	bug.IssueType = TypeBug
+	closedBug := planIssue("mg-2", StatusClosed)
+	closedBug.IssueType = TypeBug
+	issues := []Issue{bug, closedBug, planIssue("mg-3", StatusOpen)}
+
+	seeds, rooted := PlanSeeds(issues, "type:bug")
+	if rooted {
+		t.Error("filter query treated as a root")
+	}
+	if len(seeds) != 1 || seeds[0] != "mg-1" {
+		t.Errorf("seeds = %v, want [mg-1] (closed matches dropped)", seeds)
+	}
+	if seeds, _ := PlanSeeds(issues, "  "); seeds != nil {
+		t.Errorf("blank source seeds = %v, want none", seeds)
+	}
+}
+
+func TestPlanConvoyEffort(t *testing.T) {
+	base := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	closedAfter := func(id string, d time.Duration) Issue {
+		iss := planIssue(id, StatusClosed)
+		started := base
+		closed := base.Add(d)
+		iss.StartedAt, iss.ClosedAt = &started, &closed
+		return iss
+	}
+	estimated := planIssue("mg-10", StatusOpen)
+	mins := 90
+	estimated.EstimatedMinutes = &mins
+	bug := planIssue("mg-11", StatusOpen)
+	bug.IssueType = TypeBug
+
+	issues := []Issue{
+		closedAfter("mg-1", 2*time.Hour),
+		closedAfter("mg-2", 4*time.Hour),
+		closedAfter("mg-3", 6*time.Hour),
+		estimated,
+		planIssue("mg-12", StatusOpen),
+		bug,
+	}
+	byID := make(map[string]Effort)
+	for _, m := range PlanConvoy(issues, []string{"mg-10", "mg-11", "mg-12"}, DefaultBlockingTypes) {
+		byID[m.Issue.ID] = m.Effort
+	}
+	if e := byID["mg-10"]; e.Source != EffortEstimate || e.Label() != "1h" {
+		t.Errorf("estimated effort = %+v (%s), want the issue's own 1h", e, e.Label())
+	}
+	if e := byID["mg-12"]; e.Source != EffortHistory || e.Label() != "~4h" {
+		t.Errorf("task effort = %+v (%s), want ~4h task median", e, e.Label())
+	}
+	// No bug history: falls back to the median across all types.
+	if e := byID["mg-11"]; e.Source != EffortHistory || e.Duration != 4*time.Hour {
+		t.Errorf("bug effort = %+v, want overall median", e)
+	}
+	if got := (Effort{}).Label(); got != "?" {
+		t.Errorf("unknown effort label = %q, want ?", got)
+	}
+}
@@ -0,0 +1,20 @@
+package data
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// Percentile returns the nearest-rank p-th percentile of ds (p in (0, 1]):
+// the smallest value with at least p of ds at or below it, so the median of
+// an even count is the lower middle value. It is zero for no values.
+func Percentile(ds []time.Duration, p float64) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(ds)
+	slices.Sort(sorted)
+	i := int(math.Ceil(float64(len(sorted))*p)) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
@@ -0,0 +1,33 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	ds := []time.Duration{4, 1, 3, 2}
+	for _, tt := range []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0.5, 2}, // even count: the lower middle
+		{0.75, 3},
+		{0.85, 4},
+		{1, 4},
+		{0, 1},
+	} {
+		if got := Percentile(ds, tt.p); got != tt.want {
+			t.Errorf("Percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := Percentile([]time.Duration{5, 1, 3}, 0.5); got != 3 {
+		t.Errorf("odd median = %v, want 3", got)
+	}
+	if Percentile(nil, 0.5) != 0 {
+		t.Error("no values should give zero")
+	}
+	if ds[0] != 4 {
+		t.Error("Percentile must not reorder its input")
+	}
+}
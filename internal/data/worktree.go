@@ -0,0 +1,191 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Worktree is a git worktree holding an issue's branch, so a local agent can
+// work on it without touching the main checkout or other agents' trees.
+type Worktree struct {
+	IssueID string
+	Path    string
+	Branch  string
+	Dirty   bool // uncommitted changes in the worktree
+	Ahead   int  // commits on Branch that the base branch lacks
+}
+
+// Status is a short description of the worktree's state, e.g.
+// "dirty · 2 ahead".
+func (w Worktree) Status() string {
+	state := "clean"
+	if w.Dirty {
+		state = "dirty"
+	}
+	if w.Ahead > 0 {
+		return fmt.Sprintf("%s · %d ahead", state, w.Ahead)
+	}
+	return state
+}
+
+// WorktreesEnabled reports whether local agent launches get a worktree per
+// issue (MG_WORKTREES=1, set by the --worktrees flag).
+func WorktreesEnabled() bool {
+	v, _ := strconv.ParseBool(os.Getenv("MG_WORKTREES"))
+	return v
+}
+
+// WorktreePath returns where the worktree for branch lives: under
+// MG_WORKTREE_DIR if set, otherwise in a <project>.worktrees directory next
+// to projectDir, named after the branch.
+func WorktreePath(projectDir, branch string) string {
+	root := os.Getenv("MG_WORKTREE_DIR")
+	if root == "" {
+		abs, err := filepath.Abs(projectDir)
+		if err != nil {
+			abs = projectDir
+		}
+		root = filepath.Join(filepath.Dir(abs), filepath.Base(abs)+".worktrees")
+	}
+	return filepath.Join(root, strings.ReplaceAll(branch, "/", "-"))
+}
+
+// EnsureWorktree returns the issue's worktree, creating it and its
+// BranchName branch if needed. An existing branch is checked out rather
+// than recreated.
+func EnsureWorktree(projectDir string, issue Issue) (Worktree, error) {
+	existing, err := ListWorktrees(projectDir, []Issue{issue})
+	if err != nil {
+		return Worktree{}, err
+	}
+	if wt, ok := existing[issue.ID]; ok {
+		return wt, nil
+	}
+	branch := BranchName(issue)
+	path := WorktreePath(projectDir, branch)
+	if _, err := runGit(projectDir, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+		_, err = runGit(projectDir, "worktree", "add", path, branch)
+		if err != nil {
+			return Worktree{}, err
+		}
+	} else if _, err := runGit(projectDir, "worktree", "add", "-b", branch, path); err != nil {
+		return Worktree{}, err
+	}
+	return Worktree{IssueID: issue.ID, Path: path, Branch: branch}, nil
+}
+
+// ListWorktrees returns the project's worktrees whose branch was named for
+// one of issues, keyed by issue ID, with their dirty and ahead status
+// measured against the main checkout's current branch.
+func ListWorktrees(projectDir string, issues []Issue) (map[string]Worktree, error) {
+	out, err := runGit(projectDir, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(issues))
+	for _, iss := range issues {
+		ids[iss.ID] = true
+	}
+	base, _ := runGit(projectDir, "rev-parse", "--abbrev-ref", "HEAD")
+	base = strings.TrimSpace(base)
+
+	result := make(map[string]Worktree)
+	for i, w := range parseWorktreeList(out) {
+		if i == 0 {
+			continue // the main checkout
+		}
+		id := branchIssueID(w.Branch, ids)
+		if id == "" {
+			continue
+		}
+		w.IssueID = id
+		if status, err := runGit(w.Path, "status", "--porcelain"); err == nil {
+			w.Dirty = strings.TrimSpace(status) != ""
+		}
+		if base != "" && base != "HEAD" && base != w.Branch {
+			if n, err := runGit(w.Path, "rev-list", "--count", base+"..HEAD"); err == nil {
+				w.Ahead, _ = strconv.Atoi(strings.TrimSpace(n))
+			}
+		}
+		result[id] = w
+	}
+	return result, nil
+}
+
+// RemoveWorktree removes the worktree, then deletes its branch if the branch
+// is merged. It fails, keeping everything, if the worktree has uncommitted
+// changes; an unmerged branch is kept and reported by branchDeleted.
+func RemoveWorktree(projectDir string, wt Worktree) (branchDeleted bool, err error) {
+	if _, err := runGit(projectDir, "worktree", "remove", wt.Path); err != nil {
+		return false, err
+	}
+	if wt.Branch == "" {
+		return false, nil
+	}
+	// -d refuses branches that aren't merged.
+	_, err = runGit(projectDir, "branch", "-d", wt.Branch)
+	return err == nil, nil
+}
+
+// parseWorktreeList parses `git worktree list --porcelain`, main checkout
+// first. Detached worktrees have no Branch.
+func parseWorktreeList(out string) []Worktree {
+	var list []Worktree
+	for _, block := range strings.Split(strings.TrimSpace(out), "\n\n") {
+		var w Worktree
+		for _, line := range strings.Split(block, "\n") {
+			switch {
+			case strings.HasPrefix(line, "worktree "):
+				w.Path = strings.TrimPrefix(line, "worktree ")
+			case strings.HasPrefix(line, "branch "):
+				w.Branch = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+			}
+		}
+		if w.Path != "" {
+			list = append(list, w)
+		}
+	}
+	return list
+}
+
+// branchIssueID finds the issue a BranchName-style branch ("fix/mg-7-title")
+// was named for, preferring the longest matching ID so "mg-7.1" wins over
+// "mg-7".
+func branchIssueID(branch string, ids map[string]bool) string {
+	_, rest, ok := strings.Cut(branch, "/")
+	if !ok {
+		return ""
+	}
+	best := ""
+	for id := range ids {
+		if (rest == id || strings.HasPrefix(rest, id+"-")) && len(id) > len(best) {
+			best = id
+		}
+	}
+	return best
+}
+
+// runGit runs git in dir and returns its stdout; a failure carries git's
+// own message.
+func runGit(dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutMedium)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return "", fmt.Errorf("git %s: %w", args[0], err)
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return string(out), nil
+}
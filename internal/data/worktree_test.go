@@ -0,0 +1,118 @@
+package data
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// gitRepo makes a repository with one commit on main.
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		if _, err := runGit(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestWorktreeLifecycle(t *testing.T) {
+	repo := gitRepo(t)
+	t.Setenv("MG_WORKTREE_DIR", t.TempDir())
+	issue := Issue{ID: "mg-7", Title: "Fix the parser", IssueType: TypeBug}
+	issues := []Issue{issue, {ID: "mg-7.1"}}
+
+	wt, err := EnsureWorktree(repo, issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wt.Branch != "fix/mg-7-fix-the-parser" || filepath.Base(wt.Path) != "fix-mg-7-fix-the-parser" {
+		t.Fatalf("worktree = %+v", wt)
+	}
+	if again, err := EnsureWorktree(repo, issue); err != nil || filepath.Base(again.Path) != filepath.Base(wt.Path) {
+		t.Fatalf("second ensure = %+v, %v; want the same worktree", again, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(wt.Path, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ListWorktrees(repo, issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := got["mg-7"]; !w.Dirty || w.Ahead != 0 || len(got) != 1 {
+		t.Fatalf("dirty list = %+v", got)
+	}
+	if _, err := RemoveWorktree(repo, got["mg-7"]); err == nil {
+		t.Fatal("removing a dirty worktree should fail")
+	}
+
+	for _, args := range [][]string{{"add", "a.txt"}, {"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "-m", "a"}} {
+		if _, err := runGit(wt.Path, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, _ = ListWorktrees(repo, issues)
+	if w := got["mg-7"]; w.Dirty || w.Ahead != 1 || w.Status() != "clean · 1 ahead" {
+		t.Fatalf("committed list = %+v", w)
+	}
+
+	// Unmerged: the worktree goes, the branch stays.
+	deleted, err := RemoveWorktree(repo, got["mg-7"])
+	if err != nil || deleted {
+		t.Fatalf("remove unmerged = %v, %v", deleted, err)
+	}
+	if _, err := os.Stat(wt.Path); !os.IsNotExist(err) {
+		t.Fatalf("worktree dir still present: %v", err)
+	}
+
+	// Recreating checks out the existing branch; once merged it is deleted.
+	wt, err = EnsureWorktree(repo, issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runGit(repo, "merge", "-q", "--ff-only", wt.Branch); err != nil {
+		t.Fatal(err)
+	}
+	if deleted, err := RemoveWorktree(repo, wt); err != nil || !deleted {
+		t.Fatalf("remove merged = %v, %v", deleted, err)
+	}
+	if got, _ := ListWorktrees(repo, issues); len(got) != 0 {
+		t.Fatalf("after cleanup = %+v", got)
+	}
+}
+
+func TestParseWorktreeList(t *testing.T) {
+	out := "worktree /src/mg\nHEAD abc\nbranch refs/heads/main\n\n" +
+		"worktree /src/mg.worktrees/feat-mg-1-x\nHEAD def\nbranch refs/heads/feat/mg-1-x\n\n" +
+		"worktree /tmp/detached\nHEAD 123\ndetached\n"
+	got := parseWorktreeList(out)
+	if len(got) != 3 || got[0].Branch != "main" || got[1].Branch != "feat/mg-1-x" || got[1].Path != "/src/mg.worktrees/feat-mg-1-x" || got[2].Branch != "" {
+		t.Fatalf("parseWorktreeList = %+v", got)
+	}
+}
+
+func TestBranchIssueIDPrefersLongestID(t *testing.T) {
+	ids := map[string]bool{"mg-7": true, "mg-7.1": true, "mg-70": true}
+	tests := map[string]string{
+		"fix/mg-7-title":   "mg-7",
+		"fix/mg-7.1-title": "mg-7.1",
+		"feat/mg-70":       "mg-70",
+		"feat/mg-8-title":  "",
+		"main":             "",
+	}
+	for branch, want := range tests {
+		if got := branchIssueID(branch, ids); got != want {
+			t.Errorf("branchIssueID(%q) = %q, want %q", branch, got, want)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package gastown
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/config"
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+// Alert rule targets.
+const (
+	RuleTargetAgent = "agent"
+	RuleTargetIssue = "issue"
+)
+
+// RuleDuration is a duration in an alert rules file: a Go duration ("10m",
+// "1h30m") or a whole number of days ("3d").
+type RuleDuration time.Duration
+
+// UnmarshalJSON parses a RuleDuration from a JSON string.
+func (d *RuleDuration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := parseRuleDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = RuleDuration(v)
+	return nil
+}
+
+func parseRuleDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return v, nil
+}
+
+// String formats the duration compactly: whole days as "3d", otherwise as
+// hours and minutes.
+func (d RuleDuration) String() string {
+	v := time.Duration(d)
+	switch {
+	case v >= 24*time.Hour && v%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", v/(24*time.Hour))
+	case v%time.Hour == 0:
+		return fmt.Sprintf("%dh", v/time.Hour)
+	case v%time.Minute == 0:
+		return strings.TrimSuffix(v.String(), "0s")
+	}
+	return v.String()
+}
+
+// AlertRule is a user-defined problem, evaluated against every agent or
+// issue on each poll. All the conditions set must hold; unset ones match
+// anything. An agent rule must hold continuously for For before it raises;
+// an issue rule's Stale matches issues not updated for that long.
+type AlertRule struct {
+	Name     string       `json:"name"`
+	Target   string       `json:"target"`             // RuleTargetAgent or RuleTargetIssue
+	Severity string       `json:"severity,omitempty"` // "warn" (default) or "error"
+	Message  string       `json:"message,omitempty"`
+	For      RuleDuration `json:"for,omitempty"`
+
+	// Agent conditions.
+	Role    string `json:"role,omitempty"`
+	Rig     string `json:"rig,omitempty"`
+	State   string `json:"state,omitempty"`
+	HasWork *bool  `json:"has_work,omitempty"`
+	Running *bool  `json:"running,omitempty"`
+
+	// Issue conditions.
+	Status   string       `json:"status,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Label    string       `json:"label,omitempty"`
+	Assignee string       `json:"assignee,omitempty"`
+	Priority *int         `json:"max_priority,omitempty"` // P0..Pn, matches this priority or more urgent
+	Stale    RuleDuration `json:"stale,omitempty"`
+}
+
+// AlertRulesPath returns the alert rules path: MG_ALERT_RULES if set,
+// otherwise mardi-gras/alerts.json under the user config directory.
+func AlertRulesPath() string {
+	return config.Path("MG_ALERT_RULES", "alerts.json")
+}
+
+// LoadAlertRules reads and checks an alert rules file. A missing file is not
+// an error: it returns nil, nil and no rules run.
+func LoadAlertRules(path string) ([]AlertRule, error) {
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("alert rules: %w", err)
+	}
+	var file struct {
+		Rules []AlertRule `json:"rules"`
+	}
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("alert rules %s: %w", path, err)
+	}
+	for i, r := range file.Rules {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("alert rules %s: rule %d: %w", path, i+1, err)
+		}
+	}
+	return file.Rules, nil
+}
+
+func (r AlertRule) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	if r.Severity != "" && r.Severity != "warn" && r.Severity != "error" {
+		return fmt.Errorf("%s: severity %q, want warn or error", r.Name, r.Severity)
+	}
+	agentSet := r.Role != "" || r.Rig != "" || r.State != "" || r.HasWork != nil || r.Running != nil
+	issueSet := r.Status != "" || r.Type != "" || r.Label != "" || r.Assignee != "" || r.Priority != nil || r.Stale != 0
+	switch r.Target {
+	case RuleTargetAgent:
+		if issueSet {
+			return fmt.Errorf("%s: issue conditions on an agent rule", r.Name)
+		}
+	case RuleTargetIssue:
+		if agentSet {
+			return fmt.Errorf("%s: agent conditions on an issue rule", r.Name)
+		}
+	default:
+		return fmt.Errorf("%s: target %q, want agent or issue", r.Name, r.Target)
+	}
+	return nil
+}
+
+// EvaluateAlertRules returns a "rule" problem for every agent or issue a rule
+// matches at now. Agent rules carry their For as the problem's After, so the
+// problem tracker raises them only once they have held that long.
+func EvaluateAlertRules(rules []AlertRule, status *TownStatus, issues []data.Issue, now time.Time) []Problem {
+	var out []Problem
+	for _, r := range rules {
+		severity := r.Severity
+		if severity == "" {
+			severity = "warn"
+		}
+		switch r.Target {
+		case RuleTargetAgent:
+			if status == nil {
+				continue
+			}
+			for _, a := range status.Agents {
+				if !r.matchAgent(a) {
+					continue
+				}
+				out = append(out, Problem{
+					Type:     "rule",
+					Subject:  r.Name,
+					Category: r.Name,
+					Agent:    a,
+					Detail:   r.detail(""),
+					Severity: severity,
+					After:    time.Duration(r.For),
+				})
+			}
+		case RuleTargetIssue:
+			for _, iss := range issues {
+				if !r.matchIssue(iss, now) {
+					continue
+				}
+				out = append(out, Problem{
+					Type:     "rule",
+					Subject:  r.Name,
+					Category: r.Name,
+					IssueID:  iss.ID,
+					Detail:   r.detail(iss.ID + " " + iss.Title),
+					Severity: severity,
+					After:    time.Duration(r.For),
+				})
+			}
+		}
+	}
+	return out
+}
+
+func (r AlertRule) matchAgent(a AgentRuntime) bool {
+	switch {
+	case r.Role != "" && a.Role != r.Role,
+		r.Rig != "" && a.Rig != r.Rig,
+		r.State != "" && a.State != r.State,
+		r.HasWork != nil && a.HasWork != *r.HasWork,
+		r.Running != nil && a.Running != *r.Running:
+		return false
+	}
+	return true
+}
+
+func (r AlertRule) matchIssue(iss data.Issue, now time.Time) bool {
+	switch {
+	case r.Status != "" && string(iss.Status) != r.Status,
+		r.Type != "" && string(iss.IssueType) != r.Type,
+		r.Label != "" && !slices.Contains(iss.Labels, r.Label),
+		r.Assignee != "" && iss.Assignee != r.Assignee,
+		r.Priority != nil && int(iss.Priority) > *r.Priority,
+		r.Stale != 0 && now.Sub(iss.UpdatedAt) < time.Duration(r.Stale):
+		return false
+	}
+	return true
+}
+
+// detail describes a match: the rule's message, or its conditions spelled
+// out, after the subject when there is one.
+func (r AlertRule) detail(subject string) string {
+	text := r.Message
+	if text == "" {
+		var conds []string
+		add := func(label, v string) {
+			if v != "" {
+				conds = append(conds, label+v)
+			}
+		}
+		add("role ", r.Role)
+		add("rig ", r.Rig)
+		add("", r.State)
+		if r.HasWork != nil {
+			if *r.HasWork {
+				conds = append(conds, "has work")
+			} else {
+				conds = append(conds, "no work")
+			}
+		}
+		if r.Running != nil {
+			if *r.Running {
+				conds = append(conds, "running")
+			} else {
+				conds = append(conds, "not running")
+			}
+		}
+		add("", r.Status)
+		add("type ", r.Type)
+		add("label ", r.Label)
+		add("assigned to ", r.Assignee)
+		if r.Priority != nil {
+			conds = append(conds, fmt.Sprintf("P%d or higher", *r.Priority))
+		}
+		if r.Stale != 0 {
+			conds = append(conds, "no update for "+r.Stale.String())
+		}
+		if r.For != 0 {
+			conds = append(conds, "for "+r.For.String())
+		}
+		text = "Rule " + r.Name
+		if len(conds) > 0 {
+			text += ": " + strings.Join(conds, ", ")
+		}
+	}
+	if subject != "" {
+		return subject + " — " + text
+	}
+	return text
+}
@@ -0,0 +1,85 @@
+package gastown
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+func TestLoadAlertRules(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "alerts.json")
+	raw := `{"rules": [
+		{"name": "idle-with-work", "target": "agent", "state": "idle", "has_work": true, "for": "10m"},
+		{"name": "stale-wip", "target": "issue", "status": "in_progress", "stale": "3d", "severity": "error"}
+	]}`
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadAlertRules(path)
+	if err != nil || len(rules) != 2 {
+		t.Fatalf("LoadAlertRules = %+v, %v", rules, err)
+	}
+	if time.Duration(rules[0].For) != 10*time.Minute || time.Duration(rules[1].Stale) != 72*time.Hour {
+		t.Fatalf("durations = %v %v", rules[0].For, rules[1].Stale)
+	}
+
+	for _, bad := range []string{
+		`{"rules": [{"target": "agent"}]}`,
+		`{"rules": [{"name": "x", "target": "rig"}]}`,
+		`{"rules": [{"name": "x", "target": "agent", "status": "open"}]}`,
+		`{"rules": [{"name": "x", "target": "issue", "stale": "soon"}]}`,
+		`{"rules": [{"name": "x", "target": "issue", "severity": "fatal"}]}`,
+	} {
+		if err := os.WriteFile(path, []byte(bad), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadAlertRules(path); err == nil {
+			t.Errorf("LoadAlertRules(%s) should fail", bad)
+		}
+	}
+
+	if rules, err := LoadAlertRules(filepath.Join(dir, "none.json")); rules != nil || err != nil {
+		t.Fatalf("missing file = %v, %v", rules, err)
+	}
+}
+
+func TestEvaluateAlertRules(t *testing.T) {
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	yes := true
+	rules := []AlertRule{
+		{Name: "idle-with-work", Target: RuleTargetAgent, State: "idle", HasWork: &yes, For: RuleDuration(10 * time.Minute)},
+		{Name: "stale-wip", Target: RuleTargetIssue, Status: "in_progress", Stale: RuleDuration(72 * time.Hour), Severity: "error"},
+	}
+	status := &TownStatus{Agents: []AgentRuntime{
+		{Name: "Toast", State: "idle", HasWork: true},
+		{Name: "Muffin", State: "working", HasWork: true},
+	}}
+	issues := []data.Issue{
+		{ID: "mg-1", Title: "Old", Status: data.StatusInProgress, UpdatedAt: now.AddDate(0, 0, -4)},
+		{ID: "mg-2", Title: "Fresh", Status: data.StatusInProgress, UpdatedAt: now.Add(-time.Hour)},
+		{ID: "mg-3", Title: "Parked", Status: data.StatusOpen, UpdatedAt: now.AddDate(0, 0, -9)},
+	}
+
+	got := EvaluateAlertRules(rules, status, issues, now)
+	if len(got) != 2 {
+		t.Fatalf("got %d problems, want 2: %+v", len(got), got)
+	}
+	if got[0].Agent.Name != "Toast" || got[0].After != 10*time.Minute || got[0].Severity != "warn" {
+		t.Errorf("agent problem = %+v", got[0])
+	}
+	if got[0].Detail != "Rule idle-with-work: idle, has work, for 10m" {
+		t.Errorf("agent detail = %q", got[0].Detail)
+	}
+	if got[1].IssueID != "mg-1" || got[1].Severity != "error" || !strings.HasPrefix(got[1].Detail, "mg-1 Old — ") {
+		t.Errorf("issue problem = %+v", got[1])
+	}
+	if got[0].Key() == got[1].Key() {
+		t.Error("rule problems need distinct keys")
+	}
+}
@@ -0,0 +1,261 @@
+package gastown
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/config"
+)
+
+// BudgetLimit caps spend over a day and/or a week. Zero leaves that period
+// unlimited.
+type BudgetLimit struct {
+	Daily  float64 `json:"daily,omitempty"`
+	Weekly float64 `json:"weekly,omitempty"`
+}
+
+// BudgetConfig is the cost budget configuration, read from BudgetsPath:
+//
+//	{
+//	  "daily": 50, "weekly": 250,
+//	  "rigs":  {"gastown": {"daily": 20}},
+//	  "roles": {"polecat": {"daily": 30, "weekly": 150}},
+//	  "confirm_sling": true
+//	}
+//
+// The top-level limits apply to the whole town; rigs and roles are matched
+// against the by_rig and by_role breakdowns of `gt costs`.
+type BudgetConfig struct {
+	BudgetLimit
+	Rigs  map[string]BudgetLimit `json:"rigs,omitempty"`
+	Roles map[string]BudgetLimit `json:"roles,omitempty"`
+
+	// ConfirmSling asks for confirmation before slinging new work while any
+	// budget is already exceeded.
+	ConfirmSling bool `json:"confirm_sling,omitempty"`
+}
+
+// BudgetsPath returns the budget config path: MG_BUDGETS if set, otherwise
+// mardi-gras/budgets.json under the user config directory.
+func BudgetsPath() string {
+	return config.Path("MG_BUDGETS", "budgets.json")
+}
+
+// LoadBudgets reads a budget config. A missing file is not an error: it
+// returns nil, nil and budgets stay off.
+func LoadBudgets(path string) (*BudgetConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("budgets: %w", err)
+	}
+	var cfg BudgetConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("budgets %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Budget scopes.
+const (
+	BudgetTown = "town"
+	BudgetRig  = "rig"
+	BudgetRole = "role"
+)
+
+// Budget periods.
+const (
+	BudgetDaily  = "daily"
+	BudgetWeekly = "weekly"
+)
+
+// BudgetStatus is one configured limit measured against current spend.
+type BudgetStatus struct {
+	Scope  string // BudgetTown, BudgetRig or BudgetRole
+	Name   string // rig or role name; empty for the town
+	Period string // BudgetDaily or BudgetWeekly
+	Limit  float64
+
+	// Spent is the spend so far in the period; Projected extends today's
+	// burn rate to the end of the period.
+	Spent     float64
+	Projected float64
+}
+
+// Label names the budget ("town daily", "rig gastown weekly").
+func (s BudgetStatus) Label() string {
+	if s.Name == "" {
+		return s.Scope + " " + s.Period
+	}
+	return s.Scope + " " + s.Name + " " + s.Period
+}
+
+// Exceeded reports whether spend has already reached the limit.
+func (s BudgetStatus) Exceeded() bool {
+	return s.Limit > 0 && s.Spent >= s.Limit
+}
+
+// Overrun reports whether the current burn rate projects past the limit.
+func (s BudgetStatus) Overrun() bool {
+	return s.Limit > 0 && s.Projected > s.Limit
+}
+
+// Percent is spend as a percentage of the limit.
+func (s BudgetStatus) Percent() float64 {
+	if s.Limit <= 0 {
+		return 0
+	}
+	return s.Spent * 100 / s.Limit
+}
+
+// EvaluateBudgets measures every configured limit against costs, the live
+// `gt costs` reading, and history, the recorded samples. Spend per day comes
+// from CostDays over both, so a rolling total such as "last 24h" counts only
+// its share of today, and the week sums each day since Sunday once.
+//
+// A day-to-date reading projects today's hourly burn to the end of the day.
+// A rolling one already is a rate: its window total, spread over the window,
+// is charged for the hours left today. Either daily rate then fills the rest
+// of the week.
+func EvaluateBudgets(cfg *BudgetConfig, costs *CostsOutput, history []CostSample, now time.Time) []BudgetStatus {
+	if cfg == nil || costs == nil {
+		return nil
+	}
+
+	weekday := int(now.Weekday())
+	days := CostDays(append(slices.Clone(history), CostSample{At: now, CostsOutput: *costs}), weekday+1, now)
+	today := days[weekday]
+	earlier := SumCostDays(days[:weekday])
+	daysLeft := 6 - weekday
+
+	// A day-to-date burn rate comes from the hours elapsed today, floored at
+	// one hour so the first minutes after midnight do not project absurd
+	// totals.
+	elapsed := max(now.Sub(startOfDay(now)).Hours(), 1)
+	hoursLeft := startOfDay(now).AddDate(0, 0, 1).Sub(now).Hours()
+	window := costs.Window().Hours()
+	project := func(spent, live float64) (endOfDay, perDay float64) {
+		if window > 0 {
+			rate := live / window
+			return spent + rate*hoursLeft, rate * 24
+		}
+		perDay = spent * 24 / elapsed
+		return perDay, perDay
+	}
+
+	var out []BudgetStatus
+	add := func(scope, name string, limit BudgetLimit, spent, before, live float64) {
+		projectedToday, perDay := project(spent, live)
+		if limit.Daily > 0 {
+			out = append(out, BudgetStatus{Scope: scope, Name: name, Period: BudgetDaily,
+				Limit: limit.Daily, Spent: spent, Projected: projectedToday})
+		}
+		if limit.Weekly > 0 {
+			out = append(out, BudgetStatus{Scope: scope, Name: name, Period: BudgetWeekly,
+				Limit: limit.Weekly, Spent: before + spent,
+				Projected: before + projectedToday + perDay*float64(daysLeft)})
+		}
+	}
+
+	var before float64
+	if earlier != nil {
+		before = earlier.Total.Cost
+	}
+	add(BudgetTown, "", cfg.BudgetLimit, today.Cost, before, costs.Total.Cost)
+
+	for _, name := range sortedKeys(cfg.Rigs) {
+		add(BudgetRig, name, cfg.Rigs[name], today.ByRig[name], rigCost(earlier, name), rigCost(costs, name))
+	}
+	for _, name := range sortedKeys(cfg.Roles) {
+		add(BudgetRole, name, cfg.Roles[name], today.ByRole[name], roleCost(earlier, name), roleCost(costs, name))
+	}
+	return out
+}
+
+// ExceededBudgets returns the budgets whose spend has reached the limit.
+func ExceededBudgets(statuses []BudgetStatus) []BudgetStatus {
+	var out []BudgetStatus
+	for _, s := range statuses {
+		if s.Exceeded() {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// BudgetProblems reports exceeded and projected-overrun budgets for the
+// Problems view: an error once a limit is hit, a warning while the burn rate
+// only projects past it.
+func BudgetProblems(statuses []BudgetStatus) []Problem {
+	var out []Problem
+	for _, s := range statuses {
+		switch {
+		case s.Exceeded():
+			out = append(out, Problem{
+				Type:     "budget",
+				Subject:  s.Label(),
+				Detail:   fmt.Sprintf("%s budget exceeded: $%.2f of $%.2f", s.Label(), s.Spent, s.Limit),
+				Severity: "error",
+				RigName:  budgetRig(s),
+			})
+		case s.Overrun():
+			out = append(out, Problem{
+				Type:     "budget",
+				Subject:  s.Label(),
+				Detail:   fmt.Sprintf("%s budget projected to overrun: $%.2f of $%.2f at current burn", s.Label(), s.Projected, s.Limit),
+				Severity: "warn",
+				RigName:  budgetRig(s),
+			})
+		}
+	}
+	return out
+}
+
+func budgetRig(s BudgetStatus) string {
+	if s.Scope == BudgetRig {
+		return s.Name
+	}
+	return ""
+}
+
+func rigCost(c *CostsOutput, rig string) float64 {
+	if c == nil {
+		return 0
+	}
+	for _, rc := range c.ByRig {
+		if rc.Rig == rig {
+			return rc.Cost
+		}
+	}
+	return 0
+}
+
+func roleCost(c *CostsOutput, role string) float64 {
+	if c == nil {
+		return 0
+	}
+	for _, rc := range c.ByRole {
+		if rc.Role == role {
+			return rc.Cost
+		}
+	}
+	return 0
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
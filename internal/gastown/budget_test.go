@@ -0,0 +1,153 @@
+package gastown
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadBudgets(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := LoadBudgets(filepath.Join(dir, "missing.json"))
+	if err != nil || cfg != nil {
+		t.Fatalf("missing file = %v, %v; want nil, nil", cfg, err)
+	}
+
+	path := filepath.Join(dir, "budgets.json")
+	raw := `{"daily": 50, "weekly": 250, "rigs": {"gastown": {"daily": 20}}, "roles": {"polecat": {"weekly": 150}}, "confirm_sling": true}`
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadBudgets(path)
+	if err != nil {
+		t.Fatalf("LoadBudgets: %v", err)
+	}
+	if cfg.Daily != 50 || cfg.Weekly != 250 || !cfg.ConfirmSling {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+	if cfg.Rigs["gastown"].Daily != 20 || cfg.Roles["polecat"].Weekly != 150 {
+		t.Fatalf("rigs/roles = %+v / %+v", cfg.Rigs, cfg.Roles)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBudgets(path); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
+
+func TestEvaluateBudgets(t *testing.T) {
+	// Wednesday noon: half the day gone, four days left in the week after today.
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	cfg := &BudgetConfig{
+		BudgetLimit: BudgetLimit{Daily: 50, Weekly: 200},
+		Rigs:        map[string]BudgetLimit{"gastown": {Daily: 10}},
+		Roles:       map[string]BudgetLimit{"polecat": {Daily: 100}},
+	}
+	costs := &CostsOutput{
+		ByRig:  []RigCost{{Rig: "gastown", Cost: 12}},
+		ByRole: []RoleCost{{Role: "polecat", Cost: 20}},
+	}
+	costs.Total.Cost = 30
+	monday := CostSample{At: now.AddDate(0, 0, -2)}
+	monday.Total.Cost = 40
+
+	got := EvaluateBudgets(cfg, costs, []CostSample{monday}, now)
+	if len(got) != 4 {
+		t.Fatalf("got %d statuses, want 4: %+v", len(got), got)
+	}
+
+	daily := got[0]
+	if daily.Label() != "town daily" || daily.Spent != 30 || daily.Projected != 60 {
+		t.Fatalf("town daily = %+v", daily)
+	}
+	if daily.Exceeded() || !daily.Overrun() {
+		t.Fatalf("town daily exceeded=%v overrun=%v", daily.Exceeded(), daily.Overrun())
+	}
+
+	weekly := got[1]
+	// 40 earlier + 60 projected today + 60 × 3 remaining days.
+	if weekly.Spent != 70 || weekly.Projected != 280 {
+		t.Fatalf("town weekly = %+v", weekly)
+	}
+
+	rig := got[2]
+	if rig.Label() != "rig gastown daily" || !rig.Exceeded() {
+		t.Fatalf("rig = %+v", rig)
+	}
+	role := got[3]
+	if role.Overrun() || role.Percent() != 20 {
+		t.Fatalf("role = %+v", role)
+	}
+
+	if ex := ExceededBudgets(got); len(ex) != 1 || ex[0].Name != "gastown" {
+		t.Fatalf("ExceededBudgets = %+v", ex)
+	}
+}
+
+func TestEvaluateBudgetsRollingPeriod(t *testing.T) {
+	// Wednesday 06:00, with gt reporting a rolling 24h total of $48 ($2/h)
+	// both now and at the last poll, Tuesday 22:00.
+	now := time.Date(2026, 3, 4, 6, 0, 0, 0, time.UTC)
+	rolling := func(at time.Time) CostSample {
+		s := CostSample{At: at, CostsOutput: CostsOutput{Period: "last 24h", ByRig: []RigCost{{Rig: "gastown", Cost: 24}}}}
+		s.Total.Cost = 48
+		return s
+	}
+	live := rolling(now).CostsOutput
+	cfg := &BudgetConfig{
+		BudgetLimit: BudgetLimit{Daily: 50, Weekly: 300},
+		Rigs:        map[string]BudgetLimit{"gastown": {Daily: 25}},
+	}
+
+	got := EvaluateBudgets(cfg, &live, []CostSample{rolling(now.Add(-8 * time.Hour))}, now)
+	if len(got) != 3 {
+		t.Fatalf("got %d statuses, want 3: %+v", len(got), got)
+	}
+	// Today is the six hours since midnight at $2/h, projected at that rate
+	// for the 18 left: not the whole window, and not scaled by 24/6.
+	daily := got[0]
+	if daily.Spent != 12 || daily.Projected != 48 || daily.Exceeded() || daily.Overrun() {
+		t.Fatalf("town daily = %+v", daily)
+	}
+	// Monday 4 + Tuesday 48 before today; $48 a day for the three days left.
+	weekly := got[1]
+	if weekly.Spent != 64 || weekly.Projected != 244 {
+		t.Fatalf("town weekly = %+v", weekly)
+	}
+	rig := got[2]
+	if rig.Spent != 6 || rig.Projected != 24 || rig.Overrun() {
+		t.Fatalf("rig daily = %+v", rig)
+	}
+}
+
+func TestEvaluateBudgetsNil(t *testing.T) {
+	if got := EvaluateBudgets(nil, &CostsOutput{}, nil, time.Now()); got != nil {
+		t.Fatalf("nil config = %+v", got)
+	}
+	if got := EvaluateBudgets(&BudgetConfig{}, nil, nil, time.Now()); got != nil {
+		t.Fatalf("nil costs = %+v", got)
+	}
+}
+
+func TestBudgetProblems(t *testing.T) {
+	statuses := []BudgetStatus{
+		{Scope: BudgetRig, Name: "gastown", Period: BudgetDaily, Limit: 10, Spent: 12, Projected: 24},
+		{Scope: BudgetTown, Period: BudgetWeekly, Limit: 200, Spent: 70, Projected: 280},
+		{Scope: BudgetRole, Name: "polecat", Period: BudgetDaily, Limit: 100, Spent: 20, Projected: 40},
+	}
+	probs := BudgetProblems(statuses)
+	if len(probs) != 2 {
+		t.Fatalf("got %d problems, want 2: %+v", len(probs), probs)
+	}
+	if probs[0].Severity != "error" || probs[0].RigName != "gastown" || !strings.Contains(probs[0].Detail, "exceeded") {
+		t.Fatalf("exceeded problem = %+v", probs[0])
+	}
+	if probs[1].Severity != "warn" || probs[1].Type != "budget" || !strings.Contains(probs[1].Detail, "overrun") {
+		t.Fatalf("overrun problem = %+v", probs[1])
+	}
+}
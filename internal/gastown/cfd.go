@@ -0,0 +1,132 @@
+package gastown
+
+import (
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+// MaxCFDDays is the longest history ComputeCFD reconstructs; views slice a
+// shorter window from its tail.
+const MaxCFDDays = 90
+
+// CFDSections lists the parade sections in cumulative flow stacking order,
+// bottom band first: finished work at the base, then Stalled so its build-up
+// sits on a stable floor, then Rolling, with Lined Up on top.
+var CFDSections = []data.ParadeStatus{
+	data.ParadePastTheStand,
+	data.ParadeStalled,
+	data.ParadeRolling,
+	data.ParadeLinedUp,
+}
+
+// CFDDay is the parade section headcount at the end of one day.
+type CFDDay struct {
+	Day    time.Time // midnight, in now's location
+	Counts [4]int    // indexed by data.ParadeStatus
+}
+
+// Total is the number of issues that existed by the end of the day.
+func (d CFDDay) Total() int {
+	return d.Counts[0] + d.Counts[1] + d.Counts[2] + d.Counts[3]
+}
+
+// CFD is a cumulative flow history: one CFDDay per calendar day, oldest first,
+// ending with today.
+type CFD struct {
+	Days []CFDDay
+}
+
+// Last returns the final n days (all of them when n <= 0 or exceeds the
+// history).
+func (c CFD) Last(n int) []CFDDay {
+	if n <= 0 || n >= len(c.Days) {
+		return c.Days
+	}
+	return c.Days[len(c.Days)-n:]
+}
+
+// ComputeCFD reconstructs how many issues sat in each parade section at the
+// end of each of the last days days from issue timestamps: an issue is Past
+// the Stand from ClosedAt, Rolling from StartedAt, and Stalled on any day one
+// of its blockers existed but had not yet closed. Dependency edges carry no
+// timestamps, so today's edges are assumed to have held throughout. Today's
+// column is the live parade grouping, so the chart always ends where the
+// parade is.
+func ComputeCFD(issues []data.Issue, blockingTypes map[string]bool, days int, now time.Time) CFD {
+	if days <= 0 {
+		days = DefaultForecastWindow
+	}
+	days = min(days, MaxCFDDays)
+
+	issueMap := data.BuildIssueMap(issues)
+	today := startOfDay(now)
+	out := CFD{Days: make([]CFDDay, days)}
+	for i := range out.Days {
+		out.Days[i].Day = today.AddDate(0, 0, i-days+1)
+	}
+
+	for idx := range issues {
+		iss := &issues[idx]
+		for i := range out.Days {
+			if i == days-1 {
+				out.Days[i].Counts[iss.ParadeGroup(issueMap, blockingTypes)]++
+				continue
+			}
+			end := out.Days[i].Day.AddDate(0, 0, 1)
+			if s, ok := sectionAt(iss, issueMap, blockingTypes, end); ok {
+				out.Days[i].Counts[s]++
+			}
+		}
+	}
+	return out
+}
+
+// sectionAt places an issue in its parade section as of the instant end, or
+// reports false when the issue did not exist yet.
+func sectionAt(iss *data.Issue, issueMap map[string]*data.Issue, blockingTypes map[string]bool, end time.Time) (data.ParadeStatus, bool) {
+	if iss.CreatedAt.IsZero() || !iss.CreatedAt.Before(end) {
+		return 0, false
+	}
+	if closedBy(iss, end) {
+		return data.ParadePastTheStand, true
+	}
+	if blockedAt(iss, issueMap, blockingTypes, end) {
+		return data.ParadeStalled, true
+	}
+	if startedBy(iss, end) {
+		return data.ParadeRolling, true
+	}
+	return data.ParadeLinedUp, true
+}
+
+func closedBy(iss *data.Issue, end time.Time) bool {
+	return iss.ClosedAt != nil && iss.ClosedAt.Before(end)
+}
+
+// startedBy reports whether work on iss had begun by end. An in-progress issue
+// without a recorded start counts from its last update.
+func startedBy(iss *data.Issue, end time.Time) bool {
+	if iss.StartedAt != nil {
+		return iss.StartedAt.Before(end)
+	}
+	return iss.Status == data.StatusInProgress && iss.UpdatedAt.Before(end)
+}
+
+// blockedAt mirrors EvaluateDependencies as of end: a blocking edge counts if
+// its target is missing, or existed by end and had not yet closed.
+func blockedAt(iss *data.Issue, issueMap map[string]*data.Issue, blockingTypes map[string]bool, end time.Time) bool {
+	for _, dep := range iss.Dependencies {
+		if !blockingTypes[dep.Type] {
+			continue
+		}
+		target, ok := issueMap[dep.DependsOnID]
+		if !ok {
+			return true
+		}
+		if target.CreatedAt.Before(end) && !closedBy(target, end) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package gastown
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+func TestComputeCFD(t *testing.T) {
+	now := time.Date(2026, 6, 10, 15, 0, 0, 0, time.UTC)
+	day := func(n int) *time.Time {
+		d := now.AddDate(0, 0, -n)
+		return &d
+	}
+	issues := []data.Issue{
+		// Created 4 days ago, started 3, closed 1.
+		{ID: "mg-1", Status: data.StatusClosed, CreatedAt: *day(4), StartedAt: day(3), ClosedAt: day(1)},
+		// Blocked by mg-1 until it closed, then started today.
+		{ID: "mg-2", Status: data.StatusInProgress, CreatedAt: *day(3), StartedAt: &now,
+			Dependencies: []data.Dependency{{IssueID: "mg-2", DependsOnID: "mg-1", Type: "blocks"}}},
+		// Created 2 days ago, still lined up.
+		{ID: "mg-3", Status: data.StatusOpen, CreatedAt: *day(2)},
+	}
+
+	cfd := ComputeCFD(issues, data.DefaultBlockingTypes, 5, now)
+	if len(cfd.Days) != 5 {
+		t.Fatalf("got %d days, want 5", len(cfd.Days))
+	}
+	want := [][4]int{
+		// Rolling, LinedUp, Stalled, PastTheStand
+		{0, 1, 0, 0}, // 4 days ago: mg-1 created
+		{1, 0, 1, 0}, // 3 days ago: mg-1 started, mg-2 stalled on it
+		{1, 1, 1, 0}, // 2 days ago: mg-3 lined up
+		{0, 2, 0, 1}, // yesterday: mg-1 closed, mg-2 unblocked
+		{1, 1, 0, 1}, // today: live grouping, mg-2 rolling
+	}
+	for i, w := range want {
+		if got := cfd.Days[i].Counts; got != w {
+			t.Errorf("day %d counts = %v, want %v", i, got, w)
+		}
+	}
+	if got := cfd.Days[4].Total(); got != 3 {
+		t.Errorf("today's total = %d, want 3", got)
+	}
+	if got := len(cfd.Last(2)); got != 2 {
+		t.Errorf("Last(2) returned %d days", got)
+	}
+	if !cfd.Days[4].Day.Equal(startOfDay(now)) {
+		t.Errorf("last day = %v, want today", cfd.Days[4].Day)
+	}
+}
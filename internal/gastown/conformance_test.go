@@ -0,0 +1,550 @@
+package gastown
+
+// Driver conformance suite. runDriverConformance exercises every Driver method
+// against a fake backend and asserts the behavior the app relies on,
+// independent of which orchestrator is behind the seam:
+//
+//   - success:     a call on a known object returns nil (or ErrUnsupported)
+//   - features:    Supports agrees with which gated methods return ErrUnsupported
+//   - unsupported: ErrUnsupported is decided locally, without touching the backend
+//   - not-found:   an unknown id fails with a single-line, non-ErrUnsupported error
+//   - timeout:     a backend that never answers fails promptly instead of hanging
+//
+// GTDriver runs against testdata/gt (the fake gt script), GCDriver against
+// testdata/fakegc (the fake supervisor), and ProcessDriver against
+// testdata/fakedriver (a fake out-of-process driver). A new Driver is
+// validated by building a conformanceBackend for it and calling
+// runDriverConformance.
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// conformanceFixture names objects that exist on the fake backend. The
+// not-found pass swaps every field for a "missing-" id, which every fake
+// answers with its backend's not-found error.
+type conformanceFixture struct {
+	Agent   string // roster agent with a live session
+	Issue   string // dispatchable issue
+	Convoy  string
+	Epic    string // epic with child issues, for ConvoyCreateFromEpic
+	Mail    string // message id
+	Formula string
+	Root    string // molecule root issue
+	Step    string // molecule step
+}
+
+func (fx conformanceFixture) missing() conformanceFixture {
+	return conformanceFixture{
+		Agent:   "missing-agent",
+		Issue:   "missing-404",
+		Convoy:  "missing-convoy",
+		Epic:    "missing-epic",
+		Mail:    "missing-mail",
+		Formula: fx.Formula,
+		Root:    "missing-root",
+		Step:    "missing-step",
+	}
+}
+
+// conformanceBackend is a Driver wired to a fake backend.
+type conformanceBackend struct {
+	driver  Driver
+	fixture conformanceFixture
+	// slow returns a driver whose backend never answers in time, and the
+	// deadline to give each call. Every supported method must fail within
+	// conformanceTimeoutBound.
+	slow func(t *testing.T) (Driver, time.Duration)
+	// skip lists methods the fake backend cannot serve, with the reason.
+	skip map[string]string
+}
+
+// conformanceTimeoutBound is how long a call against a hung backend may take.
+const conformanceTimeoutBound = 3 * time.Second
+
+// conformanceCall is one Driver method as the suite invokes it. keyed calls
+// address a fixture object and so also get a not-found pass.
+type conformanceCall struct {
+	method  string
+	keyed   bool
+	feature *Feature // gating feature, if any
+	call    func(ctx context.Context, d Driver, fx conformanceFixture) error
+}
+
+func featureRef(f Feature) *Feature { return &f }
+
+var conformanceCalls = []conformanceCall{
+	{method: "Status", call: func(ctx context.Context, d Driver, _ conformanceFixture) error {
+		_, err := d.Status(ctx)
+		return err
+	}},
+	{method: "Formulas", call: func(ctx context.Context, d Driver, _ conformanceFixture) error {
+		_, err := d.Formulas(ctx)
+		return err
+	}},
+	{method: "Comments", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		_, err := d.Comments(ctx, fx.Issue)
+		return err
+	}},
+	{method: "SessionTranscript", keyed: true, feature: featureRef(FeatureTranscript), call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		_, err := d.SessionTranscript(ctx, fx.Agent)
+		return err
+	}},
+	{method: "Sling", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		return d.Sling(ctx, SlingRequest{IssueIDs: []string{fx.Issue}, Target: fx.Agent})
+	}},
+	{method: "Unsling", keyed: true, feature: featureRef(FeatureUnsling), call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		return d.Unsling(ctx, fx.Issue)
+	}},
+	{method: "Nudge", keyed: true, feature: featureRef(FeatureNudge), call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		return d.Nudge(ctx, fx.Agent, "conformance check")
+	}},
+	{method: "Decommission", keyed: true, feature: featureRef(FeatureDecommission), call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		return d.Decommission(ctx, fx.Agent)
+	}},
+	{method: "CascadeClose", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		return d.CascadeClose(ctx, fx.Issue)
+	}},
+	{method: "Assign", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		_, err := d.Assign(ctx, fx.Agent, "Conformance task", "task", "2", "", false)
+		return err
+	}},
+	{method: "ConvoyList", call: func(ctx context.Context, d Driver, _ conformanceFixture) error {
+		_, err := d.ConvoyList(ctx)
+		return err
+	}},
+	{method: "ConvoyStatus", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		_, err := d.ConvoyStatus(ctx, fx.Convoy)
+		return err
+	}},
+	{method: "ConvoyCreate", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		_, err := d.ConvoyCreate(ctx, "Conformance convoy", []string{fx.Issue})
+		return err
+	}},
+	{method: "ConvoyCreateFromEpic", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		_, err := d.ConvoyCreateFromEpic(ctx, "Conformance convoy", fx.Epic)
+		return err
+	}},
+	{method: "ConvoyAdd", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		return d.ConvoyAdd(ctx, fx.Convoy, []string{fx.Issue})
+	}},
+	{method: "ConvoyClose", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		return d.ConvoyClose(ctx, fx.Convoy)
+	}},
+	{method: "ConvoyLand", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		return d.ConvoyLand(ctx, fx.Convoy)
+	}},
+	{method: "ConvoyWatch", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		return d.ConvoyWatch(ctx, fx.Convoy)
+	}},
+	{method: "ConvoyUnwatch", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		return d.ConvoyUnwatch(ctx, fx.Convoy)
+	}},
+	{method: "MailInbox", call: func(ctx context.Context, d Driver, _ conformanceFixture) error {
+		_, err := d.MailInbox(ctx, false)
+		return err
+	}},
+	{method: "MailRead", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		_, err := d.MailRead(ctx, fx.Mail)
+		return err
+	}},
+	{method: "MailReply", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		return d.MailReply(ctx, fx.Mail, "ack")
+	}},
+	{method: "MailSend", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		return d.MailSend(ctx, fx.Agent, "Conformance", "hello")
+	}},
+	{method: "MailArchive", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		return d.MailArchive(ctx, fx.Mail)
+	}},
+	{method: "MailMarkRead", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		return d.MailMarkRead(ctx, fx.Mail)
+	}},
+	{method: "MailMarkAllRead", call: func(ctx context.Context, d Driver, _ conformanceFixture) error {
+		return d.MailMarkAllRead(ctx)
+	}},
+	{method: "MoleculeDAG", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		_, err := d.MoleculeDAG(ctx, fx.Root)
+		return err
+	}},
+	{method: "MoleculeProgress", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		_, err := d.MoleculeProgress(ctx, fx.Root)
+		return err
+	}},
+	{method: "MoleculeStepDone", keyed: true, call: func(ctx context.Context, d Driver, fx conformanceFixture) error {
+		_, err := d.MoleculeStepDone(ctx, fx.Step)
+		return err
+	}},
+	{method: "Vitals", feature: featureRef(FeatureVitals), call: func(ctx context.Context, d Driver, _ conformanceFixture) error {
+		_, err := d.Vitals(ctx)
+		return err
+	}},
+	{method: "Costs", feature: featureRef(FeatureCosts), call: func(ctx context.Context, d Driver, _ conformanceFixture) error {
+		_, err := d.Costs(ctx)
+		return err
+	}},
+	{method: "PatrolScan", feature: featureRef(FeaturePatrol), call: func(ctx context.Context, d Driver, _ conformanceFixture) error {
+		_, err := d.PatrolScan(ctx)
+		return err
+	}},
+}
+
+// TestConformanceCallsCoverDriver keeps the call table in step with the
+// interface: a method added to Driver must be added to the suite too.
+func TestConformanceCallsCoverDriver(t *testing.T) {
+	covered := map[string]bool{"Backend": true, "Supports": true}
+	for _, c := range conformanceCalls {
+		covered[c.method] = true
+	}
+	typ := reflect.TypeOf((*Driver)(nil)).Elem()
+	for i := range typ.NumMethod() {
+		if name := typ.Method(i).Name; !covered[name] {
+			t.Errorf("Driver.%s has no conformance call", name)
+		}
+	}
+}
+
+// runDriverConformance runs the suite against one backend.
+func runDriverConformance(t *testing.T, b conformanceBackend) {
+	t.Helper()
+	d := b.driver
+	if d.Backend() == "" {
+		t.Fatal("Backend() is empty")
+	}
+
+	// unsupported records which methods this driver opts out of, learned
+	// from the success pass and asserted on in the later passes.
+	unsupported := map[string]bool{}
+
+	t.Run("success", func(t *testing.T) {
+		for _, c := range conformanceCalls {
+			t.Run(c.method, func(t *testing.T) {
+				if reason, ok := b.skip[c.method]; ok {
+					t.Skip(reason)
+				}
+				err := c.call(context.Background(), d, b.fixture)
+				switch {
+				case errors.Is(err, ErrUnsupported):
+					unsupported[c.method] = true
+				case err != nil:
+					t.Fatalf("%s on fixture: %v", c.method, err)
+				}
+				if c.feature != nil && d.Supports(*c.feature) == unsupported[c.method] {
+					t.Errorf("Supports(%d) = %v but %s unsupported = %v",
+						*c.feature, d.Supports(*c.feature), c.method, unsupported[c.method])
+				}
+			})
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		// A canceled context makes any backend round trip fail, so getting
+		// ErrUnsupported back proves the driver decided without asking.
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		for _, c := range conformanceCalls {
+			if !unsupported[c.method] {
+				continue
+			}
+			t.Run(c.method, func(t *testing.T) {
+				for _, fx := range []conformanceFixture{b.fixture, b.fixture.missing()} {
+					if err := c.call(ctx, d, fx); !errors.Is(err, ErrUnsupported) {
+						t.Errorf("%s = %v, want ErrUnsupported regardless of context or id", c.method, err)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		missing := b.fixture.missing()
+		for _, c := range conformanceCalls {
+			if !c.keyed || unsupported[c.method] {
+				continue
+			}
+			t.Run(c.method, func(t *testing.T) {
+				if reason, ok := b.skip[c.method]; ok {
+					t.Skip(reason)
+				}
+				err := c.call(context.Background(), d, missing)
+				if err == nil {
+					t.Fatalf("%s on a missing id succeeded", c.method)
+				}
+				if errors.Is(err, ErrUnsupported) {
+					t.Fatalf("%s on a missing id = ErrUnsupported, want a backend error", c.method)
+				}
+				assertDisplayableError(t, err)
+			})
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		if b.slow == nil {
+			t.Skip("backend has no slow mode")
+		}
+		slow, deadline := b.slow(t)
+		for _, c := range conformanceCalls {
+			if unsupported[c.method] {
+				continue
+			}
+			t.Run(c.method, func(t *testing.T) {
+				if reason, ok := b.skip[c.method]; ok {
+					t.Skip(reason)
+				}
+				ctx, cancel := context.WithTimeout(context.Background(), deadline)
+				defer cancel()
+				start := time.Now()
+				err := c.call(ctx, slow, b.fixture)
+				if elapsed := time.Since(start); elapsed > conformanceTimeoutBound {
+					t.Errorf("%s took %v against a hung backend, want < %v", c.method, elapsed, conformanceTimeoutBound)
+				}
+				if err == nil {
+					t.Fatalf("%s against a hung backend succeeded", c.method)
+				}
+				assertDisplayableError(t, err)
+			})
+		}
+	})
+}
+
+// rawProblemJSON catches a problem+json body leaking into an error verbatim
+// instead of being reduced to its detail.
+var rawProblemJSON = regexp.MustCompile(`\{\s*"(title|status|detail)"`)
+
+// assertDisplayableError checks an error is fit for a one-line toast.
+func assertDisplayableError(t *testing.T, err error) {
+	t.Helper()
+	msg := err.Error()
+	if strings.TrimSpace(msg) == "" {
+		t.Errorf("error message is empty")
+	}
+	if strings.ContainsAny(msg, "\r\n") {
+		t.Errorf("error spans lines: %q", msg)
+	}
+	if rawProblemJSON.MatchString(msg) {
+		t.Errorf("error carries a raw response body: %q", msg)
+	}
+}
+
+// --- Gas Town: testdata/gt -------------------------------------------------
+
+// fakeTmux stands in for tmux capture-pane, which GTDriver.SessionTranscript
+// reads instead of gt. It honors the same test hooks as testdata/gt.
+const fakeTmux = `#!/usr/bin/env bash
+if [ -n "$FAKE_GT_DELAY" ]; then
+  sleep "$FAKE_GT_DELAY" >/dev/null 2>&1
+fi
+for arg in "$@"; do
+  case "$arg" in
+    missing-*) echo "can't find session: $arg" >&2; exit 1 ;;
+  esac
+done
+printf 'reading internal/auth\nok (41 tests)\n\n\n'
+`
+
+// gtConformanceBackend puts testdata (and a fake tmux) first on PATH so the
+// real package-level gt wrappers run against the fake script.
+func gtConformanceBackend(t *testing.T) conformanceBackend {
+	t.Helper()
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	testdata, err := filepath.Abs(filepath.Join("..", "..", "testdata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "tmux"), []byte(fakeTmux), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", strings.Join([]string{testdata, bin, os.Getenv("PATH")}, string(os.PathListSeparator)))
+	t.Setenv("FAKE_GT_DELAY", "")
+
+	return conformanceBackend{
+		driver: NewGTDriver(),
+		fixture: conformanceFixture{
+			Agent:   "obsidian",
+			Issue:   "mg-001",
+			Convoy:  "convoy-1",
+			Epic:    "mg-003",
+			Mail:    "mail-1",
+			Formula: "shiny",
+			Root:    "mg-001",
+			Step:    "mg-001.2",
+		},
+		// GTDriver ignores ctx; the CLI wrappers' own timeouts must fire.
+		slow: func(t *testing.T) (Driver, time.Duration) {
+			t.Setenv("FAKE_GT_DELAY", "5")
+			long, medium, short := timeoutLong, timeoutMedium, timeoutShort
+			timeoutLong, timeoutMedium, timeoutShort = 100*time.Millisecond, 100*time.Millisecond, 100*time.Millisecond
+			t.Cleanup(func() { timeoutLong, timeoutMedium, timeoutShort = long, medium, short })
+			return NewGTDriver(), time.Minute
+		},
+		skip: map[string]string{
+			"Comments": "GTDriver reads comments from bd, which has no fake in testdata",
+		},
+	}
+}
+
+func TestGTDriverConformance(t *testing.T) {
+	runDriverConformance(t, gtConformanceBackend(t))
+}
+
+// --- Gas City: testdata/fakegc ---------------------------------------------
+
+var fakegcListening = regexp.MustCompile(`listening on (http://\S+)`)
+
+// buildFake compiles a fake backend under testdata/ into a temp dir and
+// returns the binary's path.
+func buildFake(t *testing.T, name string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds and runs testdata/" + name)
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available to build " + name)
+	}
+	bin := filepath.Join(t.TempDir(), name)
+	build := exec.Command(goBin, "build", "-o", bin, "../../testdata/"+name)
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("build %s: %v\n%s", name, err, out)
+	}
+	return bin
+}
+
+// startFakeGC builds testdata/fakegc and serves it on a free port, returning
+// its base URL. The process is killed when the test ends.
+func startFakeGC(t *testing.T) string {
+	t.Helper()
+	bin := buildFake(t, "fakegc")
+	cmd := exec.Command(bin, "-addr", "127.0.0.1:0")
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start fakegc: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	found := make(chan string, 1)
+	go func() {
+		sc := bufio.NewScanner(stderr)
+		for sc.Scan() {
+			if m := fakegcListening.FindStringSubmatch(sc.Text()); m != nil {
+				found <- m[1]
+				break
+			}
+		}
+		// Keep draining the request log so the server never blocks on it.
+		for sc.Scan() {
+		}
+	}()
+	select {
+	case url := <-found:
+		return url
+	case <-time.After(10 * time.Second):
+		t.Fatal("fakegc did not report a listening address")
+		return ""
+	}
+}
+
+func gcConformanceBackend(t *testing.T) conformanceBackend {
+	t.Helper()
+	d, err := NewGCDriver(startFakeGC(t), "bourbon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conformanceBackend{
+		driver: d,
+		fixture: conformanceFixture{
+			Agent:   "zulu",
+			Issue:   "mg-q12",
+			Convoy:  "cv-carnival",
+			Epic:    "mg-e01",
+			Mail:    "m1",
+			Formula: "shiny",
+			Root:    "mg-q12",
+			Step:    "mg-q12",
+		},
+		slow: func(t *testing.T) (Driver, time.Duration) {
+			// A supervisor that accepts the connection and never answers; the
+			// driver must give up when the caller's deadline passes.
+			release := make(chan struct{})
+			hung := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-release:
+				}
+			}))
+			t.Cleanup(func() {
+				close(release)
+				hung.Close()
+			})
+			slow, err := NewGCDriver(hung.URL, "bourbon")
+			if err != nil {
+				t.Fatal(err)
+			}
+			return slow, 200 * time.Millisecond
+		},
+	}
+}
+
+func TestGCDriverConformance(t *testing.T) {
+	runDriverConformance(t, gcConformanceBackend(t))
+}
+
+// --- out-of-process: testdata/fakedriver -----------------------------------
+
+func processConformanceBackend(t *testing.T) conformanceBackend {
+	t.Helper()
+	bin := buildFake(t, "fakedriver")
+	t.Setenv("FAKE_DRIVER_DELAY", "")
+	d, err := NewProcessDriver(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = d.Close() })
+	return conformanceBackend{
+		driver: d,
+		fixture: conformanceFixture{
+			Agent:   "atlas",
+			Issue:   "mg-001",
+			Convoy:  "cv-1",
+			Epic:    "mg-003",
+			Mail:    "msg-1",
+			Formula: "shiny",
+			Root:    "mg-001",
+			Step:    "mg-001.1",
+		},
+		slow: func(t *testing.T) (Driver, time.Duration) {
+			t.Setenv("FAKE_DRIVER_DELAY", "5s")
+			slow, err := NewProcessDriver(bin)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = slow.Close() })
+			return slow, 200 * time.Millisecond
+		},
+	}
+}
+
+func TestProcessDriverConformance(t *testing.T) {
+	runDriverConformance(t, processConformanceBackend(t))
+}
@@ -87,6 +87,11 @@ func ConvoyCreateFromEpic(name, epicID string) (string, error) {
 
 // ConvoyAdd adds issues to an existing convoy.
 func ConvoyAdd(convoyID string, issueIDs []string) error {
+	for _, id := range issueIDs {
+		if err := validateIssueID(id); err != nil {
+			return err
+		}
+	}
 	args := []string{"convoy", "add", "--", convoyID}
 	args = append(args, issueIDs...)
 	out, err := runCombinedWithTimeout(timeoutShort, "gt", args...)
@@ -0,0 +1,243 @@
+package gastown
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/config"
+)
+
+// MaxCostHistoryDays is the longest history the trend views load.
+const MaxCostHistoryDays = 90
+
+// CostSample is one `gt costs` reading recorded to the cost history. Its
+// Period says what the totals cover: the calendar day so far, in which case
+// the latest sample of a day is that day's spend, or a rolling window, in
+// which case CostDays spreads each sample over the time since the one before.
+type CostSample struct {
+	At time.Time `json:"at"`
+	CostsOutput
+}
+
+// CostHistoryPath returns the cost history path: MG_COST_HISTORY if set,
+// otherwise mardi-gras/costs.jsonl under the user config directory.
+func CostHistoryPath() string {
+	return config.Path("MG_COST_HISTORY", "costs.jsonl")
+}
+
+// LoadCostHistory reads the samples taken at or after since, oldest first. A
+// missing file is an empty history; unparseable lines are skipped.
+func LoadCostHistory(path string, since time.Time) ([]CostSample, error) {
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cost history: %w", err)
+	}
+	var out []CostSample
+	sc := bufio.NewScanner(bytes.NewReader(raw))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var s CostSample
+		if json.Unmarshal(sc.Bytes(), &s) != nil || s.At.IsZero() || s.At.Before(since) {
+			continue
+		}
+		out = append(out, s)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out, nil
+}
+
+// RecordCostSample adds a sample to the history at path, replacing any
+// earlier sample from the same bucket: the calendar day for day-to-date
+// totals, so the file grows by a line a day, and the hour for rolling ones,
+// which CostDays needs more often to follow the burn rate. Rolling samples
+// older than MaxCostHistoryDays are thinned to one a day. The file is
+// replaced atomically, but callers still run one RecordCostSample at a time
+// so that overlapping rewrites can't drop a sample.
+func RecordCostSample(path string, sample CostSample) error {
+	if path == "" {
+		return fmt.Errorf("cost history: no path")
+	}
+	all, err := LoadCostHistory(path, time.Time{})
+	if err != nil {
+		return err
+	}
+	loc := sample.At.Location()
+	hourly := sample.At.AddDate(0, 0, -MaxCostHistoryDays)
+	bucket := func(s CostSample) time.Time {
+		at := s.At.In(loc)
+		if s.Rolling() && !at.Before(hourly) {
+			return at.Truncate(time.Hour)
+		}
+		return startOfDay(at)
+	}
+	// Keep the latest sample of each bucket, the new sample included.
+	all = append(all, sample)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].At.Before(all[j].At) })
+	var kept []CostSample
+	for i, s := range all {
+		if i+1 < len(all) && all[i+1].Rolling() == s.Rolling() && bucket(all[i+1]).Equal(bucket(s)) {
+			continue
+		}
+		kept = append(kept, s)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, s := range kept {
+		if err := enc.Encode(s); err != nil {
+			return fmt.Errorf("cost history: %w", err)
+		}
+	}
+	if err := config.WriteFileAtomic(path, buf.Bytes()); err != nil {
+		return fmt.Errorf("cost history: %w", err)
+	}
+	return nil
+}
+
+// CostDay is one calendar day of spend from the cost history.
+type CostDay struct {
+	Day     time.Time // midnight, in now's location
+	Sampled bool      // false when mg recorded nothing that day
+
+	Cost         float64
+	InputTokens  int
+	OutputTokens int
+	Sessions     int
+	ByRole       map[string]float64
+	ByRig        map[string]float64
+}
+
+// CostDays lays the history out as one CostDay per calendar day over the last
+// days days, oldest first and ending with today.
+//
+// A day with a day-to-date sample takes its latest one. A rolling total only
+// says what was spent over the window before it, so each rolling sample is
+// charged for the time since the previous rolling sample, at most one
+// window, at the sample's average rate, and that spend is split across the
+// days it falls in. Samples a window apart then count each hour once, and
+// hourly samples follow the burn rate through the day. Session counts can't
+// be split, so a day built from rolling samples takes their largest.
+func CostDays(samples []CostSample, days int, now time.Time) []CostDay {
+	if days <= 0 {
+		return nil
+	}
+	today := startOfDay(now)
+	out := make([]CostDay, days)
+	index := make(map[time.Time]int, days)
+	for i := range out {
+		out[i].Day = today.AddDate(0, 0, i-days+1)
+		index[out[i].Day] = i
+	}
+	latest := make(map[time.Time]CostSample)
+	var rolling []CostSample
+	for _, s := range samples {
+		if s.Rolling() {
+			rolling = append(rolling, s)
+			continue
+		}
+		d := startOfDay(s.At.In(now.Location()))
+		if prev, ok := latest[d]; !ok || !s.At.Before(prev.At) {
+			latest[d] = s
+		}
+	}
+
+	sort.SliceStable(rolling, func(i, j int) bool { return rolling[i].At.Before(rolling[j].At) })
+	var prev time.Time
+	for _, s := range rolling {
+		window := s.Window()
+		end := s.At.In(now.Location())
+		start := end.Add(-window)
+		if prev.After(start) {
+			start = prev
+		}
+		prev = end
+		for day := startOfDay(start); day.Before(end); day = day.AddDate(0, 0, 1) {
+			from, to := day, day.AddDate(0, 0, 1)
+			if start.After(from) {
+				from = start
+			}
+			if end.Before(to) {
+				to = end
+			}
+			i, ok := index[day]
+			if !ok || !from.Before(to) {
+				continue
+			}
+			out[i].add(s, float64(to.Sub(from))/float64(window))
+		}
+	}
+
+	for d, s := range latest {
+		if i, ok := index[d]; ok {
+			out[i] = CostDay{Day: d}
+			out[i].add(s, 1)
+		}
+	}
+	return out
+}
+
+// add charges frac of sample s to the day.
+func (d *CostDay) add(s CostSample, frac float64) {
+	if !d.Sampled {
+		d.Sampled = true
+		d.ByRole = make(map[string]float64, len(s.ByRole))
+		d.ByRig = make(map[string]float64, len(s.ByRig))
+	}
+	d.Cost += s.Total.Cost * frac
+	d.InputTokens += int(math.Round(float64(s.Total.InputTokens) * frac))
+	d.OutputTokens += int(math.Round(float64(s.Total.OutputTokens) * frac))
+	d.Sessions = max(d.Sessions, s.Sessions)
+	for _, rc := range s.ByRole {
+		d.ByRole[rc.Role] += rc.Cost * frac
+	}
+	for _, rc := range s.ByRig {
+		d.ByRig[rc.Rig] += rc.Cost * frac
+	}
+}
+
+// SumCostDays totals a run of days into one CostsOutput, with by-role and
+// by-rig breakdowns sorted by name. It returns nil when no day was sampled.
+func SumCostDays(days []CostDay) *CostsOutput {
+	var out CostsOutput
+	roles := make(map[string]float64)
+	rigs := make(map[string]float64)
+	sampled := false
+	for _, d := range days {
+		if !d.Sampled {
+			continue
+		}
+		sampled = true
+		out.Total.Cost += d.Cost
+		out.Total.InputTokens += d.InputTokens
+		out.Total.OutputTokens += d.OutputTokens
+		out.Sessions += d.Sessions
+		for k, v := range d.ByRole {
+			roles[k] += v
+		}
+		for k, v := range d.ByRig {
+			rigs[k] += v
+		}
+	}
+	if !sampled {
+		return nil
+	}
+	for _, k := range sortedKeys(roles) {
+		out.ByRole = append(out.ByRole, RoleCost{Role: k, Cost: roles[k]})
+	}
+	for _, k := range sortedKeys(rigs) {
+		out.ByRig = append(out.ByRig, RigCost{Rig: k, Cost: rigs[k]})
+	}
+	return &out
+}
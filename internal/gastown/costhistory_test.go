@@ -0,0 +1,146 @@
+package gastown
+
+import (
+	"math"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func costSample(at time.Time, cost float64) CostSample {
+	s := CostSample{At: at}
+	s.Total = CostTotal{InputTokens: 1000, OutputTokens: 200, Cost: cost}
+	s.Sessions = 2
+	s.ByRole = []RoleCost{{Role: "polecat", Cost: cost * 0.75}, {Role: "witness", Cost: cost * 0.25}}
+	s.ByRig = []RigCost{{Rig: "gastown", Cost: cost}}
+	return s
+}
+
+func TestRecordCostSampleKeepsOnePerDay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mg", "costs.jsonl")
+	day := time.Date(2026, 3, 4, 9, 0, 0, 0, time.Local)
+
+	for _, s := range []CostSample{
+		costSample(day.AddDate(0, 0, -1), 20),
+		costSample(day, 5),
+		costSample(day.Add(3*time.Hour), 8),
+	} {
+		if err := RecordCostSample(path, s); err != nil {
+			t.Fatalf("RecordCostSample: %v", err)
+		}
+	}
+
+	got, err := LoadCostHistory(path, time.Time{})
+	if err != nil {
+		t.Fatalf("LoadCostHistory: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d samples, want one per day: %+v", len(got), got)
+	}
+	if got[0].Total.Cost != 20 || got[1].Total.Cost != 8 || got[1].ByRig[0].Rig != "gastown" {
+		t.Fatalf("samples = %+v", got)
+	}
+
+	recent, _ := LoadCostHistory(path, day)
+	if len(recent) != 1 {
+		t.Fatalf("since filter kept %d samples, want 1", len(recent))
+	}
+}
+
+func TestLoadCostHistoryMissing(t *testing.T) {
+	got, err := LoadCostHistory(filepath.Join(t.TempDir(), "none.jsonl"), time.Time{})
+	if err != nil || got != nil {
+		t.Fatalf("missing file = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestCostDaysAndSum(t *testing.T) {
+	now := time.Date(2026, 3, 4, 15, 0, 0, 0, time.Local) // Wednesday
+	samples := []CostSample{
+		costSample(now.AddDate(0, 0, -3), 4), // Sunday
+		costSample(now.AddDate(0, 0, -1), 6), // Tuesday
+		costSample(now.AddDate(0, 0, -1).Add(-time.Hour), 2),
+		costSample(now, 10),
+	}
+
+	days := CostDays(samples, 5, now)
+	if len(days) != 5 || !days[4].Day.Equal(startOfDay(now)) {
+		t.Fatalf("days = %+v", days)
+	}
+	if days[0].Sampled || !days[1].Sampled || days[2].Sampled {
+		t.Fatalf("sampled flags wrong: %+v", days)
+	}
+	if days[3].Cost != 6 {
+		t.Fatalf("Tuesday = %v, want the latest sample (6)", days[3].Cost)
+	}
+
+	sum := SumCostDays(days)
+	if sum.Total.Cost != 20 || sum.Total.InputTokens != 3000 || sum.Sessions != 6 {
+		t.Fatalf("sum = %+v", sum)
+	}
+	if len(sum.ByRole) != 2 || sum.ByRole[0].Role != "polecat" || sum.ByRole[0].Cost != 15 {
+		t.Fatalf("by role = %+v", sum.ByRole)
+	}
+	if SumCostDays(days[:1]) != nil {
+		t.Fatal("unsampled days should sum to nil")
+	}
+}
+
+func rollingSample(at time.Time, cost float64) CostSample {
+	s := costSample(at, cost)
+	s.Period = "last 24h"
+	return s
+}
+
+func TestCostDaysSpreadsRollingTotals(t *testing.T) {
+	now := time.Date(2026, 3, 4, 15, 0, 0, 0, time.Local)
+	tue := time.Date(2026, 3, 3, 0, 0, 0, 0, time.Local)
+	samples := []CostSample{
+		rollingSample(tue.Add(12*time.Hour), 24), // first: its whole window, half of it Monday
+		rollingSample(tue.Add(18*time.Hour), 48), // 6h at $2/h
+		rollingSample(tue.Add(30*time.Hour), 24), // 12h at $1/h across midnight
+	}
+
+	days := CostDays(samples, 3, now)
+	want := []float64{12, 30, 6}
+	for i, d := range days {
+		if !d.Sampled || math.Abs(d.Cost-want[i]) > 1e-9 {
+			t.Errorf("%s = %v (sampled %v), want %v", d.Day.Format(time.DateOnly), d.Cost, d.Sampled, want[i])
+		}
+	}
+	if got := days[1].ByRole["polecat"]; math.Abs(got-22.5) > 1e-9 {
+		t.Errorf("Tuesday polecat = %v, want 22.5", got)
+	}
+	if days[1].Sessions != 2 {
+		t.Errorf("Tuesday sessions = %d, want the largest sample's 2", days[1].Sessions)
+	}
+}
+
+func TestRecordCostSampleKeepsRollingSamplesHourly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "costs.jsonl")
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.Local)
+	old := now.AddDate(0, 0, -MaxCostHistoryDays-5)
+	for _, s := range []CostSample{
+		rollingSample(old.Add(time.Hour), 1),
+		rollingSample(old.Add(5*time.Hour), 2),
+		rollingSample(now.Add(10*time.Minute), 3),
+		rollingSample(now.Add(40*time.Minute), 4),
+		rollingSample(now.Add(65*time.Minute), 5),
+	} {
+		if err := RecordCostSample(path, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := LoadCostHistory(path, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var costs []float64
+	for _, s := range got {
+		costs = append(costs, s.Total.Cost)
+	}
+	if !slices.Equal(costs, []float64{2, 4, 5}) {
+		t.Fatalf("kept %v, want the last of the old day and the last of each recent hour", costs)
+	}
+}
@@ -3,6 +3,8 @@ package gastown
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // CostsOutput represents the parsed output of `gt costs --json`.
@@ -14,6 +16,24 @@ type CostsOutput struct {
 	ByRig    []RigCost  `json:"by_rig"`
 }
 
+// Rolling reports whether the totals cover a rolling window, such as gt's
+// "last 24h", rather than the calendar day so far ("today", or no period).
+func (c CostsOutput) Rolling() bool {
+	return c.Period != "" && c.Period != "today"
+}
+
+// Window is the span a rolling total covers: the duration in "last <d>" when
+// it parses, otherwise a day. It is zero for day-to-date totals.
+func (c CostsOutput) Window() time.Duration {
+	if !c.Rolling() {
+		return 0
+	}
+	if d, err := time.ParseDuration(strings.TrimPrefix(c.Period, "last ")); err == nil && d > 0 {
+		return d
+	}
+	return 24 * time.Hour
+}
+
 // CostTotal holds aggregate token/cost totals.
 type CostTotal struct {
 	InputTokens  int     `json:"input_tokens"`
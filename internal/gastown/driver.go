@@ -41,6 +41,18 @@ const (
 	// from ~/gt/.events.jsonl. Gas Town only — Gas City has no such file (its
 	// equivalent is the supervisor events API, not yet wired up).
 	FeatureActivityFeed
+	// FeatureTranscript is reading an agent's session history: the supervisor
+	// transcript API on Gas City, the tmux scrollback on Gas Town.
+	FeatureTranscript
+	// FeatureNudge is Driver.Nudge.
+	FeatureNudge
+	// FeatureUnsling is Driver.Unsling. Gas City has no endpoint for it.
+	FeatureUnsling
+	// FeatureDecommission is Driver.Decommission.
+	FeatureDecommission
+	// FeatureAutoSling is slinging without a SlingRequest.Target, leaving
+	// the orchestrator to pick the agent. Gas City needs an explicit target.
+	FeatureAutoSling
 )
 
 // SlingRequest collapses the several `gt sling` variants (single/multiple,
@@ -49,15 +61,15 @@ const (
 // POST /v0/city/{city}/sling. Agent and Formula are mutually exclusive
 // (Formula wins if both are set, mirroring the existing call sites).
 type SlingRequest struct {
-	IssueIDs []string // one or more issues to dispatch
-	Agent    string   // optional --agent runtime override (e.g. "codex")
-	Formula  string   // optional formula name (full workflow)
+	IssueIDs []string `json:"issue_ids"`         // one or more issues to dispatch
+	Agent    string   `json:"agent,omitempty"`   // optional --agent runtime override (e.g. "codex")
+	Formula  string   `json:"formula,omitempty"` // optional formula name (full workflow)
 
 	// Reserved for the Gas City driver; unused by the Gas Town driver.
-	Target string
-	Rig    string
-	Title  string
-	Force  bool
+	Target string `json:"target,omitempty"`
+	Rig    string `json:"rig,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Force  bool   `json:"force,omitempty"`
 }
 
 // Driver is the seam between mg and the multi-agent orchestrator. The Gas
@@ -84,6 +96,10 @@ type Driver interface {
 	Status(ctx context.Context) (*TownStatus, error)
 	Formulas(ctx context.Context) ([]string, error)
 	Comments(ctx context.Context, issueID string) ([]Comment, error)
+	// SessionTranscript reads the history of an agent's session. target is
+	// the roster session name (AgentRuntime.Session), falling back to the
+	// agent's address when the roster reports no session.
+	SessionTranscript(ctx context.Context, target string) (*SessionTranscript, error)
 
 	// Dispatch / lifecycle.
 	Sling(ctx context.Context, req SlingRequest) error
@@ -98,6 +114,7 @@ type Driver interface {
 	ConvoyStatus(ctx context.Context, convoyID string) (*ConvoyDetail, error)
 	ConvoyCreate(ctx context.Context, name string, issueIDs []string) (string, error)
 	ConvoyCreateFromEpic(ctx context.Context, name, epicID string) (string, error)
+	ConvoyAdd(ctx context.Context, convoyID string, issueIDs []string) error
 	ConvoyClose(ctx context.Context, convoyID string) error
 	ConvoyLand(ctx context.Context, convoyID string) error
 	ConvoyWatch(ctx context.Context, convoyID string) error
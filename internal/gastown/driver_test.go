@@ -33,6 +33,7 @@ func TestGTDriverSupports(t *testing.T) {
 		{FeatureCosts, true},
 		{FeaturePatrol, true},
 		{FeatureSSE, false},
+		{FeatureTranscript, true},
 		{Feature(999), false}, // unknown feature
 	}
 	for _, tt := range tests {
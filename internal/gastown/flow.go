@@ -0,0 +1,234 @@
+package gastown
+
+import (
+	"sort"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+// minFlowSamples is how many closed issues a type needs before its own P85
+// cycle time is trusted as an aging threshold; below it the overall P85 is
+// used.
+const minFlowSamples = 5
+
+// FlowDimension is a breakdown axis for flow metrics.
+type FlowDimension int
+
+const (
+	FlowByType FlowDimension = iota
+	FlowByPriority
+	FlowByLabel
+	FlowByAssignee
+	flowDimensions // count
+)
+
+// FlowDimensions lists the breakdowns in display order.
+var FlowDimensions = []FlowDimension{FlowByType, FlowByPriority, FlowByLabel, FlowByAssignee}
+
+func (d FlowDimension) String() string {
+	switch d {
+	case FlowByType:
+		return "type"
+	case FlowByPriority:
+		return "priority"
+	case FlowByLabel:
+		return "label"
+	case FlowByAssignee:
+		return "assignee"
+	}
+	return "unknown"
+}
+
+// FlowStats summarizes a set of durations.
+type FlowStats struct {
+	Count int
+	P50   time.Duration
+	P85   time.Duration
+	P95   time.Duration
+	Max   time.Duration
+}
+
+// FlowSample is one closed issue's timing. Cycle time runs from StartedAt to
+// ClosedAt and is only known when bd recorded a start; lead time runs from
+// CreatedAt to ClosedAt.
+type FlowSample struct {
+	IssueID  string
+	Closed   time.Time
+	Lead     time.Duration
+	Cycle    time.Duration
+	HasCycle bool
+}
+
+// FlowGroup is the cycle and lead time distribution for one breakdown key
+// ("bug", "P1", a label, an assignee). Samples index into FlowMetrics.Samples.
+type FlowGroup struct {
+	Key     string
+	Cycle   FlowStats
+	Lead    FlowStats
+	Samples []int
+}
+
+// AgingItem is an in-progress issue that has been worked longer than its
+// type's P85 cycle time: 85% of similar work finished sooner.
+type AgingItem struct {
+	IssueID   string
+	Title     string
+	Type      data.IssueType
+	Assignee  string
+	Age       time.Duration // since StartedAt
+	Threshold time.Duration // the P85 cycle time it exceeded
+}
+
+// FlowMetrics holds cycle and lead time distributions overall and per
+// breakdown, plus aging work-in-progress warnings.
+type FlowMetrics struct {
+	Overall FlowGroup
+	Samples []FlowSample
+	Aging   []AgingItem
+
+	groups [flowDimensions][]FlowGroup
+}
+
+// Groups returns the breakdown for d, largest groups first.
+func (f *FlowMetrics) Groups(d FlowDimension) []FlowGroup {
+	if f == nil || d < 0 || d >= flowDimensions {
+		return nil
+	}
+	return f.groups[d]
+}
+
+// ComputeFlow derives cycle and lead time distributions from closed issues
+// and flags in-progress issues older than their type's P85 cycle time.
+func ComputeFlow(issues []data.Issue, now time.Time) *FlowMetrics {
+	f := &FlowMetrics{Overall: FlowGroup{Key: "all"}}
+	var keyed [flowDimensions]map[string][]int
+	for d := range keyed {
+		keyed[d] = make(map[string][]int)
+	}
+
+	for _, iss := range issues {
+		if iss.Status != data.StatusClosed || iss.ClosedAt == nil {
+			continue
+		}
+		s := FlowSample{IssueID: iss.ID, Closed: *iss.ClosedAt, Lead: iss.ClosedAt.Sub(iss.CreatedAt)}
+		if s.Lead < 0 || iss.CreatedAt.IsZero() {
+			continue
+		}
+		if iss.StartedAt != nil && !iss.ClosedAt.Before(*iss.StartedAt) {
+			s.Cycle = iss.ClosedAt.Sub(*iss.StartedAt)
+			s.HasCycle = true
+		}
+		idx := len(f.Samples)
+		f.Samples = append(f.Samples, s)
+		f.Overall.Samples = append(f.Overall.Samples, idx)
+
+		keyed[FlowByType][string(iss.IssueType)] = append(keyed[FlowByType][string(iss.IssueType)], idx)
+		prio := data.PriorityLabel(iss.Priority)
+		keyed[FlowByPriority][prio] = append(keyed[FlowByPriority][prio], idx)
+		for _, l := range iss.Labels {
+			keyed[FlowByLabel][l] = append(keyed[FlowByLabel][l], idx)
+		}
+		who := iss.Assignee
+		if who == "" {
+			who = "unassigned"
+		}
+		keyed[FlowByAssignee][who] = append(keyed[FlowByAssignee][who], idx)
+	}
+
+	f.Overall.Cycle, f.Overall.Lead = f.stats(f.Overall.Samples)
+	for d := range keyed {
+		for key, idxs := range keyed[d] {
+			g := FlowGroup{Key: key, Samples: idxs}
+			g.Cycle, g.Lead = f.stats(idxs)
+			f.groups[d] = append(f.groups[d], g)
+		}
+		sort.Slice(f.groups[d], func(i, j int) bool {
+			a, b := f.groups[d][i], f.groups[d][j]
+			if FlowDimension(d) == FlowByPriority {
+				return a.Key < b.Key // P0 first
+			}
+			if len(a.Samples) != len(b.Samples) {
+				return len(a.Samples) > len(b.Samples)
+			}
+			return a.Key < b.Key
+		})
+	}
+
+	f.Aging = f.aging(issues, now)
+	return f
+}
+
+// stats computes cycle and lead time statistics over the given samples.
+func (f *FlowMetrics) stats(idxs []int) (cycle, lead FlowStats) {
+	var cs, ls []time.Duration
+	for _, i := range idxs {
+		s := f.Samples[i]
+		ls = append(ls, s.Lead)
+		if s.HasCycle {
+			cs = append(cs, s.Cycle)
+		}
+	}
+	return flowStats(cs), flowStats(ls)
+}
+
+func (f *FlowMetrics) aging(issues []data.Issue, now time.Time) []AgingItem {
+	threshold := func(t data.IssueType) time.Duration {
+		for _, g := range f.groups[FlowByType] {
+			if g.Key == string(t) && g.Cycle.Count >= minFlowSamples {
+				return g.Cycle.P85
+			}
+		}
+		if f.Overall.Cycle.Count >= minFlowSamples {
+			return f.Overall.Cycle.P85
+		}
+		return 0
+	}
+
+	var out []AgingItem
+	for _, iss := range issues {
+		if iss.Status != data.StatusInProgress || iss.StartedAt == nil {
+			continue
+		}
+		limit := threshold(iss.IssueType)
+		age := now.Sub(*iss.StartedAt)
+		if limit <= 0 || age <= limit {
+			continue
+		}
+		out = append(out, AgingItem{
+			IssueID:   iss.ID,
+			Title:     iss.Title,
+			Type:      iss.IssueType,
+			Assignee:  iss.Assignee,
+			Age:       age,
+			Threshold: limit,
+		})
+	}
+	// Most overdue (relative to its own threshold) first.
+	sort.Slice(out, func(i, j int) bool {
+		return float64(out[i].Age)/float64(out[i].Threshold) > float64(out[j].Age)/float64(out[j].Threshold)
+	})
+	return out
+}
+
+func flowStats(ds []time.Duration) FlowStats {
+	if len(ds) == 0 {
+		return FlowStats{}
+	}
+	return FlowStats{
+		Count: len(ds),
+		P50:   data.Percentile(ds, 0.50),
+		P85:   data.Percentile(ds, 0.85),
+		P95:   data.Percentile(ds, 0.95),
+		Max:   data.Percentile(ds, 1),
+	}
+}
+
+// FormatSpan renders a duration compactly for flow tables ("45m", "5.5h",
+// "2.0d", "3w"), in the same form as convoy ETA labels.
+func FormatSpan(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return formatETA(d)
+}
@@ -0,0 +1,95 @@
+package gastown
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+func flowIssue(id string, typ data.IssueType, created time.Time, started, closed time.Duration) data.Issue {
+	iss := data.Issue{ID: id, IssueType: typ, Priority: data.PriorityMedium, Status: data.StatusClosed, CreatedAt: created}
+	s := created.Add(started)
+	c := created.Add(closed)
+	iss.StartedAt, iss.ClosedAt = &s, &c
+	return iss
+}
+
+func TestComputeFlowStats(t *testing.T) {
+	base := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	var issues []data.Issue
+	// Ten tasks: started an hour after creation, cycle times 1h..10h.
+	for i := 1; i <= 10; i++ {
+		iss := flowIssue("mg-"+string(rune('a'+i)), data.TypeTask, base, time.Hour, time.Hour+time.Duration(i)*time.Hour)
+		if i%2 == 0 {
+			iss.Labels = []string{"backend"}
+			iss.Assignee = "atlas"
+		}
+		issues = append(issues, iss)
+	}
+	bug := flowIssue("mg-bug", data.TypeBug, base, 0, 48*time.Hour)
+	bug.StartedAt = nil // no recorded start: lead time only
+	bug.Priority = data.PriorityCritical
+	issues = append(issues, bug, data.Issue{ID: "mg-open", Status: data.StatusOpen, CreatedAt: base})
+
+	f := ComputeFlow(issues, base.Add(72*time.Hour))
+	if f.Overall.Lead.Count != 11 || f.Overall.Cycle.Count != 10 {
+		t.Fatalf("overall counts lead %d cycle %d, want 11 and 10", f.Overall.Lead.Count, f.Overall.Cycle.Count)
+	}
+	if f.Overall.Cycle.P50 != 5*time.Hour || f.Overall.Cycle.P85 != 9*time.Hour || f.Overall.Cycle.Max != 10*time.Hour {
+		t.Errorf("cycle stats = %+v, want P50 5h P85 9h max 10h", f.Overall.Cycle)
+	}
+
+	types := f.Groups(FlowByType)
+	if len(types) != 2 || types[0].Key != "task" || types[1].Key != "bug" {
+		t.Fatalf("type groups = %+v, want task then bug", types)
+	}
+	if types[1].Cycle.Count != 0 || types[1].Lead.P50 != 48*time.Hour {
+		t.Errorf("bug group = %+v, want lead only", types[1])
+	}
+	if prios := f.Groups(FlowByPriority); prios[0].Key != "P0" {
+		t.Errorf("priority groups should sort P0 first, got %+v", prios)
+	}
+	labels := f.Groups(FlowByLabel)
+	if len(labels) != 1 || labels[0].Key != "backend" || labels[0].Cycle.Count != 5 {
+		t.Errorf("label groups = %+v, want backend with 5", labels)
+	}
+	who := f.Groups(FlowByAssignee)
+	if len(who) != 2 || who[0].Key != "unassigned" || who[1].Key != "atlas" {
+		t.Errorf("assignee groups = %+v", who)
+	}
+}
+
+func TestComputeFlowAging(t *testing.T) {
+	base := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	var issues []data.Issue
+	for i := range 6 {
+		issues = append(issues, flowIssue("mg-"+string(rune('a'+i)), data.TypeBug, base, 0, 4*time.Hour))
+	}
+	now := base.Add(30 * 24 * time.Hour)
+	old := now.Add(-10 * time.Hour)
+	fresh := now.Add(-time.Hour)
+	issues = append(issues,
+		data.Issue{ID: "mg-old", Title: "Old bug", IssueType: data.TypeBug, Status: data.StatusInProgress, StartedAt: &old},
+		data.Issue{ID: "mg-fresh", IssueType: data.TypeBug, Status: data.StatusInProgress, StartedAt: &fresh},
+		// Too few features of its own: falls back to the overall P85.
+		data.Issue{ID: "mg-feat", IssueType: data.TypeFeature, Status: data.StatusInProgress, StartedAt: &old},
+	)
+
+	f := ComputeFlow(issues, now)
+	if len(f.Aging) != 2 {
+		t.Fatalf("aging = %+v, want mg-old and mg-feat", f.Aging)
+	}
+	if f.Aging[0].Threshold != 4*time.Hour || f.Aging[0].Age != 10*time.Hour {
+		t.Errorf("aging item = %+v, want 10h over a 4h P85", f.Aging[0])
+	}
+}
+
+func TestFormatSpan(t *testing.T) {
+	if got := FormatSpan(0); got != "-" {
+		t.Errorf("FormatSpan(0) = %q", got)
+	}
+	if got := FormatSpan(36 * time.Hour); got != "1.5d" {
+		t.Errorf("FormatSpan(36h) = %q", got)
+	}
+}
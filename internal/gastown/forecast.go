@@ -0,0 +1,252 @@
+package gastown
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+// Forecast defaults. The window is long enough to smooth over a quiet week
+// but short enough to track a team's current pace.
+const (
+	DefaultForecastWindow = 30   // days of close history sampled
+	DefaultForecastTrials = 5000 // simulated futures per target
+
+	// maxForecastDays bounds a single simulated future so a window with a
+	// handful of closes cannot spin on a huge backlog.
+	maxForecastDays = 3650
+)
+
+// ForecastOptions tunes the Monte Carlo forecaster. Zero fields take the
+// defaults above.
+type ForecastOptions struct {
+	Window int    // days of close history to sample (see Forecasts)
+	Trials int    // simulated futures per target
+	Seed   uint64 // RNG seed for reproducible runs; 0 seeds from the clock
+}
+
+func (o ForecastOptions) withDefaults() ForecastOptions {
+	if o.Window <= 0 {
+		o.Window = DefaultForecastWindow
+	}
+	if o.Trials <= 0 {
+		o.Trials = DefaultForecastTrials
+	}
+	if o.Seed == 0 {
+		o.Seed = uint64(time.Now().UnixNano())
+	}
+	return o
+}
+
+// Forecast kinds.
+const (
+	ForecastConvoy = "convoy"
+	ForecastEpic   = "epic"
+	ForecastQuery  = "query"
+)
+
+// Forecast is a Monte Carlo completion forecast for one body of work: the
+// remaining item count is burned down by drawing a past day's close count at
+// random for each future day, many times over, and the percentiles of the
+// resulting finish days give dates that are 50%, 85% and 95% likely.
+type Forecast struct {
+	Kind      string // ForecastConvoy, ForecastEpic or ForecastQuery
+	ID        string // convoy or epic id, or the filter query
+	Title     string
+	Remaining int
+
+	// Known is false when the window holds no closes to sample from; the
+	// dates are then zero.
+	Known bool
+	P50   time.Time
+	P85   time.Time
+	P95   time.Time
+
+	// Histogram counts trials by finish day: index 0 is today, 1 tomorrow.
+	// Trials that hit the simulation cap land in the last bucket.
+	Histogram []int
+	Trials    int
+}
+
+// PercentileLabel renders a forecast date relative to now ("today",
+// "Jun 12 (3d)"), or "unknown" when there is no forecast.
+func (f Forecast) PercentileLabel(t, now time.Time) string {
+	if !f.Known || t.IsZero() {
+		return "unknown"
+	}
+	days := int(startOfDay(t).Sub(startOfDay(now)).Hours() / 24)
+	if days <= 0 {
+		return "today"
+	}
+	return fmt.Sprintf("%s (%dd)", t.Format("Jan 02"), days)
+}
+
+// DailyThroughput counts issues closed on each of the last window days,
+// oldest first, ending with today.
+func DailyThroughput(issues []data.Issue, window int, now time.Time) []int {
+	if window <= 0 {
+		window = DefaultForecastWindow
+	}
+	counts := make([]int, window)
+	today := startOfDay(now)
+	for _, iss := range issues {
+		if iss.Status != data.StatusClosed || iss.ClosedAt == nil {
+			continue
+		}
+		dayIdx := int(today.Sub(startOfDay(*iss.ClosedAt)).Hours() / 24)
+		if dayIdx >= 0 && dayIdx < window {
+			counts[window-1-dayIdx]++
+		}
+	}
+	return counts
+}
+
+// SimulateCompletion runs the Monte Carlo burn-down of remaining items
+// against sampled daily throughput and fills the forecast's dates and
+// histogram. Finish dates are calendar days counted from now's day.
+func SimulateCompletion(remaining int, throughput []int, opts ForecastOptions, now time.Time) Forecast {
+	opts = opts.withDefaults()
+	f := Forecast{Remaining: remaining, Trials: opts.Trials}
+	if remaining <= 0 {
+		return f
+	}
+	var total int
+	for _, n := range throughput {
+		total += n
+	}
+	if total == 0 {
+		return f
+	}
+
+	rng := rand.New(rand.NewPCG(opts.Seed, uint64(remaining)))
+	finish := make([]int, opts.Trials)
+	for trial := range finish {
+		done, day := 0, 0
+		for day < maxForecastDays {
+			done += throughput[rng.IntN(len(throughput))]
+			if done >= remaining {
+				break
+			}
+			day++
+		}
+		finish[trial] = day
+	}
+	sort.Ints(finish)
+
+	f.Histogram = make([]int, finish[len(finish)-1]+1)
+	for _, d := range finish {
+		f.Histogram[d]++
+	}
+	today := startOfDay(now)
+	at := func(p float64) time.Time {
+		idx := max(int(math.Ceil(float64(len(finish))*p))-1, 0)
+		return today.AddDate(0, 0, finish[idx])
+	}
+	f.Known = true
+	f.P50, f.P85, f.P95 = at(0.50), at(0.85), at(0.95)
+	return f
+}
+
+// ForecastConvoys forecasts every open convoy's remaining tracked issues.
+func ForecastConvoys(convoys []ConvoyDetail, throughput []int, opts ForecastOptions, now time.Time) []Forecast {
+	var out []Forecast
+	for _, c := range convoys {
+		remaining := c.Total - c.Completed
+		if c.Status == "closed" || remaining <= 0 {
+			continue
+		}
+		f := SimulateCompletion(remaining, throughput, opts, now)
+		f.Kind, f.ID, f.Title = ForecastConvoy, c.ID, c.Title
+		out = append(out, f)
+	}
+	return out
+}
+
+// ForecastEpics forecasts every open epic with unfinished children.
+func ForecastEpics(issues []data.Issue, throughput []int, opts ForecastOptions, now time.Time) []Forecast {
+	var out []Forecast
+	for _, iss := range issues {
+		if iss.IssueType != data.TypeEpic || iss.Status == data.StatusClosed {
+			continue
+		}
+		remaining := 0
+		for _, child := range data.Descendants(issues, iss.ID) {
+			if child.Status != data.StatusClosed {
+				remaining++
+			}
+		}
+		if remaining == 0 {
+			continue
+		}
+		f := SimulateCompletion(remaining, throughput, opts, now)
+		f.Kind, f.ID, f.Title = ForecastEpic, iss.ID, iss.Title
+		out = append(out, f)
+	}
+	return out
+}
+
+// ForecastFilter forecasts the unfinished issues matching a parade filter
+// query, so any saved or ad-hoc view can be forecast like an epic.
+func ForecastFilter(issues []data.Issue, query string, throughput []int, opts ForecastOptions, now time.Time) Forecast {
+	remaining := 0
+	for _, iss := range data.FilterIssues(issues, query) {
+		if iss.Status != data.StatusClosed {
+			remaining++
+		}
+	}
+	f := SimulateCompletion(remaining, throughput, opts, now)
+	f.Kind, f.ID, f.Title = ForecastQuery, query, query
+	return f
+}
+
+// ForecastSeed derives a seed for Forecasts from its inputs: the issues,
+// convoys, query and the day. Forecasting the same data twice on one day
+// gives the same dates, and a changed seed is a cheap sign that anything
+// changed that the forecasts depend on. It hashes a fingerprint rather than
+// the full data, so it can run on every refresh: each issue's ID, status
+// and timestamps (bd bumps updated_at on any edit), and each convoy's
+// progress and tracked statuses.
+func ForecastSeed(issues []data.Issue, convoys []ConvoyDetail, query string, now time.Time) uint64 {
+	h := fnv.New64a()
+	var buf []byte
+	for _, iss := range issues {
+		buf = append(buf[:0], iss.ID...)
+		buf = append(buf, 0)
+		buf = append(buf, iss.Status...)
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(iss.UpdatedAt.UnixNano()))
+		if iss.ClosedAt != nil {
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(iss.ClosedAt.UnixNano()))
+		}
+		_, _ = h.Write(buf)
+	}
+	for _, c := range convoys {
+		_, _ = fmt.Fprintf(h, "\x01%s\x00%s\x00%d\x00%d", c.ID, c.Status, c.Completed, c.Total)
+		for _, t := range c.Tracked {
+			_, _ = fmt.Fprintf(h, "\x02%s\x00%s", t.ID, t.Status)
+		}
+	}
+	_, _ = fmt.Fprintf(h, "\x03%s\x00%s", query, now.Format(time.DateOnly))
+	if seed := h.Sum64(); seed != 0 {
+		return seed
+	}
+	return 1 // 0 means "seed from the clock"
+}
+
+// Forecasts samples the last opts.Window days of closes once and forecasts
+// every open convoy and epic, plus the filter query when one is given.
+func Forecasts(issues []data.Issue, convoys []ConvoyDetail, query string, opts ForecastOptions, now time.Time) []Forecast {
+	opts = opts.withDefaults()
+	throughput := DailyThroughput(issues, opts.Window, now)
+	out := ForecastConvoys(convoys, throughput, opts, now)
+	out = append(out, ForecastEpics(issues, throughput, opts, now)...)
+	if query != "" {
+		out = append(out, ForecastFilter(issues, query, throughput, opts, now))
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package gastown
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+func closedOn(id string, at time.Time) data.Issue {
+	return data.Issue{ID: id, Status: data.StatusClosed, ClosedAt: &at}
+}
+
+func TestDailyThroughput(t *testing.T) {
+	now := time.Date(2026, 6, 10, 15, 0, 0, 0, time.Local)
+	issues := []data.Issue{
+		closedOn("mg-1", now.Add(-time.Hour)),
+		closedOn("mg-2", now.Add(-2*time.Hour)),
+		closedOn("mg-3", now.AddDate(0, 0, -2)),
+		closedOn("mg-4", now.AddDate(0, 0, -10)), // outside the window
+		{ID: "mg-5", Status: data.StatusOpen},
+	}
+	got := DailyThroughput(issues, 3, now)
+	want := []int{1, 0, 2}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("DailyThroughput = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSimulateCompletionConstantRate(t *testing.T) {
+	now := time.Date(2026, 6, 10, 9, 0, 0, 0, time.Local)
+	// Exactly two closes every day: 5 items finish on the third day.
+	f := SimulateCompletion(5, []int{2, 2, 2}, ForecastOptions{Trials: 100, Seed: 1}, now)
+	want := startOfDay(now).AddDate(0, 0, 2)
+	if !f.Known || !f.P50.Equal(want) || !f.P95.Equal(want) {
+		t.Fatalf("forecast = %+v, want every percentile on %v", f, want)
+	}
+	if len(f.Histogram) != 3 || f.Histogram[2] != 100 {
+		t.Errorf("histogram = %v, want all 100 trials on day 2", f.Histogram)
+	}
+	if got := f.PercentileLabel(f.P50, now); got != want.Format("Jan 02")+" (2d)" {
+		t.Errorf("PercentileLabel = %q", got)
+	}
+}
+
+func TestSimulateCompletionPercentilesOrdered(t *testing.T) {
+	now := time.Now()
+	f := SimulateCompletion(20, []int{0, 1, 3, 0, 5, 2, 0}, ForecastOptions{Trials: 2000, Seed: 7}, now)
+	if !f.Known {
+		t.Fatal("expected a forecast")
+	}
+	if f.P50.After(f.P85) || f.P85.After(f.P95) {
+		t.Errorf("percentiles out of order: P50 %v P85 %v P95 %v", f.P50, f.P85, f.P95)
+	}
+	total := 0
+	for _, n := range f.Histogram {
+		total += n
+	}
+	if total != 2000 {
+		t.Errorf("histogram holds %d trials, want 2000", total)
+	}
+	again := SimulateCompletion(20, []int{0, 1, 3, 0, 5, 2, 0}, ForecastOptions{Trials: 2000, Seed: 7}, now)
+	if !again.P85.Equal(f.P85) {
+		t.Error("same seed should reproduce the forecast")
+	}
+}
+
+func TestSimulateCompletionNoHistory(t *testing.T) {
+	f := SimulateCompletion(3, []int{0, 0, 0}, ForecastOptions{}, time.Now())
+	if f.Known || f.PercentileLabel(f.P50, time.Now()) != "unknown" {
+		t.Errorf("no closes in the window should give no forecast, got %+v", f)
+	}
+}
+
+func TestForecastTargets(t *testing.T) {
+	now := time.Now()
+	throughput := []int{1, 1}
+	opts := ForecastOptions{Trials: 10, Seed: 1}
+
+	convoys := []ConvoyDetail{
+		{ID: "cv-1", Title: "Open", Status: "open", Total: 4, Completed: 1},
+		{ID: "cv-2", Status: "closed", Total: 2, Completed: 2},
+	}
+	if got := ForecastConvoys(convoys, throughput, opts, now); len(got) != 1 || got[0].ID != "cv-1" || got[0].Remaining != 3 {
+		t.Errorf("ForecastConvoys = %+v, want cv-1 with 3 remaining", got)
+	}
+
+	issues := []data.Issue{
+		{ID: "mg-1", Title: "Epic", IssueType: data.TypeEpic, Status: data.StatusOpen},
+		{ID: "mg-1.1", Status: data.StatusOpen},
+		{ID: "mg-1.2", Status: data.StatusClosed},
+		{ID: "mg-2", Title: "Done epic", IssueType: data.TypeEpic, Status: data.StatusOpen},
+		{ID: "mg-3", IssueType: data.TypeBug, Status: data.StatusOpen},
+	}
+	epics := ForecastEpics(issues, throughput, opts, now)
+	if len(epics) != 1 || epics[0].ID != "mg-1" || epics[0].Remaining != 1 {
+		t.Errorf("ForecastEpics = %+v, want mg-1 with 1 remaining", epics)
+	}
+	if f := ForecastFilter(issues, "type:bug", throughput, opts, now); f.Remaining != 1 || f.Kind != ForecastQuery {
+		t.Errorf("ForecastFilter = %+v, want 1 remaining bug", f)
+	}
+}
+
+func TestForecastSeed(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	issues := []data.Issue{closedOn("mg-1", now.AddDate(0, 0, -1)), {ID: "mg-2", Status: data.StatusOpen}}
+	convoys := []ConvoyDetail{{ID: "cv-1", Total: 2}}
+
+	seed := ForecastSeed(issues, convoys, "", now)
+	if seed == 0 || ForecastSeed(issues, convoys, "", now.Add(6*time.Hour)) != seed {
+		t.Fatal("same data on the same day should give the same seed")
+	}
+	edited := slices.Clone(issues)
+	edited[1].UpdatedAt = now
+	progressed := []ConvoyDetail{{ID: "cv-1", Total: 2, Completed: 1}}
+	for name, other := range map[string]uint64{
+		"issue":    ForecastSeed(issues[:1], convoys, "", now),
+		"edit":     ForecastSeed(edited, convoys, "", now),
+		"progress": ForecastSeed(issues, progressed, "", now),
+		"convoy":   ForecastSeed(issues, nil, "", now),
+		"query":    ForecastSeed(issues, convoys, "p1", now),
+		"day":      ForecastSeed(issues, convoys, "", now.AddDate(0, 0, 1)),
+	} {
+		if other == seed {
+			t.Errorf("changing the %s kept the seed", name)
+		}
+	}
+
+	opts := ForecastOptions{Trials: 200, Seed: seed}
+	a := Forecasts(issues, convoys, "", opts, now)
+	b := Forecasts(issues, convoys, "", opts, now)
+	if len(a) != 1 || !a[0].P85.Equal(b[0].P85) {
+		t.Fatalf("seeded forecasts differ: %+v vs %+v", a, b)
+	}
+}
@@ -1,6 +1,7 @@
 package gastown
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -79,16 +80,26 @@ func gcParseSupervisorLog(log string) string {
 }
 
 // SelectDriver returns the orchestrator driver mg should use. It defaults to
-// the Gas Town CLI driver and returns a Gas City HTTP driver only when
-// GCEnabled() is true. If the Gas City client cannot be constructed (bad base
-// URL), it falls back to the Gas Town driver so mg still runs.
-func SelectDriver() Driver {
+// the Gas Town CLI driver. MG_DRIVER=exec:<path> selects an out-of-process
+// driver, and otherwise a Gas City HTTP driver is returned when GCEnabled()
+// is true. If the chosen driver cannot be constructed (binary fails to start
+// or handshake, bad base URL), it falls back to the Gas Town driver so mg
+// still runs, and returns the error alongside it so the misconfiguration can
+// be reported.
+func SelectDriver() (Driver, error) {
+	if path := ProcessDriverPath(); path != "" {
+		d, err := NewProcessDriver(path)
+		if err != nil {
+			return NewGTDriver(), fmt.Errorf("%s: %w", EnvDriver, err)
+		}
+		return d, nil
+	}
 	if !GCEnabled() {
-		return NewGTDriver()
+		return NewGTDriver(), nil
 	}
 	d, err := NewGCDriver(GCBaseURL(), strings.TrimSpace(os.Getenv(EnvGCCity)))
 	if err != nil {
-		return NewGTDriver()
+		return NewGTDriver(), fmt.Errorf("gas city driver: %w", err)
 	}
-	return d
+	return d, nil
 }
@@ -1,10 +1,13 @@
 package gastown
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -16,8 +19,8 @@ import (
 // Supervisor HTTP API (https://docs.gascityhall.com/reference/api) via the
 // generated gcclient package instead of shelling out to a CLI.
 //
-// The read path (roster), mail, formulas, sling, nudge/decommission, convoys,
-// and assign are implemented. What remains ErrUnsupported is either absent from
+// The read path (roster), mail, formulas, sling, nudge/decommission, session
+// transcripts, convoys, and assign are implemented. What remains ErrUnsupported is either absent from
 // the supervisor API (comments, unsling, cascade close, convoy land/watch, the
 // molecule DAG trio) or gt-only by nature (vitals/costs/patrol, plus the
 // gt-shaped recovery/handoff/activity features declared on the Feature enum) —
@@ -26,6 +29,7 @@ type GCDriver struct {
 	baseURL string
 	city    string // optional pin; "" = resolve the first running city
 	client  *gcclient.ClientWithResponses
+	hc      *http.Client // shared with client; used for endpoints gcclient does not generate
 }
 
 // Compile-time assurance that GCDriver satisfies the Driver interface.
@@ -39,16 +43,24 @@ func NewGCDriver(baseURL, city string) (*GCDriver, error) {
 	if err != nil {
 		return nil, fmt.Errorf("gc client: %w", err)
 	}
-	return &GCDriver{baseURL: baseURL, city: strings.TrimSpace(city), client: c}, nil
+	return &GCDriver{baseURL: baseURL, city: strings.TrimSpace(city), client: c, hc: httpClient}, nil
 }
 
 func (*GCDriver) Backend() string { return BackendGasCity }
 
-// Supports reports false for every optional feature today: vitals/costs/patrol
-// have no Gas City equivalent; recovery/handoff/activity-feed are gt-shaped
+// Supports reports true for the session transcript, nudge and decommission.
+// Vitals/costs/patrol and unsling have no Gas City equivalent, sling always
+// needs a target; recovery/handoff/activity-feed are gt-shaped
 // (they shell out to gt or read ~/gt/.events.jsonl) and would fail with a raw
 // exec error rather than cleanly; and the SSE stream lands in Phase 4.
-func (*GCDriver) Supports(Feature) bool { return false }
+func (*GCDriver) Supports(feature Feature) bool {
+	switch feature {
+	case FeatureTranscript, FeatureNudge, FeatureDecommission:
+		return true
+	default:
+		return false
+	}
+}
 
 // Status fetches the live agent roster over HTTP and adapts it to TownStatus.
 func (d *GCDriver) Status(ctx context.Context) (*TownStatus, error) {
@@ -488,6 +500,69 @@ func gcSessionMatches(s gcclient.SessionResponse, want string) bool {
 	return false
 }
 
+// SessionTranscript reads the agent's conversation via
+// GET /v0/city/{city}/session/{id}/transcript?format=conversation.
+//
+// The transcript operation is not in gcclient's generated subset (see
+// gcclient/config.yaml), so it is fetched with the driver's plain HTTP client
+// and decoded into a local shape that keeps only the fields mg renders.
+func (d *GCDriver) SessionTranscript(ctx context.Context, target string) (*SessionTranscript, error) {
+	city, err := d.resolveCity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sid, err := d.resolveSessionID(ctx, city, target)
+	if err != nil {
+		return nil, err
+	}
+	u := strings.TrimRight(d.baseURL, "/") + "/v0/city/" + url.PathEscape(city) +
+		"/session/" + url.PathEscape(sid) + "/transcript?format=conversation"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gc transcript: %w", err)
+	}
+	resp, err := d.hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gc transcript: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("gc transcript: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gc transcript: %s", gcRespErr(resp.StatusCode, body))
+	}
+	var tr gcTranscriptResponse
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return nil, fmt.Errorf("gc transcript: %w", err)
+	}
+	out := &SessionTranscript{Session: tr.ID, Provider: tr.Provider}
+	if out.Session == "" {
+		out.Session = sid
+	}
+	for _, t := range tr.Turns {
+		e := TranscriptEntry{Role: t.Role, Text: t.Text}
+		if ts, err := time.Parse(time.RFC3339, t.Timestamp); err == nil {
+			e.Time = ts
+		}
+		out.Entries = append(out.Entries, e)
+	}
+	return out, nil
+}
+
+// gcTranscriptResponse mirrors the SessionTranscriptConversationResponse
+// schema (conversation format only).
+type gcTranscriptResponse struct {
+	ID       string `json:"id"`
+	Provider string `json:"provider"`
+	Turns    []struct {
+		Role      string `json:"role"`
+		Text      string `json:"text"`
+		Timestamp string `json:"timestamp"`
+	} `json:"turns"`
+}
+
 // --- sling -----------------------------------------------------------------
 
 // Sling dispatches each issue via POST /v0/city/{city}/sling. Gas City requires
@@ -708,6 +783,40 @@ func (d *GCDriver) ConvoyCreateFromEpic(ctx context.Context, name, epicID string
 	return d.ConvoyCreate(ctx, name, ids)
 }
 
+// ConvoyAdd adds issues to an existing convoy via
+// POST /v0/city/{city}/convoy/{id}/add. Like the transcript, the operation is
+// outside gcclient's generated subset, so it goes through the plain client.
+func (d *GCDriver) ConvoyAdd(ctx context.Context, convoyID string, issueIDs []string) error {
+	city, err := d.resolveCity(ctx)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(struct {
+		Items []string `json:"items"`
+	}{issueIDs})
+	if err != nil {
+		return fmt.Errorf("gc convoy add: %w", err)
+	}
+	u := strings.TrimRight(d.baseURL, "/") + "/v0/city/" + url.PathEscape(city) +
+		"/convoy/" + url.PathEscape(convoyID) + "/add"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("gc convoy add: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GC-Request", gcRequestToken)
+	resp, err := d.hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("gc convoy add: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("gc convoy add: %w", err)
+	}
+	return gcMutationErr("gc convoy add", resp.StatusCode, body)
+}
+
 // ConvoyClose closes a convoy via POST /v0/city/{city}/convoy/{id}/close.
 func (d *GCDriver) ConvoyClose(ctx context.Context, convoyID string) error {
 	city, err := d.resolveCity(ctx)
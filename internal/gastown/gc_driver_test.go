@@ -54,6 +54,9 @@ func TestGCDriverSupports(t *testing.T) {
 			t.Errorf("Supports(%d) = true, want false", f)
 		}
 	}
+	if !d.Supports(FeatureTranscript) {
+		t.Error("Supports(FeatureTranscript) = false, want true")
+	}
 }
 
 func TestGCDriverStatus(t *testing.T) {
@@ -243,13 +246,14 @@ func TestGCParseSupervisorLog(t *testing.T) {
 }
 
 func TestSelectDriver(t *testing.T) {
+	t.Setenv(EnvDriver, "")
 	t.Setenv(EnvGCAPI, "")
-	if d := SelectDriver(); d.Backend() != "gastown" {
-		t.Errorf("without %s: Backend() = %q, want gastown", EnvGCAPI, d.Backend())
+	if d, err := SelectDriver(); err != nil || d.Backend() != "gastown" {
+		t.Errorf("without %s: Backend() = %q, err %v, want gastown", EnvGCAPI, d.Backend(), err)
 	}
 	t.Setenv(EnvGCAPI, "http://127.0.0.1:8080")
-	if d := SelectDriver(); d.Backend() != "gascity" {
-		t.Errorf("with %s: Backend() = %q, want gascity", EnvGCAPI, d.Backend())
+	if d, err := SelectDriver(); err != nil || d.Backend() != "gascity" {
+		t.Errorf("with %s: Backend() = %q, err %v, want gascity", EnvGCAPI, d.Backend(), err)
 	}
 }
 
@@ -419,6 +423,14 @@ func gcSessionServer(t *testing.T, lastCSRF, hitID *string) *httptest.Server {
 	}
 	mux.HandleFunc("/v0/city/mardi_gras/session/va-9/submit", func(w http.ResponseWriter, r *http.Request) { record(w, r, "va-9") })
 	mux.HandleFunc("/v0/city/mardi_gras/session/va-9/kill", func(w http.ResponseWriter, r *http.Request) { record(w, r, "va-9") })
+	mux.HandleFunc("/v0/city/mardi_gras/session/va-9/transcript", func(w http.ResponseWriter, r *http.Request) {
+		*hitID = "va-9?" + r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"va-9","template":"t","provider":"claude","format":"conversation","turns":[` +
+			`{"role":"user","text":"fix the parade float","timestamp":"2026-06-12T10:01:00Z"},` +
+			`{"role":"assistant","text":"On it.\nReading float.go"}` +
+			`]}`))
+	})
 	srv := httptest.NewServer(mux)
 	t.Cleanup(srv.Close)
 	return srv
@@ -454,6 +466,51 @@ func TestGCDriverDecommission(t *testing.T) {
 	}
 }
 
+func TestGCDriverSessionTranscript(t *testing.T) {
+	var csrf, hit string
+	srv := gcSessionServer(t, &csrf, &hit)
+	d, _ := NewGCDriver(srv.URL, "mardi_gras")
+	tr, err := d.SessionTranscript(context.Background(), "obsidian")
+	if err != nil {
+		t.Fatalf("SessionTranscript: %v", err)
+	}
+	if hit != "va-9?format=conversation" {
+		t.Errorf("transcript request = %q, want the running session in conversation format", hit)
+	}
+	if tr.Session != "va-9" || tr.Provider != "claude" {
+		t.Errorf("transcript = %+v, want session va-9 from claude", tr)
+	}
+	if len(tr.Entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(tr.Entries))
+	}
+	if tr.Entries[0].Role != "user" || tr.Entries[0].Time.IsZero() {
+		t.Errorf("entry[0] = %+v, want a timestamped user turn", tr.Entries[0])
+	}
+	if tr.Entries[1].Text != "On it.\nReading float.go" || !tr.Entries[1].Time.IsZero() {
+		t.Errorf("entry[1] = %+v, want multi-line assistant turn without a timestamp", tr.Entries[1])
+	}
+}
+
+func TestGCDriverSessionTranscriptNotFound(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/city/mardi_gras/sessions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"items":[{"id":"va-1","session_name":"obsidian","title":"obsidian","state":"active","provider":"claude","template":"t","created_at":"2026-06-12T10:00:00Z","attached":false,"running":true}],"total":1}`))
+	})
+	mux.HandleFunc("/v0/city/mardi_gras/session/va-1/transcript", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"detail":"no transcript for session","status":404}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	d, _ := NewGCDriver(srv.URL, "mardi_gras")
+	_, err := d.SessionTranscript(context.Background(), "obsidian")
+	if err == nil || !strings.Contains(err.Error(), "no transcript") {
+		t.Errorf("err = %v, want the problem detail surfaced", err)
+	}
+}
+
 func TestGCDriverNudgeNoSession(t *testing.T) {
 	var csrf, hit string
 	srv := gcSessionServer(t, &csrf, &hit)
@@ -559,6 +616,36 @@ func TestGCDriverConvoyCreateClose(t *testing.T) {
 	}
 }
 
+func TestGCDriverConvoyAdd(t *testing.T) {
+	var csrf string
+	var got struct {
+		Items []string `json:"items"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/city/mardi_gras/convoy/cv-1/add", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("convoy add: method = %s, want POST", r.Method)
+		}
+		csrf = r.Header.Get("X-GC-Request")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	d, _ := NewGCDriver(srv.URL, "mardi_gras")
+
+	if err := d.ConvoyAdd(context.Background(), "cv-1", []string{"b-3", "b-4"}); err != nil {
+		t.Fatalf("ConvoyAdd: %v", err)
+	}
+	if csrf == "" {
+		t.Error("X-GC-Request not sent on convoy add")
+	}
+	if len(got.Items) != 2 || got.Items[0] != "b-3" || got.Items[1] != "b-4" {
+		t.Errorf("convoy add items = %v, want [b-3 b-4]", got.Items)
+	}
+}
+
 func TestGCDriverSling(t *testing.T) {
 	var csrf, gotTarget, gotBead string
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
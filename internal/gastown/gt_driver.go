@@ -24,7 +24,8 @@ func (GTDriver) Backend() string { return BackendGasTown }
 func (GTDriver) Supports(feature Feature) bool {
 	switch feature {
 	case FeatureVitals, FeatureCosts, FeaturePatrol,
-		FeatureRecovery, FeatureHandoff, FeatureActivityFeed:
+		FeatureRecovery, FeatureHandoff, FeatureActivityFeed, FeatureTranscript,
+		FeatureNudge, FeatureUnsling, FeatureDecommission, FeatureAutoSling:
 		return true
 	case FeatureSSE:
 		return false
@@ -43,6 +44,10 @@ func (GTDriver) Comments(_ context.Context, issueID string) ([]Comment, error) {
 	return FetchComments(issueID)
 }
 
+func (GTDriver) SessionTranscript(_ context.Context, target string) (*SessionTranscript, error) {
+	return CaptureSessionHistory(target)
+}
+
 // Dispatch / lifecycle.
 
 // Sling fans the unified request back out to the matching gt sling variant,
@@ -98,6 +103,10 @@ func (GTDriver) ConvoyCreateFromEpic(_ context.Context, name, epicID string) (st
 	return ConvoyCreateFromEpic(name, epicID)
 }
 
+func (GTDriver) ConvoyAdd(_ context.Context, convoyID string, issueIDs []string) error {
+	return ConvoyAdd(convoyID, issueIDs)
+}
+
 func (GTDriver) ConvoyClose(_ context.Context, convoyID string) error { return ConvoyClose(convoyID) }
 
 func (GTDriver) ConvoyLand(_ context.Context, convoyID string) error { return ConvoyLand(convoyID) }
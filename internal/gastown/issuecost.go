@@ -0,0 +1,390 @@
+package gastown
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+// Hold sources: how attribution learned that an agent had an issue, most
+// direct first.
+const (
+	HoldSling    = "sling events"
+	HoldHook     = "live hook"
+	HoldAssignee = "assignee dates"
+)
+
+// IssueCost is the spend attributed to one issue (or, from RollupIssueCosts,
+// to an epic or convoy).
+//
+// gt reports cost by role and rig only, so the figure is reconstructed: first
+// the spans during which each agent held the issue, from sling and session
+// death events, live hooks, or failing those the issue's own start and close
+// dates; then each agent's share of its role's or rig's daily cost, divided
+// across the issues it held that day by time held. Tokens are the town's
+// daily tokens apportioned by the same dollar share.
+type IssueCost struct {
+	IssueID      string
+	Cost         float64
+	InputTokens  int
+	OutputTokens int
+	Hours        float64 // agent-hours the issue was held
+	Agents       []string
+
+	// Priced is false when no held time could be matched to cost data (the
+	// agent is not in the roster, or nothing was recorded for those days).
+	Priced bool
+
+	// Estimate is set when any step approximated: a role's cost split across
+	// several agents, an agent's day shared between issues, or holds taken
+	// from assignee dates. Method says which.
+	Estimate bool
+	Method   string
+}
+
+// Label formats the cost for display, prefixed "~" when it is an estimate.
+func (c IssueCost) Label() string {
+	if !c.Priced {
+		return "unpriced"
+	}
+	label := fmt.Sprintf("$%.2f", c.Cost)
+	if c.Estimate {
+		label = "~" + label
+	}
+	return label
+}
+
+// costHold is one span during which an agent held an issue.
+type costHold struct {
+	agent      string // roster name when known, else the raw actor
+	issue      string
+	start, end time.Time
+	source     string
+}
+
+// AttributeIssueCosts estimates what each issue cost from the agent roster,
+// the event log (any order), the recorded cost history and today's live
+// costs. Issues nobody held are absent from the result. The roster is
+// today's, so a past day is priced as if the same agents shared each role.
+func AttributeIssueCosts(issues []data.Issue, status *TownStatus, events []Event, history []CostSample, today *CostsOutput, now time.Time) map[string]IssueCost {
+	holds := issueHolds(issues, status, events, now)
+	if len(holds) == 0 {
+		return nil
+	}
+
+	// Lay out every day any hold touches, with today's live figures as the
+	// latest sample.
+	first := now
+	for _, h := range holds {
+		if h.start.Before(first) {
+			first = h.start
+		}
+	}
+	span := min(int(startOfDay(now).Sub(startOfDay(first)).Hours()/24+0.5)+1, MaxCostHistoryDays)
+	if today != nil {
+		history = append(slices.Clone(history), CostSample{At: now, CostsOutput: *today})
+	}
+	days := CostDays(history, span, now)
+
+	// Hours each agent held anything, per day, for time-sharing its cost.
+	type agentDay struct {
+		agent string
+		day   int
+	}
+	busy := make(map[agentDay]float64)
+	overlap := func(h costHold, i int) float64 {
+		from := days[i].Day
+		to := from.AddDate(0, 0, 1)
+		if h.start.After(from) {
+			from = h.start
+		}
+		if h.end.Before(to) {
+			to = h.end
+		}
+		return max(to.Sub(from).Hours(), 0)
+	}
+	for _, h := range holds {
+		for i := range days {
+			busy[agentDay{h.agent, i}] += overlap(h, i)
+		}
+	}
+
+	type acc struct {
+		IssueCost
+		agents, sources, bases map[string]bool
+		shared                 bool
+	}
+	accs := make(map[string]*acc)
+	for _, h := range holds {
+		a, ok := accs[h.issue]
+		if !ok {
+			a = &acc{
+				IssueCost: IssueCost{IssueID: h.issue},
+				agents:    make(map[string]bool),
+				sources:   make(map[string]bool),
+				bases:     make(map[string]bool),
+			}
+			accs[h.issue] = a
+		}
+		a.agents[h.agent] = true
+		a.sources[h.source] = true
+		if h.source == HoldAssignee {
+			a.Estimate = true
+		}
+		agent := rosterAgent(status, h.agent)
+		for i, d := range days {
+			hours := overlap(h, i)
+			if hours <= 0 {
+				continue
+			}
+			a.Hours += hours
+			if agent == nil || !d.Sampled {
+				continue
+			}
+			dayCost, basis, exact := attributeCost(*agent, status, d.ByRole, d.ByRig)
+			if basis == "" {
+				continue
+			}
+			share := hours / busy[agentDay{h.agent, i}]
+			if share < 0.999 {
+				a.shared = true
+				a.Estimate = true
+			}
+			if !exact {
+				a.Estimate = true
+			}
+			a.bases[basis] = true
+			cost := dayCost * share
+			a.Cost += cost
+			a.Priced = true
+			if d.Cost > 0 {
+				a.InputTokens += int(float64(d.InputTokens) * cost / d.Cost)
+				a.OutputTokens += int(float64(d.OutputTokens) * cost / d.Cost)
+			}
+		}
+	}
+
+	out := make(map[string]IssueCost, len(accs))
+	for id, a := range accs {
+		a.Agents = sortedKeys(a.agents)
+		parts := []string{strings.Join(orderedSources(a.sources), " + ")}
+		parts = append(parts, sortedKeys(a.bases)...)
+		if a.shared {
+			parts = append(parts, "time-shared with other issues")
+		}
+		if !a.Priced {
+			parts = append(parts, "no cost data for the agent or days")
+		}
+		a.Method = strings.Join(parts, " · ")
+		out[id] = a.IssueCost
+	}
+	return out
+}
+
+// RollupIssueCosts sums the costs of ids (an epic's descendants, a convoy's
+// tracked issues) under id, returning how many of them were priced.
+func RollupIssueCosts(costs map[string]IssueCost, id string, ids []string) (IssueCost, int) {
+	out := IssueCost{IssueID: id}
+	agents := make(map[string]bool)
+	priced := 0
+	for _, member := range ids {
+		c, ok := costs[member]
+		if !ok {
+			continue
+		}
+		out.Hours += c.Hours
+		for _, a := range c.Agents {
+			agents[a] = true
+		}
+		if !c.Priced {
+			continue
+		}
+		priced++
+		out.Priced = true
+		out.Cost += c.Cost
+		out.InputTokens += c.InputTokens
+		out.OutputTokens += c.OutputTokens
+		out.Estimate = out.Estimate || c.Estimate
+	}
+	out.Agents = sortedKeys(agents)
+	out.Method = fmt.Sprintf("sum of %d of %d issues", priced, len(ids))
+	return out, priced
+}
+
+// issueHolds reconstructs who held which issue when. Sling events open a hold
+// for their target and close the target's previous one; session deaths close
+// the actor's hold. Holds still open end now if the agent still hooks the
+// issue, else at the issue's close or last update. Live hooks with no sling
+// on record start at the issue's start, and issues nobody was seen holding
+// fall back to assignee and start/close dates. Every hold ends by the
+// issue's close.
+func issueHolds(issues []data.Issue, status *TownStatus, events []Event, now time.Time) []costHold {
+	issueMap := data.BuildIssueMap(issues)
+	resolve := func(who string) string {
+		if a := rosterAgent(status, who); a != nil {
+			return a.Name
+		}
+		return who
+	}
+	hooks := func(agent, issue string) bool {
+		a := rosterAgent(status, agent)
+		return a != nil && a.HookBead == issue
+	}
+
+	type timed struct {
+		at time.Time
+		ev Event
+	}
+	var evs []timed
+	for _, ev := range events {
+		if ev.Type != "sling" && ev.Type != "session_death" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, ev.Timestamp)
+		if err != nil {
+			continue
+		}
+		evs = append(evs, timed{t, ev})
+	}
+	sort.SliceStable(evs, func(i, j int) bool { return evs[i].at.Before(evs[j].at) })
+
+	var holds []costHold
+	open := make(map[string]*costHold)
+	closeHold := func(agent string, at time.Time) {
+		if h := open[agent]; h != nil {
+			h.end = at
+			holds = append(holds, *h)
+			delete(open, agent)
+		}
+	}
+	for _, e := range evs {
+		switch e.ev.Type {
+		case "sling":
+			bead := EventPayloadString(e.ev, "bead")
+			target := EventPayloadString(e.ev, "target")
+			if bead == "" || target == "" {
+				continue
+			}
+			agent := resolve(target)
+			closeHold(agent, e.at)
+			open[agent] = &costHold{agent: agent, issue: bead, start: e.at, source: HoldSling}
+		case "session_death":
+			closeHold(resolve(e.ev.Actor), e.at)
+		}
+	}
+	for agent, h := range open {
+		switch iss := issueMap[h.issue]; {
+		case hooks(agent, h.issue):
+			h.end = now
+		case iss != nil && iss.ClosedAt != nil:
+			h.end = *iss.ClosedAt
+		case iss != nil:
+			h.end = iss.UpdatedAt
+		}
+		holds = append(holds, *h)
+	}
+
+	held := make(map[string]bool)
+	for _, h := range holds {
+		held[h.agent+"\x00"+h.issue] = true
+	}
+	if status != nil {
+		for _, a := range status.Agents {
+			iss := issueMap[a.HookBead]
+			if iss == nil || held[a.Name+"\x00"+a.HookBead] {
+				continue
+			}
+			start := iss.UpdatedAt
+			if iss.StartedAt != nil {
+				start = *iss.StartedAt
+			}
+			holds = append(holds, costHold{agent: a.Name, issue: a.HookBead, start: start, end: now, source: HoldHook})
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, h := range holds {
+		seen[h.issue] = true
+	}
+	for _, iss := range issues {
+		if seen[iss.ID] || iss.Assignee == "" || iss.StartedAt == nil {
+			continue
+		}
+		end := now
+		if iss.ClosedAt != nil {
+			end = *iss.ClosedAt
+		} else if iss.Status != data.StatusInProgress {
+			continue
+		}
+		holds = append(holds, costHold{agent: resolve(iss.Assignee), issue: iss.ID, start: *iss.StartedAt, end: end, source: HoldAssignee})
+	}
+
+	// Clamp to the issue's close and drop empty spans.
+	kept := holds[:0]
+	for _, h := range holds {
+		if iss := issueMap[h.issue]; iss != nil && iss.ClosedAt != nil && iss.ClosedAt.Before(h.end) {
+			h.end = *iss.ClosedAt
+		}
+		if h.end.After(h.start) {
+			kept = append(kept, h)
+		}
+	}
+	return kept
+}
+
+// attributeCost finds the spend that belongs to one agent. gt reports costs
+// by role and by rig, so a figure is exact only when the agent is the sole
+// member of its role, or else of its rig; otherwise the agent gets an even
+// split of its role's cost, and exact is false. basis describes the figure
+// and is empty when nothing could be attributed.
+func attributeCost(agent AgentRuntime, status *TownStatus, byRole, byRig map[string]float64) (cost float64, basis string, exact bool) {
+	sameRole, sameRig := 0, 0
+	for _, a := range status.Agents {
+		if a.Role == agent.Role {
+			sameRole++
+		}
+		if a.Rig == agent.Rig {
+			sameRig++
+		}
+	}
+	roleCost, hasRole := byRole[agent.Role]
+	switch {
+	case sameRole == 1 && hasRole:
+		return roleCost, "sole " + agent.Role, true
+	case sameRig == 1 && agent.Rig != "":
+		if rigCost, ok := byRig[agent.Rig]; ok {
+			return rigCost, "sole agent on " + agent.Rig, true
+		}
+	}
+	if hasRole && sameRole > 0 {
+		return roleCost / float64(sameRole), fmt.Sprintf("%s cost split %d ways", agent.Role, sameRole), false
+	}
+	return 0, "", false
+}
+
+// rosterAgent finds the roster agent who names: by name, address or session.
+func rosterAgent(status *TownStatus, who string) *AgentRuntime {
+	if status == nil || who == "" {
+		return nil
+	}
+	for i := range status.Agents {
+		if status.Agents[i].Matches(who) || status.Agents[i].Session == who {
+			return &status.Agents[i]
+		}
+	}
+	return nil
+}
+
+func orderedSources(set map[string]bool) []string {
+	var out []string
+	for _, s := range []string{HoldSling, HoldHook, HoldAssignee} {
+		if set[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
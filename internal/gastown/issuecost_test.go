@@ -0,0 +1,131 @@
+package gastown
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+func slingEvent(at time.Time, bead, target string) Event {
+	return Event{Timestamp: at.Format(time.RFC3339), Type: "sling",
+		Payload: json.RawMessage(fmt.Sprintf(`{"bead":%q,"target":%q}`, bead, target))}
+}
+
+func TestAttributeIssueCosts(t *testing.T) {
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	at := func(hour int) time.Time { return time.Date(2026, 6, 20, hour, 0, 0, 0, time.UTC) }
+	started, yesterday, closed := at(6), now.AddDate(0, 0, -1), at(9)
+	issues := []data.Issue{
+		{ID: "mg-1", Status: data.StatusInProgress, UpdatedAt: at(10)},
+		{ID: "mg-2", Status: data.StatusInProgress, StartedAt: &started, UpdatedAt: at(7)},
+		{ID: "mg-3", Status: data.StatusOpen, UpdatedAt: at(11)},
+		{ID: "mg-4", Status: data.StatusClosed, Assignee: "Nobody", StartedAt: &yesterday, ClosedAt: &closed},
+	}
+	status := &TownStatus{Agents: []AgentRuntime{
+		{Name: "Toast", Role: "polecat", Rig: "gastown", Address: "gastown/polecats/Toast"},
+		{Name: "Muffin", Role: "polecat", Rig: "beads", Address: "beads/polecats/Muffin", HookBead: "mg-2"},
+	}}
+	today := &CostsOutput{
+		Total:  CostTotal{Cost: 8, InputTokens: 8000, OutputTokens: 800},
+		ByRole: []RoleCost{{Role: "polecat", Cost: 8}},
+		ByRig:  []RigCost{{Rig: "gastown", Cost: 6}, {Rig: "beads", Cost: 2}},
+	}
+	// Newest first, as the event log is kept.
+	events := []Event{
+		slingEvent(at(10), "mg-3", "gastown/polecats/Toast"),
+		slingEvent(at(8), "mg-1", "gastown/polecats/Toast"),
+	}
+
+	got := AttributeIssueCosts(issues, status, events, nil, today, now)
+
+	// Toast held mg-1 for 2h then mg-3 for 1h: the rig's $6 splits 2:1.
+	mg1 := got["mg-1"]
+	if math.Abs(mg1.Cost-4) > 0.001 || mg1.Hours != 2 || !mg1.Estimate {
+		t.Errorf("mg-1 = %+v, want ~$4 over 2h", mg1)
+	}
+	if !strings.Contains(mg1.Method, HoldSling) || !strings.Contains(mg1.Method, "time-shared") {
+		t.Errorf("mg-1 method = %q", mg1.Method)
+	}
+	if mg3 := got["mg-3"]; math.Abs(mg3.Cost-2) > 0.001 {
+		t.Errorf("mg-3 = %+v, want $2 (hold ends at last update)", mg3)
+	}
+
+	// Muffin hooks mg-2 with no sling on record, alone on its rig all day.
+	mg2 := got["mg-2"]
+	if mg2.Cost != 2 || mg2.Estimate || mg2.Hours != 6 || mg2.InputTokens != 2000 {
+		t.Errorf("mg-2 = %+v, want exact $2, 6h, 2000 tokens in", mg2)
+	}
+	if mg2.Label() != "$2.00" || !strings.HasPrefix(mg2.Method, HoldHook) {
+		t.Errorf("mg-2 label %q method %q", mg2.Label(), mg2.Method)
+	}
+
+	// Assignee outside the roster: held, but not priced.
+	mg4 := got["mg-4"]
+	if mg4.Priced || !mg4.Estimate || mg4.Hours != 21 || mg4.Label() != "unpriced" {
+		t.Errorf("mg-4 = %+v, want unpriced 21h estimate", mg4)
+	}
+
+	roll, priced := RollupIssueCosts(got, "convoy", []string{"mg-1", "mg-2", "mg-4", "mg-9"})
+	if priced != 2 || math.Abs(roll.Cost-6) > 0.001 || !roll.Estimate || roll.Label() != "~$6.00" {
+		t.Errorf("rollup = %+v (%d priced), want ~$6 from 2", roll, priced)
+	}
+}
+
+func TestAttributeIssueCostsFromHistory(t *testing.T) {
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.Local)
+	slung := now.AddDate(0, 0, -1)
+	closed := now.Add(-6 * time.Hour)
+	issues := []data.Issue{{ID: "mg-5", Status: data.StatusClosed, ClosedAt: &closed}}
+	status := &TownStatus{Agents: []AgentRuntime{{Name: "Toast", Role: "polecat", Rig: "gastown"}}}
+	history := []CostSample{costSample(slung, 10)}
+
+	got := AttributeIssueCosts(issues, status, []Event{slingEvent(slung, "mg-5", "Toast")}, history, nil, now)
+
+	// Only yesterday was recorded; Toast is the sole polecat.
+	mg5 := got["mg-5"]
+	if mg5.Cost != 7.5 || mg5.Estimate || mg5.Hours != 18 {
+		t.Errorf("mg-5 = %+v, want exact $7.50 over 18h", mg5)
+	}
+	if !strings.Contains(mg5.Method, "sole polecat") {
+		t.Errorf("mg-5 method = %q", mg5.Method)
+	}
+}
+
+func TestAttributeIssueCostsNoHolds(t *testing.T) {
+	if got := AttributeIssueCosts([]data.Issue{{ID: "mg-1", Status: data.StatusOpen}}, nil, nil, nil, nil, time.Now()); got != nil {
+		t.Fatalf("got %+v, want nil", got)
+	}
+}
+
+func TestAttributeCost(t *testing.T) {
+	status := &TownStatus{Agents: []AgentRuntime{
+		{Name: "Toast", Role: "polecat", Rig: "gastown"},
+		{Name: "Muffin", Role: "polecat", Rig: "beads"},
+		{Name: "Nux", Role: "polecat", Rig: "beads"},
+		{Name: "Slit", Role: "witness", Rig: "beads"},
+	}}
+	byRole := map[string]float64{"polecat": 9, "witness": 4}
+	byRig := map[string]float64{"gastown": 6, "beads": 7}
+	tests := []struct {
+		agent AgentRuntime
+		cost  float64
+		basis string
+		exact bool
+	}{
+		{status.Agents[3], 4, "sole witness", true},
+		{status.Agents[0], 6, "sole agent on gastown", true},
+		{status.Agents[1], 3, "polecat cost split 3 ways", false},
+		{AgentRuntime{Name: "Ghost", Role: "mayor"}, 0, "", false},
+	}
+	for _, tt := range tests {
+		cost, basis, exact := attributeCost(tt.agent, status, byRole, byRig)
+		if cost != tt.cost || basis != tt.basis || exact != tt.exact {
+			t.Errorf("%s: got %v %q %v, want %v %q %v", tt.agent.Name, cost, basis, exact, tt.cost, tt.basis, tt.exact)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package gastown
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/config"
+)
+
+// PatrolHistoryRetention is how far back the patrol history reaches. At one
+// scan a minute that is about 1440 samples.
+const PatrolHistoryRetention = 24 * time.Hour
+
+// PatrolSample is one `gt patrol scan` recorded to the patrol history. At is
+// when mg took it; the scan's own Timestamp is kept as gt reported it.
+type PatrolSample struct {
+	At time.Time `json:"at"`
+	PatrolScanResult
+}
+
+// Flagged counts the zombies and stalls the scan found.
+func (s PatrolSample) Flagged() int {
+	return s.Zombies.Found + s.Stalls.Found
+}
+
+// Checked is the number of agents the scan looked at.
+func (s PatrolSample) Checked() int {
+	return max(s.Zombies.Checked, s.Stalls.Checked)
+}
+
+// PatrolHistoryPath returns the patrol history path: MG_PATROL_HISTORY if
+// set, otherwise mardi-gras/patrol.jsonl under the user config directory.
+func PatrolHistoryPath() string {
+	return config.Path("MG_PATROL_HISTORY", "patrol.jsonl")
+}
+
+// LoadPatrolHistory reads the scans taken at or after since, oldest first. A
+// missing file is an empty history; unparseable lines are skipped.
+func LoadPatrolHistory(path string, since time.Time) ([]PatrolSample, error) {
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("patrol history: %w", err)
+	}
+	var out []PatrolSample
+	sc := bufio.NewScanner(bytes.NewReader(raw))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var s PatrolSample
+		if json.Unmarshal(sc.Bytes(), &s) != nil || s.At.IsZero() || s.At.Before(since) {
+			continue
+		}
+		out = append(out, s)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out, nil
+}
+
+// AddPatrolSample appends a scan to history and drops the scans that have
+// aged out of PatrolHistoryRetention at the new scan's time.
+func AddPatrolSample(history []PatrolSample, sample PatrolSample) []PatrolSample {
+	cutoff := sample.At.Add(-PatrolHistoryRetention)
+	kept := make([]PatrolSample, 0, len(history)+1)
+	for _, s := range history {
+		if !s.At.Before(cutoff) {
+			kept = append(kept, s)
+		}
+	}
+	return append(kept, sample)
+}
+
+// SavePatrolHistory replaces the history at path, atomically.
+func SavePatrolHistory(path string, history []PatrolSample) error {
+	if path == "" {
+		return fmt.Errorf("patrol history: no path")
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, s := range history {
+		if err := enc.Encode(s); err != nil {
+			return fmt.Errorf("patrol history: %w", err)
+		}
+	}
+	if err := config.WriteFileAtomic(path, buf.Bytes()); err != nil {
+		return fmt.Errorf("patrol history: %w", err)
+	}
+	return nil
+}
+
+// RigPatrolTimeline is one rig's patrol health over the history.
+type RigPatrolTimeline struct {
+	Rig string
+	// Flagged holds, per time bucket oldest first, the most agents any scan
+	// in the bucket flagged; -1 marks a bucket with no scan.
+	Flagged []int
+	Latest  PatrolSample
+	// HealthySince is when the rig's current run of clean scans began; zero
+	// while the latest scan flags anything.
+	HealthySince time.Time
+}
+
+// PatrolTimelines lays the history out per rig as buckets time buckets
+// spanning span and ending at now, rigs in name order.
+func PatrolTimelines(history []PatrolSample, buckets int, span time.Duration, now time.Time) []RigPatrolTimeline {
+	if buckets <= 0 || len(history) == 0 {
+		return nil
+	}
+	start := now.Add(-span)
+	width := span / time.Duration(buckets)
+	byRig := make(map[string]*RigPatrolTimeline)
+	for _, s := range history {
+		t := byRig[s.Rig]
+		if t == nil {
+			t = &RigPatrolTimeline{Rig: s.Rig, Flagged: make([]int, buckets)}
+			for i := range t.Flagged {
+				t.Flagged[i] = -1
+			}
+			byRig[s.Rig] = t
+		}
+		if !s.At.Before(t.Latest.At) {
+			t.Latest = s
+		}
+		switch {
+		case s.Flagged() > 0:
+			t.HealthySince = time.Time{}
+		case t.HealthySince.IsZero():
+			t.HealthySince = s.At
+		}
+		if s.At.Before(start) || s.At.After(now) {
+			continue
+		}
+		i := min(int(s.At.Sub(start)/width), buckets-1)
+		t.Flagged[i] = max(t.Flagged[i], s.Flagged())
+	}
+	out := make([]RigPatrolTimeline, 0, len(byRig))
+	for _, rig := range sortedKeys(byRig) {
+		out = append(out, *byRig[rig])
+	}
+	return out
+}
+
+// PatrolOutcome is one agent a patrol scan flagged, and what became of it.
+type PatrolOutcome struct {
+	PatrolDetail
+	// RecoveredAt is the first later scan of the rig that no longer flagged
+	// the agent; zero if none has.
+	RecoveredAt time.Time
+	// Pending is set when no later scan of the rig exists yet.
+	Pending bool
+}
+
+// PatrolOutcomes follows each zombie and stall in history[i] through the
+// later scans of the same rig to see whether the agent recovered. Scans
+// without details yield no outcomes.
+func PatrolOutcomes(history []PatrolSample, i int) []PatrolOutcome {
+	if i < 0 || i >= len(history) {
+		return nil
+	}
+	scan := history[i]
+	var later []PatrolSample
+	for _, s := range history[i+1:] {
+		if s.Rig == scan.Rig {
+			later = append(later, s)
+		}
+	}
+	var out []PatrolOutcome
+	for _, d := range scan.Details {
+		if d.Type == "completion" {
+			continue
+		}
+		o := PatrolOutcome{PatrolDetail: d, Pending: len(later) == 0}
+		for _, s := range later {
+			// A scan that found something but gave no details can't
+			// say who.
+			if s.Flagged() > 0 && len(s.Details) == 0 {
+				continue
+			}
+			if !s.flags(d.Agent, d.Type) {
+				o.RecoveredAt = s.At
+				break
+			}
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+func (s PatrolSample) flags(agent, typ string) bool {
+	for _, d := range s.Details {
+		if d.Agent == agent && d.Type == typ {
+			return true
+		}
+	}
+	return false
+}
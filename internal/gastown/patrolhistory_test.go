@@ -0,0 +1,91 @@
+package gastown
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func patrolSample(at time.Time, rig string, details ...PatrolDetail) PatrolSample {
+	s := PatrolSample{At: at, PatrolScanResult: PatrolScanResult{Rig: rig, Details: details}}
+	s.Zombies.Checked, s.Stalls.Checked = 4, 4
+	for _, d := range details {
+		switch d.Type {
+		case "zombie":
+			s.Zombies.Found++
+		case "stall":
+			s.Stalls.Found++
+		}
+	}
+	return s
+}
+
+func TestPatrolHistoryRoundTripAndRetention(t *testing.T) {
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	var history []PatrolSample
+	history = AddPatrolSample(history, patrolSample(now.Add(-25*time.Hour), "gt"))
+	history = AddPatrolSample(history, patrolSample(now.Add(-time.Hour), "gt", PatrolDetail{Type: "zombie", Agent: "Toast"}))
+	history = AddPatrolSample(history, patrolSample(now, "gt"))
+	if len(history) != 2 {
+		t.Fatalf("history = %d samples, want the scan past retention dropped", len(history))
+	}
+
+	path := filepath.Join(t.TempDir(), "patrol.jsonl")
+	if err := SavePatrolHistory(path, history); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadPatrolHistory(path, now.Add(-30*time.Minute))
+	if err != nil || len(got) != 1 || !got[0].At.Equal(now) {
+		t.Fatalf("LoadPatrolHistory since 30m = %+v, %v", got, err)
+	}
+	got, _ = LoadPatrolHistory(path, time.Time{})
+	if len(got) != 2 || got[0].Flagged() != 1 || got[0].Details[0].Agent != "Toast" || got[0].Checked() != 4 {
+		t.Fatalf("round trip = %+v", got)
+	}
+	if got, err := LoadPatrolHistory(filepath.Join(t.TempDir(), "none.jsonl"), time.Time{}); got != nil || err != nil {
+		t.Fatalf("missing file = %v, %v", got, err)
+	}
+}
+
+func TestPatrolTimelinesAndOutcomes(t *testing.T) {
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	toast := PatrolDetail{Type: "zombie", Agent: "Toast", HookBead: "mg-1"}
+	nux := PatrolDetail{Type: "stall", Agent: "Nux"}
+	history := []PatrolSample{
+		patrolSample(now.Add(-3*time.Hour), "gt", toast, nux),
+		patrolSample(now.Add(-150*time.Minute), "bd"),
+		patrolSample(now.Add(-2*time.Hour), "gt", nux),
+		patrolSample(now.Add(-90*time.Minute), "gt"),
+		patrolSample(now.Add(-time.Hour), "bd", PatrolDetail{Type: "stall", Agent: "Slit"}),
+	}
+
+	timelines := PatrolTimelines(history, 6, 6*time.Hour, now)
+	if len(timelines) != 2 || timelines[0].Rig != "bd" || timelines[1].Rig != "gt" {
+		t.Fatalf("timelines = %+v", timelines)
+	}
+	gt := timelines[1]
+	if want := []int{-1, -1, -1, 2, 1, -1}; !slices.Equal(gt.Flagged, want) {
+		t.Fatalf("gt buckets = %v, want %v", gt.Flagged, want)
+	}
+	if !gt.HealthySince.Equal(now.Add(-90*time.Minute)) || gt.Latest.Flagged() != 0 {
+		t.Fatalf("gt healthy since %v, latest %+v", gt.HealthySince, gt.Latest)
+	}
+	if !timelines[0].HealthySince.IsZero() {
+		t.Fatal("bd's latest scan flagged Slit, so it is not healthy")
+	}
+
+	outcomes := PatrolOutcomes(history, 0)
+	if len(outcomes) != 2 {
+		t.Fatalf("outcomes = %+v", outcomes)
+	}
+	if outcomes[0].Agent != "Toast" || !outcomes[0].RecoveredAt.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("Toast should recover at the next gt scan: %+v", outcomes[0])
+	}
+	if outcomes[1].Agent != "Nux" || !outcomes[1].RecoveredAt.Equal(now.Add(-90*time.Minute)) {
+		t.Errorf("Nux should recover a scan later: %+v", outcomes[1])
+	}
+	if o := PatrolOutcomes(history, 4); len(o) != 1 || !o[0].Pending {
+		t.Errorf("the latest bd scan has no later scan: %+v", o)
+	}
+}
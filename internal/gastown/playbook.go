@@ -0,0 +1,378 @@
+package gastown
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/config"
+)
+
+// Playbook actions.
+const (
+	PlaybookNudge   = "nudge"   // nudge the agent
+	PlaybookResling = "resling" // release the agent's hooked bead and sling it again
+	PlaybookRestart = "restart" // decommission a polecat and sling its hooked bead again
+)
+
+// Playbook defaults, used when the config leaves them unset.
+const (
+	DefaultPlaybookCooldown    = 30 * time.Minute
+	DefaultPlaybookMaxAttempts = 3
+	DefaultPlaybookMaxPerHour  = 6
+
+	defaultPlaybookNudge = "mg playbook: you have hooked work but look idle. Please pick it back up."
+)
+
+// playbookStateTTL is how long PlaybookState remembers a problem it last
+// acted on. A problem quiet for that long starts over with a fresh budget.
+const playbookStateTTL = 24 * time.Hour
+
+// PlaybookConfig is the remediation playbook configuration, read from
+// PlaybooksPath:
+//
+//	{
+//	  "dry_run": true,
+//	  "max_actions_per_hour": 6,
+//	  "playbooks": [
+//	    {"problem": "stalled", "action": "nudge", "after": "10m"},
+//	    {"problem": "zombie", "action": "resling", "max_attempts": 1},
+//	    {"problem": "backoff", "action": "restart", "after": "15m", "cooldown": "1h"}
+//	  ]
+//	}
+//
+// Nothing runs unless the file exists, and then only the listed playbooks.
+type PlaybookConfig struct {
+	// DryRun plans and audits actions without executing them.
+	DryRun bool `json:"dry_run,omitempty"`
+	// MaxPerHour caps actions across all playbooks in any rolling hour.
+	MaxPerHour int        `json:"max_actions_per_hour,omitempty"`
+	Playbooks  []Playbook `json:"playbooks"`
+}
+
+// Playbook remediates one type of problem. It acts once the problem has
+// been open for After, then at most every Cooldown, and at most MaxAttempts
+// times per occurrence of the problem.
+type Playbook struct {
+	Problem     string       `json:"problem"`           // problem type: "stalled", "zombie", "backoff", …
+	Subject     string       `json:"subject,omitempty"` // for "rule" problems, the rule name
+	Role        string       `json:"role,omitempty"`    // only agents in this role
+	Action      string       `json:"action"`            // PlaybookNudge, PlaybookResling or PlaybookRestart
+	After       RuleDuration `json:"after,omitempty"`
+	Cooldown    RuleDuration `json:"cooldown,omitempty"`
+	MaxAttempts int          `json:"max_attempts,omitempty"`
+	Message     string       `json:"message,omitempty"` // nudge text
+}
+
+// PlaybooksPath returns the playbook config path: MG_PLAYBOOKS if set,
+// otherwise mardi-gras/playbooks.json under the user config directory.
+func PlaybooksPath() string {
+	return config.Path("MG_PLAYBOOKS", "playbooks.json")
+}
+
+// LoadPlaybooks reads and checks a playbook config. A missing file is not an
+// error: it returns nil, nil and no playbooks run.
+func LoadPlaybooks(path string) (*PlaybookConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("playbooks: %w", err)
+	}
+	var cfg PlaybookConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("playbooks %s: %w", path, err)
+	}
+	if cfg.MaxPerHour < 0 {
+		return nil, fmt.Errorf("playbooks %s: max_actions_per_hour %d", path, cfg.MaxPerHour)
+	}
+	for i, pb := range cfg.Playbooks {
+		if err := pb.validate(); err != nil {
+			return nil, fmt.Errorf("playbooks %s: playbook %d: %w", path, i+1, err)
+		}
+	}
+	return &cfg, nil
+}
+
+func (pb Playbook) validate() error {
+	switch pb.Problem {
+	case "stalled", "stuck", "backoff", "zombie", "rule":
+	case "":
+		return fmt.Errorf("missing problem")
+	default:
+		return fmt.Errorf("problem %q has no agent to act on", pb.Problem)
+	}
+	switch pb.Action {
+	case PlaybookNudge, PlaybookResling, PlaybookRestart:
+	default:
+		return fmt.Errorf("%s: action %q, want nudge, resling or restart", pb.Problem, pb.Action)
+	}
+	if pb.MaxAttempts < 0 {
+		return fmt.Errorf("%s: max_attempts %d", pb.Problem, pb.MaxAttempts)
+	}
+	return nil
+}
+
+func (pb Playbook) matches(p TrackedProblem) bool {
+	switch {
+	case pb.Problem != p.Type,
+		pb.Subject != "" && pb.Subject != p.Subject,
+		pb.Role != "" && pb.Role != p.Agent.Role,
+		p.Agent.Name == "" && p.Agent.Address == "":
+		return false
+	}
+	// Re-slinging needs a hooked bead; only polecats can be decommissioned.
+	switch pb.Action {
+	case PlaybookResling:
+		return p.Agent.HookBead != ""
+	case PlaybookRestart:
+		return p.Agent.Role == "polecat"
+	}
+	return true
+}
+
+// PlaybookState remembers what the playbooks have done, for cooldowns,
+// attempt limits and the hourly cap. The zero value has done nothing.
+type PlaybookState struct {
+	recent   []time.Time          // every action in the last hour
+	last     map[string]time.Time // per problem occurrence and playbook
+	attempts map[string]int
+}
+
+// Remediation is one planned playbook action.
+type Remediation struct {
+	Problem TrackedProblem
+	Action  string
+	Message string // nudge text
+	Attempt int    // 1 for the first try on this occurrence
+	DryRun  bool
+}
+
+// PlanRemediations picks the actions due at now for the active problems, in
+// order, and records them in state as taken. A problem gets the first
+// playbook that matches it. Acknowledged problems are left to the human who
+// acknowledged them, and problems absent from the latest poll are left to
+// clear. Dry-run actions count against every limit, so a dry run shows
+// exactly what a live one would do.
+func PlanRemediations(cfg *PlaybookConfig, active []TrackedProblem, state *PlaybookState, now time.Time) []Remediation {
+	if cfg == nil || len(cfg.Playbooks) == 0 {
+		return nil
+	}
+	if state.last == nil {
+		state.last = make(map[string]time.Time)
+		state.attempts = make(map[string]int)
+	}
+	for key, at := range state.last {
+		if now.Sub(at) > playbookStateTTL {
+			delete(state.last, key)
+			delete(state.attempts, key)
+		}
+	}
+	recent := state.recent[:0]
+	for _, at := range state.recent {
+		if now.Sub(at) < time.Hour {
+			recent = append(recent, at)
+		}
+	}
+	state.recent = recent
+
+	maxPerHour := cfg.MaxPerHour
+	if maxPerHour == 0 {
+		maxPerHour = DefaultPlaybookMaxPerHour
+	}
+
+	var out []Remediation
+	for _, p := range active {
+		if p.Acked || p.Missing() || p.Snoozed(now) {
+			continue
+		}
+		for i, pb := range cfg.Playbooks {
+			if !pb.matches(p) {
+				continue
+			}
+			if now.Sub(p.FirstSeen) < time.Duration(pb.After) {
+				break
+			}
+			key := fmt.Sprintf("%s|%d|%d", p.ID, p.Occurrences, i)
+			cooldown := time.Duration(pb.Cooldown)
+			if cooldown == 0 {
+				cooldown = DefaultPlaybookCooldown
+			}
+			if last, ok := state.last[key]; ok && now.Sub(last) < cooldown {
+				break
+			}
+			maxAttempts := pb.MaxAttempts
+			if maxAttempts == 0 {
+				maxAttempts = DefaultPlaybookMaxAttempts
+			}
+			if state.attempts[key] >= maxAttempts || len(state.recent) >= maxPerHour {
+				break
+			}
+			state.last[key] = now
+			state.attempts[key]++
+			state.recent = append(state.recent, now)
+			msg := pb.Message
+			if msg == "" && pb.Action == PlaybookNudge {
+				msg = defaultPlaybookNudge
+			}
+			out = append(out, Remediation{
+				Problem: p,
+				Action:  pb.Action,
+				Message: msg,
+				Attempt: state.attempts[key],
+				DryRun:  cfg.DryRun,
+			})
+			break
+		}
+	}
+	return out
+}
+
+// Target is the agent the remediation acts on: its address, or its name
+// when the roster has no address.
+func (r Remediation) Target() string {
+	if r.Problem.Agent.Address != "" {
+		return r.Problem.Agent.Address
+	}
+	return r.Problem.Agent.Name
+}
+
+// Describe says what the remediation does, e.g. "nudge Toast" or
+// "re-sling mg-12 from Toast".
+func (r Remediation) Describe() string {
+	name := r.Problem.Agent.Name
+	switch r.Action {
+	case PlaybookResling:
+		return fmt.Sprintf("re-sling %s from %s", r.Problem.Agent.HookBead, name)
+	case PlaybookRestart:
+		if r.Problem.Agent.HookBead != "" {
+			return fmt.Sprintf("restart %s with %s", name, r.Problem.Agent.HookBead)
+		}
+		return "restart " + name
+	}
+	return r.Action + " " + name
+}
+
+// PlaybookSkipped is the error Execute returns when the driver can't
+// perform every step of the action. Nothing was done.
+type PlaybookSkipped struct {
+	Reason string
+}
+
+func (e *PlaybookSkipped) Error() string { return "skipped: " + e.Reason }
+
+// unsupported names the first step of the action the driver can't perform,
+// or "" when it can do them all. Checking up front keeps a restart from
+// decommissioning an agent it then can't re-sling.
+func (r Remediation) unsupported(d Driver) string {
+	need := map[string][]Feature{
+		PlaybookNudge:   {FeatureNudge},
+		PlaybookResling: {FeatureUnsling, FeatureAutoSling},
+		PlaybookRestart: {FeatureDecommission},
+	}[r.Action]
+	if r.Action == PlaybookRestart && r.Problem.Agent.HookBead != "" {
+		need = append(need, FeatureAutoSling)
+	}
+	steps := map[Feature]string{
+		FeatureNudge:        "nudge",
+		FeatureUnsling:      "unsling",
+		FeatureDecommission: "decommission",
+		FeatureAutoSling:    "sling without a target",
+	}
+	for _, f := range need {
+		if !d.Supports(f) {
+			return fmt.Sprintf("%s driver can't %s", d.Backend(), steps[f])
+		}
+	}
+	return ""
+}
+
+// Execute performs the remediation through the driver. A dry run does
+// nothing, and an action the driver can't carry out in full is skipped with
+// a *PlaybookSkipped error.
+func (r Remediation) Execute(ctx context.Context, d Driver) error {
+	if r.DryRun {
+		return nil
+	}
+	if reason := r.unsupported(d); reason != "" {
+		return &PlaybookSkipped{Reason: reason}
+	}
+	bead := r.Problem.Agent.HookBead
+	switch r.Action {
+	case PlaybookNudge:
+		return d.Nudge(ctx, r.Target(), r.Message)
+	case PlaybookResling:
+		if err := d.Unsling(ctx, bead); err != nil {
+			return fmt.Errorf("unsling %s: %w", bead, err)
+		}
+		return d.Sling(ctx, SlingRequest{IssueIDs: []string{bead}, Rig: r.Problem.Agent.Rig})
+	case PlaybookRestart:
+		if err := d.Decommission(ctx, r.Target()); err != nil {
+			return fmt.Errorf("decommission %s: %w", r.Target(), err)
+		}
+		if bead == "" {
+			return nil
+		}
+		return d.Sling(ctx, SlingRequest{IssueIDs: []string{bead}, Rig: r.Problem.Agent.Rig})
+	}
+	return fmt.Errorf("unknown playbook action %q", r.Action)
+}
+
+// PlaybookAudit is one line of the playbook audit log: an action taken, or
+// in a dry run one that would have been.
+type PlaybookAudit struct {
+	At        time.Time `json:"at"`
+	ProblemID string    `json:"problem_id"`
+	Problem   string    `json:"problem"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target"`
+	Issue     string    `json:"issue,omitempty"`
+	Attempt   int       `json:"attempt"`
+	DryRun    bool      `json:"dry_run,omitempty"`
+	Skipped   string    `json:"skipped,omitempty"` // why the driver couldn't act
+	Error     string    `json:"error,omitempty"`
+}
+
+// Audit records the outcome of the remediation at at.
+func (r Remediation) Audit(at time.Time, err error) PlaybookAudit {
+	a := PlaybookAudit{
+		At:        at,
+		ProblemID: r.Problem.ID,
+		Problem:   r.Problem.Type,
+		Action:    r.Action,
+		Target:    r.Target(),
+		Issue:     r.Problem.Agent.HookBead,
+		Attempt:   r.Attempt,
+		DryRun:    r.DryRun,
+	}
+	var skipped *PlaybookSkipped
+	switch {
+	case errors.As(err, &skipped):
+		a.Skipped = skipped.Reason
+	case err != nil:
+		a.Error = err.Error()
+	}
+	return a
+}
+
+// PlaybookAuditPath returns the playbook audit log path: MG_PLAYBOOK_AUDIT if
+// set, otherwise mardi-gras/playbook-audit.jsonl under the user config
+// directory.
+func PlaybookAuditPath() string {
+	return config.Path("MG_PLAYBOOK_AUDIT", "playbook-audit.jsonl")
+}
+
+// AppendPlaybookAudit appends one entry to the audit log at path.
+func AppendPlaybookAudit(path string, entry PlaybookAudit) error {
+	if err := config.AppendJSONL(path, entry); err != nil {
+		return fmt.Errorf("playbook audit: %w", err)
+	}
+	return nil
+}
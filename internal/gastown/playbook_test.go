@@ -0,0 +1,230 @@
+package gastown
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadPlaybooks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "playbooks.json")
+	raw := `{"dry_run": true, "playbooks": [
+		{"problem": "stalled", "action": "nudge", "after": "10m"},
+		{"problem": "zombie", "action": "resling", "max_attempts": 1}
+	]}`
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadPlaybooks(path)
+	if err != nil || cfg == nil || len(cfg.Playbooks) != 2 || !cfg.DryRun {
+		t.Fatalf("LoadPlaybooks = %+v, %v", cfg, err)
+	}
+	if time.Duration(cfg.Playbooks[0].After) != 10*time.Minute {
+		t.Fatalf("after = %v", cfg.Playbooks[0].After)
+	}
+
+	for _, bad := range []string{
+		`{"playbooks": [{"action": "nudge"}]}`,
+		`{"playbooks": [{"problem": "dead_rig", "action": "nudge"}]}`,
+		`{"playbooks": [{"problem": "stalled", "action": "reboot"}]}`,
+		`{"playbooks": [{"problem": "stalled", "action": "nudge", "after": "soon"}]}`,
+	} {
+		if err := os.WriteFile(path, []byte(bad), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPlaybooks(path); err == nil {
+			t.Errorf("LoadPlaybooks(%s) should fail", bad)
+		}
+	}
+
+	if cfg, err := LoadPlaybooks(filepath.Join(dir, "none.json")); cfg != nil || err != nil {
+		t.Fatalf("missing file = %v, %v", cfg, err)
+	}
+}
+
+func TestPlanRemediationsLimits(t *testing.T) {
+	start := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	cfg := &PlaybookConfig{MaxPerHour: 3, Playbooks: []Playbook{
+		{Problem: "stalled", Action: PlaybookNudge, After: RuleDuration(10 * time.Minute), MaxAttempts: 2},
+		{Problem: "zombie", Action: PlaybookResling},
+		{Problem: "backoff", Action: PlaybookRestart},
+	}}
+	tracked := func(p Problem) TrackedProblem {
+		return TrackedProblem{Problem: p, ID: p.ID(), FirstSeen: start, Raised: true, Occurrences: 1}
+	}
+	stalled := tracked(Problem{Type: "stalled", Agent: AgentRuntime{Name: "Toast", Address: "gt/polecats/Toast", Role: "polecat"}})
+	zombie := tracked(Problem{Type: "zombie", Agent: AgentRuntime{Name: "Nux", Role: "polecat", Rig: "gt", HookBead: "mg-1"}})
+	crewBackoff := tracked(Problem{Type: "backoff", Agent: AgentRuntime{Name: "max", Role: "crew"}})
+	acked := tracked(Problem{Type: "zombie", Agent: AgentRuntime{Name: "Slit", HookBead: "mg-2"}})
+	acked.Acked = true
+	active := []TrackedProblem{stalled, zombie, crewBackoff, acked}
+
+	var state PlaybookState
+	got := PlanRemediations(cfg, active, &state, start.Add(time.Minute))
+	if len(got) != 1 || got[0].Describe() != "re-sling mg-1 from Nux" {
+		t.Fatalf("first plan = %+v, want only the zombie (stalled waits 10m, crew can't restart, acked skipped)", got)
+	}
+
+	got = PlanRemediations(cfg, active, &state, start.Add(11*time.Minute))
+	if len(got) != 1 || got[0].Action != PlaybookNudge || got[0].Target() != "gt/polecats/Toast" || got[0].Attempt != 1 {
+		t.Fatalf("after 11m = %+v, want the nudge (zombie cooling down)", got)
+	}
+	if got[0].Message != defaultPlaybookNudge {
+		t.Errorf("message = %q", got[0].Message)
+	}
+
+	// Cooldowns over: the stalled agent gets its second and last attempt,
+	// the zombie is held back by the hourly cap.
+	got = PlanRemediations(cfg, active, &state, start.Add(45*time.Minute))
+	if len(got) != 1 || got[0].Action != PlaybookNudge || got[0].Attempt != 2 {
+		t.Fatalf("after 45m = %+v, want the second nudge only", got)
+	}
+	if got = PlanRemediations(cfg, active, &state, start.Add(59*time.Minute)); len(got) != 0 {
+		t.Fatalf("at the hourly cap = %+v, want nothing", got)
+	}
+
+	// The cap rolls off; the nudge is out of attempts but the zombie is due.
+	got = PlanRemediations(cfg, active, &state, start.Add(2*time.Hour))
+	if len(got) != 1 || got[0].Action != PlaybookResling || got[0].Attempt != 2 {
+		t.Fatalf("after 2h = %+v, want the zombie's second re-sling", got)
+	}
+
+	// A new occurrence of the stalled problem gets a fresh budget.
+	stalled.Occurrences = 2
+	stalled.FirstSeen = start.Add(3 * time.Hour)
+	got = PlanRemediations(cfg, []TrackedProblem{stalled}, &state, start.Add(3*time.Hour+10*time.Minute))
+	if len(got) != 1 || got[0].Attempt != 1 {
+		t.Fatalf("new occurrence = %+v, want a first attempt", got)
+	}
+}
+
+// playbookDriver records the lifecycle calls a remediation makes. It
+// supports every lifecycle feature unless listed in missing.
+type playbookDriver struct {
+	Driver
+	calls   []string
+	fail    error
+	missing []Feature
+}
+
+func (d *playbookDriver) Backend() string { return "fake" }
+
+func (d *playbookDriver) Supports(f Feature) bool { return !slices.Contains(d.missing, f) }
+
+func (d *playbookDriver) Nudge(_ context.Context, target, _ string) error {
+	d.calls = append(d.calls, "nudge "+target)
+	return d.fail
+}
+
+func (d *playbookDriver) Unsling(_ context.Context, id string) error {
+	d.calls = append(d.calls, "unsling "+id)
+	return d.fail
+}
+
+func (d *playbookDriver) Sling(_ context.Context, req SlingRequest) error {
+	d.calls = append(d.calls, "sling "+strings.Join(req.IssueIDs, ",")+" "+req.Rig)
+	return d.fail
+}
+
+func (d *playbookDriver) Decommission(_ context.Context, address string) error {
+	d.calls = append(d.calls, "decommission "+address)
+	return d.fail
+}
+
+func TestRemediationExecuteAndAudit(t *testing.T) {
+	agent := AgentRuntime{Name: "Nux", Address: "gt/polecats/Nux", Role: "polecat", Rig: "gt", HookBead: "mg-1"}
+	r := Remediation{Problem: TrackedProblem{Problem: Problem{Type: "backoff", Agent: agent}, ID: "abc123"}, Action: PlaybookRestart, Attempt: 1}
+
+	d := &playbookDriver{}
+	if err := r.Execute(context.Background(), d); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(d.calls, "; "); got != "decommission gt/polecats/Nux; sling mg-1 gt" {
+		t.Fatalf("restart calls = %q", got)
+	}
+
+	d = &playbookDriver{fail: errors.New("no such bead")}
+	r.Action = PlaybookResling
+	err := r.Execute(context.Background(), d)
+	if err == nil || len(d.calls) != 1 {
+		t.Fatalf("failed unsling should stop before the sling: %v, %v", err, d.calls)
+	}
+
+	d = &playbookDriver{}
+	r.DryRun = true
+	if err := r.Execute(context.Background(), d); err != nil || len(d.calls) != 0 {
+		t.Fatalf("dry run made calls: %v, %v", d.calls, err)
+	}
+
+	r.DryRun = false
+	skipped := r.Audit(time.Time{}, &PlaybookSkipped{Reason: "fake driver can't unsling"})
+	if skipped.Skipped == "" || skipped.Error != "" {
+		t.Fatalf("skip audited as %+v", skipped)
+	}
+	r.DryRun = true
+
+	path := filepath.Join(t.TempDir(), "audit", "playbook-audit.jsonl")
+	at := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	if err := AppendPlaybookAudit(path, r.Audit(at, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendPlaybookAudit(path, r.Audit(at, errors.New("boom"))); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"problem_id":"abc123"`) ||
+		!strings.Contains(lines[0], `"dry_run":true`) || !strings.Contains(lines[1], `"error":"boom"`) {
+		t.Fatalf("audit log = %s", raw)
+	}
+}
+
+// gcLikeDriver is a Gas City-shaped driver: it can nudge and decommission,
+// but has no unsling and needs a target to sling.
+func gcLikeDriver() *playbookDriver {
+	return &playbookDriver{missing: []Feature{FeatureUnsling, FeatureAutoSling}}
+}
+
+func TestRemediationSkipsActionsTheDriverCantPerform(t *testing.T) {
+	agent := AgentRuntime{Name: "Nux", Address: "city/Nux", HookBead: "mg-1"}
+	problem := TrackedProblem{Problem: Problem{Type: "stalled", Agent: agent}, ID: "abc123"}
+	for _, tc := range []struct {
+		action, calls string
+		skipped       bool
+	}{
+		{PlaybookNudge, "nudge city/Nux", false},
+		{PlaybookResling, "", true},
+		{PlaybookRestart, "", true}, // no decommission it can't follow with a sling
+	} {
+		d := gcLikeDriver()
+		r := Remediation{Problem: problem, Action: tc.action, Message: "wake up"}
+		err := r.Execute(context.Background(), d)
+		var skip *PlaybookSkipped
+		if errors.As(err, &skip) != tc.skipped {
+			t.Errorf("%s: err = %v, skipped want %v", tc.action, err, tc.skipped)
+		}
+		if got := strings.Join(d.calls, "; "); got != tc.calls {
+			t.Errorf("%s: calls = %q, want %q", tc.action, got, tc.calls)
+		}
+		if a := r.Audit(time.Now(), err); tc.skipped && (a.Skipped == "" || a.Error != "") {
+			t.Errorf("%s: audit = %+v, want a skip reason and no error", tc.action, a)
+		}
+	}
+
+	// Without a hooked bead a restart is only a decommission, which works.
+	d := gcLikeDriver()
+	bare := problem
+	bare.Agent.HookBead = ""
+	if err := (Remediation{Problem: bare, Action: PlaybookRestart}).Execute(context.Background(), d); err != nil || len(d.calls) != 1 {
+		t.Fatalf("bare restart: %v, %v", err, d.calls)
+	}
+}
@@ -27,14 +27,7 @@ func PredictConvoys(convoys []ConvoyDetail, velocity *VelocityMetrics) []ConvoyP
 		return nil
 	}
 
-	// Compute daily close rate from velocity
-	// Use week rate if today rate is too small (more stable)
-	dailyRate := float64(velocity.ClosedWeek) / 7.0
-	if velocity.ClosedToday > 0 {
-		// Weighted blend: today's rate is more recent but volatile
-		todayRate := float64(velocity.ClosedToday)
-		dailyRate = todayRate*0.3 + dailyRate*0.7
-	}
+	dailyRate := dailyCloseRate(velocity)
 
 	var predictions []ConvoyPrediction
 
@@ -81,6 +74,19 @@ func PredictConvoys(convoys []ConvoyDetail, velocity *VelocityMetrics) []ConvoyP
 	return predictions
 }
 
+// dailyCloseRate is the issues-closed-per-day rate used for projections: the
+// week's average, blended with today's count when there is one.
+func dailyCloseRate(velocity *VelocityMetrics) float64 {
+	// Use week rate if today rate is too small (more stable)
+	dailyRate := float64(velocity.ClosedWeek) / 7.0
+	if velocity.ClosedToday > 0 {
+		// Weighted blend: today's rate is more recent but volatile
+		todayRate := float64(velocity.ClosedToday)
+		dailyRate = todayRate*0.3 + dailyRate*0.7
+	}
+	return dailyRate
+}
+
 // PredictCostBudget estimates when the daily cost budget will be exhausted.
 func PredictCostBudget(costs *CostsOutput, dailyBudget float64, now time.Time) string {
 	if costs == nil || dailyBudget <= 0 || costs.Total.Cost <= 0 {
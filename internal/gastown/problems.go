@@ -1,10 +1,15 @@
 package gastown
 
-import "fmt"
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+)
 
 // Problem represents a detected issue with a Gas Town agent or beads infrastructure.
 type Problem struct {
-	Type     string          // "stalled", "backoff", "zombie", "dead_rig", "doctor"
+	Type     string          // "stalled", "backoff", "zombie", "dead_rig", "doctor", "budget", "rule", "playbook", "runtime", "codex", "approval"
 	Agent    AgentRuntime    // the affected agent (zero value for rig-level/doctor problems)
 	Detail   string          // human-readable description
 	Severity string          // "warn", "error"
@@ -12,6 +17,33 @@ type Problem struct {
 	Fix      string          // suggested fix command, if any
 	RigName  string          // rig name for rig-level problems
 	Orphans  []OrphanedIssue // orphaned issues for dead_rig problems
+	IssueID  string          // the affected issue for issue-level rule problems
+
+	// Subject tells apart problems of one type on the same agent or rig
+	// whose Detail changes between polls: the doctor check, the budget, the
+	// alert rule. It feeds Key.
+	Subject string
+
+	// After holds the problem back until it has been seen continuously this
+	// long (an alert rule's "for").
+	After time.Duration
+}
+
+// Key identifies a problem across polls: its type, agent, rig, issue and
+// subject, but not its changing detail or severity.
+func (p Problem) Key() string {
+	agent := p.Agent.Address
+	if agent == "" {
+		agent = p.Agent.Name
+	}
+	return strings.Join([]string{p.Type, agent, p.RigName, p.IssueID, p.Subject}, "|")
+}
+
+// ID is a short stable identifier for the problem, derived from Key.
+func (p Problem) ID() string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(p.Key()))
+	return fmt.Sprintf("%06x", h.Sum32()&0xffffff)
 }
 
 // DetectProblems analyzes TownStatus and returns any detected problems.
@@ -120,6 +152,7 @@ func DoctorProblems(diagnostics []DoctorDiagnostic) []Problem {
 		}
 		problems = append(problems, Problem{
 			Type:     "doctor",
+			Subject:  d.Name,
 			Detail:   d.Name + ": " + d.Explanation,
 			Severity: sev,
 			Category: d.Category,
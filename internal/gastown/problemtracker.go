@@ -0,0 +1,340 @@
+package gastown
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/config"
+)
+
+// DefaultProblemClearAfter is how long a raised problem must stay absent
+// before the tracker resolves it. A problem that comes back sooner is the
+// same occurrence, counted as a flap, rather than a new alarm.
+const DefaultProblemClearAfter = 2 * time.Minute
+
+// Problem history bounds: resolved problems older than this, or beyond this
+// many, are dropped.
+const (
+	ProblemHistoryRetention = 7 * 24 * time.Hour
+	MaxProblemHistory       = 200
+)
+
+// TrackedProblem is a problem with its lifecycle across polls.
+type TrackedProblem struct {
+	Problem
+	ID string
+
+	FirstSeen  time.Time // start of the current occurrence
+	LastSeen   time.Time
+	ResolvedAt time.Time // zero while open
+
+	// Raised is false while the problem waits out its After; such problems
+	// are not shown and are forgotten as soon as they go absent.
+	Raised bool
+
+	Acked        bool      // acknowledged; cleared when the problem resolves
+	SnoozedUntil time.Time // hidden until then
+	Flaps        int       // times it went absent and came back within the clear window
+	Occurrences  int       // times it has been raised
+
+	missing bool // absent from the latest observation
+	order   int  // position in the latest observation, for stable display
+}
+
+// Resolved reports whether the problem has cleared.
+func (t TrackedProblem) Resolved() bool {
+	return !t.ResolvedAt.IsZero()
+}
+
+// Missing reports whether the problem was absent from the latest poll but
+// has not yet been absent long enough to resolve.
+func (t TrackedProblem) Missing() bool {
+	return t.missing
+}
+
+// Snoozed reports whether the problem is hidden at now.
+func (t TrackedProblem) Snoozed(now time.Time) bool {
+	return now.Before(t.SnoozedUntil)
+}
+
+// ProblemTracker turns per-poll problem snapshots into problems with stable
+// IDs and a history: first and last seen, resolution, flaps, and the user's
+// acknowledgements and snoozes. The zero value is an empty tracker.
+type ProblemTracker struct {
+	ClearAfter time.Duration // zero means DefaultProblemClearAfter
+	entries    map[string]*TrackedProblem
+}
+
+// NewProblemTracker returns a tracker seeded with a saved history.
+func NewProblemTracker(history []TrackedProblem) *ProblemTracker {
+	t := &ProblemTracker{ClearAfter: DefaultProblemClearAfter, entries: make(map[string]*TrackedProblem)}
+	for _, h := range history {
+		if !h.Resolved() {
+			// Open problems from an earlier run resolve unless they are
+			// seen again within the clear window.
+			h.missing = true
+		}
+		t.entries[h.Key()] = &h
+	}
+	return t
+}
+
+// Observe records one poll's problems and reports whether anything worth
+// saving changed: a problem raised, resolved or came back.
+func (t *ProblemTracker) Observe(problems []Problem, now time.Time) bool {
+	if t.entries == nil {
+		t.entries = make(map[string]*TrackedProblem)
+	}
+	clearAfter := t.ClearAfter
+	if clearAfter == 0 {
+		clearAfter = DefaultProblemClearAfter
+	}
+	changed := false
+	seen := make(map[string]bool, len(problems))
+	for i, p := range problems {
+		key := p.Key()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		e := t.entries[key]
+		switch {
+		case e == nil:
+			e = &TrackedProblem{ID: p.ID(), FirstSeen: now}
+			t.entries[key] = e
+		case e.Resolved():
+			e.FirstSeen = now
+			e.ResolvedAt = time.Time{}
+			e.Raised = false
+			e.Acked = false
+			e.Flaps = 0
+			changed = true
+		case e.missing && e.Raised:
+			e.Flaps++
+			changed = true
+		}
+		e.Problem = p
+		e.LastSeen = now
+		e.missing = false
+		e.order = i
+		if !e.Raised && now.Sub(e.FirstSeen) >= p.After {
+			e.Raised = true
+			e.Occurrences++
+			changed = true
+		}
+	}
+
+	for key, e := range t.entries {
+		if seen[key] || e.Resolved() {
+			continue
+		}
+		if !e.Raised {
+			delete(t.entries, key)
+			continue
+		}
+		e.missing = true
+		if now.Sub(e.LastSeen) >= clearAfter {
+			e.ResolvedAt = now
+			e.Acked = false
+			changed = true
+		}
+	}
+
+	t.prune(now)
+	return changed
+}
+
+// Active returns the raised, unresolved problems not snoozed at now, in the
+// order of the latest poll, with problems that have just gone absent last.
+func (t *ProblemTracker) Active(now time.Time) []TrackedProblem {
+	out := t.filter(func(e *TrackedProblem) bool {
+		return e.Raised && !e.Resolved() && !e.Snoozed(now)
+	})
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].missing != out[j].missing {
+			return !out[i].missing
+		}
+		return out[i].order < out[j].order
+	})
+	return out
+}
+
+// Snoozed returns the open problems snoozed at now, soonest to wake first.
+func (t *ProblemTracker) Snoozed(now time.Time) []TrackedProblem {
+	out := t.filter(func(e *TrackedProblem) bool {
+		return e.Raised && !e.Resolved() && e.Snoozed(now)
+	})
+	sort.SliceStable(out, func(i, j int) bool { return out[i].SnoozedUntil.Before(out[j].SnoozedUntil) })
+	return out
+}
+
+// History returns resolved problems, most recently resolved first.
+func (t *ProblemTracker) History() []TrackedProblem {
+	out := t.filter(func(e *TrackedProblem) bool { return e.Resolved() })
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ResolvedAt.After(out[j].ResolvedAt) })
+	return out
+}
+
+// Unacked counts the active problems nobody has acknowledged.
+func (t *ProblemTracker) Unacked(now time.Time) int {
+	n := 0
+	for _, e := range t.Active(now) {
+		if !e.Acked {
+			n++
+		}
+	}
+	return n
+}
+
+// Ack toggles acknowledgement of the open problem id. It reports whether the
+// problem was found.
+func (t *ProblemTracker) Ack(id string) bool {
+	e := t.byID(id)
+	if e == nil || e.Resolved() {
+		return false
+	}
+	e.Acked = !e.Acked
+	return true
+}
+
+// Snooze hides the open problem id until until; a zero until wakes it.
+func (t *ProblemTracker) Snooze(id string, until time.Time) bool {
+	e := t.byID(id)
+	if e == nil || e.Resolved() {
+		return false
+	}
+	e.SnoozedUntil = until
+	return true
+}
+
+// Entries returns every raised problem, open or resolved, for saving.
+func (t *ProblemTracker) Entries() []TrackedProblem {
+	out := t.filter(func(e *TrackedProblem) bool { return e.Raised })
+	sort.SliceStable(out, func(i, j int) bool { return out[i].FirstSeen.Before(out[j].FirstSeen) })
+	return out
+}
+
+func (t *ProblemTracker) filter(keep func(*TrackedProblem) bool) []TrackedProblem {
+	var out []TrackedProblem
+	for _, e := range t.entries {
+		if keep(e) {
+			out = append(out, *e)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func (t *ProblemTracker) byID(id string) *TrackedProblem {
+	for _, e := range t.entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// prune drops resolved problems past the retention window or beyond the
+// history cap, oldest first.
+func (t *ProblemTracker) prune(now time.Time) {
+	resolved := t.History()
+	for i, h := range resolved {
+		if i >= MaxProblemHistory || now.Sub(h.ResolvedAt) > ProblemHistoryRetention {
+			delete(t.entries, h.Key())
+		}
+	}
+}
+
+// problemRecord is one line of the saved problem history: the identifying
+// and displayed fields of a TrackedProblem.
+type problemRecord struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Severity string `json:"severity"`
+	Detail   string `json:"detail"`
+	Subject  string `json:"subject,omitempty"`
+	Agent    string `json:"agent,omitempty"`
+	Address  string `json:"address,omitempty"`
+	Role     string `json:"role,omitempty"`
+	AgentRig string `json:"agent_rig,omitempty"`
+	Rig      string `json:"rig,omitempty"`
+	Issue    string `json:"issue,omitempty"`
+	Category string `json:"category,omitempty"`
+
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+	ResolvedAt   time.Time `json:"resolved_at,omitzero"`
+	Acked        bool      `json:"acked,omitempty"`
+	SnoozedUntil time.Time `json:"snoozed_until,omitzero"`
+	Flaps        int       `json:"flaps,omitempty"`
+	Occurrences  int       `json:"occurrences"`
+}
+
+// ProblemHistoryPath returns the problem history path: MG_PROBLEM_HISTORY if
+// set, otherwise mardi-gras/problems.jsonl under the user config directory.
+func ProblemHistoryPath() string {
+	return config.Path("MG_PROBLEM_HISTORY", "problems.jsonl")
+}
+
+// LoadProblemHistory reads a saved problem history. A missing file is an
+// empty history; unparseable lines are skipped.
+func LoadProblemHistory(path string) ([]TrackedProblem, error) {
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("problem history: %w", err)
+	}
+	var out []TrackedProblem
+	for _, line := range bytes.Split(raw, []byte("\n")) {
+		var r problemRecord
+		if json.Unmarshal(line, &r) != nil || r.ID == "" {
+			continue
+		}
+		out = append(out, TrackedProblem{
+			Problem: Problem{
+				Type: r.Type, Severity: r.Severity, Detail: r.Detail, Subject: r.Subject,
+				Agent:   AgentRuntime{Name: r.Agent, Address: r.Address, Role: r.Role, Rig: r.AgentRig},
+				RigName: r.Rig, IssueID: r.Issue, Category: r.Category,
+			},
+			ID: r.ID, FirstSeen: r.FirstSeen, LastSeen: r.LastSeen, ResolvedAt: r.ResolvedAt,
+			Raised: true, Acked: r.Acked, SnoozedUntil: r.SnoozedUntil,
+			Flaps: r.Flaps, Occurrences: r.Occurrences,
+		})
+	}
+	return out, nil
+}
+
+// SaveProblemHistory replaces the history at path with the tracker's
+// entries, atomically.
+func SaveProblemHistory(path string, entries []TrackedProblem) error {
+	if path == "" {
+		return fmt.Errorf("problem history: no path")
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		r := problemRecord{
+			ID: e.ID, Type: e.Type, Severity: e.Severity, Detail: e.Detail, Subject: e.Subject,
+			Agent: e.Agent.Name, Address: e.Agent.Address, Role: e.Agent.Role, AgentRig: e.Agent.Rig, Rig: e.RigName,
+			Issue: e.IssueID, Category: e.Category,
+			FirstSeen: e.FirstSeen, LastSeen: e.LastSeen, ResolvedAt: e.ResolvedAt,
+			Acked: e.Acked, SnoozedUntil: e.SnoozedUntil, Flaps: e.Flaps, Occurrences: e.Occurrences,
+		}
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("problem history: %w", err)
+		}
+	}
+	if err := config.WriteFileAtomic(path, buf.Bytes()); err != nil {
+		return fmt.Errorf("problem history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package gastown
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProblemTrackerHysteresis(t *testing.T) {
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	zombie := Problem{Type: "zombie", Agent: AgentRuntime{Name: "Toast", Address: "gastown/polecats/Toast"}, Detail: "x", Severity: "error"}
+	tr := NewProblemTracker(nil)
+
+	if !tr.Observe([]Problem{zombie}, now) {
+		t.Fatal("a new problem should report a change")
+	}
+	active := tr.Active(now)
+	if len(active) != 1 || active[0].ID != zombie.ID() || active[0].Occurrences != 1 {
+		t.Fatalf("active = %+v", active)
+	}
+
+	// Gone for one poll, back inside the clear window: a flap, same occurrence.
+	tr.Observe(nil, now.Add(30*time.Second))
+	if a := tr.Active(now); len(a) != 1 || !a[0].Missing() {
+		t.Fatalf("briefly absent problem should stay listed as missing: %+v", a)
+	}
+	tr.Observe([]Problem{zombie}, now.Add(time.Minute))
+	got := tr.Active(now)[0]
+	if got.Flaps != 1 || got.Missing() || !got.FirstSeen.Equal(now) {
+		t.Fatalf("flap = %+v", got)
+	}
+
+	// Absent past the clear window: resolved into history.
+	tr.Observe(nil, now.Add(2*time.Minute))
+	if !tr.Observe(nil, now.Add(4*time.Minute)) {
+		t.Fatal("resolving should report a change")
+	}
+	if len(tr.Active(now)) != 0 || len(tr.History()) != 1 {
+		t.Fatalf("active %d history %d, want 0 and 1", len(tr.Active(now)), len(tr.History()))
+	}
+
+	// Recurring after resolution opens a new occurrence under the same ID.
+	tr.Observe([]Problem{zombie}, now.Add(time.Hour))
+	if a := tr.Active(now); len(a) != 1 || a[0].Occurrences != 2 || a[0].Flaps != 0 {
+		t.Fatalf("recurrence = %+v", a)
+	}
+}
+
+func TestProblemTrackerAfterAndAckSnooze(t *testing.T) {
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	idle := Problem{Type: "rule", Subject: "idle", Agent: AgentRuntime{Name: "Toast"}, After: 10 * time.Minute}
+	tr := NewProblemTracker(nil)
+
+	tr.Observe([]Problem{idle}, now)
+	tr.Observe([]Problem{idle}, now.Add(5*time.Minute))
+	if len(tr.Active(now)) != 0 {
+		t.Fatal("rule should wait out its for")
+	}
+	// Going absent resets the clock.
+	tr.Observe(nil, now.Add(6*time.Minute))
+	tr.Observe([]Problem{idle}, now.Add(7*time.Minute))
+	tr.Observe([]Problem{idle}, now.Add(15*time.Minute))
+	if len(tr.Active(now)) != 0 {
+		t.Fatal("interrupted condition should restart its for")
+	}
+	later := now.Add(17 * time.Minute)
+	tr.Observe([]Problem{idle}, later)
+	if len(tr.Active(later)) != 1 || tr.Unacked(later) != 1 {
+		t.Fatal("rule should raise after holding for 10m")
+	}
+
+	if !tr.Ack(idle.ID()) || tr.Unacked(later) != 0 || !tr.Active(later)[0].Acked {
+		t.Fatal("ack should mark the problem and drop it from the unacked count")
+	}
+	if !tr.Snooze(idle.ID(), later.Add(time.Hour)) || len(tr.Active(later)) != 0 || len(tr.Snoozed(later)) != 1 {
+		t.Fatal("snoozed problem should move out of the active list")
+	}
+	if len(tr.Active(later.Add(2*time.Hour))) != 1 {
+		t.Fatal("snooze should expire")
+	}
+	if tr.Ack("nope") || tr.Snooze("nope", later) {
+		t.Fatal("unknown IDs should not be found")
+	}
+}
+
+func TestProblemHistoryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mg", "problems.jsonl")
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	stalled := Problem{Type: "stalled", Agent: AgentRuntime{Name: "Toast", Rig: "gastown"}, Detail: "idle", Severity: "warn"}
+	budget := Problem{Type: "budget", Subject: "town daily", Detail: "over", Severity: "error"}
+
+	tr := NewProblemTracker(nil)
+	tr.Observe([]Problem{stalled, budget}, now)
+	tr.Ack(stalled.ID())
+	tr.Observe([]Problem{stalled}, now.Add(5*time.Minute))
+	if err := SaveProblemHistory(path, tr.Entries()); err != nil {
+		t.Fatalf("SaveProblemHistory: %v", err)
+	}
+
+	loaded, err := LoadProblemHistory(path)
+	if err != nil || len(loaded) != 2 {
+		t.Fatalf("LoadProblemHistory = %d entries, %v", len(loaded), err)
+	}
+	re := NewProblemTracker(loaded)
+	if len(re.History()) != 1 || re.History()[0].ID != budget.ID() {
+		t.Fatalf("history = %+v", re.History())
+	}
+	// The open problem keeps its ID and ack when seen again after a restart.
+	re.Observe([]Problem{stalled}, now.Add(6*time.Minute))
+	a := re.Active(now)
+	if len(a) != 1 || a[0].ID != stalled.ID() || !a[0].Acked || !a[0].FirstSeen.Equal(now) {
+		t.Fatalf("reloaded active = %+v", a)
+	}
+
+	if got, err := LoadProblemHistory(filepath.Join(t.TempDir(), "none")); err != nil || got != nil {
+		t.Fatalf("missing history = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,652 @@
+package gastown
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// EnvDriver selects an out-of-process orchestrator driver:
+// MG_DRIVER=exec:/path/to/bin. It takes precedence over MG_GC_API.
+const EnvDriver = "MG_DRIVER"
+
+// processDriverScheme prefixes the MG_DRIVER value naming a driver binary.
+const processDriverScheme = "exec:"
+
+// ProcessProtocolVersion is the version of the out-of-process driver protocol
+// mg speaks. A driver must echo it from initialize; see docs/driver-protocol.md.
+const ProcessProtocolVersion = 1
+
+// processRPCMethodNotFound is the JSON-RPC 2.0 "method not found" code. A
+// driver that answers with it is treated as not implementing the method.
+const processRPCMethodNotFound = -32601
+
+// ProcessDriverPath returns the driver binary named by MG_DRIVER=exec:<path>,
+// or "" when MG_DRIVER does not select an out-of-process driver.
+func ProcessDriverPath() string {
+	v := strings.TrimSpace(os.Getenv(EnvDriver))
+	if !strings.HasPrefix(v, processDriverScheme) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(v, processDriverScheme))
+}
+
+// ProcessEnabled reports whether the user has plugged in an out-of-process
+// driver via MG_DRIVER.
+func ProcessEnabled() bool { return ProcessDriverPath() != "" }
+
+// ProcessDriver is a Driver implemented by a separate program that speaks
+// newline-delimited JSON-RPC 2.0 on its stdin/stdout. Each Driver method maps
+// to one request (Status -> "status", ConvoyCreateFromEpic ->
+// "convoyCreateFromEpic", …); params and results use the same JSON shapes as
+// the package's types. This lets a homegrown scheduler back mg's roster,
+// sling, mail and convoy UI without a fork.
+//
+// The driver declares the methods it implements in the initialize handshake.
+// Anything it leaves out returns ErrUnsupported without a round trip, and the
+// gated features (vitals, costs, patrol, transcript) are supported exactly
+// when their method is declared.
+type ProcessDriver struct {
+	backend string
+	methods map[string]bool
+
+	cmd    *exec.Cmd // nil when connected over plain pipes (tests)
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+
+	wMu    sync.Mutex
+	nextID atomic.Int64
+	// pending maps request id -> chan processResponse.
+	pending sync.Map
+
+	done   chan struct{}
+	stderr *processStderr
+	// exited closes once the process has been reaped and its stderr fully
+	// copied; nil when there is no process.
+	exited chan struct{}
+
+	closeOnce sync.Once
+}
+
+// Compile-time assurance that ProcessDriver satisfies the Driver interface.
+var _ Driver = (*ProcessDriver)(nil)
+
+type processRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int64  `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type processResponse struct {
+	ID     *int64          `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *processRPCErr  `json:"error,omitempty"`
+}
+
+type processRPCErr struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type processInitParams struct {
+	ProtocolVersion int    `json:"protocol_version"`
+	Client          string `json:"client"`
+}
+
+type processInitResult struct {
+	ProtocolVersion int      `json:"protocol_version"`
+	Backend         string   `json:"backend"`
+	Methods         []string `json:"methods"`
+}
+
+// NewProcessDriver starts the driver binary at path and performs the
+// initialize handshake. The process lives until Close, or until mg exits and
+// the driver sees EOF on stdin.
+func NewProcessDriver(path string) (*ProcessDriver, error) {
+	cmd := exec.Command(path) //nolint:gosec // path is the user's configured MG_DRIVER binary
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("driver %s: %w", filepath.Base(path), err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("driver %s: %w", filepath.Base(path), err)
+	}
+	stderr := &processStderr{}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("driver %s: %w", filepath.Base(path), err)
+	}
+	d := newProcessDriver(path, cmd, stderr, stdin, stdout)
+	if err := d.initialize(); err != nil {
+		_ = d.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// newProcessDriver wires a driver to an already-connected stdin/stdout pair
+// and starts the read loop. cmd is the started process, or nil over plain
+// pipes. The caller performs the handshake.
+func newProcessDriver(path string, cmd *exec.Cmd, stderr *processStderr, stdin io.WriteCloser, stdout io.ReadCloser) *ProcessDriver {
+	d := &ProcessDriver{
+		backend: filepath.Base(path),
+		cmd:     cmd,
+		stdin:   stdin,
+		stdout:  stdout,
+		done:    make(chan struct{}),
+		stderr:  stderr,
+	}
+	if cmd != nil {
+		d.exited = make(chan struct{})
+	}
+	go d.readLoop()
+	return d
+}
+
+// initialize negotiates the protocol version and records the backend name and
+// implemented methods.
+func (d *ProcessDriver) initialize() error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutShort)
+	defer cancel()
+	var res processInitResult
+	err := d.roundTrip(ctx, "initialize",
+		processInitParams{ProtocolVersion: ProcessProtocolVersion, Client: "mardi-gras"}, &res)
+	if err != nil {
+		return fmt.Errorf("driver %s: initialize: %w", d.backend, err)
+	}
+	if res.ProtocolVersion != ProcessProtocolVersion {
+		return fmt.Errorf("driver %s: speaks protocol version %d, mg needs %d",
+			d.backend, res.ProtocolVersion, ProcessProtocolVersion)
+	}
+	if name := strings.TrimSpace(res.Backend); name != "" {
+		d.backend = name
+	}
+	d.methods = make(map[string]bool, len(res.Methods))
+	for _, m := range res.Methods {
+		d.methods[m] = true
+	}
+	return nil
+}
+
+// Close stops the driver process: stdin is closed so it can exit cleanly, and
+// it is killed if still running after a grace period. Safe to call twice.
+func (d *ProcessDriver) Close() error {
+	d.closeOnce.Do(func() {
+		_ = d.stdin.Close()
+		if d.cmd == nil {
+			_ = d.stdout.Close()
+			return
+		}
+		select {
+		case <-d.exited:
+		case <-time.After(2 * time.Second):
+			_ = d.cmd.Process.Signal(syscall.SIGTERM)
+			select {
+			case <-d.exited:
+			case <-time.After(time.Second):
+				_ = d.cmd.Process.Kill()
+				<-d.exited
+			}
+		}
+	})
+	return nil
+}
+
+// readLoop routes responses to their callers until stdout ends, then reaps
+// the process. Wait closes the stdout pipe, so it must not run before the
+// last read has returned.
+func (d *ProcessDriver) readLoop() {
+	defer func() {
+		close(d.done)
+		if d.cmd != nil {
+			_ = d.cmd.Wait()
+			close(d.exited)
+		}
+	}()
+	r := bufio.NewReader(d.stdout)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var resp processResponse
+			// Lines that are not responses (stray logging, notifications) are
+			// skipped rather than tearing the connection down.
+			if json.Unmarshal(line, &resp) == nil && resp.ID != nil {
+				if ch, ok := d.pending.LoadAndDelete(*resp.ID); ok {
+					ch.(chan processResponse) <- resp
+				}
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// exitErr explains why the connection is gone, preferring the driver's last
+// stderr line over a bare EOF.
+func (d *ProcessDriver) exitErr() error {
+	// stdout can hit EOF before the last stderr write has been copied; wait
+	// briefly for the process to be reaped so that line is not lost.
+	if d.exited != nil {
+		select {
+		case <-d.exited:
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+	if d.stderr != nil {
+		if last := d.stderr.lastLine(); last != "" {
+			return fmt.Errorf("driver %s exited: %s", d.backend, sanitizeOutput([]byte(last)))
+		}
+	}
+	return fmt.Errorf("driver %s exited", d.backend)
+}
+
+// roundTrip sends one request and decodes its result into out (which may be
+// nil). It returns when the driver answers, ctx ends, or the process exits.
+func (d *ProcessDriver) roundTrip(ctx context.Context, method string, params, out any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case <-d.done:
+		return d.exitErr()
+	default:
+	}
+
+	id := d.nextID.Add(1)
+	ch := make(chan processResponse, 1)
+	d.pending.Store(id, ch)
+	defer d.pending.Delete(id)
+
+	line, err := json.Marshal(processRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	d.wMu.Lock()
+	_, err = d.stdin.Write(append(line, '\n'))
+	d.wMu.Unlock()
+	if err != nil {
+		return d.exitErr()
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-d.done:
+		return d.exitErr()
+	case resp := <-ch:
+		if resp.Error != nil {
+			if resp.Error.Code == processRPCMethodNotFound {
+				return ErrUnsupported
+			}
+			if msg := sanitizeOutput([]byte(resp.Error.Message)); msg != "" {
+				return errors.New(msg)
+			}
+			return fmt.Errorf("error %d", resp.Error.Code)
+		}
+		if out == nil || len(resp.Result) == 0 || string(resp.Result) == "null" {
+			return nil
+		}
+		if err := json.Unmarshal(resp.Result, out); err != nil {
+			return fmt.Errorf("parse result: %w", err)
+		}
+		return nil
+	}
+}
+
+// call invokes a Driver method on the process. Undeclared methods are
+// ErrUnsupported without a round trip. A call whose ctx has no deadline gets
+// timeoutLong, so a wedged driver cannot hang the UI.
+func (d *ProcessDriver) call(ctx context.Context, method string, params, out any) error {
+	if !d.methods[method] {
+		return ErrUnsupported
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeoutLong)
+		defer cancel()
+	}
+	err := d.roundTrip(ctx, method, params, out)
+	if err != nil && !errors.Is(err, ErrUnsupported) {
+		return fmt.Errorf("%s %s: %w", d.backend, method, err)
+	}
+	return err
+}
+
+// processStderr keeps the driver's most recent stderr line for exit errors.
+// Stderr is never echoed: mg is a fullscreen TUI.
+type processStderr struct {
+	mu   sync.Mutex
+	tail []byte
+}
+
+func (s *processStderr) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tail = append(s.tail, p...)
+	if len(s.tail) > 4096 {
+		s.tail = s.tail[len(s.tail)-4096:]
+	}
+	return len(p), nil
+}
+
+func (s *processStderr) lastLine() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lines := strings.Split(strings.TrimSpace(string(s.tail)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// processFeatureMethods maps each Driver-backed feature to the method whose
+// presence implies it. Recovery, handoff and the activity feed are local gt
+// operations, and SSE is not part of the protocol, so a process driver never
+// claims them.
+var processFeatureMethods = map[Feature]string{
+	FeatureVitals:       "vitals",
+	FeatureCosts:        "costs",
+	FeaturePatrol:       "patrolScan",
+	FeatureTranscript:   "sessionTranscript",
+	FeatureNudge:        "nudge",
+	FeatureUnsling:      "unsling",
+	FeatureDecommission: "decommission",
+	FeatureAutoSling:    "sling",
+}
+
+// Backend reports the name the driver gave in its handshake, defaulting to
+// the binary's base name.
+func (d *ProcessDriver) Backend() string { return d.backend }
+
+func (d *ProcessDriver) Supports(feature Feature) bool {
+	m, ok := processFeatureMethods[feature]
+	return ok && d.methods[m]
+}
+
+// Wire params. Field names are part of the protocol; see docs/driver-protocol.md.
+
+type processIssueParams struct {
+	IssueID string `json:"issue_id"`
+}
+
+type processConvoyParams struct {
+	ConvoyID string `json:"convoy_id"`
+}
+
+type processMessageParams struct {
+	MessageID string `json:"message_id"`
+}
+
+// Reads.
+
+func (d *ProcessDriver) Status(ctx context.Context) (*TownStatus, error) {
+	var out TownStatus
+	if err := d.call(ctx, "status", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (d *ProcessDriver) Formulas(ctx context.Context) ([]string, error) {
+	var out []string
+	if err := d.call(ctx, "formulas", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (d *ProcessDriver) Comments(ctx context.Context, issueID string) ([]Comment, error) {
+	var out []Comment
+	if err := d.call(ctx, "comments", processIssueParams{IssueID: issueID}, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (d *ProcessDriver) SessionTranscript(ctx context.Context, target string) (*SessionTranscript, error) {
+	var out SessionTranscript
+	params := struct {
+		Target string `json:"target"`
+	}{target}
+	if err := d.call(ctx, "sessionTranscript", params, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Dispatch / lifecycle.
+
+// Sling forwards the whole request, including the Gas City-style Target/Rig
+// fields, and leaves fan-out over IssueIDs to the driver.
+func (d *ProcessDriver) Sling(ctx context.Context, req SlingRequest) error {
+	return d.call(ctx, "sling", req, nil)
+}
+
+func (d *ProcessDriver) Unsling(ctx context.Context, issueID string) error {
+	return d.call(ctx, "unsling", processIssueParams{IssueID: issueID}, nil)
+}
+
+func (d *ProcessDriver) Nudge(ctx context.Context, target, message string) error {
+	params := struct {
+		Target  string `json:"target"`
+		Message string `json:"message,omitempty"`
+	}{target, message}
+	return d.call(ctx, "nudge", params, nil)
+}
+
+func (d *ProcessDriver) Decommission(ctx context.Context, address string) error {
+	params := struct {
+		Address string `json:"address"`
+	}{address}
+	return d.call(ctx, "decommission", params, nil)
+}
+
+func (d *ProcessDriver) CascadeClose(ctx context.Context, issueID string) error {
+	return d.call(ctx, "cascadeClose", processIssueParams{IssueID: issueID}, nil)
+}
+
+// Assign returns the driver's human-readable summary, like gt assign's output.
+func (d *ProcessDriver) Assign(ctx context.Context, crewMember, title, issueType, priority, label string, nudge bool) (string, error) {
+	params := struct {
+		CrewMember string `json:"crew_member"`
+		Title      string `json:"title"`
+		IssueType  string `json:"issue_type,omitempty"`
+		Priority   string `json:"priority,omitempty"`
+		Label      string `json:"label,omitempty"`
+		Nudge      bool   `json:"nudge,omitempty"`
+	}{crewMember, title, issueType, priority, label, nudge}
+	var out string
+	if err := d.call(ctx, "assign", params, &out); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// Convoys.
+
+func (d *ProcessDriver) ConvoyList(ctx context.Context) ([]ConvoyDetail, error) {
+	var out []ConvoyDetail
+	if err := d.call(ctx, "convoyList", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (d *ProcessDriver) ConvoyStatus(ctx context.Context, convoyID string) (*ConvoyDetail, error) {
+	var out ConvoyDetail
+	if err := d.call(ctx, "convoyStatus", processConvoyParams{ConvoyID: convoyID}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (d *ProcessDriver) ConvoyCreate(ctx context.Context, name string, issueIDs []string) (string, error) {
+	params := struct {
+		Name     string   `json:"name"`
+		IssueIDs []string `json:"issue_ids"`
+	}{name, issueIDs}
+	var out string
+	if err := d.call(ctx, "convoyCreate", params, &out); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+func (d *ProcessDriver) ConvoyCreateFromEpic(ctx context.Context, name, epicID string) (string, error) {
+	params := struct {
+		Name   string `json:"name"`
+		EpicID string `json:"epic_id"`
+	}{name, epicID}
+	var out string
+	if err := d.call(ctx, "convoyCreateFromEpic", params, &out); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+func (d *ProcessDriver) ConvoyAdd(ctx context.Context, convoyID string, issueIDs []string) error {
+	params := struct {
+		ConvoyID string   `json:"convoy_id"`
+		IssueIDs []string `json:"issue_ids"`
+	}{convoyID, issueIDs}
+	return d.call(ctx, "convoyAdd", params, nil)
+}
+
+func (d *ProcessDriver) ConvoyClose(ctx context.Context, convoyID string) error {
+	return d.call(ctx, "convoyClose", processConvoyParams{ConvoyID: convoyID}, nil)
+}
+
+func (d *ProcessDriver) ConvoyLand(ctx context.Context, convoyID string) error {
+	return d.call(ctx, "convoyLand", processConvoyParams{ConvoyID: convoyID}, nil)
+}
+
+func (d *ProcessDriver) ConvoyWatch(ctx context.Context, convoyID string) error {
+	return d.call(ctx, "convoyWatch", processConvoyParams{ConvoyID: convoyID}, nil)
+}
+
+func (d *ProcessDriver) ConvoyUnwatch(ctx context.Context, convoyID string) error {
+	return d.call(ctx, "convoyUnwatch", processConvoyParams{ConvoyID: convoyID}, nil)
+}
+
+// Mail.
+
+func (d *ProcessDriver) MailInbox(ctx context.Context, unreadOnly bool) ([]MailMessage, error) {
+	params := struct {
+		UnreadOnly bool `json:"unread_only,omitempty"`
+	}{unreadOnly}
+	var out []MailMessage
+	if err := d.call(ctx, "mailInbox", params, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (d *ProcessDriver) MailRead(ctx context.Context, messageID string) (*MailMessage, error) {
+	var out MailMessage
+	if err := d.call(ctx, "mailRead", processMessageParams{MessageID: messageID}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (d *ProcessDriver) MailReply(ctx context.Context, messageID, body string) error {
+	params := struct {
+		MessageID string `json:"message_id"`
+		Body      string `json:"body"`
+	}{messageID, body}
+	return d.call(ctx, "mailReply", params, nil)
+}
+
+func (d *ProcessDriver) MailSend(ctx context.Context, address, subject, body string) error {
+	params := struct {
+		Address string `json:"address"`
+		Subject string `json:"subject"`
+		Body    string `json:"body"`
+	}{address, subject, body}
+	return d.call(ctx, "mailSend", params, nil)
+}
+
+func (d *ProcessDriver) MailArchive(ctx context.Context, messageID string) error {
+	return d.call(ctx, "mailArchive", processMessageParams{MessageID: messageID}, nil)
+}
+
+func (d *ProcessDriver) MailMarkRead(ctx context.Context, messageID string) error {
+	return d.call(ctx, "mailMarkRead", processMessageParams{MessageID: messageID}, nil)
+}
+
+func (d *ProcessDriver) MailMarkAllRead(ctx context.Context) error {
+	return d.call(ctx, "mailMarkAllRead", nil, nil)
+}
+
+// Molecule / workflow DAG.
+
+func (d *ProcessDriver) MoleculeDAG(ctx context.Context, rootID string) (*DAGInfo, error) {
+	params := struct {
+		RootID string `json:"root_id"`
+	}{rootID}
+	var out DAGInfo
+	if err := d.call(ctx, "moleculeDAG", params, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (d *ProcessDriver) MoleculeProgress(ctx context.Context, rootID string) (*MoleculeProgress, error) {
+	params := struct {
+		RootID string `json:"root_id"`
+	}{rootID}
+	var out MoleculeProgress
+	if err := d.call(ctx, "moleculeProgress", params, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (d *ProcessDriver) MoleculeStepDone(ctx context.Context, stepID string) (*StepDoneResult, error) {
+	params := struct {
+		StepID string `json:"step_id"`
+	}{stepID}
+	var out StepDoneResult
+	if err := d.call(ctx, "moleculeStepDone", params, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Health / analytics.
+
+func (d *ProcessDriver) Vitals(ctx context.Context) (*Vitals, error) {
+	var out Vitals
+	if err := d.call(ctx, "vitals", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (d *ProcessDriver) Costs(ctx context.Context) (*CostsOutput, error) {
+	var out CostsOutput
+	if err := d.call(ctx, "costs", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (d *ProcessDriver) PatrolScan(ctx context.Context) (*PatrolScanResult, error) {
+	var out PatrolScanResult
+	if err := d.call(ctx, "patrolScan", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,252 @@
+package gastown
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// pipeDriverHandler answers one request; a non-nil error becomes a JSON-RPC
+// error object.
+type pipeDriverHandler func(method string, params json.RawMessage) (any, *processRPCErr)
+
+// pipeDriver connects a ProcessDriver to an in-process fake over io.Pipe and
+// performs the handshake. calls counts non-initialize requests received.
+func pipeDriver(t *testing.T, methods []string, h pipeDriverHandler) (*ProcessDriver, *atomic.Int32, error) {
+	t.Helper()
+	reqR, reqW := io.Pipe()
+	respR, respW := io.Pipe()
+	var calls atomic.Int32
+
+	go func() {
+		defer respW.Close()
+		enc := json.NewEncoder(respW)
+		sc := bufio.NewScanner(reqR)
+		for sc.Scan() {
+			var req struct {
+				ID     int64           `json:"id"`
+				Method string          `json:"method"`
+				Params json.RawMessage `json:"params"`
+			}
+			if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+				return
+			}
+			var result any
+			var rerr *processRPCErr
+			if req.Method == "initialize" {
+				result = processInitResult{ProtocolVersion: ProcessProtocolVersion, Backend: "acme", Methods: methods}
+			} else {
+				calls.Add(1)
+				result, rerr = h(req.Method, req.Params)
+			}
+			msg := map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result}
+			if rerr != nil {
+				msg = map[string]any{"jsonrpc": "2.0", "id": req.ID, "error": rerr}
+			}
+			_ = enc.Encode(msg)
+		}
+	}()
+
+	d := newProcessDriver("/opt/bin/acme-driver", nil, nil, reqW, respR)
+	t.Cleanup(func() { _ = d.Close() })
+	return d, &calls, d.initialize()
+}
+
+func TestProcessDriverHandshake(t *testing.T) {
+	d, _, err := pipeDriver(t, []string{"status", "costs", "sessionTranscript"}, nil)
+	if err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+	if d.Backend() != "acme" {
+		t.Errorf("Backend() = %q, want the handshake's name", d.Backend())
+	}
+	for f, want := range map[Feature]bool{
+		FeatureCosts:        true,
+		FeatureTranscript:   true,
+		FeatureVitals:       false,
+		FeaturePatrol:       false,
+		FeatureRecovery:     false,
+		FeatureHandoff:      false,
+		FeatureActivityFeed: false,
+		FeatureSSE:          false,
+	} {
+		if got := d.Supports(f); got != want {
+			t.Errorf("Supports(%d) = %v, want %v", f, got, want)
+		}
+	}
+}
+
+func TestProcessDriverProtocolMismatch(t *testing.T) {
+	reqR, reqW := io.Pipe()
+	respR, respW := io.Pipe()
+	go func() {
+		sc := bufio.NewScanner(reqR)
+		if sc.Scan() {
+			_, _ = io.WriteString(respW, `{"jsonrpc":"2.0","id":1,"result":{"protocol_version":99,"backend":"acme"}}`+"\n")
+		}
+	}()
+	d := newProcessDriver("acme", nil, nil, reqW, respR)
+	defer d.Close()
+	err := d.initialize()
+	if err == nil || !strings.Contains(err.Error(), "protocol version 99") {
+		t.Fatalf("initialize = %v, want a protocol version error", err)
+	}
+}
+
+func TestProcessDriverUndeclaredMethodIsLocal(t *testing.T) {
+	d, calls, err := pipeDriver(t, []string{"status"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.ConvoyLand(context.Background(), "cv-1"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("ConvoyLand = %v, want ErrUnsupported", err)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("driver received %d requests for an undeclared method, want 0", n)
+	}
+}
+
+func TestProcessDriverMethodNotFoundIsUnsupported(t *testing.T) {
+	d, _, err := pipeDriver(t, []string{"vitals"}, func(string, json.RawMessage) (any, *processRPCErr) {
+		return nil, &processRPCErr{Code: processRPCMethodNotFound, Message: "no vitals here"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Vitals(context.Background()); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Vitals = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestProcessDriverErrorIsOneLine(t *testing.T) {
+	d, _, err := pipeDriver(t, []string{"unsling"}, func(string, json.RawMessage) (any, *processRPCErr) {
+		return nil, &processRPCErr{Code: 404, Message: "issue mg-9 not found\ntraceback: /home/me/sched/main.py:42"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.Unsling(context.Background(), "mg-9")
+	if err == nil {
+		t.Fatal("Unsling succeeded, want the driver's error")
+	}
+	if got, want := err.Error(), "acme unsling: issue mg-9 not found"; got != want {
+		t.Errorf("error = %q, want %q", got, want)
+	}
+}
+
+func TestProcessDriverWireShapes(t *testing.T) {
+	var gotMethod string
+	var gotParams json.RawMessage
+	d, _, err := pipeDriver(t, []string{"sling", "status"}, func(method string, params json.RawMessage) (any, *processRPCErr) {
+		gotMethod, gotParams = method, params
+		if method == "status" {
+			return json.RawMessage(`{"agents":[{"name":"atlas","running":true,"state":"working","hook_bead":"mg-1"}]}`), nil
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := SlingRequest{IssueIDs: []string{"mg-1", "mg-2"}, Target: "atlas", Formula: "shiny"}
+	if err := d.Sling(context.Background(), req); err != nil {
+		t.Fatalf("Sling: %v", err)
+	}
+	if gotMethod != "sling" {
+		t.Errorf("method = %q, want sling", gotMethod)
+	}
+	var sent map[string]any
+	if err := json.Unmarshal(gotParams, &sent); err != nil {
+		t.Fatal(err)
+	}
+	if sent["target"] != "atlas" || sent["formula"] != "shiny" || len(sent["issue_ids"].([]any)) != 2 {
+		t.Errorf("sling params = %s", gotParams)
+	}
+	if _, ok := sent["agent"]; ok {
+		t.Errorf("empty agent sent on the wire: %s", gotParams)
+	}
+
+	st, err := d.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if len(st.Agents) != 1 || st.Agents[0].HookBead != "mg-1" || !st.Agents[0].Running {
+		t.Errorf("Status = %+v", st)
+	}
+}
+
+func TestProcessDriverExitReportsStderr(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	// Answers the handshake, then dies on the first real request.
+	script := `#!/usr/bin/env bash
+read -r _
+echo '{"jsonrpc":"2.0","id":1,"result":{"protocol_version":1,"backend":"flaky","methods":["status"]}}'
+read -r _
+echo "scheduler: database is locked" >&2
+exit 3
+`
+	bin := filepath.Join(t.TempDir(), "flaky")
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewProcessDriver(bin)
+	if err != nil {
+		t.Fatalf("NewProcessDriver: %v", err)
+	}
+	defer d.Close()
+
+	_, err = d.Status(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "database is locked") {
+		t.Fatalf("Status after crash = %v, want the driver's last stderr line", err)
+	}
+	// The connection stays dead; later calls fail fast with the same story.
+	if _, err := d.Status(context.Background()); err == nil || !strings.Contains(err.Error(), "exited") {
+		t.Errorf("second Status = %v, want an exited error", err)
+	}
+}
+
+func TestNewProcessDriverMissingBinary(t *testing.T) {
+	if _, err := NewProcessDriver(filepath.Join(t.TempDir(), "nope")); err == nil {
+		t.Error("NewProcessDriver on a missing binary succeeded")
+	}
+}
+
+func TestProcessDriverPath(t *testing.T) {
+	for _, tc := range []struct{ env, want string }{
+		{"", ""},
+		{"gascity", ""},
+		{"exec:/usr/local/bin/sched", "/usr/local/bin/sched"},
+		{"  exec: /opt/sched  ", "/opt/sched"},
+		{"exec:", ""},
+	} {
+		t.Setenv(EnvDriver, tc.env)
+		if got := ProcessDriverPath(); got != tc.want {
+			t.Errorf("%s=%q: ProcessDriverPath() = %q, want %q", EnvDriver, tc.env, got, tc.want)
+		}
+		if got := ProcessEnabled(); got != (tc.want != "") {
+			t.Errorf("%s=%q: ProcessEnabled() = %v", EnvDriver, tc.env, got)
+		}
+	}
+}
+
+func TestSelectDriverProcessFallsBack(t *testing.T) {
+	t.Setenv(EnvGCAPI, "http://127.0.0.1:8080")
+	t.Setenv(EnvDriver, "exec:"+filepath.Join(t.TempDir(), "nope"))
+	d, err := SelectDriver()
+	if d.Backend() != BackendGasTown {
+		t.Errorf("unstartable %s: Backend() = %q, want gastown fallback", EnvDriver, d.Backend())
+	}
+	if err == nil || !strings.Contains(err.Error(), EnvDriver) {
+		t.Errorf("unstartable %s: err = %v, want it reported", EnvDriver, err)
+	}
+}
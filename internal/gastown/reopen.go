@@ -0,0 +1,78 @@
+package gastown
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/config"
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+// Reopen is a closed issue mg saw come back open. An issue's own fields
+// don't say whether it was closed before, so scorecards count reopens from
+// the status changes mg records between loads.
+type Reopen struct {
+	At       time.Time   `json:"at"`
+	IssueID  string      `json:"issue"`
+	Assignee string      `json:"assignee,omitempty"`
+	Status   data.Status `json:"status"` // the status it reopened to
+}
+
+// ReopenHistoryPath returns the reopen history path: MG_REOPEN_HISTORY if
+// set, otherwise mardi-gras/reopens.jsonl under the user config directory.
+func ReopenHistoryPath() string {
+	return config.Path("MG_REOPEN_HISTORY", "reopens.jsonl")
+}
+
+// DetectReopens compares issues with their previous statuses and returns the
+// ones that went from closed to anything else, stamped now.
+func DetectReopens(prev map[string]data.Status, issues []data.Issue, now time.Time) []Reopen {
+	var out []Reopen
+	for _, iss := range issues {
+		if prev[iss.ID] == data.StatusClosed && iss.Status != data.StatusClosed {
+			out = append(out, Reopen{At: now, IssueID: iss.ID, Assignee: iss.Assignee, Status: iss.Status})
+		}
+	}
+	return out
+}
+
+// RecordReopens appends reopens to the history at path.
+func RecordReopens(path string, reopens []Reopen) error {
+	for _, r := range reopens {
+		if err := config.AppendJSONL(path, r); err != nil {
+			return fmt.Errorf("reopen history: %w", err)
+		}
+	}
+	return nil
+}
+
+// LoadReopens reads the reopen history, oldest first. A missing file is an
+// empty history; unparseable lines are skipped.
+func LoadReopens(path string) ([]Reopen, error) {
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reopen history: %w", err)
+	}
+	var out []Reopen
+	sc := bufio.NewScanner(bytes.NewReader(raw))
+	for sc.Scan() {
+		var r Reopen
+		if json.Unmarshal(sc.Bytes(), &r) != nil || r.IssueID == "" {
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out, nil
+}
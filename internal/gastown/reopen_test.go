@@ -0,0 +1,37 @@
+package gastown
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+func TestReopenHistory(t *testing.T) {
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	prev := map[string]data.Status{"a": data.StatusClosed, "b": data.StatusClosed, "c": data.StatusOpen}
+	issues := []data.Issue{
+		{ID: "a", Status: data.StatusOpen, Assignee: "Toast"},
+		{ID: "b", Status: data.StatusClosed, Assignee: "Toast"},
+		{ID: "c", Status: data.StatusInProgress, Assignee: "Muffin"},
+		{ID: "d", Status: data.StatusOpen},
+	}
+	got := DetectReopens(prev, issues, now)
+	if len(got) != 1 || got[0].IssueID != "a" || got[0].Assignee != "Toast" || got[0].Status != data.StatusOpen {
+		t.Fatalf("DetectReopens = %+v, want only a", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "reopens.jsonl")
+	if loaded, err := LoadReopens(path); err != nil || loaded != nil {
+		t.Fatalf("missing file = %v, %v", loaded, err)
+	}
+	later := Reopen{At: now.Add(time.Hour), IssueID: "e", Assignee: "Muffin"}
+	if err := RecordReopens(path, append([]Reopen{later}, got...)); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadReopens(path)
+	if err != nil || len(loaded) != 2 || loaded[0].IssueID != "a" || loaded[1].IssueID != "e" {
+		t.Fatalf("LoadReopens = %+v, %v; want a then e", loaded, err)
+	}
+}
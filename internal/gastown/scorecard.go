@@ -2,6 +2,7 @@ package gastown
 
 import (
 	"sort"
+	"time"
 
 	"github.com/matt-wright86/mardi-gras/internal/data"
 )
@@ -10,41 +11,219 @@ import (
 type AgentScorecard struct {
 	Name         string
 	IssuesClosed int
+
+	// MedianCycle is the median StartedAt → ClosedAt time of the agent's
+	// closed issues; zero when none recorded a start.
+	MedianCycle time.Duration
+
+	// Reopened counts closed issues assigned to the agent that mg saw come
+	// back open (see DetectReopens).
+	Reopened int
+
+	// AbandonedHooks counts work the agent dropped: occurrences of zombie
+	// and patrol zombie/orphan problems in the problem history, each a
+	// session that stopped with work still hooked.
+	AbandonedHooks int
+
+	// CommentsPerIssue is the mean comment count across closed issues.
+	CommentsPerIssue float64
+
+	// Cost is the spend attributed to the agent from CostsOutput, valid when
+	// CostKnown: the exact figures of the attribution per-issue costs use,
+	// its role's cost when it is the only agent in that role, or its rig's
+	// cost when it is the only agent on that rig.
+	Cost      float64
+	CostKnown bool
+
+	// Week-over-week trend: closes and median cycle time over the last seven
+	// days against the seven before.
+	ClosedThisWeek int
+	ClosedLastWeek int
+	CycleThisWeek  time.Duration
+	CycleLastWeek  time.Duration
+}
+
+// CostPerClosed returns the attributed cost divided by issues closed.
+func (sc AgentScorecard) CostPerClosed() (float64, bool) {
+	if !sc.CostKnown || sc.IssuesClosed == 0 {
+		return 0, false
+	}
+	return sc.Cost / float64(sc.IssuesClosed), true
+}
+
+// ClosedTrend is +1 when the agent closed more issues this week than last,
+// -1 when fewer, and 0 when level.
+func (sc AgentScorecard) ClosedTrend() int {
+	return trend(float64(sc.ClosedThisWeek), float64(sc.ClosedLastWeek))
+}
+
+// CycleTrend is +1 when this week's median cycle time is longer than last
+// week's, -1 when shorter, and 0 when level or either week has no closes.
+func (sc AgentScorecard) CycleTrend() int {
+	if sc.CycleThisWeek == 0 || sc.CycleLastWeek == 0 {
+		return 0
+	}
+	return trend(float64(sc.CycleThisWeek), float64(sc.CycleLastWeek))
 }
 
-// ComputeScorecards derives per-agent scorecards from closed issues.
+func trend(cur, prev float64) int {
+	switch {
+	case cur > prev:
+		return 1
+	case cur < prev:
+		return -1
+	}
+	return 0
+}
+
+// ComputeScorecards derives per-agent scorecards from issues alone.
 // It maps issues to agents via the Assignee field.
 func ComputeScorecards(issues []data.Issue) []AgentScorecard {
+	return ComputeScorecardsFrom(issues, nil, nil, nil, nil, time.Now())
+}
+
+// ComputeScorecardsFrom derives per-agent scorecards from issues, the agent
+// roster, costs, the problem history and the reopen history. All but issues
+// are optional (nil-safe); each missing source leaves its columns empty.
+func ComputeScorecardsFrom(issues []data.Issue, status *TownStatus, costs *CostsOutput, problems []TrackedProblem, reopens []Reopen, now time.Time) []AgentScorecard {
 	agents := make(map[string]*AgentScorecard)
+	card := func(name string) *AgentScorecard {
+		sc, ok := agents[name]
+		if !ok {
+			sc = &AgentScorecard{Name: name}
+			agents[name] = sc
+		}
+		return sc
+	}
+
+	weekStart := now.AddDate(0, 0, -7)
+	prevStart := now.AddDate(0, 0, -14)
+	cycles := make(map[string][]time.Duration)
+	thisWeek := make(map[string][]time.Duration)
+	lastWeek := make(map[string][]time.Duration)
+	comments := make(map[string]int)
 
 	for _, iss := range issues {
+		name := iss.Assignee
+		if name == "" {
+			continue
+		}
 		if iss.Status != data.StatusClosed {
 			continue
 		}
 
-		name := iss.Assignee
-		if name == "" {
+		sc := card(name)
+		sc.IssuesClosed++
+		comments[name] += iss.CommentCount
+
+		if iss.ClosedAt == nil {
 			continue
 		}
+		var cycle time.Duration
+		if iss.StartedAt != nil && !iss.ClosedAt.Before(*iss.StartedAt) {
+			cycle = iss.ClosedAt.Sub(*iss.StartedAt)
+			cycles[name] = append(cycles[name], cycle)
+		}
+		switch {
+		case !iss.ClosedAt.Before(weekStart):
+			sc.ClosedThisWeek++
+			if cycle > 0 {
+				thisWeek[name] = append(thisWeek[name], cycle)
+			}
+		case !iss.ClosedAt.Before(prevStart):
+			sc.ClosedLastWeek++
+			if cycle > 0 {
+				lastWeek[name] = append(lastWeek[name], cycle)
+			}
+		}
+	}
 
-		sc, ok := agents[name]
-		if !ok {
-			sc = &AgentScorecard{Name: name}
-			agents[name] = sc
+	for _, r := range reopens {
+		if r.Assignee != "" {
+			card(r.Assignee).Reopened++
 		}
+	}
 
-		sc.IssuesClosed++
+	// Abandoned hooks: each raise of a zombie or orphan problem is a session
+	// that stopped holding work. Match against cards in name order so an
+	// agent that several names could mean always lands on the same card.
+	names := make([]string, 0, len(agents))
+	for name := range agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, p := range problems {
+		if !abandonedHook(p.Type) {
+			continue
+		}
+		if sc := matchCard(agents, names, p.Agent); sc != nil {
+			sc.AbandonedHooks += max(p.Occurrences, 1)
+		}
 	}
 
 	result := make([]AgentScorecard, 0, len(agents))
-	for _, sc := range agents {
+	for name, sc := range agents {
+		sc.MedianCycle = data.Percentile(cycles[name], 0.5)
+		sc.CycleThisWeek = data.Percentile(thisWeek[name], 0.5)
+		sc.CycleLastWeek = data.Percentile(lastWeek[name], 0.5)
+		if sc.IssuesClosed > 0 {
+			sc.CommentsPerIssue = float64(comments[name]) / float64(sc.IssuesClosed)
+		}
+		sc.Cost, sc.CostKnown = scorecardCost(name, status, costs)
 		result = append(result, *sc)
 	}
 
 	// Sort by issues closed descending
 	sort.Slice(result, func(i, j int) bool {
-		return result[i].IssuesClosed > result[j].IssuesClosed
+		if result[i].IssuesClosed != result[j].IssuesClosed {
+			return result[i].IssuesClosed > result[j].IssuesClosed
+		}
+		return result[i].Name < result[j].Name
 	})
 
 	return result
 }
+
+// scorecardCost is the spend attributed to one agent from the current
+// totals. Scorecards show only exact figures: an even split of a shared role
+// is left out rather than shown as the agent's own cost.
+func scorecardCost(name string, status *TownStatus, costs *CostsOutput) (float64, bool) {
+	agent := rosterAgent(status, name)
+	if agent == nil || costs == nil {
+		return 0, false
+	}
+	var d CostDay
+	d.add(CostSample{CostsOutput: *costs}, 1)
+	cost, _, exact := attributeCost(*agent, status, d.ByRole, d.ByRig)
+	return cost, exact
+}
+
+// abandonedHook reports whether a problem type means a stopped session left
+// work hooked: DetectProblems' zombies and patrol's zombies and orphans.
+func abandonedHook(typ string) bool {
+	switch typ {
+	case "zombie", "patrol_zombie", "patrol_orphan":
+		return true
+	}
+	return false
+}
+
+// matchCard finds the card for a problem's agent: an exact name or address
+// first, then the first of the sorted names the agent matches.
+func matchCard(agents map[string]*AgentScorecard, names []string, a AgentRuntime) *AgentScorecard {
+	if a.Name == "" && a.Address == "" {
+		return nil
+	}
+	if sc, ok := agents[a.Name]; ok {
+		return sc
+	}
+	if sc, ok := agents[a.Address]; ok {
+		return sc
+	}
+	for _, name := range names {
+		if a.Matches(name) {
+			return agents[name]
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package gastown
 
 import (
 	"testing"
+	"time"
 
 	"github.com/matt-wright86/mardi-gras/internal/data"
 )
@@ -59,3 +60,113 @@ func TestComputeScorecardsNoClosedIssues(t *testing.T) {
 		t.Fatalf("expected 0 scorecards for no closed issues, got %d", len(cards))
 	}
 }
+
+func TestComputeScorecardsFrom(t *testing.T) {
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	closed := func(id, who string, daysAgo int, cycle time.Duration, comments int) data.Issue {
+		c := now.AddDate(0, 0, -daysAgo)
+		s := c.Add(-cycle)
+		return data.Issue{ID: id, Status: data.StatusClosed, Assignee: who,
+			CreatedAt: s.Add(-time.Hour), StartedAt: &s, ClosedAt: &c, CommentCount: comments}
+	}
+	issues := []data.Issue{
+		closed("a", "Toast", 1, 2*time.Hour, 4),
+		closed("b", "Toast", 2, 4*time.Hour, 0),
+		closed("c", "Toast", 9, 8*time.Hour, 2),
+		closed("d", "Muffin", 10, time.Hour, 1),
+		{ID: "e", Status: data.StatusOpen, Assignee: "Toast"},
+	}
+	status := &TownStatus{Agents: []AgentRuntime{
+		{Name: "Toast", Role: "polecat", Rig: "gastown", Address: "gastown/polecats/Toast", Running: true},
+		{Name: "Muffin", Role: "polecat", Rig: "beads", Address: "beads/polecats/Muffin", HookBead: "mg-9"},
+	}}
+	costs := &CostsOutput{
+		ByRole: []RoleCost{{Role: "polecat", Cost: 9}},
+		ByRig:  []RigCost{{Rig: "gastown", Cost: 6}, {Rig: "beads", Cost: 2}},
+	}
+	reopens := []Reopen{{At: now.AddDate(0, 0, -3), IssueID: "e", Assignee: "Toast", Status: data.StatusOpen}}
+	problems := []TrackedProblem{
+		{Problem: Problem{Type: "zombie", Agent: status.Agents[1]}, Occurrences: 2},
+		{Problem: Problem{Type: "patrol_orphan", Agent: AgentRuntime{Name: "Toast"}}, Occurrences: 1},
+		{Problem: Problem{Type: "stalled", Agent: status.Agents[0]}, Occurrences: 5},
+		{Problem: Problem{Type: "patrol_zombie", RigName: "gastown"}, Occurrences: 1},
+	}
+
+	cards := ComputeScorecardsFrom(issues, status, costs, problems, reopens, now)
+	if len(cards) != 2 || cards[0].Name != "Toast" {
+		t.Fatalf("cards = %+v, want Toast then Muffin", cards)
+	}
+	toast, muffin := cards[0], cards[1]
+
+	if toast.IssuesClosed != 3 || toast.MedianCycle != 4*time.Hour {
+		t.Errorf("Toast closed %d median %v, want 3 and 4h", toast.IssuesClosed, toast.MedianCycle)
+	}
+	if toast.Reopened != 1 {
+		t.Errorf("Toast Reopened = %d, want 1", toast.Reopened)
+	}
+	if toast.CommentsPerIssue != 2 {
+		t.Errorf("Toast CommentsPerIssue = %v, want 2", toast.CommentsPerIssue)
+	}
+	if toast.AbandonedHooks != 1 || muffin.AbandonedHooks != 2 {
+		t.Errorf("abandoned hooks Toast %d Muffin %d, want 1 (orphan) and 2 (zombie twice)", toast.AbandonedHooks, muffin.AbandonedHooks)
+	}
+
+	// Two polecats share the role, so costs fall back to each one's rig.
+	if cpc, ok := toast.CostPerClosed(); !ok || cpc != 2 {
+		t.Errorf("Toast cost per closed = %v %v, want 2", cpc, ok)
+	}
+	if cpc, ok := muffin.CostPerClosed(); !ok || cpc != 2 {
+		t.Errorf("Muffin cost per closed = %v %v, want 2", cpc, ok)
+	}
+
+	if toast.ClosedThisWeek != 2 || toast.ClosedLastWeek != 1 || toast.ClosedTrend() != 1 {
+		t.Errorf("Toast weekly closes %d/%d trend %d, want 2/1 up", toast.ClosedThisWeek, toast.ClosedLastWeek, toast.ClosedTrend())
+	}
+	if toast.CycleTrend() != -1 {
+		t.Errorf("Toast cycle trend = %d, want faster (-1): %v vs %v", toast.CycleTrend(), toast.CycleThisWeek, toast.CycleLastWeek)
+	}
+	if muffin.ClosedTrend() != -1 || muffin.CycleTrend() != 0 {
+		t.Errorf("Muffin trends closed %d cycle %d, want -1 and 0", muffin.ClosedTrend(), muffin.CycleTrend())
+	}
+}
+
+func TestScorecardCostUnattributable(t *testing.T) {
+	status := &TownStatus{Agents: []AgentRuntime{
+		{Name: "Toast", Role: "polecat", Rig: "gastown"},
+		{Name: "Muffin", Role: "polecat", Rig: "gastown"},
+	}}
+	costs := &CostsOutput{ByRole: []RoleCost{{Role: "polecat", Cost: 9}}, ByRig: []RigCost{{Rig: "gastown", Cost: 9}}}
+	now := time.Now()
+	cards := ComputeScorecardsFrom([]data.Issue{{ID: "a", Status: data.StatusClosed, Assignee: "Toast", ClosedAt: &now}}, status, costs, nil, nil, now)
+	if _, ok := cards[0].CostPerClosed(); ok {
+		t.Error("cost shared by role and rig should not be attributed")
+	}
+}
+
+func TestScorecardAbandonedHooksMatchOneCard(t *testing.T) {
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	issues := []data.Issue{
+		{ID: "a", Status: data.StatusClosed, Assignee: "polecats/Toast", ClosedAt: &now},
+		{ID: "b", Status: data.StatusClosed, Assignee: "Toast", ClosedAt: &now},
+		{ID: "c", Status: data.StatusClosed, Assignee: "beads/polecats/Toast", ClosedAt: &now},
+	}
+	// Matches every card's name by suffix; exact names win, then sorted order.
+	byAddress := TrackedProblem{Problem: Problem{Type: "zombie", Agent: AgentRuntime{Address: "gastown/polecats/Toast"}}, Occurrences: 1}
+	for range 20 {
+		cards := ComputeScorecardsFrom(issues, nil, nil, []TrackedProblem{byAddress}, nil, now)
+		got := map[string]int{}
+		for _, c := range cards {
+			got[c.Name] = c.AbandonedHooks
+		}
+		if got["Toast"] != 1 || got["polecats/Toast"] != 0 || got["beads/polecats/Toast"] != 0 {
+			t.Fatalf("abandoned hooks = %v, want only Toast", got)
+		}
+	}
+	exact := TrackedProblem{Problem: Problem{Type: "zombie", Agent: AgentRuntime{Name: "Toast", Address: "beads/polecats/Toast"}}}
+	cards := ComputeScorecardsFrom(issues, nil, nil, []TrackedProblem{exact}, nil, now)
+	for _, c := range cards {
+		if want := map[string]int{"Toast": 1}[c.Name]; c.AbandonedHooks != want {
+			t.Errorf("%s abandoned hooks = %d, want %d", c.Name, c.AbandonedHooks, want)
+		}
+	}
+}
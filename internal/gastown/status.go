@@ -3,6 +3,7 @@ package gastown
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 // TownStatus is the normalized view of `gt status --json`.
@@ -148,6 +149,15 @@ func normalizeStatus(raw *rawTownStatus) *TownStatus {
 	return status
 }
 
+// Matches reports whether who, a bd assignee or event-log actor, names this
+// agent: by name, by address, or by the last segment of its address.
+func (a AgentRuntime) Matches(who string) bool {
+	if who == "" {
+		return false
+	}
+	return a.Name == who || a.Address == who || strings.HasSuffix(a.Address, "/"+who)
+}
+
 // AgentForIssue returns the agent working on a given issue, if any.
 func (s *TownStatus) AgentForIssue(issueID string) *AgentRuntime {
 	if s == nil {
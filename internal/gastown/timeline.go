@@ -0,0 +1,157 @@
+package gastown
+
+import (
+	"sort"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+// TimelineMember is one convoy member placed on a time axis. Dates come from
+// the local issue list; members mg has no record of (another rig's beads)
+// have Known=false and no dates.
+type TimelineMember struct {
+	TrackedIssueInfo
+	Known   bool
+	Created time.Time
+	Started time.Time // zero when never started (or bd did not record it)
+	Closed  time.Time // zero while unfinished
+
+	// ProjectedStart/End place unfinished members forward from now at the
+	// convoy's close rate. Zero when there is no velocity to project from.
+	ProjectedStart time.Time
+	ProjectedEnd   time.Time
+
+	BlockedBy  []string // open blockers from the local dependency graph
+	WorkerIdle string   // "idle", "stuck", "backoff" or "offline" when the worker is not progressing
+}
+
+// Done reports whether the member is closed.
+func (m TimelineMember) Done() bool { return !m.Closed.IsZero() || m.Status == "closed" }
+
+// Active reports whether the member is being worked.
+func (m TimelineMember) Active() bool { return m.Status == "in_progress" || m.Status == "hooked" }
+
+// IsBlocked reports whether the member waits on an open blocker, either per
+// the orchestrator or per the local dependency graph.
+func (m TimelineMember) IsBlocked() bool { return !m.Done() && (m.Blocked || len(m.BlockedBy) > 0) }
+
+// ConvoyTimeline lays a convoy's members out on a shared time axis running
+// from the earliest member's creation to the later of now and the projected
+// completion.
+type ConvoyTimeline struct {
+	Convoy  ConvoyDetail
+	Members []TimelineMember
+	Start   time.Time
+	End     time.Time
+	Now     time.Time
+	ETA     time.Time // projected completion; zero when unknown or already done
+}
+
+// BuildConvoyTimeline joins a convoy's tracked issues with their local issue
+// history and the agent roster. Unfinished members are projected forward one
+// after another at the velocity's daily close rate (the same rate
+// PredictConvoys uses, so the last projected end matches its ETA): active
+// work first, then ready work, then blocked work. status and velocity may be
+// nil.
+func BuildConvoyTimeline(c ConvoyDetail, issues []data.Issue, status *TownStatus, velocity *VelocityMetrics, blockingTypes map[string]bool, now time.Time) ConvoyTimeline {
+	issueMap := data.BuildIssueMap(issues)
+	tl := ConvoyTimeline{Convoy: c, Now: now, Start: now, End: now}
+
+	for _, t := range c.Tracked {
+		m := TimelineMember{TrackedIssueInfo: t}
+		if iss, ok := issueMap[t.ID]; ok {
+			m.Known = true
+			m.Created = iss.CreatedAt
+			if iss.StartedAt != nil {
+				m.Started = *iss.StartedAt
+			}
+			if iss.ClosedAt != nil && iss.Status == data.StatusClosed {
+				m.Closed = *iss.ClosedAt
+			}
+			if iss.Status != data.StatusClosed {
+				m.BlockedBy = iss.EvaluateDependencies(issueMap, blockingTypes).BlockingIDs
+			}
+			if !m.Created.IsZero() && m.Created.Before(tl.Start) {
+				tl.Start = m.Created
+			}
+			if m.Closed.After(tl.End) {
+				tl.End = m.Closed
+			}
+		}
+		if !m.Done() && m.Worker != "" && status != nil {
+			m.WorkerIdle = workerIdleReason(status.Agents, m.Worker)
+		}
+		tl.Members = append(tl.Members, m)
+	}
+
+	if velocity == nil {
+		return tl
+	}
+	rate := dailyCloseRate(velocity)
+	if rate <= 0 {
+		return tl
+	}
+	interval := time.Duration(float64(24*time.Hour) / rate)
+
+	var pending []int
+	for i, m := range tl.Members {
+		if !m.Done() {
+			pending = append(pending, i)
+		}
+	}
+	rank := func(m TimelineMember) int {
+		switch {
+		case m.Active() && !m.IsBlocked():
+			return 0
+		case !m.IsBlocked():
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(pending, func(a, b int) bool {
+		return rank(tl.Members[pending[a]]) < rank(tl.Members[pending[b]])
+	})
+	for k, i := range pending {
+		m := &tl.Members[i]
+		m.ProjectedStart = now.Add(time.Duration(k) * interval)
+		if m.Active() {
+			m.ProjectedStart = now // already underway
+		}
+		m.ProjectedEnd = now.Add(time.Duration(k+1) * interval)
+		tl.ETA = m.ProjectedEnd
+	}
+	if tl.ETA.After(tl.End) {
+		tl.End = tl.ETA
+	}
+	return tl
+}
+
+// workerIdleReason explains why the named worker is not progressing its
+// hooked work, or returns "" when it is. Workers are matched by name or by
+// address (or its last segment, "rig/name").
+func workerIdleReason(agents []AgentRuntime, worker string) string {
+	for _, a := range agents {
+		if !a.Matches(worker) {
+			continue
+		}
+		switch {
+		case !a.Running:
+			return "offline"
+		case a.State == "stuck", a.State == "backoff", a.State == "idle":
+			return a.State
+		}
+		return ""
+	}
+	return "offline"
+}
+
+// ETALabel renders the time from now to the projected completion ("1.5d"),
+// or "" when there is no projection.
+func (tl ConvoyTimeline) ETALabel() string {
+	if tl.ETA.IsZero() {
+		return ""
+	}
+	return formatETA(tl.ETA.Sub(tl.Now))
+}
@@ -0,0 +1,88 @@
+package gastown
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matt-wright86/mardi-gras/internal/data"
+)
+
+func TestBuildConvoyTimeline(t *testing.T) {
+	now := time.Date(2026, 6, 10, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-72 * time.Hour)
+	started := now.Add(-24 * time.Hour)
+	closed := now.Add(-12 * time.Hour)
+	issues := []data.Issue{
+		{ID: "mg-1", Status: data.StatusClosed, CreatedAt: created, StartedAt: &started, ClosedAt: &closed},
+		{ID: "mg-2", Status: data.StatusInProgress, CreatedAt: created.Add(time.Hour), StartedAt: &started},
+		{ID: "mg-3", Status: data.StatusOpen, CreatedAt: created,
+			Dependencies: []data.Dependency{{IssueID: "mg-3", DependsOnID: "mg-2", Type: "blocks"}}},
+		{ID: "mg-4", Status: data.StatusOpen, CreatedAt: created},
+	}
+	convoy := ConvoyDetail{ID: "cv-1", Tracked: []TrackedIssueInfo{
+		{ID: "mg-1", Status: "closed"},
+		{ID: "mg-2", Status: "in_progress", Worker: "atlas"},
+		{ID: "mg-3", Status: "open"},
+		{ID: "mg-4", Status: "open"},
+		{ID: "other-9", Status: "open"}, // another rig's bead
+	}}
+	status := &TownStatus{Agents: []AgentRuntime{{Name: "atlas", Address: "core/atlas", Running: true, State: "idle"}}}
+	velocity := &VelocityMetrics{ClosedWeek: 14} // 2/day -> one member every 12h
+
+	tl := BuildConvoyTimeline(convoy, issues, status, velocity, data.DefaultBlockingTypes, now)
+
+	if !tl.Start.Equal(created) {
+		t.Errorf("Start = %v, want earliest created %v", tl.Start, created)
+	}
+	byID := make(map[string]TimelineMember)
+	for _, m := range tl.Members {
+		byID[m.ID] = m
+	}
+	if m := byID["mg-1"]; !m.Done() || !m.Closed.Equal(closed) || !m.ProjectedEnd.IsZero() {
+		t.Errorf("closed member = %+v, want real close and no projection", m)
+	}
+	if m := byID["mg-2"]; m.WorkerIdle != "idle" || !m.ProjectedStart.Equal(now) || !m.ProjectedEnd.Equal(now.Add(12*time.Hour)) {
+		t.Errorf("active member = idle %q projected %v..%v", m.WorkerIdle, m.ProjectedStart, m.ProjectedEnd)
+	}
+	// Ready work (mg-4, other-9) is projected before blocked work (mg-3).
+	if m := byID["mg-3"]; !m.IsBlocked() || len(m.BlockedBy) != 1 || !m.ProjectedEnd.Equal(now.Add(48*time.Hour)) {
+		t.Errorf("blocked member = blockedBy %v projected end %v, want last", m.BlockedBy, m.ProjectedEnd)
+	}
+	if m := byID["other-9"]; m.Known {
+		t.Error("a bead missing from the local list should not be Known")
+	}
+	if !tl.ETA.Equal(now.Add(48*time.Hour)) || !tl.End.Equal(tl.ETA) {
+		t.Errorf("ETA = %v End = %v, want both %v", tl.ETA, tl.End, now.Add(48*time.Hour))
+	}
+	if got := tl.ETALabel(); got != "2.0d" {
+		t.Errorf("ETALabel() = %q, want 2.0d", got)
+	}
+}
+
+func TestBuildConvoyTimelineNoVelocity(t *testing.T) {
+	now := time.Now()
+	convoy := ConvoyDetail{ID: "cv-1", Tracked: []TrackedIssueInfo{{ID: "mg-1", Status: "open"}}}
+	tl := BuildConvoyTimeline(convoy, nil, nil, &VelocityMetrics{}, data.DefaultBlockingTypes, now)
+	if !tl.ETA.IsZero() || tl.ETALabel() != "" || !tl.Members[0].ProjectedEnd.IsZero() {
+		t.Errorf("no close rate should mean no projection, got ETA %v", tl.ETA)
+	}
+}
+
+func TestWorkerIdleReason(t *testing.T) {
+	agents := []AgentRuntime{
+		{Name: "atlas", Address: "core/atlas", Running: true, State: "working"},
+		{Name: "bishop", Running: false},
+		{Name: "cairn", Address: "core/cairn", Running: true, State: "stuck"},
+	}
+	for worker, want := range map[string]string{
+		"atlas":      "",
+		"core/atlas": "",
+		"bishop":     "offline",
+		"cairn":      "stuck",
+		"nobody":     "offline",
+	} {
+		if got := workerIdleReason(agents, worker); got != want {
+			t.Errorf("workerIdleReason(%q) = %q, want %q", worker, got, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package gastown
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// TranscriptEntry is one unit of an agent session transcript: a conversation
+// turn on Gas City, or a single captured terminal line on Gas Town.
+type TranscriptEntry struct {
+	Role string    `json:"role,omitempty"` // "user", "assistant", "system", … ("" for raw terminal lines)
+	Text string    `json:"text"`           // turn text; may span several lines
+	Time time.Time `json:"time,omitzero"`  // zero when the backend does not report one
+}
+
+// SessionTranscript is the readable history of an agent's session, oldest
+// entry first.
+type SessionTranscript struct {
+	Session  string            `json:"session"`            // session id (Gas City) or tmux session name (Gas Town)
+	Provider string            `json:"provider,omitempty"` // producing provider (claude, codex, …); "" when unknown
+	Entries  []TranscriptEntry `json:"entries"`
+}
+
+// transcriptHistoryLines bounds how much tmux scrollback a Gas Town capture
+// pulls. Agents can run for hours; the overlay only needs recent history.
+const transcriptHistoryLines = 2000
+
+// CaptureSessionHistory reads the scrollback of a gt agent's tmux session
+// (`tmux capture-pane -p -J -S -N -t <session>`). Wrapped lines are joined
+// (-J) so search matches text the agent printed as one line.
+func CaptureSessionHistory(session string) (*SessionTranscript, error) {
+	session = strings.TrimSpace(session)
+	if session == "" {
+		return nil, fmt.Errorf("tmux capture: agent has no session")
+	}
+	out, err := runWithTimeout(timeoutShort, "tmux", "capture-pane", "-p", "-J",
+		"-S", fmt.Sprintf("-%d", transcriptHistoryLines), "-t", session)
+	if err != nil {
+		return nil, fmt.Errorf("tmux capture %s: %w", session, err)
+	}
+	return &SessionTranscript{Session: session, Entries: parseCapturedHistory(string(out))}, nil
+}
+
+// parseCapturedHistory turns raw capture-pane output into one entry per line,
+// stripping ANSI sequences and stray control bytes and dropping the trailing
+// blank rows tmux pads the visible pane with.
+func parseCapturedHistory(raw string) []TranscriptEntry {
+	clean := strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' {
+			return r
+		}
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, ansi.Strip(raw))
+	lines := strings.Split(clean, "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	entries := make([]TranscriptEntry, 0, len(lines))
+	for _, ln := range lines {
+		entries = append(entries, TranscriptEntry{Text: strings.TrimRight(ln, " ")})
+	}
+	return entries
+}
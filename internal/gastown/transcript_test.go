@@ -0,0 +1,55 @@
+package gastown
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseCapturedHistory(t *testing.T) {
+	raw := "\x1b[1;32m$ make test\x1b[0m\nok  \tmardi-gras\t0.4s   \n\x07done\n\n\n"
+	got := parseCapturedHistory(raw)
+	want := []string{"$ make test", "ok  \tmardi-gras\t0.4s", "done"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(got), len(want), got)
+	}
+	for i, e := range got {
+		if e.Text != want[i] || e.Role != "" {
+			t.Errorf("entry[%d] = %+v, want raw line %q", i, e, want[i])
+		}
+	}
+}
+
+func TestParseCapturedHistoryEmpty(t *testing.T) {
+	if got := parseCapturedHistory("\n\n  \n"); got != nil {
+		t.Errorf("blank capture = %+v, want nil", got)
+	}
+}
+
+func TestCaptureSessionHistoryArgs(t *testing.T) {
+	calls, restore := mockRunCapture([]byte("hello\n"), nil)
+	defer restore()
+
+	tr, err := CaptureSessionHistory("gt-mardi-toast")
+	if err != nil {
+		t.Fatalf("CaptureSessionHistory: %v", err)
+	}
+	if tr.Session != "gt-mardi-toast" || len(tr.Entries) != 1 {
+		t.Errorf("transcript = %+v", tr)
+	}
+	want := []string{"tmux", "capture-pane", "-p", "-J", "-S", "-2000", "-t", "gt-mardi-toast"}
+	if len(*calls) != 1 || !slices.Equal((*calls)[0], want) {
+		t.Errorf("calls = %v, want %v", *calls, want)
+	}
+}
+
+func TestCaptureSessionHistoryErrors(t *testing.T) {
+	if _, err := CaptureSessionHistory("  "); err == nil {
+		t.Error("expected error for an agent without a session")
+	}
+	restore := mockRun(nil, errors.New("can't find session"))
+	defer restore()
+	if _, err := CaptureSessionHistory("gt-gone"); err == nil {
+		t.Error("expected capture failure to surface")
+	}
+}
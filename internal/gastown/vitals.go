@@ -8,28 +8,28 @@ import (
 
 // Vitals represents parsed output from `gt vitals`.
 type Vitals struct {
-	Servers []DoltServer
-	Backups BackupStatus
-	Raw     string // fallback if parsing fails
+	Servers []DoltServer `json:"servers"`
+	Backups BackupStatus `json:"backups"`
+	Raw     string       `json:"raw,omitempty"` // fallback if parsing fails
 }
 
 // DoltServer represents a single Dolt server entry from vitals output.
 type DoltServer struct {
-	Port        string
-	Label       string
-	PID         int
-	DiskUsage   string
-	Connections string
-	Latency     string
-	Running     bool
+	Port        string `json:"port"`
+	Label       string `json:"label"`
+	PID         int    `json:"pid"`
+	DiskUsage   string `json:"disk_usage"`
+	Connections string `json:"connections"`
+	Latency     string `json:"latency"`
+	Running     bool   `json:"running"`
 }
 
 // BackupStatus represents backup freshness from vitals output.
 type BackupStatus struct {
-	LocalLabel string
-	JSONLLabel string
-	LocalOK    bool
-	JSONLOK    bool
+	LocalLabel string `json:"local_label"`
+	JSONLLabel string `json:"jsonl_label"`
+	LocalOK    bool   `json:"local_ok"`
+	JSONLOK    bool   `json:"jsonl_ok"`
 }
 
 // FetchVitals runs `gt vitals` and parses the text output.
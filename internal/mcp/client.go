@@ -0,0 +1,376 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// Transport is the read/write side of a JSON-RPC connection. The default
+// transport spawns the server as a subprocess (see SubprocessTransport);
+// tests use an in-memory pipe pair.
+type Transport interface {
+	// Reader returns a stream of newline-delimited JSON objects from the server.
+	Reader() io.Reader
+	// Writer accepts newline-delimited JSON objects to send to the server.
+	Writer() io.Writer
+	// Close shuts the transport down. After Close, Reader will return io.EOF
+	// after draining any in-flight bytes, and Writer will return an error.
+	Close() error
+}
+
+// Client speaks MCP over the supplied Transport. One Client manages one
+// subprocess (or pipe pair). The zero value is not usable — construct with
+// Dial.
+type Client struct {
+	t     Transport
+	enc   *json.Encoder
+	dec   *json.Decoder
+	w     io.Writer
+	wMu   sync.Mutex
+	rdErr atomic.Value // error
+
+	nextID  atomic.Int64
+	pending sync.Map // map[int]chan response
+
+	notifyCh      chan Notification
+	notifyHandler func(Notification)
+	chansClosed   atomic.Bool
+
+	serverReqCh chan ServerRequest
+	serverInfo  Implementation
+
+	closeOnce sync.Once
+	closeErr  error
+	done      chan struct{}
+}
+
+// ClientOption customizes Dial behavior.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	clientVersion      string
+	notificationBuffer int
+	notificationFunc   func(Notification)
+}
+
+// WithClientVersion overrides the version reported to the server in initialize.
+// Defaults to "dev".
+func WithClientVersion(v string) ClientOption {
+	return func(o *clientOptions) { o.clientVersion = v }
+}
+
+// WithNotificationBuffer sets the buffered channel size for notification
+// delivery. Defaults to 64. A larger buffer reduces backpressure on the
+// reader goroutine when the consumer (e.g. BubbleTea) is slow to drain.
+func WithNotificationBuffer(n int) ClientOption {
+	return func(o *clientOptions) {
+		if n > 0 {
+			o.notificationBuffer = n
+		}
+	}
+}
+
+// WithNotificationHandler hands every notification to fn on the read
+// goroutine instead of the Notifications channel, which then stays empty.
+// Notifications reach fn in wire order relative to responses, so a
+// notification sent before a tool result is handled before the call
+// completes. fn must not call back into the Client synchronously.
+func WithNotificationHandler(fn func(Notification)) ClientOption {
+	return func(o *clientOptions) { o.notificationFunc = fn }
+}
+
+// Dial constructs a Client around the given Transport and performs the MCP
+// initialize handshake.
+//
+// On any handshake failure the transport is closed before returning.
+func Dial(ctx context.Context, t Transport, opts ...ClientOption) (*Client, error) {
+	o := clientOptions{
+		clientVersion:      clientVersionFallback,
+		notificationBuffer: 64,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	c := &Client{
+		t:             t,
+		dec:           json.NewDecoder(bufio.NewReader(t.Reader())),
+		w:             t.Writer(),
+		notifyCh:      make(chan Notification, o.notificationBuffer),
+		notifyHandler: o.notificationFunc,
+		serverReqCh:   make(chan ServerRequest, 16),
+		done:          make(chan struct{}),
+	}
+	c.enc = json.NewEncoder(c.w)
+
+	go c.readLoop()
+
+	if err := c.initialize(ctx, o.clientVersion); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	if err := c.Notify(MethodInitialized, nil); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("notify initialized: %w", err)
+	}
+	return c, nil
+}
+
+// ServerInfo returns the name and version the server reported in initialize.
+func (c *Client) ServerInfo() Implementation {
+	return c.serverInfo
+}
+
+// Notifications returns a receive channel of server notifications, unless a
+// WithNotificationHandler is set. The channel is closed when the client
+// shuts down. Consumers must drain promptly or risk the reader goroutine
+// blocking; the buffer is sized via WithNotificationBuffer.
+func (c *Client) Notifications() <-chan Notification {
+	return c.notifyCh
+}
+
+// ServerRequests returns a receive channel of server-initiated JSON-RPC
+// requests (e.g. `elicitation/create` prompts). The channel is closed when
+// the client shuts down. Each request must be answered with
+// Respond/RespondError using its RawID, or the server loop that issued it
+// will stall.
+func (c *Client) ServerRequests() <-chan ServerRequest {
+	return c.serverReqCh
+}
+
+// Done returns a channel that is closed when the underlying transport hits
+// EOF or an unrecoverable read error. Reading from it is non-blocking only
+// after shutdown.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// ReadError returns the error that terminated the read loop, if any.
+func (c *Client) ReadError() error {
+	if v := c.rdErr.Load(); v != nil {
+		if e, ok := v.(error); ok {
+			return e
+		}
+	}
+	return nil
+}
+
+// Close shuts the client and transport down. Safe to call multiple times.
+// In-flight Call goroutines unblock via the c.done channel, which is closed
+// by readLoop when the transport reports EOF (which Close triggers by
+// closing stdin). We deliberately do NOT close pending response channels —
+// doing so would race with readLoop dispatching a response that was just
+// LoadAndDelete'd from the pending map, causing a send-on-closed-channel
+// panic.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		c.closeErr = c.t.Close()
+	})
+	return c.closeErr
+}
+
+// Call issues a JSON-RPC request and waits for the matching response. It
+// blocks until ctx is canceled, the server responds, or the transport closes.
+func (c *Client) Call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	id, ch, err := c.send(method, params)
+	if err != nil {
+		return nil, err
+	}
+	defer c.pending.Delete(id)
+	return c.await(ctx, ch)
+}
+
+// send writes a request and registers its pending response channel. The
+// caller must delete the pending entry once it stops waiting.
+func (c *Client) send(method string, params any) (int, chan response, error) {
+	id := int(c.nextID.Add(1))
+	ch := make(chan response, 1)
+	c.pending.Store(id, ch)
+
+	raw, err := marshalParams(params)
+	if err != nil {
+		c.pending.Delete(id)
+		return 0, nil, fmt.Errorf("marshal params: %w", err)
+	}
+	req := request{
+		JSONRPC: jsonRPCVersion,
+		ID:      id,
+		Method:  method,
+		Params:  raw,
+	}
+	if err := c.writeJSON(req); err != nil {
+		c.pending.Delete(id)
+		return 0, nil, fmt.Errorf("write request: %w", err)
+	}
+	return id, ch, nil
+}
+
+// await waits for the response on ch.
+func (c *Client) await(ctx context.Context, ch chan response) (json.RawMessage, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.done:
+		if err := c.ReadError(); err != nil {
+			return nil, fmt.Errorf("transport closed: %w", err)
+		}
+		return nil, errors.New("transport closed")
+	case resp, ok := <-ch:
+		if !ok {
+			return nil, errors.New("client closed")
+		}
+		if resp.Error != nil {
+			return nil, &RPCError{Code: resp.Error.Code, Message: resp.Error.Message}
+		}
+		return resp.Result, nil
+	}
+}
+
+// RPCError is a JSON-RPC error response from the server.
+type RPCError struct {
+	Code    int
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// Notify sends a JSON-RPC notification (no id, no response expected).
+func (c *Client) Notify(method string, params any) error {
+	raw, err := marshalParams(params)
+	if err != nil {
+		return err
+	}
+	n := notification{
+		JSONRPC: jsonRPCVersion,
+		Method:  method,
+		Params:  raw,
+	}
+	return c.writeJSON(n)
+}
+
+// Respond answers a server-initiated request (ServerRequest) with a result. The
+// rawID must be the ServerRequest.RawID, echoed back verbatim so the server can
+// match the reply to its outstanding request.
+func (c *Client) Respond(rawID json.RawMessage, result any) error {
+	raw, err := marshalParams(result)
+	if err != nil {
+		return fmt.Errorf("marshal result: %w", err)
+	}
+	return c.writeJSON(response{
+		JSONRPC: jsonRPCVersion,
+		ID:      rawID,
+		Result:  raw,
+	})
+}
+
+// RespondError answers a server-initiated request with a JSON-RPC error object.
+func (c *Client) RespondError(rawID json.RawMessage, code int, message string) error {
+	return c.writeJSON(response{
+		JSONRPC: jsonRPCVersion,
+		ID:      rawID,
+		Error:   &rpcError{Code: code, Message: message},
+	})
+}
+
+func (c *Client) initialize(ctx context.Context, version string) error {
+	params := initializeParams{
+		ProtocolVersion: protocolVersion,
+		// Advertise the elicitation capability so the server is free to send
+		// `elicitation/create` requests (MCP spec); mg answers them with its
+		// approval dialog.
+		Capabilities: map[string]any{"elicitation": map[string]any{}},
+		ClientInfo: Implementation{
+			Name:    clientName,
+			Version: version,
+		},
+	}
+	raw, err := c.Call(ctx, MethodInitialize, params)
+	if err != nil {
+		return fmt.Errorf("initialize: %w", err)
+	}
+	var res initializeResult
+	if json.Unmarshal(raw, &res) == nil {
+		c.serverInfo = res.ServerInfo
+	}
+	return nil
+}
+
+func (c *Client) writeJSON(v any) error {
+	c.wMu.Lock()
+	defer c.wMu.Unlock()
+	return c.enc.Encode(v)
+}
+
+func marshalParams(params any) (json.RawMessage, error) {
+	if params == nil {
+		return nil, nil
+	}
+	if raw, ok := params.(json.RawMessage); ok {
+		return raw, nil
+	}
+	b, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// readLoop dispatches inbound JSON-RPC messages:
+//   - request (id + method, e.g. elicitation/create) → server-initiated request,
+//     forwarded on serverReqCh.
+//   - response (id, no method) → routed to the matching pending channel.
+//   - notification → the notification handler, or notifyCh.
+func (c *Client) readLoop() {
+	defer func() {
+		if !c.chansClosed.Swap(true) {
+			close(c.notifyCh)
+			close(c.serverReqCh)
+		}
+		close(c.done)
+	}()
+	for {
+		var msg response
+		if err := c.dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			c.rdErr.Store(err)
+			return
+		}
+		switch {
+		case msg.Method != "" && len(msg.ID) > 0:
+			// Server-initiated request. Best-effort, non-blocking send: approval
+			// prompts don't pile up, and we must never block the read loop (which
+			// also feeds in-flight Call responses).
+			select {
+			case c.serverReqCh <- ServerRequest{RawID: msg.ID, Method: msg.Method, Params: msg.Params}:
+			default:
+			}
+		case len(msg.ID) > 0:
+			id, ok := parseIntID(msg.ID)
+			if !ok {
+				continue
+			}
+			if v, ok := c.pending.LoadAndDelete(id); ok {
+				if ch, ok := v.(chan response); ok {
+					ch <- msg
+				}
+			}
+		case msg.Method != "":
+			n := Notification{Method: msg.Method, Params: msg.Params}
+			if c.notifyHandler != nil {
+				c.notifyHandler(n)
+			} else {
+				c.notifyCh <- n
+			}
+		}
+	}
+}
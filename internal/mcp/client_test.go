@@ -0,0 +1,262 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// inbound is the part of a message the fake server routes on.
+type inbound struct {
+	ID     int             `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// fakeServer is a scripted MCP server on the far side of a pipe pair.
+type fakeServer struct {
+	enc      *json.Encoder
+	wMu      sync.Mutex
+	Incoming chan inbound
+}
+
+// newFakeServer dials a Client against a fake server that has already
+// answered initialize.
+func newFakeServer(t *testing.T, opts ...ClientOption) (*Client, *fakeServer) {
+	t.Helper()
+	transport, sr, sw := newPipePair()
+	fs := &fakeServer{enc: json.NewEncoder(sw), Incoming: make(chan inbound, 16)}
+	go func() {
+		defer close(fs.Incoming)
+		dec := json.NewDecoder(bufio.NewReader(sr))
+		for {
+			var msg inbound
+			if err := dec.Decode(&msg); err != nil {
+				return
+			}
+			if msg.Method == MethodInitialize {
+				fs.Send(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": map[string]any{
+					"protocolVersion": "2025-03-26",
+					"serverInfo":      map[string]any{"name": "fake", "version": "0.1"},
+				}})
+				continue
+			}
+			fs.Incoming <- msg
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	c, err := Dial(ctx, transport, opts...)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	fs.Expect(t, MethodInitialized)
+	t.Cleanup(func() {
+		_ = c.Close()
+		_ = sr.Close()
+		_ = sw.Close()
+	})
+	return c, fs
+}
+
+func (f *fakeServer) Send(v any) {
+	f.wMu.Lock()
+	defer f.wMu.Unlock()
+	_ = f.enc.Encode(v)
+}
+
+func (f *fakeServer) Respond(id int, result string) {
+	f.Send(map[string]any{"jsonrpc": "2.0", "id": id, "result": json.RawMessage(result)})
+}
+
+func (f *fakeServer) Notify(method string, params string) {
+	f.Send(map[string]any{"jsonrpc": "2.0", "method": method, "params": json.RawMessage(params)})
+}
+
+func (f *fakeServer) Expect(t *testing.T, method string) inbound {
+	t.Helper()
+	select {
+	case msg := <-f.Incoming:
+		if msg.Method != method {
+			t.Fatalf("expected %q, got %q", method, msg.Method)
+		}
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for %s", method)
+		return inbound{}
+	}
+}
+
+func TestDialRecordsServerInfo(t *testing.T) {
+	c, _ := newFakeServer(t)
+	if got := c.ServerInfo(); got.Name != "fake" || got.Version != "0.1" {
+		t.Fatalf("ServerInfo = %+v", got)
+	}
+}
+
+func TestCallReturnsRPCError(t *testing.T) {
+	c, fs := newFakeServer(t)
+	go func() {
+		req := fs.Expect(t, "bogus")
+		fs.Send(map[string]any{"jsonrpc": "2.0", "id": req.ID, "error": map[string]any{"code": -32601, "message": "method not found"}})
+	}()
+	_, err := c.Call(context.Background(), "bogus", nil)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != -32601 {
+		t.Fatalf("err = %v, want RPCError -32601", err)
+	}
+}
+
+func TestNotificationsChannel(t *testing.T) {
+	c, fs := newFakeServer(t)
+	fs.Notify(MethodLogMessage, `{"level":"info","data":"hello"}`)
+	select {
+	case n := <-c.Notifications():
+		var p LogMessageParams
+		if n.Method != MethodLogMessage || json.Unmarshal(n.Params, &p) != nil || p.Level != "info" {
+			t.Fatalf("notification = %s %s", n.Method, n.Params)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("notification not delivered")
+	}
+}
+
+// TestNotificationHandlerRunsBeforeResponse guards the ordering codexmcp
+// relies on: a notification sent before a response is handled before the
+// call returns.
+func TestNotificationHandlerRunsBeforeResponse(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	c, fs := newFakeServer(t, WithNotificationHandler(func(n Notification) {
+		mu.Lock()
+		seen = append(seen, n.Method)
+		mu.Unlock()
+	}))
+	go func() {
+		req := fs.Expect(t, "work")
+		fs.Notify("custom/event", `{}`)
+		fs.Respond(req.ID, `{}`)
+	}()
+	if _, err := c.Call(context.Background(), "work", nil); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != 1 || seen[0] != "custom/event" {
+		t.Fatalf("handler saw %v before the response", seen)
+	}
+}
+
+func TestServerRequestStringID(t *testing.T) {
+	c, fs := newFakeServer(t)
+	fs.Send(map[string]any{"jsonrpc": "2.0", "id": "q-1", "method": MethodElicit, "params": map[string]any{"message": "Proceed?"}})
+	var sr ServerRequest
+	select {
+	case sr = <-c.ServerRequests():
+	case <-time.After(2 * time.Second):
+		t.Fatal("server request not routed")
+	}
+	if string(sr.RawID) != `"q-1"` || sr.Method != MethodElicit {
+		t.Fatalf("server request = %s %s", sr.RawID, sr.Method)
+	}
+	if err := c.Respond(sr.RawID, ElicitResult(ElicitDecline, nil)); err != nil {
+		t.Fatalf("Respond: %v", err)
+	}
+}
+
+func TestListToolsFollowsCursor(t *testing.T) {
+	c, fs := newFakeServer(t)
+	go func() {
+		req := fs.Expect(t, MethodToolsList)
+		fs.Respond(req.ID, `{"tools":[{"name":"a"}],"nextCursor":"p2"}`)
+		req = fs.Expect(t, MethodToolsList)
+		if !strings.Contains(string(req.Params), `"p2"`) {
+			t.Errorf("second page params = %s", req.Params)
+		}
+		fs.Respond(req.ID, `{"tools":[{"name":"b","description":"B"}]}`)
+	}()
+	tools, err := c.ListTools(context.Background())
+	if err != nil {
+		t.Fatalf("ListTools: %v", err)
+	}
+	if len(tools) != 2 || tools[0].Name != "a" || tools[1].Description != "B" {
+		t.Fatalf("tools = %+v", tools)
+	}
+}
+
+func TestCallToolResultAndProgressToken(t *testing.T) {
+	c, fs := newFakeServer(t)
+	call, err := c.CallTool(context.Background(), "run", map[string]any{"prompt": "hi"}, WithProgress())
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	req := fs.Expect(t, MethodToolsCall)
+	if req.ID != call.ID() {
+		t.Fatalf("request id %d, call id %d", req.ID, call.ID())
+	}
+	var p CallToolParams
+	if err := json.Unmarshal(req.Params, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "run" || p.Arguments["prompt"] != "hi" || p.Meta == nil || p.Meta.ProgressToken != call.ProgressToken() {
+		t.Fatalf("params = %+v", p)
+	}
+	tok, _ := json.Marshal(call.ProgressToken())
+	if !call.IsProgress(ProgressParams{ProgressToken: tok}) || call.IsProgress(ProgressParams{ProgressToken: json.RawMessage(`"other"`)}) {
+		t.Fatal("IsProgress doesn't match the call's token")
+	}
+
+	fs.Respond(req.ID, `{"content":[{"type":"text","text":"done"}],"isError":true}`)
+	select {
+	case res := <-call.Done():
+		if res.Err != nil || !res.Result.IsError || res.Result.Text() != "done" {
+			t.Fatalf("result = %+v", res)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("call never finished")
+	}
+}
+
+func TestToolCallCancelNotifiesServer(t *testing.T) {
+	c, fs := newFakeServer(t)
+	call, err := c.CallTool(context.Background(), "slow", nil)
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	fs.Expect(t, MethodToolsCall)
+	call.Cancel("user stop")
+	call.Cancel("again")
+
+	n := fs.Expect(t, MethodCancelled)
+	var p cancelledParams
+	if err := json.Unmarshal(n.Params, &p); err != nil || p.RequestID != call.ID() || p.Reason != "user stop" {
+		t.Fatalf("cancelled params = %s", n.Params)
+	}
+	if res := <-call.Done(); !errors.Is(res.Err, ErrCallCancelled) {
+		t.Fatalf("Err = %v, want ErrCallCancelled", res.Err)
+	}
+}
+
+func TestToolCallEndsWhenTransportCloses(t *testing.T) {
+	c, fs := newFakeServer(t)
+	call, err := c.CallTool(context.Background(), "slow", nil)
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	fs.Expect(t, MethodToolsCall)
+	_ = c.Close()
+	select {
+	case res := <-call.Done():
+		if res.Err == nil {
+			t.Fatal("expected an error after Close")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("call not unblocked by Close")
+	}
+}
@@ -0,0 +1,262 @@
+// Package mcp is a small Model Context Protocol client over stdio. It
+// speaks newline-delimited JSON-RPC 2.0, performs the initialize handshake,
+// lists and calls tools, cancels in-flight calls, and surfaces the server's
+// notifications and requests (elicitation) to the caller.
+//
+// It models only the client side mg needs to drive agents that expose an
+// MCP server; codexmcp builds the codex tools and event stream on top of it.
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// JSON-RPC envelope types.
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type notification struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response models any inbound JSON-RPC object: a response to one of our
+// requests, a notification, or a server-initiated request (elicitation/create).
+// ID is kept as RawMessage so a string id can't fail the whole-line decode —
+// our own request ids are always ints (see parseIntID), while server-request
+// ids are echoed back verbatim.
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// parseIntID extracts an integer id from a raw JSON-RPC id. Returns ok=false for
+// absent or non-integer (e.g. string) ids. Used to route responses to the
+// pending map, which is keyed by the int ids we allocate for our own requests.
+func parseIntID(raw json.RawMessage) (int, bool) {
+	if len(raw) == 0 {
+		return 0, false
+	}
+	var id int
+	if err := json.Unmarshal(raw, &id); err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+type rpcError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// MCP method names. Exported so servers faked in tests can match on them.
+const (
+	MethodInitialize  = "initialize"
+	MethodInitialized = "notifications/initialized"
+	MethodToolsList   = "tools/list"
+	MethodToolsCall   = "tools/call"
+	MethodCancelled   = "notifications/cancelled"
+	MethodProgress    = "notifications/progress"
+	MethodLogMessage  = "notifications/message"
+	MethodElicit      = "elicitation/create"
+)
+
+const (
+	protocolVersion       = "2025-03-26"
+	clientName            = "mardi-gras"
+	clientVersionFallback = "dev"
+	jsonRPCVersion        = "2.0"
+)
+
+// initializeParams is sent in the MCP `initialize` request.
+type initializeParams struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	Capabilities    map[string]any `json:"capabilities"`
+	ClientInfo      Implementation `json:"clientInfo"`
+}
+
+// Implementation names a client or server in the initialize handshake.
+type Implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// initializeResult is the part of the server's initialize reply mg keeps.
+type initializeResult struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	ServerInfo      Implementation `json:"serverInfo"`
+}
+
+// CallToolParams is the params object for `tools/call`. Meta carries the
+// progress token when the caller asked for progress notifications.
+type CallToolParams struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments"`
+	Meta      *RequestMeta   `json:"_meta,omitempty"`
+}
+
+// RequestMeta is the `_meta` object of a request.
+type RequestMeta struct {
+	ProgressToken any `json:"progressToken,omitempty"`
+}
+
+// Tool is one entry of a `tools/list` result.
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"inputSchema,omitempty"`
+}
+
+type listToolsResult struct {
+	Tools      []Tool `json:"tools"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ToolResult is the result of a `tools/call`. A tool that ran but failed
+// reports IsError with the failure in Content, rather than a JSON-RPC error.
+type ToolResult struct {
+	Content           []Content       `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
+}
+
+// Content is one block of a tool result. mg renders text blocks and names
+// the others by type.
+type Content struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+// Text joins the result's text blocks.
+func (r ToolResult) Text() string {
+	var parts []string
+	for _, c := range r.Content {
+		if c.Type == "text" && c.Text != "" {
+			parts = append(parts, c.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Notification is an inbound JSON-RPC notification.
+type Notification struct {
+	Method string
+	Params json.RawMessage
+}
+
+// ProgressParams is the payload of `notifications/progress`. The token is
+// the one the request carried in `_meta.progressToken`.
+type ProgressParams struct {
+	ProgressToken json.RawMessage `json:"progressToken"`
+	Progress      float64         `json:"progress"`
+	Total         float64         `json:"total,omitempty"`
+	Message       string          `json:"message,omitempty"`
+}
+
+// LogMessageParams is the payload of `notifications/message`. Data is any
+// JSON value; servers usually send a string.
+type LogMessageParams struct {
+	Level  string          `json:"level"`
+	Logger string          `json:"logger,omitempty"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// cancelledParams is the payload of `notifications/cancelled`.
+type cancelledParams struct {
+	RequestID int    `json:"requestId"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// ServerRequest is an inbound JSON-RPC request initiated by the server (as
+// opposed to a response to one of our requests, or a notification). Servers
+// use these for elicitation, e.g. approval prompts. RawID is the
+// server-allocated id; it must be echoed back verbatim on the reply (see
+// Client.Respond) — the type (number vs string) is server-defined.
+type ServerRequest struct {
+	RawID  json.RawMessage
+	Method string
+	Params json.RawMessage
+}
+
+// Elicitation actions a client answers `elicitation/create` with.
+const (
+	ElicitAccept  = "accept"
+	ElicitDecline = "decline"
+	ElicitCancel  = "cancel"
+)
+
+// ElicitRequest is a decoded `elicitation/create` request: a message for
+// the user and the JSON schema of the answer the server wants back.
+type ElicitRequest struct {
+	Message         string          `json:"message"`
+	RequestedSchema json.RawMessage `json:"requestedSchema,omitempty"`
+}
+
+// ParseElicitRequest decodes `elicitation/create` params. ok is false when
+// they aren't an object or carry no message.
+func ParseElicitRequest(params json.RawMessage) (ElicitRequest, bool) {
+	var r ElicitRequest
+	if err := json.Unmarshal(params, &r); err != nil || r.Message == "" {
+		return ElicitRequest{}, false
+	}
+	return r, true
+}
+
+// ElicitConfirmContent returns the content that answers yes to an
+// elicitation whose message is its only question: nil for an empty schema,
+// otherwise its single boolean property set to true. ok is false when the
+// schema asks for anything else, including several booleans, since a
+// confirmation of the message can't say which of them it means.
+func ElicitConfirmContent(schema json.RawMessage) (map[string]any, bool) {
+	if len(schema) == 0 || string(schema) == "null" {
+		return nil, true
+	}
+	var s struct {
+		Properties map[string]struct {
+			Type string `json:"type"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return nil, false
+	}
+	for _, name := range s.Required {
+		if _, ok := s.Properties[name]; !ok {
+			return nil, false
+		}
+	}
+	if len(s.Properties) == 0 {
+		return nil, true
+	}
+	if len(s.Properties) > 1 {
+		return nil, false
+	}
+	for name, p := range s.Properties {
+		if p.Type == "boolean" {
+			return map[string]any{name: true}, true
+		}
+	}
+	return nil, false
+}
+
+// ElicitResult builds the reply to an elicitation. content is only sent
+// with ElicitAccept, and only when non-nil.
+func ElicitResult(action string, content map[string]any) map[string]any {
+	res := map[string]any{"action": action}
+	if action == ElicitAccept && content != nil {
+		res["content"] = content
+	}
+	return res
+}
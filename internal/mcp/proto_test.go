@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseIntID(t *testing.T) {
+	if id, ok := parseIntID(json.RawMessage(`42`)); !ok || id != 42 {
+		t.Fatalf("parseIntID(42) = %d,%v", id, ok)
+	}
+	if _, ok := parseIntID(json.RawMessage(`"abc"`)); ok {
+		t.Fatal("parseIntID(string) returned ok=true, want false")
+	}
+	if _, ok := parseIntID(nil); ok {
+		t.Fatal("parseIntID(nil) returned ok=true, want false")
+	}
+}
+
+func TestParseElicitRequest(t *testing.T) {
+	r, ok := ParseElicitRequest(json.RawMessage(`{"message":"Deploy to staging?","requestedSchema":{"type":"object"}}`))
+	if !ok || r.Message != "Deploy to staging?" || len(r.RequestedSchema) == 0 {
+		t.Fatalf("ParseElicitRequest = %+v, %v", r, ok)
+	}
+	if _, ok := ParseElicitRequest(json.RawMessage(`{}`)); ok {
+		t.Fatal("ok = true without a message, want false")
+	}
+	if _, ok := ParseElicitRequest(nil); ok {
+		t.Fatal("ok = true for nil params, want false")
+	}
+}
+
+func TestElicitResult(t *testing.T) {
+	got := ElicitResult(ElicitAccept, map[string]any{"ok": true})
+	if got["action"] != ElicitAccept || got["content"] == nil {
+		t.Fatalf("accept = %v", got)
+	}
+	if got := ElicitResult(ElicitDecline, map[string]any{"ok": true}); len(got) != 1 || got["action"] != ElicitDecline {
+		t.Fatalf("decline = %v, want action only", got)
+	}
+}
+
+func TestToolResultText(t *testing.T) {
+	var r ToolResult
+	if err := json.Unmarshal([]byte(`{"content":[{"type":"text","text":"a"},{"type":"image"},{"type":"text","text":"b"}]}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Text(); got != "a\nb" {
+		t.Fatalf("Text = %q", got)
+	}
+}
+
+func TestElicitConfirmContent(t *testing.T) {
+	for schema, want := range map[string]bool{
+		``:                  true,
+		`{"type":"object"}`: true,
+		`{"type":"object","properties":{"ok":{"type":"boolean"}},"required":["ok"]}`:            true,
+		`{"type":"object","properties":{"n":{"type":"number"}}}`:                                false,
+		`{"type":"object","properties":{"push":{"type":"boolean"},"force":{"type":"boolean"}}}`: false,
+		`{"type":"object","required":["ok"]}`:                                                   false,
+		`[`:                                                                                     false,
+	} {
+		if _, ok := ElicitConfirmContent(json.RawMessage(schema)); ok != want {
+			t.Errorf("ElicitConfirmContent(%s) ok = %v, want %v", schema, ok, want)
+		}
+	}
+	content, _ := ElicitConfirmContent(json.RawMessage(`{"properties":{"ok":{"type":"boolean"}}}`))
+	if content["ok"] != true {
+		t.Errorf("content = %v, want ok=true", content)
+	}
+}
@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// ListTools returns every tool the server offers, following pagination.
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var tools []Tool
+	cursor := ""
+	for {
+		var params any
+		if cursor != "" {
+			params = map[string]string{"cursor": cursor}
+		}
+		raw, err := c.Call(ctx, MethodToolsList, params)
+		if err != nil {
+			return nil, fmt.Errorf("tools/list: %w", err)
+		}
+		var page listToolsResult
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return nil, fmt.Errorf("tools/list: %w", err)
+		}
+		tools = append(tools, page.Tools...)
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return tools, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// CallOption customizes a CallTool request.
+type CallOption func(*CallToolParams)
+
+// WithProgress asks the server for `notifications/progress` about the call,
+// tagged with the call's ProgressToken.
+func WithProgress() CallOption {
+	return func(p *CallToolParams) { p.Meta = &RequestMeta{} }
+}
+
+// ToolCall is one in-flight `tools/call`. Its result lands on Done exactly
+// once: the tool's result, a JSON-RPC error, the transport closing, or the
+// call being canceled.
+type ToolCall struct {
+	client *Client
+	id     int
+	token  string
+	done   chan ToolCallResult
+	stop   chan struct{}
+
+	stopOnce   sync.Once
+	stopReason string
+}
+
+// ToolCallResult is the outcome of a ToolCall. Raw is the undecoded result
+// object, for callers that read server-specific fields.
+type ToolCallResult struct {
+	Result ToolResult
+	Raw    json.RawMessage
+	Err    error
+}
+
+// ErrCallCancelled is the ToolCallResult error after ToolCall.Cancel.
+var ErrCallCancelled = errors.New("mcp: tool call cancelled")
+
+// CallTool sends `tools/call` for the named tool and returns without
+// waiting for the result. ctx bounds the whole call: when it ends first the
+// call is cancelled on the server as with Cancel.
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]any, opts ...CallOption) (*ToolCall, error) {
+	params := CallToolParams{Name: name, Arguments: args}
+	for _, opt := range opts {
+		opt(&params)
+	}
+	tc := &ToolCall{
+		client: c,
+		done:   make(chan ToolCallResult, 1),
+		stop:   make(chan struct{}),
+	}
+	if params.Meta != nil {
+		tc.token = "mg-" + strconv.FormatInt(c.nextID.Add(1), 10)
+		params.Meta.ProgressToken = tc.token
+	}
+	id, ch, err := c.send(MethodToolsCall, params)
+	if err != nil {
+		return nil, fmt.Errorf("tools/call %s: %w", name, err)
+	}
+	tc.id = id
+	go tc.await(ctx, ch)
+	return tc, nil
+}
+
+// ID returns the JSON-RPC id of the tools/call request. Servers that stream
+// their own notifications (codex/event) tag them with it.
+func (tc *ToolCall) ID() int { return tc.id }
+
+// ProgressToken returns the token progress notifications for this call
+// carry, or "" when the call was made without WithProgress.
+func (tc *ToolCall) ProgressToken() string { return tc.token }
+
+// IsProgress reports whether a `notifications/progress` belongs to this call.
+func (tc *ToolCall) IsProgress(p ProgressParams) bool {
+	if tc.token == "" {
+		return false
+	}
+	var tok string
+	return json.Unmarshal(p.ProgressToken, &tok) == nil && tok == tc.token
+}
+
+// Done returns a channel that yields the call's result exactly once.
+func (tc *ToolCall) Done() <-chan ToolCallResult { return tc.done }
+
+// Cancel tells the server to stop working on the call with
+// `notifications/cancelled` and resolves Done with ErrCallCancelled. The
+// server may still send notifications for work already under way. Safe to
+// call more than once, and after the call finished.
+func (tc *ToolCall) Cancel(reason string) {
+	tc.stopOnce.Do(func() {
+		tc.stopReason = reason
+		close(tc.stop)
+	})
+}
+
+func (tc *ToolCall) await(ctx context.Context, ch chan response) {
+	c := tc.client
+	defer c.pending.Delete(tc.id)
+	var res ToolCallResult
+	select {
+	case <-ctx.Done():
+		tc.cancelOnServer("context done")
+		res.Err = ctx.Err()
+	case <-tc.stop:
+		tc.cancelOnServer(tc.stopReason)
+		res.Err = ErrCallCancelled
+	case <-c.done:
+		// Transport closed (subprocess exited) before any response — surface
+		// the underlying read error if there was one.
+		res.Err = c.ReadError()
+		if res.Err == nil {
+			res.Err = errors.New("mcp transport closed")
+		}
+	case resp := <-ch:
+		if resp.Error != nil {
+			res.Err = &RPCError{Code: resp.Error.Code, Message: resp.Error.Message}
+			break
+		}
+		res.Raw = resp.Result
+		if err := json.Unmarshal(resp.Result, &res.Result); err != nil && len(resp.Result) > 0 {
+			res.Err = fmt.Errorf("decode tool result: %w", err)
+		}
+	}
+	tc.done <- res
+}
+
+// cancelOnServer sends notifications/cancelled for the call. Best-effort:
+// the transport may already be gone.
+func (tc *ToolCall) cancelOnServer(reason string) {
+	_ = tc.client.Notify(MethodCancelled, cancelledParams{RequestID: tc.id, Reason: reason})
+}
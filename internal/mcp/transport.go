@@ -1,4 +1,4 @@
-package codexmcp
+package mcp
 
 import (
 	"errors"
@@ -9,7 +9,7 @@ import (
 	"time"
 )
 
-// SubprocessTransport runs `codex mcp-server` as a child process and proxies
+// SubprocessTransport runs an MCP server as a child process and proxies
 // stdio for the Client. Stderr is captured into Logs for inclusion in error
 // messages; it is intentionally not echoed to mg's terminal since the parent
 // is a fullscreen TUI.
@@ -31,20 +31,8 @@ type SubprocessTransport struct {
 type SubprocessOption func(*subprocessOptions)
 
 type subprocessOptions struct {
-	binary string
-	args   []string
-	dir    string
-	env    []string
-}
-
-// WithBinary overrides the codex binary path. Defaults to "codex".
-func WithBinary(path string) SubprocessOption {
-	return func(o *subprocessOptions) { o.binary = path }
-}
-
-// WithExtraArgs appends arguments to `codex mcp-server`.
-func WithExtraArgs(args ...string) SubprocessOption {
-	return func(o *subprocessOptions) { o.args = append(o.args, args...) }
+	dir string
+	env []string
 }
 
 // WithDir sets the working directory of the subprocess.
@@ -58,18 +46,15 @@ func WithEnv(env []string) SubprocessOption {
 	return func(o *subprocessOptions) { o.env = env }
 }
 
-// SpawnSubprocess launches `codex mcp-server` and returns a Transport wired
-// to its stdio.
-func SpawnSubprocess(opts ...SubprocessOption) (*SubprocessTransport, error) {
-	o := subprocessOptions{
-		binary: "codex",
-		args:   []string{"mcp-server"},
-	}
+// SpawnSubprocess launches the MCP server binary with args and returns a
+// Transport wired to its stdio.
+func SpawnSubprocess(binary string, args []string, opts ...SubprocessOption) (*SubprocessTransport, error) {
+	var o subprocessOptions
 	for _, opt := range opts {
 		opt(&o)
 	}
 
-	cmd := exec.Command(o.binary, o.args...) //nolint:gosec // binary is a configured codex path, not user input
+	cmd := exec.Command(binary, args...) //nolint:gosec // binary comes from the runtime registry, not user input
 	if o.dir != "" {
 		cmd.Dir = o.dir
 	}
@@ -117,7 +102,7 @@ func (s *SubprocessTransport) Reader() io.Reader { return s.stdout }
 // Writer implements Transport.
 func (s *SubprocessTransport) Writer() io.Writer { return s.stdin }
 
-// Close terminates the subprocess. It first closes stdin (giving codex a
+// Close terminates the subprocess. It first closes stdin (giving the server a
 // chance to exit cleanly), then waits up to 2 seconds, then SIGTERMs, then
 // SIGKILLs. Idempotent.
 func (s *SubprocessTransport) Close() error {
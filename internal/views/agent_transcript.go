@@ -0,0 +1,369 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+	"github.com/matt-wright86/mardi-gras/internal/ui"
+)
+
+// transcriptLine is one display row of a flattened session transcript.
+// Turn headers (role + timestamp) and body text are separate rows so search
+// and scrolling work on exactly what is on screen.
+type transcriptLine struct {
+	text   string
+	header bool
+	role   string
+}
+
+// AgentTranscript is a scrollable overlay over a roster agent's session
+// history, shown in place of the Gas Town panel. It supports incremental
+// search (/, n, N) and a follow mode that pins the view to the newest output
+// as the app refreshes the transcript.
+type AgentTranscript struct {
+	width  int
+	height int
+
+	agent      gastown.AgentRuntime
+	transcript *gastown.SessionTranscript
+	err        string
+	loading    bool
+
+	lines  []transcriptLine
+	offset int // index of the first visible line
+	follow bool
+
+	searching   bool
+	searchInput textinput.Model
+	query       string
+	matches     []int // line indexes containing query
+	matchIdx    int
+}
+
+// NewAgentTranscript constructs an empty transcript overlay.
+func NewAgentTranscript(width, height int) AgentTranscript {
+	return AgentTranscript{width: width, height: height, follow: true}
+}
+
+// SetSize updates dimensions and re-wraps the transcript.
+func (t *AgentTranscript) SetSize(width, height int) {
+	t.width = width
+	t.height = height
+	t.rebuild()
+}
+
+// Open resets the overlay for a new agent. The transcript itself arrives
+// later via SetTranscript.
+func (t *AgentTranscript) Open(agent gastown.AgentRuntime) {
+	*t = AgentTranscript{width: t.width, height: t.height, agent: agent, follow: true, loading: true}
+}
+
+// Agent returns the agent whose session is shown.
+func (t *AgentTranscript) Agent() gastown.AgentRuntime { return t.agent }
+
+// SetTranscript swaps in a freshly fetched transcript. In follow mode the view
+// jumps to the newest line; otherwise the scroll position is kept.
+func (t *AgentTranscript) SetTranscript(tr *gastown.SessionTranscript) {
+	t.loading = false
+	t.err = ""
+	t.transcript = tr
+	t.rebuild()
+}
+
+// SetError records a failed fetch. A previously loaded transcript stays on
+// screen so a transient error does not blank the overlay.
+func (t *AgentTranscript) SetError(err error) {
+	t.loading = false
+	if err != nil {
+		t.err = err.Error()
+	}
+}
+
+// Searching reports whether the search input has focus; the app must then
+// route every key here so printable keys reach the input.
+func (t *AgentTranscript) Searching() bool { return t.searching }
+
+// Following reports whether follow mode is on.
+func (t *AgentTranscript) Following() bool { return t.follow }
+
+// Update handles scrolling, search, and the submit-message key.
+func (t AgentTranscript) Update(msg tea.Msg) (AgentTranscript, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		if t.searching {
+			var cmd tea.Cmd
+			t.searchInput, cmd = t.searchInput.Update(msg)
+			return t, cmd
+		}
+		return t, nil
+	}
+
+	if t.searching {
+		switch keyMsg.String() {
+		case "esc":
+			t.searching = false
+			t.searchInput.Blur()
+			return t, nil
+		case "enter":
+			t.searching = false
+			t.searchInput.Blur()
+			t.query = strings.TrimSpace(t.searchInput.Value())
+			t.findMatches()
+			if len(t.matches) > 0 {
+				t.matchIdx = 0
+				t.jumpToMatch()
+			}
+			return t, nil
+		}
+		var cmd tea.Cmd
+		t.searchInput, cmd = t.searchInput.Update(msg)
+		return t, cmd
+	}
+
+	half := max(t.bodyHeight()/2, 1)
+	switch keyMsg.String() {
+	case "j", "down":
+		t.scrollTo(t.offset + 1)
+	case "k", "up":
+		t.scrollTo(t.offset - 1)
+		t.follow = false
+	case "ctrl+d", "pgdown":
+		t.scrollTo(t.offset + half)
+	case "ctrl+u", "pgup":
+		t.scrollTo(t.offset - half)
+		t.follow = false
+	case "g":
+		t.scrollTo(0)
+		t.follow = false
+	case "G":
+		t.scrollTo(t.maxOffset())
+		t.follow = true
+	case "f":
+		t.follow = !t.follow
+		if t.follow {
+			t.scrollTo(t.maxOffset())
+		}
+	case "/":
+		t.searching = true
+		t.searchInput = textinput.New()
+		t.searchInput.Prompt = ui.InputPrompt.Render("/")
+		t.searchInput.Placeholder = "search transcript..."
+		t.searchInput.SetValue(t.query)
+		t.searchInput.SetWidth(max(t.width-8, 10))
+		t.searchInput.Focus()
+		return t, textinput.Blink
+	case "n":
+		if len(t.matches) > 0 {
+			t.matchIdx = (t.matchIdx + 1) % len(t.matches)
+			t.jumpToMatch()
+		}
+	case "N":
+		if len(t.matches) > 0 {
+			t.matchIdx = (t.matchIdx - 1 + len(t.matches)) % len(t.matches)
+			t.jumpToMatch()
+		}
+	case "i":
+		agent := t.agent
+		return t, func() tea.Msg {
+			return GasTownActionMsg{Type: ActionNudge, Agent: agent}
+		}
+	}
+	return t, nil
+}
+
+// rebuild flattens the transcript into wrapped display lines and re-applies
+// the search and follow state.
+func (t *AgentTranscript) rebuild() {
+	t.lines = nil
+	if t.transcript != nil {
+		wrapW := max(t.width-4, 10)
+		for _, e := range t.transcript.Entries {
+			if e.Role != "" {
+				head := e.Role
+				if !e.Time.IsZero() {
+					head += "  " + e.Time.Local().Format("15:04:05")
+				}
+				t.lines = append(t.lines, transcriptLine{text: head, header: true, role: e.Role})
+			}
+			for ln := range strings.SplitSeq(e.Text, "\n") {
+				for wl := range strings.SplitSeq(ansi.Wrap(ln, wrapW, ""), "\n") {
+					t.lines = append(t.lines, transcriptLine{text: wl, role: e.Role})
+				}
+			}
+		}
+	}
+	t.findMatches()
+	if t.follow {
+		t.offset = t.maxOffset()
+	} else {
+		t.scrollTo(t.offset)
+	}
+}
+
+// findMatches recomputes the line indexes containing the current query.
+func (t *AgentTranscript) findMatches() {
+	t.matches = nil
+	if t.query == "" {
+		return
+	}
+	q := strings.ToLower(t.query)
+	for i, l := range t.lines {
+		if strings.Contains(strings.ToLower(l.text), q) {
+			t.matches = append(t.matches, i)
+		}
+	}
+	if t.matchIdx >= len(t.matches) {
+		t.matchIdx = 0
+	}
+}
+
+// jumpToMatch centers the current match and leaves follow mode, since the
+// user is now reading history rather than tailing.
+func (t *AgentTranscript) jumpToMatch() {
+	t.follow = false
+	t.scrollTo(t.matches[t.matchIdx] - t.bodyHeight()/2)
+}
+
+func (t *AgentTranscript) scrollTo(off int) {
+	t.offset = max(min(off, t.maxOffset()), 0)
+}
+
+func (t *AgentTranscript) maxOffset() int {
+	return max(len(t.lines)-t.bodyHeight(), 0)
+}
+
+// bodyHeight is the number of transcript rows that fit between the header
+// (title, meta, blank) and the footer (blank, status/search line, hints).
+func (t *AgentTranscript) bodyHeight() int {
+	return max(t.height-6, 1)
+}
+
+// View renders the overlay inside ui.DetailBorder.
+func (t AgentTranscript) View() string {
+	header := lipgloss.NewStyle().Bold(true).Foreground(ui.BrightGold).Render("SESSION TRANSCRIPT")
+	out := []string{header, t.metaLine(), ""}
+
+	dim := lipgloss.NewStyle().Foreground(ui.Dim)
+	body := t.bodyHeight()
+	switch {
+	case len(t.lines) == 0 && t.err != "":
+		out = append(out, lipgloss.NewStyle().Foreground(ui.StatusStalled).Render(ui.SymStalled+" "+t.err))
+		body--
+	case len(t.lines) == 0 && t.loading:
+		out = append(out, dim.Render("loading session history..."))
+		body--
+	case len(t.lines) == 0:
+		out = append(out, dim.Render("No session history yet."))
+		body--
+	default:
+		end := min(t.offset+body, len(t.lines))
+		current := -1
+		if len(t.matches) > 0 {
+			current = t.matches[t.matchIdx]
+		}
+		for i := t.offset; i < end; i++ {
+			out = append(out, t.renderLine(t.lines[i], i == current))
+		}
+		body -= end - t.offset
+	}
+	for ; body > 0; body-- {
+		out = append(out, "")
+	}
+
+	out = append(out, "", t.statusLine())
+	if t.searching {
+		out = append(out, t.searchInput.View())
+	} else {
+		out = append(out, dim.Render("  / search  n/N next/prev  f follow  i message  R refresh  esc back"))
+	}
+
+	return ui.DetailBorder.Width(t.width).Height(t.height).Render(strings.Join(out, "\n"))
+}
+
+func (t AgentTranscript) metaLine() string {
+	parts := []string{lipgloss.NewStyle().Foreground(ui.Light).Render(t.agent.Name)}
+	if t.transcript != nil {
+		if t.transcript.Provider != "" {
+			parts = append(parts, lipgloss.NewStyle().Foreground(ui.Muted).Render(t.transcript.Provider))
+		}
+		if t.transcript.Session != "" {
+			parts = append(parts, lipgloss.NewStyle().Foreground(ui.Dim).Render("session "+t.transcript.Session))
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+func (t AgentTranscript) statusLine() string {
+	dim := lipgloss.NewStyle().Foreground(ui.Dim)
+	var parts []string
+	if t.follow {
+		parts = append(parts, lipgloss.NewStyle().Foreground(ui.BrightGreen).Render(ui.SymWorking+" following"))
+	} else {
+		parts = append(parts, dim.Render(fmt.Sprintf("line %d/%d", min(t.offset+1, len(t.lines)), len(t.lines))))
+	}
+	if t.query != "" {
+		if len(t.matches) == 0 {
+			parts = append(parts, lipgloss.NewStyle().Foreground(ui.StatusStalled).Render(fmt.Sprintf("no match for %q", t.query)))
+		} else {
+			parts = append(parts, lipgloss.NewStyle().Foreground(ui.BrightGold).Render(
+				fmt.Sprintf("match %d/%d %q", t.matchIdx+1, len(t.matches), t.query)))
+		}
+	}
+	if t.err != "" && len(t.lines) > 0 {
+		parts = append(parts, lipgloss.NewStyle().Foreground(ui.StatusStalled).Render(ui.SymStalled+" refresh failed"))
+	}
+	return "  " + strings.Join(parts, "  ")
+}
+
+func (t AgentTranscript) renderLine(l transcriptLine, current bool) string {
+	if l.header {
+		fg := ui.Muted
+		icon := "◀"
+		if l.role == "assistant" {
+			fg = ui.BrightGold
+			icon = "▶"
+		}
+		return lipgloss.NewStyle().Foreground(fg).Bold(true).Render(icon + " " + l.text)
+	}
+	base := lipgloss.NewStyle().Foreground(ui.Light)
+	text := "  " + l.text
+	if l.role == "" {
+		text = l.text // raw terminal capture: no turn indentation
+	}
+	if t.query == "" {
+		return base.Render(text)
+	}
+	return highlightMatches(text, t.query, base, current)
+}
+
+// highlightMatches renders text with every case-insensitive occurrence of
+// query emphasized; the current match is shown inverted.
+func highlightMatches(text, query string, base lipgloss.Style, current bool) string {
+	lower := strings.ToLower(text)
+	q := strings.ToLower(query)
+	// Lowercasing can change byte lengths for some scripts; fall back to
+	// plain rendering rather than slicing mid-rune.
+	if len(lower) != len(text) || q == "" {
+		return base.Render(text)
+	}
+	hl := lipgloss.NewStyle().Foreground(ui.BrightGold).Bold(true).Underline(true)
+	if current {
+		hl = hl.Reverse(true)
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, q)
+		if i < 0 {
+			b.WriteString(base.Render(text))
+			return b.String()
+		}
+		b.WriteString(base.Render(text[:i]))
+		b.WriteString(hl.Render(text[i : i+len(q)]))
+		text, lower = text[i+len(q):], lower[i+len(q):]
+	}
+}
@@ -0,0 +1,142 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+)
+
+func transcriptKey(s string) tea.KeyPressMsg {
+	switch s {
+	case "enter":
+		return tea.KeyPressMsg{Code: tea.KeyEnter}
+	case "esc":
+		return tea.KeyPressMsg{Code: tea.KeyEscape}
+	}
+	r := []rune(s)[0]
+	return tea.KeyPressMsg{Code: r, Text: s}
+}
+
+func rawTranscript(n int) *gastown.SessionTranscript {
+	tr := &gastown.SessionTranscript{Session: "gt-beads-toast"}
+	for i := range n {
+		tr.Entries = append(tr.Entries, gastown.TranscriptEntry{Text: fmt.Sprintf("line %02d", i)})
+	}
+	return tr
+}
+
+func TestAgentTranscriptLoadingAndError(t *testing.T) {
+	v := NewAgentTranscript(80, 20)
+	v.Open(gastown.AgentRuntime{Name: "toast"})
+	if !strings.Contains(v.View(), "loading session history") {
+		t.Fatal("expected loading placeholder before first fetch")
+	}
+	v.SetError(fmt.Errorf("gc: no session for agent %q", "toast"))
+	if !strings.Contains(v.View(), "no session for agent") {
+		t.Fatal("expected fetch error in view")
+	}
+}
+
+func TestAgentTranscriptFollowPinsToBottom(t *testing.T) {
+	v := NewAgentTranscript(80, 20)
+	v.Open(gastown.AgentRuntime{Name: "toast"})
+	v.SetTranscript(rawTranscript(50))
+	if v.offset != v.maxOffset() {
+		t.Fatalf("offset = %d, want bottom %d in follow mode", v.offset, v.maxOffset())
+	}
+	if !strings.Contains(v.View(), "line 49") {
+		t.Fatal("newest line should be visible while following")
+	}
+
+	// Scrolling up leaves follow mode; a refresh keeps the position.
+	v, _ = v.Update(transcriptKey("k"))
+	if v.Following() {
+		t.Fatal("k should leave follow mode")
+	}
+	pos := v.offset
+	v.SetTranscript(rawTranscript(60))
+	if v.offset != pos {
+		t.Fatalf("offset moved to %d after refresh, want %d", v.offset, pos)
+	}
+
+	// G re-enters follow mode.
+	v, _ = v.Update(transcriptKey("G"))
+	if !v.Following() || v.offset != v.maxOffset() {
+		t.Fatal("G should jump to bottom and resume following")
+	}
+}
+
+func TestAgentTranscriptSearch(t *testing.T) {
+	v := NewAgentTranscript(80, 20)
+	v.Open(gastown.AgentRuntime{Name: "toast"})
+	v.SetTranscript(rawTranscript(50))
+
+	v, _ = v.Update(transcriptKey("/"))
+	if !v.Searching() {
+		t.Fatal("/ should open the search input")
+	}
+	for _, r := range "line 0" {
+		v, _ = v.Update(transcriptKey(string(r)))
+	}
+	v, _ = v.Update(transcriptKey("enter"))
+	if v.Searching() {
+		t.Fatal("enter should close the search input")
+	}
+	if len(v.matches) != 10 {
+		t.Fatalf("matches = %d, want 10 (line 00..09)", len(v.matches))
+	}
+	if v.Following() {
+		t.Fatal("jumping to a match should leave follow mode")
+	}
+	if !strings.Contains(v.View(), "match 1/10") {
+		t.Fatal("status line should show match position")
+	}
+
+	v, _ = v.Update(transcriptKey("N"))
+	if v.matchIdx != 9 {
+		t.Fatalf("N from first match = %d, want wrap to 9", v.matchIdx)
+	}
+	v, _ = v.Update(transcriptKey("n"))
+	if v.matchIdx != 0 {
+		t.Fatalf("n from last match = %d, want wrap to 0", v.matchIdx)
+	}
+}
+
+func TestAgentTranscriptConversationTurns(t *testing.T) {
+	v := NewAgentTranscript(80, 20)
+	v.Open(gastown.AgentRuntime{Name: "obsidian"})
+	v.SetTranscript(&gastown.SessionTranscript{
+		Session:  "va-9",
+		Provider: "claude",
+		Entries: []gastown.TranscriptEntry{
+			{Role: "user", Text: "fix the float", Time: time.Date(2026, 6, 12, 10, 1, 0, 0, time.UTC)},
+			{Role: "assistant", Text: "On it.\nReading float.go"},
+		},
+	})
+	if len(v.lines) != 5 {
+		t.Fatalf("lines = %d, want 2 headers + 3 body rows", len(v.lines))
+	}
+	view := v.View()
+	for _, want := range []string{"claude", "session va-9", "assistant", "Reading float.go"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+}
+
+func TestAgentTranscriptSubmitMessage(t *testing.T) {
+	v := NewAgentTranscript(80, 20)
+	v.Open(gastown.AgentRuntime{Name: "toast", Address: "beads/toast"})
+	_, cmd := v.Update(transcriptKey("i"))
+	if cmd == nil {
+		t.Fatal("i should emit a message action")
+	}
+	action, ok := cmd().(GasTownActionMsg)
+	if !ok || action.Type != ActionNudge || action.Agent.Address != "beads/toast" {
+		t.Fatalf("got %+v, want nudge action for beads/toast", action)
+	}
+}
@@ -0,0 +1,772 @@
+package views
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/matt-wright86/mardi-gras/internal/data"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+	"github.com/matt-wright86/mardi-gras/internal/ui"
+)
+
+// analyticsMode is how the flow analytics overlay draws the selected metric.
+type analyticsMode int
+
+const (
+	analyticsTable     analyticsMode = iota // percentile table per group
+	analyticsHistogram                      // duration histogram for the cursor group
+	analyticsScatter                        // close date vs duration, cursor group highlighted
+	analyticsCFD                            // cumulative flow across parade sections
+	analyticsCosts                          // recorded spend trends and breakdown
+	analyticsModes                          // count
+)
+
+func (m analyticsMode) String() string {
+	switch m {
+	case analyticsHistogram:
+		return "histogram"
+	case analyticsScatter:
+		return "scatter"
+	case analyticsCFD:
+		return "cumulative flow"
+	case analyticsCosts:
+		return "costs"
+	}
+	return "table"
+}
+
+// maxAgingRows caps the aging WIP list under the chart.
+const maxAgingRows = 5
+
+// cfdWindows are the day ranges of the cumulative flow and cost views, cycled
+// with +/-.
+var cfdWindows = []int{14, 30, 60, gastown.MaxCFDDays}
+
+// histogramBucket is one duration band of the flow histogram.
+type histogramBucket struct {
+	label string
+	upTo  time.Duration // exclusive upper bound; 0 = unbounded
+}
+
+var flowBuckets = []histogramBucket{
+	{"<1h", time.Hour},
+	{"1-4h", 4 * time.Hour},
+	{"4-12h", 12 * time.Hour},
+	{"12h-1d", 24 * time.Hour},
+	{"1-2d", 48 * time.Hour},
+	{"2-4d", 96 * time.Hour},
+	{"4-7d", 7 * 24 * time.Hour},
+	{"1-2w", 14 * 24 * time.Hour},
+	{"2w+", 0},
+}
+
+// Analytics is the flow analytics overlay: cycle time (started → closed) and
+// lead time (created → closed) percentiles broken down by type, priority,
+// label or assignee, with histogram and scatter views of the selected group
+// and a list of in-progress work older than its type's P85 cycle time. A
+// fourth view stacks the parade sections into a cumulative flow diagram, and
+// a fifth charts recorded spend. It is shown in place of the detail pane.
+type Analytics struct {
+	width  int
+	height int
+
+	flow  *gastown.FlowMetrics
+	cfd   gastown.CFD
+	costs []gastown.CostSample
+	now   time.Time
+
+	dim    int  // index into gastown.FlowDimensions
+	lead   bool // lead time instead of cycle time
+	mode   analyticsMode
+	cursor int // 0 = all issues, then the dimension's groups
+	offset int
+	window int // index into cfdWindows
+}
+
+// NewAnalytics constructs an empty analytics overlay.
+func NewAnalytics(width, height int) Analytics {
+	return Analytics{width: width, height: height, window: 1}
+}
+
+// SetSize updates dimensions.
+func (a *Analytics) SetSize(width, height int) {
+	a.width = width
+	a.height = height
+	a.scrollToCursor()
+}
+
+// SetFlow swaps in freshly computed flow metrics, keeping the breakdown and
+// cursor.
+func (a *Analytics) SetFlow(f *gastown.FlowMetrics, now time.Time) {
+	a.flow = f
+	a.now = now
+	a.cursor = max(min(a.cursor, len(a.rows())-1), 0)
+	a.scrollToCursor()
+}
+
+// SetCFD swaps in a freshly computed cumulative flow history. It should span
+// gastown.MaxCFDDays; the overlay shows a window from its tail.
+func (a *Analytics) SetCFD(c gastown.CFD) {
+	a.cfd = c
+}
+
+// SetCostHistory swaps in the recorded cost samples for the costs view.
+func (a *Analytics) SetCostHistory(samples []gastown.CostSample) {
+	a.costs = samples
+}
+
+// Aging returns the in-progress issues past their type's P85 cycle time.
+func (a *Analytics) Aging() []gastown.AgingItem {
+	if a.flow == nil {
+		return nil
+	}
+	return a.flow.Aging
+}
+
+// Update handles row navigation, breakdown, metric and mode switches, and the
+// cumulative flow window.
+func (a Analytics) Update(msg tea.Msg) (Analytics, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return a, nil
+	}
+	n := len(a.rows())
+	switch keyMsg.String() {
+	case "j", "down":
+		a.moveCursor(1)
+	case "k", "up":
+		a.moveCursor(-1)
+	case "g":
+		a.moveCursor(-n)
+	case "G":
+		a.moveCursor(n)
+	case "l", "right":
+		a.dim = (a.dim + 1) % len(gastown.FlowDimensions)
+		a.cursor, a.offset = 0, 0
+	case "h", "left":
+		a.dim = (a.dim + len(gastown.FlowDimensions) - 1) % len(gastown.FlowDimensions)
+		a.cursor, a.offset = 0, 0
+	case "m":
+		a.lead = !a.lead
+	case "v":
+		a.mode = (a.mode + 1) % analyticsModes
+	case "+", "=":
+		a.window = min(a.window+1, len(cfdWindows)-1)
+	case "-":
+		a.window = max(a.window-1, 0)
+	}
+	return a, nil
+}
+
+// rows is the overall group followed by the current breakdown.
+func (a Analytics) rows() []gastown.FlowGroup {
+	if a.flow == nil {
+		return nil
+	}
+	return append([]gastown.FlowGroup{a.flow.Overall}, a.flow.Groups(gastown.FlowDimensions[a.dim])...)
+}
+
+func (a *Analytics) moveCursor(delta int) {
+	a.cursor = max(min(a.cursor+delta, len(a.rows())-1), 0)
+	a.scrollToCursor()
+}
+
+func (a *Analytics) scrollToCursor() {
+	body := a.bodyHeight() - 1 // table header row
+	if a.cursor < a.offset {
+		a.offset = a.cursor
+	}
+	if a.cursor >= a.offset+body {
+		a.offset = a.cursor - body + 1
+	}
+	a.offset = max(min(a.offset, len(a.rows())-body), 0)
+}
+
+// agingHeight is the rows the aging list takes under the chart (its title
+// plus up to maxAgingRows items), or 0 when nothing is aging.
+func (a *Analytics) agingHeight() int {
+	n := len(a.Aging())
+	if n == 0 {
+		return 0
+	}
+	return 1 + min(n, maxAgingRows)
+}
+
+// bodyHeight is the rows left for the table or chart between the header
+// (title, tabs, summary, blank) and the footer (aging list, blank, hints).
+func (a *Analytics) bodyHeight() int {
+	return max(a.height-6-a.agingHeight(), 3)
+}
+
+func (a Analytics) stats(g gastown.FlowGroup) gastown.FlowStats {
+	if a.lead {
+		return g.Lead
+	}
+	return g.Cycle
+}
+
+func (a Analytics) metricName() string {
+	if a.lead {
+		return "lead time"
+	}
+	return "cycle time"
+}
+
+// sampleValue returns a sample's duration for the current metric.
+func (a Analytics) sampleValue(s gastown.FlowSample) (time.Duration, bool) {
+	if a.lead {
+		return s.Lead, true
+	}
+	return s.Cycle, s.HasCycle
+}
+
+// View renders the overlay inside ui.DetailBorder.
+func (a Analytics) View() string {
+	header := lipgloss.NewStyle().Bold(true).Foreground(ui.BrightGold).Render("FLOW ANALYTICS")
+	out := []string{header, a.tabLine(), a.summaryLine(), ""}
+
+	dim := lipgloss.NewStyle().Foreground(ui.Dim)
+	body := a.bodyHeight()
+	var chart []string
+	switch {
+	case a.mode == analyticsCFD:
+		chart = a.renderCFD(body)
+	case a.mode == analyticsCosts:
+		chart = a.renderCosts(body)
+	case a.flow == nil:
+		chart = []string{dim.Render("Computing flow metrics...")}
+	case len(a.flow.Samples) == 0:
+		chart = []string{dim.Render("No closed issues yet: cycle and lead times need history.")}
+	case a.mode == analyticsHistogram:
+		chart = a.renderHistogram(body)
+	case a.mode == analyticsScatter:
+		chart = a.renderScatter(body)
+	default:
+		chart = a.renderTable(body)
+	}
+	if len(chart) > body {
+		chart = chart[:body]
+	}
+	out = append(out, chart...)
+	for i := len(chart); i < body; i++ {
+		out = append(out, "")
+	}
+
+	out = append(out, a.renderAging()...)
+	hints := "  j/k group  h/l breakdown  m cycle/lead  v table/histogram/scatter/flow/costs  +/- days  esc close"
+	out = append(out, "", dim.Render(ansi.Truncate(hints, max(a.width-4, 10), "…")))
+
+	return ui.DetailBorder.Width(a.width).Height(a.height).Render(strings.Join(out, "\n"))
+}
+
+func (a Analytics) tabLine() string {
+	var tabs []string
+	for i, d := range gastown.FlowDimensions {
+		if i == a.dim {
+			tabs = append(tabs, lipgloss.NewStyle().Foreground(ui.BrightGold).Bold(true).Underline(true).Render(d.String()))
+		} else {
+			tabs = append(tabs, lipgloss.NewStyle().Foreground(ui.Muted).Render(d.String()))
+		}
+	}
+	metric := lipgloss.NewStyle().Foreground(ui.Light).Render(a.metricName())
+	mode := lipgloss.NewStyle().Foreground(ui.Dim).Render(a.mode.String())
+	return "by " + strings.Join(tabs, "  ") + "    " + metric + " · " + mode
+}
+
+func (a Analytics) summaryLine() string {
+	dim := lipgloss.NewStyle().Foreground(ui.Dim)
+	if a.flow == nil {
+		return ""
+	}
+	parts := []string{fmt.Sprintf("%d closed", a.flow.Overall.Lead.Count)}
+	if missing := a.flow.Overall.Lead.Count - a.flow.Overall.Cycle.Count; missing > 0 {
+		parts = append(parts, fmt.Sprintf("%d without a start time", missing))
+	}
+	s := dim.Render(strings.Join(parts, " · "))
+	if n := len(a.flow.Aging); n > 0 {
+		s += dim.Render(" · ") + lipgloss.NewStyle().Foreground(ui.StatusStalled).Render(fmt.Sprintf("%d aging", n))
+	}
+	return s
+}
+
+func (a Analytics) renderTable(body int) []string {
+	keyW := max(min(a.width-4-2-4*7-6, 24), 8)
+	head := fmt.Sprintf("  %-*s %5s %6s %6s %6s %6s", keyW, "GROUP", "N", "P50", "P85", "P95", "MAX")
+	lines := []string{lipgloss.NewStyle().Foreground(ui.Muted).Bold(true).Render(head)}
+
+	rows := a.rows()
+	end := min(a.offset+body-1, len(rows))
+	for i := a.offset; i < end; i++ {
+		g := rows[i]
+		st := a.stats(g)
+		cursor := "  "
+		if i == a.cursor {
+			cursor = lipgloss.NewStyle().Foreground(ui.BrightGold).Render("▸ ")
+		}
+		key := truncate(g.Key, keyW)
+		keyStyle := lipgloss.NewStyle().Foreground(ui.Light).Bold(i == a.cursor)
+		if i == 0 {
+			keyStyle = keyStyle.Foreground(ui.BrightGold)
+		}
+		nums := fmt.Sprintf(" %5d %6s %6s %6s %6s", st.Count,
+			gastown.FormatSpan(st.P50), gastown.FormatSpan(st.P85),
+			gastown.FormatSpan(st.P95), gastown.FormatSpan(st.Max))
+		line := cursor + keyStyle.Render(fmt.Sprintf("%-*s", keyW, key)) +
+			lipgloss.NewStyle().Foreground(ui.Dim).Render(nums)
+		lines = append(lines, ansi.Truncate(line, max(a.width-4, 10), ""))
+	}
+	return lines
+}
+
+// selected returns the group under the cursor.
+func (a Analytics) selected() gastown.FlowGroup {
+	rows := a.rows()
+	if a.cursor < len(rows) {
+		return rows[a.cursor]
+	}
+	return gastown.FlowGroup{}
+}
+
+func (a Analytics) renderHistogram(body int) []string {
+	g := a.selected()
+	st := a.stats(g)
+	counts := make([]int, len(flowBuckets))
+	peak := 0
+	for _, idx := range g.Samples {
+		d, ok := a.sampleValue(a.flow.Samples[idx])
+		if !ok {
+			continue
+		}
+		b := len(flowBuckets) - 1
+		for i, fb := range flowBuckets {
+			if fb.upTo > 0 && d < fb.upTo {
+				b = i
+				break
+			}
+		}
+		counts[b]++
+		peak = max(peak, counts[b])
+	}
+
+	title := fmt.Sprintf("%s · %s · %d samples", g.Key, a.metricName(), st.Count)
+	lines := []string{lipgloss.NewStyle().Foreground(ui.Light).Render(title)}
+	if st.Count == 0 {
+		return append(lines, lipgloss.NewStyle().Foreground(ui.Dim).Render("No samples for this group."))
+	}
+	barW := max(a.width-4-8-14, 5)
+	for i, fb := range flowBuckets {
+		if len(lines) >= body {
+			break
+		}
+		n := counts[i]
+		w := 0
+		if peak > 0 {
+			w = n * barW / peak
+		}
+		if n > 0 && w == 0 {
+			w = 1
+		}
+		bar := lipgloss.NewStyle().Foreground(ui.BrightGreen).Render(strings.Repeat(ui.SymProgress, w))
+		line := fmt.Sprintf("  %-7s %s %s", fb.label, bar, lipgloss.NewStyle().Foreground(ui.Dim).Render(fmt.Sprintf("%d", n)))
+		if st.P85 >= lowerBound(i) && (fb.upTo == 0 || st.P85 < fb.upTo) {
+			line += lipgloss.NewStyle().Foreground(ui.BrightGold).Render(" ◂ P85")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// lowerBound is the inclusive lower edge of histogram bucket i.
+func lowerBound(i int) time.Duration {
+	if i == 0 {
+		return 0
+	}
+	return flowBuckets[i-1].upTo
+}
+
+// renderScatter plots every sample by close date (x) and duration (y, log
+// scale), with the cursor group's samples highlighted and its P85 marked.
+func (a Analytics) renderScatter(body int) []string {
+	g := a.selected()
+	highlight := make(map[int]bool, len(g.Samples))
+	for _, idx := range g.Samples {
+		highlight[idx] = true
+	}
+
+	type point struct {
+		at  time.Time
+		d   time.Duration
+		hot bool
+	}
+	var pts []point
+	var first time.Time
+	var lo, hi time.Duration
+	for i, s := range a.flow.Samples {
+		d, ok := a.sampleValue(s)
+		if !ok || d <= 0 {
+			continue
+		}
+		pts = append(pts, point{s.Closed, d, highlight[i]})
+		if first.IsZero() || s.Closed.Before(first) {
+			first = s.Closed
+		}
+		if lo == 0 || d < lo {
+			lo = d
+		}
+		hi = max(hi, d)
+	}
+
+	title := fmt.Sprintf("%s (highlighted) · %s by close date", g.Key, a.metricName())
+	lines := []string{lipgloss.NewStyle().Foreground(ui.Light).Render(title)}
+	if len(pts) == 0 {
+		return append(lines, lipgloss.NewStyle().Foreground(ui.Dim).Render("No samples for this metric."))
+	}
+
+	const axisW = 7
+	plotW := max(a.width-4-2-axisW-1, 10)
+	plotH := max(body-2, 3)
+	last := a.now
+	if last.IsZero() || last.Before(first) {
+		last = pts[len(pts)-1].at
+	}
+	span := last.Sub(first)
+	logLo, logHi := math.Log(float64(lo)), math.Log(float64(hi))
+	row := func(d time.Duration) int {
+		if logHi <= logLo {
+			return plotH / 2
+		}
+		frac := (math.Log(float64(d)) - logLo) / (logHi - logLo)
+		return plotH - 1 - int(frac*float64(plotH-1)+0.5)
+	}
+
+	grid := make([][]rune, plotH)
+	hot := make([][]bool, plotH)
+	for r := range grid {
+		grid[r] = []rune(strings.Repeat(" ", plotW))
+		hot[r] = make([]bool, plotW)
+	}
+	if p85 := a.stats(g).P85; p85 >= lo && p85 <= hi {
+		r := row(p85)
+		for c := range grid[r] {
+			grid[r][c] = '┄'
+		}
+	}
+	for _, p := range pts {
+		c := plotW - 1
+		if span > 0 {
+			c = int(float64(p.at.Sub(first)) / float64(span) * float64(plotW-1))
+		}
+		c = max(min(c, plotW-1), 0)
+		r := row(p.d)
+		if p.hot {
+			grid[r][c] = '●'
+			hot[r][c] = true
+		} else if !hot[r][c] {
+			grid[r][c] = '·'
+		}
+	}
+
+	dimStyle := lipgloss.NewStyle().Foreground(ui.Dim)
+	hotStyle := lipgloss.NewStyle().Foreground(ui.BrightGold)
+	for r := range grid {
+		label := ""
+		switch r {
+		case 0:
+			label = gastown.FormatSpan(hi)
+		case plotH - 1:
+			label = gastown.FormatSpan(lo)
+		}
+		var b strings.Builder
+		for c, ch := range grid[r] {
+			switch {
+			case hot[r][c]:
+				b.WriteString(hotStyle.Render(string(ch)))
+			case ch == '┄':
+				b.WriteString(hotStyle.Faint(true).Render(string(ch)))
+			default:
+				b.WriteString(dimStyle.Render(string(ch)))
+			}
+		}
+		lines = append(lines, "  "+dimStyle.Render(fmt.Sprintf("%*s", axisW, label))+"│"+b.String())
+	}
+	from, to := first.Local().Format("Jan 02"), last.Local().Format("Jan 02")
+	gap := max(plotW-len(from)-len(to), 1)
+	lines = append(lines, "  "+strings.Repeat(" ", axisW+1)+dimStyle.Render(from+strings.Repeat(" ", gap)+to))
+	return lines
+}
+
+// renderAging lists in-progress issues past their type's P85 cycle time.
+func (a Analytics) renderAging() []string {
+	aging := a.Aging()
+	if len(aging) == 0 {
+		return nil
+	}
+	warn := lipgloss.NewStyle().Foreground(ui.StatusStalled)
+	dim := lipgloss.NewStyle().Foreground(ui.Dim)
+	title := fmt.Sprintf("AGING WIP · %d past their type's P85 cycle time", len(aging))
+	lines := []string{warn.Bold(true).Render(title)}
+	for _, it := range aging[:min(len(aging), maxAgingRows)] {
+		who := it.Assignee
+		if who == "" {
+			who = "unassigned"
+		}
+		line := warn.Render(ui.SymWarning+" "+it.IssueID) + " " +
+			lipgloss.NewStyle().Foreground(ui.Light).Render(it.Title) + " " +
+			dim.Render(fmt.Sprintf("%s · %s · %s > P85 %s", it.Type, who,
+				gastown.FormatSpan(it.Age), gastown.FormatSpan(it.Threshold)))
+		lines = append(lines, ansi.Truncate("  "+line, max(a.width-4, 10), "…"))
+	}
+	return lines
+}
+
+// cfdBlocks are lower-eighth blocks for the boundary between two stacked
+// bands: index n fills the bottom n/8 of the cell.
+var cfdBlocks = []string{" ", "▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+
+// renderCFD stacks the parade sections over the selected day window, Past the
+// Stand at the base and Lined Up on top, in the parade's section colors. Band
+// edges inside a cell use eighth blocks so growth reads smoothly. A legend
+// row per section gives its count, change over the window and a sparkline.
+func (a Analytics) renderCFD(body int) []string {
+	days := a.cfd.Last(cfdWindows[a.window])
+	dimStyle := lipgloss.NewStyle().Foreground(ui.Dim)
+	title := fmt.Sprintf("cumulative flow · last %d days", cfdWindows[a.window])
+	lines := []string{lipgloss.NewStyle().Foreground(ui.Light).Render(title)}
+	if len(days) == 0 {
+		return append(lines, dimStyle.Render("No issue history yet."))
+	}
+
+	colors := make(map[data.ParadeStatus]color.Color)
+	for _, sec := range sections() {
+		colors[sec.Status] = sec.Color
+	}
+
+	peak := 0
+	for _, d := range days {
+		peak = max(peak, d.Total())
+	}
+	peak = max(peak, 1)
+
+	const axisW = 5
+	plotW := max(a.width-4-2-axisW-1, 10)
+	plotH := max(body-2-len(gastown.CFDSections), 3)
+	unit := float64(peak) / float64(plotH)
+
+	// Cumulative band tops per column, bottom band first.
+	cols := make([][]float64, plotW)
+	for c := range cols {
+		d := days[min(c*len(days)/plotW, len(days)-1)]
+		if len(days) > plotW {
+			// Compressed: take the last day the column covers.
+			d = days[min((c+1)*len(days)/plotW-1, len(days)-1)]
+		}
+		tops := make([]float64, len(gastown.CFDSections))
+		sum := 0
+		for i, sec := range gastown.CFDSections {
+			sum += d.Counts[sec]
+			tops[i] = float64(sum)
+		}
+		cols[c] = tops
+	}
+	// band returns the index of the band covering height y, or -1 above the
+	// stack.
+	band := func(tops []float64, y float64) int {
+		for i, top := range tops {
+			if y < top {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for r := range plotH {
+		bottom := float64(plotH-1-r) * unit
+		var b strings.Builder
+		for c := range plotW {
+			tops := cols[c]
+			lo := band(tops, bottom)
+			if lo < 0 {
+				b.WriteString(" ")
+				continue
+			}
+			fill := min(int((tops[lo]-bottom)/unit*8+0.5), 8)
+			hi := band(tops, tops[lo])
+			style := lipgloss.NewStyle().Foreground(colors[gastown.CFDSections[lo]])
+			if fill < 8 && hi >= 0 {
+				style = style.Background(colors[gastown.CFDSections[hi]])
+			}
+			if fill == 0 && hi >= 0 {
+				b.WriteString(lipgloss.NewStyle().Foreground(colors[gastown.CFDSections[hi]]).Render("█"))
+				continue
+			}
+			b.WriteString(style.Render(cfdBlocks[max(fill, 1)]))
+		}
+		label := ""
+		switch r {
+		case 0:
+			label = fmt.Sprintf("%d", peak)
+		case plotH - 1:
+			label = "0"
+		}
+		lines = append(lines, "  "+dimStyle.Render(fmt.Sprintf("%*s", axisW, label))+"│"+b.String())
+	}
+	from, to := days[0].Day.Format("Jan 02"), days[len(days)-1].Day.Format("Jan 02")
+	gap := max(plotW-len(from)-len(to), 1)
+	lines = append(lines, "  "+strings.Repeat(" ", axisW+1)+dimStyle.Render(from+strings.Repeat(" ", gap)+to))
+
+	// Legend, top band first so it reads like the chart.
+	first, last := days[0], days[len(days)-1]
+	open := last.Total() - last.Counts[data.ParadePastTheStand]
+	sparkW := max(min(len(days), a.width-4-30), 5)
+	for i := len(gastown.CFDSections) - 1; i >= 0; i-- {
+		status := gastown.CFDSections[i]
+		var sec paradeSection
+		for _, s := range sections() {
+			if s.Status == status {
+				sec = s
+			}
+		}
+		series := make([]int, len(days))
+		for j, d := range days {
+			series[j] = d.Counts[status]
+		}
+		// Sparklines show the tail when the window is wider than the space.
+		if len(series) > sparkW {
+			series = series[len(series)-sparkW:]
+		}
+		n := last.Counts[status]
+		delta := dimStyle.Render(fmt.Sprintf("%+4d", n-first.Counts[status]))
+		if status == data.ParadeStalled && open > 0 {
+			// Stalled share of open work, hotter as more of it is stuck.
+			delta = ui.GradientHeat.At(n * 100 / open).Render(fmt.Sprintf("%+4d", n-first.Counts[status]))
+		}
+		name := lipgloss.NewStyle().Foreground(sec.Color).Render(fmt.Sprintf("%s %-14s", sec.Symbol, sec.Title))
+		lines = append(lines, "  "+name+dimStyle.Render(fmt.Sprintf("%4d", n))+" "+delta+"  "+ui.RenderSparkline(series, sparkW))
+	}
+	return lines
+}
+
+// renderCosts charts recorded spend over the selected day window: daily cost,
+// tokens in and out, and weekly totals as sparklines, then a table of cost
+// per role and rig with each one's share and daily trend.
+func (a Analytics) renderCosts(body int) []string {
+	n := cfdWindows[a.window]
+	days := gastown.CostDays(a.costs, n, a.now)
+	dimStyle := lipgloss.NewStyle().Foreground(ui.Dim)
+	light := lipgloss.NewStyle().Foreground(ui.Light)
+	lines := []string{light.Render(fmt.Sprintf("cost · last %d days", n))}
+
+	total := gastown.SumCostDays(days)
+	if total == nil {
+		return append(lines, dimStyle.Render("No cost history yet: mg records gt costs while the Gas Town panel or budgets poll it."))
+	}
+	sampled := 0
+	for _, d := range days {
+		if d.Sampled {
+			sampled++
+		}
+	}
+	lines = append(lines, dimStyle.Render(fmt.Sprintf("$%.2f · $%.2f/day over %d recorded days · %dk in / %dk out",
+		total.Total.Cost, total.Total.Cost/float64(sampled), sampled,
+		total.Total.InputTokens/1000, total.Total.OutputTokens/1000)), "")
+
+	const labelW = 12
+	sparkW := max(min(len(days), a.width-4-2-labelW-14), 5)
+	tail := func(series []int) []int {
+		// Sparklines show the tail when the window is wider than the space.
+		if len(series) > sparkW {
+			return series[len(series)-sparkW:]
+		}
+		return series
+	}
+	row := func(label string, series []int, note string) string {
+		return "  " + lipgloss.NewStyle().Foreground(ui.Muted).Render(fmt.Sprintf("%-*s", labelW, label)) +
+			ui.RenderSparkline(tail(series), sparkW) + "  " + dimStyle.Render(note)
+	}
+
+	cents := make([]int, len(days))
+	in := make([]int, len(days))
+	out := make([]int, len(days))
+	peak := 0.0
+	for i, d := range days {
+		cents[i] = int(d.Cost * 100)
+		in[i] = d.InputTokens / 1000
+		out[i] = d.OutputTokens / 1000
+		peak = max(peak, d.Cost)
+	}
+	lines = append(lines,
+		row("daily cost", cents, fmt.Sprintf("peak $%.2f", peak)),
+		row("tokens in", in, fmt.Sprintf("%dk", total.Total.InputTokens/1000)),
+		row("tokens out", out, fmt.Sprintf("%dk", total.Total.OutputTokens/1000)))
+
+	// Weekly totals, counted back from today so the last bar is this week.
+	var weeks []int
+	for end := len(days); end > 0; end -= 7 {
+		wk := gastown.SumCostDays(days[max(end-7, 0):end])
+		c := 0
+		if wk != nil {
+			c = int(wk.Total.Cost * 100)
+		}
+		weeks = append([]int{c}, weeks...)
+	}
+	note := fmt.Sprintf("this week $%.2f", float64(weeks[len(weeks)-1])/100)
+	if len(weeks) > 1 {
+		note += fmt.Sprintf(" · prior $%.2f", float64(weeks[len(weeks)-2])/100)
+	}
+	lines = append(lines, "  "+lipgloss.NewStyle().Foreground(ui.Muted).Render(fmt.Sprintf("%-*s", labelW, "weekly cost"))+
+		ui.RenderSparkline(weeks, len(weeks))+"  "+dimStyle.Render(note), "")
+
+	// Breakdown: roles then rigs, each by cost.
+	type share struct {
+		scope, name string
+		cost        float64
+	}
+	var shares []share
+	for _, rc := range total.ByRole {
+		shares = append(shares, share{"role", rc.Role, rc.Cost})
+	}
+	for _, rc := range total.ByRig {
+		shares = append(shares, share{"rig", rc.Rig, rc.Cost})
+	}
+	sort.SliceStable(shares, func(i, j int) bool {
+		if shares[i].scope != shares[j].scope {
+			return shares[i].scope == "role"
+		}
+		return shares[i].cost > shares[j].cost
+	})
+	nameW := max(min(a.width-4-2-10-7-sparkW-4, 20), 8)
+	head := fmt.Sprintf("  %-*s %10s %6s  %s", nameW, "ROLE / RIG", "COST", "SHARE", "DAILY")
+	lines = append(lines, lipgloss.NewStyle().Foreground(ui.Muted).Bold(true).Render(head))
+	for _, sh := range shares {
+		series := make([]int, len(days))
+		for i, d := range days {
+			if sh.scope == "role" {
+				series[i] = int(d.ByRole[sh.name] * 100)
+			} else {
+				series[i] = int(d.ByRig[sh.name] * 100)
+			}
+		}
+		pct := 0.0
+		if total.Total.Cost > 0 {
+			pct = sh.cost * 100 / total.Total.Cost
+		}
+		nameStyle := light
+		if sh.scope == "role" {
+			nameStyle = lipgloss.NewStyle().Foreground(ui.RoleColor(sh.name))
+		}
+		name := nameStyle.Render(fmt.Sprintf("%-*s", nameW, truncate(sh.scope+" "+sh.name, nameW)))
+		lines = append(lines, "  "+name+dimStyle.Render(fmt.Sprintf(" %10s %5.0f%%  ", fmt.Sprintf("$%.2f", sh.cost), pct))+
+			ui.RenderSparkline(tail(series), sparkW))
+	}
+	if len(lines) > body {
+		lines = lines[:body]
+	}
+	return lines
+}
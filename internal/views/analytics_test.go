@@ -0,0 +1,145 @@
+package views
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/matt-wright86/mardi-gras/internal/data"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+)
+
+func analyticsFlow(now time.Time) *gastown.FlowMetrics {
+	var issues []data.Issue
+	for i := 1; i <= 8; i++ {
+		created := now.Add(-time.Duration(10+i) * 24 * time.Hour)
+		started := created.Add(time.Hour)
+		closed := started.Add(time.Duration(i) * 6 * time.Hour)
+		typ := data.TypeTask
+		if i%4 == 0 {
+			typ = data.TypeBug
+		}
+		issues = append(issues, data.Issue{
+			ID: "mg-" + string(rune('a'+i)), IssueType: typ, Status: data.StatusClosed,
+			Priority: data.PriorityHigh, Assignee: "atlas",
+			CreatedAt: created, StartedAt: &started, ClosedAt: &closed,
+		})
+	}
+	stale := now.Add(-30 * 24 * time.Hour)
+	issues = append(issues, data.Issue{
+		ID: "mg-old", Title: "Stuck refactor", IssueType: data.TypeTask, Status: data.StatusInProgress,
+		CreatedAt: stale, StartedAt: &stale,
+	})
+	return gastown.ComputeFlow(issues, now)
+}
+
+func TestAnalyticsTableAndAging(t *testing.T) {
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	a := NewAnalytics(100, 30)
+	a.SetFlow(analyticsFlow(now), now)
+
+	out := ansi.Strip(a.View())
+	for _, want := range []string{"FLOW ANALYTICS", "GROUP", "P85", "all", "task", "bug", "cycle time", "AGING WIP", "mg-old"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("view missing %q:\n%s", want, out)
+		}
+	}
+	if len(a.Aging()) != 1 {
+		t.Errorf("Aging() = %d items, want 1", len(a.Aging()))
+	}
+}
+
+func TestAnalyticsKeys(t *testing.T) {
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	a := NewAnalytics(100, 30)
+	a.SetFlow(analyticsFlow(now), now)
+
+	a, _ = a.Update(transcriptKey("l"))
+	if out := ansi.Strip(a.View()); !strings.Contains(out, "P1") {
+		t.Errorf("l should switch to the priority breakdown:\n%s", out)
+	}
+	a, _ = a.Update(transcriptKey("m"))
+	if out := ansi.Strip(a.View()); !strings.Contains(out, "lead time") {
+		t.Errorf("m should switch to lead time:\n%s", out)
+	}
+	a, _ = a.Update(transcriptKey("m"))
+	a, _ = a.Update(transcriptKey("v"))
+	if out := ansi.Strip(a.View()); !strings.Contains(out, "◂ P85") {
+		t.Errorf("v should show the histogram with a P85 marker:\n%s", out)
+	}
+	a, _ = a.Update(transcriptKey("v"))
+	if out := ansi.Strip(a.View()); !strings.Contains(out, "●") {
+		t.Errorf("v again should show the scatter with highlighted samples:\n%s", out)
+	}
+}
+
+func TestAnalyticsEmpty(t *testing.T) {
+	a := NewAnalytics(80, 20)
+	a.SetFlow(gastown.ComputeFlow(nil, time.Now()), time.Now())
+	a, _ = a.Update(transcriptKey("j"))
+	if out := ansi.Strip(a.View()); !strings.Contains(out, "No closed issues yet") {
+		t.Errorf("empty flow should explain itself:\n%s", out)
+	}
+}
+
+func TestAnalyticsCumulativeFlow(t *testing.T) {
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	started := now.Add(-5 * 24 * time.Hour)
+	issues := []data.Issue{
+		{ID: "mg-1", Status: data.StatusInProgress, CreatedAt: now.Add(-20 * 24 * time.Hour), StartedAt: &started},
+		{ID: "mg-2", Status: data.StatusOpen, CreatedAt: now.Add(-10 * 24 * time.Hour),
+			Dependencies: []data.Dependency{{IssueID: "mg-2", DependsOnID: "mg-1", Type: "blocks"}}},
+	}
+	a := NewAnalytics(100, 30)
+	a.SetFlow(gastown.ComputeFlow(issues, now), now)
+	a.SetCFD(gastown.ComputeCFD(issues, data.DefaultBlockingTypes, gastown.MaxCFDDays, now))
+	for range 3 {
+		a, _ = a.Update(transcriptKey("v"))
+	}
+
+	out := ansi.Strip(a.View())
+	for _, want := range []string{"cumulative flow · last 30 days", "Rolling", "Lined Up", "Stalled", "Past the Stand", "█", "Jun 20"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("cumulative flow view missing %q:\n%s", want, out)
+		}
+	}
+
+	a, _ = a.Update(transcriptKey("+"))
+	if out := ansi.Strip(a.View()); !strings.Contains(out, "last 60 days") {
+		t.Errorf("+ should widen the window:\n%s", out)
+	}
+	a, _ = a.Update(transcriptKey("-"))
+	a, _ = a.Update(transcriptKey("-"))
+	if out := ansi.Strip(a.View()); !strings.Contains(out, "last 14 days") {
+		t.Errorf("- should narrow the window:\n%s", out)
+	}
+}
+
+func TestAnalyticsCosts(t *testing.T) {
+	now := time.Date(2026, 6, 20, 12, 0, 0, 0, time.Local)
+	var samples []gastown.CostSample
+	for i, cost := range []float64{4, 6, 10} {
+		s := gastown.CostSample{At: now.AddDate(0, 0, i-2)}
+		s.Total = gastown.CostTotal{InputTokens: 20000, OutputTokens: 5000, Cost: cost}
+		s.ByRole = []gastown.RoleCost{{Role: "polecat", Cost: cost}}
+		s.ByRig = []gastown.RigCost{{Rig: "gastown", Cost: cost}}
+		samples = append(samples, s)
+	}
+	a := NewAnalytics(100, 30)
+	a.SetFlow(gastown.ComputeFlow(nil, now), now)
+	for range 4 {
+		a, _ = a.Update(transcriptKey("v"))
+	}
+	if out := ansi.Strip(a.View()); !strings.Contains(out, "No cost history yet") {
+		t.Errorf("costs view without history should explain itself:\n%s", out)
+	}
+
+	a.SetCostHistory(samples)
+	out := ansi.Strip(a.View())
+	for _, want := range []string{"cost · last 30 days", "$20.00", "60k in / 15k out", "daily cost", "weekly cost", "role polecat", "rig gastown", "100%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("costs view missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/matt-wright86/mardi-gras/internal/ui"
+)
+
+// CodexSessionSelectMsg is emitted when the user picks a session to show.
+type CodexSessionSelectMsg struct {
+	IssueID string
+}
+
+// CodexSessionRow is one codex MCP session in the session list.
+type CodexSessionRow struct {
+	IssueID     string
+	Title       string
+	Model       string
+	State       string // "running", "awaiting approval", "done", "errored", "canceled"
+	Tokens      int
+	LastEventAt time.Time
+	Shown       bool // the transcript currently shown by M
+}
+
+// CodexSessions lists the codex MCP sessions in place of the detail pane so
+// the user can switch between their transcripts.
+type CodexSessions struct {
+	width  int
+	height int
+	rows   []CodexSessionRow
+	cursor int
+}
+
+// NewCodexSessions creates an empty session list.
+func NewCodexSessions(width, height int) CodexSessions {
+	return CodexSessions{width: width, height: height}
+}
+
+// SetSize updates dimensions.
+func (c *CodexSessions) SetSize(width, height int) {
+	c.width = width
+	c.height = height
+}
+
+// SetRows replaces the listed sessions, keeping the cursor in range.
+func (c *CodexSessions) SetRows(rows []CodexSessionRow) {
+	c.rows = rows
+	if c.cursor >= len(rows) {
+		c.cursor = max(len(rows)-1, 0)
+	}
+}
+
+// Update handles key events for the session list.
+func (c CodexSessions) Update(msg tea.Msg) (CodexSessions, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyPressMsg)
+	if !ok || len(c.rows) == 0 {
+		return c, nil
+	}
+	switch key := keyMsg.String(); key {
+	case "j", "down":
+		if c.cursor < len(c.rows)-1 {
+			c.cursor++
+		}
+	case "k", "up":
+		if c.cursor > 0 {
+			c.cursor--
+		}
+	case "g":
+		c.cursor = 0
+	case "G":
+		c.cursor = len(c.rows) - 1
+	case "enter":
+		return c, selectCodexSession(c.rows[c.cursor].IssueID)
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		if i := int(key[0] - '1'); i < len(c.rows) {
+			c.cursor = i
+			return c, selectCodexSession(c.rows[i].IssueID)
+		}
+	}
+	return c, nil
+}
+
+func selectCodexSession(issueID string) tea.Cmd {
+	return func() tea.Msg {
+		return CodexSessionSelectMsg{IssueID: issueID}
+	}
+}
+
+// View renders the session list.
+func (c CodexSessions) View() string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.BrightGold)
+	dimStyle := lipgloss.NewStyle().Foreground(ui.Dim)
+	lines := []string{headerStyle.Render(fmt.Sprintf("CODEX SESSIONS (%d)", len(c.rows))), ""}
+	if len(c.rows) == 0 {
+		lines = append(lines, dimStyle.Render("  No codex MCP sessions. Press M on an issue to launch one."))
+	}
+
+	now := time.Now()
+	for i, r := range c.rows {
+		prefix := "  "
+		if i == c.cursor {
+			prefix = ui.ItemCursor.Render(ui.Cursor) + " "
+		}
+		num := dimStyle.Render(fmt.Sprintf("%d", i+1))
+		if i >= 9 {
+			num = " "
+		}
+		id := lipgloss.NewStyle().Foreground(ui.Light).Bold(r.Shown).Render(r.IssueID)
+		title := truncate(r.Title, max(c.width-len(r.IssueID)-12, 10))
+		lines = append(lines, fmt.Sprintf("%s%s %s  %s", prefix, num, id, dimStyle.Render(title)))
+
+		details := []string{codexStateLabel(r.State)}
+		if r.Model != "" {
+			details = append(details, lipgloss.NewStyle().Foreground(ui.Muted).Render(r.Model))
+		}
+		if r.Tokens > 0 {
+			details = append(details, dimStyle.Render(formatTokens(r.Tokens)+" tokens"))
+		}
+		if !r.LastEventAt.IsZero() {
+			details = append(details, dimStyle.Render("last event "+formatDuration(now.Sub(r.LastEventAt))+" ago"))
+		}
+		lines = append(lines, "      "+strings.Join(details, dimStyle.Render(" · ")), "")
+	}
+
+	lines = append(lines, dimStyle.Render("  enter/1-9 show transcript  S close"))
+	return ui.DetailBorder.Width(c.width).Height(c.height).Render(strings.Join(lines, "\n"))
+}
+
+// codexStateLabel colors a session state like the transcript status line.
+func codexStateLabel(state string) string {
+	fg := ui.BrightGold
+	sym := ui.SymWorking
+	switch state {
+	case "awaiting approval":
+		fg, sym = ui.StatusStalled, ui.SymOverdue
+	case "done":
+		fg = ui.BrightGreen
+	case "errored":
+		fg, sym = ui.StatusStalled, ui.SymStalled
+	case "canceled":
+		fg, sym = ui.Muted, ui.SymStalled
+	}
+	return lipgloss.NewStyle().Foreground(fg).Render(sym + " " + state)
+}
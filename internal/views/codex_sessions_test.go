@@ -0,0 +1,39 @@
+package views
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestCodexSessionsViewAndSelect(t *testing.T) {
+	c := NewCodexSessions(80, 30)
+	c.SetRows([]CodexSessionRow{
+		{IssueID: "mg-1", Title: "Parser", Model: "gpt-5", State: "running", Tokens: 2500, LastEventAt: time.Now().Add(-2 * time.Minute)},
+		{IssueID: "mg-2", Title: "Lexer", State: "awaiting approval"},
+	})
+	view := ansi.Strip(c.View())
+	for _, want := range []string{"CODEX SESSIONS (2)", "mg-1", "Parser", "gpt-5", "2.5k tokens", "last event 2m ago", "awaiting approval"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	c, _ = c.Update(tea.KeyPressMsg{Code: 'j', Text: "j"})
+	_, cmd := c.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if msg, ok := cmd().(CodexSessionSelectMsg); !ok || msg.IssueID != "mg-2" {
+		t.Fatalf("enter selected %+v", msg)
+	}
+	_, cmd = c.Update(tea.KeyPressMsg{Code: '1', Text: "1"})
+	if msg, ok := cmd().(CodexSessionSelectMsg); !ok || msg.IssueID != "mg-1" {
+		t.Fatalf("1 selected %+v", msg)
+	}
+
+	c.SetRows(nil)
+	if _, cmd := c.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); cmd != nil {
+		t.Error("enter on an empty list should do nothing")
+	}
+}
@@ -1,6 +1,7 @@
 package views
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"image/color"
@@ -17,27 +18,47 @@ import (
 // Entries are appended as codex/event notifications arrive and rendered in
 // arrival order.
 type CodexTranscriptEntry struct {
-	At    time.Time
-	Kind  string // "agent", "user", "exec", "tool", "search", "patch", "task", "error", "info"
-	Title string
-	Body  string // optional multi-line body (wrapped on render)
-	Error bool
+	At    time.Time `json:"at"`
+	Kind  string    `json:"kind"` // "agent", "user", "reasoning", "exec", "tool", "search", "patch", "plan", "task", "error", "info", "raw"
+	Title string    `json:"title"`
+	Body  string    `json:"body,omitempty"` // optional multi-line body (wrapped on render)
+	// Detail is folded under the title until the entry is expanded: command
+	// output, the rest of a reasoning summary, a plan's steps, or the JSON of
+	// an event mg doesn't know.
+	Detail   string `json:"detail,omitempty"`
+	Error    bool   `json:"error,omitempty"`
+	Expanded bool   `json:"-"`
 }
 
-// CodexTranscriptState is the rendered state for one issue's session.
+// CodexTranscriptState is the rendered state for one issue's session. It is
+// also what mg saves per issue, so the JSON shape is a file format.
 type CodexTranscriptState struct {
-	IssueID  string
-	ThreadID string
-	Model    string
-	Cwd      string
-	Status   string // "running", "done", "errored", "canceled"
-	Entries  []CodexTranscriptEntry
-	StartAt  time.Time
-	EndAt    time.Time
+	IssueID string `json:"issue_id"`
+	// Agent names the runtime for the header; "" is codex.
+	Agent    string                 `json:"agent,omitempty"`
+	ThreadID string                 `json:"thread_id,omitempty"`
+	Model    string                 `json:"model,omitempty"`
+	Cwd      string                 `json:"cwd,omitempty"`
+	Status   string                 `json:"status"` // "running", "done", "errored", "canceled"
+	Entries  []CodexTranscriptEntry `json:"entries"`
+	StartAt  time.Time              `json:"start_at"`
+	EndAt    time.Time              `json:"end_at,omitzero"`
+	// Final is the content of the last completed turn.
+	Final string `json:"final,omitempty"`
 	// TurnStartAt is the start time of the current turn (initial or reply).
 	// Reset on each codex-reply dispatch so the status-line elapsed timer
 	// reflects per-turn duration rather than total session lifetime.
-	TurnStartAt time.Time
+	TurnStartAt time.Time `json:"-"`
+	// LastEventAt is when the session last sent any event, displayed or not.
+	LastEventAt time.Time `json:"last_event_at,omitzero"`
+	// Tokens is the session's total token usage from token_count events.
+	Tokens int `json:"tokens,omitempty"`
+	// ContextTokens is how much of the model's context window the last
+	// response used, out of ContextWindow.
+	ContextTokens int `json:"context_tokens,omitempty"`
+	ContextWindow int `json:"context_window,omitempty"`
+	// Plan is the agent's latest step list from plan_update events.
+	Plan []codexmcp.PlanItem `json:"plan,omitempty"`
 }
 
 // maxTranscriptEntries caps Entries to prevent unbounded growth over long
@@ -57,15 +78,23 @@ func (s *CodexTranscriptState) AppendEntry(e CodexTranscriptEntry) {
 }
 
 // CodexTranscript renders the right-pane overlay showing live agent state.
+// A cursor picks an entry to expand; in follow mode it sits on the newest
+// entry and the view stays pinned to the bottom as events arrive.
 type CodexTranscript struct {
 	width  int
 	height int
 	state  *CodexTranscriptState
+
+	cursor int
+	follow bool
 }
 
+// maxPlanRows caps the pinned plan checklist above the entries.
+const maxPlanRows = 8
+
 // NewCodexTranscript constructs a transcript view with the given dimensions.
 func NewCodexTranscript(w, h int) CodexTranscript {
-	return CodexTranscript{width: w, height: h}
+	return CodexTranscript{width: w, height: h, follow: true}
 }
 
 // SetSize updates dimensions.
@@ -75,12 +104,59 @@ func (c *CodexTranscript) SetSize(w, h int) {
 }
 
 // SetState swaps the underlying transcript state pointer. Passing nil renders
-// an empty "no active session" placeholder.
-func (c *CodexTranscript) SetState(s *CodexTranscriptState) { c.state = s }
+// an empty "no active session" placeholder. Switching to another session
+// puts the cursor back in follow mode; refreshing the same one keeps it.
+func (c *CodexTranscript) SetState(s *CodexTranscriptState) {
+	if s != c.state {
+		c.follow = true
+	}
+	c.state = s
+}
+
+// Following reports whether the cursor tracks the newest entry.
+func (c CodexTranscript) Following() bool { return c.follow }
+
+// Update moves the entry cursor and expands or folds the entry under it.
+func (c CodexTranscript) Update(msg tea.Msg) (CodexTranscript, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyPressMsg)
+	if !ok || c.state == nil || len(c.state.Entries) == 0 {
+		return c, nil
+	}
+	last := len(c.state.Entries) - 1
+	cur := c.current()
+	switch keyMsg.String() {
+	case "j", "down":
+		c.moveTo(cur + 1)
+	case "k", "up":
+		c.moveTo(cur - 1)
+	case "g":
+		c.moveTo(0)
+	case "G":
+		c.moveTo(last)
+	case "enter", "space":
+		if e := &c.state.Entries[cur]; e.Detail != "" {
+			e.Expanded = !e.Expanded
+		}
+	}
+	return c, nil
+}
+
+// current returns the index of the entry under the cursor.
+func (c CodexTranscript) current() int {
+	last := len(c.state.Entries) - 1
+	if c.follow {
+		return last
+	}
+	return max(min(c.cursor, last), 0)
+}
 
-// Update is a no-op for now; the transcript view is read-only. Future work
-// will add scrolling, copy, and a kill keybind.
-func (c CodexTranscript) Update(_ tea.Msg) (CodexTranscript, tea.Cmd) { return c, nil }
+// moveTo puts the cursor on entry i; landing on the newest entry turns
+// follow mode back on.
+func (c *CodexTranscript) moveTo(i int) {
+	last := len(c.state.Entries) - 1
+	c.cursor = max(min(i, last), 0)
+	c.follow = c.cursor == last
+}
 
 // View renders the transcript inside ui.DetailBorder.
 func (c CodexTranscript) View() string {
@@ -89,7 +165,11 @@ func (c CodexTranscript) View() string {
 }
 
 func (c CodexTranscript) body() string {
-	header := lipgloss.NewStyle().Bold(true).Foreground(ui.BrightGold).Render("CODEX (MCP)")
+	agent := "codex"
+	if c.state != nil && c.state.Agent != "" {
+		agent = c.state.Agent
+	}
+	header := lipgloss.NewStyle().Bold(true).Foreground(ui.BrightGold).Render(strings.ToUpper(agent) + " (MCP)")
 
 	if c.state == nil {
 		hint := lipgloss.NewStyle().Foreground(ui.Dim).Render(
@@ -101,20 +181,24 @@ func (c CodexTranscript) body() string {
 	meta := c.metaLine()
 	statusLine := c.statusLine()
 
-	out := []string{header, meta, statusLine, ""}
+	out := []string{header, meta, statusLine}
+	if len(c.state.Plan) > 0 {
+		out = append(out, c.planLines()...)
+	}
+	out = append(out, "")
 
 	if len(c.state.Entries) == 0 {
 		waiting := lipgloss.NewStyle().Foreground(ui.Dim).Render("waiting for first event...")
 		out = append(out, waiting)
 	} else {
-		// Reserve some space for the header/meta lines. Show the last N
-		// entries that fit; transcripts can grow long.
-		budget := max(c.height-6, 5)
+		// Whatever the header, plan, hint and border leave over goes to the
+		// entries; transcripts can grow long.
+		budget := max(c.height-len(out)-4, 5)
 		rendered := c.renderEntries(budget)
 		out = append(out, rendered...)
 	}
 
-	hint := lipgloss.NewStyle().Foreground(ui.Dim).Render("  r reply  M close  K kill session  esc back")
+	hint := lipgloss.NewStyle().Foreground(ui.Dim).Render("  j/k select  enter expand  r reply  S sessions  M close  esc back")
 	out = append(out, "", hint)
 
 	return strings.Join(out, "\n")
@@ -136,9 +220,71 @@ func (c CodexTranscript) metaLine() string {
 		}
 		parts = append(parts, lipgloss.NewStyle().Foreground(ui.Dim).Render("thread "+short))
 	}
+	if st.Tokens > 0 {
+		parts = append(parts, lipgloss.NewStyle().Foreground(ui.Dim).Render(formatTokens(st.Tokens)+" tokens"))
+	}
+	if pct, ok := st.ContextPercent(); ok {
+		fg := ui.Dim
+		switch {
+		case pct >= 90:
+			fg = ui.StatusStalled
+		case pct >= 70:
+			fg = ui.BrightGold
+		}
+		label := fmt.Sprintf("context %d%% of %s", pct, formatTokens(st.ContextWindow))
+		parts = append(parts, lipgloss.NewStyle().Foreground(fg).Render(label))
+	}
 	return strings.Join(parts, "  ")
 }
 
+// ContextPercent returns how full the model's context window was after the
+// last response. ok is false until both the window and a usage are known.
+func (s *CodexTranscriptState) ContextPercent() (pct int, ok bool) {
+	if s.ContextWindow <= 0 || s.ContextTokens <= 0 {
+		return 0, false
+	}
+	return min(s.ContextTokens*100/s.ContextWindow, 100), true
+}
+
+// planLines renders the pinned plan checklist, keeping the unfinished steps
+// in view when the plan is longer than maxPlanRows.
+func (c CodexTranscript) planLines() []string {
+	plan := c.state.Plan
+	done := 0
+	for _, it := range plan {
+		if it.Status == "completed" {
+			done++
+		}
+	}
+	head := lipgloss.NewStyle().Foreground(ui.Muted).Render(fmt.Sprintf("plan %d/%d", done, len(plan)))
+	out := []string{head}
+	start := 0
+	if len(plan) > maxPlanRows {
+		start = min(done, len(plan)-maxPlanRows)
+	}
+	if start > 0 {
+		out = append(out, lipgloss.NewStyle().Foreground(ui.Dim).Render(fmt.Sprintf("  %s %d done", ui.SymStepDone, start)))
+	}
+	for _, it := range plan[start:min(start+maxPlanRows, len(plan))] {
+		sym, fg := planSymbol(it.Status)
+		out = append(out, "  "+lipgloss.NewStyle().Foreground(fg).Render(sym+" "+it.Step))
+	}
+	if rest := len(plan) - start - maxPlanRows; rest > 0 {
+		out = append(out, lipgloss.NewStyle().Foreground(ui.Dim).Render(fmt.Sprintf("  … %d more", rest)))
+	}
+	return out
+}
+
+func planSymbol(status string) (string, color.Color) {
+	switch status {
+	case "completed":
+		return ui.SymStepDone, ui.Dim
+	case "in_progress":
+		return ui.SymStepActive, ui.BrightGold
+	}
+	return ui.SymStepReady, ui.Light
+}
+
 func (c CodexTranscript) statusLine() string {
 	st := c.state
 	var sym, label string
@@ -178,26 +324,47 @@ func (c CodexTranscript) statusLine() string {
 	return style.Render(sym+" "+label) + lipgloss.NewStyle().Foreground(ui.Dim).Render(elapsed)
 }
 
+// renderEntries fills budget lines with the entry under the cursor at the
+// bottom and as many earlier entries as fit above it. Only entries that can
+// be on screen are rendered; transcripts run to hundreds of entries.
 func (c CodexTranscript) renderEntries(budget int) []string {
 	entries := c.state.Entries
-	// Take last `budget` entries — naive line budget; entries may take more
-	// than one line each but this is close enough until we add a viewport.
-	start := 0
-	if len(entries) > budget {
-		start = len(entries) - budget
+	cur := c.current()
+	lines := c.renderEntry(entries[cur], true)
+	if len(lines) >= budget {
+		return lines[:budget]
 	}
-	var lines []string
-	for _, e := range entries[start:] {
-		lines = append(lines, c.renderEntry(e)...)
+	for i := cur - 1; i >= 0 && len(lines) < budget; i-- {
+		lines = append(c.renderEntry(entries[i], false), lines...)
 	}
-	return lines
+	for i := cur + 1; i < len(entries) && len(lines) < budget; i++ {
+		lines = append(lines, c.renderEntry(entries[i], false)...)
+		lines = lines[:min(len(lines), budget)]
+	}
+	return lines[max(len(lines)-budget, 0):]
 }
 
-func (c CodexTranscript) renderEntry(e CodexTranscriptEntry) []string {
+// maxDetailLines caps an expanded entry's detail; the full text stays in
+// the transcript state.
+const maxDetailLines = 200
+
+func (c CodexTranscript) renderEntry(e CodexTranscriptEntry, selected bool) []string {
 	ts := lipgloss.NewStyle().Foreground(ui.Dim).Render(e.At.Format("15:04:05"))
 	icon, fg := iconFor(e.Kind, e.Error)
 	titleStyle := lipgloss.NewStyle().Foreground(fg)
-	line := fmt.Sprintf("%s %s %s", ts, titleStyle.Render(icon), e.Title)
+	marker := " "
+	if selected {
+		marker = lipgloss.NewStyle().Foreground(ui.BrightGold).Render("›")
+	}
+	line := fmt.Sprintf("%s%s %s %s", marker, ts, titleStyle.Render(icon), e.Title)
+	detail := splitLines(e.Detail)
+	if len(detail) > 0 {
+		fold := fmt.Sprintf("  ▸ %d lines", len(detail))
+		if e.Expanded {
+			fold = "  ▾"
+		}
+		line += lipgloss.NewStyle().Foreground(ui.Dim).Render(fold)
+	}
 	out := []string{line}
 	if e.Body != "" {
 		bodyStyle := lipgloss.NewStyle().Foreground(ui.Light)
@@ -211,6 +378,15 @@ func (c CodexTranscript) renderEntry(e CodexTranscriptEntry) []string {
 			out = append(out, "    "+bodyStyle.Render(ln))
 		}
 	}
+	if e.Expanded && len(detail) > 0 {
+		detailStyle := lipgloss.NewStyle().Foreground(ui.Muted)
+		if len(detail) > maxDetailLines {
+			detail = append(detail[:maxDetailLines:maxDetailLines], fmt.Sprintf("… %d more lines", len(detail)-maxDetailLines))
+		}
+		for _, ln := range detail {
+			out = append(out, "    "+detailStyle.Render(strings.ReplaceAll(ln, "\t", "    ")))
+		}
+	}
 	return out
 }
 
@@ -223,6 +399,8 @@ func iconFor(kind string, isError bool) (string, color.Color) {
 		return "▶", ui.BrightGold
 	case "user":
 		return "◀", ui.Muted
+	case "reasoning":
+		return "∴", ui.Muted
 	case "exec":
 		return "$", ui.Light
 	case "tool":
@@ -231,22 +409,62 @@ func iconFor(kind string, isError bool) (string, color.Color) {
 		return "?", ui.Muted
 	case "patch":
 		return "±", ui.BrightGreen
+	case "plan":
+		return ui.SymStepActive, ui.BrightPurple
 	case "task":
 		return "─", ui.Dim
 	case "info":
 		return "·", ui.Dim
+	case "raw":
+		return "◇", ui.Dim
 	default:
 		return "·", ui.Dim
 	}
 }
 
+// streamingEvents are codex events mg deliberately drops: token-by-token
+// deltas and item wrappers that repeat what the complete events already
+// carry, and startup chatter.
+var streamingEvents = map[string]bool{
+	"agent_message_delta":               true,
+	"agent_message_content_delta":       true,
+	"agent_reasoning_delta":             true,
+	"agent_reasoning_raw_content":       true,
+	"agent_reasoning_raw_content_delta": true,
+	"agent_reasoning_section_break":     true,
+	"reasoning_content_delta":           true,
+	"reasoning_raw_content_delta":       true,
+	"exec_command_output_delta":         true,
+	"raw_response_item":                 true,
+	"item_started":                      true,
+	"item_completed":                    true,
+	"mcp_startup_update":                true,
+	"mcp_startup_complete":              true,
+}
+
 // AppendEvent converts a CodexEvent into a transcript entry, mutating state.
 // Returns true if the event was actually appended (i.e. it was one of the
-// display-relevant kinds). Unrecognized events are dropped — they remain
-// available via the raw stream for future expansion.
+// display-relevant kinds). Streaming deltas are dropped; any other event mg
+// doesn't know becomes a "raw" entry whose JSON can be expanded.
 func (s *CodexTranscriptState) AppendEvent(ev codexmcp.CodexEvent) bool {
 	now := time.Now()
-	switch ev.EventType() {
+	s.LastEventAt = now
+	evType := ev.EventType()
+	if streamingEvents[evType] {
+		return false
+	}
+	switch evType {
+	case "token_count":
+		// Usage is shown in the meta line and session list, not as an entry.
+		var tc codexmcp.TokenCountEvent
+		if json.Unmarshal(ev.Msg, &tc) == nil && tc.Info != nil {
+			s.Tokens = tc.Info.TotalTokenUsage.TotalTokens
+			s.ContextTokens = tc.Info.LastTokenUsage.TotalTokens
+			if tc.Info.ModelContextWindow > 0 {
+				s.ContextWindow = tc.Info.ModelContextWindow
+			}
+		}
+		return false
 	case "session_configured":
 		var sc codexmcp.SessionConfiguredEvent
 		_ = json.Unmarshal(ev.Msg, &sc)
@@ -264,6 +482,9 @@ func (s *CodexTranscriptState) AppendEvent(ev codexmcp.CodexEvent) bool {
 	case "task_started":
 		var ts codexmcp.TaskStartedEvent
 		_ = json.Unmarshal(ev.Msg, &ts)
+		if ts.ModelContextWindow > 0 {
+			s.ContextWindow = ts.ModelContextWindow
+		}
 		s.AppendEntry(CodexTranscriptEntry{
 			At:    now,
 			Kind:  "task",
@@ -289,6 +510,40 @@ func (s *CodexTranscriptState) AppendEvent(ev codexmcp.CodexEvent) bool {
 			Body:  remainder(am.Message),
 		})
 		return true
+	case "agent_reasoning":
+		var ar codexmcp.AgentReasoningEvent
+		_ = json.Unmarshal(ev.Msg, &ar)
+		s.AppendEntry(CodexTranscriptEntry{
+			At:     now,
+			Kind:   "reasoning",
+			Title:  strings.Trim(strings.TrimSpace(firstLine(ar.Text)), "*"),
+			Detail: strings.TrimSpace(remainder(strings.TrimSpace(ar.Text))),
+		})
+		return true
+	case "plan_update":
+		var pu codexmcp.PlanUpdateEvent
+		_ = json.Unmarshal(ev.Msg, &pu)
+		s.Plan = pu.Plan
+		done := 0
+		steps := make([]string, len(pu.Plan))
+		for i, it := range pu.Plan {
+			if it.Status == "completed" {
+				done++
+			}
+			sym, _ := planSymbol(it.Status)
+			steps[i] = sym + " " + it.Step
+		}
+		title := fmt.Sprintf("plan %d/%d", done, len(pu.Plan))
+		if ex := firstLine(pu.Explanation); ex != "" {
+			title += "  " + ex
+		}
+		s.AppendEntry(CodexTranscriptEntry{
+			At:     now,
+			Kind:   "plan",
+			Title:  title,
+			Detail: strings.Join(steps, "\n"),
+		})
+		return true
 	case "user_message":
 		var um codexmcp.UserMessageEvent
 		_ = json.Unmarshal(ev.Msg, &um)
@@ -311,10 +566,11 @@ func (s *CodexTranscriptState) AppendEvent(ev codexmcp.CodexEvent) bool {
 		var ec codexmcp.ExecCommandEndEvent
 		_ = json.Unmarshal(ev.Msg, &ec)
 		s.AppendEntry(CodexTranscriptEntry{
-			At:    now,
-			Kind:  "exec",
-			Title: fmt.Sprintf("exit %d", ec.ExitCode),
-			Error: ec.ExitCode != 0,
+			At:     now,
+			Kind:   "exec",
+			Title:  fmt.Sprintf("exit %d", ec.ExitCode),
+			Detail: strings.TrimRight(ec.Output(), "\n"),
+			Error:  ec.ExitCode != 0,
 		})
 		return true
 	case "mcp_tool_call_begin":
@@ -350,8 +606,98 @@ func (s *CodexTranscriptState) AppendEvent(ev codexmcp.CodexEvent) bool {
 			Error: true,
 		})
 		return true
+	case "background_event":
+		var bg codexmcp.BackgroundEvent
+		_ = json.Unmarshal(ev.Msg, &bg)
+		if bg.Message == "" {
+			return false
+		}
+		s.AppendEntry(CodexTranscriptEntry{
+			At:    now,
+			Kind:  "info",
+			Title: bg.Message,
+		})
+		return true
+	}
+	if evType == "" {
+		evType = "event"
 	}
-	return false
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, ev.Msg, "", "  ") != nil {
+		pretty.Reset()
+		pretty.Write(ev.Msg)
+	}
+	s.AppendEntry(CodexTranscriptEntry{
+		At:     now,
+		Kind:   "raw",
+		Title:  evType,
+		Detail: pretty.String(),
+	})
+	return true
+}
+
+// Summary renders the session as a plain-text comment for the issue: what
+// ran, how it ended and the final answer.
+func (s *CodexTranscriptState) Summary() string {
+	var b strings.Builder
+	b.WriteString("Codex session")
+	if s.ThreadID != "" {
+		b.WriteString(" " + s.ThreadID)
+	}
+	if s.Model != "" {
+		b.WriteString(" (" + s.Model + ")")
+	}
+	b.WriteString(": " + s.Status)
+	if !s.StartAt.IsZero() && !s.EndAt.IsZero() {
+		b.WriteString(", " + s.EndAt.Sub(s.StartAt).Truncate(time.Second).String())
+	}
+	if s.Tokens > 0 {
+		b.WriteString(", " + formatTokens(s.Tokens) + " tokens")
+	}
+	b.WriteString("\n")
+
+	var commands, failed, tools int
+	for _, e := range s.Entries {
+		switch {
+		case e.Kind == "exec" && strings.HasPrefix(e.Title, "exit "):
+			if e.Error {
+				failed++
+			}
+		case e.Kind == "exec":
+			commands++
+		case e.Kind == "tool" && !strings.HasPrefix(e.Title, "tool "):
+			tools++
+		}
+	}
+	fmt.Fprintf(&b, "%d commands (%d failed), %d tool calls\n", commands, failed, tools)
+
+	final := s.Final
+	if final == "" {
+		for i := len(s.Entries) - 1; i >= 0; i-- {
+			if e := s.Entries[i]; e.Kind == "agent" && !e.Error {
+				final = strings.TrimSpace(e.Title + "\n" + e.Body)
+				break
+			}
+		}
+	}
+	if final != "" {
+		if len(final) > 2000 {
+			final = final[:2000] + "…"
+		}
+		b.WriteString("\n" + final + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatTokens abbreviates a token count: 950, 12.3k, 1.2M.
+func formatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	}
+	return fmt.Sprintf("%d", n)
 }
 
 // firstLine returns the first non-empty line of s.
@@ -364,6 +710,14 @@ func firstLine(s string) string {
 	return ""
 }
 
+// splitLines splits s into lines, returning nil for an empty string.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
 // remainder returns everything after the first line of s, or "" if there
 // is only one line.
 func remainder(s string) string {
@@ -5,7 +5,9 @@ import (
 	"maps"
 	"strings"
 	"testing"
+	"time"
 
+	tea "charm.land/bubbletea/v2"
 	"github.com/matt-wright86/mardi-gras/internal/codexmcp"
 )
 
@@ -95,6 +97,25 @@ func TestAppendEventUnknownTypeIsDropped(t *testing.T) {
 	}
 }
 
+func TestAppendEventTokenCountTracksUsage(t *testing.T) {
+	state := &CodexTranscriptState{}
+	if state.AppendEvent(mkEvent("token_count", map[string]any{"info": nil})) {
+		t.Fatal("token_count should not add an entry")
+	}
+	if state.LastEventAt.IsZero() {
+		t.Fatal("LastEventAt should track every event")
+	}
+	state.AppendEvent(mkEvent("token_count", map[string]any{
+		"info": map[string]any{"total_token_usage": map[string]int{"total_tokens": 12345}},
+	}))
+	if state.Tokens != 12345 || len(state.Entries) != 0 {
+		t.Fatalf("tokens = %d, entries = %d", state.Tokens, len(state.Entries))
+	}
+	if got := formatTokens(state.Tokens); got != "12.3k" {
+		t.Errorf("formatTokens = %q", got)
+	}
+}
+
 func TestViewWithoutStateShowsPlaceholder(t *testing.T) {
 	v := NewCodexTranscript(80, 24)
 	out := v.View()
@@ -121,3 +142,174 @@ func TestViewWithEntriesIncludesTitles(t *testing.T) {
 		t.Fatalf("missing agent message: %q", out)
 	}
 }
+
+func TestSummaryCountsWorkAndEndsWithFinal(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	state := &CodexTranscriptState{
+		ThreadID: "thr-1", Model: "gpt-5", Status: "done",
+		StartAt: start, EndAt: start.Add(90 * time.Second), Tokens: 12345,
+	}
+	_ = state.AppendEvent(mkEvent("exec_command_begin", map[string]any{"call_id": "c1", "command": []string{"go", "test"}}))
+	_ = state.AppendEvent(mkEvent("exec_command_end", map[string]any{"call_id": "c1", "exit_code": 1}))
+	_ = state.AppendEvent(mkEvent("mcp_tool_call_begin", map[string]any{"invocation": map[string]string{"server": "gh", "tool": "search"}}))
+	_ = state.AppendEvent(mkEvent("mcp_tool_call_end", map[string]any{}))
+	_ = state.AppendEvent(mkEvent("agent_message", map[string]string{"message": "Fixed the flaky test."}))
+
+	got := state.Summary()
+	for _, want := range []string{"Codex session thr-1 (gpt-5): done, 1m30s, 12.3k tokens", "1 commands (1 failed), 1 tool calls", "Fixed the flaky test."} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q:\n%s", want, got)
+		}
+	}
+
+	state.Final = "Final answer."
+	if got := state.Summary(); !strings.HasSuffix(got, "Final answer.") || strings.Contains(got, "flaky") {
+		t.Errorf("Final should replace the last agent message:\n%s", got)
+	}
+}
+
+func TestAppendEventExecEndFoldsOutput(t *testing.T) {
+	state := &CodexTranscriptState{}
+	state.AppendEvent(mkEvent("exec_command_end", map[string]any{
+		"call_id": "c1", "exit_code": 0, "stdout": "ok\n", "stderr": "warn\n",
+	}))
+	state.AppendEvent(mkEvent("exec_command_end", map[string]any{
+		"call_id": "c2", "exit_code": 0, "stdout": "a\n", "stderr": "b\n", "aggregated_output": "b\na\n",
+	}))
+	if got := state.Entries[0].Detail; got != "ok\nwarn" {
+		t.Errorf("stdout+stderr detail = %q", got)
+	}
+	if got := state.Entries[1].Detail; got != "b\na" {
+		t.Errorf("aggregated detail = %q", got)
+	}
+	if state.Entries[0].Title != "exit 0" {
+		t.Errorf("title = %q", state.Entries[0].Title)
+	}
+}
+
+func TestAppendEventReasoningAndPlan(t *testing.T) {
+	state := &CodexTranscriptState{}
+	state.AppendEvent(mkEvent("agent_reasoning", map[string]string{
+		"text": "**Inspecting the parser**\n\nThe tokenizer drops trailing commas.",
+	}))
+	state.AppendEvent(mkEvent("plan_update", map[string]any{
+		"explanation": "Fix then test",
+		"plan": []map[string]string{
+			{"step": "Fix tokenizer", "status": "completed"},
+			{"step": "Add test", "status": "in_progress"},
+			{"step": "Update docs", "status": "pending"},
+		},
+	}))
+	r := state.Entries[0]
+	if r.Kind != "reasoning" || r.Title != "Inspecting the parser" || r.Detail != "The tokenizer drops trailing commas." {
+		t.Errorf("reasoning entry = %+v", r)
+	}
+	p := state.Entries[1]
+	if p.Kind != "plan" || p.Title != "plan 1/3  Fix then test" {
+		t.Errorf("plan entry = %+v", p)
+	}
+	if !strings.Contains(p.Detail, "✓ Fix tokenizer") || !strings.Contains(p.Detail, "○ Update docs") {
+		t.Errorf("plan detail = %q", p.Detail)
+	}
+	if len(state.Plan) != 3 {
+		t.Fatalf("plan = %+v", state.Plan)
+	}
+
+	v := NewCodexTranscript(80, 30)
+	v.SetState(state)
+	out := v.View()
+	for _, want := range []string{"plan 1/3", "● Add test", "○ Update docs"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("view missing pinned plan %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestAppendEventUnknownBecomesRawEntry(t *testing.T) {
+	state := &CodexTranscriptState{}
+	if !state.AppendEvent(mkEvent("turn_diff", map[string]string{"unified_diff": "--- a\n+++ b"})) {
+		t.Fatal("unknown event should be kept for inspection")
+	}
+	e := state.Entries[0]
+	if e.Kind != "raw" || e.Title != "turn_diff" {
+		t.Fatalf("raw entry = %+v", e)
+	}
+	if !strings.Contains(e.Detail, "\n  \"unified_diff\"") {
+		t.Errorf("detail should be indented JSON: %q", e.Detail)
+	}
+	for _, noise := range []string{"agent_message_delta", "exec_command_output_delta", "raw_response_item"} {
+		if state.AppendEvent(mkEvent(noise, nil)) {
+			t.Errorf("%s should be dropped", noise)
+		}
+	}
+}
+
+func TestAppendEventBackgroundEventAndAgentHeader(t *testing.T) {
+	state := &CodexTranscriptState{Agent: "Helper", Status: "running"}
+	if !state.AppendEvent(mkEvent("background_event", map[string]string{"message": "[info] reading files"})) {
+		t.Fatal("background_event should be kept")
+	}
+	if state.AppendEvent(mkEvent("background_event", nil)) {
+		t.Error("empty background_event should be dropped")
+	}
+	if e := state.Entries[0]; e.Kind != "info" || e.Title != "[info] reading files" {
+		t.Fatalf("entry = %+v", e)
+	}
+	v := NewCodexTranscript(80, 20)
+	v.SetState(state)
+	if out := v.View(); !strings.Contains(out, "HELPER (MCP)") {
+		t.Errorf("header should name the agent:\n%s", out)
+	}
+}
+
+func TestAppendEventTracksContextWindow(t *testing.T) {
+	state := &CodexTranscriptState{}
+	state.AppendEvent(mkEvent("task_started", map[string]any{"turn_id": "1", "model_context_window": 200000}))
+	if _, ok := state.ContextPercent(); ok {
+		t.Fatal("no usage yet")
+	}
+	state.AppendEvent(mkEvent("token_count", map[string]any{
+		"info": map[string]any{
+			"total_token_usage": map[string]int{"total_tokens": 90000},
+			"last_token_usage":  map[string]int{"total_tokens": 50000},
+		},
+	}))
+	if pct, ok := state.ContextPercent(); !ok || pct != 25 {
+		t.Fatalf("context = %d%% (%v)", pct, ok)
+	}
+	v := NewCodexTranscript(100, 24)
+	v.SetState(state)
+	if out := v.View(); !strings.Contains(out, "context 25% of 200.0k") {
+		t.Errorf("meta line missing context usage:\n%s", out)
+	}
+}
+
+func TestTranscriptCursorExpandsEntry(t *testing.T) {
+	state := &CodexTranscriptState{IssueID: "bd-1", Status: "running"}
+	state.AppendEvent(mkEvent("exec_command_end", map[string]any{"exit_code": 1, "aggregated_output": "FAIL TestParse\nexit status 1"}))
+	state.AppendEvent(mkEvent("agent_message", map[string]string{"message": "looking"}))
+
+	v := NewCodexTranscript(80, 24)
+	v.SetState(state)
+	if out := v.View(); !strings.Contains(out, "▸ 2 lines") || strings.Contains(out, "FAIL TestParse") {
+		t.Fatalf("output should start folded:\n%s", out)
+	}
+
+	v, _ = v.Update(tea.KeyPressMsg{Code: 'k', Text: "k"})
+	if v.Following() {
+		t.Fatal("k should leave follow mode")
+	}
+	v, _ = v.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if !state.Entries[0].Expanded {
+		t.Fatal("enter should expand the selected entry")
+	}
+	if out := v.View(); !strings.Contains(out, "FAIL TestParse") {
+		t.Fatalf("expanded output missing:\n%s", out)
+	}
+
+	v, _ = v.Update(tea.KeyPressMsg{Code: 'G', Text: "G"})
+	v, _ = v.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if !v.Following() || state.Entries[1].Expanded {
+		t.Fatal("G should follow again; entries without detail don't expand")
+	}
+}
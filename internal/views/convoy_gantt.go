@@ -0,0 +1,399 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+	"github.com/matt-wright86/mardi-gras/internal/ui"
+)
+
+// ganttLabelWidth caps the member label column (symbol, id, title) so the
+// time axis keeps most of the width.
+const ganttLabelWidth = 30
+
+// ConvoyGantt draws a convoy's members as bars on a shared time axis:
+// waiting from creation to start, worked from start to close (or now), and
+// projected forward at the convoy's close rate. Blocked members and members
+// whose worker is idle are flagged so a stalled convoy shows where it is
+// stuck. It is shown in place of the Gas Town panel.
+type ConvoyGantt struct {
+	width  int
+	height int
+
+	convoyID string
+	title    string
+	timeline *gastown.ConvoyTimeline
+	err      string
+	loading  bool
+
+	cursor int
+	offset int
+}
+
+// NewConvoyGantt constructs an empty convoy timeline overlay.
+func NewConvoyGantt(width, height int) ConvoyGantt {
+	return ConvoyGantt{width: width, height: height}
+}
+
+// SetSize updates dimensions.
+func (g *ConvoyGantt) SetSize(width, height int) {
+	g.width = width
+	g.height = height
+	g.scrollToCursor()
+}
+
+// Open resets the overlay for a convoy. The timeline arrives later via
+// SetTimeline once the app has fetched the convoy's current members.
+func (g *ConvoyGantt) Open(c gastown.ConvoyDetail) {
+	*g = ConvoyGantt{width: g.width, height: g.height, convoyID: c.ID, title: c.Title, loading: true}
+}
+
+// ConvoyID returns the id of the convoy shown.
+func (g *ConvoyGantt) ConvoyID() string { return g.convoyID }
+
+// SetTimeline swaps in a freshly built timeline, keeping the cursor.
+func (g *ConvoyGantt) SetTimeline(tl gastown.ConvoyTimeline) {
+	g.loading = false
+	g.err = ""
+	g.timeline = &tl
+	if tl.Convoy.Title != "" {
+		g.title = tl.Convoy.Title
+	}
+	g.cursor = min(g.cursor, max(len(tl.Members)-1, 0))
+	g.scrollToCursor()
+}
+
+// SetError records a failed fetch. A previously built timeline stays on
+// screen so a transient error does not blank the overlay.
+func (g *ConvoyGantt) SetError(err error) {
+	g.loading = false
+	if err != nil {
+		g.err = err.Error()
+	}
+}
+
+// Selected returns the member under the cursor, or nil.
+func (g *ConvoyGantt) Selected() *gastown.TimelineMember {
+	if g.timeline == nil || g.cursor >= len(g.timeline.Members) {
+		return nil
+	}
+	return &g.timeline.Members[g.cursor]
+}
+
+// Update handles member navigation.
+func (g ConvoyGantt) Update(msg tea.Msg) (ConvoyGantt, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return g, nil
+	}
+	n := g.memberCount()
+	switch keyMsg.String() {
+	case "j", "down":
+		g.moveCursor(1)
+	case "k", "up":
+		g.moveCursor(-1)
+	case "g":
+		g.moveCursor(-n)
+	case "G":
+		g.moveCursor(n)
+	}
+	return g, nil
+}
+
+func (g *ConvoyGantt) memberCount() int {
+	if g.timeline == nil {
+		return 0
+	}
+	return len(g.timeline.Members)
+}
+
+func (g *ConvoyGantt) moveCursor(delta int) {
+	g.cursor = max(min(g.cursor+delta, g.memberCount()-1), 0)
+	g.scrollToCursor()
+}
+
+func (g *ConvoyGantt) scrollToCursor() {
+	body := g.bodyHeight()
+	if g.cursor < g.offset {
+		g.offset = g.cursor
+	}
+	if g.cursor >= g.offset+body {
+		g.offset = g.cursor - body + 1
+	}
+	g.offset = max(min(g.offset, g.memberCount()-body), 0)
+}
+
+// bodyHeight is the number of member rows that fit between the header
+// (title, summary, blank, axis dates, axis rule) and the footer (blank,
+// cursor detail, hints).
+func (g *ConvoyGantt) bodyHeight() int {
+	return max(g.height-8, 1)
+}
+
+// labelWidth and barWidth split the inner width between the member label
+// column and the time axis.
+func (g ConvoyGantt) labelWidth() int {
+	return min(ganttLabelWidth, max((g.width-4)/3, 12))
+}
+
+func (g ConvoyGantt) barWidth() int {
+	// cursor (2) + label + flag column (3) + border/padding (4)
+	return max(g.width-4-2-g.labelWidth()-3, 10)
+}
+
+// View renders the overlay inside ui.DetailBorder.
+func (g ConvoyGantt) View() string {
+	header := lipgloss.NewStyle().Bold(true).Foreground(ui.BrightGold).Render("CONVOY TIMELINE")
+	name := lipgloss.NewStyle().Foreground(ui.Light).Render(g.title)
+	if g.convoyID != "" {
+		name += lipgloss.NewStyle().Foreground(ui.Dim).Render("  " + g.convoyID)
+	}
+	out := []string{header + "  " + name, g.summaryLine(), ""}
+
+	dim := lipgloss.NewStyle().Foreground(ui.Dim)
+	body := g.bodyHeight() + 2 // axis rows, when there is an axis
+	switch {
+	case g.timeline == nil && g.err != "":
+		out = append(out, lipgloss.NewStyle().Foreground(ui.StatusStalled).Render(ui.SymStalled+" "+g.err))
+		body--
+	case g.timeline == nil:
+		out = append(out, dim.Render("loading convoy..."))
+		body--
+	case len(g.timeline.Members) == 0:
+		out = append(out, dim.Render("This convoy tracks no issues."))
+		body--
+	default:
+		out = append(out, g.axisDates(), g.axisRule())
+		body -= 2
+		end := min(g.offset+body, len(g.timeline.Members))
+		for i := g.offset; i < end; i++ {
+			out = append(out, g.renderMember(g.timeline.Members[i], i == g.cursor))
+		}
+		body -= end - g.offset
+	}
+	for ; body > 0; body-- {
+		out = append(out, "")
+	}
+
+	out = append(out, "", g.detailLine(),
+		dim.Render("  j/k move  R refresh  esc back    · waiting  █ worked  ░ projected  ┃ now"))
+
+	return ui.DetailBorder.Width(g.width).Height(g.height).Render(strings.Join(out, "\n"))
+}
+
+// summaryLine counts progress, the projected completion, and how many
+// members are blocked or held by an idle worker.
+func (g ConvoyGantt) summaryLine() string {
+	dim := lipgloss.NewStyle().Foreground(ui.Dim)
+	if g.timeline == nil {
+		return ""
+	}
+	var done, blocked, idle int
+	for _, m := range g.timeline.Members {
+		switch {
+		case m.Done():
+			done++
+		case m.IsBlocked():
+			blocked++
+		}
+		if m.WorkerIdle != "" {
+			idle++
+		}
+	}
+	parts := []string{dim.Render(fmt.Sprintf("%d/%d done", done, len(g.timeline.Members)))}
+	if eta := g.timeline.ETALabel(); eta != "" {
+		parts = append(parts, dim.Render("ETA ~"+eta))
+	}
+	if blocked > 0 {
+		parts = append(parts, lipgloss.NewStyle().Foreground(ui.StatusStalled).Render(fmt.Sprintf("%d blocked", blocked)))
+	}
+	if idle > 0 {
+		parts = append(parts, lipgloss.NewStyle().Foreground(ui.BrightGold).Render(fmt.Sprintf("%d idle worker(s)", idle)))
+	}
+	if g.err != "" {
+		parts = append(parts, lipgloss.NewStyle().Foreground(ui.StatusStalled).Render(ui.SymStalled+" refresh failed"))
+	}
+	return strings.Join(parts, dim.Render(" · "))
+}
+
+// column maps t onto the bar area, clamped to its ends.
+func (g ConvoyGantt) column(t time.Time) int {
+	tl := g.timeline
+	span := tl.End.Sub(tl.Start)
+	w := g.barWidth()
+	if span <= 0 {
+		return w - 1
+	}
+	col := int(float64(t.Sub(tl.Start)) / float64(span) * float64(w-1))
+	return max(min(col, w-1), 0)
+}
+
+// axisDates labels the axis with its first and last dates.
+func (g ConvoyGantt) axisDates() string {
+	tl := g.timeline
+	left := tl.Start.Local().Format("Jan 02")
+	right := tl.End.Local().Format("Jan 02")
+	gap := max(g.barWidth()-len(left)-len(right), 1)
+	pad := strings.Repeat(" ", 2+g.labelWidth()+3)
+	return lipgloss.NewStyle().Foreground(ui.Dim).Render(pad + left + strings.Repeat(" ", gap) + right)
+}
+
+// axisRule draws the axis with a marker at now.
+func (g ConvoyGantt) axisRule() string {
+	w := g.barWidth()
+	now := g.column(g.timeline.Now)
+	pad := strings.Repeat(" ", 2+g.labelWidth()+3)
+	rule := lipgloss.NewStyle().Foreground(ui.Dim)
+	return pad + rule.Render(strings.Repeat("─", now)) +
+		lipgloss.NewStyle().Foreground(ui.BrightGold).Render("┃") +
+		rule.Render(strings.Repeat("─", w-now-1))
+}
+
+// memberStyle picks a member's status symbol and color, matching the
+// expanded convoy list in the Gas Town panel.
+func memberStyle(m gastown.TimelineMember) (string, lipgloss.Style) {
+	switch {
+	case m.Done():
+		return ui.SymResolved, lipgloss.NewStyle().Foreground(ui.BrightGreen)
+	case m.IsBlocked():
+		return ui.SymStalled, lipgloss.NewStyle().Foreground(ui.StatusStalled)
+	case m.Active():
+		return ui.SymWorking, lipgloss.NewStyle().Foreground(ui.BrightGold)
+	default:
+		return ui.SymLinedUp, lipgloss.NewStyle().Foreground(ui.Muted)
+	}
+}
+
+func (g ConvoyGantt) renderMember(m gastown.TimelineMember, current bool) string {
+	sym, style := memberStyle(m)
+	cursor := "  "
+	if current {
+		cursor = lipgloss.NewStyle().Foreground(ui.BrightGold).Render("▸ ")
+	}
+	labelW := g.labelWidth()
+	label := truncate(m.ID+" "+m.Title, labelW-2)
+	label += strings.Repeat(" ", max(labelW-2-lipgloss.Width(label), 0))
+
+	flag := " "
+	switch {
+	case m.IsBlocked():
+		flag = lipgloss.NewStyle().Foreground(ui.StatusStalled).Render(ui.SymStalled)
+	case m.WorkerIdle != "":
+		flag = lipgloss.NewStyle().Foreground(ui.BrightGold).Render(ui.SymBackoff)
+	}
+
+	line := cursor + style.Render(sym) + " " +
+		lipgloss.NewStyle().Foreground(ui.Light).Bold(current).Render(label) +
+		" " + flag + " " + g.renderBar(m, style)
+	return ansi.Truncate(line, max(g.width-4, 10), "")
+}
+
+// renderBar draws one member's row of the time axis. Members with no local
+// history and no projection get a note instead of an empty row.
+func (g ConvoyGantt) renderBar(m gastown.TimelineMember, worked lipgloss.Style) string {
+	w := g.barWidth()
+	if m.Created.IsZero() && m.ProjectedEnd.IsZero() {
+		return lipgloss.NewStyle().Foreground(ui.Dim).Render("no local history")
+	}
+
+	cells := make([]rune, w)
+	for i := range cells {
+		cells[i] = ' '
+	}
+	fill := func(from, to time.Time, r rune) {
+		if from.IsZero() || to.IsZero() {
+			return
+		}
+		for c := g.column(from); c <= g.column(to); c++ {
+			cells[c] = r
+		}
+	}
+	// Waiting: created until started, or for unstarted work until closed,
+	// its projected start, or now.
+	waitEnd := m.Started
+	switch {
+	case !waitEnd.IsZero():
+	case !m.Closed.IsZero():
+		waitEnd = m.Closed
+	case !m.ProjectedStart.IsZero():
+		waitEnd = m.ProjectedStart
+	case !m.Done():
+		waitEnd = g.timeline.Now
+	}
+	fill(m.Created, waitEnd, '·')
+	workEnd := m.Closed
+	if workEnd.IsZero() && !m.Started.IsZero() && !m.Done() {
+		workEnd = g.timeline.Now
+	}
+	fill(m.Started, workEnd, '█')
+	fill(m.ProjectedStart, m.ProjectedEnd, '░')
+
+	// Render runs of the same rune with one style each.
+	waitStyle := lipgloss.NewStyle().Foreground(ui.Dim)
+	projStyle := lipgloss.NewStyle().Foreground(ui.Muted)
+	if m.IsBlocked() {
+		projStyle = lipgloss.NewStyle().Foreground(ui.StatusStalled)
+	}
+	var b strings.Builder
+	for i := 0; i < len(cells); {
+		j := i
+		for j < len(cells) && cells[j] == cells[i] {
+			j++
+		}
+		run := string(cells[i:j])
+		switch cells[i] {
+		case '·':
+			b.WriteString(waitStyle.Render(run))
+		case '█':
+			b.WriteString(worked.Render(run))
+		case '░':
+			b.WriteString(projStyle.Render(run))
+		default:
+			b.WriteString(run)
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// detailLine spells out the cursor member's dates, worker, and blockers.
+func (g ConvoyGantt) detailLine() string {
+	m := g.Selected()
+	if m == nil {
+		return ""
+	}
+	dim := lipgloss.NewStyle().Foreground(ui.Dim)
+	day := func(t time.Time) string { return t.Local().Format("Jan 02 15:04") }
+	parts := []string{lipgloss.NewStyle().Foreground(ui.Light).Render(m.ID)}
+	if !m.Created.IsZero() {
+		parts = append(parts, dim.Render("created "+day(m.Created)))
+	}
+	if !m.Started.IsZero() {
+		parts = append(parts, dim.Render("started "+day(m.Started)))
+	}
+	if !m.Closed.IsZero() {
+		parts = append(parts, dim.Render("closed "+day(m.Closed)))
+	} else if !m.ProjectedEnd.IsZero() {
+		parts = append(parts, dim.Render("projected "+day(m.ProjectedEnd)))
+	}
+	if m.Worker != "" {
+		worker := "worker " + m.Worker
+		if m.WorkerIdle != "" {
+			parts = append(parts, lipgloss.NewStyle().Foreground(ui.BrightGold).Render(worker+" ("+m.WorkerIdle+")"))
+		} else {
+			parts = append(parts, dim.Render(worker))
+		}
+	}
+	if len(m.BlockedBy) > 0 {
+		parts = append(parts, lipgloss.NewStyle().Foreground(ui.StatusStalled).Render(
+			ui.SymStalled+" blocked by "+strings.Join(m.BlockedBy, ", ")))
+	} else if m.IsBlocked() {
+		parts = append(parts, lipgloss.NewStyle().Foreground(ui.StatusStalled).Render(ui.SymStalled+" blocked"))
+	}
+	return ansi.Truncate("  "+strings.Join(parts, "  "), max(g.width-4, 10), "")
+}
@@ -0,0 +1,67 @@
+package views
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+)
+
+func ganttTimeline() gastown.ConvoyTimeline {
+	now := time.Date(2026, 6, 10, 12, 0, 0, 0, time.UTC)
+	start := now.Add(-4 * 24 * time.Hour)
+	return gastown.ConvoyTimeline{
+		Convoy: gastown.ConvoyDetail{ID: "cv-1", Title: "Sprint"},
+		Start:  start,
+		End:    now.Add(4 * 24 * time.Hour),
+		Now:    now,
+		ETA:    now.Add(4 * 24 * time.Hour),
+		Members: []gastown.TimelineMember{
+			{TrackedIssueInfo: gastown.TrackedIssueInfo{ID: "mg-1", Title: "Done thing", Status: "closed"},
+				Known: true, Created: start, Started: start.Add(24 * time.Hour), Closed: start.Add(48 * time.Hour)},
+			{TrackedIssueInfo: gastown.TrackedIssueInfo{ID: "mg-2", Title: "Active thing", Status: "in_progress", Worker: "atlas"},
+				Known: true, Created: start, Started: now.Add(-24 * time.Hour), WorkerIdle: "idle",
+				ProjectedStart: now, ProjectedEnd: now.Add(2 * 24 * time.Hour)},
+			{TrackedIssueInfo: gastown.TrackedIssueInfo{ID: "mg-3", Title: "Blocked thing", Status: "open"},
+				Known: true, Created: start, BlockedBy: []string{"mg-2"},
+				ProjectedStart: now.Add(2 * 24 * time.Hour), ProjectedEnd: now.Add(4 * 24 * time.Hour)},
+		},
+	}
+}
+
+func TestConvoyGanttView(t *testing.T) {
+	g := NewConvoyGantt(100, 20)
+	g.Open(gastown.ConvoyDetail{ID: "cv-1"})
+	if view := ansi.Strip(g.View()); !strings.Contains(view, "loading convoy") {
+		t.Fatalf("opened overlay should show loading:\n%s", view)
+	}
+
+	g.SetTimeline(ganttTimeline())
+	view := ansi.Strip(g.View())
+	for _, want := range []string{"CONVOY TIMELINE", "Sprint", "1/3 done", "ETA ~4.0d", "1 blocked", "1 idle worker(s)", "┃", "█", "░"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestConvoyGanttCursorDetail(t *testing.T) {
+	g := NewConvoyGantt(120, 20)
+	g.Open(gastown.ConvoyDetail{ID: "cv-1"})
+	g.SetTimeline(ganttTimeline())
+
+	g, _ = g.Update(transcriptKey("G"))
+	if m := g.Selected(); m == nil || m.ID != "mg-3" {
+		t.Fatalf("G should select the last member, got %+v", m)
+	}
+	if view := ansi.Strip(g.View()); !strings.Contains(view, "blocked by mg-2") {
+		t.Errorf("cursor detail should name the blocker:\n%s", view)
+	}
+
+	g, _ = g.Update(transcriptKey("k"))
+	if view := ansi.Strip(g.View()); !strings.Contains(view, "worker atlas (idle)") {
+		t.Errorf("cursor detail should flag the idle worker:\n%s", view)
+	}
+}
@@ -0,0 +1,378 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/matt-wright86/mardi-gras/internal/data"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+	"github.com/matt-wright86/mardi-gras/internal/ui"
+)
+
+// ConvoyPlanMsg is emitted when the planner's member list is confirmed.
+// Exactly one of Name (create a new convoy) and ConvoyID (add to an existing
+// one) is set.
+type ConvoyPlanMsg struct {
+	Name     string
+	ConvoyID string
+	IssueIDs []string
+}
+
+// plannerInput is which prompt, if any, owns the planner's input line.
+type plannerInput int
+
+const (
+	plannerBrowse plannerInput = iota
+	plannerSource              // editing the query / root ids
+	plannerName                // naming a new convoy
+	plannerTarget              // picking an existing convoy to add to
+)
+
+// ConvoyPlanner proposes convoy members from a filter query or root issues,
+// expanded to their transitive blocking closure, and lets the user prune the
+// list before creating a convoy or adding to an existing one. It is shown in
+// place of the detail pane.
+type ConvoyPlanner struct {
+	width  int
+	height int
+
+	issues        []data.Issue
+	blockingTypes map[string]bool
+	convoys       []gastown.ConvoyDetail
+
+	source  string
+	rooted  bool
+	members []data.PlanMember
+	pruned  map[string]bool
+
+	cursor int
+	offset int
+
+	input     plannerInput
+	textInput textinput.Model
+}
+
+// NewConvoyPlanner constructs an empty planner.
+func NewConvoyPlanner(width, height int) ConvoyPlanner {
+	return ConvoyPlanner{width: width, height: height, pruned: make(map[string]bool)}
+}
+
+// SetSize updates dimensions.
+func (p *ConvoyPlanner) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+	p.scrollToCursor()
+}
+
+// Open resets the planner and computes a plan for source. convoys are the
+// known convoys offered as targets when adding to an existing one. With no
+// source the source prompt opens straight away.
+func (p *ConvoyPlanner) Open(issues []data.Issue, blockingTypes map[string]bool, source string, convoys []gastown.ConvoyDetail) tea.Cmd {
+	*p = ConvoyPlanner{
+		width:         p.width,
+		height:        p.height,
+		issues:        issues,
+		blockingTypes: blockingTypes,
+		convoys:       convoys,
+		pruned:        make(map[string]bool),
+	}
+	p.plan(source)
+	if p.source == "" {
+		return p.startInput(plannerSource)
+	}
+	return nil
+}
+
+// SetIssues replans against a fresh issue list, keeping the source and any
+// pruning so a background reload does not undo the user's edits.
+func (p *ConvoyPlanner) SetIssues(issues []data.Issue) {
+	p.issues = issues
+	pruned := p.pruned
+	p.plan(p.source)
+	p.pruned = pruned
+}
+
+// SetConvoys updates the convoys offered when adding to an existing one.
+func (p *ConvoyPlanner) SetConvoys(convoys []gastown.ConvoyDetail) {
+	p.convoys = convoys
+}
+
+// Editing reports whether a prompt has focus; the app must then route every
+// key here so printable keys reach the input.
+func (p *ConvoyPlanner) Editing() bool { return p.input != plannerBrowse }
+
+// Members returns the full proposal, pruned members included.
+func (p *ConvoyPlanner) Members() []data.PlanMember { return p.members }
+
+// Selected returns the ids of the members that are not pruned, in plan order.
+func (p *ConvoyPlanner) Selected() []string {
+	var ids []string
+	for _, m := range p.members {
+		if !p.pruned[m.Issue.ID] {
+			ids = append(ids, m.Issue.ID)
+		}
+	}
+	return ids
+}
+
+func (p *ConvoyPlanner) plan(source string) {
+	p.source = strings.TrimSpace(source)
+	seeds, rooted := data.PlanSeeds(p.issues, p.source)
+	p.rooted = rooted
+	p.members = data.PlanConvoy(p.issues, seeds, p.blockingTypes)
+	p.pruned = make(map[string]bool)
+	p.cursor = min(p.cursor, max(len(p.members)-1, 0))
+	p.scrollToCursor()
+}
+
+// Update handles navigation, pruning, and the source/name/target prompts.
+func (p ConvoyPlanner) Update(msg tea.Msg) (ConvoyPlanner, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyPressMsg)
+	if p.input != plannerBrowse {
+		if ok {
+			switch keyMsg.String() {
+			case "esc":
+				p.input = plannerBrowse
+				p.textInput.Blur()
+				return p, nil
+			case "enter":
+				return p.submitInput()
+			}
+		}
+		var cmd tea.Cmd
+		p.textInput, cmd = p.textInput.Update(msg)
+		return p, cmd
+	}
+	if !ok {
+		return p, nil
+	}
+
+	switch keyMsg.String() {
+	case "j", "down":
+		p.moveCursor(1)
+	case "k", "up":
+		p.moveCursor(-1)
+	case "g":
+		p.moveCursor(-len(p.members))
+	case "G":
+		p.moveCursor(len(p.members))
+	case "space", "x":
+		if p.cursor < len(p.members) {
+			id := p.members[p.cursor].Issue.ID
+			p.pruned[id] = !p.pruned[id]
+			p.moveCursor(1)
+		}
+	case "X":
+		// Toggle everything: prune all when anything is included, else restore.
+		pruneAll := len(p.Selected()) > 0
+		for _, m := range p.members {
+			p.pruned[m.Issue.ID] = pruneAll
+		}
+	case "/":
+		return p, p.startInput(plannerSource)
+	case "enter", "c":
+		if len(p.Selected()) == 0 {
+			return p, nil
+		}
+		return p, p.startInput(plannerName)
+	case "+":
+		if len(p.Selected()) == 0 {
+			return p, nil
+		}
+		return p, p.startInput(plannerTarget)
+	}
+	return p, nil
+}
+
+func (p *ConvoyPlanner) startInput(mode plannerInput) tea.Cmd {
+	p.input = mode
+	p.textInput = textinput.New()
+	p.textInput.SetWidth(max(p.width-12, 10))
+	switch mode {
+	case plannerSource:
+		p.textInput.Prompt = ui.InputPrompt.Render("plan> ")
+		p.textInput.Placeholder = "issue id(s) or filter query (type:bug p1 ...)"
+		p.textInput.SetValue(p.source)
+	case plannerName:
+		p.textInput.Prompt = ui.InputPrompt.Render("convoy> ")
+		p.textInput.Placeholder = fmt.Sprintf("Name for convoy (%d issues)...", len(p.Selected()))
+	case plannerTarget:
+		p.textInput.Prompt = ui.InputPrompt.Render("add to> ")
+		p.textInput.Placeholder = "convoy id (tab completes)"
+		var ids []string
+		for _, c := range p.convoys {
+			if c.Status != "closed" {
+				ids = append(ids, c.ID)
+			}
+		}
+		p.textInput.ShowSuggestions = len(ids) > 0
+		p.textInput.SetSuggestions(ids)
+	}
+	p.textInput.Focus()
+	return textinput.Blink
+}
+
+func (p ConvoyPlanner) submitInput() (ConvoyPlanner, tea.Cmd) {
+	mode := p.input
+	value := strings.TrimSpace(p.textInput.Value())
+	p.input = plannerBrowse
+	p.textInput.Blur()
+
+	switch mode {
+	case plannerSource:
+		p.plan(value)
+		return p, nil
+	case plannerName, plannerTarget:
+		ids := p.Selected()
+		if value == "" || len(ids) == 0 {
+			return p, nil
+		}
+		msg := ConvoyPlanMsg{Name: value, IssueIDs: ids}
+		if mode == plannerTarget {
+			msg = ConvoyPlanMsg{ConvoyID: value, IssueIDs: ids}
+		}
+		return p, func() tea.Msg { return msg }
+	}
+	return p, nil
+}
+
+func (p *ConvoyPlanner) moveCursor(delta int) {
+	p.cursor = max(min(p.cursor+delta, len(p.members)-1), 0)
+	p.scrollToCursor()
+}
+
+func (p *ConvoyPlanner) scrollToCursor() {
+	body := p.bodyHeight()
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+body {
+		p.offset = p.cursor - body + 1
+	}
+	p.offset = max(min(p.offset, len(p.members)-body), 0)
+}
+
+// bodyHeight is the number of member rows that fit between the header
+// (title, source, summary, blank) and the footer (blank, prompt/hints).
+func (p *ConvoyPlanner) bodyHeight() int {
+	return max(p.height-6, 1)
+}
+
+// View renders the planner inside ui.DetailBorder.
+func (p ConvoyPlanner) View() string {
+	header := lipgloss.NewStyle().Bold(true).Foreground(ui.BrightGold).Render("CONVOY PLANNER")
+	out := []string{header, p.sourceLine(), p.summaryLine(), ""}
+
+	dim := lipgloss.NewStyle().Foreground(ui.Dim)
+	body := p.bodyHeight()
+	switch {
+	case p.source == "":
+		out = append(out, dim.Render("Enter issue ids or a filter query to plan a convoy."))
+		body--
+	case len(p.members) == 0:
+		out = append(out, dim.Render("No open issues match."))
+		body--
+	default:
+		end := min(p.offset+body, len(p.members))
+		for i := p.offset; i < end; i++ {
+			out = append(out, p.renderMember(p.members[i], i == p.cursor))
+		}
+		body -= end - p.offset
+	}
+	for ; body > 0; body-- {
+		out = append(out, "")
+	}
+
+	out = append(out, "")
+	if p.input != plannerBrowse {
+		out = append(out, p.textInput.View())
+	} else {
+		out = append(out, dim.Render("  space prune  X all/none  / source  c create  + add to convoy  esc close"))
+	}
+
+	return ui.DetailBorder.Width(p.width).Height(p.height).Render(strings.Join(out, "\n"))
+}
+
+func (p ConvoyPlanner) sourceLine() string {
+	label := "query"
+	if p.rooted {
+		label = "root"
+	}
+	return lipgloss.NewStyle().Foreground(ui.Muted).Render(label+": ") +
+		lipgloss.NewStyle().Foreground(ui.Light).Render(p.source)
+}
+
+// summaryLine counts the selection and totals its estimated effort. The
+// total carries "~" when any part of it comes from history rather than an
+// estimate, and unestimated members are counted separately.
+func (p ConvoyPlanner) summaryLine() string {
+	var total time.Duration
+	var unknown int
+	source := data.EffortEstimate
+	selected := 0
+	for _, m := range p.members {
+		if p.pruned[m.Issue.ID] {
+			continue
+		}
+		selected++
+		switch m.Effort.Source {
+		case data.EffortUnknown:
+			unknown++
+		case data.EffortHistory:
+			source = data.EffortHistory
+		}
+		total += m.Effort.Duration
+	}
+	parts := []string{fmt.Sprintf("%d of %d selected", selected, len(p.members))}
+	if selected > unknown {
+		parts = append(parts, "effort "+data.Effort{Duration: total, Source: source}.Label())
+	}
+	if unknown > 0 {
+		parts = append(parts, fmt.Sprintf("%d unestimated", unknown))
+	}
+	return lipgloss.NewStyle().Foreground(ui.Dim).Render(strings.Join(parts, " · "))
+}
+
+func (p ConvoyPlanner) renderMember(m data.PlanMember, current bool) string {
+	check := ui.SymSelected
+	checkFg := ui.BrightGreen
+	if p.pruned[m.Issue.ID] {
+		check, checkFg = ui.SymUnselected, ui.Dim
+	}
+	sym, fg := ui.SymLinedUp, ui.StatusLinedUp
+	for _, s := range sections() {
+		if s.Status == m.Parade {
+			sym, fg = s.Symbol, s.Color
+			break
+		}
+	}
+	effort := fmt.Sprintf("%5s", m.Effort.Label())
+	prio := data.PriorityLabel(m.Issue.Priority)
+	via := ""
+	if m.Via != "" {
+		via = "  blocks " + m.Via
+	}
+
+	cursor := "  "
+	if current {
+		cursor = lipgloss.NewStyle().Foreground(ui.BrightGold).Render("▸ ")
+	}
+	prefix := cursor +
+		lipgloss.NewStyle().Foreground(checkFg).Render(check) + " " +
+		lipgloss.NewStyle().Foreground(fg).Render(sym) + " " +
+		lipgloss.NewStyle().Foreground(ui.PriorityColor(int(m.Issue.Priority))).Render(prio) + " " +
+		lipgloss.NewStyle().Foreground(ui.Muted).Render(effort) + " " +
+		lipgloss.NewStyle().Foreground(ui.Light).Bold(current).Render(m.Issue.ID) + " "
+	titleW := max(p.width-4-lipgloss.Width(prefix)-lipgloss.Width(via), 8)
+	title := lipgloss.NewStyle().Foreground(ui.Light).Render(truncate(m.Issue.Title, titleW))
+	if p.pruned[m.Issue.ID] {
+		title = lipgloss.NewStyle().Foreground(ui.Dim).Strikethrough(true).Render(truncate(m.Issue.Title, titleW))
+	}
+	line := prefix + title + lipgloss.NewStyle().Foreground(ui.Dim).Render(via)
+	return ansi.Truncate(line, max(p.width-4, 10), "")
+}
@@ -0,0 +1,117 @@
+package views
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/matt-wright86/mardi-gras/internal/data"
+	"github.com/matt-wright86/mardi-gras/internal/gastown"
+)
+
+func plannerIssues() []data.Issue {
+	return []data.Issue{
+		{ID: "mg-1", Title: "Ship login", Status: data.StatusOpen, IssueType: data.TypeFeature,
+			Dependencies: []data.Dependency{{IssueID: "mg-1", DependsOnID: "mg-2", Type: "blocks"}}},
+		{ID: "mg-2", Title: "Session store", Status: data.StatusInProgress, IssueType: data.TypeTask},
+		{ID: "mg-3", Title: "Unrelated", Status: data.StatusOpen, IssueType: data.TypeBug},
+	}
+}
+
+func plannerKey(s string) tea.KeyPressMsg {
+	switch s {
+	case "space":
+		return tea.KeyPressMsg{Code: tea.KeySpace, Text: " "}
+	}
+	return transcriptKey(s)
+}
+
+func typeInto(p ConvoyPlanner, s string) ConvoyPlanner {
+	for _, r := range s {
+		p, _ = p.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	return p
+}
+
+func TestConvoyPlannerRootPullsInBlockers(t *testing.T) {
+	p := NewConvoyPlanner(80, 20)
+	p.Open(plannerIssues(), data.DefaultBlockingTypes, "mg-1", nil)
+	if got := p.Selected(); len(got) != 2 || got[0] != "mg-2" || got[1] != "mg-1" {
+		t.Fatalf("Selected() = %v, want blocker mg-2 before mg-1", got)
+	}
+	view := ansi.Strip(p.View())
+	for _, want := range []string{"root: mg-1", "2 of 2 selected", "blocks mg-1"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+}
+
+func TestConvoyPlannerPruneAndCreate(t *testing.T) {
+	p := NewConvoyPlanner(80, 20)
+	p.Open(plannerIssues(), data.DefaultBlockingTypes, "mg-1", nil)
+
+	p, _ = p.Update(plannerKey("space")) // prune mg-2
+	if got := p.Selected(); len(got) != 1 || got[0] != "mg-1" {
+		t.Fatalf("after prune Selected() = %v, want [mg-1]", got)
+	}
+	p, _ = p.Update(plannerKey("c"))
+	if !p.Editing() {
+		t.Fatal("c should open the name prompt")
+	}
+	p = typeInto(p, "Login")
+	p, cmd := p.Update(plannerKey("enter"))
+	if cmd == nil {
+		t.Fatal("confirming a name should emit a plan")
+	}
+	msg, ok := cmd().(ConvoyPlanMsg)
+	if !ok || msg.Name != "Login" || msg.ConvoyID != "" || len(msg.IssueIDs) != 1 || msg.IssueIDs[0] != "mg-1" {
+		t.Errorf("plan = %+v, want create Login with [mg-1]", msg)
+	}
+}
+
+func TestConvoyPlannerAddToExisting(t *testing.T) {
+	p := NewConvoyPlanner(80, 20)
+	convoys := []gastown.ConvoyDetail{{ID: "cv-1", Status: "open"}, {ID: "cv-0", Status: "closed"}}
+	p.Open(plannerIssues(), data.DefaultBlockingTypes, "mg-3", convoys)
+
+	p, _ = p.Update(plannerKey("+"))
+	p = typeInto(p, "cv")
+	p, _ = p.Update(tea.KeyPressMsg{Code: tea.KeyTab}) // accept the open convoy
+	_, cmd := p.Update(plannerKey("enter"))
+	if cmd == nil {
+		t.Fatal("confirming a target should emit a plan")
+	}
+	msg := cmd().(ConvoyPlanMsg)
+	if msg.ConvoyID != "cv-1" || msg.Name != "" || len(msg.IssueIDs) != 1 || msg.IssueIDs[0] != "mg-3" {
+		t.Errorf("plan = %+v, want add [mg-3] to cv-1", msg)
+	}
+}
+
+func TestConvoyPlannerQuerySource(t *testing.T) {
+	p := NewConvoyPlanner(80, 20)
+	if cmd := p.Open(plannerIssues(), data.DefaultBlockingTypes, "", nil); cmd == nil || !p.Editing() {
+		t.Fatal("an empty source should open the source prompt")
+	}
+	p = typeInto(p, "type:bug")
+	p, _ = p.Update(plannerKey("enter"))
+	if p.Editing() {
+		t.Fatal("enter should close the source prompt")
+	}
+	if got := p.Selected(); len(got) != 1 || got[0] != "mg-3" {
+		t.Errorf("Selected() = %v, want the type:bug match", got)
+	}
+	if !strings.Contains(ansi.Strip(p.View()), "query: type:bug") {
+		t.Error("view should label a filter source as a query")
+	}
+}
+
+func TestConvoyPlannerNothingSelectedBlocksCreate(t *testing.T) {
+	p := NewConvoyPlanner(80, 20)
+	p.Open(plannerIssues(), data.DefaultBlockingTypes, "mg-3", nil)
+	p, _ = p.Update(plannerKey("X"))
+	if p, _ = p.Update(plannerKey("c")); p.Editing() {
+		t.Error("create should not prompt with every member pruned")
+	}
+}
@@ -2,6 +2,7 @@ package views
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -35,6 +36,9 @@ type Detail struct {
 	MetadataSchema   *data.MetadataSchema
 	AgentOutput      []string // live captured lines from agent's tmux pane
 	AgentOutputID    string   // which issue the agent output belongs to
+	IssueCosts       map[string]gastown.IssueCost
+	Worktrees        map[string]data.Worktree // issueID -> agent worktree
+	AgentQueue       []string                 // issue IDs waiting for a local agent
 	mdRenderer       goldmark.Markdown
 }
 
@@ -110,6 +114,14 @@ func (d *Detail) SetComments(issueID string, comments []gastown.Comment) {
 	}
 }
 
+// SetWorktrees updates the known agent worktrees.
+func (d *Detail) SetWorktrees(worktrees map[string]data.Worktree) {
+	d.Worktrees = worktrees
+	if d.Issue != nil {
+		d.Viewport.SetContent(d.renderContent())
+	}
+}
+
 // SetRichDetail enriches the current issue with fields from bd show (notes, design, acceptance_criteria).
 func (d *Detail) SetRichDetail(issueID string, rich *data.Issue) {
 	d.RichIssueID = issueID
@@ -299,6 +311,24 @@ func (d *Detail) renderContent() string {
 		}
 	}
 
+	// Local agent queue
+	if i := slices.Index(d.AgentQueue, issue.ID); i >= 0 {
+		lines = append(lines, d.row("Agent:", ui.DetailValue.Render(
+			fmt.Sprintf("queued (%d of %d)", i+1, len(d.AgentQueue)),
+		)))
+	}
+
+	// Agent worktree
+	if wt, ok := d.Worktrees[issue.ID]; ok {
+		lines = append(lines, d.row("Worktree:", ui.DetailValue.Render(wt.Path)))
+		statusColor := ui.BrightGreen
+		if wt.Dirty {
+			statusColor = ui.StatusStalled
+		}
+		lines = append(lines, d.row("Branch:", ui.DetailValue.Render(wt.Branch)+" "+
+			lipgloss.NewStyle().Foreground(statusColor).Render("("+wt.Status()+")")))
+	}
+
 	// Formula recommendation (for open/in-progress issues)
 	if issue.Status != data.StatusClosed {
 		recs := gastown.RecommendFormulas(*issue)
@@ -731,10 +761,11 @@ func (d *Detail) renderMetadata() string {
 	issue := d.Issue
 	schema := d.MetadataSchema
 
-	// If no schema and no issue metadata, nothing to render
+	// If no schema, no issue metadata and no attributed cost, nothing to render
 	hasMetadata := issue != nil && len(issue.Metadata) > 0
 	hasSchema := schema != nil && len(schema.Fields) > 0
-	if !hasSchema && !hasMetadata {
+	costRows := d.renderCostRows()
+	if !hasSchema && !hasMetadata && len(costRows) == 0 {
 		return ""
 	}
 
@@ -775,11 +806,55 @@ func (d *Detail) renderMetadata() string {
 				ui.DetailValue.Render(fmt.Sprintf("%v", issue.Metadata[key])),
 			))
 		}
+	} else {
+		lines = append(lines, ui.DetailSection.Render("METADATA"))
 	}
+	lines = append(lines, costRows...)
 
 	return strings.Join(lines, "\n")
 }
 
+// renderCostRows renders the issue's attributed cost, rolled up over its
+// descendants for an epic, with the attribution method when it is estimated.
+func (d *Detail) renderCostRows() []string {
+	if d.Issue == nil || len(d.IssueCosts) == 0 {
+		return nil
+	}
+	cost, ok := d.IssueCosts[d.Issue.ID]
+	children := data.Descendants(d.AllIssues, d.Issue.ID)
+	if len(children) > 0 {
+		ids := []string{d.Issue.ID}
+		for _, c := range children {
+			ids = append(ids, c.ID)
+		}
+		var priced int
+		cost, priced = gastown.RollupIssueCosts(d.IssueCosts, d.Issue.ID, ids)
+		ok = priced > 0
+		cost.Method = fmt.Sprintf("epic total: %d of %d issues priced", priced, len(ids))
+	}
+	if !ok {
+		return nil
+	}
+
+	dim := lipgloss.NewStyle().Foreground(ui.Dim)
+	value := cost.Label()
+	if cost.Priced && cost.InputTokens+cost.OutputTokens > 0 {
+		value += dim.Render(fmt.Sprintf("  %dk in / %dk out", cost.InputTokens/1000, cost.OutputTokens/1000))
+	}
+	if cost.Hours > 0 {
+		value += dim.Render(fmt.Sprintf("  %.1fh held", cost.Hours))
+	}
+	rows := []string{d.row("Cost:", ui.DetailValue.Render(value))}
+	if cost.Estimate || !cost.Priced || len(children) > 0 {
+		method := cost.Method
+		if cost.Estimate {
+			method = "estimate: " + method
+		}
+		rows = append(rows, d.row("", dim.Render(method)))
+	}
+	return rows
+}
+
 // renderMetadataField renders a single metadata field with schema type and issue value.
 func (d *Detail) renderMetadataField(fieldName string, field data.MetadataFieldSchema, issue *data.Issue) string {
 	typeLabel := field.FieldTypeLabel()
@@ -200,6 +200,39 @@ func TestEpicProgressRenderingInContent(t *testing.T) {
 	}
 }
 
+func TestCostRowsInMetadata(t *testing.T) {
+	issues := []data.Issue{
+		{ID: "mg-100", Title: "Platform migration", Status: data.StatusOpen, Priority: data.PriorityHigh, IssueType: data.TypeEpic, CreatedAt: time.Now()},
+		{ID: "mg-100.1", Title: "Auth", Status: data.StatusClosed, Priority: data.PriorityMedium, IssueType: data.TypeTask, CreatedAt: time.Now()},
+		{ID: "mg-100.2", Title: "Billing", Status: data.StatusOpen, Priority: data.PriorityMedium, IssueType: data.TypeTask, CreatedAt: time.Now()},
+	}
+
+	d := NewDetail(80, 40, issues)
+	d.IssueCosts = map[string]gastown.IssueCost{
+		"mg-100.1": {IssueID: "mg-100.1", Cost: 3, InputTokens: 12000, Hours: 2, Priced: true},
+		"mg-100.2": {IssueID: "mg-100.2", Cost: 1.5, Priced: true, Estimate: true, Method: "live hook · polecat cost split 2 ways"},
+	}
+
+	d.SetIssue(&issues[1])
+	content := ansi.Strip(d.renderContent())
+	if !strings.Contains(content, "METADATA") || !strings.Contains(content, "$3.00  12k in / 0k out  2.0h held") {
+		t.Fatalf("exact cost row missing:\n%s", content)
+	}
+	if strings.Contains(content, "estimate:") {
+		t.Fatalf("exact cost should not show a method:\n%s", content)
+	}
+
+	d.SetIssue(&issues[2])
+	if content = ansi.Strip(d.renderContent()); !strings.Contains(content, "~$1.50") || !strings.Contains(content, "estimate: live hook") {
+		t.Fatalf("estimated cost should show its method:\n%s", content)
+	}
+
+	d.SetIssue(&issues[0])
+	if content = ansi.Strip(d.renderContent()); !strings.Contains(content, "~$4.50") || !strings.Contains(content, "epic total: 2 of 3 issues priced") {
+		t.Fatalf("epic should roll up its children:\n%s", content)
+	}
+}
+
 func TestSetMolecule(t *testing.T) {
 	issues := []data.Issue{
 		{ID: "mg-001", Title: "Test Issue", Status: data.StatusInProgress, Priority: data.PriorityMedium, IssueType: data.TypeTask, CreatedAt: time.Now()},
@@ -1105,3 +1138,21 @@ func TestRenderMarkdownShowsBlockHTMLLiterally(t *testing.T) {
 		})
 	}
 }
+
+func TestDetailShowsWorktree(t *testing.T) {
+	issues := []data.Issue{
+		{ID: "mg-001", Title: "Parser", Status: data.StatusInProgress, Priority: data.PriorityMedium, IssueType: data.TypeBug},
+	}
+	d := NewDetail(80, 40, issues)
+	d.SetIssue(&issues[0])
+	d.SetWorktrees(map[string]data.Worktree{
+		"mg-001": {IssueID: "mg-001", Path: "/src/mg.worktrees/fix-mg-001-parser", Branch: "fix/mg-001-parser", Dirty: true, Ahead: 2},
+	})
+
+	content := ansi.Strip(d.Viewport.View())
+	for _, want := range []string{"/src/mg.worktrees/fix-mg-001-parser", "fix/mg-001-parser (dirty · 2 ahead)"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("detail should show %q, got:\n%s", want, content)
+		}
+	}
+}
@@ -3,11 +3,13 @@ package views
 import (
 	"fmt"
 	"image/color"
+	"sort"
 	"strings"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/matt-wright86/mardi-gras/internal/data"
 	"github.com/matt-wright86/mardi-gras/internal/gastown"
 	"github.com/matt-wright86/mardi-gras/internal/ui"
@@ -20,6 +22,7 @@ const (
 	SectionAgents GasTownSection = iota
 	SectionConvoys
 	SectionMail
+	SectionPatrol
 )
 
 // ActionType identifies a user action from the Gas Town panel.
@@ -38,6 +41,8 @@ const (
 	ActionMailRead        ActionType = "mail_read"
 	ActionMailMarkAllRead ActionType = "mail_mark_all_read"
 	ActionMailCompose     ActionType = "mail_compose"
+	ActionTranscript      ActionType = "transcript"
+	ActionConvoyTimeline  ActionType = "convoy_timeline"
 )
 
 // GasTownActionMsg carries user intent from the Gas Town panel back to app.go.
@@ -71,21 +76,41 @@ type GasTown struct {
 	// Costs data
 	costs *gastown.CostsOutput
 
+	// Cost attributed to each issue, rolled up per convoy
+	issueCosts map[string]gastown.IssueCost
+
+	// Configured cost budgets measured against costs
+	budgets []gastown.BudgetStatus
+
 	// Activity feed
 	events []gastown.Event
 
 	// Vitals data (server health + backups)
 	vitals *gastown.Vitals
 
+	// Patrol scan history, oldest first. The patrol section's cursor moves
+	// over the scans that flagged anything, newest first; expandedPatrol is
+	// the time of the scan whose details are shown.
+	patrolHistory  []gastown.PatrolSample
+	patrolCursor   int
+	expandedPatrol time.Time
+
 	// Velocity metrics
 	velocity *gastown.VelocityMetrics
 
-	// Agent scorecards (HOP quality aggregates)
-	scorecards []gastown.AgentScorecard
+	// Agent scorecards (HOP quality aggregates), sorted for display by
+	// scoreSort; scoreReverse flips the column's natural order.
+	scorecards   []gastown.AgentScorecard
+	scoreSort    scorecardColumn
+	scoreReverse bool
 
 	// Convoy predictions
 	predictions []gastown.ConvoyPrediction
 
+	// Monte Carlo completion forecasts (convoys, epics, the active filter)
+	forecasts      []gastown.Forecast
+	forecastWindow int
+
 	// Liveness tick state
 	tickCount      int                  // incremented every tick for animations
 	workStartTimes map[string]time.Time // agent name -> when they started working
@@ -216,6 +241,11 @@ func (g *GasTown) SetCosts(costs *gastown.CostsOutput) {
 	g.costs = costs
 }
 
+// SetBudgets updates the budget statuses drawn under the costs section.
+func (g *GasTown) SetBudgets(budgets []gastown.BudgetStatus) {
+	g.budgets = budgets
+}
+
 // GetCosts returns the current cost data for velocity computation.
 func (g *GasTown) GetCosts() *gastown.CostsOutput {
 	return g.costs
@@ -246,6 +276,33 @@ func (g *GasTown) SetVitals(v *gastown.Vitals) {
 	g.vitals = v
 }
 
+// SetPatrolHistory updates the patrol scan history.
+func (g *GasTown) SetPatrolHistory(history []gastown.PatrolSample) {
+	g.patrolHistory = history
+	n := len(g.patrolScans())
+	if g.patrolCursor >= n {
+		g.patrolCursor = max(n-1, 0)
+	}
+	if n == 0 && g.section == SectionPatrol {
+		g.section = SectionAgents
+	}
+}
+
+// maxPatrolScans caps the flagged scans listed in the patrol section.
+const maxPatrolScans = 20
+
+// patrolScans returns the indexes into patrolHistory of the scans that
+// flagged anything, newest first.
+func (g *GasTown) patrolScans() []int {
+	var out []int
+	for i := len(g.patrolHistory) - 1; i >= 0 && len(out) < maxPatrolScans; i-- {
+		if g.patrolHistory[i].Flagged() > 0 {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
 // SetVelocity updates the velocity metrics.
 func (g *GasTown) SetVelocity(v *gastown.VelocityMetrics) {
 	g.velocity = v
@@ -261,6 +318,18 @@ func (g *GasTown) SetPredictions(preds []gastown.ConvoyPrediction) {
 	g.predictions = preds
 }
 
+// SetIssueCosts updates the per-issue cost attribution shown on convoys.
+func (g *GasTown) SetIssueCosts(costs map[string]gastown.IssueCost) {
+	g.issueCosts = costs
+}
+
+// SetForecasts updates the Monte Carlo completion forecasts and the number
+// of days of close history they sampled.
+func (g *GasTown) SetForecasts(forecasts []gastown.Forecast, window int) {
+	g.forecasts = forecasts
+	g.forecastWindow = window
+}
+
 // SelectedMail returns the currently selected mail message, or nil if none.
 func (g *GasTown) SelectedMail() *gastown.MailMessage {
 	if g.section != SectionMail {
@@ -298,23 +367,25 @@ func (g GasTown) Update(msg tea.Msg) (GasTown, tea.Cmd) {
 
 	switch km.String() {
 	case "tab":
-		// Cycle through sections: Agents → Convoys → Mail → Agents
-		switch g.section {
-		case SectionAgents:
-			if len(g.convoyDetails) > 0 {
-				g.section = SectionConvoys
-			} else if len(g.mailMessages) > 0 {
-				g.section = SectionMail
+		// Cycle through the sections that have entries:
+		// Agents → Convoys → Mail → Patrol → Agents
+		order := []GasTownSection{SectionAgents}
+		if len(g.convoyDetails) > 0 {
+			order = append(order, SectionConvoys)
+		}
+		if len(g.mailMessages) > 0 {
+			order = append(order, SectionMail)
+		}
+		if len(g.patrolScans()) > 0 {
+			order = append(order, SectionPatrol)
+		}
+		next := SectionAgents
+		for i, sec := range order {
+			if sec == g.section {
+				next = order[(i+1)%len(order)]
 			}
-		case SectionConvoys:
-			if len(g.mailMessages) > 0 {
-				g.section = SectionMail
-			} else {
-				g.section = SectionAgents
-			}
-		case SectionMail:
-			g.section = SectionAgents
 		}
+		g.section = next
 		return g, nil
 
 	case "j", "down":
@@ -354,6 +425,15 @@ func (g GasTown) Update(msg tea.Msg) (GasTown, tea.Cmd) {
 					}
 				}
 			}
+		case SectionPatrol:
+			if scans := g.patrolScans(); g.patrolCursor < len(scans) {
+				at := g.patrolHistory[scans[g.patrolCursor]].At
+				if g.expandedPatrol.Equal(at) {
+					g.expandedPatrol = time.Time{}
+				} else {
+					g.expandedPatrol = at
+				}
+			}
 		}
 		return g, nil
 
@@ -377,6 +457,24 @@ func (g GasTown) Update(msg tea.Msg) (GasTown, tea.Cmd) {
 			}
 		}
 
+	case "t":
+		switch g.section {
+		case SectionAgents:
+			if a := g.SelectedAgent(); a != nil {
+				agent := *a
+				return g, func() tea.Msg {
+					return GasTownActionMsg{Type: ActionTranscript, Agent: agent}
+				}
+			}
+		case SectionConvoys:
+			if c := g.SelectedConvoy(); c != nil {
+				convoyID := c.ID
+				return g, func() tea.Msg {
+					return GasTownActionMsg{Type: ActionConvoyTimeline, ConvoyID: convoyID}
+				}
+			}
+		}
+
 	case "K":
 		if g.section == SectionAgents {
 			if a := g.SelectedAgent(); a != nil && a.Role == "polecat" {
@@ -434,6 +532,15 @@ func (g GasTown) Update(msg tea.Msg) (GasTown, tea.Cmd) {
 			}
 		}
 
+	case "o":
+		g.scoreSort = (g.scoreSort + 1) % scorecardColumns
+		g.scoreReverse = false
+		return g, nil
+
+	case "O":
+		g.scoreReverse = !g.scoreReverse
+		return g, nil
+
 	case "W":
 		if g.section == SectionConvoys {
 			if c := g.SelectedConvoy(); c != nil {
@@ -495,6 +602,11 @@ func (g *GasTown) moveCursorDown() {
 		if count > 0 && g.mailCursor < count-1 {
 			g.mailCursor++
 		}
+	case SectionPatrol:
+		count := len(g.patrolScans())
+		if count > 0 && g.patrolCursor < count-1 {
+			g.patrolCursor++
+		}
 	}
 }
 
@@ -513,6 +625,10 @@ func (g *GasTown) moveCursorUp() {
 		if g.mailCursor > 0 {
 			g.mailCursor--
 		}
+	case SectionPatrol:
+		if g.patrolCursor > 0 {
+			g.patrolCursor--
+		}
 	}
 }
 
@@ -525,6 +641,8 @@ func (g *GasTown) jumpTop() {
 		g.convoyCursor = 0
 	case SectionMail:
 		g.mailCursor = 0
+	case SectionPatrol:
+		g.patrolCursor = 0
 	}
 }
 
@@ -546,6 +664,11 @@ func (g *GasTown) jumpBottom() {
 		if count > 0 {
 			g.mailCursor = count - 1
 		}
+	case SectionPatrol:
+		count := len(g.patrolScans())
+		if count > 0 {
+			g.patrolCursor = count - 1
+		}
 	}
 }
 
@@ -624,6 +747,10 @@ func (g *GasTown) renderContent() string {
 		sections = append(sections, g.renderVitals(contentWidth))
 	}
 
+	if len(g.patrolHistory) > 0 {
+		sections = append(sections, g.renderPatrol(contentWidth))
+	}
+
 	if len(g.events) > 0 {
 		sections = append(sections, g.renderActivity(contentWidth))
 	}
@@ -632,6 +759,10 @@ func (g *GasTown) renderContent() string {
 		sections = append(sections, g.renderVelocity(contentWidth))
 	}
 
+	if len(g.forecasts) > 0 {
+		sections = append(sections, g.renderForecasts(contentWidth))
+	}
+
 	if len(g.scorecards) > 0 {
 		sections = append(sections, g.renderScorecards(contentWidth))
 	}
@@ -960,6 +1091,15 @@ func (g *GasTown) renderConvoyDetails(width int) string {
 				break
 			}
 		}
+		if len(g.issueCosts) > 0 && len(c.Tracked) > 0 {
+			ids := make([]string, len(c.Tracked))
+			for j, t := range c.Tracked {
+				ids[j] = t.ID
+			}
+			if cost, n := gastown.RollupIssueCosts(g.issueCosts, c.ID, ids); n > 0 {
+				barLine += lipgloss.NewStyle().Foreground(ui.Dim).Render("  " + cost.Label())
+			}
+		}
 		lines = append(lines, barLine)
 
 		// Compact pipeline visualization (when collapsed)
@@ -997,6 +1137,9 @@ func (g *GasTown) renderConvoyDetails(width int) string {
 					workerStyle := lipgloss.NewStyle().Foreground(ui.Dim)
 					issueLine += workerStyle.Render(fmt.Sprintf(" [%s]", t.Worker))
 				}
+				if cost, ok := g.issueCosts[t.ID]; ok && cost.Priced {
+					issueLine += lipgloss.NewStyle().Foreground(ui.Dim).Render(" " + cost.Label())
+				}
 
 				lines = append(lines, issueLine)
 			}
@@ -1170,9 +1313,44 @@ func (g *GasTown) renderCosts(width int) string {
 				c.Total.InputTokens/1000, c.Total.OutputTokens/1000)))
 	}
 
+	if len(g.budgets) > 0 {
+		lines = append(lines, g.renderBudgets(width)...)
+	}
+
 	return strings.Join(lines, "\n")
 }
 
+// renderBudgets renders one bar per configured budget, with the projected
+// spend called out when the burn rate runs past the limit.
+func (g *GasTown) renderBudgets(width int) []string {
+	dimStyle := lipgloss.NewStyle().Foreground(ui.Dim)
+	warnStyle := lipgloss.NewStyle().Foreground(ui.BrightGold)
+	overStyle := lipgloss.NewStyle().Foreground(ui.StateBackoff)
+	barW := min(max(width/4, 8), 20)
+
+	lines := []string{dimStyle.Render("  budgets:")}
+	for _, b := range g.budgets {
+		spend := fmt.Sprintf("$%.2f / $%.2f", b.Spent, b.Limit)
+		line := fmt.Sprintf("  %s %s  %s",
+			ui.GradientBar(b.Percent(), barW, ui.GradientProgress),
+			ui.GasTownLabel.Render(b.Label()),
+			dimStyle.Render(spend))
+		switch {
+		case b.Exceeded():
+			line += "  " + overStyle.Render("over budget")
+		case b.Overrun():
+			line += "  " + warnStyle.Render(fmt.Sprintf("→ $%.2f", b.Projected))
+		}
+		if b.Scope == gastown.BudgetTown && b.Period == gastown.BudgetDaily {
+			if eta := gastown.PredictCostBudget(g.costs, b.Limit, time.Now()); eta != "" && !b.Exceeded() {
+				line += "  " + dimStyle.Render(eta)
+			}
+		}
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+	}
+	return lines
+}
+
 // renderVitals renders the server health and backup freshness section.
 func (g *GasTown) renderVitals(width int) string {
 	v := g.vitals
@@ -1252,6 +1430,129 @@ func (g *GasTown) renderVitals(width int) string {
 	return strings.Join(lines, "\n")
 }
 
+// patrolTimelineBuckets is the number of one-hour sparkline columns in the
+// patrol timeline, spanning the whole history.
+const patrolTimelineBuckets = 24
+
+// renderPatrol renders the patrol section: a health timeline per rig, then
+// the recent scans that flagged anything, with the selected scan's agents
+// and whether they recovered.
+func (g *GasTown) renderPatrol(width int) string {
+	dimStyle := lipgloss.NewStyle().Foreground(ui.Dim)
+	greenStyle := lipgloss.NewStyle().Foreground(ui.BrightGreen)
+	redStyle := lipgloss.NewStyle().Foreground(ui.StateBackoff)
+	now := time.Now()
+
+	lines := []string{ui.SectionDivider("PATROL (24h)", width, g.section == SectionPatrol)}
+
+	rigW := 4
+	timelines := gastown.PatrolTimelines(g.patrolHistory, patrolTimelineBuckets, gastown.PatrolHistoryRetention, now)
+	for _, t := range timelines {
+		rigW = max(rigW, lipgloss.Width(t.Rig))
+	}
+	rigW = min(rigW, 16)
+	for _, t := range timelines {
+		values := make([]int, len(t.Flagged))
+		for i, v := range t.Flagged {
+			values[i] = max(v, 0)
+		}
+		name := t.Rig
+		if name == "" {
+			name = "town"
+		}
+		line := fmt.Sprintf("  %-*s %s  %s", rigW, truncateGT(name, rigW),
+			ui.RenderSparkline(values, patrolTimelineBuckets),
+			dimStyle.Render(fmt.Sprintf("%d/%d flagged", t.Latest.Flagged(), t.Latest.Checked())))
+		if t.HealthySince.IsZero() {
+			line += "  " + redStyle.Render("unhealthy")
+		} else {
+			line += "  " + greenStyle.Render("healthy "+formatDuration(now.Sub(t.HealthySince)))
+		}
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+	}
+
+	scans := g.patrolScans()
+	if len(scans) == 0 {
+		return strings.Join(lines, "\n")
+	}
+	lines = append(lines, dimStyle.Render("  recent flags:"))
+	for i, idx := range scans {
+		scan := g.patrolHistory[idx]
+		isSelected := g.section == SectionPatrol && i == g.patrolCursor
+		isExpanded := g.expandedPatrol.Equal(scan.At)
+
+		expandSym := "+"
+		if isExpanded {
+			expandSym = "-"
+		}
+		prefix := "  "
+		if isSelected {
+			prefix = ui.ItemCursor.Render(ui.Cursor + " ")
+		}
+		var found []string
+		if n := scan.Zombies.Found; n > 0 {
+			found = append(found, fmt.Sprintf("%d zombie(s)", n))
+		}
+		if n := scan.Stalls.Found; n > 0 {
+			found = append(found, fmt.Sprintf("%d stall(s)", n))
+		}
+		line := fmt.Sprintf("%s%s %s  %s  %s", prefix,
+			dimStyle.Render(expandSym),
+			dimStyle.Render(scan.At.Format("Jan 02 15:04")),
+			ui.GasTownValue.Render(scan.Rig),
+			strings.Join(found, ", "))
+		if isSelected {
+			line = ui.SelectedRow(line, width)
+		}
+		lines = append(lines, line)
+		if isExpanded {
+			lines = append(lines, g.renderPatrolOutcomes(idx, width)...)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderPatrolOutcomes lists the agents a scan flagged and what became of
+// each in later scans.
+func (g *GasTown) renderPatrolOutcomes(idx, width int) []string {
+	dimStyle := lipgloss.NewStyle().Foreground(ui.Dim)
+	outcomes := gastown.PatrolOutcomes(g.patrolHistory, idx)
+	if len(outcomes) == 0 {
+		return []string{dimStyle.Render("      no per-agent details in this scan")}
+	}
+	scanAt := g.patrolHistory[idx].At
+	var lines []string
+	for _, o := range outcomes {
+		var sym, outcome string
+		var style lipgloss.Style
+		switch {
+		case !o.RecoveredAt.IsZero():
+			sym, style = ui.SymResolved, lipgloss.NewStyle().Foreground(ui.BrightGreen)
+			outcome = "recovered after " + formatDuration(o.RecoveredAt.Sub(scanAt))
+		case o.Pending:
+			sym, style = ui.SymIdle, lipgloss.NewStyle().Foreground(ui.Muted)
+			outcome = "latest scan"
+		default:
+			sym, style = ui.SymStalled, lipgloss.NewStyle().Foreground(ui.StateBackoff)
+			outcome = "still flagged"
+		}
+		who := o.Agent
+		if o.Role != "" {
+			who += " (" + o.Role + ")"
+		}
+		line := fmt.Sprintf("      %s %-7s %s", style.Render(sym), o.Type, who)
+		if o.HookBead != "" {
+			line += dimStyle.Render("  " + o.HookBead)
+		}
+		line += "  " + style.Render(outcome)
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+		if o.Detail != "" {
+			lines = append(lines, dimStyle.Render("        "+truncateGT(o.Detail, width-10)))
+		}
+	}
+	return lines
+}
+
 // renderVelocity renders the workflow velocity metrics section.
 func (g *GasTown) renderVelocity(width int) string {
 	v := g.velocity
@@ -1474,37 +1775,209 @@ func formatEventTime(ts string) string {
 	}
 }
 
-// renderScorecards renders the agent scorecards section.
+// scorecardColumn is a sortable column of the scorecards table.
+type scorecardColumn int
+
+const (
+	scoreByClosed scorecardColumn = iota
+	scoreByCycle
+	scoreByReopened
+	scoreByAbandoned
+	scoreByComments
+	scoreByCost
+	scorecardColumns // count
+)
+
+// scorecardHeaders are the table headings, indexed by scorecardColumn.
+var scorecardHeaders = [scorecardColumns]string{"CLOSED", "CYCLE", "REOPEN", "ABANDON", "CMT/IS", "$/CLOSED"}
+
+// scoreValue returns a card's value in column c and whether it is known.
+// Unknown values sort last in either direction.
+func scoreValue(sc gastown.AgentScorecard, c scorecardColumn) (float64, bool) {
+	switch c {
+	case scoreByCycle:
+		return float64(sc.MedianCycle), sc.MedianCycle > 0
+	case scoreByReopened:
+		return float64(sc.Reopened), true
+	case scoreByAbandoned:
+		return float64(sc.AbandonedHooks), true
+	case scoreByComments:
+		return sc.CommentsPerIssue, sc.IssuesClosed > 0
+	case scoreByCost:
+		return sc.CostPerClosed()
+	}
+	return float64(sc.IssuesClosed), true
+}
+
+// sortedScorecards orders the cards by the selected column: shortest cycle
+// time and cheapest cost per close first, largest counts first elsewhere,
+// flipped by O.
+func (g *GasTown) sortedScorecards() []gastown.AgentScorecard {
+	cards := append([]gastown.AgentScorecard(nil), g.scorecards...)
+	ascending := g.scoreSort == scoreByCycle || g.scoreSort == scoreByCost
+	if g.scoreReverse {
+		ascending = !ascending
+	}
+	sort.SliceStable(cards, func(i, j int) bool {
+		a, aok := scoreValue(cards[i], g.scoreSort)
+		b, bok := scoreValue(cards[j], g.scoreSort)
+		switch {
+		case aok != bok:
+			return aok
+		case a == b:
+			return cards[i].Name < cards[j].Name
+		case ascending:
+			return a < b
+		}
+		return a > b
+	})
+	return cards
+}
+
+// trendArrow renders a week-over-week change: ▲ up, ▼ down, green when the
+// change is an improvement (good is +1 for closes, -1 for cycle time).
+func trendArrow(dir, good int) string {
+	if dir == 0 {
+		return " "
+	}
+	arrow := "▲"
+	if dir < 0 {
+		arrow = "▼"
+	}
+	c := ui.StatusStalled
+	if dir == good {
+		c = ui.BrightGreen
+	}
+	return lipgloss.NewStyle().Foreground(c).Render(arrow)
+}
+
+// renderScorecards renders the agent scorecards as a table sortable with o
+// (column) and O (reverse), with week-over-week trend arrows on closes and
+// cycle time.
 func (g *GasTown) renderScorecards(width int) string {
 	var lines []string
 
 	lines = append(lines, ui.SectionDivider("SCORECARDS", width, false))
+	dir := "▼"
+	if ascending := g.scoreSort == scoreByCycle || g.scoreSort == scoreByCost; ascending != g.scoreReverse {
+		dir = "▲"
+	}
 	lines = append(lines, "  "+lipgloss.NewStyle().Foreground(ui.Muted).Render(
-		fmt.Sprintf("%d agents", len(g.scorecards))))
+		fmt.Sprintf("%d agents · sorted by %s %s · trends vs last week · o sort  O reverse",
+			len(g.scorecards), strings.ToLower(scorecardHeaders[g.scoreSort]), dir)))
 
 	labelStyle := lipgloss.NewStyle().Foreground(ui.Dim)
 	nameStyle := lipgloss.NewStyle().Foreground(ui.Light)
+	headStyle := lipgloss.NewStyle().Foreground(ui.Muted).Bold(true)
+	activeStyle := lipgloss.NewStyle().Foreground(ui.BrightGold).Bold(true)
 
-	for _, sc := range g.scorecards {
-		line := fmt.Sprintf("  %-12s %s%s",
-			nameStyle.Render(sc.Name),
-			labelStyle.Render(fmt.Sprintf("%d", sc.IssuesClosed)),
-			labelStyle.Render(" closed"))
-		lines = append(lines, line)
+	// Column widths; CLOSED and CYCLE carry a trend arrow after the value.
+	widths := [scorecardColumns]int{6, 6, 6, 7, 6, 8}
+	head := "  " + headStyle.Render(fmt.Sprintf("%-12s", "AGENT"))
+	for c, h := range scorecardHeaders {
+		style := headStyle
+		if scorecardColumn(c) == g.scoreSort {
+			style = activeStyle
+		}
+		cell := fmt.Sprintf(" %*s", widths[c], h)
+		if c == int(scoreByClosed) || c == int(scoreByCycle) {
+			cell += " "
+		}
+		head += style.Render(cell)
+	}
+	lines = append(lines, ansi.Truncate(head, width, ""))
+
+	for _, sc := range g.sortedScorecards() {
+		cost := "-"
+		if cpc, ok := sc.CostPerClosed(); ok {
+			cost = fmt.Sprintf("$%.2f", cpc)
+		}
+		comments := "-"
+		if sc.IssuesClosed > 0 {
+			comments = fmt.Sprintf("%.1f", sc.CommentsPerIssue)
+		}
+		line := "  " + nameStyle.Render(fmt.Sprintf("%-12s", truncateGT(sc.Name, 12))) +
+			labelStyle.Render(fmt.Sprintf(" %*d", widths[scoreByClosed], sc.IssuesClosed)) + trendArrow(sc.ClosedTrend(), 1) +
+			labelStyle.Render(fmt.Sprintf(" %*s", widths[scoreByCycle], gastown.FormatSpan(sc.MedianCycle))) + trendArrow(sc.CycleTrend(), -1) +
+			labelStyle.Render(fmt.Sprintf(" %*d %*d %*s %*s",
+				widths[scoreByReopened], sc.Reopened,
+				widths[scoreByAbandoned], sc.AbandonedHooks,
+				widths[scoreByComments], comments,
+				widths[scoreByCost], cost))
+		lines = append(lines, ansi.Truncate(line, width, ""))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderForecasts renders the Monte Carlo forecast section: per target, a
+// sparkline of the finish-day distribution and its P50/P85/P95 dates.
+func (g *GasTown) renderForecasts(width int) string {
+	var lines []string
+
+	lines = append(lines, ui.SectionDivider("FORECAST", width, false))
+	lines = append(lines, "  "+lipgloss.NewStyle().Foreground(ui.Muted).Render(
+		fmt.Sprintf("Monte Carlo over the last %d days of closes", g.forecastWindow)))
+
+	labelStyle := lipgloss.NewStyle().Foreground(ui.Dim)
+	nameStyle := lipgloss.NewStyle().Foreground(ui.Light)
+	now := time.Now()
+	chartW := min(max(width/4, 8), 20)
+
+	for _, f := range g.forecasts {
+		sym := ui.SymConvoy
+		switch f.Kind {
+		case gastown.ForecastEpic:
+			sym = ui.SymLinedUp
+		case gastown.ForecastQuery:
+			sym = "/"
+		}
+		head := fmt.Sprintf("  %s %s  %s",
+			labelStyle.Render(sym),
+			nameStyle.Render(truncateGT(f.Title, width-20)),
+			labelStyle.Render(fmt.Sprintf("%d left", f.Remaining)))
+		lines = append(lines, head)
+
+		if !f.Known {
+			lines = append(lines, "    "+labelStyle.Render("no closes in window to sample"))
+			continue
+		}
+		chart := ui.RenderSparkline(bucketHistogram(f.Histogram, chartW), chartW)
+		dates := fmt.Sprintf("P50 %s  P85 %s  P95 %s",
+			f.PercentileLabel(f.P50, now),
+			f.PercentileLabel(f.P85, now),
+			f.PercentileLabel(f.P95, now))
+		lines = append(lines, "    "+chart+"  "+labelStyle.Render(truncateGT(dates, max(width-chartW-8, 10))))
 	}
 
 	return strings.Join(lines, "\n")
 }
 
+// bucketHistogram folds a finish-day histogram into at most width columns
+// by summing adjacent days.
+func bucketHistogram(h []int, width int) []int {
+	if len(h) <= width {
+		return h
+	}
+	per := (len(h) + width - 1) / width
+	out := make([]int, (len(h)+per-1)/per)
+	for i, n := range h {
+		out[i/per] += n
+	}
+	return out
+}
+
 func (g *GasTown) renderHints() string {
 	var hint string
 	switch g.section {
 	case SectionAgents:
-		hint = "n nudge  w mail  h handoff  K decommission  j/k navigate  tab section"
+		hint = "n nudge  t transcript  w mail  h handoff  K decommission  j/k navigate  tab section"
 	case SectionConvoys:
-		hint = "enter expand  l land  x close  w watch  W unwatch  j/k navigate  tab section"
+		hint = "enter expand  t timeline  l land  x close  w watch  W unwatch  j/k navigate  tab section"
 	case SectionMail:
 		hint = "enter read  r reply  w compose  d archive  R mark-all-read  j/k navigate  tab section"
+	case SectionPatrol:
+		hint = "enter details  j/k navigate  tab section"
 	}
 	return "\n" + ui.GasTownHint.Render(hint)
 }
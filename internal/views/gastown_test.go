@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/matt-wright86/mardi-gras/internal/gastown"
 )
 
@@ -135,6 +137,34 @@ func TestGasTownViewWithConvoys(t *testing.T) {
 	}
 }
 
+func TestGasTownRenderForecasts(t *testing.T) {
+	g := NewGasTown(100, 30)
+	now := time.Now()
+	g.SetForecasts([]gastown.Forecast{
+		{Kind: gastown.ForecastConvoy, ID: "cv-1", Title: "Sprint", Remaining: 4, Known: true,
+			P50: now.AddDate(0, 0, 2), P85: now.AddDate(0, 0, 3), P95: now.AddDate(0, 0, 5),
+			Histogram: []int{0, 1, 5, 3, 1, 1}},
+		{Kind: gastown.ForecastEpic, ID: "mg-1", Title: "Launch", Remaining: 2},
+	}, 30)
+
+	out := ansi.Strip(g.renderForecasts(80))
+	for _, want := range []string{"FORECAST", "last 30 days", "Sprint", "4 left", "P50", "(2d)", "P95", "(5d)", "no closes in window"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("forecast section missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestBucketHistogram(t *testing.T) {
+	got := bucketHistogram([]int{1, 2, 3, 4, 5}, 2)
+	if len(got) != 2 || got[0] != 6 || got[1] != 9 {
+		t.Errorf("bucketHistogram = %v, want [6 9]", got)
+	}
+	if got := bucketHistogram([]int{1, 2}, 5); len(got) != 2 {
+		t.Errorf("short histogram should pass through, got %v", got)
+	}
+}
+
 func TestGasTownViewWithRigs(t *testing.T) {
 	g := NewGasTown(100, 30)
 	status := &gastown.TownStatus{
@@ -286,6 +316,26 @@ func TestGasTownActionHandoff(t *testing.T) {
 	}
 }
 
+func TestGasTownActionTranscript(t *testing.T) {
+	g := NewGasTown(100, 30)
+	agents := []gastown.AgentRuntime{
+		{Name: "toast", Role: "polecat", Address: "beads/toast", Session: "gt-beads-toast"},
+	}
+	g.SetStatus(&gastown.TownStatus{Agents: agents}, gastown.Env{Available: true})
+
+	_, cmd := g.Update(tea.KeyPressMsg{Code: 't', Text: "t"})
+	if cmd == nil {
+		t.Fatal("expected cmd from transcript action")
+	}
+	action, ok := cmd().(GasTownActionMsg)
+	if !ok {
+		t.Fatal("expected GasTownActionMsg")
+	}
+	if action.Type != ActionTranscript || action.Agent.Session != "gt-beads-toast" {
+		t.Fatalf("action = %+v, want transcript for toast's session", action)
+	}
+}
+
 func TestGasTownActionDecommissionOnlyPolecat(t *testing.T) {
 	g := NewGasTown(100, 30)
 	agents := []gastown.AgentRuntime{
@@ -573,6 +623,22 @@ func TestGasTownActionConvoyLand(t *testing.T) {
 	}
 }
 
+func TestGasTownActionConvoyTimeline(t *testing.T) {
+	g := NewGasTown(100, 30)
+	g.SetStatus(&gastown.TownStatus{}, gastown.Env{Available: true})
+	g.SetConvoyDetails([]gastown.ConvoyDetail{{ID: "cv-1", Title: "Sprint", Status: "open"}})
+	g.section = SectionConvoys
+
+	_, cmd := g.Update(tea.KeyPressMsg{Code: 't', Text: "t"})
+	if cmd == nil {
+		t.Fatal("expected cmd from convoy timeline action")
+	}
+	action, ok := cmd().(GasTownActionMsg)
+	if !ok || action.Type != ActionConvoyTimeline || action.ConvoyID != "cv-1" {
+		t.Fatalf("action = %+v, want convoy timeline for cv-1", action)
+	}
+}
+
 func TestGasTownActionConvoyClose(t *testing.T) {
 	g := NewGasTown(100, 30)
 	status := &gastown.TownStatus{Agents: []gastown.AgentRuntime{}}
@@ -1005,6 +1071,45 @@ func TestGasTownSetCosts(t *testing.T) {
 	}
 }
 
+func TestGasTownBudgetBars(t *testing.T) {
+	g := NewGasTown(100, 60)
+	status := &gastown.TownStatus{Agents: []gastown.AgentRuntime{}}
+	g.SetStatus(status, gastown.Env{Available: true})
+	g.SetCosts(&gastown.CostsOutput{Total: gastown.CostTotal{Cost: 30}})
+	g.SetBudgets([]gastown.BudgetStatus{
+		{Scope: gastown.BudgetTown, Period: gastown.BudgetWeekly, Limit: 200, Spent: 70, Projected: 280},
+		{Scope: gastown.BudgetRig, Name: "gastown", Period: gastown.BudgetDaily, Limit: 10, Spent: 12, Projected: 24},
+	})
+
+	view := ansi.Strip(g.View())
+	for _, want := range []string{"budgets:", "town weekly", "$70.00 / $200.00", "→ $280.00", "rig gastown daily", "over budget"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestGasTownConvoyIssueCosts(t *testing.T) {
+	g := NewGasTown(100, 60)
+	g.SetStatus(&gastown.TownStatus{Agents: []gastown.AgentRuntime{}}, gastown.Env{Available: true})
+	g.SetConvoyDetails([]gastown.ConvoyDetail{{
+		ID: "cv-1", Title: "Sprint 1", Status: "open", Completed: 1, Total: 2,
+		Tracked: []gastown.TrackedIssueInfo{{ID: "mg-1", Title: "Auth", Status: "closed"}, {ID: "mg-2", Title: "Billing", Status: "open"}},
+	}})
+	g.SetIssueCosts(map[string]gastown.IssueCost{
+		"mg-1": {IssueID: "mg-1", Cost: 3, Priced: true},
+		"mg-2": {IssueID: "mg-2", Cost: 1, Priced: true, Estimate: true},
+	})
+	g.expandedConvoy = 0
+
+	view := ansi.Strip(g.View())
+	for _, want := range []string{"~$4.00", "Auth $3.00", "Billing ~$1.00"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("view missing %q:\n%s", want, view)
+		}
+	}
+}
+
 func TestGasTownNoCostsSection(t *testing.T) {
 	g := NewGasTown(100, 30)
 	status := &gastown.TownStatus{Agents: []gastown.AgentRuntime{}}
@@ -1173,6 +1278,45 @@ func TestGasTownSetScorecards(t *testing.T) {
 	}
 }
 
+func TestGasTownScorecardTableSort(t *testing.T) {
+	g := NewGasTown(100, 30)
+	g.SetScorecards([]gastown.AgentScorecard{
+		{Name: "Toast", IssuesClosed: 5, MedianCycle: 6 * time.Hour, ClosedThisWeek: 3, ClosedLastWeek: 1},
+		{Name: "Muffin", IssuesClosed: 3, MedianCycle: 2 * time.Hour, Reopened: 2, Cost: 3, CostKnown: true},
+		{Name: "Biscuit", IssuesClosed: 1},
+	})
+	order := func() string {
+		var names []string
+		for _, sc := range g.sortedScorecards() {
+			names = append(names, sc.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	if got := order(); got != "Toast,Muffin,Biscuit" {
+		t.Errorf("default order = %s, want most closed first", got)
+	}
+	g, _ = g.Update(transcriptKey("o"))
+	if got := order(); got != "Muffin,Toast,Biscuit" {
+		t.Errorf("cycle order = %s, want fastest first and unknown last", got)
+	}
+	g, _ = g.Update(transcriptKey("O"))
+	if got := order(); got != "Toast,Muffin,Biscuit" {
+		t.Errorf("reversed cycle order = %s, want slowest first and unknown still last", got)
+	}
+	g, _ = g.Update(transcriptKey("o"))
+	if got := order(); got != "Muffin,Biscuit,Toast" {
+		t.Errorf("reopen order = %s, want most reopened first", got)
+	}
+
+	out := ansi.Strip(g.renderScorecards(100))
+	for _, want := range []string{"sorted by reopen ▼", "AGENT", "CLOSED", "$/CLOSED", "$1.00", "6.0h", "▲"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("scorecards table missing %q:\n%s", want, out)
+		}
+	}
+}
+
 func TestGasTownPredictionInConvoyView(t *testing.T) {
 	g := NewGasTown(100, 30)
 	status := &gastown.TownStatus{Agents: []gastown.AgentRuntime{}}
@@ -1511,3 +1655,44 @@ func TestGasTownMailMarkAllReadInMailSection(t *testing.T) {
 		t.Fatalf("expected mail_mark_all_read, got %s", msg.Type)
 	}
 }
+
+func TestGasTownPatrolTimelineAndDrillDown(t *testing.T) {
+	now := time.Now()
+	zombie := gastown.PatrolSample{At: now.Add(-10 * time.Minute), PatrolScanResult: gastown.PatrolScanResult{
+		Rig:     "gastown",
+		Zombies: gastown.PatrolFinding{Checked: 3, Found: 1},
+		Stalls:  gastown.PatrolFinding{Checked: 3},
+		Details: []gastown.PatrolDetail{{Type: "zombie", Agent: "Toast", Role: "polecat", HookBead: "mg-1"}},
+	}}
+	clean := gastown.PatrolSample{At: now.Add(-5 * time.Minute), PatrolScanResult: gastown.PatrolScanResult{
+		Rig:     "gastown",
+		Zombies: gastown.PatrolFinding{Checked: 3},
+		Stalls:  gastown.PatrolFinding{Checked: 3},
+	}}
+
+	g := NewGasTown(100, 60)
+	g.SetStatus(&gastown.TownStatus{Agents: []gastown.AgentRuntime{}}, gastown.Env{Available: true})
+	g.SetPatrolHistory([]gastown.PatrolSample{zombie, clean})
+
+	view := ansi.Strip(g.View())
+	for _, want := range []string{"PATROL (24h)", "gastown", "0/3 flagged", "healthy 5m", "1 zombie(s)"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	// Tab reaches the patrol section (no convoys or mail); enter drills in.
+	g, _ = g.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if g.Section() != SectionPatrol {
+		t.Fatalf("section = %v, want patrol", g.Section())
+	}
+	g, _ = g.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	view = ansi.Strip(g.View())
+	if !strings.Contains(view, "Toast (polecat)  mg-1  recovered after 5m") {
+		t.Fatalf("drill-down missing Toast's recovery:\n%s", view)
+	}
+	g, _ = g.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if g.Section() != SectionAgents {
+		t.Fatalf("tab from patrol = %v, want agents", g.Section())
+	}
+}
@@ -3,6 +3,7 @@ package views
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
@@ -16,12 +17,34 @@ type RecoveryActionMsg struct {
 	Orphans []gastown.OrphanedIssue
 }
 
+// Problem actions carried by ProblemActionMsg.
+const (
+	ProblemAck    = "ack"
+	ProblemSnooze = "snooze"
+)
+
+// ProblemActionMsg is emitted when the user acknowledges (a) or snoozes (z)
+// a tracked problem. Snoozing an already snoozed problem wakes it.
+type ProblemActionMsg struct {
+	ID     string
+	Action string
+}
+
 // Problems renders the problems detection view in place of the detail pane.
+// In history mode (H) it lists snoozed and resolved problems instead of the
+// active ones.
 type Problems struct {
 	width    int
 	height   int
-	problems []gastown.Problem
+	problems []gastown.TrackedProblem
+	snoozed  []gastown.TrackedProblem
+	history  []gastown.TrackedProblem
 	cursor   int
+
+	// remediations is the latest playbook action per problem ID.
+	remediations map[string]gastown.PlaybookAudit
+
+	showHistory bool
 }
 
 // NewProblems creates a Problems panel.
@@ -35,12 +58,41 @@ func (p *Problems) SetSize(width, height int) {
 	p.height = height
 }
 
-// SetProblems updates the problem list.
+// SetProblems updates the problem list from an untracked snapshot.
 func (p *Problems) SetProblems(problems []gastown.Problem) {
-	p.problems = problems
-	if p.cursor >= len(problems) {
-		p.cursor = max(len(problems)-1, 0)
+	tracked := make([]gastown.TrackedProblem, len(problems))
+	for i, prob := range problems {
+		tracked[i] = gastown.TrackedProblem{Problem: prob}
 	}
+	p.SetTracked(tracked, nil, nil)
+}
+
+// SetTracked updates the active, snoozed and resolved problems.
+func (p *Problems) SetTracked(active, snoozed, history []gastown.TrackedProblem) {
+	p.problems = active
+	p.snoozed = snoozed
+	p.history = history
+	p.clampCursor()
+}
+
+// SetRemediations updates the latest playbook action per problem ID.
+func (p *Problems) SetRemediations(last map[string]gastown.PlaybookAudit) {
+	p.remediations = last
+}
+
+func (p *Problems) clampCursor() {
+	if n := len(p.items()); p.cursor >= n {
+		p.cursor = max(n-1, 0)
+	}
+}
+
+// items is the list the cursor moves over: active problems, or in history
+// mode the snoozed then resolved ones.
+func (p Problems) items() []gastown.TrackedProblem {
+	if !p.showHistory {
+		return p.problems
+	}
+	return append(append([]gastown.TrackedProblem(nil), p.snoozed...), p.history...)
 }
 
 // Count returns the number of detected problems.
@@ -54,13 +106,19 @@ func (p Problems) Update(msg tea.Msg) (Problems, tea.Cmd) {
 	if !ok {
 		return p, nil
 	}
-	if len(p.problems) == 0 {
+	if keyMsg.String() == "H" {
+		p.showHistory = !p.showHistory
+		p.cursor = 0
+		return p, nil
+	}
+	items := p.items()
+	if len(items) == 0 {
 		return p, nil
 	}
 
 	switch keyMsg.String() {
 	case "j", "down":
-		if p.cursor < len(p.problems)-1 {
+		if p.cursor < len(items)-1 {
 			p.cursor++
 		}
 	case "k", "up":
@@ -70,8 +128,28 @@ func (p Problems) Update(msg tea.Msg) (Problems, tea.Cmd) {
 	case "g":
 		p.cursor = 0
 	case "G":
-		p.cursor = len(p.problems) - 1
+		p.cursor = len(items) - 1
+
+	// Acknowledge or snooze the selected tracked problem
+	case "a":
+		prob := items[p.cursor]
+		if p.showHistory || prob.ID == "" {
+			return p, nil
+		}
+		return p, problemAction(prob.ID, ProblemAck)
+	case "z":
+		prob := items[p.cursor]
+		if prob.ID == "" || prob.Resolved() {
+			return p, nil
+		}
+		return p, problemAction(prob.ID, ProblemSnooze)
+	}
 
+	// Agent and rig actions apply to active problems only.
+	if p.showHistory {
+		return p, nil
+	}
+	switch keyMsg.String() {
 	// Actions on selected problem's agent
 	case "n":
 		a := p.problems[p.cursor].Agent
@@ -104,9 +182,21 @@ func (p Problems) Update(msg tea.Msg) (Problems, tea.Cmd) {
 	return p, nil
 }
 
+func problemAction(id, action string) tea.Cmd {
+	return func() tea.Msg {
+		return ProblemActionMsg{ID: id, Action: action}
+	}
+}
+
 // View renders the problems panel.
 func (p Problems) View() string {
+	if p.showHistory {
+		return p.renderFrame(p.historyLines())
+	}
+
 	var lines []string
+	now := time.Now()
+	hintStyle := lipgloss.NewStyle().Foreground(ui.Dim)
 
 	// Header
 	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.BrightGold)
@@ -115,19 +205,21 @@ func (p Problems) View() string {
 		lines = append(lines, "")
 		okStyle := lipgloss.NewStyle().Foreground(ui.BrightGreen)
 		lines = append(lines, okStyle.Render("  "+ui.SymResolved+" No problems detected"))
+		if len(p.snoozed) > 0 || len(p.history) > 0 {
+			lines = append(lines, "", hintStyle.Render(fmt.Sprintf("  %d snoozed, %d resolved  H history", len(p.snoozed), len(p.history))))
+		}
 	} else {
 		warnStyle := lipgloss.NewStyle().Foreground(ui.StatusStalled).Bold(true)
 		header := fmt.Sprintf("PROBLEMS (%d detected)", len(p.problems))
-		lines = append(lines, warnStyle.Render(header))
+		lines = append(lines, warnStyle.Render(header)+p.snoozedNote())
 		lines = append(lines, "")
 
 		for i, prob := range p.problems {
-			lines = append(lines, p.renderProblem(i, prob)...)
+			lines = append(lines, p.renderProblem(i, prob, now)...)
 			lines = append(lines, "") // spacer between problems
 		}
 
 		// Hint bar
-		hintStyle := lipgloss.NewStyle().Foreground(ui.Dim)
 		hasDeadRig := false
 		for _, prob := range p.problems {
 			if prob.Type == "dead_rig" {
@@ -140,8 +232,15 @@ func (p Problems) View() string {
 			hint += "  R recover rig"
 		}
 		lines = append(lines, hintStyle.Render(hint))
+		if p.problems[0].ID != "" {
+			lines = append(lines, hintStyle.Render("  a ack  z snooze 1h  H history"))
+		}
 	}
 
+	return p.renderFrame(lines)
+}
+
+func (p Problems) renderFrame(lines []string) string {
 	content := strings.Join(lines, "\n")
 
 	return ui.DetailBorder.
@@ -150,7 +249,47 @@ func (p Problems) View() string {
 		Render(content)
 }
 
-func (p Problems) renderProblem(idx int, prob gastown.Problem) []string {
+func (p Problems) snoozedNote() string {
+	if len(p.snoozed) == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(ui.Dim).Render(fmt.Sprintf("  %d snoozed", len(p.snoozed)))
+}
+
+// historyLines renders history mode: snoozed problems, then resolved ones
+// with when they were seen and how long they lasted.
+func (p Problems) historyLines() []string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.BrightGold)
+	dimStyle := lipgloss.NewStyle().Foreground(ui.Dim)
+	lines := []string{
+		headerStyle.Render(fmt.Sprintf("PROBLEM HISTORY (%d snoozed, %d resolved)", len(p.snoozed), len(p.history))),
+		"",
+	}
+	items := p.items()
+	if len(items) == 0 {
+		lines = append(lines, dimStyle.Render("  Nothing snoozed or resolved in the last week"))
+	}
+	now := time.Now()
+	for i, prob := range items {
+		lines = append(lines, p.renderProblem(i, prob, now)...)
+		var when string
+		if prob.Resolved() {
+			when = fmt.Sprintf("first seen %s  resolved %s  lasted %s",
+				prob.FirstSeen.Format("Jan 02 15:04"), prob.ResolvedAt.Format("Jan 02 15:04"),
+				formatDuration(prob.ResolvedAt.Sub(prob.FirstSeen)))
+		} else {
+			when = fmt.Sprintf("snoozed until %s", prob.SnoozedUntil.Format("15:04"))
+		}
+		if prob.Occurrences > 1 {
+			when += fmt.Sprintf("  %d occurrences", prob.Occurrences)
+		}
+		lines = append(lines, "    "+dimStyle.Render(when), "")
+	}
+	lines = append(lines, dimStyle.Render("  z wake snoozed  H back"))
+	return lines
+}
+
+func (p Problems) renderProblem(idx int, prob gastown.TrackedProblem, now time.Time) []string {
 	var lines []string
 
 	// Severity + type badge
@@ -183,6 +322,13 @@ func (p Problems) renderProblem(idx int, prob gastown.Problem) []string {
 		}
 	case "dead_rig":
 		contextLabel = "rig " + prob.RigName
+	case "rule":
+		switch {
+		case prob.IssueID != "":
+			contextLabel = "issue " + prob.IssueID
+		case prob.Agent.Name != "":
+			contextLabel = fmt.Sprintf("%s %s", prob.Agent.Role, prob.Agent.Name)
+		}
 	default:
 		contextLabel = fmt.Sprintf("%s %s", prob.Agent.Role, prob.Agent.Name)
 	}
@@ -199,12 +345,19 @@ func (p Problems) renderProblem(idx int, prob gastown.Problem) []string {
 		lipgloss.NewStyle().Foreground(ui.Light).Bold(true).Render(typeLabel),
 		lipgloss.NewStyle().Foreground(ui.Muted).Render(contextLabel),
 	)
+	if prob.ID != "" {
+		line1 += "  " + p.trackingLabel(prob, now)
+	}
 	lines = append(lines, line1)
 
 	// Second line: detail
 	detailStyle := lipgloss.NewStyle().Foreground(ui.Light)
 	lines = append(lines, "    "+detailStyle.Render(prob.Detail))
 
+	if line := p.remediationLine(prob, now); line != "" {
+		lines = append(lines, line)
+	}
+
 	// Orphan list for dead_rig problems
 	if prob.Type == "dead_rig" && len(prob.Orphans) > 0 {
 		orphanStyle := lipgloss.NewStyle().Foreground(ui.Muted)
@@ -233,3 +386,48 @@ func (p Problems) renderProblem(idx int, prob gastown.Problem) []string {
 
 	return lines
 }
+
+// trackingLabel renders a tracked problem's ID, age and state: acknowledged,
+// flapping, or absent from the latest poll and about to clear.
+func (p Problems) trackingLabel(prob gastown.TrackedProblem, now time.Time) string {
+	dimStyle := lipgloss.NewStyle().Foreground(ui.Dim)
+	parts := []string{"#" + prob.ID}
+	if !prob.Resolved() {
+		parts = append(parts, formatDuration(now.Sub(prob.FirstSeen)))
+	}
+	if prob.Flaps > 0 {
+		parts = append(parts, fmt.Sprintf("flapped %d×", prob.Flaps))
+	}
+	if prob.Missing() {
+		parts = append(parts, "clearing")
+	}
+	label := dimStyle.Render(strings.Join(parts, " · "))
+	if prob.Acked {
+		label += " " + lipgloss.NewStyle().Foreground(ui.BrightGreen).Render("ACK")
+	}
+	return label
+}
+
+// remediationLine notes the latest playbook action on a problem, or what a
+// dry run would have done.
+func (p Problems) remediationLine(prob gastown.TrackedProblem, now time.Time) string {
+	a, ok := p.remediations[prob.ID]
+	if !ok || prob.ID == "" || a.At.Before(prob.FirstSeen) {
+		return ""
+	}
+	text := fmt.Sprintf("auto: %s %s %s ago", a.Action, a.Target, formatDuration(now.Sub(a.At)))
+	if a.Attempt > 1 {
+		text += fmt.Sprintf(" · attempt %d", a.Attempt)
+	}
+	style := lipgloss.NewStyle().Foreground(ui.Muted).Italic(true)
+	switch {
+	case a.DryRun:
+		text += " (dry run)"
+	case a.Skipped != "":
+		text += " · skipped: " + a.Skipped
+	case a.Error != "":
+		text += " · failed: " + a.Error
+		style = style.Foreground(ui.StatusStalled)
+	}
+	return "    " + style.Render(text)
+}
@@ -3,8 +3,10 @@ package views
 import (
 	"strings"
 	"testing"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/matt-wright86/mardi-gras/internal/gastown"
 )
 
@@ -315,3 +317,76 @@ func TestProblemsHints(t *testing.T) {
 		t.Fatal("view should contain hint 'decommission'")
 	}
 }
+
+func TestProblemsTrackedAckSnoozeHistory(t *testing.T) {
+	now := time.Now()
+	stalled := gastown.TrackedProblem{
+		Problem: gastown.Problem{Type: "stalled", Agent: gastown.AgentRuntime{Name: "Toast", Role: "polecat"}, Severity: "warn"},
+		ID:      "abc123", FirstSeen: now.Add(-12 * time.Minute), Raised: true, Flaps: 2, Acked: true,
+	}
+	resolved := gastown.TrackedProblem{
+		Problem: gastown.Problem{Type: "rule", IssueID: "mg-7", Detail: "mg-7 Old — stale", Severity: "warn"},
+		ID:      "def456", FirstSeen: now.Add(-3 * time.Hour), ResolvedAt: now.Add(-time.Hour), Raised: true, Occurrences: 2,
+	}
+	snoozed := stalled
+	snoozed.ID = "fed321"
+	snoozed.SnoozedUntil = now.Add(time.Hour)
+
+	p := NewProblems(100, 40)
+	p.SetTracked([]gastown.TrackedProblem{stalled}, []gastown.TrackedProblem{snoozed}, []gastown.TrackedProblem{resolved})
+
+	view := ansi.Strip(p.View())
+	for _, want := range []string{"PROBLEMS (1 detected)", "1 snoozed", "#abc123 · 12m · flapped 2×", "ACK", "a ack"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	_, cmd := p.Update(tea.KeyPressMsg{Code: 'a', Text: "a"})
+	if msg, ok := cmd().(ProblemActionMsg); !ok || msg.ID != "abc123" || msg.Action != ProblemAck {
+		t.Fatalf("a = %#v, want ack of abc123", msg)
+	}
+
+	p, _ = p.Update(tea.KeyPressMsg{Code: 'H', Text: "H"})
+	view = ansi.Strip(p.View())
+	for _, want := range []string{"PROBLEM HISTORY (1 snoozed, 1 resolved)", "#fed321", "snoozed until", "issue mg-7", "lasted 2h0m", "2 occurrences"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("history missing %q:\n%s", want, view)
+		}
+	}
+	// z on the snoozed entry wakes it; nothing acts on a resolved one.
+	_, cmd = p.Update(tea.KeyPressMsg{Code: 'z', Text: "z"})
+	if msg, ok := cmd().(ProblemActionMsg); !ok || msg.ID != "fed321" || msg.Action != ProblemSnooze {
+		t.Fatalf("z = %#v, want snooze toggle of fed321", msg)
+	}
+	p, _ = p.Update(tea.KeyPressMsg{Code: 'j', Text: "j"})
+	for _, key := range []rune{'z', 'a', 'n'} {
+		if _, cmd = p.Update(tea.KeyPressMsg{Code: key, Text: string(key)}); cmd != nil {
+			t.Errorf("%c on a resolved problem should do nothing", key)
+		}
+	}
+}
+
+func TestProblemsRemediationLine(t *testing.T) {
+	now := time.Now()
+	stalled := gastown.TrackedProblem{
+		Problem: gastown.Problem{Type: "stalled", Agent: gastown.AgentRuntime{Name: "Toast", Role: "polecat"}, Severity: "warn"},
+		ID:      "abc123", FirstSeen: now.Add(-20 * time.Minute), Raised: true,
+	}
+	p := NewProblems(100, 40)
+	p.SetTracked([]gastown.TrackedProblem{stalled}, nil, nil)
+	p.SetRemediations(map[string]gastown.PlaybookAudit{
+		"abc123": {At: now.Add(-5 * time.Minute), ProblemID: "abc123", Action: "nudge", Target: "Toast", Attempt: 2, DryRun: true},
+	})
+	view := ansi.Strip(p.View())
+	if !strings.Contains(view, "auto: nudge Toast 5m ago · attempt 2 (dry run)") {
+		t.Fatalf("view missing the remediation:\n%s", view)
+	}
+
+	// An action from before this occurrence began is not shown.
+	stalled.FirstSeen = now.Add(-time.Minute)
+	p.SetTracked([]gastown.TrackedProblem{stalled}, nil, nil)
+	if view = ansi.Strip(p.View()); strings.Contains(view, "auto:") {
+		t.Fatalf("stale remediation shown:\n%s", view)
+	}
+}
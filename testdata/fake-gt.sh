@@ -2,6 +2,25 @@
 # Fake gt binary for local TUI testing.
 # Usage: PATH="$(pwd)/testdata:$PATH" ./mg --path testdata/sample.jsonl
 # Responds to the gt subcommands that mg invokes.
+#
+# Test hooks (used by the driver conformance suite in internal/gastown):
+#   FAKE_GT_DELAY=<secs>  sleep before answering, to exercise timeouts
+#   any "missing-*" arg   fail like gt does for an unknown id/agent
+
+if [ -n "$FAKE_GT_DELAY" ]; then
+  # Detach sleep from our stdout/stderr so a timeout kill of this script
+  # closes the pipes immediately instead of waiting out the sleep.
+  sleep "$FAKE_GT_DELAY" >/dev/null 2>&1
+fi
+
+for arg in "$@"; do
+  case "$arg" in
+    missing-*)
+      echo "Error: $arg not found" >&2
+      exit 1
+      ;;
+  esac
+done
 
 case "$1" in
   status)
@@ -112,11 +131,63 @@ EOF
 ]
 EOF
         ;;
+      status)
+        cat <<'EOF'
+{"id":"convoy-1","title":"Auth Feature Sprint","status":"rolling",
+ "tracked":[{"id":"mg-001","title":"Fix auth service","status":"in_progress","worker":"obsidian"},
+            {"id":"mg-002","title":"Auth tests","status":"open"}],
+ "completed":1,"total":3,"progress_pct":33.3}
+EOF
+        ;;
+      create)
+        echo "Created convoy convoy-3" ;;
+      add)
+        echo "Added ${*:5} to convoy $4" ;;
       *)
         echo "{}" ;;
     esac
     ;;
 
+  formula)
+    printf 'shiny\nquick\nhotfix\n'
+    ;;
+
+  sling|unsling|nudge|close)
+    echo "ok"
+    ;;
+
+  polecat)
+    echo "Decommissioned $3"
+    ;;
+
+  assign)
+    echo "Created mg-010 and hooked to crew member"
+    ;;
+
+  mol)
+    case "$2" in
+      dag)
+        cat <<'EOF'
+{"root_id":"mg-001","root_title":"Fix auth service","total_nodes":2,"tiers":2,
+ "nodes":{
+  "mg-001.1":{"id":"mg-001.1","title":"design","status":"done","tier":0},
+  "mg-001.2":{"id":"mg-001.2","title":"implement","status":"in_progress","tier":1,"dependencies":["mg-001.1"]}},
+ "tier_groups":[["mg-001.1"],["mg-001.2"]]}
+EOF
+        ;;
+      progress)
+        echo '{"root_id":"mg-001","root_title":"Fix auth service","total_steps":2,"done_steps":1,"in_progress_steps":1,"percent_complete":50}'
+        ;;
+      step)
+        echo '{"step_id":"mg-001.2","molecule_id":"mg-001","step_closed":true,"complete":true,"action":"done"}'
+        ;;
+      *)
+        echo "fake-gt: unknown mol command '$2'" >&2
+        exit 1
+        ;;
+    esac
+    ;;
+
   mail)
     case "$2" in
       inbox)
@@ -132,6 +203,13 @@ EOF
    "body":"All checks passed on feat/auth branch.","timestamp":"2026-02-28T22:00:00Z",
    "read":true}
 ]
+EOF
+        ;;
+      read)
+        cat <<'EOF'
+{"id":"mail-1","from":"mayor","to":"crew/matt","subject":"Weekly patrol report",
+ "body":"All clear this week. No incidents.","timestamp":"2026-03-01T08:00:00Z",
+ "read":true}
 EOF
         ;;
       *)
@@ -0,0 +1,155 @@
+// Command fakedriver is a fake out-of-process orchestrator driver for local
+// TUI testing and for the driver conformance suite. It speaks mg's driver
+// protocol (newline-delimited JSON-RPC 2.0 on stdio; see
+// docs/driver-protocol.md) and serves canned data for a small homegrown
+// scheduler, so it doubles as a reference implementation for anyone plugging
+// their own scheduler into mg.
+//
+//	go build -o /tmp/mg-fakedriver ./testdata/fakedriver
+//	MG_DRIVER=exec:/tmp/mg-fakedriver ./mg --path testdata/sample.jsonl
+//
+// Any id, agent or address beginning with "missing-" fails like an unknown
+// object would. FAKE_DRIVER_DELAY=<duration> (e.g. 5s) adds latency to every
+// request after the handshake, to exercise timeouts.
+//
+// Lives under testdata/ so the Go toolchain ignores it (not built/linted/shipped
+// with the module). See `make dev-exec`.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const protocolVersion = 1
+
+// methods is what the handshake declares. Convoy land/watch, molecules,
+// vitals and patrol are left out so mg hides them, as it would for a real
+// scheduler with no such concepts.
+var methods = []string{
+	"status", "formulas", "comments", "sessionTranscript",
+	"sling", "unsling", "nudge", "decommission", "cascadeClose", "assign",
+	"convoyList", "convoyStatus", "convoyCreate", "convoyCreateFromEpic", "convoyAdd", "convoyClose",
+	"mailInbox", "mailRead", "mailReply", "mailSend", "mailArchive", "mailMarkRead", "mailMarkAllRead",
+	"costs",
+}
+
+// canned results, keyed by method, for calls that read fixed data.
+var canned = map[string]string{
+	"status": `{"agents":[
+		{"name":"atlas","role":"worker","rig":"core","running":true,"has_work":true,"work_title":"Deploy authentication service","hook_bead":"mg-001","state":"working","address":"core/atlas","session":"atlas","agent_info":"claude/opus"},
+		{"name":"bishop","role":"worker","rig":"core","running":true,"has_work":true,"work_title":"Fix CI pipeline timeout","hook_bead":"mg-002","state":"working","address":"core/bishop","session":"bishop","agent_info":"codex"},
+		{"name":"cairn","role":"worker","rig":"core","running":true,"state":"idle","address":"core/cairn"}
+	],"rigs":[{"name":"core","polecat_count":3,"crew_count":0,"has_witness":false,"has_refinery":false}],"convoys":[]}`,
+
+	"formulas": `["shiny","quick","hotfix"]`,
+
+	"comments": `[{"id":"c1","author":"atlas","body":"Rollout plan drafted.","created_at":"2026-06-13T08:10:00Z"}]`,
+
+	"sessionTranscript": `{"session":"atlas","provider":"claude","entries":[
+		{"role":"user","text":"Work mg-001: deploy the authentication service.","time":"2026-06-13T08:01:00Z"},
+		{"role":"assistant","text":"Staging rollout is green. Promoting to production.","time":"2026-06-13T08:20:00Z"}
+	]}`,
+
+	"convoyList": `[{"id":"cv-1","title":"Auth launch","status":"open","tracked":[
+		{"id":"mg-001","title":"Deploy authentication service","status":"in_progress","worker":"atlas"},
+		{"id":"mg-002","title":"Fix CI pipeline timeout","status":"in_progress","worker":"bishop"}
+	],"completed":0,"total":2,"progress_pct":0}]`,
+
+	"convoyStatus": `{"id":"cv-1","title":"Auth launch","status":"open","tracked":[
+		{"id":"mg-001","title":"Deploy authentication service","status":"in_progress","worker":"atlas"},
+		{"id":"mg-002","title":"Fix CI pipeline timeout","status":"in_progress","worker":"bishop"}
+	],"completed":0,"total":2,"progress_pct":0}`,
+
+	"convoyCreate":         `"Created convoy cv-2"`,
+	"convoyCreateFromEpic": `"Created convoy cv-3"`,
+	"assign":               `"Created mg-100 and assigned to atlas"`,
+
+	"mailInbox": `[
+		{"id":"msg-1","from":"atlas","to":"you","subject":"Staging rollout green","body":"Promoting mg-001 next.","timestamp":"2026-06-13T08:20:00Z","read":false},
+		{"id":"msg-2","from":"bishop","to":"you","subject":"CI timeout reproduced","body":"Integration stage hits 30m.","timestamp":"2026-06-13T08:05:00Z","read":true}
+	]`,
+	"mailRead": `{"id":"msg-1","from":"atlas","to":"you","subject":"Staging rollout green","body":"Promoting mg-001 next.","timestamp":"2026-06-13T08:20:00Z","read":true}`,
+
+	"costs": `{"period":"today","total":{"input_tokens":120000,"output_tokens":30000,"cost":4.2},"sessions":2}`,
+}
+
+type request struct {
+	ID     *int64          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func main() {
+	delay, _ := time.ParseDuration(os.Getenv("FAKE_DRIVER_DELAY"))
+
+	declared := map[string]bool{}
+	for _, m := range methods {
+		declared[m] = true
+	}
+
+	var wMu sync.Mutex
+	enc := json.NewEncoder(os.Stdout)
+	reply := func(id int64, result any, rerr *rpcError) {
+		msg := map[string]any{"jsonrpc": "2.0", "id": id}
+		if rerr != nil {
+			msg["error"] = rerr
+		} else {
+			msg["result"] = result
+		}
+		wMu.Lock()
+		defer wMu.Unlock()
+		_ = enc.Encode(msg)
+	}
+
+	// Requests are answered concurrently (mg issues them from several
+	// goroutines); the protocol matches replies to requests by id.
+	sc := bufio.NewScanner(os.Stdin)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		var req request
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil || req.ID == nil {
+			continue // notifications and junk get no reply
+		}
+		go func(req request) {
+			logReq(req.Method)
+			if req.Method == "initialize" {
+				reply(*req.ID, map[string]any{
+					"protocol_version": protocolVersion,
+					"backend":          "fakedriver",
+					"methods":          methods,
+				}, nil)
+				return
+			}
+			if delay > 0 {
+				time.Sleep(delay)
+			}
+			if !declared[req.Method] {
+				reply(*req.ID, nil, &rpcError{Code: -32601, Message: "method not found: " + req.Method})
+				return
+			}
+			if strings.Contains(string(req.Params), `"missing-`) {
+				reply(*req.ID, nil, &rpcError{Code: 404, Message: "not found: no such issue, convoy, message or agent"})
+				return
+			}
+			if body, ok := canned[req.Method]; ok {
+				reply(*req.ID, json.RawMessage(body), nil)
+				return
+			}
+			reply(*req.ID, nil, nil) // mutations: accepted
+		}(req)
+	}
+	// EOF on stdin: mg has exited or closed the driver.
+}
+
+func logReq(method string) { fmt.Fprintf(os.Stderr, "fakedriver: %s\n", method) }
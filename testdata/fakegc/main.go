@@ -13,7 +13,9 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -49,6 +51,18 @@ var responses = map[string]string{
 		{"id":"s-muses","session_name":"muses","title":"muses","pool":"polecat","rig":"second_line","state":"active","provider":"anthropic","template":"polecat","created_at":"2026-06-13T08:20:00Z","attached":true,"running":true,"display_name":"Muses"}
 	],"total":4}`,
 
+	// Session transcripts — conversation turns for the transcript overlay (t).
+	"/v0/city/bourbon/session/s-zulu/transcript": `{"id":"s-zulu","template":"polecat","provider":"anthropic","format":"conversation","turns":[
+		{"role":"user","text":"Work mg-q12: refactor the auth service so token refresh lives in one place.","timestamp":"2026-06-13T08:01:00Z"},
+		{"role":"assistant","text":"Reading internal/auth. Token refresh is duplicated in client.go and session.go.\nPlan:\n1. Extract refreshToken into token.go\n2. Point both callers at it\n3. Run the auth tests","timestamp":"2026-06-13T08:02:10Z"},
+		{"role":"user","text":"Rex is adding test coverage in parallel — keep the public API stable.","timestamp":"2026-06-13T08:20:00Z"},
+		{"role":"assistant","text":"Understood. Signatures unchanged; refreshToken stays unexported.\ngo test ./internal/auth/... — ok (41 tests)","timestamp":"2026-06-13T08:41:35Z"}
+	]}`,
+	"/v0/city/bourbon/session/s-rex/transcript": `{"id":"s-rex","template":"polecat","provider":"anthropic","format":"conversation","turns":[
+		{"role":"user","text":"Work mg-q18: add table-driven tests for the auth service.","timestamp":"2026-06-13T08:05:00Z"},
+		{"role":"assistant","text":"Adding cases for expired, revoked, and malformed tokens.","timestamp":"2026-06-13T08:07:12Z"}
+	]}`,
+
 	// Mail — a mix of read/unread, priorities, senders.
 	"/v0/city/bourbon/mail": `{"items":[
 		{"id":"m1","from":"witness","to":"mayor","subject":"Quorum reached on mg-q12","body":"Two approvals in. Safe to land.","created_at":"2026-06-13T09:30:00Z","read":false,"priority":1,"rig":"krewe"},
@@ -84,6 +98,16 @@ var responses = map[string]string{
 		"progress":{"total":5,"closed":2}
 	}`,
 
+	// Single message + an epic's bead graph, so mail read and convoy
+	// create-from-epic resolve (the driver conformance suite exercises both).
+	"/v0/city/bourbon/mail/m1": `{"id":"m1","from":"witness","to":"mayor","subject":"Quorum reached on mg-q12","body":"Two approvals in. Safe to land.","created_at":"2026-06-13T09:30:00Z","read":false,"priority":1}`,
+
+	"/v0/city/bourbon/beads/graph/mg-e01": `{"root":{"id":"mg-e01","title":"Auth overhaul","status":"open","issue_type":"epic"},"beads":[
+		{"id":"mg-e01","title":"Auth overhaul","status":"open","issue_type":"epic"},
+		{"id":"mg-q12","title":"Refactor auth service","status":"in_progress","issue_type":"task"},
+		{"id":"mg-q18","title":"Add auth test coverage","status":"in_progress","issue_type":"task"}
+	],"deps":[]}`,
+
 	"/v0/city/bourbon/convoy/cv-cleanup": `{
 		"convoy":{"id":"cv-cleanup","title":"Tech-debt cleanup","status":"open","issue_type":"convoy","priority":3},
 		"children":[
@@ -102,11 +126,16 @@ func main() {
 
 	mux := http.NewServeMux()
 
-	// Canned GETs.
+	// Canned GETs. A mutation on the same path (POST /convoys, POST /mail)
+	// goes to handleOther like any other write.
 	for path, body := range responses {
 		body := body
 		path := path
 		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				handleOther(w, r)
+				return
+			}
 			logReq(r)
 			// The agents endpoint backs the panel's status poll; delaying it
 			// (via -delay) keeps the loading spinner on screen long enough to
@@ -118,29 +147,46 @@ func main() {
 		})
 	}
 
-	// Everything else: accept mutations (POST/PUT/PATCH/DELETE) with a 2xx so
-	// sling/nudge/decommission/convoy/mail actions succeed in the demo, and
-	// 404 unknown GETs (mg treats those as "feature absent").
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		logReq(r)
-		switch r.Method {
-		case http.MethodGet:
-			writeJSON(w, http.StatusNotFound, `{"title":"Not Found","status":404,"detail":"not_found: fakegc has no canned response for this path"}`)
-		default: // mutation
-			code := http.StatusOK
-			if strings.HasSuffix(r.URL.Path, "/submit") || strings.HasSuffix(r.URL.Path, "/kill") {
-				code = http.StatusAccepted
-			} else if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "convoys") {
-				code = http.StatusCreated
-				writeJSON(w, code, `{"id":"cv-new","title":"new convoy","status":"open","issue_type":"convoy"}`)
-				return
-			}
-			writeJSON(w, code, `{"status":"accepted"}`)
-		}
-	})
+	mux.HandleFunc("/", handleOther)
 
-	log.Printf("fakegc: Supervisor API listening on http://127.0.0.1%s (city %q)", normalize(*addr), city)
-	log.Fatal(http.ListenAndServe(*addr, mux))
+	// Listen first so -addr :0 works: the log line reports the port actually
+	// bound, in the same shape as the real supervisor's startup line.
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("fakegc: Supervisor API listening on http://127.0.0.1:%d (city %q)", l.Addr().(*net.TCPAddr).Port, city)
+	log.Fatal(http.Serve(l, mux))
+}
+
+// handleOther answers everything without a canned response: it accepts
+// mutations (POST/PUT/PATCH/DELETE) with a 2xx so sling/nudge/decommission/
+// convoy/mail actions succeed in the demo, and 404s unknown GETs (mg treats those as "feature absent"). A mutation that
+// names a "missing-" id (in the path or body) is a 404 too, so not-found
+// handling can be exercised for writes as well as reads.
+func handleOther(w http.ResponseWriter, r *http.Request) {
+	logReq(r)
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case r.Method == http.MethodGet:
+		writeJSON(w, http.StatusNotFound, `{"title":"Not Found","status":404,"detail":"not_found: fakegc has no canned response for this path"}`)
+	case strings.Contains(r.URL.Path, "missing-") || strings.Contains(string(body), "missing-"):
+		writeJSON(w, http.StatusNotFound, `{"title":"Not Found","status":404,"detail":"not_found: no such bead, message, or session"}`)
+	default: // mutation
+		code := http.StatusOK
+		if strings.HasSuffix(r.URL.Path, "/submit") || strings.HasSuffix(r.URL.Path, "/kill") {
+			code = http.StatusAccepted
+		} else if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "convoys") {
+			code = http.StatusCreated
+			writeJSON(w, code, `{"id":"cv-new","title":"new convoy","status":"open","issue_type":"convoy"}`)
+			return
+		} else if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/beads") {
+			code = http.StatusCreated
+			writeJSON(w, code, `{"id":"mg-new","title":"new bead","status":"open","issue_type":"task"}`)
+			return
+		}
+		writeJSON(w, code, `{"status":"accepted"}`)
+	}
 }
 
 func writeJSON(w http.ResponseWriter, code int, body string) {
@@ -150,10 +196,3 @@ func writeJSON(w http.ResponseWriter, code int, body string) {
 }
 
 func logReq(r *http.Request) { log.Printf("fakegc: %s %s", r.Method, r.URL.Path) }
-
-func normalize(addr string) string {
-	if strings.HasPrefix(addr, ":") {
-		return addr
-	}
-	return ":" + strings.TrimPrefix(addr, "127.0.0.1:")
-}